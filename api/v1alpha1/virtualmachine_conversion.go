@@ -144,9 +144,19 @@ func Convert_v1alpha2_VirtualMachineVolume_To_v1alpha1_VirtualMachineVolume(
 		}
 	}
 
+	// NOTE: in.Ephemeral and in.Image do not exist in v1a1. See restore_v1alpha2_VirtualMachineVolumes().
+
 	return autoConvert_v1alpha2_VirtualMachineVolume_To_v1alpha1_VirtualMachineVolume(in, out, s)
 }
 
+func Convert_v1alpha2_PersistentVolumeClaimVolumeSource_To_v1alpha1_PersistentVolumeClaimVolumeSource(
+	in *v1alpha2.PersistentVolumeClaimVolumeSource, out *PersistentVolumeClaimVolumeSource, s apiconversion.Scope) error {
+
+	// The volume attach options do not exist in v1a1. See restore_v1alpha2_VirtualMachineVolumes().
+
+	return autoConvert_v1alpha2_PersistentVolumeClaimVolumeSource_To_v1alpha1_PersistentVolumeClaimVolumeSource(in, out, s)
+}
+
 func convert_v1alpha1_VmMetadata_To_v1alpha2_BootstrapSpec(
 	in *VirtualMachineMetadata) *v1alpha2.VirtualMachineBootstrapSpec {
 
@@ -594,9 +604,6 @@ func Convert_v1alpha1_VirtualMachineSpec_To_v1alpha2_VirtualMachineSpec(
 		out.Reserved.ResourcePolicyName = in.ResourcePolicyName
 	}
 
-	// Deprecated:
-	// in.Ports
-
 	return nil
 }
 
@@ -637,12 +644,17 @@ func Convert_v1alpha2_VirtualMachineSpec_To_v1alpha1_VirtualMachineSpec(
 
 	// TODO = in.ReadinessGates
 
-	// Deprecated:
-	// out.Ports
-
 	return nil
 }
 
+func Convert_v1alpha1_VirtualMachinePort_To_v1alpha2_VirtualMachinePort(
+	in *VirtualMachinePort, out *v1alpha2.VirtualMachinePort, s apiconversion.Scope) error {
+
+	// The deprecated Ip does not exist in v1a2.
+
+	return autoConvert_v1alpha1_VirtualMachinePort_To_v1alpha2_VirtualMachinePort(in, out, s)
+}
+
 func Convert_v1alpha1_VirtualMachineVolumeStatus_To_v1alpha2_VirtualMachineVolumeStatus(
 	in *VirtualMachineVolumeStatus, out *v1alpha2.VirtualMachineVolumeStatus, s apiconversion.Scope) error {
 
@@ -825,6 +837,8 @@ func restore_v1alpha2_VirtualMachineBootstrapSpec(
 			dstVAppConfig.RawProperties = srcVAppConfig.RawProperties
 		}
 	}
+
+	dstBootstrap.Customization = srcBootstrap.Customization
 }
 
 func restore_v1alpha2_VirtualMachineNetworkSpec(
@@ -877,6 +891,50 @@ func restore_v1alpha2_VirtualMachineReadinessProbeSpec(
 			dst.Spec.ReadinessProbe = &v1alpha2.VirtualMachineReadinessProbeSpec{}
 		}
 		dst.Spec.ReadinessProbe.GuestInfo = src.Spec.ReadinessProbe.GuestInfo
+		dst.Spec.ReadinessProbe.HTTPGet = src.Spec.ReadinessProbe.HTTPGet
+	}
+}
+
+func restore_v1alpha2_VirtualMachineLivenessProbeSpec(
+	dst, src *v1alpha2.VirtualMachine) {
+
+	dst.Spec.LivenessProbe = src.Spec.LivenessProbe
+}
+
+func restore_v1alpha2_VirtualMachineCurrentSnapshotName(
+	dst, src *v1alpha2.VirtualMachine) {
+
+	dst.Spec.CurrentSnapshotName = src.Spec.CurrentSnapshotName
+}
+
+func restore_v1alpha2_VirtualMachineVolumes(
+	dst, src *v1alpha2.VirtualMachine) {
+
+	srcVolumes := map[string]*v1alpha2.VirtualMachineVolume{}
+	for i := range src.Spec.Volumes {
+		srcVolumes[src.Spec.Volumes[i].Name] = &src.Spec.Volumes[i]
+	}
+
+	for i := range dst.Spec.Volumes {
+		srcVolume := srcVolumes[dst.Spec.Volumes[i].Name]
+		if srcVolume == nil {
+			continue
+		}
+
+		dst.Spec.Volumes[i].Ephemeral = srcVolume.Ephemeral
+		dst.Spec.Volumes[i].Image = srcVolume.Image
+
+		dstClaim := dst.Spec.Volumes[i].PersistentVolumeClaim
+		srcClaim := srcVolume.PersistentVolumeClaim
+		if dstClaim == nil || srcClaim == nil {
+			continue
+		}
+
+		dstClaim.ControllerType = srcClaim.ControllerType
+		dstClaim.ControllerBusNumber = srcClaim.ControllerBusNumber
+		dstClaim.UnitNumber = srcClaim.UnitNumber
+		dstClaim.DiskMode = srcClaim.DiskMode
+		dstClaim.SharingMode = srcClaim.SharingMode
 	}
 }
 
@@ -896,6 +954,9 @@ func (src *VirtualMachine) ConvertTo(dstRaw conversion.Hub) error {
 	restore_v1alpha2_VirtualMachineBootstrapSpec(dst, restored)
 	restore_v1alpha2_VirtualMachineNetworkSpec(dst, restored)
 	restore_v1alpha2_VirtualMachineReadinessProbeSpec(dst, restored)
+	restore_v1alpha2_VirtualMachineLivenessProbeSpec(dst, restored)
+	restore_v1alpha2_VirtualMachineCurrentSnapshotName(dst, restored)
+	restore_v1alpha2_VirtualMachineVolumes(dst, restored)
 
 	dst.Status = restored.Status
 
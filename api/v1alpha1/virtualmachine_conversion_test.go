@@ -160,6 +160,22 @@ func TestVirtualMachineConversion(t *testing.T) {
 						Host: "some-host",
 						Port: intstr.FromString("https"),
 					},
+					HTTPGet: &nextver.HTTPGetAction{
+						Path:   "/healthz",
+						Port:   intstr.FromInt(8080),
+						Host:   "some-host",
+						Scheme: nextver.URISchemeHTTPS,
+						HTTPHeaders: []nextver.HTTPHeader{
+							{
+								Name:  "X-Header",
+								Value: "value",
+							},
+						},
+						ExpectedStatus: &nextver.HTTPStatusCodeRange{
+							Min: 200,
+							Max: 299,
+						},
+					},
 					GuestHeartbeat: &nextver.GuestHeartbeatAction{
 						ThresholdStatus: nextver.RedHeartbeatStatus,
 					},
@@ -172,6 +188,16 @@ func TestVirtualMachineConversion(t *testing.T) {
 					TimeoutSeconds: 100,
 					PeriodSeconds:  200,
 				},
+				LivenessProbe: &nextver.VirtualMachineLivenessProbeSpec{
+					VirtualMachineReadinessProbeSpec: nextver.VirtualMachineReadinessProbeSpec{
+						GuestHeartbeat: &nextver.GuestHeartbeatAction{
+							ThresholdStatus: nextver.YellowHeartbeatStatus,
+						},
+						PeriodSeconds: 30,
+					},
+					FailureThreshold: 5,
+					RestartPolicy:    nextver.VirtualMachineLivenessRestartPolicyAlways,
+				},
 				Advanced: &nextver.VirtualMachineAdvancedSpec{
 					BootDiskCapacity:              ptrOf(resource.MustParse("1024k")),
 					DefaultVolumeProvisioningMode: nextver.VirtualMachineVolumeProvisioningModeThickEagerZero,
@@ -258,6 +258,7 @@ func Convert_v1alpha2_VirtualMachineImageStatus_To_v1alpha1_VirtualMachineImageS
 	// out.ContentLibraryRef =
 
 	// in.Capabilities
+	// in.Disks
 
 	out.Conditions = convert_v1alpha2_VirtualMachineImageStatusConditions_To_v1alpha1_VirtualMachineImageStatusConditions(in.Conditions)
 
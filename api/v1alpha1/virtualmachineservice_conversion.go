@@ -4,20 +4,103 @@
 package v1alpha1
 
 import (
-	"github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	apiconversion "k8s.io/apimachinery/pkg/conversion"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/vmware-tanzu/vm-operator/api/utilconversion"
+	"github.com/vmware-tanzu/vm-operator/api/v1alpha2"
 )
 
+func Convert_v1alpha1_VirtualMachineServicePort_To_v1alpha2_VirtualMachineServicePort(
+	in *VirtualMachineServicePort, out *v1alpha2.VirtualMachineServicePort, s apiconversion.Scope) error {
+
+	if err := autoConvert_v1alpha1_VirtualMachineServicePort_To_v1alpha2_VirtualMachineServicePort(in, out, s); err != nil {
+		return err
+	}
+
+	out.TargetPort = intstr.FromInt(int(in.TargetPort))
+
+	return nil
+}
+
+func Convert_v1alpha2_VirtualMachineServicePort_To_v1alpha1_VirtualMachineServicePort(
+	in *v1alpha2.VirtualMachineServicePort, out *VirtualMachineServicePort, s apiconversion.Scope) error {
+
+	if err := autoConvert_v1alpha2_VirtualMachineServicePort_To_v1alpha1_VirtualMachineServicePort(in, out, s); err != nil {
+		return err
+	}
+
+	// The NodePort and named TargetPorts do not exist in v1a1. See restore_v1alpha2_VirtualMachineServicePorts().
+	if in.TargetPort.Type == intstr.Int {
+		out.TargetPort = in.TargetPort.IntVal
+	}
+
+	return nil
+}
+
+func Convert_v1alpha2_VirtualMachineServiceSpec_To_v1alpha1_VirtualMachineServiceSpec(
+	in *v1alpha2.VirtualMachineServiceSpec, out *VirtualMachineServiceSpec, s apiconversion.Scope) error {
+
+	// The IPFamilies and IPFamilyPolicy do not exist in v1a1. See restore_v1alpha2_VirtualMachineServiceIPFamilies().
+
+	return autoConvert_v1alpha2_VirtualMachineServiceSpec_To_v1alpha1_VirtualMachineServiceSpec(in, out, s)
+}
+
+func restore_v1alpha2_VirtualMachineServiceIPFamilies(
+	dst, src *v1alpha2.VirtualMachineService) {
+
+	dst.Spec.IPFamilies = src.Spec.IPFamilies
+	dst.Spec.IPFamilyPolicy = src.Spec.IPFamilyPolicy
+}
+
+func restore_v1alpha2_VirtualMachineServicePorts(
+	dst, src *v1alpha2.VirtualMachineService) {
+
+	srcPorts := map[string]*v1alpha2.VirtualMachineServicePort{}
+	for i := range src.Spec.Ports {
+		srcPorts[src.Spec.Ports[i].Name] = &src.Spec.Ports[i]
+	}
+
+	for i := range dst.Spec.Ports {
+		dstPort := &dst.Spec.Ports[i]
+		if srcPort, ok := srcPorts[dstPort.Name]; ok {
+			dstPort.NodePort = srcPort.NodePort
+			if srcPort.TargetPort.Type == intstr.String && dstPort.TargetPort.IntValue() == 0 {
+				dstPort.TargetPort = srcPort.TargetPort
+			}
+		}
+	}
+}
+
 // ConvertTo converts this VirtualMachineService to the Hub version.
 func (src *VirtualMachineService) ConvertTo(dstRaw conversion.Hub) error {
 	dst := dstRaw.(*v1alpha2.VirtualMachineService)
-	return Convert_v1alpha1_VirtualMachineService_To_v1alpha2_VirtualMachineService(src, dst, nil)
+	if err := Convert_v1alpha1_VirtualMachineService_To_v1alpha2_VirtualMachineService(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Manually restore data.
+	restored := &v1alpha2.VirtualMachineService{}
+	if ok, err := utilconversion.UnmarshalData(src, restored); err != nil || !ok {
+		return err
+	}
+
+	restore_v1alpha2_VirtualMachineServicePorts(dst, restored)
+	restore_v1alpha2_VirtualMachineServiceIPFamilies(dst, restored)
+
+	return nil
 }
 
 // ConvertFrom converts the hub version to this VirtualMachineService.
 func (dst *VirtualMachineService) ConvertFrom(srcRaw conversion.Hub) error {
 	src := srcRaw.(*v1alpha2.VirtualMachineService)
-	return Convert_v1alpha2_VirtualMachineService_To_v1alpha1_VirtualMachineService(src, dst, nil)
+	if err := Convert_v1alpha2_VirtualMachineService_To_v1alpha1_VirtualMachineService(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Preserve Hub data on down-conversion except for metadata
+	return utilconversion.MarshalData(src, dst)
 }
 
 // ConvertTo converts this VirtualMachineServiceList to the Hub version.
@@ -142,11 +142,6 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1alpha2.PersistentVolumeClaimVolumeSource)(nil), (*PersistentVolumeClaimVolumeSource)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1alpha2_PersistentVolumeClaimVolumeSource_To_v1alpha1_PersistentVolumeClaimVolumeSource(a.(*v1alpha2.PersistentVolumeClaimVolumeSource), b.(*PersistentVolumeClaimVolumeSource), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*ResourcePoolSpec)(nil), (*v1alpha2.ResourcePoolSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_ResourcePoolSpec_To_v1alpha2_ResourcePoolSpec(a.(*ResourcePoolSpec), b.(*v1alpha2.ResourcePoolSpec), scope)
 	}); err != nil {
@@ -312,6 +307,11 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha2.VirtualMachinePort)(nil), (*VirtualMachinePort)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_VirtualMachinePort_To_v1alpha1_VirtualMachinePort(a.(*v1alpha2.VirtualMachinePort), b.(*VirtualMachinePort), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*VirtualMachinePublishRequest)(nil), (*v1alpha2.VirtualMachinePublishRequest)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_VirtualMachinePublishRequest_To_v1alpha2_VirtualMachinePublishRequest(a.(*VirtualMachinePublishRequest), b.(*v1alpha2.VirtualMachinePublishRequest), scope)
 	}); err != nil {
@@ -422,26 +422,11 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*VirtualMachineServicePort)(nil), (*v1alpha2.VirtualMachineServicePort)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1alpha1_VirtualMachineServicePort_To_v1alpha2_VirtualMachineServicePort(a.(*VirtualMachineServicePort), b.(*v1alpha2.VirtualMachineServicePort), scope)
-	}); err != nil {
-		return err
-	}
-	if err := s.AddGeneratedConversionFunc((*v1alpha2.VirtualMachineServicePort)(nil), (*VirtualMachineServicePort)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1alpha2_VirtualMachineServicePort_To_v1alpha1_VirtualMachineServicePort(a.(*v1alpha2.VirtualMachineServicePort), b.(*VirtualMachineServicePort), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*VirtualMachineServiceSpec)(nil), (*v1alpha2.VirtualMachineServiceSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_VirtualMachineServiceSpec_To_v1alpha2_VirtualMachineServiceSpec(a.(*VirtualMachineServiceSpec), b.(*v1alpha2.VirtualMachineServiceSpec), scope)
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1alpha2.VirtualMachineServiceSpec)(nil), (*VirtualMachineServiceSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1alpha2_VirtualMachineServiceSpec_To_v1alpha1_VirtualMachineServiceSpec(a.(*v1alpha2.VirtualMachineServiceSpec), b.(*VirtualMachineServiceSpec), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*VirtualMachineServiceStatus)(nil), (*v1alpha2.VirtualMachineServiceStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_VirtualMachineServiceStatus_To_v1alpha2_VirtualMachineServiceStatus(a.(*VirtualMachineServiceStatus), b.(*v1alpha2.VirtualMachineServiceStatus), scope)
 	}); err != nil {
@@ -527,6 +512,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddConversionFunc((*VirtualMachinePort)(nil), (*v1alpha2.VirtualMachinePort)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_VirtualMachinePort_To_v1alpha2_VirtualMachinePort(a.(*VirtualMachinePort), b.(*v1alpha2.VirtualMachinePort), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddConversionFunc((*VirtualMachineServicePort)(nil), (*v1alpha2.VirtualMachineServicePort)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_VirtualMachineServicePort_To_v1alpha2_VirtualMachineServicePort(a.(*VirtualMachineServicePort), b.(*v1alpha2.VirtualMachineServicePort), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddConversionFunc((*VirtualMachineSetResourcePolicySpec)(nil), (*v1alpha2.VirtualMachineSetResourcePolicySpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_VirtualMachineSetResourcePolicySpec_To_v1alpha2_VirtualMachineSetResourcePolicySpec(a.(*VirtualMachineSetResourcePolicySpec), b.(*v1alpha2.VirtualMachineSetResourcePolicySpec), scope)
 	}); err != nil {
@@ -552,6 +547,11 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddConversionFunc((*v1alpha2.PersistentVolumeClaimVolumeSource)(nil), (*PersistentVolumeClaimVolumeSource)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_PersistentVolumeClaimVolumeSource_To_v1alpha1_PersistentVolumeClaimVolumeSource(a.(*v1alpha2.PersistentVolumeClaimVolumeSource), b.(*PersistentVolumeClaimVolumeSource), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddConversionFunc((*v1alpha2.VirtualMachineClassStatus)(nil), (*VirtualMachineClassStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_VirtualMachineClassStatus_To_v1alpha1_VirtualMachineClassStatus(a.(*v1alpha2.VirtualMachineClassStatus), b.(*VirtualMachineClassStatus), scope)
 	}); err != nil {
@@ -572,6 +572,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddConversionFunc((*v1alpha2.VirtualMachineServicePort)(nil), (*VirtualMachineServicePort)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_VirtualMachineServicePort_To_v1alpha1_VirtualMachineServicePort(a.(*v1alpha2.VirtualMachineServicePort), b.(*VirtualMachineServicePort), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddConversionFunc((*v1alpha2.VirtualMachineServiceSpec)(nil), (*VirtualMachineServiceSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_VirtualMachineServiceSpec_To_v1alpha1_VirtualMachineServiceSpec(a.(*v1alpha2.VirtualMachineServiceSpec), b.(*VirtualMachineServiceSpec), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddConversionFunc((*v1alpha2.VirtualMachineSetResourcePolicySpec)(nil), (*VirtualMachineSetResourcePolicySpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_VirtualMachineSetResourcePolicySpec_To_v1alpha1_VirtualMachineSetResourcePolicySpec(a.(*v1alpha2.VirtualMachineSetResourcePolicySpec), b.(*VirtualMachineSetResourcePolicySpec), scope)
 	}); err != nil {
@@ -904,14 +914,14 @@ func Convert_v1alpha1_PersistentVolumeClaimVolumeSource_To_v1alpha2_PersistentVo
 func autoConvert_v1alpha2_PersistentVolumeClaimVolumeSource_To_v1alpha1_PersistentVolumeClaimVolumeSource(in *v1alpha2.PersistentVolumeClaimVolumeSource, out *PersistentVolumeClaimVolumeSource, s conversion.Scope) error {
 	out.PersistentVolumeClaimVolumeSource = in.PersistentVolumeClaimVolumeSource
 	out.InstanceVolumeClaim = (*InstanceVolumeClaimVolumeSource)(unsafe.Pointer(in.InstanceVolumeClaim))
+	// WARNING: in.ControllerType requires manual conversion: does not exist in peer-type
+	// WARNING: in.ControllerBusNumber requires manual conversion: does not exist in peer-type
+	// WARNING: in.UnitNumber requires manual conversion: does not exist in peer-type
+	// WARNING: in.DiskMode requires manual conversion: does not exist in peer-type
+	// WARNING: in.SharingMode requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_v1alpha2_PersistentVolumeClaimVolumeSource_To_v1alpha1_PersistentVolumeClaimVolumeSource is an autogenerated conversion function.
-func Convert_v1alpha2_PersistentVolumeClaimVolumeSource_To_v1alpha1_PersistentVolumeClaimVolumeSource(in *v1alpha2.PersistentVolumeClaimVolumeSource, out *PersistentVolumeClaimVolumeSource, s conversion.Scope) error {
-	return autoConvert_v1alpha2_PersistentVolumeClaimVolumeSource_To_v1alpha1_PersistentVolumeClaimVolumeSource(in, out, s)
-}
-
 func autoConvert_v1alpha1_ResourcePoolSpec_To_v1alpha2_ResourcePoolSpec(in *ResourcePoolSpec, out *v1alpha2.ResourcePoolSpec, s conversion.Scope) error {
 	out.Name = in.Name
 	if err := Convert_v1alpha1_VirtualMachineResourceSpec_To_v1alpha2_VirtualMachineResourceSpec(&in.Reservations, &out.Reservations, s); err != nil {
@@ -1443,6 +1453,7 @@ func autoConvert_v1alpha2_VirtualMachineImageStatus_To_v1alpha1_VirtualMachineIm
 	// WARNING: in.Capabilities requires manual conversion: does not exist in peer-type
 	out.Firmware = in.Firmware
 	// WARNING: in.HardwareVersion requires manual conversion: does not exist in peer-type
+	// WARNING: in.Disks requires manual conversion: does not exist in peer-type
 	// WARNING: in.OSInfo requires manual conversion: does not exist in peer-type
 	// WARNING: in.OVFProperties requires manual conversion: does not exist in peer-type
 	// WARNING: in.VMwareSystemProperties requires manual conversion: does not exist in peer-type
@@ -1505,6 +1516,26 @@ func Convert_v1alpha2_VirtualMachineList_To_v1alpha1_VirtualMachineList(in *v1al
 	return autoConvert_v1alpha2_VirtualMachineList_To_v1alpha1_VirtualMachineList(in, out, s)
 }
 
+func autoConvert_v1alpha1_VirtualMachinePort_To_v1alpha2_VirtualMachinePort(in *VirtualMachinePort, out *v1alpha2.VirtualMachinePort, s conversion.Scope) error {
+	out.Port = int32(in.Port)
+	// WARNING: in.Ip requires manual conversion: does not exist in peer-type
+	out.Name = in.Name
+	out.Protocol = string(in.Protocol)
+	return nil
+}
+
+func autoConvert_v1alpha2_VirtualMachinePort_To_v1alpha1_VirtualMachinePort(in *v1alpha2.VirtualMachinePort, out *VirtualMachinePort, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Port = int(in.Port)
+	out.Protocol = corev1.Protocol(in.Protocol)
+	return nil
+}
+
+// Convert_v1alpha2_VirtualMachinePort_To_v1alpha1_VirtualMachinePort is an autogenerated conversion function.
+func Convert_v1alpha2_VirtualMachinePort_To_v1alpha1_VirtualMachinePort(in *v1alpha2.VirtualMachinePort, out *VirtualMachinePort, s conversion.Scope) error {
+	return autoConvert_v1alpha2_VirtualMachinePort_To_v1alpha1_VirtualMachinePort(in, out, s)
+}
+
 func autoConvert_v1alpha1_VirtualMachinePublishRequest_To_v1alpha2_VirtualMachinePublishRequest(in *VirtualMachinePublishRequest, out *v1alpha2.VirtualMachinePublishRequest, s conversion.Scope) error {
 	out.ObjectMeta = in.ObjectMeta
 	if err := Convert_v1alpha1_VirtualMachinePublishRequestSpec_To_v1alpha2_VirtualMachinePublishRequestSpec(&in.Spec, &out.Spec, s); err != nil {
@@ -1823,7 +1854,17 @@ func Convert_v1alpha2_VirtualMachineService_To_v1alpha1_VirtualMachineService(in
 
 func autoConvert_v1alpha1_VirtualMachineServiceList_To_v1alpha2_VirtualMachineServiceList(in *VirtualMachineServiceList, out *v1alpha2.VirtualMachineServiceList, s conversion.Scope) error {
 	out.ListMeta = in.ListMeta
-	out.Items = *(*[]v1alpha2.VirtualMachineService)(unsafe.Pointer(&in.Items))
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]v1alpha2.VirtualMachineService, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha1_VirtualMachineService_To_v1alpha2_VirtualMachineService(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
 	return nil
 }
 
@@ -1834,7 +1875,17 @@ func Convert_v1alpha1_VirtualMachineServiceList_To_v1alpha2_VirtualMachineServic
 
 func autoConvert_v1alpha2_VirtualMachineServiceList_To_v1alpha1_VirtualMachineServiceList(in *v1alpha2.VirtualMachineServiceList, out *VirtualMachineServiceList, s conversion.Scope) error {
 	out.ListMeta = in.ListMeta
-	out.Items = *(*[]VirtualMachineService)(unsafe.Pointer(&in.Items))
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VirtualMachineService, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha2_VirtualMachineService_To_v1alpha1_VirtualMachineService(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
 	return nil
 }
 
@@ -1847,31 +1898,32 @@ func autoConvert_v1alpha1_VirtualMachineServicePort_To_v1alpha2_VirtualMachineSe
 	out.Name = in.Name
 	out.Protocol = in.Protocol
 	out.Port = in.Port
-	out.TargetPort = in.TargetPort
+	// WARNING: in.TargetPort requires manual conversion: inconvertible types (int32 vs k8s.io/apimachinery/pkg/util/intstr.IntOrString)
 	return nil
 }
 
-// Convert_v1alpha1_VirtualMachineServicePort_To_v1alpha2_VirtualMachineServicePort is an autogenerated conversion function.
-func Convert_v1alpha1_VirtualMachineServicePort_To_v1alpha2_VirtualMachineServicePort(in *VirtualMachineServicePort, out *v1alpha2.VirtualMachineServicePort, s conversion.Scope) error {
-	return autoConvert_v1alpha1_VirtualMachineServicePort_To_v1alpha2_VirtualMachineServicePort(in, out, s)
-}
-
 func autoConvert_v1alpha2_VirtualMachineServicePort_To_v1alpha1_VirtualMachineServicePort(in *v1alpha2.VirtualMachineServicePort, out *VirtualMachineServicePort, s conversion.Scope) error {
 	out.Name = in.Name
 	out.Protocol = in.Protocol
 	out.Port = in.Port
-	out.TargetPort = in.TargetPort
+	// WARNING: in.TargetPort requires manual conversion: inconvertible types (k8s.io/apimachinery/pkg/util/intstr.IntOrString vs int32)
+	// WARNING: in.NodePort requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_v1alpha2_VirtualMachineServicePort_To_v1alpha1_VirtualMachineServicePort is an autogenerated conversion function.
-func Convert_v1alpha2_VirtualMachineServicePort_To_v1alpha1_VirtualMachineServicePort(in *v1alpha2.VirtualMachineServicePort, out *VirtualMachineServicePort, s conversion.Scope) error {
-	return autoConvert_v1alpha2_VirtualMachineServicePort_To_v1alpha1_VirtualMachineServicePort(in, out, s)
-}
-
 func autoConvert_v1alpha1_VirtualMachineServiceSpec_To_v1alpha2_VirtualMachineServiceSpec(in *VirtualMachineServiceSpec, out *v1alpha2.VirtualMachineServiceSpec, s conversion.Scope) error {
 	out.Type = v1alpha2.VirtualMachineServiceType(in.Type)
-	out.Ports = *(*[]v1alpha2.VirtualMachineServicePort)(unsafe.Pointer(&in.Ports))
+	if in.Ports != nil {
+		in, out := &in.Ports, &out.Ports
+		*out = make([]v1alpha2.VirtualMachineServicePort, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha1_VirtualMachineServicePort_To_v1alpha2_VirtualMachineServicePort(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Ports = nil
+	}
 	out.Selector = *(*map[string]string)(unsafe.Pointer(&in.Selector))
 	out.LoadBalancerIP = in.LoadBalancerIP
 	out.LoadBalancerSourceRanges = *(*[]string)(unsafe.Pointer(&in.LoadBalancerSourceRanges))
@@ -1887,20 +1939,27 @@ func Convert_v1alpha1_VirtualMachineServiceSpec_To_v1alpha2_VirtualMachineServic
 
 func autoConvert_v1alpha2_VirtualMachineServiceSpec_To_v1alpha1_VirtualMachineServiceSpec(in *v1alpha2.VirtualMachineServiceSpec, out *VirtualMachineServiceSpec, s conversion.Scope) error {
 	out.Type = VirtualMachineServiceType(in.Type)
-	out.Ports = *(*[]VirtualMachineServicePort)(unsafe.Pointer(&in.Ports))
+	if in.Ports != nil {
+		in, out := &in.Ports, &out.Ports
+		*out = make([]VirtualMachineServicePort, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha2_VirtualMachineServicePort_To_v1alpha1_VirtualMachineServicePort(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Ports = nil
+	}
 	out.Selector = *(*map[string]string)(unsafe.Pointer(&in.Selector))
 	out.LoadBalancerIP = in.LoadBalancerIP
 	out.LoadBalancerSourceRanges = *(*[]string)(unsafe.Pointer(&in.LoadBalancerSourceRanges))
 	out.ClusterIP = in.ClusterIP
 	out.ExternalName = in.ExternalName
+	// WARNING: in.IPFamilies requires manual conversion: does not exist in peer-type
+	// WARNING: in.IPFamilyPolicy requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_v1alpha2_VirtualMachineServiceSpec_To_v1alpha1_VirtualMachineServiceSpec is an autogenerated conversion function.
-func Convert_v1alpha2_VirtualMachineServiceSpec_To_v1alpha1_VirtualMachineServiceSpec(in *v1alpha2.VirtualMachineServiceSpec, out *VirtualMachineServiceSpec, s conversion.Scope) error {
-	return autoConvert_v1alpha2_VirtualMachineServiceSpec_To_v1alpha1_VirtualMachineServiceSpec(in, out, s)
-}
-
 func autoConvert_v1alpha1_VirtualMachineServiceStatus_To_v1alpha2_VirtualMachineServiceStatus(in *VirtualMachineServiceStatus, out *v1alpha2.VirtualMachineServiceStatus, s conversion.Scope) error {
 	if err := Convert_v1alpha1_LoadBalancerStatus_To_v1alpha2_LoadBalancerStatus(&in.LoadBalancer, &out.LoadBalancer, s); err != nil {
 		return err
@@ -2045,7 +2104,17 @@ func autoConvert_v1alpha1_VirtualMachineSpec_To_v1alpha2_VirtualMachineSpec(in *
 	out.SuspendMode = v1alpha2.VirtualMachinePowerOpMode(in.SuspendMode)
 	out.NextRestartTime = in.NextRestartTime
 	out.RestartMode = v1alpha2.VirtualMachinePowerOpMode(in.RestartMode)
-	// WARNING: in.Ports requires manual conversion: does not exist in peer-type
+	if in.Ports != nil {
+		in, out := &in.Ports, &out.Ports
+		*out = make([]v1alpha2.VirtualMachinePort, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha1_VirtualMachinePort_To_v1alpha2_VirtualMachinePort(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Ports = nil
+	}
 	// WARNING: in.VmMetadata requires manual conversion: does not exist in peer-type
 	out.StorageClass = in.StorageClass
 	// WARNING: in.NetworkInterfaces requires manual conversion: does not exist in peer-type
@@ -2097,6 +2166,17 @@ func autoConvert_v1alpha2_VirtualMachineSpec_To_v1alpha1_VirtualMachineSpec(in *
 	} else {
 		out.Volumes = nil
 	}
+	if in.Ports != nil {
+		in, out := &in.Ports, &out.Ports
+		*out = make([]VirtualMachinePort, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha2_VirtualMachinePort_To_v1alpha1_VirtualMachinePort(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Ports = nil
+	}
 	if in.ReadinessProbe != nil {
 		in, out := &in.ReadinessProbe, &out.ReadinessProbe
 		*out = new(Probe)
@@ -2106,6 +2186,8 @@ func autoConvert_v1alpha2_VirtualMachineSpec_To_v1alpha1_VirtualMachineSpec(in *
 	} else {
 		out.ReadinessProbe = nil
 	}
+	// WARNING: in.LivenessProbe requires manual conversion: does not exist in peer-type
+	// WARNING: in.CurrentSnapshotName requires manual conversion: does not exist in peer-type
 	// WARNING: in.Advanced requires manual conversion: does not exist in peer-type
 	// WARNING: in.Reserved requires manual conversion: does not exist in peer-type
 	out.MinHardwareVersion = in.MinHardwareVersion
@@ -2153,6 +2235,7 @@ func autoConvert_v1alpha1_VirtualMachineStatus_To_v1alpha2_VirtualMachineStatus(
 func autoConvert_v1alpha2_VirtualMachineStatus_To_v1alpha1_VirtualMachineStatus(in *v1alpha2.VirtualMachineStatus, out *VirtualMachineStatus, s conversion.Scope) error {
 	// WARNING: in.Image requires manual conversion: does not exist in peer-type
 	// WARNING: in.Class requires manual conversion: does not exist in peer-type
+	// WARNING: in.StorageClass requires manual conversion: does not exist in peer-type
 	out.Host = in.Host
 	out.PowerState = VirtualMachinePowerState(in.PowerState)
 	if in.Conditions != nil {
@@ -2185,6 +2268,10 @@ func autoConvert_v1alpha2_VirtualMachineStatus_To_v1alpha1_VirtualMachineStatus(
 	out.Zone = in.Zone
 	out.LastRestartTime = (*v1.Time)(unsafe.Pointer(in.LastRestartTime))
 	out.HardwareVersion = in.HardwareVersion
+	// WARNING: in.CurrentSnapshot requires manual conversion: does not exist in peer-type
+	// WARNING: in.Liveness requires manual conversion: does not exist in peer-type
+	// WARNING: in.Guest requires manual conversion: does not exist in peer-type
+	// WARNING: in.GuestCustomization requires manual conversion: does not exist in peer-type
 	return nil
 }
 
@@ -2256,5 +2343,8 @@ func autoConvert_v1alpha2_VirtualMachineVolumeStatus_To_v1alpha1_VirtualMachineV
 	out.Attached = in.Attached
 	// WARNING: in.DiskUUID requires manual conversion: does not exist in peer-type
 	out.Error = in.Error
+	// WARNING: in.RequestedCapacity requires manual conversion: does not exist in peer-type
+	// WARNING: in.AttachedCapacity requires manual conversion: does not exist in peer-type
+	// WARNING: in.ResizeState requires manual conversion: does not exist in peer-type
 	return nil
 }
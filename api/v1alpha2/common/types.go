@@ -97,3 +97,28 @@ type NameValuePair struct {
 	// +optional
 	Value string `json:"value,omitempty"`
 }
+
+// ObjectMeta is metadata that all persisted resources must have, which
+// includes all objects users must create. This is a copy of customizable
+// fields from metav1.ObjectMeta.
+//
+// ObjectMeta is embedded in templates, such as
+// VirtualMachineReplicaSet.Spec.Template, from which other resources are
+// created.
+type ObjectMeta struct {
+	// Labels is a map of string keys and values that can be used to organize
+	// and categorize (scope and select) objects.
+	// More info: http://kubernetes.io/docs/user-guide/labels
+	//
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations is an unstructured key value map stored with a resource
+	// that may be set by external tools to store and retrieve arbitrary
+	// metadata. They are not queryable and should be preserved when modifying
+	// objects.
+	// More info: http://kubernetes.io/docs/user-guide/annotations
+	//
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
@@ -4,6 +4,8 @@
 package v1alpha2
 
 import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
 	"github.com/vmware-tanzu/vm-operator/api/v1alpha2/cloudinit"
 	"github.com/vmware-tanzu/vm-operator/api/v1alpha2/common"
 	"github.com/vmware-tanzu/vm-operator/api/v1alpha2/sysprep"
@@ -80,6 +82,41 @@ type VirtualMachineBootstrapSpec struct {
 	//
 	// +optional
 	VAppConfig *VirtualMachineBootstrapVAppConfigSpec `json:"vAppConfig,omitempty"`
+
+	// Customization describes how failures of guest customization are
+	// reported and recovered from.
+	//
+	// Please note this field only applies to the LinuxPrep and Sysprep
+	// bootstrap providers.
+	//
+	// +optional
+	Customization *VirtualMachineBootstrapCustomizationSpec `json:"customization,omitempty"`
+}
+
+// VirtualMachineBootstrapCustomizationSpec describes how failures of guest
+// customization are reported and recovered from.
+type VirtualMachineBootstrapCustomizationSpec struct {
+	// RetryOnSecretChange specifies whether guest customization is run again
+	// after it failed once the bootstrap data, ex. the data in the Secret
+	// referenced by RawSysprep, has changed.
+	//
+	// When true, a VM whose guest customization failed is powered off after
+	// its bootstrap data changes, and it is customized again with the new
+	// data when it is powered back on.
+	//
+	// +optional
+	RetryOnSecretChange bool `json:"retryOnSecretChange,omitempty"`
+
+	// GuestCredentials is the name of a Secret resource in the same Namespace
+	// as this VM with the "username" and "password" keys of a guest account.
+	//
+	// When specified and VMware Tools is running in the guest, the account is
+	// used to fetch the tail of the guest customization log after guest
+	// customization failed. Please refer to
+	// VirtualMachineGuestCustomizationStatus.LogTail for more information.
+	//
+	// +optional
+	GuestCredentials string `json:"guestCredentials,omitempty"`
 }
 
 // VirtualMachineBootstrapCloudInitSpec describes the CloudInit configuration
@@ -182,3 +219,51 @@ type VirtualMachineBootstrapVAppConfigSpec struct {
 	// +optional
 	RawProperties string `json:"rawProperties,omitempty"`
 }
+
+// VirtualMachineGuestCustomizationStatus describes the observed state of
+// guest customization in the VM's guest OS.
+type VirtualMachineGuestCustomizationStatus struct {
+	// Status is the vSphere status of guest customization, ex.
+	// TOOLSDEPLOYPKG_SUCCEEDED or TOOLSDEPLOYPKG_FAILED.
+	//
+	// +optional
+	Status string `json:"status,omitempty"`
+
+	// StartTime is the time guest customization started in the guest OS.
+	//
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// EndTime is the time guest customization completed in the guest OS.
+	//
+	// +optional
+	EndTime *metav1.Time `json:"endTime,omitempty"`
+
+	// ErrorMessage describes the error reported by the guest OS when guest
+	// customization failed.
+	//
+	// +optional
+	ErrorMessage string `json:"errorMessage,omitempty"`
+
+	// LogLocation is the path in the guest OS of the guest customization log.
+	//
+	// +optional
+	LogLocation string `json:"logLocation,omitempty"`
+
+	// LogTail is the last part of the guest customization log, fetched from
+	// the guest with VMware Tools guest operations after guest customization
+	// failed.
+	//
+	// Please note this field is only populated when
+	// spec.bootstrap.customization.guestCredentials is specified.
+	//
+	// +optional
+	LogTail string `json:"logTail,omitempty"`
+
+	// DataHash is the hash of the bootstrap data the VM was most recently
+	// customized with. It is used to determine whether the bootstrap data
+	// changed after guest customization failed.
+	//
+	// +optional
+	DataHash string `json:"dataHash,omitempty"`
+}
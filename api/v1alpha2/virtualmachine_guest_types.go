@@ -0,0 +1,78 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha2
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// VirtualMachineGuestFilesystemStatus describes the observed state of a
+// filesystem mounted in the guest.
+type VirtualMachineGuestFilesystemStatus struct {
+	// Path is the path at which the filesystem is mounted in the guest, ex.
+	// "/" or "C:\".
+	Path string `json:"path"`
+
+	// Type is the type of the filesystem, ex. "ext4" or "NTFS".
+	//
+	// +optional
+	Type string `json:"type,omitempty"`
+
+	// Capacity is the total capacity of the filesystem.
+	//
+	// +optional
+	Capacity *resource.Quantity `json:"capacity,omitempty"`
+
+	// FreeSpace is the free space of the filesystem.
+	//
+	// +optional
+	FreeSpace *resource.Quantity `json:"freeSpace,omitempty"`
+}
+
+// VirtualMachineGuestStatus describes the observed resource usage of the VM
+// and its guest.
+//
+// Please note the filesystem information is only available if the guest has
+// VM Tools installed.
+//
+// Please note the resource usage changes continuously, so it is only refreshed
+// periodically rather than every time the VM is reconciled.
+type VirtualMachineGuestStatus struct {
+	// LastUpdateTime is the time at which the resource usage was last read
+	// from vSphere.
+	//
+	// +optional
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
+
+	// Filesystems describes the filesystems mounted in the guest.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=path
+	Filesystems []VirtualMachineGuestFilesystemStatus `json:"filesystems,omitempty"`
+
+	// ConsumedMemory is the amount of host memory consumed by the VM.
+	//
+	// +optional
+	ConsumedMemory *resource.Quantity `json:"consumedMemory,omitempty"`
+
+	// CPUUsageMHz is the CPU usage of the VM in MHz.
+	//
+	// +optional
+	CPUUsageMHz int64 `json:"cpuUsageMHz,omitempty"`
+
+	// CommittedStorage is the storage space used by the VM's files on the
+	// datastores.
+	//
+	// +optional
+	CommittedStorage *resource.Quantity `json:"committedStorage,omitempty"`
+
+	// UncommittedStorage is the additional storage space that may be used by
+	// the VM's files on the datastores, ex. by thin provisioned disks growing
+	// to their full size.
+	//
+	// +optional
+	UncommittedStorage *resource.Quantity `json:"uncommittedStorage,omitempty"`
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// VirtualMachineLivenessRestartPolicy describes what happens to a VM when its
+// liveness probe fails FailureThreshold times in a row.
+type VirtualMachineLivenessRestartPolicy string
+
+const (
+	// VirtualMachineLivenessRestartPolicyAlways restarts the VM, in
+	// accordance with spec.restartMode, when its liveness probe fails.
+	VirtualMachineLivenessRestartPolicyAlways VirtualMachineLivenessRestartPolicy = "Always"
+
+	// VirtualMachineLivenessRestartPolicyNever only records the liveness probe
+	// failures in the VM's status.
+	VirtualMachineLivenessRestartPolicyNever VirtualMachineLivenessRestartPolicy = "Never"
+)
+
+// VirtualMachineLivenessProbeSpec describes a probe used to determine if a VM
+// is alive, and what happens to the VM when it is not.
+//
+// The probe actions are the same as those of a readiness probe and are
+// mutually exclusive.
+type VirtualMachineLivenessProbeSpec struct {
+	VirtualMachineReadinessProbeSpec `json:",inline"`
+
+	// FailureThreshold specifies the number of consecutive times the probe
+	// must fail before the VM is considered dead.
+	// Defaults to 3. Minimum value is 1.
+	// +optional
+	// +kubebuilder:default=3
+	// +kubebuilder:validation:Minimum:=1
+	FailureThreshold int32 `json:"failureThreshold,omitempty"`
+
+	// InitialDelaySeconds specifies the number of seconds after the VM is
+	// powered on, or restarted because the probe failed, before the probe is
+	// run.
+	//
+	// After a restart, the probe additionally waits a back-off period that
+	// doubles with each restart, starting at PeriodSeconds and up to five
+	// minutes, so a VM that never becomes alive is not restarted in a loop.
+	//
+	// Defaults to 0 seconds. Minimum value is 0.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum:=0
+	InitialDelaySeconds int32 `json:"initialDelaySeconds,omitempty"`
+
+	// RestartPolicy describes what happens when the VM is considered dead.
+	//
+	// There are two supported policies: Always and Never. The Always policy
+	// restarts the VM in accordance with spec.restartMode. The Never policy
+	// only records the failures in status.liveness.
+	//
+	// If omitted, the policy defaults to Always.
+	//
+	// +optional
+	// +kubebuilder:default=Always
+	// +kubebuilder:validation:Enum=Always;Never
+	RestartPolicy VirtualMachineLivenessRestartPolicy `json:"restartPolicy,omitempty"`
+}
+
+// VirtualMachineLivenessStatus describes the observed state of a VM's
+// liveness probe.
+type VirtualMachineLivenessStatus struct {
+	// ConsecutiveFailures describes the number of times in a row the liveness
+	// probe has failed since it last succeeded or the VM was last restarted
+	// by the probe.
+	//
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+
+	// RestartCount describes the number of times the VM was restarted because
+	// its liveness probe failed.
+	//
+	// +optional
+	RestartCount int32 `json:"restartCount,omitempty"`
+
+	// LastFailureReason describes why the liveness probe last failed.
+	//
+	// +optional
+	LastFailureReason string `json:"lastFailureReason,omitempty"`
+
+	// LastFailureTime describes the last time the liveness probe failed.
+	//
+	// +optional
+	LastFailureTime *metav1.Time `json:"lastFailureTime,omitempty"`
+
+	// LastRestartTime describes the last time the VM was restarted because
+	// its liveness probe failed.
+	//
+	// +optional
+	LastRestartTime *metav1.Time `json:"lastRestartTime,omitempty"`
+
+	// PoweredOnTime describes when the liveness probe first observed the VM
+	// powered on. The probe is not run until InitialDelaySeconds after this
+	// time.
+	//
+	// +optional
+	PoweredOnTime *metav1.Time `json:"poweredOnTime,omitempty"`
+}
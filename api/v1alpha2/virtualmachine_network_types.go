@@ -429,6 +429,19 @@ type VirtualMachineNetworkIPStackStatus struct {
 	KernelConfig []common.KeyValuePair `json:"kernelConfig,omitempty"`
 }
 
+// VirtualMachineNetworkInterfacePendingStatus describes a network interface
+// that was hot-added to a powered on VM and is pending configuration in the
+// guest.
+type VirtualMachineNetworkInterfacePendingStatus struct {
+	// Name is the name of the interface from the VM's spec.
+	Name string `json:"name"`
+
+	// MACAddr is the MAC address of the interface's device.
+	//
+	// +optional
+	MACAddr string `json:"macAddr,omitempty"`
+}
+
 // VirtualMachineNetworkStatus defines the observed state of a VM's
 // network configuration.
 type VirtualMachineNetworkStatus struct {
@@ -440,6 +453,21 @@ type VirtualMachineNetworkStatus struct {
 	// +listMapKey=name
 	Interfaces []VirtualMachineNetworkInterfaceStatus `json:"interfaces,omitempty"`
 
+	// PendingGuestConfig describes the network interfaces that were hot-added
+	// to the powered on VM and whose IP configuration has not yet been
+	// observed in the guest.
+	//
+	// If the bootstrap provider is CloudInit then the network configuration
+	// for the hot-added interfaces is pushed to the guest by updating the
+	// VM's cloud-init metadata. Please note the guest's cloud-init must be
+	// configured to handle hotplug events for the configuration to be
+	// applied without a reboot.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	PendingGuestConfig []VirtualMachineNetworkInterfacePendingStatus `json:"pendingGuestConfig,omitempty"`
+
 	// PrimaryIP4 describes the VM's primary IP4 address.
 	//
 	// If the bootstrap provider is CloudInit then this value is set to the
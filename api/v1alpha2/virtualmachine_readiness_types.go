@@ -17,6 +17,10 @@ type VirtualMachineReadinessProbeSpec struct {
 	// +optional
 	TCPSocket *TCPSocketAction `json:"tcpSocket,omitempty"`
 
+	// HTTPGet specifies an action involving an HTTP GET request.
+	// +optional
+	HTTPGet *HTTPGetAction `json:"httpGet,omitempty"`
+
 	// GuestHeartbeat specifies an action involving the guest heartbeat status.
 	// +optional
 	GuestHeartbeat *GuestHeartbeatAction `json:"guestHeartbeat,omitempty"`
@@ -75,6 +79,78 @@ type TCPSocketAction struct {
 	Host string `json:"host,omitempty"`
 }
 
+// URIScheme identifies the scheme used for connection to a host for an
+// HTTPGetAction.
+type URIScheme string
+
+const (
+	// URISchemeHTTP means that the scheme used will be http://.
+	URISchemeHTTP URIScheme = "HTTP"
+	// URISchemeHTTPS means that the scheme used will be https://.
+	URISchemeHTTPS URIScheme = "HTTPS"
+)
+
+// HTTPHeader describes a custom header to be used in HTTP probes.
+type HTTPHeader struct {
+	// Name is the header field name.
+	Name string `json:"name"`
+
+	// Value is the header field value.
+	Value string `json:"value"`
+}
+
+// HTTPStatusCodeRange describes an inclusive range of HTTP status codes.
+type HTTPStatusCodeRange struct {
+	// Min is the lowest status code in the range.
+	// +kubebuilder:validation:Minimum:=100
+	// +kubebuilder:validation:Maximum:=599
+	Min int32 `json:"min"`
+
+	// Max is the highest status code in the range.
+	// +kubebuilder:validation:Minimum:=100
+	// +kubebuilder:validation:Maximum:=599
+	Max int32 `json:"max"`
+}
+
+// HTTPGetAction describes an action based on HTTP GET requests.
+type HTTPGetAction struct {
+	// Path specifies the path to access on the HTTP server.
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// Port specifies a number or name of the port to access on the VM.
+	// If the format of port is a number, it must be in the range 1 to 65535.
+	// If the format of name is a string, it must be an IANA_SVC_NAME.
+	Port intstr.IntOrString `json:"port"`
+
+	// Host is an optional IP of the VM to connect to. Host defaults to the VM
+	// IP. The probe fails if Host is not one of the IPs in the VM's network
+	// status.
+	// +optional
+	Host string `json:"host,omitempty"`
+
+	// Scheme specifies the scheme to use for connecting to the host.
+	// Defaults to HTTP.
+	//
+	// Please note the server's certificate is not verified when the scheme
+	// is HTTPS.
+	//
+	// +optional
+	// +kubebuilder:default=HTTP
+	// +kubebuilder:validation:Enum=HTTP;HTTPS
+	Scheme URIScheme `json:"scheme,omitempty"`
+
+	// HTTPHeaders specifies custom headers to set in the request. HTTP allows
+	// repeated headers.
+	// +optional
+	HTTPHeaders []HTTPHeader `json:"httpHeaders,omitempty"`
+
+	// ExpectedStatus specifies the range of HTTP status codes that indicate
+	// success. Defaults to 200-399.
+	// +optional
+	ExpectedStatus *HTTPStatusCodeRange `json:"expectedStatus,omitempty"`
+}
+
 // GuestHeartbeatStatus is the guest heartbeat status.
 type GuestHeartbeatStatus string
 
@@ -20,6 +20,44 @@ const (
 	VirtualMachineVolumeProvisioningModeThickEagerZero VirtualMachineVolumeProvisioningMode = "ThickEagerZero"
 )
 
+// VirtualMachineVolumeControllerType is the type of the controller to which a
+// volume is attached.
+//
+// +kubebuilder:validation:Enum=ParaVirtualSCSI;LsiLogic;LsiLogicSAS;BusLogic;NVME;SATA
+type VirtualMachineVolumeControllerType string
+
+const (
+	VirtualMachineVolumeControllerTypeParaVirtualSCSI VirtualMachineVolumeControllerType = "ParaVirtualSCSI"
+	VirtualMachineVolumeControllerTypeLsiLogic        VirtualMachineVolumeControllerType = "LsiLogic"
+	VirtualMachineVolumeControllerTypeLsiLogicSAS     VirtualMachineVolumeControllerType = "LsiLogicSAS"
+	VirtualMachineVolumeControllerTypeBusLogic        VirtualMachineVolumeControllerType = "BusLogic"
+	VirtualMachineVolumeControllerTypeNVME            VirtualMachineVolumeControllerType = "NVME"
+	VirtualMachineVolumeControllerTypeSATA            VirtualMachineVolumeControllerType = "SATA"
+)
+
+// VirtualMachineVolumeDiskMode is the mode of the disk backing a volume,
+// which determines whether the disk is affected by snapshots.
+//
+// +kubebuilder:validation:Enum=Persistent;IndependentPersistent;IndependentNonPersistent
+type VirtualMachineVolumeDiskMode string
+
+const (
+	VirtualMachineVolumeDiskModePersistent               VirtualMachineVolumeDiskMode = "Persistent"
+	VirtualMachineVolumeDiskModeIndependentPersistent    VirtualMachineVolumeDiskMode = "IndependentPersistent"
+	VirtualMachineVolumeDiskModeIndependentNonPersistent VirtualMachineVolumeDiskMode = "IndependentNonPersistent"
+)
+
+// VirtualMachineVolumeSharingMode is the sharing mode of the disk backing a
+// volume.
+//
+// +kubebuilder:validation:Enum=None;MultiWriter
+type VirtualMachineVolumeSharingMode string
+
+const (
+	VirtualMachineVolumeSharingModeNone        VirtualMachineVolumeSharingMode = "None"
+	VirtualMachineVolumeSharingModeMultiWriter VirtualMachineVolumeSharingMode = "MultiWriter"
+)
+
 // VirtualMachineVolume represents a named volume in a VM.
 type VirtualMachineVolume struct {
 	// Name represents the volume's name. Must be a DNS_LABEL and unique within
@@ -42,6 +80,55 @@ type VirtualMachineVolumeSource struct {
 	//
 	// +optional
 	PersistentVolumeClaim *PersistentVolumeClaimVolumeSource `json:"persistentVolumeClaim,omitempty"`
+
+	// Ephemeral represents a scratch disk that is created with the VM and
+	// deleted with the VM. The disk is created as part of the VM's
+	// configuration rather than by CNS, so it cannot be detached from the VM.
+	//
+	// +optional
+	Ephemeral *EphemeralVolumeSource `json:"ephemeral,omitempty"`
+
+	// Image represents a data disk whose content is cloned from a disk of a
+	// VirtualMachineImage. The disk is attached in independent non-persistent
+	// mode, so the guest may write to the disk, but the writes are discarded
+	// when the VM is powered off. The disk is created with the VM and deleted
+	// with the VM.
+	//
+	// +optional
+	Image *ImageVolumeSource `json:"image,omitempty"`
+}
+
+// EphemeralVolumeSource describes a scratch disk that lives and dies with the
+// VM.
+type EphemeralVolumeSource struct {
+	// StorageClass is the name of the Kubernetes StorageClass that provides
+	// the backing storage for the disk.
+	StorageClass string `json:"storageClass"`
+
+	// Size is the capacity of the disk.
+	Size resource.Quantity `json:"size"`
+}
+
+// ImageVolumeSource describes a data disk whose content is cloned from a disk
+// of a VirtualMachineImage.
+type ImageVolumeSource struct {
+	// Name is the name of the VirtualMachineImage or
+	// ClusterVirtualMachineImage whose disk is cloned. The image is resolved
+	// the same way as the VM's spec.imageName.
+	Name string `json:"name"`
+
+	// DiskIndex is the index of the disk in the image whose content is
+	// cloned. Defaults to 0, the first disk of the image.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	DiskIndex int32 `json:"diskIndex,omitempty"`
+
+	// StorageClass is the name of the Kubernetes StorageClass that provides
+	// the backing storage for the disk. Defaults to the VM's storage class.
+	//
+	// +optional
+	StorageClass string `json:"storageClass,omitempty"`
 }
 
 // PersistentVolumeClaimVolumeSource is a composite for the Kubernetes
@@ -52,6 +139,45 @@ type PersistentVolumeClaimVolumeSource struct {
 	// InstanceVolumeClaim is set if the PVC is backed by instance storage.
 	// +optional
 	InstanceVolumeClaim *InstanceVolumeClaimVolumeSource `json:"instanceVolumeClaim,omitempty"`
+
+	// ControllerType describes the type of the controller to which the volume
+	// is attached. When omitted, the volume remains on the controller selected
+	// when the volume is attached.
+	//
+	// The attach options are applied to the volume's disk before the VM is
+	// powered on. They are not applied to a volume hot-attached to a powered
+	// on VM, so they cannot be specified for a volume added to a powered on
+	// VM.
+	// +optional
+	ControllerType VirtualMachineVolumeControllerType `json:"controllerType,omitempty"`
+
+	// ControllerBusNumber describes the bus number of the controller, of the
+	// type specified by ControllerType, to which the volume is attached. The
+	// controller is added to the VM if it does not exist. When omitted, the
+	// first controller of the type is used. ControllerType is required when
+	// ControllerBusNumber is specified.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=3
+	ControllerBusNumber *int32 `json:"controllerBusNumber,omitempty"`
+
+	// UnitNumber describes the unit number of the volume on its controller.
+	// When omitted, a free unit is used. ControllerType and ControllerBusNumber
+	// are required when UnitNumber is specified.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	UnitNumber *int32 `json:"unitNumber,omitempty"`
+
+	// DiskMode describes the mode of the disk backing the volume. Defaults to
+	// Persistent.
+	// +optional
+	DiskMode VirtualMachineVolumeDiskMode `json:"diskMode,omitempty"`
+
+	// SharingMode describes the sharing mode of the disk backing the volume.
+	// The MultiWriter sharing mode allows the volume to be attached to
+	// multiple VMs, for example for clustered filesystems. Defaults to None.
+	// +optional
+	SharingMode VirtualMachineVolumeSharingMode `json:"sharingMode,omitempty"`
 }
 
 // InstanceVolumeClaimVolumeSource contains information about the instance
@@ -65,6 +191,20 @@ type InstanceVolumeClaimVolumeSource struct {
 	Size resource.Quantity `json:"size"`
 }
 
+// VirtualMachineVolumeResizeState is the type used to express the state of
+// the expansion of a volume attached to a VM.
+type VirtualMachineVolumeResizeState string
+
+const (
+	// VirtualMachineVolumeResizeInProgress indicates the volume is being
+	// expanded.
+	VirtualMachineVolumeResizeInProgress VirtualMachineVolumeResizeState = "InProgress"
+
+	// VirtualMachineVolumeResizeFileSystemPending indicates the volume has
+	// been expanded, but the file system on the volume has yet to be resized.
+	VirtualMachineVolumeResizeFileSystemPending VirtualMachineVolumeResizeState = "FileSystemResizePending"
+)
+
 // VirtualMachineVolumeStatus defines the observed state of a
 // VirtualMachineVolume instance.
 type VirtualMachineVolumeStatus struct {
@@ -85,4 +225,22 @@ type VirtualMachineVolumeStatus struct {
 	// volume.  Error will be empty if attachment succeeds.
 	// +optional
 	Error string `json:"error,omitempty"`
+
+	// RequestedCapacity is the storage capacity requested by the volume's
+	// PersistentVolumeClaim.
+	// +optional
+	RequestedCapacity *resource.Quantity `json:"requestedCapacity,omitempty"`
+
+	// AttachedCapacity is the storage capacity of the volume attached to the
+	// VM. When the volume is expanded, AttachedCapacity is updated to the
+	// RequestedCapacity once the online expansion of the attached disk has
+	// finished.
+	// +optional
+	AttachedCapacity *resource.Quantity `json:"attachedCapacity,omitempty"`
+
+	// ResizeState describes the expansion of the volume while the
+	// AttachedCapacity is less than the RequestedCapacity. ResizeState is
+	// empty when the volume is not being expanded.
+	// +optional
+	ResizeState VirtualMachineVolumeResizeState `json:"resizeState,omitempty"`
 }
@@ -41,6 +41,102 @@ const (
 
 	// VirtualMachineConditionCreated indicates that the VM has been created.
 	VirtualMachineConditionCreated = "VirtualMachineCreated"
+
+	// VirtualMachineConditionSnapshotReverted indicates that the VM has been
+	// reverted to the snapshot specified by spec.currentSnapshotName.
+	VirtualMachineConditionSnapshotReverted = "VirtualMachineSnapshotReverted"
+)
+
+const (
+	// VirtualMachineConditionResized exposes whether the VM's CPU and memory
+	// match the VirtualMachineClass specified by spec.className.
+	//
+	// The condition's status is set to true once the VM has been resized to
+	// the class referenced by status.class.
+	VirtualMachineConditionResized = "VirtualMachineResized"
+
+	// VirtualMachineResizePendingReason documents that the VM is not powered
+	// on and will be resized to the new class the next time it is powered on.
+	VirtualMachineResizePendingReason = "ResizePending"
+
+	// VirtualMachineResizeRestartRequiredReason documents that the VM is
+	// powered on and the resize cannot be hot-added, so the VM will only be
+	// resized to the new class after it is powered off and back on.
+	VirtualMachineResizeRestartRequiredReason = "RestartRequired"
+)
+
+const (
+	// VirtualMachineConditionBootDiskResized exposes whether the VM's boot
+	// disk has the capacity specified by spec.advanced.bootDiskCapacity.
+	VirtualMachineConditionBootDiskResized = "VirtualMachineBootDiskResized"
+
+	// VirtualMachineBootDiskShrinkNotSupportedReason documents that the boot
+	// disk capacity is less than the capacity of the VM's boot disk. Disks
+	// cannot be shrunk so the capacity is ignored.
+	VirtualMachineBootDiskShrinkNotSupportedReason = "ShrinkNotSupported"
+)
+
+const (
+	// VirtualMachineConditionStorageRelocated exposes whether the VM's home
+	// and boot disks reside on a datastore compatible with the storage policy
+	// of the StorageClass specified by spec.storageClass.
+	//
+	// The condition's status is set to true once the VM has been relocated to
+	// the storage class referenced by status.storageClass and its storage is
+	// compliant with the policy.
+	VirtualMachineConditionStorageRelocated = "VirtualMachineStorageRelocated"
+
+	// VirtualMachineStorageRelocationInProgressReason documents that the VM's
+	// storage is being relocated to a datastore compatible with the new
+	// storage class. The condition's message includes the task's progress.
+	VirtualMachineStorageRelocationInProgressReason = "RelocationInProgress"
+
+	// VirtualMachineStorageRelocationFailedReason documents that the
+	// relocation of the VM's storage failed.
+	VirtualMachineStorageRelocationFailedReason = "RelocationFailed"
+
+	// VirtualMachineStorageNotCompliantReason documents that the relocation
+	// completed but the VM's storage is not compliant with the storage
+	// policy of the new storage class.
+	VirtualMachineStorageNotCompliantReason = "NotCompliant"
+)
+
+const (
+	// VirtualMachineConditionZoneMigrated exposes whether the VM has been
+	// migrated to the availability zone specified by the VM's
+	// topology.kubernetes.io/zone label.
+	//
+	// The condition's status is set to true once the VM has been migrated to
+	// the zone referenced by status.zone.
+	VirtualMachineConditionZoneMigrated = "VirtualMachineZoneMigrated"
+
+	// VirtualMachineZoneMigrationInProgressReason documents that the VM is
+	// being migrated to a cluster in the new zone. The condition's message
+	// includes the task's progress.
+	VirtualMachineZoneMigrationInProgressReason = "MigrationInProgress"
+
+	// VirtualMachineZoneMigrationFailedReason documents that the migration of
+	// the VM to the new zone failed.
+	VirtualMachineZoneMigrationFailedReason = "MigrationFailed"
+)
+
+const (
+	// VirtualMachineConditionImageVolumesCopied exposes whether the disks of
+	// the VM's image volumes have been copied from their images.
+	//
+	// The condition's status is set to true once the disks of all of the VM's
+	// image volumes have been copied and added to the VM. The VM is not
+	// powered on until then.
+	VirtualMachineConditionImageVolumesCopied = "VirtualMachineImageVolumesCopied"
+
+	// VirtualMachineImageVolumeCopyInProgressReason documents that the disk of
+	// an image volume is being copied from its image. The condition's message
+	// includes the task's progress.
+	VirtualMachineImageVolumeCopyInProgressReason = "CopyInProgress"
+
+	// VirtualMachineImageVolumeCopyFailedReason documents that the copy of the
+	// disk of an image volume failed.
+	VirtualMachineImageVolumeCopyFailedReason = "CopyFailed"
 )
 
 const (
@@ -107,6 +203,12 @@ const (
 	// booted at least once. This annotation cannot be set by users and will not
 	// be removed once set until the VM is deleted.
 	FirstBootDoneAnnotation = "virtualmachine." + GroupName + "/first-boot-done"
+
+	// NetworkSpecHashAnnotation is an annotation that records the hash of the
+	// network spec the ethernet cards of the powered on VM were most recently
+	// reconfigured with. It is used to only reconfigure the VM's network when
+	// the network spec changes. This annotation cannot be set by users.
+	NetworkSpecHashAnnotation = "virtualmachine." + GroupName + "/network-spec-hash"
 )
 
 // VirtualMachine backup/restore related constants.
@@ -177,6 +279,32 @@ const (
 	VirtualMachinePowerOpModeTrySoft VirtualMachinePowerOpMode = "TrySoft"
 )
 
+// VirtualMachinePort describes a named port that a service running in the VM's
+// guest listens on.
+type VirtualMachinePort struct {
+	// Name is the name of the port. A VirtualMachineService may refer to the
+	// port by this name in the targetPort of its ports.
+	//
+	// The name must be an IANA_SVC_NAME, i.e. at most 15 lowercase
+	// alphanumeric characters or '-', with at least one letter, and unique
+	// among the ports of the VM.
+	Name string `json:"name"`
+
+	// Port is the number of the port in the guest.
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port"`
+
+	// Protocol is the Layer 4 transport protocol of the port. Supports "TCP",
+	// "UDP", and "SCTP". Defaults to "TCP".
+	//
+	// +optional
+	// +kubebuilder:default=TCP
+	// +kubebuilder:validation:Enum=TCP;UDP;SCTP
+	Protocol string `json:"protocol,omitempty"`
+}
+
 // VirtualMachineSpec defines the desired state of a VirtualMachine.
 type VirtualMachineSpec struct {
 	// ImageName describes the name of the image resource used to deploy this
@@ -203,6 +331,13 @@ type VirtualMachineSpec struct {
 	// default value, such as when there is a single VirtualMachineClass
 	// resource available in the same Namespace as the VM being deployed.
 	//
+	// Changing this field resizes the VM's CPU and memory, as well as their
+	// reservations and limits, to those of the new class. The resize is
+	// hot-added when the VM is powered on and its guest and hardware version
+	// allow it, otherwise it is applied the next time the VM is powered on.
+	// Please refer to the VirtualMachineResized condition for the progress of
+	// the resize.
+	//
 	// +optional
 	ClassName string `json:"className,omitempty"`
 
@@ -328,11 +463,46 @@ type VirtualMachineSpec struct {
 	// +listMapKey=name
 	Volumes []VirtualMachineVolume `json:"volumes,omitempty"`
 
+	// Ports is the list of named ports that services in the VM's guest listen
+	// on. A VirtualMachineService that selects the VM may refer to one of these
+	// ports by name in its targetPort, so that the port is resolved for each
+	// VM. This allows a VirtualMachineService to front VMs that listen on
+	// different ports.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Ports []VirtualMachinePort `json:"ports,omitempty"`
+
 	// ReadinessProbe describes a probe used to determine the VM's ready state.
 	//
 	// +optional
 	ReadinessProbe *VirtualMachineReadinessProbeSpec `json:"readinessProbe,omitempty"`
 
+	// LivenessProbe describes a probe used to determine if the VM is alive.
+	//
+	// When the probe fails FailureThreshold times in a row, the VM is
+	// restarted in accordance with RestartMode unless the probe's
+	// RestartPolicy is Never.
+	//
+	// +optional
+	LivenessProbe *VirtualMachineLivenessProbeSpec `json:"livenessProbe,omitempty"`
+
+	// CurrentSnapshotName describes the name of a VirtualMachineSnapshot
+	// resource, in the same Namespace as the VM, to which the VM should be
+	// reverted.
+	//
+	// When this value differs from the name of the snapshot referenced by
+	// status.currentSnapshot, the VM is reverted to the specified snapshot.
+	// If the snapshot does not include the VM's memory, the VM is powered off
+	// as part of the revert and then brought back to spec.powerState.
+	//
+	// To revert the VM to the same snapshot again, first clear this field and
+	// then set it back to the name of the snapshot.
+	//
+	// +optional
+	CurrentSnapshotName string `json:"currentSnapshotName,omitempty"`
+
 	// Advanced describes a set of optional, advanced VM configuration options.
 	// +optional
 	Advanced *VirtualMachineAdvancedSpec `json:"advanced,omitempty"`
@@ -403,11 +573,21 @@ type VirtualMachineAdvancedSpec struct {
 	// BootDiskCapacity is the capacity of the VM's boot disk -- the first disk
 	// from the VirtualMachineImage from which the VM was deployed.
 	//
-	// Please note it is not advised to change this value while the VM is
-	// running. Also, resizing the VM's boot disk may require actions inside of
-	// the guest to take advantage of the additional capacity. Finally, changing
-	// the size of the VM's boot disk, even increasing it, could adversely
-	// affect the VM.
+	// The capacity may be increased after the VM is deployed, in which case the
+	// boot disk is extended in place, even while the VM is running. The
+	// capacity may not be decreased, nor be less than the capacity of the
+	// image's boot disk. If the capacity is less than that of the VM's boot
+	// disk, for example because the disk was extended outside of VM Service,
+	// the capacity is ignored and the VirtualMachineBootDiskResized condition
+	// reports that the disk cannot be shrunk.
+	//
+	// Please note resizing the VM's boot disk may require actions inside of the
+	// guest to take advantage of the additional capacity. The new capacity, in
+	// bytes, is published to the guest with the ExtraConfig key
+	// guestinfo.vmservice.boot-disk-capacity, and cloud-init's growpart and
+	// resizefs modules grow the root partition and filesystem on the next boot.
+	// Finally, changing the size of the VM's boot disk, even increasing it,
+	// could adversely affect the VM.
 	//
 	// +optional
 	BootDiskCapacity *resource.Quantity `json:"bootDiskCapacity,omitempty"`
@@ -435,11 +615,19 @@ type VirtualMachineStatus struct {
 	Image *common.LocalObjectRef `json:"image,omitempty"`
 
 	// Class is a reference to the VirtualMachineClass resource used to deploy
-	// this VM.
+	// this VM, or the class the VM was most recently resized to after a change
+	// to spec.className.
 	//
 	// +optional
 	Class *common.LocalObjectRef `json:"class,omitempty"`
 
+	// StorageClass is the name of the StorageClass whose storage policy the
+	// VM's home and boot disks were placed with, or the storage class they
+	// were most recently relocated to after a change to spec.storageClass.
+	//
+	// +optional
+	StorageClass string `json:"storageClass,omitempty"`
+
 	// Host describes the hostname or IP address of the infrastructure host
 	// where the VM is executed.
 	//
@@ -492,7 +680,8 @@ type VirtualMachineStatus struct {
 	ChangeBlockTracking *bool `json:"changeBlockTracking,omitempty"`
 
 	// Zone describes the availability zone where the VirtualMachine has been
-	// scheduled.
+	// scheduled, or the zone the VM was most recently migrated to after a
+	// change to the topology.kubernetes.io/zone label.
 	//
 	// Please note this field may be empty when the cluster is not zone-aware.
 	//
@@ -512,6 +701,31 @@ type VirtualMachineStatus struct {
 	//
 	// +optional
 	HardwareVersion int32 `json:"hardwareVersion,omitempty"`
+
+	// CurrentSnapshot is a reference to the VirtualMachineSnapshot resource
+	// to which the VM was most recently reverted by way of
+	// spec.currentSnapshotName.
+	//
+	// +optional
+	CurrentSnapshot *common.LocalObjectRef `json:"currentSnapshot,omitempty"`
+
+	// Liveness describes the observed state of the VM's liveness probe.
+	//
+	// +optional
+	Liveness *VirtualMachineLivenessStatus `json:"liveness,omitempty"`
+
+	// Guest describes the observed resource usage of the VM, including the
+	// usage of the filesystems in its guest.
+	//
+	// +optional
+	Guest *VirtualMachineGuestStatus `json:"guest,omitempty"`
+
+	// GuestCustomization describes the observed state of guest customization
+	// in the VM's guest OS, including the error and log location when it
+	// failed.
+	//
+	// +optional
+	GuestCustomization *VirtualMachineGuestCustomizationStatus `json:"guestCustomization,omitempty"`
 }
 
 // +kubebuilder:object:root=true
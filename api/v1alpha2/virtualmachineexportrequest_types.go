@@ -0,0 +1,307 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// VirtualMachineExportRequestConditionSourceValid is the Type for a
+	// VirtualMachineExportRequest resource's status condition.
+	//
+	// The condition's status is set to true only when the VM to export
+	// exists, has been created on the underlying infrastructure and is
+	// powered off.
+	VirtualMachineExportRequestConditionSourceValid = "SourceValid"
+
+	// VirtualMachineExportRequestConditionTargetValid is the Type for a
+	// VirtualMachineExportRequest resource's status condition.
+	//
+	// The condition's status is set to true only when the target the VM is
+	// exported to is ready to be written.
+	VirtualMachineExportRequestConditionTargetValid = "TargetValid"
+
+	// VirtualMachineExportRequestConditionExported is the Type for a
+	// VirtualMachineExportRequest resource's status condition.
+	//
+	// The condition's status is set to true only when all the files of the
+	// exported VM have been written to the target.
+	VirtualMachineExportRequestConditionExported = "Exported"
+
+	// VirtualMachineExportRequestConditionComplete is the Type for a
+	// VirtualMachineExportRequest resource's status condition.
+	//
+	// The condition's status is set to true once the export has either
+	// succeeded or failed.
+	VirtualMachineExportRequestConditionComplete = "Complete"
+)
+
+// Condition.Reason for Conditions related to VirtualMachineExportRequest.
+// The reasons SourceVirtualMachineNotExistReason and
+// SourceVirtualMachineNotCreatedReason are also used.
+const (
+	// SourceVirtualMachinePoweredOnReason documents that the VM to export is
+	// not powered off.
+	SourceVirtualMachinePoweredOnReason = "SourceVirtualMachinePoweredOn"
+
+	// TargetPersistentVolumeClaimNotExistReason documents that the
+	// PersistentVolumeClaim the VM is exported to does not exist.
+	TargetPersistentVolumeClaimNotExistReason = "TargetPersistentVolumeClaimNotExist"
+
+	// TargetTransferImageNotConfiguredReason documents that the image of the
+	// Pod that writes the exported files to a PersistentVolumeClaim, or the
+	// ClusterRole that allows VM Operator to manage the Pod, is not
+	// configured.
+	TargetTransferImageNotConfiguredReason = "TargetTransferImageNotConfigured"
+
+	// TargetTransferPodNotReadyReason documents that the Pod that writes the
+	// exported files to a PersistentVolumeClaim is not ready.
+	TargetTransferPodNotReadyReason = "TargetTransferPodNotReady"
+
+	// TargetCredentialsInvalidReason documents that the Secret with the
+	// credentials of the object store the VM is exported to does not exist
+	// or is missing a key.
+	TargetCredentialsInvalidReason = "TargetCredentialsInvalid"
+
+	// TargetObjectStoreEndpointNotAllowedReason documents that the object
+	// store the VM is exported to is not one of the object stores that VMs
+	// may be exported to.
+	TargetObjectStoreEndpointNotAllowedReason = "TargetObjectStoreEndpointNotAllowed"
+
+	// ExportingReason documents that the VM is being exported.
+	ExportingReason = "Exporting"
+
+	// ExportFailureReason documents that the VM could not be exported.
+	ExportFailureReason = "ExportFailure"
+)
+
+const (
+	// VirtualMachineExportObjectStoreAccessKeyIDKey is the key in the Secret
+	// referenced by an object store target that contains the access key ID.
+	VirtualMachineExportObjectStoreAccessKeyIDKey = "accessKeyID"
+
+	// VirtualMachineExportObjectStoreSecretAccessKeyKey is the key in the
+	// Secret referenced by an object store target that contains the secret
+	// access key.
+	VirtualMachineExportObjectStoreSecretAccessKeyKey = "secretAccessKey"
+)
+
+// VirtualMachineExportFormat is the format a VM is exported to.
+//
+// +kubebuilder:validation:Enum=OVF;OVA
+type VirtualMachineExportFormat string
+
+const (
+	// VirtualMachineExportFormatOVF exports the VM as an OVF descriptor, a
+	// manifest and the disks as separate files.
+	VirtualMachineExportFormatOVF VirtualMachineExportFormat = "OVF"
+
+	// VirtualMachineExportFormatOVA exports the VM as a single OVA file, a
+	// tar archive of the OVF descriptor, the manifest and the disks.
+	VirtualMachineExportFormatOVA VirtualMachineExportFormat = "OVA"
+)
+
+// VirtualMachineExportRequestSource is the source of an export request.
+type VirtualMachineExportRequestSource struct {
+	// Name is the name of the VirtualMachine to export.
+	Name string `json:"name"`
+
+	// APIVersion is the API version of the referenced object.
+	//
+	// +kubebuilder:default=vmoperator.vmware.com/v1alpha2
+	// +optional
+	APIVersion string `json:"apiVersion,omitempty"`
+
+	// Kind is the kind of referenced object.
+	//
+	// +kubebuilder:default=VirtualMachine
+	// +optional
+	Kind string `json:"kind,omitempty"`
+}
+
+// VirtualMachineExportPersistentVolumeClaimTarget describes a
+// PersistentVolumeClaim the exported files are written to.
+type VirtualMachineExportPersistentVolumeClaimTarget struct {
+	// ClaimName is the name of a PersistentVolumeClaim in the same namespace
+	// as the export request.
+	ClaimName string `json:"claimName"`
+
+	// Path is the directory in the volume the exported files are written
+	// to. Defaults to the name of the export request.
+	//
+	// +optional
+	Path string `json:"path,omitempty"`
+}
+
+// VirtualMachineExportObjectStoreTarget describes an S3-compatible object
+// store the exported files are written to.
+type VirtualMachineExportObjectStoreTarget struct {
+	// Endpoint is the URL of the object store, ex. https://s3.example.com.
+	// The object store must be one of the object stores that VMs may be
+	// exported to, which are configured by the administrator.
+	Endpoint string `json:"endpoint"`
+
+	// Bucket is the name of the bucket the exported files are written to.
+	Bucket string `json:"bucket"`
+
+	// Prefix is the prefix of the keys of the exported files. Defaults to
+	// the name of the export request.
+	//
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// Region is the region used to sign the requests to the object store.
+	//
+	// +kubebuilder:default=us-east-1
+	// +optional
+	Region string `json:"region,omitempty"`
+
+	// CredentialsSecretName is the name of a Secret in the same namespace as
+	// the export request that contains the accessKeyID and secretAccessKey
+	// keys used to access the object store.
+	CredentialsSecretName string `json:"credentialsSecretName"`
+}
+
+// VirtualMachineExportRequestTarget is the target of an export request.
+// Exactly one of the fields must be set.
+type VirtualMachineExportRequestTarget struct {
+	// PersistentVolumeClaim writes the exported files to a
+	// PersistentVolumeClaim.
+	//
+	// +optional
+	PersistentVolumeClaim *VirtualMachineExportPersistentVolumeClaimTarget `json:"persistentVolumeClaim,omitempty"`
+
+	// ObjectStore writes the exported files to an S3-compatible object
+	// store.
+	//
+	// +optional
+	ObjectStore *VirtualMachineExportObjectStoreTarget `json:"objectStore,omitempty"`
+}
+
+// VirtualMachineExportRequestSpec defines the desired state of a
+// VirtualMachineExportRequest.
+type VirtualMachineExportRequestSpec struct {
+	// Source is the VM to export. The VM must be powered off.
+	Source VirtualMachineExportRequestSource `json:"source"`
+
+	// Format is the format the VM is exported to.
+	//
+	// +kubebuilder:default=OVF
+	// +optional
+	Format VirtualMachineExportFormat `json:"format,omitempty"`
+
+	// Target is where the exported files are written.
+	Target VirtualMachineExportRequestTarget `json:"target"`
+}
+
+// VirtualMachineExportFile describes a file of an exported VM.
+type VirtualMachineExportFile struct {
+	// Name is the name of the file.
+	Name string `json:"name"`
+
+	// Size is the size of the file in bytes.
+	Size int64 `json:"size"`
+
+	// Checksum is the SHA256 checksum of the file in hex.
+	Checksum string `json:"checksum"`
+}
+
+// VirtualMachineExportRequestStatus defines the observed state of a
+// VirtualMachineExportRequest.
+type VirtualMachineExportRequestStatus struct {
+	// StartTime represents time when the request was acknowledged by the
+	// controller.
+	//
+	// +optional
+	StartTime metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime represents time when the request was completed.
+	//
+	// The value of this field should be equal to the value of the
+	// LastTransitionTime for the status condition Type=Complete.
+	//
+	// +optional
+	CompletionTime metav1.Time `json:"completionTime,omitempty"`
+
+	// Progress is the estimated percentage of the export that is complete.
+	//
+	// +optional
+	Progress int32 `json:"progress,omitempty"`
+
+	// Files describes the files written to the target. For the OVA format,
+	// these are the files contained in the OVA file.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Files []VirtualMachineExportFile `json:"files,omitempty"`
+
+	// Manifest is the content of the OVF manifest of the exported VM.
+	//
+	// +optional
+	Manifest string `json:"manifest,omitempty"`
+
+	// Ready is set to true only when the VM has been successfully exported.
+	//
+	// +optional
+	Ready bool `json:"ready,omitempty"`
+
+	// Conditions describes the observed conditions of the
+	// VirtualMachineExportRequest.
+	//
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Namespaced,shortName=vmexport
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="VirtualMachine",type="string",JSONPath=".spec.source.name"
+// +kubebuilder:printcolumn:name="Format",type="string",JSONPath=".spec.format"
+// +kubebuilder:printcolumn:name="Progress",type="integer",JSONPath=".status.progress"
+// +kubebuilder:printcolumn:name="Ready",type="boolean",JSONPath=".status.ready"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// VirtualMachineExportRequest is the schema for the
+// virtualmachineexportrequests API and represents a request to export a VM
+// to an OVF or OVA on a PersistentVolumeClaim or an S3-compatible object
+// store.
+type VirtualMachineExportRequest struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VirtualMachineExportRequestSpec   `json:"spec,omitempty"`
+	Status VirtualMachineExportRequestStatus `json:"status,omitempty"`
+}
+
+func (r *VirtualMachineExportRequest) NamespacedName() string {
+	return r.Namespace + "/" + r.Name
+}
+
+func (r *VirtualMachineExportRequest) GetConditions() []metav1.Condition {
+	return r.Status.Conditions
+}
+
+func (r *VirtualMachineExportRequest) SetConditions(conditions []metav1.Condition) {
+	r.Status.Conditions = conditions
+}
+
+// +kubebuilder:object:root=true
+
+// VirtualMachineExportRequestList contains a list of
+// VirtualMachineExportRequest resources.
+type VirtualMachineExportRequestList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VirtualMachineExportRequest `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(
+		&VirtualMachineExportRequest{},
+		&VirtualMachineExportRequestList{},
+	)
+}
@@ -0,0 +1,291 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/vmware-tanzu/vm-operator/api/v1alpha2/common"
+)
+
+const (
+	// VirtualMachineGuestCommandRequestConditionSourceValid is the Type for a
+	// VirtualMachineGuestCommandRequest resource's status condition.
+	//
+	// The condition's status is set to true only when the VM the command is
+	// run in exists, has been created on the underlying infrastructure, is
+	// powered on and has VMware Tools running.
+	VirtualMachineGuestCommandRequestConditionSourceValid = "SourceValid"
+
+	// VirtualMachineGuestCommandRequestConditionInputsValid is the Type for a
+	// VirtualMachineGuestCommandRequest resource's status condition.
+	//
+	// The condition's status is set to true only when the guest credentials
+	// and the data of the files to upload have been read.
+	VirtualMachineGuestCommandRequestConditionInputsValid = "InputsValid"
+
+	// VirtualMachineGuestCommandRequestConditionSucceeded is the Type for a
+	// VirtualMachineGuestCommandRequest resource's status condition.
+	//
+	// The condition's status is set to true only when the command exited
+	// with an exit code of zero.
+	VirtualMachineGuestCommandRequestConditionSucceeded = "Succeeded"
+
+	// VirtualMachineGuestCommandRequestConditionComplete is the Type for a
+	// VirtualMachineGuestCommandRequest resource's status condition.
+	//
+	// The condition's status is set to true once the command has either
+	// succeeded or failed.
+	VirtualMachineGuestCommandRequestConditionComplete = "Complete"
+)
+
+// Condition.Reason for Conditions related to VirtualMachineGuestCommandRequest.
+// The reasons SourceVirtualMachineNotExistReason and
+// SourceVirtualMachineNotCreatedReason are also used.
+const (
+	// SourceVirtualMachinePoweredOffReason documents that the VM the command
+	// is run in is not powered on.
+	SourceVirtualMachinePoweredOffReason = "SourceVirtualMachinePoweredOff"
+
+	// SourceVirtualMachineToolsNotRunningReason documents that VMware Tools is
+	// not running in the guest of the VM the command is run in.
+	SourceVirtualMachineToolsNotRunningReason = "SourceVirtualMachineToolsNotRunning"
+
+	// GuestCredentialsInvalidReason documents that the Secret with the guest
+	// credentials does not exist or is missing a key.
+	GuestCredentialsInvalidReason = "GuestCredentialsInvalid"
+
+	// GuestCommandFileInvalidReason documents that the ConfigMap or Secret
+	// with the data of a file to upload does not exist or is missing the key.
+	GuestCommandFileInvalidReason = "GuestCommandFileInvalid"
+
+	// GuestCommandRunningReason documents that the command is running.
+	GuestCommandRunningReason = "Running"
+
+	// GuestCommandFailedReason documents that the command exited with a
+	// non-zero exit code.
+	GuestCommandFailedReason = "CommandFailed"
+
+	// GuestCommandTimedOutReason documents that the command was terminated
+	// because it did not exit before the timeout.
+	GuestCommandTimedOutReason = "TimedOut"
+
+	// GuestOperationsFailedReason documents that the files could not be
+	// uploaded, or the command could not be started or its output fetched,
+	// with the guest operations of VMware Tools.
+	GuestOperationsFailedReason = "GuestOperationsFailed"
+
+	// GuestCommandInterruptedReason documents that the command was running
+	// when VM Operator restarted, so the result of the command is unknown. The
+	// command is not run again.
+	GuestCommandInterruptedReason = "Interrupted"
+)
+
+const (
+	// VirtualMachineGuestCredentialsUsernameKey is the key in a Secret with
+	// guest credentials that contains the name of the guest account.
+	VirtualMachineGuestCredentialsUsernameKey = "username"
+
+	// VirtualMachineGuestCredentialsPasswordKey is the key in a Secret with
+	// guest credentials that contains the password of the guest account.
+	VirtualMachineGuestCredentialsPasswordKey = "password"
+)
+
+// VirtualMachineGuestCommandFileKeySelector selects a key of a ConfigMap or
+// Secret in the same namespace as the request.
+type VirtualMachineGuestCommandFileKeySelector struct {
+	// Name is the name of the ConfigMap or Secret.
+	Name string `json:"name"`
+
+	// Key is the key in the ConfigMap or Secret whose data is the content of
+	// the file.
+	Key string `json:"key"`
+}
+
+// VirtualMachineGuestCommandFile describes a file that is uploaded into the
+// guest before the command is run. Exactly one of ConfigMap and Secret must
+// be set.
+type VirtualMachineGuestCommandFile struct {
+	// Path is the absolute path in the guest the file is written to. An
+	// existing file is overwritten.
+	Path string `json:"path"`
+
+	// ConfigMap selects the key of a ConfigMap whose data is the content of
+	// the file.
+	//
+	// +optional
+	ConfigMap *VirtualMachineGuestCommandFileKeySelector `json:"configMap,omitempty"`
+
+	// Secret selects the key of a Secret whose data is the content of the
+	// file.
+	//
+	// +optional
+	Secret *VirtualMachineGuestCommandFileKeySelector `json:"secret,omitempty"`
+}
+
+// VirtualMachineGuestCommandRequestSpec defines the desired state of a
+// VirtualMachineGuestCommandRequest.
+type VirtualMachineGuestCommandRequestSpec struct {
+	// VirtualMachineName is the name of the VirtualMachine the command is run
+	// in. The VM must be powered on and have VMware Tools running.
+	VirtualMachineName string `json:"virtualMachineName"`
+
+	// Command is the absolute path in the guest of the program to run.
+	Command string `json:"command"`
+
+	// Args is the list of arguments passed to the command.
+	//
+	// +optional
+	Args []string `json:"args,omitempty"`
+
+	// Env is the list of environment variables set for the command.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Env []common.NameValuePair `json:"env,omitempty"`
+
+	// WorkingDir is the directory in the guest the command is run in.
+	// Defaults to the home directory of the guest account.
+	//
+	// +optional
+	WorkingDir string `json:"workingDir,omitempty"`
+
+	// CredentialsSecretName is the name of a Secret in the same namespace as
+	// the request that contains the username and password keys of the guest
+	// account the command is run as.
+	CredentialsSecretName string `json:"credentialsSecretName"`
+
+	// TimeoutSeconds is how long the command is allowed to run before it is
+	// terminated.
+	//
+	// +kubebuilder:default=300
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TimeoutSeconds int64 `json:"timeoutSeconds,omitempty"`
+
+	// Files is the list of files uploaded into the guest before the command
+	// is run.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=path
+	Files []VirtualMachineGuestCommandFile `json:"files,omitempty"`
+
+	// TTLSecondsAfterFinished is the time-to-live duration for how long this
+	// resource will be allowed to exist once the command completes. After the
+	// TTL expires, the resource will be automatically deleted without the
+	// user having to take any direct action.
+	//
+	// If this field is unset then the request resource will not be
+	// automatically deleted. If this field is set to zero then the request
+	// resource is eligible for deletion immediately after it finishes.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	TTLSecondsAfterFinished *int64 `json:"ttlSecondsAfterFinished,omitempty"`
+}
+
+// VirtualMachineGuestCommandRequestStatus defines the observed state of a
+// VirtualMachineGuestCommandRequest.
+type VirtualMachineGuestCommandRequestStatus struct {
+	// StartTime represents time when the request was acknowledged by the
+	// controller.
+	//
+	// +optional
+	StartTime metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime represents time when the request was completed.
+	//
+	// The value of this field should be equal to the value of the
+	// LastTransitionTime for the status condition Type=Complete.
+	//
+	// +optional
+	CompletionTime metav1.Time `json:"completionTime,omitempty"`
+
+	// ExitCode is the exit code of the command.
+	//
+	// +optional
+	ExitCode *int32 `json:"exitCode,omitempty"`
+
+	// Stdout is the standard output of the command. Only the last 16KiB of
+	// the output is kept.
+	//
+	// +optional
+	Stdout string `json:"stdout,omitempty"`
+
+	// StdoutTruncated is true when the standard output of the command was
+	// larger than what is kept in Stdout.
+	//
+	// +optional
+	StdoutTruncated bool `json:"stdoutTruncated,omitempty"`
+
+	// Stderr is the standard error of the command. Only the last 16KiB of the
+	// output is kept.
+	//
+	// +optional
+	Stderr string `json:"stderr,omitempty"`
+
+	// StderrTruncated is true when the standard error of the command was
+	// larger than what is kept in Stderr.
+	//
+	// +optional
+	StderrTruncated bool `json:"stderrTruncated,omitempty"`
+
+	// Conditions describes the observed conditions of the
+	// VirtualMachineGuestCommandRequest.
+	//
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Namespaced,shortName=vmguestcmd
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="VirtualMachine",type="string",JSONPath=".spec.virtualMachineName"
+// +kubebuilder:printcolumn:name="Command",type="string",priority=1,JSONPath=".spec.command"
+// +kubebuilder:printcolumn:name="ExitCode",type="integer",JSONPath=".status.exitCode"
+// +kubebuilder:printcolumn:name="Complete",type="string",JSONPath=".status.conditions[?(@.type=='Complete')].status"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// VirtualMachineGuestCommandRequest is the schema for the
+// virtualmachineguestcommandrequests API and represents a request to run a
+// command in the guest of a VM with the guest operations of VMware Tools.
+type VirtualMachineGuestCommandRequest struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VirtualMachineGuestCommandRequestSpec   `json:"spec,omitempty"`
+	Status VirtualMachineGuestCommandRequestStatus `json:"status,omitempty"`
+}
+
+func (r *VirtualMachineGuestCommandRequest) NamespacedName() string {
+	return r.Namespace + "/" + r.Name
+}
+
+func (r *VirtualMachineGuestCommandRequest) GetConditions() []metav1.Condition {
+	return r.Status.Conditions
+}
+
+func (r *VirtualMachineGuestCommandRequest) SetConditions(conditions []metav1.Condition) {
+	r.Status.Conditions = conditions
+}
+
+// +kubebuilder:object:root=true
+
+// VirtualMachineGuestCommandRequestList contains a list of
+// VirtualMachineGuestCommandRequest resources.
+type VirtualMachineGuestCommandRequestList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VirtualMachineGuestCommandRequest `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(
+		&VirtualMachineGuestCommandRequest{},
+		&VirtualMachineGuestCommandRequestList{},
+	)
+}
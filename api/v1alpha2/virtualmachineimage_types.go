@@ -4,6 +4,7 @@
 package v1alpha2
 
 import (
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/vmware-tanzu/vm-operator/api/v1alpha2/common"
@@ -122,6 +123,25 @@ type VirtualMachineImageSpec struct {
 	ProviderRef common.LocalObjectRef `json:"providerRef,omitempty"`
 }
 
+// VirtualMachineImageDiskInfo describes a disk of an image.
+type VirtualMachineImageDiskInfo struct {
+	// Boot describes whether this disk is the boot disk of a VM deployed from
+	// the image.
+	//
+	// +optional
+	Boot bool `json:"boot,omitempty"`
+
+	// Capacity is the virtual disk capacity in bytes.
+	//
+	// +optional
+	Capacity *resource.Quantity `json:"capacity,omitempty"`
+
+	// Size is the estimated populated size of the virtual disk in bytes.
+	//
+	// +optional
+	Size *resource.Quantity `json:"size,omitempty"`
+}
+
 // VirtualMachineImageStatus defines the observed state of VirtualMachineImage.
 type VirtualMachineImageStatus struct {
 
@@ -160,6 +180,13 @@ type VirtualMachineImageStatus struct {
 	// +optional
 	HardwareVersion *int32 `json:"hardwareVersion,omitempty"`
 
+	// Disks describes the observed disks of this image, in the order they are
+	// listed in the image's OVF. The boot disk of a VM deployed from the image
+	// is the disk whose Boot field is true.
+	//
+	// +optional
+	Disks []VirtualMachineImageDiskInfo `json:"disks,omitempty"`
+
 	// OSInfo describes the observed operating system information for this
 	// image.
 	//
@@ -0,0 +1,262 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// VirtualMachineImageImportRequestConditionTargetValid is the Type for a
+	// VirtualMachineImageImportRequest resource's status condition.
+	//
+	// The condition's status is set to true only when the target content
+	// library exists, is writable and ready, and does not already contain an
+	// item with the target item's name.
+	VirtualMachineImageImportRequestConditionTargetValid = "TargetValid"
+
+	// VirtualMachineImageImportRequestConditionUploaded is the Type for a
+	// VirtualMachineImageImportRequest resource's status condition.
+	//
+	// The condition's status is set to true only when the image has been
+	// pulled from the source URL into the content library and its checksum
+	// has been verified.
+	VirtualMachineImageImportRequestConditionUploaded = "Uploaded"
+
+	// VirtualMachineImageImportRequestConditionImageAvailable is the Type for
+	// a VirtualMachineImageImportRequest resource's status condition.
+	//
+	// The condition's status is set to true only when the
+	// VirtualMachineImage resource realized from the imported content
+	// library item is ready.
+	VirtualMachineImageImportRequestConditionImageAvailable = "ImageAvailable"
+
+	// VirtualMachineImageImportRequestConditionComplete is the Type for a
+	// VirtualMachineImageImportRequest resource's status condition.
+	//
+	// The condition's status is set to true only when all other conditions
+	// present on the resource have a truthy status.
+	VirtualMachineImageImportRequestConditionComplete = "Complete"
+)
+
+// Condition.Reason for Conditions related to VirtualMachineImageImportRequest.
+// The reasons for the target content library, the upload and the image
+// availability of a VirtualMachinePublishRequest are also used.
+const (
+	// UploadChecksumMismatchReason documents that the checksum of the file
+	// pulled into the content library does not match the expected checksum.
+	UploadChecksumMismatchReason = "ChecksumMismatch"
+
+	// UploadChecksumUnverifiedReason documents that the content library did
+	// not report a checksum of the pulled file with the expected algorithm,
+	// so the file could not be verified.
+	UploadChecksumUnverifiedReason = "ChecksumUnverified"
+
+	// TargetVirtualMachineImageNotReadyReason documents that the
+	// VirtualMachineImage resource realized from the imported content library
+	// item is not ready.
+	TargetVirtualMachineImageNotReadyReason = "VirtualMachineImageNotReady"
+)
+
+// VirtualMachineImageImportChecksumAlgorithm is the algorithm of the checksum
+// of an imported image.
+//
+// +kubebuilder:validation:Enum=SHA256;SHA512;SHA1;MD5
+type VirtualMachineImageImportChecksumAlgorithm string
+
+const (
+	VirtualMachineImageImportChecksumAlgorithmSHA256 VirtualMachineImageImportChecksumAlgorithm = "SHA256"
+	VirtualMachineImageImportChecksumAlgorithmSHA512 VirtualMachineImageImportChecksumAlgorithm = "SHA512"
+	VirtualMachineImageImportChecksumAlgorithmSHA1   VirtualMachineImageImportChecksumAlgorithm = "SHA1"
+	VirtualMachineImageImportChecksumAlgorithmMD5    VirtualMachineImageImportChecksumAlgorithm = "MD5"
+)
+
+// VirtualMachineImageImportChecksum is the expected checksum of an imported
+// image.
+type VirtualMachineImageImportChecksum struct {
+	// Algorithm is the algorithm of the checksum.
+	//
+	// +kubebuilder:default=SHA256
+	// +optional
+	Algorithm VirtualMachineImageImportChecksumAlgorithm `json:"algorithm,omitempty"`
+
+	// Value is the checksum in hex.
+	Value string `json:"value"`
+}
+
+// VirtualMachineImageImportRequestSource is the source of an import request.
+type VirtualMachineImageImportRequestSource struct {
+	// URL is the HTTP or HTTPS URL of the OVA or OVF to import. The content
+	// library service pulls the image from this URL, so it must be reachable
+	// from vCenter.
+	URL string `json:"url"`
+
+	// Checksum is the expected checksum of the file at the URL. The import
+	// fails if the checksum of the pulled file does not match, or if the
+	// content library does not report a checksum with this algorithm.
+	Checksum VirtualMachineImageImportChecksum `json:"checksum"`
+}
+
+// VirtualMachineImageImportRequestTargetItem is the item part of an import
+// request's target.
+type VirtualMachineImageImportRequestTargetItem struct {
+	// Name is the name of the content library item that is created, as it
+	// shows up in vCenter Content Library.
+	//
+	// If omitted then the controller uses the name of the
+	// VirtualMachineImageImportRequest resource.
+	//
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Description is the description to assign to the content library item.
+	//
+	// +optional
+	Description string `json:"description,omitempty"`
+}
+
+// VirtualMachineImageImportRequestTargetLocation is the location part of an
+// import request's target.
+type VirtualMachineImageImportRequestTargetLocation struct {
+	// Name is the name of the referenced object.
+	Name string `json:"name"`
+
+	// APIVersion is the API version of the referenced object.
+	//
+	// +kubebuilder:default=imageregistry.vmware.com/v1alpha1
+	// +optional
+	APIVersion string `json:"apiVersion,omitempty"`
+
+	// Kind is the kind of referenced object.
+	//
+	// +kubebuilder:default=ContentLibrary
+	// +optional
+	Kind string `json:"kind,omitempty"`
+}
+
+// VirtualMachineImageImportRequestTarget is the target of an import request,
+// a ContentLibrary resource.
+type VirtualMachineImageImportRequestTarget struct {
+	// Item contains information about the content library item that is
+	// created.
+	//
+	// +optional
+	Item VirtualMachineImageImportRequestTargetItem `json:"item,omitempty"`
+
+	// Location is the content library the image is imported into.
+	Location VirtualMachineImageImportRequestTargetLocation `json:"location"`
+}
+
+// VirtualMachineImageImportRequestSpec defines the desired state of a
+// VirtualMachineImageImportRequest.
+type VirtualMachineImageImportRequestSpec struct {
+	// Source is the URL and checksum of the image to import.
+	Source VirtualMachineImageImportRequestSource `json:"source"`
+
+	// Target is the content library and item the image is imported into.
+	Target VirtualMachineImageImportRequestTarget `json:"target"`
+}
+
+// VirtualMachineImageImportRequestStatus defines the observed state of a
+// VirtualMachineImageImportRequest.
+type VirtualMachineImageImportRequestStatus struct {
+	// StartTime represents time when the request was acknowledged by the
+	// controller.
+	//
+	// +optional
+	StartTime metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime represents time when the request was completed.
+	//
+	// The value of this field should be equal to the value of the
+	// LastTransitionTime for the status condition Type=Complete.
+	//
+	// +optional
+	CompletionTime metav1.Time `json:"completionTime,omitempty"`
+
+	// ItemID is the ID of the content library item created for the import.
+	//
+	// +optional
+	ItemID string `json:"itemID,omitempty"`
+
+	// UpdateSessionID is the ID of the content library update session that
+	// pulls the image into the item.
+	//
+	// +optional
+	UpdateSessionID string `json:"updateSessionID,omitempty"`
+
+	// Progress is the percentage of the image that has been pulled into the
+	// content library.
+	//
+	// +optional
+	Progress int32 `json:"progress,omitempty"`
+
+	// ImageName is the name of the VirtualMachineImage resource realized
+	// from the imported content library item.
+	//
+	// +optional
+	ImageName string `json:"imageName,omitempty"`
+
+	// Ready is set to true only when the image has been imported and the
+	// VirtualMachineImage resource is ready.
+	//
+	// +optional
+	Ready bool `json:"ready,omitempty"`
+
+	// Conditions is a list of the latest, available observations of the
+	// request's current state.
+	//
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Namespaced,shortName=vmiimport
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Library",type="string",JSONPath=".spec.target.location.name"
+// +kubebuilder:printcolumn:name="Progress",type="integer",JSONPath=".status.progress"
+// +kubebuilder:printcolumn:name="Image",type="string",JSONPath=".status.imageName"
+// +kubebuilder:printcolumn:name="Ready",type="boolean",JSONPath=".status.ready"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// VirtualMachineImageImportRequest is the schema for the
+// virtualmachineimageimportrequests API and represents a request to import an
+// OVA or OVF from a URL into a content library as a new VirtualMachineImage.
+type VirtualMachineImageImportRequest struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VirtualMachineImageImportRequestSpec   `json:"spec,omitempty"`
+	Status VirtualMachineImageImportRequestStatus `json:"status,omitempty"`
+}
+
+func (r *VirtualMachineImageImportRequest) NamespacedName() string {
+	return r.Namespace + "/" + r.Name
+}
+
+func (r *VirtualMachineImageImportRequest) GetConditions() []metav1.Condition {
+	return r.Status.Conditions
+}
+
+func (r *VirtualMachineImageImportRequest) SetConditions(conditions []metav1.Condition) {
+	r.Status.Conditions = conditions
+}
+
+// +kubebuilder:object:root=true
+
+// VirtualMachineImageImportRequestList contains a list of
+// VirtualMachineImageImportRequest resources.
+type VirtualMachineImageImportRequestList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VirtualMachineImageImportRequest `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(
+		&VirtualMachineImageImportRequest{},
+		&VirtualMachineImageImportRequestList{},
+	)
+}
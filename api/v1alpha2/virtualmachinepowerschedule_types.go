@@ -0,0 +1,212 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// VirtualMachinePowerScheduleConditionReady is the Type for a
+	// VirtualMachinePowerSchedule resource's status condition.
+	//
+	// The condition's status is set to true only when the schedule is valid
+	// and the most recent power operation was applied to all of the selected
+	// VMs.
+	VirtualMachinePowerScheduleConditionReady = "PowerScheduleReady"
+)
+
+// Condition.Reason for Conditions related to VirtualMachinePowerSchedule.
+const (
+	// VirtualMachinePowerScheduleInvalidScheduleReason documents that one of
+	// the schedule's cron expressions or its time zone is invalid.
+	VirtualMachinePowerScheduleInvalidScheduleReason = "InvalidSchedule"
+
+	// VirtualMachinePowerScheduleOperationFailedReason documents that the
+	// most recent power operation could not be applied to one or more of the
+	// selected VMs.
+	VirtualMachinePowerScheduleOperationFailedReason = "OperationFailed"
+
+	// VirtualMachinePowerScheduleSuspendedReason documents that the schedule
+	// is suspended.
+	VirtualMachinePowerScheduleSuspendedReason = "Suspended"
+)
+
+// VirtualMachinePowerScheduleOperation is a power operation that may be
+// scheduled by a VirtualMachinePowerSchedule.
+//
+// +kubebuilder:validation:Enum=PoweredOn;PoweredOff;Suspended;Restart
+type VirtualMachinePowerScheduleOperation string
+
+const (
+	// VirtualMachinePowerScheduleOperationPoweredOn sets the selected VMs'
+	// spec.powerState to PoweredOn.
+	VirtualMachinePowerScheduleOperationPoweredOn VirtualMachinePowerScheduleOperation = "PoweredOn"
+
+	// VirtualMachinePowerScheduleOperationPoweredOff sets the selected VMs'
+	// spec.powerState to PoweredOff.
+	VirtualMachinePowerScheduleOperationPoweredOff VirtualMachinePowerScheduleOperation = "PoweredOff"
+
+	// VirtualMachinePowerScheduleOperationSuspended sets the selected VMs'
+	// spec.powerState to Suspended.
+	VirtualMachinePowerScheduleOperationSuspended VirtualMachinePowerScheduleOperation = "Suspended"
+
+	// VirtualMachinePowerScheduleOperationRestart restarts the selected VMs
+	// that are powered on by way of spec.nextRestartTime.
+	VirtualMachinePowerScheduleOperationRestart VirtualMachinePowerScheduleOperation = "Restart"
+)
+
+// VirtualMachinePowerScheduleSpec defines the desired state of a
+// VirtualMachinePowerSchedule.
+//
+// Each of the schedules is a cron expression in the standard five field
+// format, ex. "0 19 * * 1-5" for 7pm on weekdays, or one of the predefined
+// schedules such as "@daily". At least one schedule must be specified.
+type VirtualMachinePowerScheduleSpec struct {
+	// Selector is a label query over the VirtualMachines, in the same
+	// Namespace as this schedule, to which the power operations are applied.
+	//
+	// More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/labels/#label-selectors
+	Selector *metav1.LabelSelector `json:"selector"`
+
+	// PoweredOn is the schedule on which the selected VMs are powered on.
+	//
+	// +optional
+	PoweredOn string `json:"poweredOn,omitempty"`
+
+	// PoweredOff is the schedule on which the selected VMs are powered off.
+	//
+	// +optional
+	PoweredOff string `json:"poweredOff,omitempty"`
+
+	// Suspended is the schedule on which the selected VMs are suspended.
+	//
+	// +optional
+	Suspended string `json:"suspended,omitempty"`
+
+	// Restart is the schedule on which the selected VMs that are powered on
+	// are restarted in accordance with their spec.restartMode.
+	//
+	// +optional
+	Restart string `json:"restart,omitempty"`
+
+	// TimeZone is the name of the time zone, from the IANA time zone
+	// database, in which the schedules are evaluated, ex. "America/New_York".
+	//
+	// Defaults to UTC.
+	//
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+
+	// Suspend prevents any power operations from being applied while true.
+	//
+	// Please note when the schedule is resumed, only the most recent of the
+	// power operations that were missed while it was suspended is applied.
+	//
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+
+	// StartingDeadlineSeconds is the deadline in seconds for applying a power
+	// operation that was missed, ex. because the schedule was suspended or the
+	// controller was not running at the time the operation was due. A missed
+	// operation that was due more than this many seconds ago is not applied.
+	//
+	// Defaults to no deadline, in which case the most recent of the missed
+	// power operations is always applied.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds,omitempty"`
+}
+
+// VirtualMachinePowerScheduleStatus defines the observed state of a
+// VirtualMachinePowerSchedule.
+type VirtualMachinePowerScheduleStatus struct {
+	// LastRunTime is the time at which the most recent power operation was
+	// scheduled to run.
+	//
+	// +optional
+	LastRunTime *metav1.Time `json:"lastRunTime,omitempty"`
+
+	// LastRunOperation is the most recent power operation that was run.
+	//
+	// +optional
+	LastRunOperation VirtualMachinePowerScheduleOperation `json:"lastRunOperation,omitempty"`
+
+	// NextRunTime is the time at which the next power operation is scheduled
+	// to run.
+	//
+	// Please note this field is not set while the schedule is suspended.
+	//
+	// +optional
+	NextRunTime *metav1.Time `json:"nextRunTime,omitempty"`
+
+	// NextRunOperation is the next power operation that is scheduled to run.
+	//
+	// +optional
+	NextRunOperation VirtualMachinePowerScheduleOperation `json:"nextRunOperation,omitempty"`
+
+	// ObservedGeneration reflects the generation of the most recently
+	// observed VirtualMachinePowerSchedule.
+	//
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions describes the observed conditions of the
+	// VirtualMachinePowerSchedule.
+	//
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Namespaced,shortName=vmpowerschedule
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Suspend",type="boolean",JSONPath=".spec.suspend"
+// +kubebuilder:printcolumn:name="Last-Operation",type="string",JSONPath=".status.lastRunOperation"
+// +kubebuilder:printcolumn:name="Last-Run",type="date",JSONPath=".status.lastRunTime"
+// +kubebuilder:printcolumn:name="Next-Operation",type="string",priority=1,JSONPath=".status.nextRunOperation"
+// +kubebuilder:printcolumn:name="Next-Run",type="string",priority=1,JSONPath=".status.nextRunTime"
+
+// VirtualMachinePowerSchedule is the schema for the
+// virtualmachinepowerschedules API and represents the schedules on which a
+// set of VirtualMachines are powered on, powered off, suspended and
+// restarted.
+type VirtualMachinePowerSchedule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VirtualMachinePowerScheduleSpec   `json:"spec,omitempty"`
+	Status VirtualMachinePowerScheduleStatus `json:"status,omitempty"`
+}
+
+func (ps *VirtualMachinePowerSchedule) NamespacedName() string {
+	return ps.Namespace + "/" + ps.Name
+}
+
+func (ps *VirtualMachinePowerSchedule) GetConditions() []metav1.Condition {
+	return ps.Status.Conditions
+}
+
+func (ps *VirtualMachinePowerSchedule) SetConditions(conditions []metav1.Condition) {
+	ps.Status.Conditions = conditions
+}
+
+// +kubebuilder:object:root=true
+
+// VirtualMachinePowerScheduleList contains a list of
+// VirtualMachinePowerSchedule resources.
+type VirtualMachinePowerScheduleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VirtualMachinePowerSchedule `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(
+		&VirtualMachinePowerSchedule{},
+		&VirtualMachinePowerScheduleList{},
+	)
+}
@@ -0,0 +1,186 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/vmware-tanzu/vm-operator/api/v1alpha2/common"
+)
+
+const (
+	// VirtualMachineReplicaSetConditionReplicasReady is the Type for a
+	// VirtualMachineReplicaSet resource's status condition.
+	//
+	// The condition's status is set to true only when the number of ready
+	// replicas matches the number of desired replicas.
+	VirtualMachineReplicaSetConditionReplicasReady = "ReplicasReady"
+
+	// VirtualMachineReplicaSetReplicasNotReadyReason documents that fewer
+	// replicas are ready than are desired.
+	VirtualMachineReplicaSetReplicasNotReadyReason = "ReplicasNotReady"
+
+	// VirtualMachineReplicaSetReplicaCreateFailedReason documents that a
+	// replica could not be created.
+	VirtualMachineReplicaSetReplicaCreateFailedReason = "ReplicaCreateFailed"
+
+	// VirtualMachineReplicaSetReplicaDeleteFailedReason documents that a
+	// replica could not be deleted.
+	VirtualMachineReplicaSetReplicaDeleteFailedReason = "ReplicaDeleteFailed"
+)
+
+const (
+	// VirtualMachineReplicaSetNameLabel is the label added to the
+	// VirtualMachine resources created by a VirtualMachineReplicaSet. Its
+	// value is the name of the VirtualMachineReplicaSet.
+	VirtualMachineReplicaSetNameLabel = GroupName + "/replicaset-name"
+)
+
+// VirtualMachineTemplateSpec describes the data needed to create a
+// VirtualMachine from a template.
+type VirtualMachineTemplateSpec struct {
+	// Standard object's metadata.
+	//
+	// +optional
+	common.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec is the specification of the desired behavior of each replica
+	// VirtualMachine.
+	//
+	// +optional
+	Spec VirtualMachineSpec `json:"spec,omitempty"`
+}
+
+// VirtualMachineReplicaSetSpec defines the desired state of a
+// VirtualMachineReplicaSet.
+type VirtualMachineReplicaSetSpec struct {
+	// Replicas is the number of desired replicas.
+	//
+	// This is a pointer to distinguish between explicit zero and unspecified.
+	//
+	// +optional
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=0
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Selector is a label query over VirtualMachines that should match the
+	// replica count. Label keys and values that must match in order to be
+	// controlled by this VirtualMachineReplicaSet.
+	//
+	// It must match the labels in the template.
+	// More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/labels/#label-selectors
+	Selector *metav1.LabelSelector `json:"selector"`
+
+	// Template is the object that describes the VirtualMachine that will be
+	// created if insufficient replicas are detected.
+	//
+	// Changes to the template only affect replicas that are created after
+	// the change.
+	Template VirtualMachineTemplateSpec `json:"template"`
+
+	// ResourcePolicyName describes the name of a
+	// VirtualMachineSetResourcePolicy resource, in the same Namespace as this
+	// VirtualMachineReplicaSet, used to place the replicas in a shared
+	// resource pool and folder.
+	//
+	// +optional
+	ResourcePolicyName string `json:"resourcePolicyName,omitempty"`
+
+	// ClusterModuleGroup describes the name of one of the cluster module
+	// groups in the VirtualMachineSetResourcePolicy specified by
+	// ResourcePolicyName. The replicas are added to this cluster module so
+	// that they are scheduled on different hosts when possible.
+	//
+	// Please note this field requires ResourcePolicyName to be set.
+	//
+	// +optional
+	ClusterModuleGroup string `json:"clusterModuleGroup,omitempty"`
+}
+
+// VirtualMachineReplicaSetStatus defines the observed state of a
+// VirtualMachineReplicaSet.
+type VirtualMachineReplicaSetStatus struct {
+	// Replicas is the most recently observed number of replicas.
+	//
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// ReadyReplicas is the number of replicas that are ready.
+	//
+	// A replica with a readiness probe is ready when its Ready condition,
+	// which is maintained by the readiness probe, is true. A replica without
+	// a readiness probe is ready once it has been created and is in its
+	// desired power state.
+	//
+	// +optional
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+
+	// Selector is the same as the label selector in the spec, but in the
+	// string format. It is used by the scale subresource.
+	//
+	// +optional
+	Selector string `json:"selector,omitempty"`
+
+	// ObservedGeneration reflects the generation of the most recently
+	// observed VirtualMachineReplicaSet.
+	//
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions describes the observed conditions of the
+	// VirtualMachineReplicaSet.
+	//
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Namespaced,shortName=vmrs
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+// +kubebuilder:subresource:scale:specpath=.spec.replicas,statuspath=.status.replicas,selectorpath=.status.selector
+// +kubebuilder:printcolumn:name="Desired",type="integer",JSONPath=".spec.replicas"
+// +kubebuilder:printcolumn:name="Current",type="integer",JSONPath=".status.replicas"
+// +kubebuilder:printcolumn:name="Ready",type="integer",JSONPath=".status.readyReplicas"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// VirtualMachineReplicaSet is the schema for the virtualmachinereplicasets
+// API and represents a set of identical VirtualMachines kept at a desired
+// number of replicas.
+type VirtualMachineReplicaSet struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VirtualMachineReplicaSetSpec   `json:"spec,omitempty"`
+	Status VirtualMachineReplicaSetStatus `json:"status,omitempty"`
+}
+
+func (rs *VirtualMachineReplicaSet) NamespacedName() string {
+	return rs.Namespace + "/" + rs.Name
+}
+
+func (rs *VirtualMachineReplicaSet) GetConditions() []metav1.Condition {
+	return rs.Status.Conditions
+}
+
+func (rs *VirtualMachineReplicaSet) SetConditions(conditions []metav1.Condition) {
+	rs.Status.Conditions = conditions
+}
+
+// +kubebuilder:object:root=true
+
+// VirtualMachineReplicaSetList contains a list of VirtualMachineReplicaSet
+// resources.
+type VirtualMachineReplicaSetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VirtualMachineReplicaSet `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(
+		&VirtualMachineReplicaSet{},
+		&VirtualMachineReplicaSetList{},
+	)
+}
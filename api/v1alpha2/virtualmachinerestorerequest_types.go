@@ -0,0 +1,181 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// VirtualMachineRestoreRequestConditionRestored is the Type for a
+	// VirtualMachineRestoreRequest resource's status condition.
+	//
+	// The condition's status is set to true only when all of the VMs that
+	// were found on the underlying infrastructure without a matching
+	// VirtualMachine resource have been fully restored.
+	VirtualMachineRestoreRequestConditionRestored = "Restored"
+
+	// VirtualMachineRestoreRequestConditionComplete is the Type for a
+	// VirtualMachineRestoreRequest resource's status condition.
+	//
+	// The condition's status is set to true once the request has been
+	// processed, regardless of the result of restoring each VM.
+	VirtualMachineRestoreRequestConditionComplete = "Complete"
+)
+
+// Condition.Reason for Conditions related to VirtualMachineRestoreRequest.
+const (
+	// VirtualMachineRestoreConflictReason documents that one or more VMs
+	// were not restored because a different VM with the same name already
+	// exists.
+	VirtualMachineRestoreConflictReason = "Conflict"
+
+	// VirtualMachineRestorePartiallyRestoredReason documents that one or more
+	// VMs were restored, but some of the resources they reference, ex. a
+	// PersistentVolumeClaim, could not be.
+	VirtualMachineRestorePartiallyRestoredReason = "PartiallyRestored"
+
+	// VirtualMachineRestoreFailedReason documents that one or more VMs could
+	// not be restored, ex. because their backup data is missing or invalid.
+	VirtualMachineRestoreFailedReason = "RestoreFailed"
+)
+
+// VirtualMachineRestoreResult is the result of restoring a VM.
+type VirtualMachineRestoreResult string
+
+const (
+	// VirtualMachineRestoreResultRestored indicates the VM and the resources
+	// it references were restored.
+	VirtualMachineRestoreResultRestored VirtualMachineRestoreResult = "Restored"
+
+	// VirtualMachineRestoreResultPartiallyRestored indicates the VM was
+	// restored, but some of the resources it references could not be.
+	VirtualMachineRestoreResultPartiallyRestored VirtualMachineRestoreResult = "PartiallyRestored"
+
+	// VirtualMachineRestoreResultConflict indicates the VM was not restored
+	// because a different VM with the same name already exists.
+	VirtualMachineRestoreResultConflict VirtualMachineRestoreResult = "Conflict"
+
+	// VirtualMachineRestoreResultFailed indicates the VM could not be
+	// restored.
+	VirtualMachineRestoreResultFailed VirtualMachineRestoreResult = "Failed"
+)
+
+// VirtualMachineRestoreRequestSpec defines the desired state of a
+// VirtualMachineRestoreRequest.
+type VirtualMachineRestoreRequestSpec struct {
+	// VirtualMachineNames is an optional list of the names of the
+	// VirtualMachine resources to restore. When empty, all the VMs in the
+	// namespace that have no matching VirtualMachine resource are restored.
+	//
+	// +optional
+	VirtualMachineNames []string `json:"virtualMachineNames,omitempty"`
+}
+
+// VirtualMachineRestoreStatus describes the result of restoring a VM.
+type VirtualMachineRestoreStatus struct {
+	// Name is the name of the restored VirtualMachine resource, or the name
+	// of the VM on the underlying infrastructure when it has no backup data.
+	Name string `json:"name"`
+
+	// UniqueID is the unique identifier of the VM on the underlying
+	// infrastructure, such as vSphere.
+	//
+	// +optional
+	UniqueID string `json:"uniqueID,omitempty"`
+
+	// Result is the result of restoring the VM.
+	Result VirtualMachineRestoreResult `json:"result"`
+
+	// Message describes the resources that could not be restored, or why
+	// the VM was not restored.
+	//
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// VirtualMachineRestoreRequestStatus defines the observed state of a
+// VirtualMachineRestoreRequest.
+type VirtualMachineRestoreRequestStatus struct {
+	// StartTime represents time when the request was acknowledged by the
+	// controller.
+	//
+	// +optional
+	StartTime metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime represents time when the request was completed.
+	//
+	// The value of this field should be equal to the value of the
+	// LastTransitionTime for the status condition Type=Complete.
+	//
+	// +optional
+	CompletionTime metav1.Time `json:"completionTime,omitempty"`
+
+	// VirtualMachines describes the result of restoring each of the VMs
+	// that had no matching VirtualMachine resource.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	VirtualMachines []VirtualMachineRestoreStatus `json:"virtualMachines,omitempty"`
+
+	// Conditions describes the observed conditions of the
+	// VirtualMachineRestoreRequest.
+	//
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Namespaced,shortName=vmrestore
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Complete",type="string",JSONPath=".status.conditions[?(@.type=='Complete')].status"
+// +kubebuilder:printcolumn:name="Restored",type="string",JSONPath=".status.conditions[?(@.type=='Restored')].status"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// VirtualMachineRestoreRequest is the schema for the
+// virtualmachinerestorerequests API and represents a request to restore the
+// VirtualMachine resources, and the resources they reference, from the data
+// backed up on the VMs in the underlying infrastructure.
+//
+// The VMs on the underlying infrastructure that are managed by VM Service
+// and have no matching VirtualMachine resource in the request's namespace
+// are restored.
+type VirtualMachineRestoreRequest struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VirtualMachineRestoreRequestSpec   `json:"spec,omitempty"`
+	Status VirtualMachineRestoreRequestStatus `json:"status,omitempty"`
+}
+
+func (r *VirtualMachineRestoreRequest) NamespacedName() string {
+	return r.Namespace + "/" + r.Name
+}
+
+func (r *VirtualMachineRestoreRequest) GetConditions() []metav1.Condition {
+	return r.Status.Conditions
+}
+
+func (r *VirtualMachineRestoreRequest) SetConditions(conditions []metav1.Condition) {
+	r.Status.Conditions = conditions
+}
+
+// +kubebuilder:object:root=true
+
+// VirtualMachineRestoreRequestList contains a list of
+// VirtualMachineRestoreRequest resources.
+type VirtualMachineRestoreRequestList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VirtualMachineRestoreRequest `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(
+		&VirtualMachineRestoreRequest{},
+		&VirtualMachineRestoreRequestList{},
+	)
+}
@@ -0,0 +1,174 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// AllowSerialConsoleAnnotation is an annotation that may be set to "true"
+	// on a VirtualMachineClass to allow a network-backed serial port to be
+	// added to VMs of that class by a VirtualMachineSerialConsoleRequest.
+	AllowSerialConsoleAnnotation = "virtualmachineclass." + GroupName + "/allow-serial-console"
+)
+
+const (
+	// VirtualMachineSerialConsoleRequestConditionSerialPortReady is the Type
+	// for a VirtualMachineSerialConsoleRequest resource's status condition.
+	//
+	// The condition's status is set to true only when the VM has a
+	// network-backed serial port that the console is accessed through.
+	VirtualMachineSerialConsoleRequestConditionSerialPortReady = "SerialPortReady"
+
+	// VirtualMachineSerialConsoleRequestConditionLogStreaming is the Type for
+	// a VirtualMachineSerialConsoleRequest resource's status condition.
+	//
+	// The condition's status is set to true while the output of the serial
+	// port is written to the log in the status. The condition is only set
+	// when spec.log is set.
+	VirtualMachineSerialConsoleRequestConditionLogStreaming = "LogStreaming"
+)
+
+// Condition.Reason for Conditions related to VirtualMachineSerialConsoleRequest.
+// The reason SourceVirtualMachineNotCreatedReason is also used.
+const (
+	// SerialConsoleNotAllowedReason documents that the VirtualMachineClass of
+	// the VM does not allow a serial console.
+	SerialConsoleNotAllowedReason = "SerialConsoleNotAllowed"
+
+	// SerialPortAddFailedReason documents that the network-backed serial port
+	// could not be added to the VM.
+	SerialPortAddFailedReason = "SerialPortAddFailed"
+
+	// SerialPortVirtualMachinePoweredOnReason documents that the
+	// network-backed serial port cannot be added to the VM because the VM is
+	// powered on. The serial port is added once the VM is powered off.
+	SerialPortVirtualMachinePoweredOnReason = "VirtualMachinePoweredOn"
+
+	// SerialLogDisconnectedReason documents that the connection to the serial
+	// port that the log is read from was closed. The connection is retried.
+	SerialLogDisconnectedReason = "Disconnected"
+)
+
+// VirtualMachineSerialConsoleLogSpec describes how the output of the serial
+// port is written to the log in the status of the request.
+type VirtualMachineSerialConsoleLogSpec struct {
+	// MaxBytes is the maximum size of the log. Only the last MaxBytes of the
+	// output of the serial port are kept.
+	//
+	// +optional
+	// +kubebuilder:default=65536
+	// +kubebuilder:validation:Minimum=1024
+	// +kubebuilder:validation:Maximum=262144
+	MaxBytes int64 `json:"maxBytes,omitempty"`
+}
+
+// VirtualMachineSerialConsoleRequestSpec describes the desired state for a
+// serial console request to a VM.
+type VirtualMachineSerialConsoleRequestSpec struct {
+	// Name is the name of a VM in the same Namespace as this serial console
+	// request. The VirtualMachineClass of the VM must allow a serial console
+	// with the AllowSerialConsoleAnnotation.
+	Name string `json:"name"`
+
+	// PublicKey is used to encrypt the status.response. This is expected to be
+	// a RSA OAEP public key in X.509 PEM format.
+	PublicKey string `json:"publicKey"`
+
+	// Log describes whether and how the output of the serial port is
+	// continuously written to status.log.
+	//
+	// When set, the request is not deleted when its ticket expires, and the
+	// log is written until the request is deleted.
+	//
+	// +optional
+	Log *VirtualMachineSerialConsoleLogSpec `json:"log,omitempty"`
+}
+
+// VirtualMachineSerialConsoleRequestStatus describes the observed state of
+// the request.
+type VirtualMachineSerialConsoleRequestStatus struct {
+	// Response is the encrypted one-time ticket of the serial console. The
+	// serial console is accessed by connecting to ProxyAddr over TLS and
+	// sending the decrypted ticket on the first line of the connection.
+	//
+	// +optional
+	Response string `json:"response,omitempty"`
+
+	// ExpiryTime is the time at which access via this request will expire.
+	//
+	// +optional
+	ExpiryTime metav1.Time `json:"expiryTime,omitempty"`
+
+	// ProxyAddr describes the host address and port of the serial console
+	// proxy used to access the VM's serial console.
+	//
+	// +optional
+	ProxyAddr string `json:"proxyAddr,omitempty"`
+
+	// Log is the output of the serial port, capped to spec.log.maxBytes.
+	//
+	// +optional
+	Log string `json:"log,omitempty"`
+
+	// LogUpdateTime is the time at which the log was last updated.
+	//
+	// +optional
+	LogUpdateTime metav1.Time `json:"logUpdateTime,omitempty"`
+
+	// Conditions describes the observed conditions of the
+	// VirtualMachineSerialConsoleRequest.
+	//
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Namespaced,shortName=vmserialconsole
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="VirtualMachine",type="string",JSONPath=".spec.name"
+// +kubebuilder:printcolumn:name="SerialPortReady",type="string",JSONPath=".status.conditions[?(@.type=='SerialPortReady')].status"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// VirtualMachineSerialConsoleRequest allows the creation of a serial console
+// connection to a VM, and optionally the streaming of the output of the VM's
+// serial port into a size-capped log.
+type VirtualMachineSerialConsoleRequest struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VirtualMachineSerialConsoleRequestSpec   `json:"spec,omitempty"`
+	Status VirtualMachineSerialConsoleRequestStatus `json:"status,omitempty"`
+}
+
+func (r *VirtualMachineSerialConsoleRequest) NamespacedName() string {
+	return r.Namespace + "/" + r.Name
+}
+
+func (r *VirtualMachineSerialConsoleRequest) GetConditions() []metav1.Condition {
+	return r.Status.Conditions
+}
+
+func (r *VirtualMachineSerialConsoleRequest) SetConditions(conditions []metav1.Condition) {
+	r.Status.Conditions = conditions
+}
+
+// +kubebuilder:object:root=true
+
+// VirtualMachineSerialConsoleRequestList contains a list of
+// VirtualMachineSerialConsoleRequests.
+type VirtualMachineSerialConsoleRequestList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VirtualMachineSerialConsoleRequest `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(
+		&VirtualMachineSerialConsoleRequest{},
+		&VirtualMachineSerialConsoleRequestList{},
+	)
+}
@@ -5,6 +5,7 @@ package v1alpha2
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // VirtualMachineServiceType string describes ingress methods for a service.
@@ -26,6 +27,53 @@ const (
 	// CNAME record, with no exposing or proxying of any VirtualMachines
 	// involved.
 	VirtualMachineServiceTypeExternalName VirtualMachineServiceType = "ExternalName"
+
+	// VirtualMachineServiceTypeHeadless means a service will not be allocated
+	// a cluster IP. Instead, the DNS name of the service resolves to the IPs of
+	// the VirtualMachines, and each VirtualMachine is published with its own
+	// DNS name based on its host name.
+	VirtualMachineServiceTypeHeadless VirtualMachineServiceType = "Headless"
+
+	// VirtualMachineServiceTypeNodePort means a service will be exposed on
+	// each node's IP at a static port, in addition to the cluster IP.
+	VirtualMachineServiceTypeNodePort VirtualMachineServiceType = "NodePort"
+)
+
+// IPFamily represents the IP Family (IPv4 or IPv6). This type is used to
+// express the family of an IP expressed by a type (e.g. service.spec.ipFamilies).
+// +kubebuilder:validation:Enum=IPv4;IPv6
+type IPFamily string
+
+const (
+	// IPv4Protocol indicates that this IP is IPv4 protocol.
+	IPv4Protocol IPFamily = "IPv4"
+
+	// IPv6Protocol indicates that this IP is IPv6 protocol.
+	IPv6Protocol IPFamily = "IPv6"
+)
+
+// IPFamilyPolicy represents the dual-stack-ness requested or required by a
+// VirtualMachineService.
+// +kubebuilder:validation:Enum=SingleStack;PreferDualStack;RequireDualStack
+type IPFamilyPolicy string
+
+const (
+	// IPFamilyPolicySingleStack indicates that the service is required to
+	// have a single IPFamily. The IPFamily assigned is based on the default
+	// IPFamily used by the cluster or as identified by the first entry of
+	// IPFamilies.
+	IPFamilyPolicySingleStack IPFamilyPolicy = "SingleStack"
+
+	// IPFamilyPolicyPreferDualStack indicates that the service prefers
+	// dual-stack when the cluster is configured for dual-stack. If the cluster
+	// is not configured for dual-stack the service will be assigned a single
+	// IPFamily.
+	IPFamilyPolicyPreferDualStack IPFamilyPolicy = "PreferDualStack"
+
+	// IPFamilyPolicyRequireDualStack indicates that the service requires
+	// dual-stack. Using IPFamilyPolicyRequireDualStack on a single-stack
+	// cluster will result in validation errors.
+	IPFamilyPolicyRequireDualStack IPFamilyPolicy = "RequireDualStack"
 )
 
 // VirtualMachineServicePort describes the specification of a service port to
@@ -46,7 +94,19 @@ type VirtualMachineServicePort struct {
 
 	// TargetPort describes the internal port open on a VirtualMachine that
 	// should be mapped to the external Port.
-	TargetPort int32 `json:"targetPort"`
+	//
+	// This is either the number of the port, or the name of a port in the
+	// spec.ports of the VirtualMachines. A named port is resolved for each
+	// VirtualMachine, so the VirtualMachines may listen on different ports.
+	// A VirtualMachine that does not have a port with the name and protocol
+	// is not an endpoint of the VirtualMachineService.
+	TargetPort intstr.IntOrString `json:"targetPort"`
+
+	// NodePort describes the port on each node on which this service is
+	// exposed. Only applies to VirtualMachineService Type: NodePort.
+	// If not specified, a port will be allocated when the service is created.
+	// +optional
+	NodePort int32 `json:"nodePort,omitempty"`
 }
 
 // LoadBalancerStatus represents the status of a load balancer.
@@ -78,7 +138,7 @@ type LoadBalancerIngress struct {
 type VirtualMachineServiceSpec struct {
 	// Type specifies a desired VirtualMachineServiceType for this
 	// VirtualMachineService. Supported types are ClusterIP, LoadBalancer,
-	// ExternalName.
+	// ExternalName, Headless and NodePort.
 	Type VirtualMachineServiceType `json:"type"`
 
 	// Ports specifies a list of VirtualMachineServicePort to expose with this
@@ -114,8 +174,9 @@ type VirtualMachineServiceSpec struct {
 	// of the service will fail. This field can not be changed through updates.
 	// Valid values are "None", empty string (""), or a valid IP address. "None"
 	// can be specified for headless services when proxying is not required.
-	// Only applies to types ClusterIP and LoadBalancer.
-	// Ignored if type is ExternalName.
+	// Only applies to types ClusterIP, LoadBalancer and NodePort.
+	// Ignored if type is ExternalName. Must be "None" or empty if type is
+	// Headless.
 	// More info: https://kubernetes.io/docs/concepts/services-networking/service/#virtual-ips-and-service-proxies
 	// +optional
 	ClusterIP string `json:"clusterIp,omitempty"`
@@ -126,6 +187,29 @@ type VirtualMachineServiceSpec struct {
 	// and requires Type to be ExternalName.
 	// +optional
 	ExternalName string `json:"externalName,omitempty"`
+
+	// IPFamilies is a list of IP families (e.g. IPv4, IPv6) assigned to this
+	// VirtualMachineService. The first family is the primary family of the
+	// service and cannot be changed through updates. When omitted, the
+	// families are assigned based on IPFamilyPolicy and the configuration of
+	// the cluster. At most two entries, one for each family, may be specified.
+	//
+	// An EndpointSlice is maintained for each family of the service with the
+	// addresses of that family that are reported by the VirtualMachines.
+	//
+	// Ignored if type is ExternalName.
+	// +optional
+	// +listType=atomic
+	IPFamilies []IPFamily `json:"ipFamilies,omitempty"`
+
+	// IPFamilyPolicy represents the dual-stack-ness requested or required by
+	// this VirtualMachineService. If not specified, it defaults to SingleStack.
+	// Valid values are "SingleStack", "PreferDualStack", and
+	// "RequireDualStack".
+	//
+	// Ignored if type is ExternalName.
+	// +optional
+	IPFamilyPolicy *IPFamilyPolicy `json:"ipFamilyPolicy,omitempty"`
 }
 
 // VirtualMachineServiceStatus defines the observed state of
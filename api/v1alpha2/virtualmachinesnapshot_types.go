@@ -0,0 +1,196 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha2
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// VirtualMachineSnapshotConditionCreated is the Type for a
+	// VirtualMachineSnapshot resource's status condition.
+	//
+	// The condition's status is set to true only when the snapshot has been
+	// taken on the underlying infrastructure.
+	VirtualMachineSnapshotConditionCreated = "SnapshotCreated"
+)
+
+// Condition.Reason for Conditions related to VirtualMachineSnapshot.
+const (
+	// VirtualMachineSnapshotVMNotFoundReason documents that the VM referenced
+	// by the VirtualMachineSnapshot does not exist.
+	VirtualMachineSnapshotVMNotFoundReason = "VirtualMachineNotFound"
+
+	// VirtualMachineSnapshotVMNotCreatedReason documents that the VM
+	// referenced by the VirtualMachineSnapshot has not been created on the
+	// underlying infrastructure yet.
+	VirtualMachineSnapshotVMNotCreatedReason = "VirtualMachineNotCreated"
+
+	// VirtualMachineSnapshotCreateFailedReason documents that taking the
+	// snapshot failed.
+	VirtualMachineSnapshotCreateFailedReason = "CreateFailed"
+
+	// VirtualMachineSnapshotNotFoundReason documents that the snapshot
+	// specified by a VM's spec.currentSnapshotName does not exist.
+	VirtualMachineSnapshotNotFoundReason = "VirtualMachineSnapshotNotFound"
+
+	// VirtualMachineSnapshotNotReadyReason documents that the snapshot
+	// specified by a VM's spec.currentSnapshotName is not of the VM or is not
+	// ready to be reverted to.
+	VirtualMachineSnapshotNotReadyReason = "VirtualMachineSnapshotNotReady"
+
+	// VirtualMachineSnapshotRevertFailedReason documents that reverting a VM
+	// to the snapshot specified by the VM's spec.currentSnapshotName failed.
+	VirtualMachineSnapshotRevertFailedReason = "RevertFailed"
+)
+
+// VirtualMachineSnapshotSpec defines the desired state of a
+// VirtualMachineSnapshot.
+type VirtualMachineSnapshotSpec struct {
+	// VMName is the name of the VirtualMachine resource, in the same Namespace
+	// as this snapshot, for which the snapshot is taken.
+	VMName string `json:"vmName"`
+
+	// Description is a description of the snapshot.
+	//
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// Memory describes whether to include a dump of the VM's memory in the
+	// snapshot. This only has an effect if the VM is powered on when the
+	// snapshot is taken.
+	//
+	// Snapshots that include memory may be reverted to without power cycling
+	// the VM, but take longer to create and consume more storage.
+	//
+	// +optional
+	Memory bool `json:"memory,omitempty"`
+
+	// Quiesce describes whether VMware Tools should be used to quiesce the
+	// guest file system before the snapshot is taken. This requires the VM
+	// to be powered on with VMware Tools running.
+	//
+	// Please note this field is ignored when Memory is true.
+	//
+	// +optional
+	Quiesce bool `json:"quiesce,omitempty"`
+}
+
+// VirtualMachineSnapshotStatus defines the observed state of a
+// VirtualMachineSnapshot.
+type VirtualMachineSnapshotStatus struct {
+	// UniqueID describes a unique identifier for the snapshot that is
+	// provided by the underlying infrastructure provider, such as vSphere.
+	//
+	// +optional
+	UniqueID string `json:"uniqueID,omitempty"`
+
+	// CreationTime is the time the snapshot was taken on the underlying
+	// infrastructure.
+	//
+	// +optional
+	CreationTime *metav1.Time `json:"creationTime,omitempty"`
+
+	// PowerState describes the power state of the VM at the time the snapshot
+	// was taken.
+	//
+	// +optional
+	PowerState VirtualMachinePowerState `json:"powerState,omitempty"`
+
+	// Memory describes whether the snapshot includes the VM's memory.
+	//
+	// +optional
+	Memory bool `json:"memory,omitempty"`
+
+	// Quiesced describes whether the guest file system was quiesced when the
+	// snapshot was taken.
+	//
+	// +optional
+	Quiesced bool `json:"quiesced,omitempty"`
+
+	// Size is the amount of storage consumed by the files that make up this
+	// snapshot.
+	//
+	// +optional
+	Size *resource.Quantity `json:"size,omitempty"`
+
+	// Parent is the name of this snapshot's parent in the VM's snapshot
+	// tree. It is empty for the root snapshot.
+	//
+	// +optional
+	Parent string `json:"parent,omitempty"`
+
+	// Children are the names of the snapshots in the VM's snapshot tree
+	// that were taken from this snapshot.
+	//
+	// +optional
+	Children []string `json:"children,omitempty"`
+
+	// Current describes whether this snapshot is the VM's current snapshot,
+	// i.e. the snapshot on which the VM's present state is based.
+	//
+	// +optional
+	Current bool `json:"current,omitempty"`
+
+	// Ready is set to true only when the snapshot has been taken and is
+	// available to be reverted to.
+	//
+	// +optional
+	Ready bool `json:"ready,omitempty"`
+
+	// Conditions describes the observed conditions of the snapshot.
+	//
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Namespaced,shortName=vmsnapshot
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="VirtualMachine",type="string",JSONPath=".spec.vmName"
+// +kubebuilder:printcolumn:name="Ready",type="boolean",JSONPath=".status.ready"
+// +kubebuilder:printcolumn:name="Current",type="boolean",JSONPath=".status.current"
+// +kubebuilder:printcolumn:name="Size",type="string",priority=1,JSONPath=".status.size"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// VirtualMachineSnapshot is the schema for the virtualmachinesnapshots API and
+// represents a point-in-time snapshot of a VirtualMachine.
+type VirtualMachineSnapshot struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VirtualMachineSnapshotSpec   `json:"spec,omitempty"`
+	Status VirtualMachineSnapshotStatus `json:"status,omitempty"`
+}
+
+func (vmSnapshot *VirtualMachineSnapshot) NamespacedName() string {
+	return vmSnapshot.Namespace + "/" + vmSnapshot.Name
+}
+
+func (vmSnapshot *VirtualMachineSnapshot) GetConditions() []metav1.Condition {
+	return vmSnapshot.Status.Conditions
+}
+
+func (vmSnapshot *VirtualMachineSnapshot) SetConditions(conditions []metav1.Condition) {
+	vmSnapshot.Status.Conditions = conditions
+}
+
+// +kubebuilder:object:root=true
+
+// VirtualMachineSnapshotList contains a list of VirtualMachineSnapshot
+// resources.
+type VirtualMachineSnapshotList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VirtualMachineSnapshot `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(
+		&VirtualMachineSnapshot{},
+		&VirtualMachineSnapshotList{},
+	)
+}
@@ -91,6 +91,22 @@ func (in *DynamicDirectPathIODevice) DeepCopy() *DynamicDirectPathIODevice {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EphemeralVolumeSource) DeepCopyInto(out *EphemeralVolumeSource) {
+	*out = *in
+	out.Size = in.Size.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EphemeralVolumeSource.
+func (in *EphemeralVolumeSource) DeepCopy() *EphemeralVolumeSource {
+	if in == nil {
+		return nil
+	}
+	out := new(EphemeralVolumeSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GuestHeartbeatAction) DeepCopyInto(out *GuestHeartbeatAction) {
 	*out = *in
@@ -121,6 +137,77 @@ func (in *GuestInfoAction) DeepCopy() *GuestInfoAction {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPGetAction) DeepCopyInto(out *HTTPGetAction) {
+	*out = *in
+	out.Port = in.Port
+	if in.HTTPHeaders != nil {
+		in, out := &in.HTTPHeaders, &out.HTTPHeaders
+		*out = make([]HTTPHeader, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExpectedStatus != nil {
+		in, out := &in.ExpectedStatus, &out.ExpectedStatus
+		*out = new(HTTPStatusCodeRange)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPGetAction.
+func (in *HTTPGetAction) DeepCopy() *HTTPGetAction {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPGetAction)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPHeader) DeepCopyInto(out *HTTPHeader) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPHeader.
+func (in *HTTPHeader) DeepCopy() *HTTPHeader {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPHeader)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPStatusCodeRange) DeepCopyInto(out *HTTPStatusCodeRange) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPStatusCodeRange.
+func (in *HTTPStatusCodeRange) DeepCopy() *HTTPStatusCodeRange {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPStatusCodeRange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageVolumeSource) DeepCopyInto(out *ImageVolumeSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageVolumeSource.
+func (in *ImageVolumeSource) DeepCopy() *ImageVolumeSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageVolumeSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InstanceStorage) DeepCopyInto(out *InstanceStorage) {
 	*out = *in
@@ -286,6 +373,16 @@ func (in *PersistentVolumeClaimVolumeSource) DeepCopyInto(out *PersistentVolumeC
 		*out = new(InstanceVolumeClaimVolumeSource)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ControllerBusNumber != nil {
+		in, out := &in.ControllerBusNumber, &out.ControllerBusNumber
+		*out = new(int32)
+		**out = **in
+	}
+	if in.UnitNumber != nil {
+		in, out := &in.UnitNumber, &out.UnitNumber
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PersistentVolumeClaimVolumeSource.
@@ -463,6 +560,21 @@ func (in *VirtualMachineBootstrapCloudInitSpec) DeepCopy() *VirtualMachineBootst
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineBootstrapCustomizationSpec) DeepCopyInto(out *VirtualMachineBootstrapCustomizationSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineBootstrapCustomizationSpec.
+func (in *VirtualMachineBootstrapCustomizationSpec) DeepCopy() *VirtualMachineBootstrapCustomizationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineBootstrapCustomizationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VirtualMachineBootstrapLinuxPrepSpec) DeepCopyInto(out *VirtualMachineBootstrapLinuxPrepSpec) {
 	*out = *in
@@ -501,6 +613,11 @@ func (in *VirtualMachineBootstrapSpec) DeepCopyInto(out *VirtualMachineBootstrap
 		*out = new(VirtualMachineBootstrapVAppConfigSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Customization != nil {
+		in, out := &in.Customization, &out.Customization
+		*out = new(VirtualMachineBootstrapCustomizationSpec)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineBootstrapSpec.
@@ -723,26 +840,71 @@ func (in *VirtualMachineConfigSpec) DeepCopy() *VirtualMachineConfigSpec {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachineImage) DeepCopyInto(out *VirtualMachineImage) {
+func (in *VirtualMachineExportFile) DeepCopyInto(out *VirtualMachineExportFile) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineExportFile.
+func (in *VirtualMachineExportFile) DeepCopy() *VirtualMachineExportFile {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineExportFile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineExportObjectStoreTarget) DeepCopyInto(out *VirtualMachineExportObjectStoreTarget) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineExportObjectStoreTarget.
+func (in *VirtualMachineExportObjectStoreTarget) DeepCopy() *VirtualMachineExportObjectStoreTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineExportObjectStoreTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineExportPersistentVolumeClaimTarget) DeepCopyInto(out *VirtualMachineExportPersistentVolumeClaimTarget) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineExportPersistentVolumeClaimTarget.
+func (in *VirtualMachineExportPersistentVolumeClaimTarget) DeepCopy() *VirtualMachineExportPersistentVolumeClaimTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineExportPersistentVolumeClaimTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineExportRequest) DeepCopyInto(out *VirtualMachineExportRequest) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineImage.
-func (in *VirtualMachineImage) DeepCopy() *VirtualMachineImage {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineExportRequest.
+func (in *VirtualMachineExportRequest) DeepCopy() *VirtualMachineExportRequest {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachineImage)
+	out := new(VirtualMachineExportRequest)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *VirtualMachineImage) DeepCopyObject() runtime.Object {
+func (in *VirtualMachineExportRequest) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -750,31 +912,31 @@ func (in *VirtualMachineImage) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachineImageList) DeepCopyInto(out *VirtualMachineImageList) {
+func (in *VirtualMachineExportRequestList) DeepCopyInto(out *VirtualMachineExportRequestList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]VirtualMachineImage, len(*in))
+		*out = make([]VirtualMachineExportRequest, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineImageList.
-func (in *VirtualMachineImageList) DeepCopy() *VirtualMachineImageList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineExportRequestList.
+func (in *VirtualMachineExportRequestList) DeepCopy() *VirtualMachineExportRequestList {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachineImageList)
+	out := new(VirtualMachineExportRequestList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *VirtualMachineImageList) DeepCopyObject() runtime.Object {
+func (in *VirtualMachineExportRequestList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -782,78 +944,47 @@ func (in *VirtualMachineImageList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachineImageOSInfo) DeepCopyInto(out *VirtualMachineImageOSInfo) {
-	*out = *in
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineImageOSInfo.
-func (in *VirtualMachineImageOSInfo) DeepCopy() *VirtualMachineImageOSInfo {
-	if in == nil {
-		return nil
-	}
-	out := new(VirtualMachineImageOSInfo)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachineImageProductInfo) DeepCopyInto(out *VirtualMachineImageProductInfo) {
+func (in *VirtualMachineExportRequestSource) DeepCopyInto(out *VirtualMachineExportRequestSource) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineImageProductInfo.
-func (in *VirtualMachineImageProductInfo) DeepCopy() *VirtualMachineImageProductInfo {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineExportRequestSource.
+func (in *VirtualMachineExportRequestSource) DeepCopy() *VirtualMachineExportRequestSource {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachineImageProductInfo)
+	out := new(VirtualMachineExportRequestSource)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachineImageSpec) DeepCopyInto(out *VirtualMachineImageSpec) {
+func (in *VirtualMachineExportRequestSpec) DeepCopyInto(out *VirtualMachineExportRequestSpec) {
 	*out = *in
-	out.ProviderRef = in.ProviderRef
+	out.Source = in.Source
+	in.Target.DeepCopyInto(&out.Target)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineImageSpec.
-func (in *VirtualMachineImageSpec) DeepCopy() *VirtualMachineImageSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineExportRequestSpec.
+func (in *VirtualMachineExportRequestSpec) DeepCopy() *VirtualMachineExportRequestSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachineImageSpec)
+	out := new(VirtualMachineExportRequestSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachineImageStatus) DeepCopyInto(out *VirtualMachineImageStatus) {
+func (in *VirtualMachineExportRequestStatus) DeepCopyInto(out *VirtualMachineExportRequestStatus) {
 	*out = *in
-	if in.Capabilities != nil {
-		in, out := &in.Capabilities, &out.Capabilities
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.HardwareVersion != nil {
-		in, out := &in.HardwareVersion, &out.HardwareVersion
-		*out = new(int32)
-		**out = **in
-	}
-	out.OSInfo = in.OSInfo
-	if in.OVFProperties != nil {
-		in, out := &in.OVFProperties, &out.OVFProperties
-		*out = make([]OVFProperty, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.VMwareSystemProperties != nil {
-		in, out := &in.VMwareSystemProperties, &out.VMwareSystemProperties
-		*out = make([]common.KeyValuePair, len(*in))
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	in.CompletionTime.DeepCopyInto(&out.CompletionTime)
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = make([]VirtualMachineExportFile, len(*in))
 		copy(*out, *in)
 	}
-	out.ProductInfo = in.ProductInfo
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]v1.Condition, len(*in))
@@ -863,371 +994,380 @@ func (in *VirtualMachineImageStatus) DeepCopyInto(out *VirtualMachineImageStatus
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineImageStatus.
-func (in *VirtualMachineImageStatus) DeepCopy() *VirtualMachineImageStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineExportRequestStatus.
+func (in *VirtualMachineExportRequestStatus) DeepCopy() *VirtualMachineExportRequestStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachineImageStatus)
+	out := new(VirtualMachineExportRequestStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachineList) DeepCopyInto(out *VirtualMachineList) {
+func (in *VirtualMachineExportRequestTarget) DeepCopyInto(out *VirtualMachineExportRequestTarget) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]VirtualMachine, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.PersistentVolumeClaim != nil {
+		in, out := &in.PersistentVolumeClaim, &out.PersistentVolumeClaim
+		*out = new(VirtualMachineExportPersistentVolumeClaimTarget)
+		**out = **in
+	}
+	if in.ObjectStore != nil {
+		in, out := &in.ObjectStore, &out.ObjectStore
+		*out = new(VirtualMachineExportObjectStoreTarget)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineList.
-func (in *VirtualMachineList) DeepCopy() *VirtualMachineList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineExportRequestTarget.
+func (in *VirtualMachineExportRequestTarget) DeepCopy() *VirtualMachineExportRequestTarget {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachineList)
+	out := new(VirtualMachineExportRequestTarget)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *VirtualMachineList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachineNetworkDHCPOptionsStatus) DeepCopyInto(out *VirtualMachineNetworkDHCPOptionsStatus) {
+func (in *VirtualMachineGuestCommandFile) DeepCopyInto(out *VirtualMachineGuestCommandFile) {
 	*out = *in
-	if in.Config != nil {
-		in, out := &in.Config, &out.Config
-		*out = make([]common.KeyValuePair, len(*in))
-		copy(*out, *in)
+	if in.ConfigMap != nil {
+		in, out := &in.ConfigMap, &out.ConfigMap
+		*out = new(VirtualMachineGuestCommandFileKeySelector)
+		**out = **in
+	}
+	if in.Secret != nil {
+		in, out := &in.Secret, &out.Secret
+		*out = new(VirtualMachineGuestCommandFileKeySelector)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineNetworkDHCPOptionsStatus.
-func (in *VirtualMachineNetworkDHCPOptionsStatus) DeepCopy() *VirtualMachineNetworkDHCPOptionsStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineGuestCommandFile.
+func (in *VirtualMachineGuestCommandFile) DeepCopy() *VirtualMachineGuestCommandFile {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachineNetworkDHCPOptionsStatus)
+	out := new(VirtualMachineGuestCommandFile)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachineNetworkDHCPStatus) DeepCopyInto(out *VirtualMachineNetworkDHCPStatus) {
+func (in *VirtualMachineGuestCommandFileKeySelector) DeepCopyInto(out *VirtualMachineGuestCommandFileKeySelector) {
 	*out = *in
-	in.IP4.DeepCopyInto(&out.IP4)
-	in.IP6.DeepCopyInto(&out.IP6)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineNetworkDHCPStatus.
-func (in *VirtualMachineNetworkDHCPStatus) DeepCopy() *VirtualMachineNetworkDHCPStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineGuestCommandFileKeySelector.
+func (in *VirtualMachineGuestCommandFileKeySelector) DeepCopy() *VirtualMachineGuestCommandFileKeySelector {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachineNetworkDHCPStatus)
+	out := new(VirtualMachineGuestCommandFileKeySelector)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachineNetworkDNSStatus) DeepCopyInto(out *VirtualMachineNetworkDNSStatus) {
+func (in *VirtualMachineGuestCommandRequest) DeepCopyInto(out *VirtualMachineGuestCommandRequest) {
 	*out = *in
-	if in.Nameservers != nil {
-		in, out := &in.Nameservers, &out.Nameservers
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.SearchDomains != nil {
-		in, out := &in.SearchDomains, &out.SearchDomains
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineNetworkDNSStatus.
-func (in *VirtualMachineNetworkDNSStatus) DeepCopy() *VirtualMachineNetworkDNSStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineGuestCommandRequest.
+func (in *VirtualMachineGuestCommandRequest) DeepCopy() *VirtualMachineGuestCommandRequest {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachineNetworkDNSStatus)
+	out := new(VirtualMachineGuestCommandRequest)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachineNetworkIPRouteGatewayStatus) DeepCopyInto(out *VirtualMachineNetworkIPRouteGatewayStatus) {
-	*out = *in
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineNetworkIPRouteGatewayStatus.
-func (in *VirtualMachineNetworkIPRouteGatewayStatus) DeepCopy() *VirtualMachineNetworkIPRouteGatewayStatus {
-	if in == nil {
-		return nil
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VirtualMachineGuestCommandRequest) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
 	}
-	out := new(VirtualMachineNetworkIPRouteGatewayStatus)
-	in.DeepCopyInto(out)
-	return out
+	return nil
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachineNetworkIPRouteStatus) DeepCopyInto(out *VirtualMachineNetworkIPRouteStatus) {
+func (in *VirtualMachineGuestCommandRequestList) DeepCopyInto(out *VirtualMachineGuestCommandRequestList) {
 	*out = *in
-	out.Gateway = in.Gateway
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VirtualMachineGuestCommandRequest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineNetworkIPRouteStatus.
-func (in *VirtualMachineNetworkIPRouteStatus) DeepCopy() *VirtualMachineNetworkIPRouteStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineGuestCommandRequestList.
+func (in *VirtualMachineGuestCommandRequestList) DeepCopy() *VirtualMachineGuestCommandRequestList {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachineNetworkIPRouteStatus)
+	out := new(VirtualMachineGuestCommandRequestList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VirtualMachineGuestCommandRequestList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachineNetworkIPStackStatus) DeepCopyInto(out *VirtualMachineNetworkIPStackStatus) {
+func (in *VirtualMachineGuestCommandRequestSpec) DeepCopyInto(out *VirtualMachineGuestCommandRequestSpec) {
 	*out = *in
-	in.DHCP.DeepCopyInto(&out.DHCP)
-	in.DNS.DeepCopyInto(&out.DNS)
-	if in.IPRoutes != nil {
-		in, out := &in.IPRoutes, &out.IPRoutes
-		*out = make([]VirtualMachineNetworkIPRouteStatus, len(*in))
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	if in.KernelConfig != nil {
-		in, out := &in.KernelConfig, &out.KernelConfig
-		*out = make([]common.KeyValuePair, len(*in))
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]common.NameValuePair, len(*in))
 		copy(*out, *in)
 	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineNetworkIPStackStatus.
-func (in *VirtualMachineNetworkIPStackStatus) DeepCopy() *VirtualMachineNetworkIPStackStatus {
-	if in == nil {
-		return nil
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = make([]VirtualMachineGuestCommandFile, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TTLSecondsAfterFinished != nil {
+		in, out := &in.TTLSecondsAfterFinished, &out.TTLSecondsAfterFinished
+		*out = new(int64)
+		**out = **in
 	}
-	out := new(VirtualMachineNetworkIPStackStatus)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachineNetworkInterfaceIPAddrStatus) DeepCopyInto(out *VirtualMachineNetworkInterfaceIPAddrStatus) {
-	*out = *in
-	in.Lifetime.DeepCopyInto(&out.Lifetime)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineNetworkInterfaceIPAddrStatus.
-func (in *VirtualMachineNetworkInterfaceIPAddrStatus) DeepCopy() *VirtualMachineNetworkInterfaceIPAddrStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineGuestCommandRequestSpec.
+func (in *VirtualMachineGuestCommandRequestSpec) DeepCopy() *VirtualMachineGuestCommandRequestSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachineNetworkInterfaceIPAddrStatus)
+	out := new(VirtualMachineGuestCommandRequestSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachineNetworkInterfaceIPStatus) DeepCopyInto(out *VirtualMachineNetworkInterfaceIPStatus) {
+func (in *VirtualMachineGuestCommandRequestStatus) DeepCopyInto(out *VirtualMachineGuestCommandRequestStatus) {
 	*out = *in
-	if in.AutoConfigurationEnabled != nil {
-		in, out := &in.AutoConfigurationEnabled, &out.AutoConfigurationEnabled
-		*out = new(bool)
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	in.CompletionTime.DeepCopyInto(&out.CompletionTime)
+	if in.ExitCode != nil {
+		in, out := &in.ExitCode, &out.ExitCode
+		*out = new(int32)
 		**out = **in
 	}
-	in.DHCP.DeepCopyInto(&out.DHCP)
-	if in.Addresses != nil {
-		in, out := &in.Addresses, &out.Addresses
-		*out = make([]VirtualMachineNetworkInterfaceIPAddrStatus, len(*in))
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineNetworkInterfaceIPStatus.
-func (in *VirtualMachineNetworkInterfaceIPStatus) DeepCopy() *VirtualMachineNetworkInterfaceIPStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineGuestCommandRequestStatus.
+func (in *VirtualMachineGuestCommandRequestStatus) DeepCopy() *VirtualMachineGuestCommandRequestStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachineNetworkInterfaceIPStatus)
+	out := new(VirtualMachineGuestCommandRequestStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachineNetworkInterfaceSpec) DeepCopyInto(out *VirtualMachineNetworkInterfaceSpec) {
+func (in *VirtualMachineGuestCustomizationStatus) DeepCopyInto(out *VirtualMachineGuestCustomizationStatus) {
 	*out = *in
-	out.Network = in.Network
-	if in.Addresses != nil {
-		in, out := &in.Addresses, &out.Addresses
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.MTU != nil {
-		in, out := &in.MTU, &out.MTU
-		*out = new(int64)
-		**out = **in
-	}
-	if in.Nameservers != nil {
-		in, out := &in.Nameservers, &out.Nameservers
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.Routes != nil {
-		in, out := &in.Routes, &out.Routes
-		*out = make([]VirtualMachineNetworkRouteSpec, len(*in))
-		copy(*out, *in)
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
 	}
-	if in.SearchDomains != nil {
-		in, out := &in.SearchDomains, &out.SearchDomains
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	if in.EndTime != nil {
+		in, out := &in.EndTime, &out.EndTime
+		*out = (*in).DeepCopy()
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineNetworkInterfaceSpec.
-func (in *VirtualMachineNetworkInterfaceSpec) DeepCopy() *VirtualMachineNetworkInterfaceSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineGuestCustomizationStatus.
+func (in *VirtualMachineGuestCustomizationStatus) DeepCopy() *VirtualMachineGuestCustomizationStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachineNetworkInterfaceSpec)
+	out := new(VirtualMachineGuestCustomizationStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachineNetworkInterfaceStatus) DeepCopyInto(out *VirtualMachineNetworkInterfaceStatus) {
+func (in *VirtualMachineGuestFilesystemStatus) DeepCopyInto(out *VirtualMachineGuestFilesystemStatus) {
 	*out = *in
-	in.IP.DeepCopyInto(&out.IP)
-	in.DNS.DeepCopyInto(&out.DNS)
+	if in.Capacity != nil {
+		in, out := &in.Capacity, &out.Capacity
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.FreeSpace != nil {
+		in, out := &in.FreeSpace, &out.FreeSpace
+		x := (*in).DeepCopy()
+		*out = &x
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineNetworkInterfaceStatus.
-func (in *VirtualMachineNetworkInterfaceStatus) DeepCopy() *VirtualMachineNetworkInterfaceStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineGuestFilesystemStatus.
+func (in *VirtualMachineGuestFilesystemStatus) DeepCopy() *VirtualMachineGuestFilesystemStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachineNetworkInterfaceStatus)
+	out := new(VirtualMachineGuestFilesystemStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachineNetworkRouteSpec) DeepCopyInto(out *VirtualMachineNetworkRouteSpec) {
+func (in *VirtualMachineGuestStatus) DeepCopyInto(out *VirtualMachineGuestStatus) {
 	*out = *in
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+	if in.Filesystems != nil {
+		in, out := &in.Filesystems, &out.Filesystems
+		*out = make([]VirtualMachineGuestFilesystemStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ConsumedMemory != nil {
+		in, out := &in.ConsumedMemory, &out.ConsumedMemory
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.CommittedStorage != nil {
+		in, out := &in.CommittedStorage, &out.CommittedStorage
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.UncommittedStorage != nil {
+		in, out := &in.UncommittedStorage, &out.UncommittedStorage
+		x := (*in).DeepCopy()
+		*out = &x
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineNetworkRouteSpec.
-func (in *VirtualMachineNetworkRouteSpec) DeepCopy() *VirtualMachineNetworkRouteSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineGuestStatus.
+func (in *VirtualMachineGuestStatus) DeepCopy() *VirtualMachineGuestStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachineNetworkRouteSpec)
+	out := new(VirtualMachineGuestStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachineNetworkRouteStatus) DeepCopyInto(out *VirtualMachineNetworkRouteStatus) {
+func (in *VirtualMachineImage) DeepCopyInto(out *VirtualMachineImage) {
 	*out = *in
-	if in.IPRoutes != nil {
-		in, out := &in.IPRoutes, &out.IPRoutes
-		*out = make([]VirtualMachineNetworkIPRouteStatus, len(*in))
-		copy(*out, *in)
-	}
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineNetworkRouteStatus.
-func (in *VirtualMachineNetworkRouteStatus) DeepCopy() *VirtualMachineNetworkRouteStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineImage.
+func (in *VirtualMachineImage) DeepCopy() *VirtualMachineImage {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachineNetworkRouteStatus)
+	out := new(VirtualMachineImage)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VirtualMachineImage) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachineNetworkSpec) DeepCopyInto(out *VirtualMachineNetworkSpec) {
+func (in *VirtualMachineImageDiskInfo) DeepCopyInto(out *VirtualMachineImageDiskInfo) {
 	*out = *in
-	if in.Interfaces != nil {
-		in, out := &in.Interfaces, &out.Interfaces
-		*out = make([]VirtualMachineNetworkInterfaceSpec, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.Capacity != nil {
+		in, out := &in.Capacity, &out.Capacity
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Size != nil {
+		in, out := &in.Size, &out.Size
+		x := (*in).DeepCopy()
+		*out = &x
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineNetworkSpec.
-func (in *VirtualMachineNetworkSpec) DeepCopy() *VirtualMachineNetworkSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineImageDiskInfo.
+func (in *VirtualMachineImageDiskInfo) DeepCopy() *VirtualMachineImageDiskInfo {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachineNetworkSpec)
+	out := new(VirtualMachineImageDiskInfo)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachineNetworkStatus) DeepCopyInto(out *VirtualMachineNetworkStatus) {
+func (in *VirtualMachineImageImportChecksum) DeepCopyInto(out *VirtualMachineImageImportChecksum) {
 	*out = *in
-	if in.Interfaces != nil {
-		in, out := &in.Interfaces, &out.Interfaces
-		*out = make([]VirtualMachineNetworkInterfaceStatus, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	in.VirtualMachineNetworkIPStackStatus.DeepCopyInto(&out.VirtualMachineNetworkIPStackStatus)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineNetworkStatus.
-func (in *VirtualMachineNetworkStatus) DeepCopy() *VirtualMachineNetworkStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineImageImportChecksum.
+func (in *VirtualMachineImageImportChecksum) DeepCopy() *VirtualMachineImageImportChecksum {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachineNetworkStatus)
+	out := new(VirtualMachineImageImportChecksum)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachinePublishRequest) DeepCopyInto(out *VirtualMachinePublishRequest) {
+func (in *VirtualMachineImageImportRequest) DeepCopyInto(out *VirtualMachineImageImportRequest) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
+	out.Spec = in.Spec
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePublishRequest.
-func (in *VirtualMachinePublishRequest) DeepCopy() *VirtualMachinePublishRequest {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineImageImportRequest.
+func (in *VirtualMachineImageImportRequest) DeepCopy() *VirtualMachineImageImportRequest {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachinePublishRequest)
+	out := new(VirtualMachineImageImportRequest)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *VirtualMachinePublishRequest) DeepCopyObject() runtime.Object {
+func (in *VirtualMachineImageImportRequest) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1235,31 +1375,31 @@ func (in *VirtualMachinePublishRequest) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachinePublishRequestList) DeepCopyInto(out *VirtualMachinePublishRequestList) {
+func (in *VirtualMachineImageImportRequestList) DeepCopyInto(out *VirtualMachineImageImportRequestList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]VirtualMachinePublishRequest, len(*in))
+		*out = make([]VirtualMachineImageImportRequest, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePublishRequestList.
-func (in *VirtualMachinePublishRequestList) DeepCopy() *VirtualMachinePublishRequestList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineImageImportRequestList.
+func (in *VirtualMachineImageImportRequestList) DeepCopy() *VirtualMachineImageImportRequestList {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachinePublishRequestList)
+	out := new(VirtualMachineImageImportRequestList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *VirtualMachinePublishRequestList) DeepCopyObject() runtime.Object {
+func (in *VirtualMachineImageImportRequestList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1267,58 +1407,1207 @@ func (in *VirtualMachinePublishRequestList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachinePublishRequestSource) DeepCopyInto(out *VirtualMachinePublishRequestSource) {
+func (in *VirtualMachineImageImportRequestSource) DeepCopyInto(out *VirtualMachineImageImportRequestSource) {
+	*out = *in
+	out.Checksum = in.Checksum
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineImageImportRequestSource.
+func (in *VirtualMachineImageImportRequestSource) DeepCopy() *VirtualMachineImageImportRequestSource {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineImageImportRequestSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineImageImportRequestSpec) DeepCopyInto(out *VirtualMachineImageImportRequestSpec) {
+	*out = *in
+	out.Source = in.Source
+	out.Target = in.Target
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineImageImportRequestSpec.
+func (in *VirtualMachineImageImportRequestSpec) DeepCopy() *VirtualMachineImageImportRequestSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineImageImportRequestSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineImageImportRequestStatus) DeepCopyInto(out *VirtualMachineImageImportRequestStatus) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	in.CompletionTime.DeepCopyInto(&out.CompletionTime)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineImageImportRequestStatus.
+func (in *VirtualMachineImageImportRequestStatus) DeepCopy() *VirtualMachineImageImportRequestStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineImageImportRequestStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineImageImportRequestTarget) DeepCopyInto(out *VirtualMachineImageImportRequestTarget) {
+	*out = *in
+	out.Item = in.Item
+	out.Location = in.Location
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineImageImportRequestTarget.
+func (in *VirtualMachineImageImportRequestTarget) DeepCopy() *VirtualMachineImageImportRequestTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineImageImportRequestTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineImageImportRequestTargetItem) DeepCopyInto(out *VirtualMachineImageImportRequestTargetItem) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePublishRequestSource.
-func (in *VirtualMachinePublishRequestSource) DeepCopy() *VirtualMachinePublishRequestSource {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineImageImportRequestTargetItem.
+func (in *VirtualMachineImageImportRequestTargetItem) DeepCopy() *VirtualMachineImageImportRequestTargetItem {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineImageImportRequestTargetItem)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineImageImportRequestTargetLocation) DeepCopyInto(out *VirtualMachineImageImportRequestTargetLocation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineImageImportRequestTargetLocation.
+func (in *VirtualMachineImageImportRequestTargetLocation) DeepCopy() *VirtualMachineImageImportRequestTargetLocation {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineImageImportRequestTargetLocation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineImageList) DeepCopyInto(out *VirtualMachineImageList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VirtualMachineImage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineImageList.
+func (in *VirtualMachineImageList) DeepCopy() *VirtualMachineImageList {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineImageList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VirtualMachineImageList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineImageOSInfo) DeepCopyInto(out *VirtualMachineImageOSInfo) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineImageOSInfo.
+func (in *VirtualMachineImageOSInfo) DeepCopy() *VirtualMachineImageOSInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineImageOSInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineImageProductInfo) DeepCopyInto(out *VirtualMachineImageProductInfo) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineImageProductInfo.
+func (in *VirtualMachineImageProductInfo) DeepCopy() *VirtualMachineImageProductInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineImageProductInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineImageSpec) DeepCopyInto(out *VirtualMachineImageSpec) {
+	*out = *in
+	out.ProviderRef = in.ProviderRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineImageSpec.
+func (in *VirtualMachineImageSpec) DeepCopy() *VirtualMachineImageSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineImageSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineImageStatus) DeepCopyInto(out *VirtualMachineImageStatus) {
+	*out = *in
+	if in.Capabilities != nil {
+		in, out := &in.Capabilities, &out.Capabilities
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.HardwareVersion != nil {
+		in, out := &in.HardwareVersion, &out.HardwareVersion
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Disks != nil {
+		in, out := &in.Disks, &out.Disks
+		*out = make([]VirtualMachineImageDiskInfo, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	out.OSInfo = in.OSInfo
+	if in.OVFProperties != nil {
+		in, out := &in.OVFProperties, &out.OVFProperties
+		*out = make([]OVFProperty, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VMwareSystemProperties != nil {
+		in, out := &in.VMwareSystemProperties, &out.VMwareSystemProperties
+		*out = make([]common.KeyValuePair, len(*in))
+		copy(*out, *in)
+	}
+	out.ProductInfo = in.ProductInfo
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineImageStatus.
+func (in *VirtualMachineImageStatus) DeepCopy() *VirtualMachineImageStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineImageStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineList) DeepCopyInto(out *VirtualMachineList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VirtualMachine, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineList.
+func (in *VirtualMachineList) DeepCopy() *VirtualMachineList {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VirtualMachineList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineLivenessProbeSpec) DeepCopyInto(out *VirtualMachineLivenessProbeSpec) {
+	*out = *in
+	in.VirtualMachineReadinessProbeSpec.DeepCopyInto(&out.VirtualMachineReadinessProbeSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineLivenessProbeSpec.
+func (in *VirtualMachineLivenessProbeSpec) DeepCopy() *VirtualMachineLivenessProbeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineLivenessProbeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineLivenessStatus) DeepCopyInto(out *VirtualMachineLivenessStatus) {
+	*out = *in
+	if in.LastFailureTime != nil {
+		in, out := &in.LastFailureTime, &out.LastFailureTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastRestartTime != nil {
+		in, out := &in.LastRestartTime, &out.LastRestartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.PoweredOnTime != nil {
+		in, out := &in.PoweredOnTime, &out.PoweredOnTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineLivenessStatus.
+func (in *VirtualMachineLivenessStatus) DeepCopy() *VirtualMachineLivenessStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineLivenessStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineNetworkDHCPOptionsStatus) DeepCopyInto(out *VirtualMachineNetworkDHCPOptionsStatus) {
+	*out = *in
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = make([]common.KeyValuePair, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineNetworkDHCPOptionsStatus.
+func (in *VirtualMachineNetworkDHCPOptionsStatus) DeepCopy() *VirtualMachineNetworkDHCPOptionsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineNetworkDHCPOptionsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineNetworkDHCPStatus) DeepCopyInto(out *VirtualMachineNetworkDHCPStatus) {
+	*out = *in
+	in.IP4.DeepCopyInto(&out.IP4)
+	in.IP6.DeepCopyInto(&out.IP6)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineNetworkDHCPStatus.
+func (in *VirtualMachineNetworkDHCPStatus) DeepCopy() *VirtualMachineNetworkDHCPStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineNetworkDHCPStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineNetworkDNSStatus) DeepCopyInto(out *VirtualMachineNetworkDNSStatus) {
+	*out = *in
+	if in.Nameservers != nil {
+		in, out := &in.Nameservers, &out.Nameservers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SearchDomains != nil {
+		in, out := &in.SearchDomains, &out.SearchDomains
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineNetworkDNSStatus.
+func (in *VirtualMachineNetworkDNSStatus) DeepCopy() *VirtualMachineNetworkDNSStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineNetworkDNSStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineNetworkIPRouteGatewayStatus) DeepCopyInto(out *VirtualMachineNetworkIPRouteGatewayStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineNetworkIPRouteGatewayStatus.
+func (in *VirtualMachineNetworkIPRouteGatewayStatus) DeepCopy() *VirtualMachineNetworkIPRouteGatewayStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineNetworkIPRouteGatewayStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineNetworkIPRouteStatus) DeepCopyInto(out *VirtualMachineNetworkIPRouteStatus) {
+	*out = *in
+	out.Gateway = in.Gateway
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineNetworkIPRouteStatus.
+func (in *VirtualMachineNetworkIPRouteStatus) DeepCopy() *VirtualMachineNetworkIPRouteStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineNetworkIPRouteStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineNetworkIPStackStatus) DeepCopyInto(out *VirtualMachineNetworkIPStackStatus) {
+	*out = *in
+	in.DHCP.DeepCopyInto(&out.DHCP)
+	in.DNS.DeepCopyInto(&out.DNS)
+	if in.IPRoutes != nil {
+		in, out := &in.IPRoutes, &out.IPRoutes
+		*out = make([]VirtualMachineNetworkIPRouteStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.KernelConfig != nil {
+		in, out := &in.KernelConfig, &out.KernelConfig
+		*out = make([]common.KeyValuePair, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineNetworkIPStackStatus.
+func (in *VirtualMachineNetworkIPStackStatus) DeepCopy() *VirtualMachineNetworkIPStackStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineNetworkIPStackStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineNetworkInterfaceIPAddrStatus) DeepCopyInto(out *VirtualMachineNetworkInterfaceIPAddrStatus) {
+	*out = *in
+	in.Lifetime.DeepCopyInto(&out.Lifetime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineNetworkInterfaceIPAddrStatus.
+func (in *VirtualMachineNetworkInterfaceIPAddrStatus) DeepCopy() *VirtualMachineNetworkInterfaceIPAddrStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineNetworkInterfaceIPAddrStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineNetworkInterfaceIPStatus) DeepCopyInto(out *VirtualMachineNetworkInterfaceIPStatus) {
+	*out = *in
+	if in.AutoConfigurationEnabled != nil {
+		in, out := &in.AutoConfigurationEnabled, &out.AutoConfigurationEnabled
+		*out = new(bool)
+		**out = **in
+	}
+	in.DHCP.DeepCopyInto(&out.DHCP)
+	if in.Addresses != nil {
+		in, out := &in.Addresses, &out.Addresses
+		*out = make([]VirtualMachineNetworkInterfaceIPAddrStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineNetworkInterfaceIPStatus.
+func (in *VirtualMachineNetworkInterfaceIPStatus) DeepCopy() *VirtualMachineNetworkInterfaceIPStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineNetworkInterfaceIPStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineNetworkInterfacePendingStatus) DeepCopyInto(out *VirtualMachineNetworkInterfacePendingStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineNetworkInterfacePendingStatus.
+func (in *VirtualMachineNetworkInterfacePendingStatus) DeepCopy() *VirtualMachineNetworkInterfacePendingStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineNetworkInterfacePendingStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineNetworkInterfaceSpec) DeepCopyInto(out *VirtualMachineNetworkInterfaceSpec) {
+	*out = *in
+	out.Network = in.Network
+	if in.Addresses != nil {
+		in, out := &in.Addresses, &out.Addresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MTU != nil {
+		in, out := &in.MTU, &out.MTU
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Nameservers != nil {
+		in, out := &in.Nameservers, &out.Nameservers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Routes != nil {
+		in, out := &in.Routes, &out.Routes
+		*out = make([]VirtualMachineNetworkRouteSpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.SearchDomains != nil {
+		in, out := &in.SearchDomains, &out.SearchDomains
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineNetworkInterfaceSpec.
+func (in *VirtualMachineNetworkInterfaceSpec) DeepCopy() *VirtualMachineNetworkInterfaceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineNetworkInterfaceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineNetworkInterfaceStatus) DeepCopyInto(out *VirtualMachineNetworkInterfaceStatus) {
+	*out = *in
+	in.IP.DeepCopyInto(&out.IP)
+	in.DNS.DeepCopyInto(&out.DNS)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineNetworkInterfaceStatus.
+func (in *VirtualMachineNetworkInterfaceStatus) DeepCopy() *VirtualMachineNetworkInterfaceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineNetworkInterfaceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineNetworkRouteSpec) DeepCopyInto(out *VirtualMachineNetworkRouteSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineNetworkRouteSpec.
+func (in *VirtualMachineNetworkRouteSpec) DeepCopy() *VirtualMachineNetworkRouteSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineNetworkRouteSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineNetworkRouteStatus) DeepCopyInto(out *VirtualMachineNetworkRouteStatus) {
+	*out = *in
+	if in.IPRoutes != nil {
+		in, out := &in.IPRoutes, &out.IPRoutes
+		*out = make([]VirtualMachineNetworkIPRouteStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineNetworkRouteStatus.
+func (in *VirtualMachineNetworkRouteStatus) DeepCopy() *VirtualMachineNetworkRouteStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineNetworkRouteStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineNetworkSpec) DeepCopyInto(out *VirtualMachineNetworkSpec) {
+	*out = *in
+	if in.Interfaces != nil {
+		in, out := &in.Interfaces, &out.Interfaces
+		*out = make([]VirtualMachineNetworkInterfaceSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineNetworkSpec.
+func (in *VirtualMachineNetworkSpec) DeepCopy() *VirtualMachineNetworkSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineNetworkSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineNetworkStatus) DeepCopyInto(out *VirtualMachineNetworkStatus) {
+	*out = *in
+	if in.Interfaces != nil {
+		in, out := &in.Interfaces, &out.Interfaces
+		*out = make([]VirtualMachineNetworkInterfaceStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PendingGuestConfig != nil {
+		in, out := &in.PendingGuestConfig, &out.PendingGuestConfig
+		*out = make([]VirtualMachineNetworkInterfacePendingStatus, len(*in))
+		copy(*out, *in)
+	}
+	in.VirtualMachineNetworkIPStackStatus.DeepCopyInto(&out.VirtualMachineNetworkIPStackStatus)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineNetworkStatus.
+func (in *VirtualMachineNetworkStatus) DeepCopy() *VirtualMachineNetworkStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineNetworkStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachinePort) DeepCopyInto(out *VirtualMachinePort) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePort.
+func (in *VirtualMachinePort) DeepCopy() *VirtualMachinePort {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachinePort)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachinePowerSchedule) DeepCopyInto(out *VirtualMachinePowerSchedule) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePowerSchedule.
+func (in *VirtualMachinePowerSchedule) DeepCopy() *VirtualMachinePowerSchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachinePowerSchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VirtualMachinePowerSchedule) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachinePowerScheduleList) DeepCopyInto(out *VirtualMachinePowerScheduleList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VirtualMachinePowerSchedule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePowerScheduleList.
+func (in *VirtualMachinePowerScheduleList) DeepCopy() *VirtualMachinePowerScheduleList {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachinePowerScheduleList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VirtualMachinePowerScheduleList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachinePowerScheduleSpec) DeepCopyInto(out *VirtualMachinePowerScheduleSpec) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StartingDeadlineSeconds != nil {
+		in, out := &in.StartingDeadlineSeconds, &out.StartingDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePowerScheduleSpec.
+func (in *VirtualMachinePowerScheduleSpec) DeepCopy() *VirtualMachinePowerScheduleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachinePowerScheduleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachinePowerScheduleStatus) DeepCopyInto(out *VirtualMachinePowerScheduleStatus) {
+	*out = *in
+	if in.LastRunTime != nil {
+		in, out := &in.LastRunTime, &out.LastRunTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NextRunTime != nil {
+		in, out := &in.NextRunTime, &out.NextRunTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePowerScheduleStatus.
+func (in *VirtualMachinePowerScheduleStatus) DeepCopy() *VirtualMachinePowerScheduleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachinePowerScheduleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachinePublishRequest) DeepCopyInto(out *VirtualMachinePublishRequest) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePublishRequest.
+func (in *VirtualMachinePublishRequest) DeepCopy() *VirtualMachinePublishRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachinePublishRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VirtualMachinePublishRequest) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachinePublishRequestList) DeepCopyInto(out *VirtualMachinePublishRequestList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VirtualMachinePublishRequest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePublishRequestList.
+func (in *VirtualMachinePublishRequestList) DeepCopy() *VirtualMachinePublishRequestList {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachinePublishRequestList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VirtualMachinePublishRequestList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachinePublishRequestSource) DeepCopyInto(out *VirtualMachinePublishRequestSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePublishRequestSource.
+func (in *VirtualMachinePublishRequestSource) DeepCopy() *VirtualMachinePublishRequestSource {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachinePublishRequestSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachinePublishRequestSpec) DeepCopyInto(out *VirtualMachinePublishRequestSpec) {
+	*out = *in
+	out.Source = in.Source
+	out.Target = in.Target
+	if in.TTLSecondsAfterFinished != nil {
+		in, out := &in.TTLSecondsAfterFinished, &out.TTLSecondsAfterFinished
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePublishRequestSpec.
+func (in *VirtualMachinePublishRequestSpec) DeepCopy() *VirtualMachinePublishRequestSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachinePublishRequestSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachinePublishRequestStatus) DeepCopyInto(out *VirtualMachinePublishRequestStatus) {
+	*out = *in
+	if in.SourceRef != nil {
+		in, out := &in.SourceRef, &out.SourceRef
+		*out = new(VirtualMachinePublishRequestSource)
+		**out = **in
+	}
+	if in.TargetRef != nil {
+		in, out := &in.TargetRef, &out.TargetRef
+		*out = new(VirtualMachinePublishRequestTarget)
+		**out = **in
+	}
+	in.CompletionTime.DeepCopyInto(&out.CompletionTime)
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	in.LastAttemptTime.DeepCopyInto(&out.LastAttemptTime)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePublishRequestStatus.
+func (in *VirtualMachinePublishRequestStatus) DeepCopy() *VirtualMachinePublishRequestStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachinePublishRequestStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachinePublishRequestTarget) DeepCopyInto(out *VirtualMachinePublishRequestTarget) {
+	*out = *in
+	out.Item = in.Item
+	out.Location = in.Location
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePublishRequestTarget.
+func (in *VirtualMachinePublishRequestTarget) DeepCopy() *VirtualMachinePublishRequestTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachinePublishRequestTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachinePublishRequestTargetItem) DeepCopyInto(out *VirtualMachinePublishRequestTargetItem) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePublishRequestTargetItem.
+func (in *VirtualMachinePublishRequestTargetItem) DeepCopy() *VirtualMachinePublishRequestTargetItem {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachinePublishRequestTargetItem)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachinePublishRequestTargetLocation) DeepCopyInto(out *VirtualMachinePublishRequestTargetLocation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePublishRequestTargetLocation.
+func (in *VirtualMachinePublishRequestTargetLocation) DeepCopy() *VirtualMachinePublishRequestTargetLocation {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachinePublishRequestTargetLocation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineReadinessProbeSpec) DeepCopyInto(out *VirtualMachineReadinessProbeSpec) {
+	*out = *in
+	if in.TCPSocket != nil {
+		in, out := &in.TCPSocket, &out.TCPSocket
+		*out = new(TCPSocketAction)
+		**out = **in
+	}
+	if in.HTTPGet != nil {
+		in, out := &in.HTTPGet, &out.HTTPGet
+		*out = new(HTTPGetAction)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GuestHeartbeat != nil {
+		in, out := &in.GuestHeartbeat, &out.GuestHeartbeat
+		*out = new(GuestHeartbeatAction)
+		**out = **in
+	}
+	if in.GuestInfo != nil {
+		in, out := &in.GuestInfo, &out.GuestInfo
+		*out = make([]GuestInfoAction, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineReadinessProbeSpec.
+func (in *VirtualMachineReadinessProbeSpec) DeepCopy() *VirtualMachineReadinessProbeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineReadinessProbeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineReplicaSet) DeepCopyInto(out *VirtualMachineReplicaSet) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineReplicaSet.
+func (in *VirtualMachineReplicaSet) DeepCopy() *VirtualMachineReplicaSet {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineReplicaSet)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VirtualMachineReplicaSet) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineReplicaSetList) DeepCopyInto(out *VirtualMachineReplicaSetList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VirtualMachineReplicaSet, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineReplicaSetList.
+func (in *VirtualMachineReplicaSetList) DeepCopy() *VirtualMachineReplicaSetList {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineReplicaSetList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VirtualMachineReplicaSetList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineReplicaSetSpec) DeepCopyInto(out *VirtualMachineReplicaSetSpec) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Template.DeepCopyInto(&out.Template)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineReplicaSetSpec.
+func (in *VirtualMachineReplicaSetSpec) DeepCopy() *VirtualMachineReplicaSetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineReplicaSetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineReplicaSetStatus) DeepCopyInto(out *VirtualMachineReplicaSetStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineReplicaSetStatus.
+func (in *VirtualMachineReplicaSetStatus) DeepCopy() *VirtualMachineReplicaSetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineReplicaSetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineReservedSpec) DeepCopyInto(out *VirtualMachineReservedSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineReservedSpec.
+func (in *VirtualMachineReservedSpec) DeepCopy() *VirtualMachineReservedSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineReservedSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineResourceSpec) DeepCopyInto(out *VirtualMachineResourceSpec) {
+	*out = *in
+	out.Cpu = in.Cpu.DeepCopy()
+	out.Memory = in.Memory.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineResourceSpec.
+func (in *VirtualMachineResourceSpec) DeepCopy() *VirtualMachineResourceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineResourceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineRestoreRequest) DeepCopyInto(out *VirtualMachineRestoreRequest) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineRestoreRequest.
+func (in *VirtualMachineRestoreRequest) DeepCopy() *VirtualMachineRestoreRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineRestoreRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VirtualMachineRestoreRequest) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineRestoreRequestList) DeepCopyInto(out *VirtualMachineRestoreRequestList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VirtualMachineRestoreRequest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineRestoreRequestList.
+func (in *VirtualMachineRestoreRequestList) DeepCopy() *VirtualMachineRestoreRequestList {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachinePublishRequestSource)
+	out := new(VirtualMachineRestoreRequestList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VirtualMachineRestoreRequestList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachinePublishRequestSpec) DeepCopyInto(out *VirtualMachinePublishRequestSpec) {
+func (in *VirtualMachineRestoreRequestSpec) DeepCopyInto(out *VirtualMachineRestoreRequestSpec) {
 	*out = *in
-	out.Source = in.Source
-	out.Target = in.Target
-	if in.TTLSecondsAfterFinished != nil {
-		in, out := &in.TTLSecondsAfterFinished, &out.TTLSecondsAfterFinished
-		*out = new(int64)
-		**out = **in
+	if in.VirtualMachineNames != nil {
+		in, out := &in.VirtualMachineNames, &out.VirtualMachineNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePublishRequestSpec.
-func (in *VirtualMachinePublishRequestSpec) DeepCopy() *VirtualMachinePublishRequestSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineRestoreRequestSpec.
+func (in *VirtualMachineRestoreRequestSpec) DeepCopy() *VirtualMachineRestoreRequestSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachinePublishRequestSpec)
+	out := new(VirtualMachineRestoreRequestSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachinePublishRequestStatus) DeepCopyInto(out *VirtualMachinePublishRequestStatus) {
+func (in *VirtualMachineRestoreRequestStatus) DeepCopyInto(out *VirtualMachineRestoreRequestStatus) {
 	*out = *in
-	if in.SourceRef != nil {
-		in, out := &in.SourceRef, &out.SourceRef
-		*out = new(VirtualMachinePublishRequestSource)
-		**out = **in
-	}
-	if in.TargetRef != nil {
-		in, out := &in.TargetRef, &out.TargetRef
-		*out = new(VirtualMachinePublishRequestTarget)
-		**out = **in
-	}
-	in.CompletionTime.DeepCopyInto(&out.CompletionTime)
 	in.StartTime.DeepCopyInto(&out.StartTime)
-	in.LastAttemptTime.DeepCopyInto(&out.LastAttemptTime)
+	in.CompletionTime.DeepCopyInto(&out.CompletionTime)
+	if in.VirtualMachines != nil {
+		in, out := &in.VirtualMachines, &out.VirtualMachines
+		*out = make([]VirtualMachineRestoreStatus, len(*in))
+		copy(*out, *in)
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]v1.Condition, len(*in))
@@ -1328,121 +2617,145 @@ func (in *VirtualMachinePublishRequestStatus) DeepCopyInto(out *VirtualMachinePu
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePublishRequestStatus.
-func (in *VirtualMachinePublishRequestStatus) DeepCopy() *VirtualMachinePublishRequestStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineRestoreRequestStatus.
+func (in *VirtualMachineRestoreRequestStatus) DeepCopy() *VirtualMachineRestoreRequestStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachinePublishRequestStatus)
+	out := new(VirtualMachineRestoreRequestStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachinePublishRequestTarget) DeepCopyInto(out *VirtualMachinePublishRequestTarget) {
+func (in *VirtualMachineRestoreStatus) DeepCopyInto(out *VirtualMachineRestoreStatus) {
 	*out = *in
-	out.Item = in.Item
-	out.Location = in.Location
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePublishRequestTarget.
-func (in *VirtualMachinePublishRequestTarget) DeepCopy() *VirtualMachinePublishRequestTarget {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineRestoreStatus.
+func (in *VirtualMachineRestoreStatus) DeepCopy() *VirtualMachineRestoreStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachinePublishRequestTarget)
+	out := new(VirtualMachineRestoreStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachinePublishRequestTargetItem) DeepCopyInto(out *VirtualMachinePublishRequestTargetItem) {
+func (in *VirtualMachineSerialConsoleLogSpec) DeepCopyInto(out *VirtualMachineSerialConsoleLogSpec) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePublishRequestTargetItem.
-func (in *VirtualMachinePublishRequestTargetItem) DeepCopy() *VirtualMachinePublishRequestTargetItem {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineSerialConsoleLogSpec.
+func (in *VirtualMachineSerialConsoleLogSpec) DeepCopy() *VirtualMachineSerialConsoleLogSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachinePublishRequestTargetItem)
+	out := new(VirtualMachineSerialConsoleLogSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachinePublishRequestTargetLocation) DeepCopyInto(out *VirtualMachinePublishRequestTargetLocation) {
+func (in *VirtualMachineSerialConsoleRequest) DeepCopyInto(out *VirtualMachineSerialConsoleRequest) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachinePublishRequestTargetLocation.
-func (in *VirtualMachinePublishRequestTargetLocation) DeepCopy() *VirtualMachinePublishRequestTargetLocation {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineSerialConsoleRequest.
+func (in *VirtualMachineSerialConsoleRequest) DeepCopy() *VirtualMachineSerialConsoleRequest {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachinePublishRequestTargetLocation)
+	out := new(VirtualMachineSerialConsoleRequest)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VirtualMachineSerialConsoleRequest) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachineReadinessProbeSpec) DeepCopyInto(out *VirtualMachineReadinessProbeSpec) {
+func (in *VirtualMachineSerialConsoleRequestList) DeepCopyInto(out *VirtualMachineSerialConsoleRequestList) {
 	*out = *in
-	if in.TCPSocket != nil {
-		in, out := &in.TCPSocket, &out.TCPSocket
-		*out = new(TCPSocketAction)
-		**out = **in
-	}
-	if in.GuestHeartbeat != nil {
-		in, out := &in.GuestHeartbeat, &out.GuestHeartbeat
-		*out = new(GuestHeartbeatAction)
-		**out = **in
-	}
-	if in.GuestInfo != nil {
-		in, out := &in.GuestInfo, &out.GuestInfo
-		*out = make([]GuestInfoAction, len(*in))
-		copy(*out, *in)
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VirtualMachineSerialConsoleRequest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineReadinessProbeSpec.
-func (in *VirtualMachineReadinessProbeSpec) DeepCopy() *VirtualMachineReadinessProbeSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineSerialConsoleRequestList.
+func (in *VirtualMachineSerialConsoleRequestList) DeepCopy() *VirtualMachineSerialConsoleRequestList {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachineReadinessProbeSpec)
+	out := new(VirtualMachineSerialConsoleRequestList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VirtualMachineSerialConsoleRequestList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachineReservedSpec) DeepCopyInto(out *VirtualMachineReservedSpec) {
+func (in *VirtualMachineSerialConsoleRequestSpec) DeepCopyInto(out *VirtualMachineSerialConsoleRequestSpec) {
 	*out = *in
+	if in.Log != nil {
+		in, out := &in.Log, &out.Log
+		*out = new(VirtualMachineSerialConsoleLogSpec)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineReservedSpec.
-func (in *VirtualMachineReservedSpec) DeepCopy() *VirtualMachineReservedSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineSerialConsoleRequestSpec.
+func (in *VirtualMachineSerialConsoleRequestSpec) DeepCopy() *VirtualMachineSerialConsoleRequestSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachineReservedSpec)
+	out := new(VirtualMachineSerialConsoleRequestSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachineResourceSpec) DeepCopyInto(out *VirtualMachineResourceSpec) {
+func (in *VirtualMachineSerialConsoleRequestStatus) DeepCopyInto(out *VirtualMachineSerialConsoleRequestStatus) {
 	*out = *in
-	out.Cpu = in.Cpu.DeepCopy()
-	out.Memory = in.Memory.DeepCopy()
+	in.ExpiryTime.DeepCopyInto(&out.ExpiryTime)
+	in.LogUpdateTime.DeepCopyInto(&out.LogUpdateTime)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineResourceSpec.
-func (in *VirtualMachineResourceSpec) DeepCopy() *VirtualMachineResourceSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineSerialConsoleRequestStatus.
+func (in *VirtualMachineSerialConsoleRequestStatus) DeepCopy() *VirtualMachineSerialConsoleRequestStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachineResourceSpec)
+	out := new(VirtualMachineSerialConsoleRequestStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -1509,6 +2822,7 @@ func (in *VirtualMachineServiceList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VirtualMachineServicePort) DeepCopyInto(out *VirtualMachineServicePort) {
 	*out = *in
+	out.TargetPort = in.TargetPort
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineServicePort.
@@ -1541,6 +2855,16 @@ func (in *VirtualMachineServiceSpec) DeepCopyInto(out *VirtualMachineServiceSpec
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.IPFamilies != nil {
+		in, out := &in.IPFamilies, &out.IPFamilies
+		*out = make([]IPFamily, len(*in))
+		copy(*out, *in)
+	}
+	if in.IPFamilyPolicy != nil {
+		in, out := &in.IPFamilyPolicy, &out.IPFamilyPolicy
+		*out = new(IPFamilyPolicy)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineServiceSpec.
@@ -1669,6 +2993,116 @@ func (in *VirtualMachineSetResourcePolicyStatus) DeepCopy() *VirtualMachineSetRe
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineSnapshot) DeepCopyInto(out *VirtualMachineSnapshot) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineSnapshot.
+func (in *VirtualMachineSnapshot) DeepCopy() *VirtualMachineSnapshot {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineSnapshot)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VirtualMachineSnapshot) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineSnapshotList) DeepCopyInto(out *VirtualMachineSnapshotList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VirtualMachineSnapshot, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineSnapshotList.
+func (in *VirtualMachineSnapshotList) DeepCopy() *VirtualMachineSnapshotList {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineSnapshotList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VirtualMachineSnapshotList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineSnapshotSpec) DeepCopyInto(out *VirtualMachineSnapshotSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineSnapshotSpec.
+func (in *VirtualMachineSnapshotSpec) DeepCopy() *VirtualMachineSnapshotSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineSnapshotSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineSnapshotStatus) DeepCopyInto(out *VirtualMachineSnapshotStatus) {
+	*out = *in
+	if in.CreationTime != nil {
+		in, out := &in.CreationTime, &out.CreationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Size != nil {
+		in, out := &in.Size, &out.Size
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Children != nil {
+		in, out := &in.Children, &out.Children
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineSnapshotStatus.
+func (in *VirtualMachineSnapshotStatus) DeepCopy() *VirtualMachineSnapshotStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineSnapshotStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VirtualMachineSpec) DeepCopyInto(out *VirtualMachineSpec) {
 	*out = *in
@@ -1689,11 +3123,21 @@ func (in *VirtualMachineSpec) DeepCopyInto(out *VirtualMachineSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Ports != nil {
+		in, out := &in.Ports, &out.Ports
+		*out = make([]VirtualMachinePort, len(*in))
+		copy(*out, *in)
+	}
 	if in.ReadinessProbe != nil {
 		in, out := &in.ReadinessProbe, &out.ReadinessProbe
 		*out = new(VirtualMachineReadinessProbeSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.LivenessProbe != nil {
+		in, out := &in.LivenessProbe, &out.LivenessProbe
+		*out = new(VirtualMachineLivenessProbeSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Advanced != nil {
 		in, out := &in.Advanced, &out.Advanced
 		*out = new(VirtualMachineAdvancedSpec)
@@ -1744,7 +3188,9 @@ func (in *VirtualMachineStatus) DeepCopyInto(out *VirtualMachineStatus) {
 	if in.Volumes != nil {
 		in, out := &in.Volumes, &out.Volumes
 		*out = make([]VirtualMachineVolumeStatus, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.ChangeBlockTracking != nil {
 		in, out := &in.ChangeBlockTracking, &out.ChangeBlockTracking
@@ -1755,6 +3201,26 @@ func (in *VirtualMachineStatus) DeepCopyInto(out *VirtualMachineStatus) {
 		in, out := &in.LastRestartTime, &out.LastRestartTime
 		*out = (*in).DeepCopy()
 	}
+	if in.CurrentSnapshot != nil {
+		in, out := &in.CurrentSnapshot, &out.CurrentSnapshot
+		*out = new(common.LocalObjectRef)
+		**out = **in
+	}
+	if in.Liveness != nil {
+		in, out := &in.Liveness, &out.Liveness
+		*out = new(VirtualMachineLivenessStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Guest != nil {
+		in, out := &in.Guest, &out.Guest
+		*out = new(VirtualMachineGuestStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GuestCustomization != nil {
+		in, out := &in.GuestCustomization, &out.GuestCustomization
+		*out = new(VirtualMachineGuestCustomizationStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineStatus.
@@ -1788,6 +3254,23 @@ func (in *VirtualMachineTemplate) DeepCopy() *VirtualMachineTemplate {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineTemplateSpec) DeepCopyInto(out *VirtualMachineTemplateSpec) {
+	*out = *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineTemplateSpec.
+func (in *VirtualMachineTemplateSpec) DeepCopy() *VirtualMachineTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VirtualMachineVolume) DeepCopyInto(out *VirtualMachineVolume) {
 	*out = *in
@@ -1812,6 +3295,16 @@ func (in *VirtualMachineVolumeSource) DeepCopyInto(out *VirtualMachineVolumeSour
 		*out = new(PersistentVolumeClaimVolumeSource)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Ephemeral != nil {
+		in, out := &in.Ephemeral, &out.Ephemeral
+		*out = new(EphemeralVolumeSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(ImageVolumeSource)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineVolumeSource.
@@ -1827,6 +3320,16 @@ func (in *VirtualMachineVolumeSource) DeepCopy() *VirtualMachineVolumeSource {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VirtualMachineVolumeStatus) DeepCopyInto(out *VirtualMachineVolumeStatus) {
 	*out = *in
+	if in.RequestedCapacity != nil {
+		in, out := &in.RequestedCapacity, &out.RequestedCapacity
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.AttachedCapacity != nil {
+		in, out := &in.AttachedCapacity, &out.AttachedCapacity
+		x := (*in).DeepCopy()
+		*out = &x
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineVolumeStatus.
@@ -0,0 +1,71 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"flag"
+	"net/http"
+	"os"
+	"strconv"
+
+	klog "k8s.io/klog/v2"
+	"k8s.io/klog/v2/klogr"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/vmware-tanzu/vm-operator/pkg"
+	"github.com/vmware-tanzu/vm-operator/pkg/exporttransfer"
+)
+
+func main() {
+	// Using the same type of logger as in the controller-manager.
+	klog.InitFlags(nil)
+	ctrllog.SetLogger(klogr.New())
+	logger := ctrllog.Log.WithName("entrypoint")
+
+	logger.Info("VM Operator export transfer server info", "version", pkg.BuildVersion,
+		"buildnumber", pkg.BuildNumber, "buildtype", pkg.BuildType, "commit", pkg.BuildCommit)
+
+	serverPort := flag.Int(
+		"server-port",
+		exporttransfer.DefaultPort,
+		"The port on which the export transfer server listens for incoming requests.",
+	)
+	dir := flag.String(
+		"dir",
+		"",
+		"The directory the exported files are written to.",
+	)
+	certFile := flag.String(
+		"cert-file",
+		"",
+		"The file of the certificate the export transfer server is served with.",
+	)
+	keyFile := flag.String(
+		"key-file",
+		"",
+		"The file of the private key of the certificate.",
+	)
+
+	flag.Parse()
+
+	token := os.Getenv(exporttransfer.TokenEnv)
+	if *dir == "" || *certFile == "" || *keyFile == "" || token == "" {
+		logger.Info("The directory, the certificate, the key and the token are required")
+		os.Exit(1)
+	}
+
+	server := &exporttransfer.Server{
+		Dir:    *dir,
+		Token:  token,
+		Logger: ctrllog.Log.WithName("export-transfer"),
+	}
+
+	logger.Info("Starting the export transfer server", "port", *serverPort, "dir", *dir)
+
+	runErr := server.Run(":"+strconv.Itoa(*serverPort), *certFile, *keyFile)
+	if runErr != nil && runErr != http.ErrServerClosed {
+		logger.Error(runErr, "Error occurred while running the export transfer server!")
+		os.Exit(1)
+	}
+}
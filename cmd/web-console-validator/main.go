@@ -18,8 +18,9 @@ import (
 )
 
 var (
-	defaultServerPort = 9868
-	defaultServerPath = "/validate"
+	defaultServerPort     = 9868
+	defaultServerPath     = "/validate"
+	defaultTrustedProxies = ""
 )
 
 func init() {
@@ -29,6 +30,9 @@ func init() {
 	if v, err := strconv.Atoi(os.Getenv("SERVER_PORT")); err == nil {
 		defaultServerPort = v
 	}
+	if v := os.Getenv("TRUSTED_PROXIES"); v != "" {
+		defaultTrustedProxies = v
+	}
 }
 
 func main() {
@@ -50,19 +54,42 @@ func main() {
 		defaultServerPath,
 		"The pattern path to handle the web-console validation requests.",
 	)
+	rateLimitQPS := flag.Float64(
+		"rate-limit-qps",
+		webconsolevalidation.DefaultRateLimitQPS,
+		"The number of web-console validation requests per second that look up a web-console request.",
+	)
+	rateLimitBurst := flag.Int(
+		"rate-limit-burst",
+		webconsolevalidation.DefaultRateLimitBurst,
+		"The number of web-console validation requests that may look up a web-console request at once above the rate limit.",
+	)
+	trustedProxiesFlag := flag.String(
+		"trusted-proxies",
+		defaultTrustedProxies,
+		"The comma-separated IP addresses and CIDRs of the proxies whose X-Forwarded-For header identifies the client.",
+	)
 
 	flag.Parse()
 
-	if initErr := webconsolevalidation.InitServer(); initErr != nil {
+	trustedProxies, parseErr := webconsolevalidation.ParseTrustedProxies(*trustedProxiesFlag)
+	if parseErr != nil {
+		logger.Error(parseErr, "Failed to parse the trusted proxies")
+		os.Exit(1)
+	}
+
+	server, initErr := webconsolevalidation.NewServer(*rateLimitQPS, *rateLimitBurst, trustedProxies)
+	if initErr != nil {
 		logger.Error(initErr, "Failed to initialize web-console validation server")
 		os.Exit(1)
 	}
 
-	logger.Info("Starting the web-console validation server", "port", *serverPort, "path", *serverPath)
+	logger.Info("Starting the web-console validation server", "port", *serverPort, "path", *serverPath,
+		"rateLimitQPS", *rateLimitQPS, "rateLimitBurst", *rateLimitBurst, "trustedProxies", *trustedProxiesFlag)
 
 	// Pass serverPath to the RunServer so one can check what path the server is listening on
 	// by looking at the commands specified in the server deployment spec.
-	runErr := webconsolevalidation.RunServer(":"+strconv.Itoa(*serverPort), *serverPath)
+	runErr := server.Run(":"+strconv.Itoa(*serverPort), *serverPath)
 	if runErr != nil && runErr != http.ErrServerClosed {
 		logger.Error(runErr, "Error occurred while running the web-console validation server!")
 	}
@@ -14,9 +14,17 @@ import (
 	"github.com/vmware-tanzu/vm-operator/controllers/providerconfigmap"
 	"github.com/vmware-tanzu/vm-operator/controllers/virtualmachine"
 	"github.com/vmware-tanzu/vm-operator/controllers/virtualmachineclass"
+	"github.com/vmware-tanzu/vm-operator/controllers/virtualmachineexportrequest"
+	"github.com/vmware-tanzu/vm-operator/controllers/virtualmachineguestcommandrequest"
+	"github.com/vmware-tanzu/vm-operator/controllers/virtualmachineimageimportrequest"
+	"github.com/vmware-tanzu/vm-operator/controllers/virtualmachinepowerschedule"
 	"github.com/vmware-tanzu/vm-operator/controllers/virtualmachinepublishrequest"
+	"github.com/vmware-tanzu/vm-operator/controllers/virtualmachinereplicaset"
+	"github.com/vmware-tanzu/vm-operator/controllers/virtualmachinerestorerequest"
+	"github.com/vmware-tanzu/vm-operator/controllers/virtualmachineserialconsolerequest"
 	"github.com/vmware-tanzu/vm-operator/controllers/virtualmachineservice"
 	"github.com/vmware-tanzu/vm-operator/controllers/virtualmachinesetresourcepolicy"
+	"github.com/vmware-tanzu/vm-operator/controllers/virtualmachinesnapshot"
 	"github.com/vmware-tanzu/vm-operator/controllers/virtualmachinewebconsolerequest"
 	"github.com/vmware-tanzu/vm-operator/controllers/volume"
 	"github.com/vmware-tanzu/vm-operator/pkg/context"
@@ -40,12 +48,36 @@ func AddToManager(ctx *context.ControllerManagerContext, mgr manager.Manager) er
 	if err := virtualmachineclass.AddToManager(ctx, mgr); err != nil {
 		return errors.Wrap(err, "failed to initialize VirtualMachineClass controller")
 	}
+	if err := virtualmachineexportrequest.AddToManager(ctx, mgr); err != nil {
+		return errors.Wrap(err, "failed to initialize VirtualMachineExportRequest controller")
+	}
+	if err := virtualmachineguestcommandrequest.AddToManager(ctx, mgr); err != nil {
+		return errors.Wrap(err, "failed to initialize VirtualMachineGuestCommandRequest controller")
+	}
+	if err := virtualmachineimageimportrequest.AddToManager(ctx, mgr); err != nil {
+		return errors.Wrap(err, "failed to initialize VirtualMachineImageImportRequest controller")
+	}
+	if err := virtualmachinepowerschedule.AddToManager(ctx, mgr); err != nil {
+		return errors.Wrap(err, "failed to initialize VirtualMachinePowerSchedule controller")
+	}
+	if err := virtualmachinereplicaset.AddToManager(ctx, mgr); err != nil {
+		return errors.Wrap(err, "failed to initialize VirtualMachineReplicaSet controller")
+	}
+	if err := virtualmachinerestorerequest.AddToManager(ctx, mgr); err != nil {
+		return errors.Wrap(err, "failed to initialize VirtualMachineRestoreRequest controller")
+	}
+	if err := virtualmachineserialconsolerequest.AddToManager(ctx, mgr); err != nil {
+		return errors.Wrap(err, "failed to initialize VirtualMachineSerialConsoleRequest controller")
+	}
 	if err := virtualmachineservice.AddToManager(ctx, mgr); err != nil {
 		return errors.Wrap(err, "failed to initialize VirtualMachineService controller")
 	}
 	if err := virtualmachinesetresourcepolicy.AddToManager(ctx, mgr); err != nil {
 		return errors.Wrap(err, "failed to initialize VirtualMachineSetResourcePolicy controller")
 	}
+	if err := virtualmachinesnapshot.AddToManager(ctx, mgr); err != nil {
+		return errors.Wrap(err, "failed to initialize VirtualMachineSnapshot controller")
+	}
 	if err := virtualmachinewebconsolerequest.AddToManager(ctx, mgr); err != nil {
 		return errors.Wrap(err, "failed to initialize VirtualMachineWebConsoleRequest controller")
 	}
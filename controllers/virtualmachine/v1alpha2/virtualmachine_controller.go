@@ -10,7 +10,9 @@ import (
 	"strings"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	ctrl "sigs.k8s.io/controller-runtime"
 	ctrlbuilder "sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -24,6 +26,7 @@ import (
 	"github.com/pkg/errors"
 
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	"github.com/vmware-tanzu/vm-operator/api/v1alpha2/common"
 
 	conditions "github.com/vmware-tanzu/vm-operator/pkg/conditions2"
 	"github.com/vmware-tanzu/vm-operator/pkg/context"
@@ -102,6 +105,11 @@ func AddToManager(ctx *context.ControllerManagerContext, mgr manager.Manager) er
 	builder = builder.Watches(&vmopv1.VirtualMachineClass{},
 		handler.EnqueueRequestsFromMapFunc(classToVMMapperFn(ctx, r.Client)))
 
+	// Only the metadata of Secrets is watched so the data of all the Secrets
+	// is not cached.
+	builder = builder.WatchesMetadata(&corev1.Secret{},
+		handler.EnqueueRequestsFromMapFunc(secretToVMMapperFn(ctx, r.Client)))
+
 	return builder.Complete(r)
 }
 
@@ -159,6 +167,80 @@ func classToVMMapperFn(ctx *context.ControllerManagerContext, c client.Client) f
 	}
 }
 
+// secretToVMMapperFn returns a mapper function that can be used to queue reconcile requests
+// for the VirtualMachines in response to an event on a Secret that contains bootstrap data
+// of VMs with spec.bootstrap.customization.retryOnSecretChange set, so that failed guest
+// customization is retried once the Secret has been fixed.
+func secretToVMMapperFn(ctx *context.ControllerManagerContext, c client.Client) func(_ goctx.Context, o client.Object) []reconcile.Request {
+	return func(_ goctx.Context, o client.Object) []reconcile.Request {
+		logger := ctx.Logger.WithValues("name", o.GetName(), "namespace", o.GetNamespace())
+
+		vmList := &vmopv1.VirtualMachineList{}
+		if err := c.List(ctx, vmList, client.InNamespace(o.GetNamespace())); err != nil {
+			logger.Error(err, "Failed to list VirtualMachines for reconciliation due to Secret watch")
+			return nil
+		}
+
+		// Populate reconcile requests for VMs that retry customization with this Secret.
+		var reconcileRequests []reconcile.Request
+		for _, vm := range vmList.Items {
+			bootstrap := vm.Spec.Bootstrap
+			if bootstrap == nil || bootstrap.Customization == nil || !bootstrap.Customization.RetryOnSecretChange {
+				continue
+			}
+
+			for _, name := range bootstrapSecretNames(bootstrap) {
+				if name == o.GetName() {
+					key := client.ObjectKey{Namespace: vm.Namespace, Name: vm.Name}
+					reconcileRequests = append(reconcileRequests, reconcile.Request{NamespacedName: key})
+					break
+				}
+			}
+		}
+
+		if len(reconcileRequests) > 0 {
+			logger.Info("Returning VM reconcile requests due to Secret watch", "requests", reconcileRequests)
+		}
+		return reconcileRequests
+	}
+}
+
+// bootstrapSecretNames returns the names of the Secrets that contain the
+// bootstrap data of the Sysprep and vApp bootstrap providers.
+func bootstrapSecretNames(bootstrap *vmopv1.VirtualMachineBootstrapSpec) []string {
+	var names []string
+
+	if v := bootstrap.Sysprep; v != nil {
+		if raw := v.RawSysprep; raw != nil {
+			names = append(names, raw.Name)
+		}
+		if cooked := v.Sysprep; cooked != nil {
+			if g := cooked.GUIUnattended; g != nil && g.Password != nil {
+				names = append(names, g.Password.Name)
+			}
+			if i := cooked.Identification; i != nil && i.DomainAdminPassword != nil {
+				names = append(names, i.DomainAdminPassword.Name)
+			}
+			if u := cooked.UserData; u != nil && u.ProductID != nil {
+				names = append(names, u.ProductID.Name)
+			}
+		}
+	}
+
+	if v := bootstrap.VAppConfig; v != nil {
+		if v.RawProperties != "" {
+			names = append(names, v.RawProperties)
+		}
+		for _, p := range v.Properties {
+			if p.Value.From != nil {
+				names = append(names, p.Value.From.Name)
+			}
+		}
+	}
+
+	return names
+}
+
 func NewReconciler(
 	client client.Client,
 	logger logr.Logger,
@@ -192,8 +274,10 @@ type Reconciler struct {
 // +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachines,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachines/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachineclasses,verbs=get;list
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachinesnapshots,verbs=get;list;watch
 // +kubebuilder:rbac:groups=vmware.com,resources=virtualnetworkinterfaces;virtualnetworkinterfaces/status,verbs=create;get;list;patch;delete;watch;update
 // +kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=events;configmaps,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=resourcequotas;namespaces,verbs=get;list;watch
 
@@ -259,6 +343,33 @@ func requeueDelay(ctx *context.VirtualMachineContextA2) time.Duration {
 		return 10 * time.Second
 	}
 
+	// Poll the progress of the VM's zone migration or storage relocation.
+	if conditions.GetReason(ctx.VM, vmopv1.VirtualMachineConditionZoneMigrated) ==
+		vmopv1.VirtualMachineZoneMigrationInProgressReason {
+		return 10 * time.Second
+	}
+	if conditions.GetReason(ctx.VM, vmopv1.VirtualMachineConditionStorageRelocated) ==
+		vmopv1.VirtualMachineStorageRelocationInProgressReason {
+		return 10 * time.Second
+	}
+
+	// Poll the progress of the copy of the disk of an image volume.
+	if conditions.GetReason(ctx.VM, vmopv1.VirtualMachineConditionImageVolumesCopied) ==
+		vmopv1.VirtualMachineImageVolumeCopyInProgressReason {
+		return 10 * time.Second
+	}
+
+	// Retry the VM's zone migration or storage relocation once it has backed
+	// off after it failed.
+	if conditions.GetReason(ctx.VM, vmopv1.VirtualMachineConditionZoneMigrated) ==
+		vmopv1.VirtualMachineZoneMigrationFailedReason {
+		return time.Minute
+	}
+	if conditions.GetReason(ctx.VM, vmopv1.VirtualMachineConditionStorageRelocated) ==
+		vmopv1.VirtualMachineStorageRelocationFailedReason {
+		return time.Minute
+	}
+
 	if ctx.VM.Status.PowerState == vmopv1.VirtualMachinePowerStateOn {
 		network := ctx.VM.Status.Network
 		if network == nil || (network.PrimaryIP4 == "" && network.PrimaryIP6 == "") {
@@ -327,6 +438,10 @@ func (r *Reconciler) ReconcileNormal(ctx *context.VirtualMachineContextA2) (rete
 		r.vmMetrics.RegisterVMCreateOrUpdateMetrics(ctx)
 	}()
 
+	if err := r.reconcileCurrentSnapshot(ctx); err != nil {
+		return err
+	}
+
 	if err := r.VMProvider.CreateOrUpdateVirtualMachine(ctx, ctx.VM); err != nil {
 		r.Recorder.EmitEvent(ctx.VM, "CreateOrUpdate", err, false)
 		return err
@@ -338,3 +453,64 @@ func (r *Reconciler) ReconcileNormal(ctx *context.VirtualMachineContextA2) (rete
 	ctx.Logger.Info("Finished Reconciling VirtualMachine")
 	return nil
 }
+
+// reconcileCurrentSnapshot reverts the VM to the snapshot specified by
+// spec.currentSnapshotName when it differs from the snapshot the VM was last
+// reverted to. The revert happens before the VM is updated so that the VM is
+// brought back to its desired power state afterwards.
+func (r *Reconciler) reconcileCurrentSnapshot(ctx *context.VirtualMachineContextA2) error {
+	snapshotName := ctx.VM.Spec.CurrentSnapshotName
+	if snapshotName == "" {
+		ctx.VM.Status.CurrentSnapshot = nil
+		conditions.Delete(ctx.VM, vmopv1.VirtualMachineConditionSnapshotReverted)
+		return nil
+	}
+
+	if ctx.VM.Status.UniqueID == "" {
+		// A VM that has not been created yet does not have any snapshots.
+		return nil
+	}
+
+	if cur := ctx.VM.Status.CurrentSnapshot; cur != nil && cur.Name == snapshotName {
+		return nil
+	}
+
+	vmSnapshot := &vmopv1.VirtualMachineSnapshot{}
+	key := client.ObjectKey{Namespace: ctx.VM.Namespace, Name: snapshotName}
+	if err := r.Get(ctx, key, vmSnapshot); err != nil {
+		if apierrors.IsNotFound(err) {
+			conditions.MarkFalse(ctx.VM, vmopv1.VirtualMachineConditionSnapshotReverted,
+				vmopv1.VirtualMachineSnapshotNotFoundReason, "VirtualMachineSnapshot %q does not exist", snapshotName)
+		}
+		return errors.Wrapf(err, "failed to get VirtualMachineSnapshot %q", snapshotName)
+	}
+
+	if vmSnapshot.Spec.VMName != ctx.VM.Name {
+		conditions.MarkFalse(ctx.VM, vmopv1.VirtualMachineConditionSnapshotReverted,
+			vmopv1.VirtualMachineSnapshotNotReadyReason, "VirtualMachineSnapshot %q is not of this VM", snapshotName)
+		return errors.Errorf("VirtualMachineSnapshot %q is of VM %q", snapshotName, vmSnapshot.Spec.VMName)
+	}
+
+	if !vmSnapshot.Status.Ready {
+		conditions.MarkFalse(ctx.VM, vmopv1.VirtualMachineConditionSnapshotReverted,
+			vmopv1.VirtualMachineSnapshotNotReadyReason, "VirtualMachineSnapshot %q is not ready", snapshotName)
+		return errors.Errorf("VirtualMachineSnapshot %q is not ready", snapshotName)
+	}
+
+	err := r.VMProvider.RevertVirtualMachineSnapshot(ctx, ctx.VM, vmSnapshot)
+	r.Recorder.EmitEvent(ctx.VM, "Revert", err, false)
+	if err != nil {
+		conditions.MarkFalse(ctx.VM, vmopv1.VirtualMachineConditionSnapshotReverted,
+			vmopv1.VirtualMachineSnapshotRevertFailedReason, err.Error())
+		return err
+	}
+
+	ctx.VM.Status.CurrentSnapshot = &common.LocalObjectRef{
+		APIVersion: vmopv1.SchemeGroupVersion.String(),
+		Kind:       "VirtualMachineSnapshot",
+		Name:       snapshotName,
+	}
+	conditions.MarkTrue(ctx.VM, vmopv1.VirtualMachineConditionSnapshotReverted)
+
+	return nil
+}
@@ -17,6 +17,7 @@ import (
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
 
 	virtualmachine "github.com/vmware-tanzu/vm-operator/controllers/virtualmachine/v1alpha2"
+	conditions "github.com/vmware-tanzu/vm-operator/pkg/conditions2"
 	vmopContext "github.com/vmware-tanzu/vm-operator/pkg/context"
 	proberfake "github.com/vmware-tanzu/vm-operator/pkg/prober2/fake"
 	providerfake "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/fake"
@@ -153,6 +154,95 @@ func unitTestsReconcile() {
 			Expect(reconciler.ReconcileNormal(vmCtx)).Should(Succeed())
 			Expect(fakeProbeManager.IsAddToProberManagerCalled).Should(BeTrue())
 		})
+
+		When("spec.currentSnapshotName is set", func() {
+			var (
+				vmSnapshot    *vmopv1.VirtualMachineSnapshot
+				revertedNames []string
+			)
+
+			BeforeEach(func() {
+				revertedNames = nil
+				vm.Status.UniqueID = "vm-42"
+				vm.Spec.CurrentSnapshotName = "dummy-snapshot"
+
+				vmSnapshot = &vmopv1.VirtualMachineSnapshot{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      vm.Spec.CurrentSnapshotName,
+						Namespace: vm.Namespace,
+					},
+					Spec: vmopv1.VirtualMachineSnapshotSpec{
+						VMName: vm.Name,
+					},
+					Status: vmopv1.VirtualMachineSnapshotStatus{
+						Ready: true,
+					},
+				}
+			})
+
+			JustBeforeEach(func() {
+				fakeVMProvider.RevertVirtualMachineSnapshotFn = func(
+					_ context.Context, _ *vmopv1.VirtualMachine, vmSnapshot *vmopv1.VirtualMachineSnapshot) error {
+					revertedNames = append(revertedNames, vmSnapshot.Name)
+					return nil
+				}
+			})
+
+			When("the snapshot does not exist", func() {
+				It("returns an error", func() {
+					err := reconciler.ReconcileNormal(vmCtx)
+					Expect(err).To(HaveOccurred())
+					Expect(revertedNames).To(BeEmpty())
+
+					c := conditions.Get(vmCtx.VM, vmopv1.VirtualMachineConditionSnapshotReverted)
+					Expect(c).ToNot(BeNil())
+					Expect(c.Reason).To(Equal(vmopv1.VirtualMachineSnapshotNotFoundReason))
+				})
+			})
+
+			When("the snapshot is not ready", func() {
+				BeforeEach(func() {
+					vmSnapshot.Status.Ready = false
+					initObjects = append(initObjects, vmSnapshot)
+				})
+
+				It("returns an error", func() {
+					err := reconciler.ReconcileNormal(vmCtx)
+					Expect(err).To(HaveOccurred())
+					Expect(revertedNames).To(BeEmpty())
+
+					c := conditions.Get(vmCtx.VM, vmopv1.VirtualMachineConditionSnapshotReverted)
+					Expect(c).ToNot(BeNil())
+					Expect(c.Reason).To(Equal(vmopv1.VirtualMachineSnapshotNotReadyReason))
+				})
+			})
+
+			When("the snapshot is ready", func() {
+				BeforeEach(func() {
+					initObjects = append(initObjects, vmSnapshot)
+				})
+
+				It("reverts the VM to the snapshot once", func() {
+					Expect(reconciler.ReconcileNormal(vmCtx)).To(Succeed())
+					Expect(revertedNames).To(Equal([]string{vmSnapshot.Name}))
+					Expect(vmCtx.VM.Status.CurrentSnapshot).ToNot(BeNil())
+					Expect(vmCtx.VM.Status.CurrentSnapshot.Name).To(Equal(vmSnapshot.Name))
+					Expect(conditions.IsTrue(vmCtx.VM, vmopv1.VirtualMachineConditionSnapshotReverted)).To(BeTrue())
+					expectEvent(ctx, "RevertSuccess")
+
+					Expect(reconciler.ReconcileNormal(vmCtx)).To(Succeed())
+					Expect(revertedNames).To(HaveLen(1))
+				})
+
+				It("clears the status when spec.currentSnapshotName is cleared", func() {
+					Expect(reconciler.ReconcileNormal(vmCtx)).To(Succeed())
+					vmCtx.VM.Spec.CurrentSnapshotName = ""
+					Expect(reconciler.ReconcileNormal(vmCtx)).To(Succeed())
+					Expect(vmCtx.VM.Status.CurrentSnapshot).To(BeNil())
+					Expect(conditions.Get(vmCtx.VM, vmopv1.VirtualMachineConditionSnapshotReverted)).To(BeNil())
+				})
+			})
+		})
 	})
 
 	Context("ReconcileDelete", func() {
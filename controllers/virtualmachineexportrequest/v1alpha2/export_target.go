@@ -0,0 +1,633 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha2
+
+import (
+	"bytes"
+	goctx "context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/exporttransfer"
+)
+
+const (
+	// DefaultObjectStorePartSize is the size of the parts of a multipart
+	// upload to an object store. Files that are not larger than a part are
+	// uploaded with a single request.
+	DefaultObjectStorePartSize = 64 * 1024 * 1024
+
+	// TransferPodVolumeMountPath is the path the PersistentVolumeClaim is
+	// mounted at in the transfer Pod.
+	TransferPodVolumeMountPath = "/export"
+
+	transferPodContainerName   = "transfer"
+	transferPodSecretMountPath = "/etc/export-transfer"
+
+	objectStoreDialTimeout    = 30 * time.Second
+	objectStoreRequestTimeout = 30 * time.Minute
+
+	awsSignAlgorithm   = "AWS4-HMAC-SHA256"
+	awsUnsignedPayload = "UNSIGNED-PAYLOAD"
+	awsTimeFormat      = "20060102T150405Z"
+	awsDateFormat      = "20060102"
+)
+
+// ObjectStoreTarget writes the files of an exported VM to an S3-compatible
+// object store using path-style requests signed with AWS Signature Version 4.
+type ObjectStoreTarget struct {
+	Endpoint        string
+	Bucket          string
+	Prefix          string
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+
+	// PartSize is the size of the parts of a multipart upload. Defaults to
+	// DefaultObjectStorePartSize.
+	PartSize int64
+
+	// HTTPClient is the client used to send the requests. Defaults to
+	// DefaultObjectStoreHTTPClient.
+	HTTPClient *http.Client
+}
+
+// DefaultObjectStoreHTTPClient is the client used to send the requests to an
+// object store. A request, which uploads at most one part, times out, and
+// redirects are not followed so the requests are only sent to the endpoint.
+var DefaultObjectStoreHTTPClient = &http.Client{
+	Timeout: objectStoreRequestTimeout,
+	Transport: &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           (&net.Dialer{Timeout: objectStoreDialTimeout}).DialContext,
+		TLSHandshakeTimeout:   objectStoreDialTimeout,
+		ResponseHeaderTimeout: objectStoreRequestTimeout,
+		MaxIdleConnsPerHost:   4,
+		IdleConnTimeout:       90 * time.Second,
+		TLSClientConfig: &tls.Config{
+			MinVersion: tls.VersionTLS12,
+		},
+	},
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// WriteFile uploads the file to the object store. Files larger than the part
+// size, or whose size is not known, are uploaded with a multipart upload.
+func (t *ObjectStoreTarget) WriteFile(ctx goctx.Context, name string, size int64, r io.Reader) error {
+	key := path.Join(t.Prefix, name)
+
+	partSize := t.PartSize
+	if partSize <= 0 {
+		partSize = DefaultObjectStorePartSize
+	}
+	if size >= 0 && size <= partSize {
+		_, err := t.do(ctx, http.MethodPut, key, nil, r, size)
+		return err
+	}
+
+	return t.writeMultipart(ctx, key, partSize, r)
+}
+
+type completeMultipartUpload struct {
+	XMLName xml.Name                `xml:"CompleteMultipartUpload"`
+	Parts   []completeMultipartPart `xml:"Part"`
+}
+
+type completeMultipartPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+type initiateMultipartUploadResult struct {
+	UploadID string `xml:"UploadId"`
+}
+
+func (t *ObjectStoreTarget) writeMultipart(ctx goctx.Context, key string, partSize int64, r io.Reader) (reterr error) {
+	resp, err := t.do(ctx, http.MethodPost, key, url.Values{"uploads": {""}}, nil, 0)
+	if err != nil {
+		return err
+	}
+	var initResult initiateMultipartUploadResult
+	if err := xml.Unmarshal(resp, &initResult); err != nil {
+		return errors.Wrap(err, "failed to decode initiate multipart upload response")
+	}
+	uploadID := initResult.UploadID
+
+	defer func() {
+		if reterr != nil {
+			_, _ = t.do(goctx.Background(), http.MethodDelete, key, url.Values{"uploadId": {uploadID}}, nil, 0)
+		}
+	}()
+
+	var (
+		complete completeMultipartUpload
+		buf      = make([]byte, partSize)
+	)
+	for partNumber := 1; ; partNumber++ {
+		n, err := io.ReadFull(r, buf)
+		if n == 0 && (err == io.EOF || err == io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return err
+		}
+
+		query := url.Values{
+			"partNumber": {fmt.Sprint(partNumber)},
+			"uploadId":   {uploadID},
+		}
+		etag, err := t.doPart(ctx, key, query, buf[:n])
+		if err != nil {
+			return err
+		}
+		complete.Parts = append(complete.Parts, completeMultipartPart{PartNumber: partNumber, ETag: etag})
+
+		if int64(n) < partSize {
+			break
+		}
+	}
+
+	body, err := xml.Marshal(complete)
+	if err != nil {
+		return err
+	}
+	_, err = t.do(ctx, http.MethodPost, key, url.Values{"uploadId": {uploadID}}, bytes.NewReader(body), int64(len(body)))
+	return err
+}
+
+func (t *ObjectStoreTarget) doPart(ctx goctx.Context, key string, query url.Values, part []byte) (string, error) {
+	req, err := t.newRequest(ctx, http.MethodPut, key, query, bytes.NewReader(part), int64(len(part)))
+	if err != nil {
+		return "", err
+	}
+	resp, err := t.send(req)
+	if err != nil {
+		return "", err
+	}
+	_ = resp.Body.Close()
+	return resp.Header.Get("ETag"), nil
+}
+
+func (t *ObjectStoreTarget) do(
+	ctx goctx.Context,
+	method, key string,
+	query url.Values,
+	body io.Reader,
+	size int64) ([]byte, error) {
+
+	req, err := t.newRequest(ctx, method, key, query, body, size)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.send(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+func (t *ObjectStoreTarget) send(req *http.Request) (*http.Response, error) {
+	httpClient := t.HTTPClient
+	if httpClient == nil {
+		httpClient = DefaultObjectStoreHTTPClient
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("%s %s failed with status %d: %s", req.Method, req.URL.Path, resp.StatusCode, string(msg))
+	}
+	return resp, nil
+}
+
+func (t *ObjectStoreTarget) newRequest(
+	ctx goctx.Context,
+	method, key string,
+	query url.Values,
+	body io.Reader,
+	size int64) (*http.Request, error) {
+
+	endpoint, err := url.Parse(t.Endpoint)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid object store endpoint")
+	}
+
+	canonicalURI := awsURIEncode(path.Join("/", endpoint.Path, t.Bucket, key), false)
+	canonicalQuery := awsCanonicalQuery(query)
+
+	u := endpoint.Scheme + "://" + endpoint.Host + canonicalURI
+	if canonicalQuery != "" {
+		u += "?" + canonicalQuery
+	}
+
+	if body == nil {
+		body = http.NoBody
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = size
+
+	now := time.Now().UTC()
+	amzDate := now.Format(awsTimeFormat)
+	req.Header.Set("x-amz-content-sha256", awsUnsignedPayload)
+	req.Header.Set("x-amz-date", amzDate)
+
+	const signedHeaders = "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		method,
+		canonicalURI,
+		canonicalQuery,
+		"host:" + req.URL.Host,
+		"x-amz-content-sha256:" + awsUnsignedPayload,
+		"x-amz-date:" + amzDate,
+		"",
+		signedHeaders,
+		awsUnsignedPayload,
+	}, "\n")
+
+	scope := strings.Join([]string{now.Format(awsDateFormat), t.Region, "s3", "aws4_request"}, "/")
+	canonicalRequestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		awsSignAlgorithm,
+		amzDate,
+		scope,
+		hex.EncodeToString(canonicalRequestHash[:]),
+	}, "\n")
+
+	key4 := hmacSHA256([]byte("AWS4"+t.SecretAccessKey), now.Format(awsDateFormat))
+	key4 = hmacSHA256(key4, t.Region)
+	key4 = hmacSHA256(key4, "s3")
+	key4 = hmacSHA256(key4, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key4, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		awsSignAlgorithm, t.AccessKeyID, scope, signedHeaders, signature))
+
+	return req, nil
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	_, _ = h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// awsURIEncode encodes s as required by AWS Signature Version 4: every byte
+// except the unreserved characters is percent-encoded, and the slash is only
+// encoded when encodeSlash is true.
+func awsURIEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func awsCanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		for _, v := range query[k] {
+			parts = append(parts, awsURIEncode(k, true)+"="+awsURIEncode(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// PersistentVolumeClaimTarget writes the files of an exported VM to a
+// PersistentVolumeClaim. The files are uploaded over TLS to a transfer Pod
+// that mounts the claim and runs the export transfer server, which writes
+// each file to the directory in the claim.
+//
+// VM Operator may only manage the transfer Pod and its Secret while the
+// RoleBinding of the transfer ClusterRole to its service account exists in
+// the namespace of the export request. Prepare creates the RoleBinding, the
+// Secret and the Pod, and Close deletes them.
+type PersistentVolumeClaimTarget struct {
+	Client    client.Client
+	APIReader client.Reader
+
+	Namespace string
+	// Name is the name of the transfer Pod, its Secret and the RoleBinding.
+	Name      string
+	ClaimName string
+	Path      string
+	Image     string
+	Owner     metav1.OwnerReference
+
+	// ClusterRole is the name of the ClusterRole that allows VM Operator to
+	// manage the transfer Pod and its Secret.
+	ClusterRole string
+	// ServiceAccount is the service account of VM Operator.
+	ServiceAccount rbacv1.Subject
+
+	transfer *exporttransfer.Client
+}
+
+// TransferPod returns the Pod that mounts the PersistentVolumeClaim the
+// files are written to.
+func (t *PersistentVolumeClaimTarget) TransferPod() *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            t.Name,
+			Namespace:       t.Namespace,
+			OwnerReferences: []metav1.OwnerReference{t.Owner},
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy:                corev1.RestartPolicyNever,
+			AutomountServiceAccountToken: pointer.Bool(false),
+			Containers: []corev1.Container{
+				{
+					Name:  transferPodContainerName,
+					Image: t.Image,
+					Command: []string{
+						"/export-transfer",
+						"--dir=" + path.Join(TransferPodVolumeMountPath, t.Path),
+						"--cert-file=" + path.Join(transferPodSecretMountPath, corev1.TLSCertKey),
+						"--key-file=" + path.Join(transferPodSecretMountPath, corev1.TLSPrivateKeyKey),
+					},
+					Env: []corev1.EnvVar{
+						{
+							Name: exporttransfer.TokenEnv,
+							ValueFrom: &corev1.EnvVarSource{
+								SecretKeyRef: &corev1.SecretKeySelector{
+									LocalObjectReference: corev1.LocalObjectReference{Name: t.Name},
+									Key:                  exporttransfer.SecretTokenKey,
+								},
+							},
+						},
+					},
+					Ports: []corev1.ContainerPort{
+						{
+							Name:          "transfer",
+							ContainerPort: exporttransfer.DefaultPort,
+							Protocol:      corev1.ProtocolTCP,
+						},
+					},
+					ReadinessProbe: &corev1.Probe{
+						ProbeHandler: corev1.ProbeHandler{
+							TCPSocket: &corev1.TCPSocketAction{
+								Port: intstr.FromInt(exporttransfer.DefaultPort),
+							},
+						},
+						PeriodSeconds: 2,
+					},
+					// The files are written as root so the transfer Pod can
+					// write to a volume that is only writable by root, but
+					// with no capabilities and a read-only root filesystem.
+					SecurityContext: &corev1.SecurityContext{
+						RunAsUser:                pointer.Int64(0),
+						AllowPrivilegeEscalation: pointer.Bool(false),
+						ReadOnlyRootFilesystem:   pointer.Bool(true),
+						Capabilities: &corev1.Capabilities{
+							Drop: []corev1.Capability{"ALL"},
+						},
+					},
+					VolumeMounts: []corev1.VolumeMount{
+						{
+							Name:      "export",
+							MountPath: TransferPodVolumeMountPath,
+						},
+						{
+							Name:      "transfer-secret",
+							MountPath: transferPodSecretMountPath,
+							ReadOnly:  true,
+						},
+					},
+				},
+			},
+			Volumes: []corev1.Volume{
+				{
+					Name: "export",
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+							ClaimName: t.ClaimName,
+						},
+					},
+				},
+				{
+					Name: "transfer-secret",
+					VolumeSource: corev1.VolumeSource{
+						Secret: &corev1.SecretVolumeSource{
+							SecretName: t.Name,
+							Items: []corev1.KeyToPath{
+								{Key: corev1.TLSCertKey, Path: corev1.TLSCertKey},
+								{Key: corev1.TLSPrivateKeyKey, Path: corev1.TLSPrivateKeyKey},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// Prepare creates the RoleBinding, the Secret and the transfer Pod if they do
+// not exist, and returns true once the transfer Pod is ready to receive the
+// files.
+func (t *PersistentVolumeClaimTarget) Prepare(ctx goctx.Context) (bool, error) {
+	if err := t.ensureRoleBinding(ctx); err != nil {
+		return false, err
+	}
+
+	secret, err := t.ensureSecret(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	pod, err := t.ensurePod(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	switch pod.Status.Phase {
+	case corev1.PodFailed, corev1.PodSucceeded:
+		// The Pod is created again when the target is next prepared.
+		if err := t.Client.Delete(ctx, pod); err != nil && !apierrors.IsNotFound(err) {
+			return false, err
+		}
+		return false, fmt.Errorf("transfer pod is %s", pod.Status.Phase)
+	}
+
+	if pod.Status.PodIP == "" || !isPodReady(pod) {
+		return false, nil
+	}
+
+	transfer, err := exporttransfer.NewClient(pod.Status.PodIP, secret.Data)
+	if err != nil {
+		return false, err
+	}
+	t.transfer = transfer
+	return true, nil
+}
+
+// WriteFile uploads the file to the transfer Pod, which must have been
+// prepared.
+func (t *PersistentVolumeClaimTarget) WriteFile(ctx goctx.Context, name string, size int64, r io.Reader) error {
+	if t.transfer == nil {
+		return errors.New("transfer pod is not ready")
+	}
+	return t.transfer.WriteFile(ctx, name, size, r)
+}
+
+// Close deletes the transfer Pod, its Secret and then the RoleBinding that
+// allowed VM Operator to manage them.
+func (t *PersistentVolumeClaimTarget) Close(ctx goctx.Context) error {
+	for _, obj := range []client.Object{&corev1.Pod{}, &corev1.Secret{}, &rbacv1.RoleBinding{}} {
+		if err := t.APIReader.Get(ctx, client.ObjectKey{Namespace: t.Namespace, Name: t.Name}, obj); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		if t.checkOwner(obj) != nil {
+			continue
+		}
+
+		uid := obj.GetUID()
+		if err := t.Client.Delete(ctx, obj, client.Preconditions{UID: &uid}); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *PersistentVolumeClaimTarget) ensureRoleBinding(ctx goctx.Context) error {
+	rb := &rbacv1.RoleBinding{}
+	err := t.APIReader.Get(ctx, client.ObjectKey{Namespace: t.Namespace, Name: t.Name}, rb)
+	if err == nil {
+		return t.checkOwner(rb)
+	}
+	if !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	rb = &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            t.Name,
+			Namespace:       t.Namespace,
+			OwnerReferences: []metav1.OwnerReference{t.Owner},
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     t.ClusterRole,
+		},
+		Subjects: []rbacv1.Subject{t.ServiceAccount},
+	}
+	if err := t.Client.Create(ctx, rb); err != nil {
+		return errors.Wrap(err, "failed to create transfer role binding")
+	}
+	return nil
+}
+
+func (t *PersistentVolumeClaimTarget) ensureSecret(ctx goctx.Context) (*corev1.Secret, error) {
+	secret := &corev1.Secret{}
+	err := t.APIReader.Get(ctx, client.ObjectKey{Namespace: t.Namespace, Name: t.Name}, secret)
+	if err == nil {
+		return secret, t.checkOwner(secret)
+	}
+	if !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	data, err := exporttransfer.NewSecretData()
+	if err != nil {
+		return nil, err
+	}
+	secret = &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            t.Name,
+			Namespace:       t.Namespace,
+			OwnerReferences: []metav1.OwnerReference{t.Owner},
+		},
+		Data: data,
+	}
+	if err := t.Client.Create(ctx, secret); err != nil {
+		return nil, errors.Wrap(err, "failed to create transfer secret")
+	}
+	return secret, nil
+}
+
+func (t *PersistentVolumeClaimTarget) ensurePod(ctx goctx.Context) (*corev1.Pod, error) {
+	pod := &corev1.Pod{}
+	err := t.APIReader.Get(ctx, client.ObjectKey{Namespace: t.Namespace, Name: t.Name}, pod)
+	if err == nil {
+		return pod, t.checkOwner(pod)
+	}
+	if !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	pod = t.TransferPod()
+	if err := t.Client.Create(ctx, pod); err != nil {
+		return nil, errors.Wrap(err, "failed to create transfer pod")
+	}
+	return pod, nil
+}
+
+// checkOwner returns an error if the object was not created for the export
+// request, so an object created by someone else is never used or deleted.
+func (t *PersistentVolumeClaimTarget) checkOwner(obj metav1.Object) error {
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.UID == t.Owner.UID && ref.Kind == t.Owner.Kind && ref.Name == t.Owner.Name {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s %s exists and is not owned by the export request", reflect.TypeOf(obj).Elem().Name(), obj.GetName())
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
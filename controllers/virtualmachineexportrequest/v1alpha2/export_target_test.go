@@ -0,0 +1,188 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha2_test
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	virtualmachineexportrequest "github.com/vmware-tanzu/vm-operator/controllers/virtualmachineexportrequest/v1alpha2"
+	"github.com/vmware-tanzu/vm-operator/pkg/exporttransfer"
+)
+
+// fakeObjectStore is a minimal S3-compatible server that supports single
+// and multipart uploads.
+type fakeObjectStore struct {
+	sync.Mutex
+	objects map[string]string
+	uploads map[string][]string
+	auth    []string
+}
+
+func (s *fakeObjectStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.auth = append(s.auth, r.Header.Get("Authorization"))
+	body, _ := io.ReadAll(r.Body)
+	query := r.URL.Query()
+
+	switch {
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		s.uploads[r.URL.Path] = nil
+		fmt.Fprint(w, "<InitiateMultipartUploadResult><UploadId>upload-1</UploadId></InitiateMultipartUploadResult>")
+	case r.Method == http.MethodPut && query.Has("partNumber"):
+		Expect(query.Get("uploadId")).To(Equal("upload-1"))
+		s.uploads[r.URL.Path] = append(s.uploads[r.URL.Path], string(body))
+		w.Header().Set("ETag", fmt.Sprintf(`"etag-%s"`, query.Get("partNumber")))
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		var complete struct {
+			Parts []struct {
+				PartNumber int
+				ETag       string
+			} `xml:"Part"`
+		}
+		Expect(xml.Unmarshal(body, &complete)).To(Succeed())
+		Expect(complete.Parts).To(HaveLen(len(s.uploads[r.URL.Path])))
+		s.objects[r.URL.Path] = strings.Join(s.uploads[r.URL.Path], "")
+		delete(s.uploads, r.URL.Path)
+	case r.Method == http.MethodPut:
+		Expect(r.ContentLength).To(BeEquivalentTo(len(body)))
+		s.objects[r.URL.Path] = string(body)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func objectStoreTargetTests() {
+	var (
+		store  *fakeObjectStore
+		server *httptest.Server
+		target *virtualmachineexportrequest.ObjectStoreTarget
+	)
+
+	BeforeEach(func() {
+		store = &fakeObjectStore{
+			objects: map[string]string{},
+			uploads: map[string][]string{},
+		}
+		server = httptest.NewServer(store)
+		target = &virtualmachineexportrequest.ObjectStoreTarget{
+			Endpoint:        server.URL,
+			Bucket:          "my-bucket",
+			Prefix:          "my-export",
+			Region:          "us-east-1",
+			AccessKeyID:     "access-key",
+			SecretAccessKey: "secret-key",
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("uploads a file with a single signed request", func() {
+		Expect(target.WriteFile(context.Background(), "my-vm.ovf", 5, strings.NewReader("hello"))).To(Succeed())
+
+		Expect(store.objects).To(HaveKeyWithValue("/my-bucket/my-export/my-vm.ovf", "hello"))
+		Expect(store.auth).To(HaveLen(1))
+		Expect(store.auth[0]).To(HavePrefix("AWS4-HMAC-SHA256 Credential=access-key/"))
+		Expect(store.auth[0]).To(ContainSubstring("/us-east-1/s3/aws4_request"))
+		Expect(store.auth[0]).To(ContainSubstring("SignedHeaders=host;x-amz-content-sha256;x-amz-date"))
+	})
+
+	It("uploads a file larger than the part size with a multipart upload", func() {
+		target.PartSize = 4
+		content := "0123456789"
+		Expect(target.WriteFile(context.Background(), "my-vm-disk-0.vmdk", int64(len(content)), strings.NewReader(content))).To(Succeed())
+
+		Expect(store.objects).To(HaveKeyWithValue("/my-bucket/my-export/my-vm-disk-0.vmdk", content))
+		Expect(store.uploads).To(BeEmpty())
+		// Initiate, three parts and complete.
+		Expect(store.auth).To(HaveLen(5))
+	})
+
+	It("uploads a file of unknown size with a multipart upload", func() {
+		content := "0123456789"
+		Expect(target.WriteFile(context.Background(), "my-vm-disk-0.vmdk", -1, strings.NewReader(content))).To(Succeed())
+
+		Expect(store.objects).To(HaveKeyWithValue("/my-bucket/my-export/my-vm-disk-0.vmdk", content))
+		// Initiate, one part and complete.
+		Expect(store.auth).To(HaveLen(3))
+	})
+
+	It("does not follow a redirect of the object store", func() {
+		var redirected bool
+		other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			redirected = true
+		}))
+		defer other.Close()
+		server.Config.Handler = http.RedirectHandler(other.URL, http.StatusTemporaryRedirect)
+
+		err := target.WriteFile(context.Background(), "my-vm.ovf", 5, strings.NewReader("hello"))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("307"))
+		Expect(redirected).To(BeFalse())
+	})
+
+	It("returns an error when the object store fails the request", func() {
+		target.Bucket = ""
+		server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		})
+
+		err := target.WriteFile(context.Background(), "my-vm.ovf", 5, strings.NewReader("hello"))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("403"))
+	})
+}
+
+func pvcTargetTests() {
+	It("returns a transfer pod that mounts the PersistentVolumeClaim and runs the transfer server", func() {
+		target := &virtualmachineexportrequest.PersistentVolumeClaimTarget{
+			Namespace: "my-ns",
+			Name:      "my-export-export",
+			ClaimName: "my-pvc",
+			Path:      "my-export",
+			Image:     "vmoperator-controller",
+			Owner: metav1.OwnerReference{
+				Kind: "VirtualMachineExportRequest",
+				Name: "my-export",
+			},
+		}
+
+		pod := target.TransferPod()
+		Expect(pod.Name).To(Equal("my-export-export"))
+		Expect(pod.Namespace).To(Equal("my-ns"))
+		Expect(pod.OwnerReferences).To(ConsistOf(target.Owner))
+		Expect(pod.Spec.Containers).To(HaveLen(1))
+
+		container := pod.Spec.Containers[0]
+		Expect(container.Image).To(Equal("vmoperator-controller"))
+		Expect(container.Command[0]).To(Equal("/export-transfer"))
+		Expect(container.Command).To(ContainElement("--dir=" + virtualmachineexportrequest.TransferPodVolumeMountPath + "/my-export"))
+		Expect(container.Env).To(HaveLen(1))
+		Expect(container.Env[0].Name).To(Equal(exporttransfer.TokenEnv))
+		Expect(container.Env[0].ValueFrom.SecretKeyRef.Name).To(Equal("my-export-export"))
+		Expect(container.VolumeMounts).To(HaveLen(2))
+		Expect(container.VolumeMounts[0].MountPath).To(Equal(virtualmachineexportrequest.TransferPodVolumeMountPath))
+
+		Expect(pod.Spec.Volumes).To(HaveLen(2))
+		Expect(pod.Spec.Volumes[0].PersistentVolumeClaim).ToNot(BeNil())
+		Expect(pod.Spec.Volumes[0].PersistentVolumeClaim.ClaimName).To(Equal("my-pvc"))
+		Expect(pod.Spec.Volumes[1].Secret).ToNot(BeNil())
+		Expect(pod.Spec.Volumes[1].Secret.SecretName).To(Equal("my-export-export"))
+	})
+}
@@ -0,0 +1,519 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha2
+
+import (
+	goctx "context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	conditions "github.com/vmware-tanzu/vm-operator/pkg/conditions2"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/lib"
+	patch "github.com/vmware-tanzu/vm-operator/pkg/patch2"
+	"github.com/vmware-tanzu/vm-operator/pkg/record"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
+)
+
+const (
+	// requeueDelay is how long to wait before checking again whether a
+	// request can be started or an export in progress has finished.
+	requeueDelay = 10 * time.Second
+)
+
+// AddToManager adds this package's controller to the provided manager.
+func AddToManager(ctx *context.ControllerManagerContext, mgr manager.Manager) error {
+	var (
+		controlledType     = &vmopv1.VirtualMachineExportRequest{}
+		controlledTypeName = reflect.TypeOf(controlledType).Elem().Name()
+
+		controllerNameShort = fmt.Sprintf("%s-controller", strings.ToLower(controlledTypeName))
+		controllerNameLong  = fmt.Sprintf("%s/%s/%s", ctx.Namespace, ctx.Name, controllerNameShort)
+	)
+
+	r := NewReconciler(
+		mgr.GetClient(),
+		mgr.GetAPIReader(),
+		ctrl.Log.WithName("controllers").WithName(controlledTypeName),
+		record.New(mgr.GetEventRecorderFor(controllerNameLong)),
+		ctx.VMProviderA2,
+		rbacv1.Subject{
+			Kind:      rbacv1.ServiceAccountKind,
+			Namespace: ctx.Namespace,
+			Name:      ctx.ServiceAccountName,
+		},
+	)
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(controlledType).
+		WatchesRawSource(&source.Channel{Source: r.exportDone}, &handler.EnqueueRequestForObject{}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: ctx.MaxConcurrentReconciles}).
+		Complete(r)
+}
+
+func NewReconciler(
+	client client.Client,
+	apiReader client.Reader,
+	logger logr.Logger,
+	recorder record.Recorder,
+	vmProvider vmprovider.VirtualMachineProviderInterfaceA2,
+	serviceAccount rbacv1.Subject) *Reconciler {
+
+	return &Reconciler{
+		Client:         client,
+		APIReader:      apiReader,
+		Logger:         logger,
+		Recorder:       recorder,
+		VMProvider:     vmProvider,
+		ServiceAccount: serviceAccount,
+		exports:        map[string]*exportState{},
+		exportDone:     make(chan event.GenericEvent, 100),
+	}
+}
+
+// Reconciler reconciles a VirtualMachineExportRequest object.
+type Reconciler struct {
+	client.Client
+	APIReader  client.Reader
+	Logger     logr.Logger
+	Recorder   record.Recorder
+	VMProvider vmprovider.VirtualMachineProviderInterfaceA2
+
+	// ServiceAccount is the service account of VM Operator, which is bound
+	// to the transfer ClusterRole in the namespace of an export request
+	// while the files are written to a PersistentVolumeClaim.
+	ServiceAccount rbacv1.Subject
+
+	// exports are the exports in progress, keyed by the namespaced name of
+	// their request. An export runs in its own goroutine since it can take
+	// much longer than a reconcile should. Its progress and the files written
+	// so far are copied to the status of the request on each reconcile, so an
+	// export that was in progress when the controller restarted continues
+	// from the files that were written.
+	exportsMu sync.Mutex
+	exports   map[string]*exportState
+
+	// exportDone receives the request of an export that finished so it is
+	// reconciled without waiting for the next requeue.
+	exportDone chan event.GenericEvent
+}
+
+// exportState is the state of an export in progress.
+type exportState struct {
+	mu       sync.Mutex
+	progress int32
+	files    []vmopv1.VirtualMachineExportFile
+	done     bool
+	result   vmprovider.VirtualMachineExportResultA2
+	err      error
+	cancel   goctx.CancelFunc
+	target   vmprovider.VirtualMachineExportTargetA2
+}
+
+func (s *exportState) setProgress(percent int32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.progress = percent
+}
+
+func (s *exportState) fileWritten(file vmopv1.VirtualMachineExportFile) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.files {
+		if s.files[i].Name == file.Name {
+			s.files[i] = file
+			return
+		}
+	}
+	s.files = append(s.files, file)
+}
+
+func (s *exportState) finish(result vmprovider.VirtualMachineExportResultA2, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.done = true
+	s.result = result
+	s.err = err
+}
+
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachineexportrequests,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachineexportrequests/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachines,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=get;create;delete
+
+// The transfer Pod and its Secret are managed with the permissions of the
+// transfer ClusterRole, which VM Operator only binds to itself in the
+// namespace of an export request to a PersistentVolumeClaim while the files
+// are written. The permission to bind the ClusterRole is granted in
+// config/rbac/export_transfer_role.yaml.
+
+func (r *Reconciler) Reconcile(ctx goctx.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
+	exportReq := &vmopv1.VirtualMachineExportRequest{}
+	if err := r.Get(ctx, req.NamespacedName, exportReq); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.cancelExport(req.NamespacedName.String())
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if !exportReq.DeletionTimestamp.IsZero() {
+		r.cancelExport(exportReq.NamespacedName())
+		return ctrl.Result{}, nil
+	}
+
+	exportCtx := &context.VirtualMachineExportRequestContextA2{
+		Context:       ctx,
+		Logger:        ctrl.Log.WithName("VirtualMachineExportRequest").WithValues("name", exportReq.NamespacedName()),
+		ExportRequest: exportReq,
+	}
+
+	patchHelper, err := patch.NewHelper(exportReq, r.Client)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to init patch helper for %s", exportCtx.String())
+	}
+	defer func() {
+		if err := patchHelper.Patch(ctx, exportReq); err != nil {
+			if reterr == nil {
+				reterr = err
+			}
+			exportCtx.Logger.Error(err, "patch failed")
+		}
+	}()
+
+	return r.ReconcileNormal(exportCtx)
+}
+
+// ReconcileNormal starts the export once the source VM and the target are
+// valid, and then reports the progress of the export until it is complete.
+// A request is only processed once: after it is complete, a new request must
+// be created to export the VM again.
+func (r *Reconciler) ReconcileNormal(ctx *context.VirtualMachineExportRequestContextA2) (ctrl.Result, error) {
+	exportReq := ctx.ExportRequest
+	if conditions.IsTrue(exportReq, vmopv1.VirtualMachineExportRequestConditionComplete) {
+		return ctrl.Result{}, nil
+	}
+
+	ctx.Logger.Info("Reconciling VirtualMachineExportRequest")
+	defer func() {
+		ctx.Logger.Info("Finished Reconciling VirtualMachineExportRequest")
+	}()
+
+	if exportReq.Status.StartTime.IsZero() {
+		exportReq.Status.StartTime = metav1.Now()
+	}
+
+	state := r.getExport(exportReq.NamespacedName())
+	if state == nil {
+		// The export is not in progress. This is also the case when the
+		// export was in progress when the controller restarted, and then the
+		// export continues from the files it had written.
+		if ok, err := r.validateSource(ctx); err != nil {
+			return ctrl.Result{}, err
+		} else if !ok {
+			return ctrl.Result{RequeueAfter: requeueDelay}, nil
+		}
+
+		target, err := r.getTarget(ctx)
+		if err != nil {
+			return ctrl.Result{}, err
+		} else if target == nil {
+			return ctrl.Result{RequeueAfter: requeueDelay}, nil
+		}
+
+		r.startExport(ctx, target)
+		conditions.MarkFalse(exportReq, vmopv1.VirtualMachineExportRequestConditionExported,
+			vmopv1.ExportingReason, "")
+		return ctrl.Result{RequeueAfter: requeueDelay}, nil
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	exportReq.Status.Progress = state.progress
+	if !state.done {
+		exportReq.Status.Files = append([]vmopv1.VirtualMachineExportFile(nil), state.files...)
+		return ctrl.Result{RequeueAfter: requeueDelay}, nil
+	}
+
+	if closer, ok := state.target.(interface{ Close(goctx.Context) error }); ok {
+		if err := closer.Close(ctx); err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "failed to close export target")
+		}
+	}
+
+	r.deleteExport(exportReq.NamespacedName())
+
+	if state.err != nil {
+		ctx.Logger.Error(state.err, "Failed to export VM")
+		exportReq.Status.Files = nil
+		conditions.MarkFalse(exportReq, vmopv1.VirtualMachineExportRequestConditionExported,
+			vmopv1.ExportFailureReason, state.err.Error())
+	} else {
+		exportReq.Status.Progress = 100
+		exportReq.Status.Files = state.result.Files
+		exportReq.Status.Manifest = state.result.Manifest
+		exportReq.Status.Ready = true
+		conditions.MarkTrue(exportReq, vmopv1.VirtualMachineExportRequestConditionExported)
+	}
+
+	conditions.MarkTrue(exportReq, vmopv1.VirtualMachineExportRequestConditionComplete)
+	exportReq.Status.CompletionTime = metav1.Now()
+	r.Recorder.EmitEvent(exportReq, "Export", state.err, false)
+
+	return ctrl.Result{}, nil
+}
+
+// validateSource returns true when the VM to export exists, has been created
+// on the underlying infrastructure and is powered off.
+func (r *Reconciler) validateSource(ctx *context.VirtualMachineExportRequestContextA2) (bool, error) {
+	exportReq := ctx.ExportRequest
+
+	vm := &vmopv1.VirtualMachine{}
+	key := client.ObjectKey{Namespace: exportReq.Namespace, Name: exportReq.Spec.Source.Name}
+	if err := r.Get(ctx, key, vm); err != nil {
+		if apierrors.IsNotFound(err) {
+			conditions.MarkFalse(exportReq, vmopv1.VirtualMachineExportRequestConditionSourceValid,
+				vmopv1.SourceVirtualMachineNotExistReason, "")
+			return false, nil
+		}
+		return false, err
+	}
+
+	if vm.Status.UniqueID == "" {
+		conditions.MarkFalse(exportReq, vmopv1.VirtualMachineExportRequestConditionSourceValid,
+			vmopv1.SourceVirtualMachineNotCreatedReason, "")
+		return false, nil
+	}
+
+	if vm.Status.PowerState != vmopv1.VirtualMachinePowerStateOff {
+		conditions.MarkFalse(exportReq, vmopv1.VirtualMachineExportRequestConditionSourceValid,
+			vmopv1.SourceVirtualMachinePoweredOnReason, "")
+		return false, nil
+	}
+
+	ctx.VM = vm
+	conditions.MarkTrue(exportReq, vmopv1.VirtualMachineExportRequestConditionSourceValid)
+	return true, nil
+}
+
+// getTarget returns the target the files of the exported VM are written to,
+// or nil when the target is not ready to be written.
+func (r *Reconciler) getTarget(ctx *context.VirtualMachineExportRequestContextA2) (vmprovider.VirtualMachineExportTargetA2, error) {
+	exportReq := ctx.ExportRequest
+
+	if pvcTarget := exportReq.Spec.Target.PersistentVolumeClaim; pvcTarget != nil {
+		pvc := &corev1.PersistentVolumeClaim{}
+		key := client.ObjectKey{Namespace: exportReq.Namespace, Name: pvcTarget.ClaimName}
+		if err := r.Get(ctx, key, pvc); err != nil {
+			if apierrors.IsNotFound(err) {
+				conditions.MarkFalse(exportReq, vmopv1.VirtualMachineExportRequestConditionTargetValid,
+					vmopv1.TargetPersistentVolumeClaimNotExistReason, "")
+				return nil, nil
+			}
+			return nil, err
+		}
+
+		image := lib.GetVMExportTransferImage()
+		clusterRole := lib.GetVMExportTransferClusterRole()
+		if image == "" || clusterRole == "" {
+			conditions.MarkFalse(exportReq, vmopv1.VirtualMachineExportRequestConditionTargetValid,
+				vmopv1.TargetTransferImageNotConfiguredReason, "")
+			return nil, nil
+		}
+
+		dir := pvcTarget.Path
+		if dir == "" {
+			dir = exportReq.Name
+		}
+
+		target := &PersistentVolumeClaimTarget{
+			Client:         r.Client,
+			APIReader:      r.APIReader,
+			Namespace:      exportReq.Namespace,
+			Name:           exportReq.Name + "-export",
+			ClaimName:      pvcTarget.ClaimName,
+			Path:           dir,
+			Image:          image,
+			ClusterRole:    clusterRole,
+			ServiceAccount: r.ServiceAccount,
+			Owner: metav1.OwnerReference{
+				APIVersion: vmopv1.SchemeGroupVersion.String(),
+				Kind:       "VirtualMachineExportRequest",
+				Name:       exportReq.Name,
+				UID:        exportReq.UID,
+				Controller: pointer.Bool(true),
+			},
+		}
+
+		if ready, err := target.Prepare(ctx); err != nil {
+			conditions.MarkFalse(exportReq, vmopv1.VirtualMachineExportRequestConditionTargetValid,
+				vmopv1.TargetTransferPodNotReadyReason, err.Error())
+			return nil, err
+		} else if !ready {
+			conditions.MarkFalse(exportReq, vmopv1.VirtualMachineExportRequestConditionTargetValid,
+				vmopv1.TargetTransferPodNotReadyReason, "")
+			return nil, nil
+		}
+
+		conditions.MarkTrue(exportReq, vmopv1.VirtualMachineExportRequestConditionTargetValid)
+		return target, nil
+	}
+
+	if osTarget := exportReq.Spec.Target.ObjectStore; osTarget != nil {
+		// The allowed object stores may have changed since the webhook
+		// validated the request.
+		if !lib.IsVMExportObjectStoreEndpointAllowed(osTarget.Endpoint) {
+			conditions.MarkFalse(exportReq, vmopv1.VirtualMachineExportRequestConditionTargetValid,
+				vmopv1.TargetObjectStoreEndpointNotAllowedReason, "")
+			return nil, nil
+		}
+
+		secret := &corev1.Secret{}
+		key := client.ObjectKey{Namespace: exportReq.Namespace, Name: osTarget.CredentialsSecretName}
+		if err := r.Get(ctx, key, secret); err != nil {
+			if apierrors.IsNotFound(err) {
+				conditions.MarkFalse(exportReq, vmopv1.VirtualMachineExportRequestConditionTargetValid,
+					vmopv1.TargetCredentialsInvalidReason, "Secret %s does not exist", osTarget.CredentialsSecretName)
+				return nil, nil
+			}
+			return nil, err
+		}
+
+		for _, k := range []string{
+			vmopv1.VirtualMachineExportObjectStoreAccessKeyIDKey,
+			vmopv1.VirtualMachineExportObjectStoreSecretAccessKeyKey} {
+
+			if len(secret.Data[k]) == 0 {
+				conditions.MarkFalse(exportReq, vmopv1.VirtualMachineExportRequestConditionTargetValid,
+					vmopv1.TargetCredentialsInvalidReason, "Secret %s is missing key %s", secret.Name, k)
+				return nil, nil
+			}
+		}
+
+		prefix := osTarget.Prefix
+		if prefix == "" {
+			prefix = exportReq.Name
+		}
+		region := osTarget.Region
+		if region == "" {
+			region = "us-east-1"
+		}
+
+		conditions.MarkTrue(exportReq, vmopv1.VirtualMachineExportRequestConditionTargetValid)
+		return &ObjectStoreTarget{
+			Endpoint:        osTarget.Endpoint,
+			Bucket:          osTarget.Bucket,
+			Prefix:          prefix,
+			Region:          region,
+			AccessKeyID:     string(secret.Data[vmopv1.VirtualMachineExportObjectStoreAccessKeyIDKey]),
+			SecretAccessKey: string(secret.Data[vmopv1.VirtualMachineExportObjectStoreSecretAccessKeyKey]),
+		}, nil
+	}
+
+	// The webhook requires exactly one target, so this should not happen.
+	return nil, errors.New("export request has no target")
+}
+
+// startExport starts exporting the VM in a new goroutine.
+func (r *Reconciler) startExport(
+	ctx *context.VirtualMachineExportRequestContextA2,
+	target vmprovider.VirtualMachineExportTargetA2) {
+
+	exportReq := ctx.ExportRequest
+	vm := ctx.VM.DeepCopy()
+
+	format := exportReq.Spec.Format
+	if format == "" {
+		format = vmopv1.VirtualMachineExportFormatOVF
+	}
+
+	// The files an earlier attempt of the export wrote are only kept in the
+	// status while the VM is exported.
+	var written []vmopv1.VirtualMachineExportFile
+	if conditions.GetReason(exportReq, vmopv1.VirtualMachineExportRequestConditionExported) == vmopv1.ExportingReason {
+		written = exportReq.Status.Files
+	}
+
+	exportCtx, cancel := goctx.WithCancel(goctx.Background())
+	state := &exportState{
+		progress: exportReq.Status.Progress,
+		files:    append([]vmopv1.VirtualMachineExportFile(nil), written...),
+		cancel:   cancel,
+		target:   target,
+	}
+
+	r.exportsMu.Lock()
+	r.exports[exportReq.NamespacedName()] = state
+	r.exportsMu.Unlock()
+
+	obj := &vmopv1.VirtualMachineExportRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: exportReq.Namespace,
+			Name:      exportReq.Name,
+		},
+	}
+	go func() {
+		defer cancel()
+
+		result, err := r.VMProvider.ExportVirtualMachine(exportCtx, vm, vmprovider.VirtualMachineExportA2{
+			Name:        vm.Name,
+			Format:      format,
+			Target:      target,
+			Progress:    state.setProgress,
+			Written:     written,
+			FileWritten: state.fileWritten,
+		})
+
+		state.finish(result, err)
+
+		select {
+		case r.exportDone <- event.GenericEvent{Object: obj}:
+		default:
+			// The request is reconciled on its next requeue instead.
+		}
+	}()
+}
+
+func (r *Reconciler) getExport(key string) *exportState {
+	r.exportsMu.Lock()
+	defer r.exportsMu.Unlock()
+	return r.exports[key]
+}
+
+func (r *Reconciler) deleteExport(key string) {
+	r.exportsMu.Lock()
+	defer r.exportsMu.Unlock()
+	delete(r.exports, key)
+}
+
+// cancelExport cancels the export of a request that was deleted.
+func (r *Reconciler) cancelExport(key string) {
+	r.exportsMu.Lock()
+	defer r.exportsMu.Unlock()
+	if state, ok := r.exports[key]; ok {
+		state.cancel()
+		delete(r.exports, key)
+	}
+}
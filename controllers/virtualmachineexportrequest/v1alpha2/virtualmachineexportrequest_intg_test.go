@@ -0,0 +1,117 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha2_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	conditions "github.com/vmware-tanzu/vm-operator/pkg/conditions2"
+	"github.com/vmware-tanzu/vm-operator/pkg/lib"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func intgTests() {
+	Describe("Invoking VirtualMachineExportRequest controller tests", intgTestsReconcile)
+}
+
+func intgTestsReconcile() {
+	var (
+		ctx       *builder.IntegrationTestContext
+		exportReq *vmopv1.VirtualMachineExportRequest
+		vm        *vmopv1.VirtualMachine
+
+		oldGetEndpoints func() []string
+	)
+
+	getExportRequest := func(ctx *builder.IntegrationTestContext, objKey client.ObjectKey) *vmopv1.VirtualMachineExportRequest {
+		exportReq := &vmopv1.VirtualMachineExportRequest{}
+		if err := ctx.Client.Get(ctx, objKey, exportReq); err != nil {
+			return nil
+		}
+		return exportReq
+	}
+
+	BeforeEach(func() {
+		ctx = suite.NewIntegrationTestContext()
+		vm = builder.DummyBasicVirtualMachineA2("dummy-vm", ctx.Namespace)
+		exportReq = builder.DummyVirtualMachineExportRequest(ctx.Namespace, "dummy-export", vm.Name)
+
+		oldGetEndpoints = lib.GetVMExportObjectStoreEndpoints
+		lib.GetVMExportObjectStoreEndpoints = func() []string {
+			return []string{"https://s3.example.com"}
+		}
+	})
+
+	AfterEach(func() {
+		ctx.AfterEach()
+		ctx = nil
+		fakeVMProvider.Reset()
+		lib.GetVMExportObjectStoreEndpoints = oldGetEndpoints
+	})
+
+	Context("Reconcile", func() {
+		BeforeEach(func() {
+			fakeVMProvider.Lock()
+			fakeVMProvider.ExportVirtualMachineFn = func(
+				_ context.Context,
+				_ *vmopv1.VirtualMachine,
+				_ vmprovider.VirtualMachineExportA2) (vmprovider.VirtualMachineExportResultA2, error) {
+
+				return vmprovider.VirtualMachineExportResultA2{
+					Files: []vmopv1.VirtualMachineExportFile{
+						{Name: "dummy-vm.ovf", Size: 42, Checksum: "abc"},
+					},
+					Manifest: "SHA256(dummy-vm.ovf)= abc\n",
+				}, nil
+			}
+			fakeVMProvider.Unlock()
+
+			Expect(ctx.Client.Create(ctx, vm)).To(Succeed())
+			vm.Status.UniqueID = "vm-42"
+			vm.Status.PowerState = vmopv1.VirtualMachinePowerStateOff
+			Expect(ctx.Client.Status().Update(ctx, vm)).To(Succeed())
+
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      exportReq.Spec.Target.ObjectStore.CredentialsSecretName,
+					Namespace: ctx.Namespace,
+				},
+				StringData: map[string]string{
+					vmopv1.VirtualMachineExportObjectStoreAccessKeyIDKey:     "access-key",
+					vmopv1.VirtualMachineExportObjectStoreSecretAccessKeyKey: "secret-key",
+				},
+			}
+			Expect(ctx.Client.Create(ctx, secret)).To(Succeed())
+
+			Expect(ctx.Client.Create(ctx, exportReq)).To(Succeed())
+		})
+
+		It("exports the VM", func() {
+			objKey := client.ObjectKeyFromObject(exportReq)
+
+			Eventually(func() bool {
+				if obj := getExportRequest(ctx, objKey); obj != nil {
+					return conditions.IsTrue(obj, vmopv1.VirtualMachineExportRequestConditionComplete)
+				}
+				return false
+			}).Should(BeTrue())
+
+			obj := getExportRequest(ctx, objKey)
+			Expect(obj.Status.Ready).To(BeTrue())
+			Expect(obj.Status.Progress).To(BeEquivalentTo(100))
+			Expect(obj.Status.Files).To(HaveLen(1))
+			Expect(obj.Status.Manifest).ToNot(BeEmpty())
+			Expect(conditions.IsTrue(obj, vmopv1.VirtualMachineExportRequestConditionExported)).To(BeTrue())
+		})
+	})
+}
@@ -0,0 +1,443 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha2_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	virtualmachineexportrequest "github.com/vmware-tanzu/vm-operator/controllers/virtualmachineexportrequest/v1alpha2"
+	conditions "github.com/vmware-tanzu/vm-operator/pkg/conditions2"
+	vmopContext "github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/exporttransfer"
+	"github.com/vmware-tanzu/vm-operator/pkg/lib"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
+	providerfake "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/fake"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func unitTests() {
+	Describe("Invoking VirtualMachineExportRequest Reconcile", unitTestsReconcile)
+	Describe("ObjectStoreTarget", objectStoreTargetTests)
+	Describe("PersistentVolumeClaimTarget", pvcTargetTests)
+}
+
+func unitTestsReconcile() {
+	const (
+		namespace = "dummy-ns"
+	)
+
+	var (
+		initObjects []client.Object
+		ctx         *builder.UnitTestContextForController
+
+		reconciler *virtualmachineexportrequest.Reconciler
+		exportCtx  *vmopContext.VirtualMachineExportRequestContextA2
+		exportReq  *vmopv1.VirtualMachineExportRequest
+		vm         *vmopv1.VirtualMachine
+		secret     *corev1.Secret
+
+		exportArgs     vmprovider.VirtualMachineExportA2
+		exportErr      error
+		exportBlock    chan struct{}
+		serviceAccount = rbacv1.Subject{
+			Kind:      rbacv1.ServiceAccountKind,
+			Namespace: "vmop-system",
+			Name:      "vmop-sa",
+		}
+		oldGetEndpoints func() []string
+	)
+
+	reconcileUntilComplete := func() {
+		Eventually(func() bool {
+			_, err := reconciler.ReconcileNormal(exportCtx)
+			Expect(err).ToNot(HaveOccurred())
+			return conditions.IsTrue(exportReq, vmopv1.VirtualMachineExportRequestConditionComplete)
+		}).Should(BeTrue())
+	}
+
+	BeforeEach(func() {
+		vm = builder.DummyBasicVirtualMachineA2("dummy-vm", namespace)
+		vm.Status.UniqueID = "vm-42"
+		vm.Status.PowerState = vmopv1.VirtualMachinePowerStateOff
+
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "dummy-credentials",
+				Namespace: namespace,
+			},
+			Data: map[string][]byte{
+				vmopv1.VirtualMachineExportObjectStoreAccessKeyIDKey:     []byte("access-key"),
+				vmopv1.VirtualMachineExportObjectStoreSecretAccessKeyKey: []byte("secret-key"),
+			},
+		}
+
+		exportReq = builder.DummyVirtualMachineExportRequest(namespace, "dummy-export", vm.Name)
+		initObjects = []client.Object{vm, secret}
+		exportArgs = vmprovider.VirtualMachineExportA2{}
+		exportErr = nil
+		exportBlock = nil
+
+		oldGetEndpoints = lib.GetVMExportObjectStoreEndpoints
+		lib.GetVMExportObjectStoreEndpoints = func() []string {
+			return []string{"https://s3.example.com"}
+		}
+	})
+
+	JustBeforeEach(func() {
+		ctx = suite.NewUnitTestContextForController(initObjects...)
+		reconciler = virtualmachineexportrequest.NewReconciler(
+			ctx.Client,
+			ctx.Client,
+			ctx.Logger,
+			ctx.Recorder,
+			ctx.VMProviderA2,
+			serviceAccount,
+		)
+		fakeVMProvider = ctx.VMProviderA2.(*providerfake.VMProviderA2)
+		fakeVMProvider.ExportVirtualMachineFn = func(
+			_ context.Context,
+			_ *vmopv1.VirtualMachine,
+			export vmprovider.VirtualMachineExportA2) (vmprovider.VirtualMachineExportResultA2, error) {
+
+			exportArgs = export
+			if exportErr != nil {
+				return vmprovider.VirtualMachineExportResultA2{}, exportErr
+			}
+			export.Progress(50)
+			export.FileWritten(vmopv1.VirtualMachineExportFile{Name: "dummy-vm-disk-0.vmdk", Size: 42, Checksum: "def"})
+			if exportBlock != nil {
+				<-exportBlock
+			}
+			return vmprovider.VirtualMachineExportResultA2{
+				Files: []vmopv1.VirtualMachineExportFile{
+					{Name: "dummy-vm.ovf", Size: 42, Checksum: "abc"},
+				},
+				Manifest: "SHA256(dummy-vm.ovf)= abc\n",
+			}, nil
+		}
+
+		exportCtx = &vmopContext.VirtualMachineExportRequestContextA2{
+			Context:       ctx,
+			Logger:        ctx.Logger.WithName(exportReq.Name),
+			ExportRequest: exportReq,
+		}
+	})
+
+	AfterEach(func() {
+		ctx.AfterEach()
+		ctx = nil
+		initObjects = nil
+		reconciler = nil
+		lib.GetVMExportObjectStoreEndpoints = oldGetEndpoints
+	})
+
+	Context("ReconcileNormal", func() {
+
+		It("exports the VM", func() {
+			reconcileUntilComplete()
+
+			Expect(exportArgs.Name).To(Equal("dummy-vm"))
+			Expect(exportArgs.Format).To(Equal(vmopv1.VirtualMachineExportFormatOVF))
+			target, ok := exportArgs.Target.(*virtualmachineexportrequest.ObjectStoreTarget)
+			Expect(ok).To(BeTrue())
+			Expect(target.Prefix).To(Equal("dummy-export"))
+			Expect(target.Region).To(Equal("us-east-1"))
+			Expect(target.AccessKeyID).To(Equal("access-key"))
+			Expect(target.SecretAccessKey).To(Equal("secret-key"))
+
+			Expect(exportReq.Status.Ready).To(BeTrue())
+			Expect(exportReq.Status.Progress).To(BeEquivalentTo(100))
+			Expect(exportReq.Status.Files).To(HaveLen(1))
+			Expect(exportReq.Status.Manifest).To(Equal("SHA256(dummy-vm.ovf)= abc\n"))
+			Expect(exportReq.Status.StartTime.IsZero()).To(BeFalse())
+			Expect(exportReq.Status.CompletionTime.IsZero()).To(BeFalse())
+			Expect(conditions.IsTrue(exportReq, vmopv1.VirtualMachineExportRequestConditionSourceValid)).To(BeTrue())
+			Expect(conditions.IsTrue(exportReq, vmopv1.VirtualMachineExportRequestConditionTargetValid)).To(BeTrue())
+			Expect(conditions.IsTrue(exportReq, vmopv1.VirtualMachineExportRequestConditionExported)).To(BeTrue())
+		})
+
+		When("the export is in progress", func() {
+			BeforeEach(func() {
+				exportBlock = make(chan struct{})
+			})
+
+			It("keeps the progress and the files written so far in the status", func() {
+				Eventually(func() []vmopv1.VirtualMachineExportFile {
+					_, err := reconciler.ReconcileNormal(exportCtx)
+					Expect(err).ToNot(HaveOccurred())
+					return exportReq.Status.Files
+				}).Should(ConsistOf(vmopv1.VirtualMachineExportFile{Name: "dummy-vm-disk-0.vmdk", Size: 42, Checksum: "def"}))
+				Expect(exportReq.Status.Progress).To(BeEquivalentTo(50))
+				Expect(exportReq.Status.Ready).To(BeFalse())
+
+				close(exportBlock)
+				reconcileUntilComplete()
+				Expect(exportReq.Status.Ready).To(BeTrue())
+			})
+		})
+
+		When("the export was in progress when the controller restarted", func() {
+			var written []vmopv1.VirtualMachineExportFile
+
+			BeforeEach(func() {
+				written = []vmopv1.VirtualMachineExportFile{{Name: "dummy-vm-disk-0.vmdk", Size: 42, Checksum: "def"}}
+				exportReq.Status.Files = written
+				exportReq.Status.Progress = 30
+				conditions.MarkFalse(exportReq, vmopv1.VirtualMachineExportRequestConditionExported,
+					vmopv1.ExportingReason, "")
+			})
+
+			It("continues the export from the files it wrote", func() {
+				reconcileUntilComplete()
+
+				Expect(exportArgs.Written).To(Equal(written))
+				Expect(exportReq.Status.Ready).To(BeTrue())
+			})
+		})
+
+		When("the export fails", func() {
+			BeforeEach(func() {
+				exportErr = errors.New("fake")
+			})
+
+			It("completes the request with a failure", func() {
+				reconcileUntilComplete()
+
+				Expect(exportReq.Status.Ready).To(BeFalse())
+				Expect(conditions.IsFalse(exportReq, vmopv1.VirtualMachineExportRequestConditionExported)).To(BeTrue())
+				Expect(conditions.GetReason(exportReq, vmopv1.VirtualMachineExportRequestConditionExported)).To(Equal(vmopv1.ExportFailureReason))
+				Expect(conditions.GetMessage(exportReq, vmopv1.VirtualMachineExportRequestConditionExported)).To(Equal("fake"))
+			})
+		})
+
+		When("the request is complete", func() {
+			BeforeEach(func() {
+				conditions.MarkTrue(exportReq, vmopv1.VirtualMachineExportRequestConditionComplete)
+			})
+
+			It("does not export the VM", func() {
+				_, err := reconciler.ReconcileNormal(exportCtx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(exportReq.Status.StartTime.IsZero()).To(BeTrue())
+			})
+		})
+
+		assertSourceNotValid := func(reason string) {
+			result, err := reconciler.ReconcileNormal(exportCtx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.RequeueAfter).ToNot(BeZero())
+			Expect(conditions.IsFalse(exportReq, vmopv1.VirtualMachineExportRequestConditionSourceValid)).To(BeTrue())
+			Expect(conditions.GetReason(exportReq, vmopv1.VirtualMachineExportRequestConditionSourceValid)).To(Equal(reason))
+			Expect(conditions.Has(exportReq, vmopv1.VirtualMachineExportRequestConditionExported)).To(BeFalse())
+		}
+
+		When("the VM does not exist", func() {
+			BeforeEach(func() {
+				initObjects = []client.Object{secret}
+			})
+
+			It("marks the source as not valid", func() {
+				assertSourceNotValid(vmopv1.SourceVirtualMachineNotExistReason)
+			})
+		})
+
+		When("the VM is not created", func() {
+			BeforeEach(func() {
+				vm.Status.UniqueID = ""
+			})
+
+			It("marks the source as not valid", func() {
+				assertSourceNotValid(vmopv1.SourceVirtualMachineNotCreatedReason)
+			})
+		})
+
+		When("the VM is powered on", func() {
+			BeforeEach(func() {
+				vm.Status.PowerState = vmopv1.VirtualMachinePowerStateOn
+			})
+
+			It("marks the source as not valid", func() {
+				assertSourceNotValid(vmopv1.SourceVirtualMachinePoweredOnReason)
+			})
+		})
+
+		When("the object store is not allowed", func() {
+			BeforeEach(func() {
+				exportReq.Spec.Target.ObjectStore.Endpoint = "http://169.254.169.254"
+			})
+
+			It("marks the target as not valid", func() {
+				_, err := reconciler.ReconcileNormal(exportCtx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(conditions.IsFalse(exportReq, vmopv1.VirtualMachineExportRequestConditionTargetValid)).To(BeTrue())
+				Expect(conditions.GetReason(exportReq, vmopv1.VirtualMachineExportRequestConditionTargetValid)).To(Equal(vmopv1.TargetObjectStoreEndpointNotAllowedReason))
+				Expect(exportArgs.Name).To(BeEmpty())
+			})
+		})
+
+		When("the credentials secret is missing a key", func() {
+			BeforeEach(func() {
+				delete(secret.Data, vmopv1.VirtualMachineExportObjectStoreSecretAccessKeyKey)
+			})
+
+			It("marks the target as not valid", func() {
+				_, err := reconciler.ReconcileNormal(exportCtx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(conditions.IsFalse(exportReq, vmopv1.VirtualMachineExportRequestConditionTargetValid)).To(BeTrue())
+				Expect(conditions.GetReason(exportReq, vmopv1.VirtualMachineExportRequestConditionTargetValid)).To(Equal(vmopv1.TargetCredentialsInvalidReason))
+			})
+		})
+
+		When("the target is a PersistentVolumeClaim", func() {
+			var pvc *corev1.PersistentVolumeClaim
+
+			BeforeEach(func() {
+				exportReq.Spec.Target = vmopv1.VirtualMachineExportRequestTarget{
+					PersistentVolumeClaim: &vmopv1.VirtualMachineExportPersistentVolumeClaimTarget{
+						ClaimName: "dummy-pvc",
+					},
+				}
+				pvc = &corev1.PersistentVolumeClaim{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "dummy-pvc",
+						Namespace: namespace,
+					},
+				}
+			})
+
+			When("the PersistentVolumeClaim does not exist", func() {
+				It("marks the target as not valid", func() {
+					_, err := reconciler.ReconcileNormal(exportCtx)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(conditions.GetReason(exportReq, vmopv1.VirtualMachineExportRequestConditionTargetValid)).To(Equal(vmopv1.TargetPersistentVolumeClaimNotExistReason))
+				})
+			})
+
+			When("the PersistentVolumeClaim exists", func() {
+				var (
+					oldGetImage       func() string
+					oldGetClusterRole func() string
+					transferKey       client.ObjectKey
+				)
+
+				BeforeEach(func() {
+					initObjects = append(initObjects, pvc)
+					oldGetImage = lib.GetVMExportTransferImage
+					oldGetClusterRole = lib.GetVMExportTransferClusterRole
+					lib.GetVMExportTransferImage = func() string { return "vmoperator-controller" }
+					lib.GetVMExportTransferClusterRole = func() string { return "export-transfer-role" }
+					transferKey = client.ObjectKey{Namespace: namespace, Name: "dummy-export-export"}
+				})
+
+				AfterEach(func() {
+					lib.GetVMExportTransferImage = oldGetImage
+					lib.GetVMExportTransferClusterRole = oldGetClusterRole
+				})
+
+				markTransferPodReady := func() {
+					pod := &corev1.Pod{}
+					Expect(ctx.Client.Get(ctx, transferKey, pod)).To(Succeed())
+					pod.Status = corev1.PodStatus{
+						Phase: corev1.PodRunning,
+						PodIP: "192.168.1.10",
+						Conditions: []corev1.PodCondition{
+							{Type: corev1.PodReady, Status: corev1.ConditionTrue},
+						},
+					}
+					Expect(ctx.Client.Update(ctx, pod)).To(Succeed())
+				}
+
+				It("marks the target as not valid when the transfer image is not configured", func() {
+					lib.GetVMExportTransferImage = func() string { return "" }
+
+					_, err := reconciler.ReconcileNormal(exportCtx)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(conditions.GetReason(exportReq, vmopv1.VirtualMachineExportRequestConditionTargetValid)).To(Equal(vmopv1.TargetTransferImageNotConfiguredReason))
+				})
+
+				It("binds the transfer ClusterRole and creates the transfer pod", func() {
+					result, err := reconciler.ReconcileNormal(exportCtx)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(result.RequeueAfter).ToNot(BeZero())
+					Expect(conditions.GetReason(exportReq, vmopv1.VirtualMachineExportRequestConditionTargetValid)).To(Equal(vmopv1.TargetTransferPodNotReadyReason))
+					Expect(exportArgs.Name).To(BeEmpty())
+
+					rb := &rbacv1.RoleBinding{}
+					Expect(ctx.Client.Get(ctx, transferKey, rb)).To(Succeed())
+					Expect(rb.RoleRef.Kind).To(Equal("ClusterRole"))
+					Expect(rb.RoleRef.Name).To(Equal("export-transfer-role"))
+					Expect(rb.Subjects).To(ConsistOf(serviceAccount))
+
+					transferSecret := &corev1.Secret{}
+					Expect(ctx.Client.Get(ctx, transferKey, transferSecret)).To(Succeed())
+					Expect(transferSecret.Data).To(HaveKey(exporttransfer.SecretTokenKey))
+					Expect(transferSecret.Data).To(HaveKey(corev1.TLSCertKey))
+					Expect(transferSecret.Data).To(HaveKey(corev1.TLSPrivateKeyKey))
+
+					pod := &corev1.Pod{}
+					Expect(ctx.Client.Get(ctx, transferKey, pod)).To(Succeed())
+					Expect(pod.Spec.Containers[0].Image).To(Equal("vmoperator-controller"))
+					Expect(pod.Spec.Containers[0].Command).To(ContainElement("--dir=/export/dummy-export"))
+
+					for _, obj := range []client.Object{rb, transferSecret, pod} {
+						Expect(obj.GetOwnerReferences()).To(HaveLen(1))
+						Expect(obj.GetOwnerReferences()[0].Name).To(Equal(exportReq.Name))
+					}
+				})
+
+				It("exports the VM to the PersistentVolumeClaim once the transfer pod is ready", func() {
+					_, err := reconciler.ReconcileNormal(exportCtx)
+					Expect(err).ToNot(HaveOccurred())
+					markTransferPodReady()
+
+					reconcileUntilComplete()
+
+					target, ok := exportArgs.Target.(*virtualmachineexportrequest.PersistentVolumeClaimTarget)
+					Expect(ok).To(BeTrue())
+					Expect(target.ClaimName).To(Equal("dummy-pvc"))
+					Expect(target.Path).To(Equal("dummy-export"))
+					Expect(target.Image).To(Equal("vmoperator-controller"))
+					Expect(exportReq.Status.Ready).To(BeTrue())
+
+					By("deleting the transfer pod, its secret and the role binding", func() {
+						for _, obj := range []client.Object{&corev1.Pod{}, &corev1.Secret{}, &rbacv1.RoleBinding{}} {
+							err := ctx.Client.Get(ctx, transferKey, obj)
+							Expect(apierrors.IsNotFound(err)).To(BeTrue())
+						}
+					})
+				})
+
+				When("a pod with the name of the transfer pod already exists", func() {
+					BeforeEach(func() {
+						initObjects = append(initObjects, &corev1.Pod{
+							ObjectMeta: metav1.ObjectMeta{
+								Name:      transferKey.Name,
+								Namespace: namespace,
+							},
+						})
+					})
+
+					It("does not use the pod", func() {
+						_, err := reconciler.ReconcileNormal(exportCtx)
+						Expect(err).To(HaveOccurred())
+						Expect(err.Error()).To(ContainSubstring("not owned by the export request"))
+						Expect(conditions.GetReason(exportReq, vmopv1.VirtualMachineExportRequestConditionTargetValid)).To(Equal(vmopv1.TargetTransferPodNotReadyReason))
+						Expect(exportArgs.Name).To(BeEmpty())
+					})
+				})
+			})
+		})
+	})
+}
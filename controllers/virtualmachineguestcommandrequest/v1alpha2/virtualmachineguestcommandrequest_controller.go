@@ -0,0 +1,500 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha2
+
+import (
+	goctx "context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	conditions "github.com/vmware-tanzu/vm-operator/pkg/conditions2"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	patch "github.com/vmware-tanzu/vm-operator/pkg/patch2"
+	"github.com/vmware-tanzu/vm-operator/pkg/record"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
+)
+
+const (
+	// requeueDelay is how long to wait before checking again whether a
+	// request can be started or a command in progress has exited.
+	requeueDelay = 10 * time.Second
+
+	// defaultTimeout is how long a command is allowed to run when the request
+	// does not specify a timeout.
+	defaultTimeout = 300 * time.Second
+
+	// maxOutputBytes is the size of the tail of the standard output and the
+	// standard error of a command that is kept in the status.
+	maxOutputBytes = 16 * 1024
+)
+
+// AddToManager adds this package's controller to the provided manager.
+func AddToManager(ctx *context.ControllerManagerContext, mgr manager.Manager) error {
+	var (
+		controlledType     = &vmopv1.VirtualMachineGuestCommandRequest{}
+		controlledTypeName = reflect.TypeOf(controlledType).Elem().Name()
+
+		controllerNameShort = fmt.Sprintf("%s-controller", strings.ToLower(controlledTypeName))
+		controllerNameLong  = fmt.Sprintf("%s/%s/%s", ctx.Namespace, ctx.Name, controllerNameShort)
+	)
+
+	r := NewReconciler(
+		mgr.GetClient(),
+		ctrl.Log.WithName("controllers").WithName(controlledTypeName),
+		record.New(mgr.GetEventRecorderFor(controllerNameLong)),
+		ctx.VMProviderA2,
+	)
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(controlledType).
+		WatchesRawSource(&source.Channel{Source: r.commandDone}, &handler.EnqueueRequestForObject{}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: ctx.MaxConcurrentReconciles}).
+		Complete(r)
+}
+
+func NewReconciler(
+	client client.Client,
+	logger logr.Logger,
+	recorder record.Recorder,
+	vmProvider vmprovider.VirtualMachineProviderInterfaceA2) *Reconciler {
+
+	return &Reconciler{
+		Client:      client,
+		Logger:      logger,
+		Recorder:    recorder,
+		VMProvider:  vmProvider,
+		commands:    map[string]*commandState{},
+		commandDone: make(chan event.GenericEvent, 100),
+	}
+}
+
+// Reconciler reconciles a VirtualMachineGuestCommandRequest object.
+type Reconciler struct {
+	client.Client
+	Logger     logr.Logger
+	Recorder   record.Recorder
+	VMProvider vmprovider.VirtualMachineProviderInterfaceA2
+
+	// commands are the commands in progress, keyed by the namespaced name of
+	// their request. A command runs in its own goroutine since it can take
+	// much longer than a reconcile should.
+	commandsMu sync.Mutex
+	commands   map[string]*commandState
+
+	// commandDone receives the request of a command that exited so it is
+	// reconciled without waiting for the next requeue.
+	commandDone chan event.GenericEvent
+}
+
+// commandState is the state of a command in progress.
+type commandState struct {
+	mu     sync.Mutex
+	done   bool
+	result vmprovider.VirtualMachineGuestCommandResultA2
+	err    error
+	cancel goctx.CancelFunc
+}
+
+func (s *commandState) finish(result vmprovider.VirtualMachineGuestCommandResultA2, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.done = true
+	s.result = result
+	s.err = err
+}
+
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachineguestcommandrequests,verbs=get;list;watch;update;patch;delete
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachineguestcommandrequests/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachines,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+
+func (r *Reconciler) Reconcile(ctx goctx.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
+	cmdReq := &vmopv1.VirtualMachineGuestCommandRequest{}
+	if err := r.Get(ctx, req.NamespacedName, cmdReq); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.cancelCommand(req.NamespacedName.String())
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if !cmdReq.DeletionTimestamp.IsZero() {
+		r.cancelCommand(cmdReq.NamespacedName())
+		return ctrl.Result{}, nil
+	}
+
+	cmdCtx := &context.VirtualMachineGuestCommandRequestContextA2{
+		Context:        ctx,
+		Logger:         ctrl.Log.WithName("VirtualMachineGuestCommandRequest").WithValues("name", cmdReq.NamespacedName()),
+		CommandRequest: cmdReq,
+	}
+
+	patchHelper, err := patch.NewHelper(cmdReq, r.Client)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to init patch helper for %s", cmdCtx.String())
+	}
+	defer func() {
+		if err := patchHelper.Patch(ctx, cmdReq); err != nil {
+			if reterr == nil {
+				reterr = err
+			}
+			cmdCtx.Logger.Error(err, "patch failed")
+		}
+	}()
+
+	return r.ReconcileNormal(cmdCtx)
+}
+
+// ReconcileNormal starts the command once the VM and the inputs are valid,
+// and then records the result of the command once it exits. A request is only
+// processed once: after it is complete, a new request must be created to run
+// the command again. A complete request is deleted once its TTL expires.
+func (r *Reconciler) ReconcileNormal(ctx *context.VirtualMachineGuestCommandRequestContextA2) (ctrl.Result, error) {
+	cmdReq := ctx.CommandRequest
+	if conditions.IsTrue(cmdReq, vmopv1.VirtualMachineGuestCommandRequestConditionComplete) {
+		requeueAfter, err := r.removeCommandRequestFromCluster(ctx)
+		return ctrl.Result{RequeueAfter: requeueAfter}, err
+	}
+
+	ctx.Logger.Info("Reconciling VirtualMachineGuestCommandRequest")
+	defer func() {
+		ctx.Logger.Info("Finished Reconciling VirtualMachineGuestCommandRequest")
+	}()
+
+	if cmdReq.Status.StartTime.IsZero() {
+		cmdReq.Status.StartTime = metav1.Now()
+	}
+
+	state := r.getCommand(cmdReq.NamespacedName())
+	if state == nil && conditions.GetReason(cmdReq, vmopv1.VirtualMachineGuestCommandRequestConditionSucceeded) ==
+		vmopv1.GuestCommandRunningReason {
+		// The command was in progress when the controller restarted. The
+		// command may have had side effects in the guest, so it is not run
+		// again, and its result cannot be fetched.
+		cmdErr := errors.New("command was running when the controller restarted and its result is unknown")
+		conditions.MarkFalse(cmdReq, vmopv1.VirtualMachineGuestCommandRequestConditionSucceeded,
+			vmopv1.GuestCommandInterruptedReason, cmdErr.Error())
+		conditions.MarkTrue(cmdReq, vmopv1.VirtualMachineGuestCommandRequestConditionComplete)
+		cmdReq.Status.CompletionTime = metav1.Now()
+		r.Recorder.EmitEvent(cmdReq, "GuestCommand", cmdErr, false)
+		return ctrl.Result{}, nil
+	}
+
+	if state == nil {
+		// The command is not in progress.
+		if ok, err := r.validateSource(ctx); err != nil {
+			return ctrl.Result{}, err
+		} else if !ok {
+			return ctrl.Result{RequeueAfter: requeueDelay}, nil
+		}
+
+		cmd, err := r.getCommandInputs(ctx)
+		if err != nil {
+			return ctrl.Result{}, err
+		} else if cmd == nil {
+			return ctrl.Result{RequeueAfter: requeueDelay}, nil
+		}
+
+		r.startCommand(ctx, *cmd)
+		conditions.MarkFalse(cmdReq, vmopv1.VirtualMachineGuestCommandRequestConditionSucceeded,
+			vmopv1.GuestCommandRunningReason, "")
+		return ctrl.Result{RequeueAfter: requeueDelay}, nil
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	if !state.done {
+		return ctrl.Result{RequeueAfter: requeueDelay}, nil
+	}
+
+	r.deleteCommand(cmdReq.NamespacedName())
+
+	cmdErr := state.err
+	if cmdErr != nil {
+		ctx.Logger.Error(cmdErr, "Failed to run guest command")
+		conditions.MarkFalse(cmdReq, vmopv1.VirtualMachineGuestCommandRequestConditionSucceeded,
+			vmopv1.GuestOperationsFailedReason, cmdErr.Error())
+	} else {
+		result := state.result
+		cmdReq.Status.ExitCode = &result.ExitCode
+		cmdReq.Status.Stdout = result.Stdout
+		cmdReq.Status.StdoutTruncated = result.StdoutTruncated
+		cmdReq.Status.Stderr = result.Stderr
+		cmdReq.Status.StderrTruncated = result.StderrTruncated
+
+		switch {
+		case result.TimedOut:
+			cmdErr = errors.Errorf("command did not exit within %s", commandTimeout(cmdReq))
+			conditions.MarkFalse(cmdReq, vmopv1.VirtualMachineGuestCommandRequestConditionSucceeded,
+				vmopv1.GuestCommandTimedOutReason, cmdErr.Error())
+		case result.ExitCode != 0:
+			cmdErr = errors.Errorf("command exited with code %d", result.ExitCode)
+			conditions.MarkFalse(cmdReq, vmopv1.VirtualMachineGuestCommandRequestConditionSucceeded,
+				vmopv1.GuestCommandFailedReason, cmdErr.Error())
+		default:
+			conditions.MarkTrue(cmdReq, vmopv1.VirtualMachineGuestCommandRequestConditionSucceeded)
+		}
+	}
+
+	conditions.MarkTrue(cmdReq, vmopv1.VirtualMachineGuestCommandRequestConditionComplete)
+	cmdReq.Status.CompletionTime = metav1.Now()
+	r.Recorder.EmitEvent(cmdReq, "GuestCommand", cmdErr, false)
+
+	return ctrl.Result{}, nil
+}
+
+// removeCommandRequestFromCluster deletes a complete request once its TTL
+// expires, and otherwise returns how long until the TTL expires.
+func (r *Reconciler) removeCommandRequestFromCluster(
+	ctx *context.VirtualMachineGuestCommandRequestContextA2) (time.Duration, error) {
+
+	cmdReq := ctx.CommandRequest
+	ttlSecondsAfterFinished := cmdReq.Spec.TTLSecondsAfterFinished
+	if ttlSecondsAfterFinished == nil {
+		// Skip auto clean up
+		return 0, nil
+	}
+
+	if *ttlSecondsAfterFinished > 0 {
+		completeTime := cmdReq.Status.CompletionTime.Time
+		targetTime := completeTime.Add(time.Duration(*ttlSecondsAfterFinished) * time.Second)
+		if time.Now().Before(targetTime) {
+			return time.Until(targetTime), nil
+		}
+	}
+
+	// TTLSecondsAfterFinished elapsed, delete the resource
+	ctx.Logger.Info("Deleting VirtualMachineGuestCommandRequest")
+	if err := r.Delete(ctx, cmdReq); err != nil {
+		return 0, client.IgnoreNotFound(err)
+	}
+
+	return 0, nil
+}
+
+// validateSource returns true when the VM the command is run in exists, has
+// been created on the underlying infrastructure, is powered on and has VMware
+// Tools running.
+func (r *Reconciler) validateSource(ctx *context.VirtualMachineGuestCommandRequestContextA2) (bool, error) {
+	cmdReq := ctx.CommandRequest
+
+	vm := &vmopv1.VirtualMachine{}
+	key := client.ObjectKey{Namespace: cmdReq.Namespace, Name: cmdReq.Spec.VirtualMachineName}
+	if err := r.Get(ctx, key, vm); err != nil {
+		if apierrors.IsNotFound(err) {
+			conditions.MarkFalse(cmdReq, vmopv1.VirtualMachineGuestCommandRequestConditionSourceValid,
+				vmopv1.SourceVirtualMachineNotExistReason, "")
+			return false, nil
+		}
+		return false, err
+	}
+
+	if vm.Status.UniqueID == "" {
+		conditions.MarkFalse(cmdReq, vmopv1.VirtualMachineGuestCommandRequestConditionSourceValid,
+			vmopv1.SourceVirtualMachineNotCreatedReason, "")
+		return false, nil
+	}
+
+	if vm.Status.PowerState != vmopv1.VirtualMachinePowerStateOn {
+		conditions.MarkFalse(cmdReq, vmopv1.VirtualMachineGuestCommandRequestConditionSourceValid,
+			vmopv1.SourceVirtualMachinePoweredOffReason, "")
+		return false, nil
+	}
+
+	if !conditions.IsTrue(vm, vmopv1.VirtualMachineToolsCondition) {
+		conditions.MarkFalse(cmdReq, vmopv1.VirtualMachineGuestCommandRequestConditionSourceValid,
+			vmopv1.SourceVirtualMachineToolsNotRunningReason, "")
+		return false, nil
+	}
+
+	ctx.VM = vm
+	conditions.MarkTrue(cmdReq, vmopv1.VirtualMachineGuestCommandRequestConditionSourceValid)
+	return true, nil
+}
+
+// getCommandInputs returns the command to run with the guest credentials and
+// the data of the files to upload, or nil when an input cannot be read.
+func (r *Reconciler) getCommandInputs(
+	ctx *context.VirtualMachineGuestCommandRequestContextA2) (*vmprovider.VirtualMachineGuestCommandA2, error) {
+
+	cmdReq := ctx.CommandRequest
+
+	secret := &corev1.Secret{}
+	key := client.ObjectKey{Namespace: cmdReq.Namespace, Name: cmdReq.Spec.CredentialsSecretName}
+	if err := r.Get(ctx, key, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			conditions.MarkFalse(cmdReq, vmopv1.VirtualMachineGuestCommandRequestConditionInputsValid,
+				vmopv1.GuestCredentialsInvalidReason, "Secret %s does not exist", key.Name)
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	for _, k := range []string{
+		vmopv1.VirtualMachineGuestCredentialsUsernameKey,
+		vmopv1.VirtualMachineGuestCredentialsPasswordKey} {
+
+		if len(secret.Data[k]) == 0 {
+			conditions.MarkFalse(cmdReq, vmopv1.VirtualMachineGuestCommandRequestConditionInputsValid,
+				vmopv1.GuestCredentialsInvalidReason, "Secret %s is missing key %s", secret.Name, k)
+			return nil, nil
+		}
+	}
+
+	files := make([]vmprovider.VirtualMachineGuestFileA2, 0, len(cmdReq.Spec.Files))
+	for _, file := range cmdReq.Spec.Files {
+		data, msg, err := r.getFileData(ctx, file)
+		if err != nil {
+			return nil, err
+		} else if msg != "" {
+			conditions.MarkFalse(cmdReq, vmopv1.VirtualMachineGuestCommandRequestConditionInputsValid,
+				vmopv1.GuestCommandFileInvalidReason, "File %s: %s", file.Path, msg)
+			return nil, nil
+		}
+		files = append(files, vmprovider.VirtualMachineGuestFileA2{Path: file.Path, Data: data})
+	}
+
+	env := make([]string, 0, len(cmdReq.Spec.Env))
+	for _, nv := range cmdReq.Spec.Env {
+		env = append(env, nv.Name+"="+nv.Value)
+	}
+
+	conditions.MarkTrue(cmdReq, vmopv1.VirtualMachineGuestCommandRequestConditionInputsValid)
+	return &vmprovider.VirtualMachineGuestCommandA2{
+		Username:       string(secret.Data[vmopv1.VirtualMachineGuestCredentialsUsernameKey]),
+		Password:       string(secret.Data[vmopv1.VirtualMachineGuestCredentialsPasswordKey]),
+		Command:        cmdReq.Spec.Command,
+		Args:           cmdReq.Spec.Args,
+		Env:            env,
+		WorkingDir:     cmdReq.Spec.WorkingDir,
+		Files:          files,
+		Timeout:        commandTimeout(cmdReq),
+		MaxOutputBytes: maxOutputBytes,
+	}, nil
+}
+
+// getFileData returns the data of the file from its ConfigMap or Secret. When
+// the data cannot be read, a message with why is returned instead.
+func (r *Reconciler) getFileData(
+	ctx *context.VirtualMachineGuestCommandRequestContextA2,
+	file vmopv1.VirtualMachineGuestCommandFile) ([]byte, string, error) {
+
+	namespace := ctx.CommandRequest.Namespace
+
+	switch {
+	case file.ConfigMap != nil:
+		cm := &corev1.ConfigMap{}
+		if err := r.Get(ctx, client.ObjectKey{Namespace: namespace, Name: file.ConfigMap.Name}, cm); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, fmt.Sprintf("ConfigMap %s does not exist", file.ConfigMap.Name), nil
+			}
+			return nil, "", err
+		}
+		if data, ok := cm.Data[file.ConfigMap.Key]; ok {
+			return []byte(data), "", nil
+		}
+		if data, ok := cm.BinaryData[file.ConfigMap.Key]; ok {
+			return data, "", nil
+		}
+		return nil, fmt.Sprintf("ConfigMap %s is missing key %s", cm.Name, file.ConfigMap.Key), nil
+
+	case file.Secret != nil:
+		secret := &corev1.Secret{}
+		if err := r.Get(ctx, client.ObjectKey{Namespace: namespace, Name: file.Secret.Name}, secret); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, fmt.Sprintf("Secret %s does not exist", file.Secret.Name), nil
+			}
+			return nil, "", err
+		}
+		if data, ok := secret.Data[file.Secret.Key]; ok {
+			return data, "", nil
+		}
+		return nil, fmt.Sprintf("Secret %s is missing key %s", secret.Name, file.Secret.Key), nil
+	}
+
+	// The webhook requires exactly one source, so this should not happen.
+	return nil, "", errors.Errorf("file %s has no source", file.Path)
+}
+
+func commandTimeout(cmdReq *vmopv1.VirtualMachineGuestCommandRequest) time.Duration {
+	if cmdReq.Spec.TimeoutSeconds > 0 {
+		return time.Duration(cmdReq.Spec.TimeoutSeconds) * time.Second
+	}
+	return defaultTimeout
+}
+
+// startCommand starts running the command in a new goroutine.
+func (r *Reconciler) startCommand(
+	ctx *context.VirtualMachineGuestCommandRequestContextA2,
+	cmd vmprovider.VirtualMachineGuestCommandA2) {
+
+	cmdReq := ctx.CommandRequest
+	vm := ctx.VM.DeepCopy()
+
+	cmdCtx, cancel := goctx.WithCancel(goctx.Background())
+	state := &commandState{cancel: cancel}
+
+	r.commandsMu.Lock()
+	r.commands[cmdReq.NamespacedName()] = state
+	r.commandsMu.Unlock()
+
+	obj := &vmopv1.VirtualMachineGuestCommandRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: cmdReq.Namespace,
+			Name:      cmdReq.Name,
+		},
+	}
+	go func() {
+		defer cancel()
+
+		result, err := r.VMProvider.RunVirtualMachineGuestCommand(cmdCtx, vm, cmd)
+		state.finish(result, err)
+
+		select {
+		case r.commandDone <- event.GenericEvent{Object: obj}:
+		default:
+			// The request is reconciled on its next requeue instead.
+		}
+	}()
+}
+
+func (r *Reconciler) getCommand(key string) *commandState {
+	r.commandsMu.Lock()
+	defer r.commandsMu.Unlock()
+	return r.commands[key]
+}
+
+func (r *Reconciler) deleteCommand(key string) {
+	r.commandsMu.Lock()
+	defer r.commandsMu.Unlock()
+	delete(r.commands, key)
+}
+
+// cancelCommand cancels the command of a request that was deleted.
+func (r *Reconciler) cancelCommand(key string) {
+	r.commandsMu.Lock()
+	defer r.commandsMu.Unlock()
+	if state, ok := r.commands[key]; ok {
+		state.cancel()
+		delete(r.commands, key)
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha2_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	conditions "github.com/vmware-tanzu/vm-operator/pkg/conditions2"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func intgTests() {
+	Describe("Invoking VirtualMachineGuestCommandRequest controller tests", intgTestsReconcile)
+}
+
+func intgTestsReconcile() {
+	var (
+		ctx    *builder.IntegrationTestContext
+		cmdReq *vmopv1.VirtualMachineGuestCommandRequest
+		vm     *vmopv1.VirtualMachine
+	)
+
+	getCommandRequest := func(ctx *builder.IntegrationTestContext, objKey client.ObjectKey) *vmopv1.VirtualMachineGuestCommandRequest {
+		cmdReq := &vmopv1.VirtualMachineGuestCommandRequest{}
+		if err := ctx.Client.Get(ctx, objKey, cmdReq); err != nil {
+			return nil
+		}
+		return cmdReq
+	}
+
+	BeforeEach(func() {
+		ctx = suite.NewIntegrationTestContext()
+		vm = builder.DummyBasicVirtualMachineA2("dummy-vm", ctx.Namespace)
+		cmdReq = builder.DummyVirtualMachineGuestCommandRequest(ctx.Namespace, "dummy-cmd", vm.Name)
+	})
+
+	AfterEach(func() {
+		ctx.AfterEach()
+		ctx = nil
+		fakeVMProvider.Reset()
+	})
+
+	Context("Reconcile", func() {
+		BeforeEach(func() {
+			fakeVMProvider.Lock()
+			fakeVMProvider.RunVirtualMachineGuestCommandFn = func(
+				_ context.Context,
+				_ *vmopv1.VirtualMachine,
+				_ vmprovider.VirtualMachineGuestCommandA2) (vmprovider.VirtualMachineGuestCommandResultA2, error) {
+
+				return vmprovider.VirtualMachineGuestCommandResultA2{
+					ExitCode: 0,
+					Stdout:   "hello\n",
+				}, nil
+			}
+			fakeVMProvider.Unlock()
+
+			Expect(ctx.Client.Create(ctx, vm)).To(Succeed())
+			vm.Status.UniqueID = "vm-42"
+			vm.Status.PowerState = vmopv1.VirtualMachinePowerStateOn
+			conditions.MarkTrue(vm, vmopv1.VirtualMachineToolsCondition)
+			Expect(ctx.Client.Status().Update(ctx, vm)).To(Succeed())
+
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      cmdReq.Spec.CredentialsSecretName,
+					Namespace: ctx.Namespace,
+				},
+				StringData: map[string]string{
+					vmopv1.VirtualMachineGuestCredentialsUsernameKey: "root",
+					vmopv1.VirtualMachineGuestCredentialsPasswordKey: "password",
+				},
+			}
+			Expect(ctx.Client.Create(ctx, secret)).To(Succeed())
+		})
+
+		It("runs the command", func() {
+			Expect(ctx.Client.Create(ctx, cmdReq)).To(Succeed())
+			objKey := client.ObjectKeyFromObject(cmdReq)
+
+			Eventually(func() bool {
+				if obj := getCommandRequest(ctx, objKey); obj != nil {
+					return conditions.IsTrue(obj, vmopv1.VirtualMachineGuestCommandRequestConditionComplete)
+				}
+				return false
+			}).Should(BeTrue())
+
+			obj := getCommandRequest(ctx, objKey)
+			Expect(obj.Status.ExitCode).ToNot(BeNil())
+			Expect(*obj.Status.ExitCode).To(BeEquivalentTo(0))
+			Expect(obj.Status.Stdout).To(Equal("hello\n"))
+			Expect(conditions.IsTrue(obj, vmopv1.VirtualMachineGuestCommandRequestConditionSucceeded)).To(BeTrue())
+		})
+
+		It("deletes the request once the TTL expires", func() {
+			cmdReq.Spec.TTLSecondsAfterFinished = new(int64)
+			Expect(ctx.Client.Create(ctx, cmdReq)).To(Succeed())
+			objKey := client.ObjectKeyFromObject(cmdReq)
+
+			Eventually(func() bool {
+				return getCommandRequest(ctx, objKey) == nil
+			}).Should(BeTrue())
+		})
+	})
+}
@@ -0,0 +1,374 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha2_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	"github.com/vmware-tanzu/vm-operator/api/v1alpha2/common"
+	virtualmachineguestcommandrequest "github.com/vmware-tanzu/vm-operator/controllers/virtualmachineguestcommandrequest/v1alpha2"
+	conditions "github.com/vmware-tanzu/vm-operator/pkg/conditions2"
+	vmopContext "github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
+	providerfake "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/fake"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func unitTests() {
+	Describe("Invoking VirtualMachineGuestCommandRequest Reconcile", unitTestsReconcile)
+}
+
+func unitTestsReconcile() {
+	const (
+		namespace = "dummy-ns"
+	)
+
+	var (
+		initObjects []client.Object
+		ctx         *builder.UnitTestContextForController
+
+		reconciler *virtualmachineguestcommandrequest.Reconciler
+		cmdCtx     *vmopContext.VirtualMachineGuestCommandRequestContextA2
+		cmdReq     *vmopv1.VirtualMachineGuestCommandRequest
+		vm         *vmopv1.VirtualMachine
+		secret     *corev1.Secret
+
+		cmdArgs   vmprovider.VirtualMachineGuestCommandA2
+		cmdResult vmprovider.VirtualMachineGuestCommandResultA2
+		cmdErr    error
+	)
+
+	reconcileUntilComplete := func() {
+		Eventually(func() bool {
+			_, err := reconciler.ReconcileNormal(cmdCtx)
+			Expect(err).ToNot(HaveOccurred())
+			return conditions.IsTrue(cmdReq, vmopv1.VirtualMachineGuestCommandRequestConditionComplete)
+		}).Should(BeTrue())
+	}
+
+	BeforeEach(func() {
+		vm = builder.DummyBasicVirtualMachineA2("dummy-vm", namespace)
+		vm.Status.UniqueID = "vm-42"
+		vm.Status.PowerState = vmopv1.VirtualMachinePowerStateOn
+		conditions.MarkTrue(vm, vmopv1.VirtualMachineToolsCondition)
+
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "dummy-credentials",
+				Namespace: namespace,
+			},
+			Data: map[string][]byte{
+				vmopv1.VirtualMachineGuestCredentialsUsernameKey: []byte("root"),
+				vmopv1.VirtualMachineGuestCredentialsPasswordKey: []byte("password"),
+			},
+		}
+
+		cmdReq = builder.DummyVirtualMachineGuestCommandRequest(namespace, "dummy-cmd", vm.Name)
+		cmdReq.Spec.Env = []common.NameValuePair{{Name: "FOO", Value: "bar"}}
+		initObjects = []client.Object{vm, secret}
+		cmdResult = vmprovider.VirtualMachineGuestCommandResultA2{
+			ExitCode: 0,
+			Stdout:   "hello\n",
+		}
+		cmdErr = nil
+	})
+
+	JustBeforeEach(func() {
+		ctx = suite.NewUnitTestContextForController(initObjects...)
+		reconciler = virtualmachineguestcommandrequest.NewReconciler(
+			ctx.Client,
+			ctx.Logger,
+			ctx.Recorder,
+			ctx.VMProviderA2,
+		)
+		fakeVMProvider = ctx.VMProviderA2.(*providerfake.VMProviderA2)
+		fakeVMProvider.RunVirtualMachineGuestCommandFn = func(
+			_ context.Context,
+			_ *vmopv1.VirtualMachine,
+			cmd vmprovider.VirtualMachineGuestCommandA2) (vmprovider.VirtualMachineGuestCommandResultA2, error) {
+
+			cmdArgs = cmd
+			return cmdResult, cmdErr
+		}
+
+		cmdCtx = &vmopContext.VirtualMachineGuestCommandRequestContextA2{
+			Context:        ctx,
+			Logger:         ctx.Logger.WithName(cmdReq.Name),
+			CommandRequest: cmdReq,
+		}
+	})
+
+	AfterEach(func() {
+		ctx.AfterEach()
+		ctx = nil
+		initObjects = nil
+		reconciler = nil
+	})
+
+	Context("ReconcileNormal", func() {
+
+		It("runs the command", func() {
+			reconcileUntilComplete()
+
+			Expect(cmdArgs.Username).To(Equal("root"))
+			Expect(cmdArgs.Password).To(Equal("password"))
+			Expect(cmdArgs.Command).To(Equal("/bin/echo"))
+			Expect(cmdArgs.Args).To(Equal([]string{"hello"}))
+			Expect(cmdArgs.Env).To(Equal([]string{"FOO=bar"}))
+			Expect(cmdArgs.Timeout).To(Equal(60 * time.Second))
+			Expect(cmdArgs.MaxOutputBytes).To(BeEquivalentTo(16 * 1024))
+
+			Expect(cmdReq.Status.ExitCode).ToNot(BeNil())
+			Expect(*cmdReq.Status.ExitCode).To(BeEquivalentTo(0))
+			Expect(cmdReq.Status.Stdout).To(Equal("hello\n"))
+			Expect(cmdReq.Status.StartTime.IsZero()).To(BeFalse())
+			Expect(cmdReq.Status.CompletionTime.IsZero()).To(BeFalse())
+			Expect(conditions.IsTrue(cmdReq, vmopv1.VirtualMachineGuestCommandRequestConditionSourceValid)).To(BeTrue())
+			Expect(conditions.IsTrue(cmdReq, vmopv1.VirtualMachineGuestCommandRequestConditionInputsValid)).To(BeTrue())
+			Expect(conditions.IsTrue(cmdReq, vmopv1.VirtualMachineGuestCommandRequestConditionSucceeded)).To(BeTrue())
+		})
+
+		When("the command exits with a non-zero exit code", func() {
+			BeforeEach(func() {
+				cmdResult = vmprovider.VirtualMachineGuestCommandResultA2{
+					ExitCode:        2,
+					Stderr:          "no such file",
+					StderrTruncated: true,
+				}
+			})
+
+			It("completes the request with a failure", func() {
+				reconcileUntilComplete()
+
+				Expect(*cmdReq.Status.ExitCode).To(BeEquivalentTo(2))
+				Expect(cmdReq.Status.Stderr).To(Equal("no such file"))
+				Expect(cmdReq.Status.StderrTruncated).To(BeTrue())
+				Expect(conditions.IsFalse(cmdReq, vmopv1.VirtualMachineGuestCommandRequestConditionSucceeded)).To(BeTrue())
+				Expect(conditions.GetReason(cmdReq, vmopv1.VirtualMachineGuestCommandRequestConditionSucceeded)).To(Equal(vmopv1.GuestCommandFailedReason))
+			})
+		})
+
+		When("the command times out", func() {
+			BeforeEach(func() {
+				cmdResult = vmprovider.VirtualMachineGuestCommandResultA2{TimedOut: true}
+			})
+
+			It("completes the request with a failure", func() {
+				reconcileUntilComplete()
+
+				Expect(conditions.IsFalse(cmdReq, vmopv1.VirtualMachineGuestCommandRequestConditionSucceeded)).To(BeTrue())
+				Expect(conditions.GetReason(cmdReq, vmopv1.VirtualMachineGuestCommandRequestConditionSucceeded)).To(Equal(vmopv1.GuestCommandTimedOutReason))
+			})
+		})
+
+		When("the guest operations fail", func() {
+			BeforeEach(func() {
+				cmdErr = errors.New("fake")
+			})
+
+			It("completes the request with a failure", func() {
+				reconcileUntilComplete()
+
+				Expect(cmdReq.Status.ExitCode).To(BeNil())
+				Expect(conditions.IsFalse(cmdReq, vmopv1.VirtualMachineGuestCommandRequestConditionSucceeded)).To(BeTrue())
+				Expect(conditions.GetReason(cmdReq, vmopv1.VirtualMachineGuestCommandRequestConditionSucceeded)).To(Equal(vmopv1.GuestOperationsFailedReason))
+				Expect(conditions.GetMessage(cmdReq, vmopv1.VirtualMachineGuestCommandRequestConditionSucceeded)).To(Equal("fake"))
+			})
+		})
+
+		When("the command was running when the controller restarted", func() {
+			BeforeEach(func() {
+				cmdReq.Status.StartTime = metav1.Now()
+				conditions.MarkFalse(cmdReq, vmopv1.VirtualMachineGuestCommandRequestConditionSucceeded,
+					vmopv1.GuestCommandRunningReason, "")
+			})
+
+			It("completes the request without running the command again", func() {
+				called := false
+				fakeVMProvider.RunVirtualMachineGuestCommandFn = func(
+					_ context.Context,
+					_ *vmopv1.VirtualMachine,
+					_ vmprovider.VirtualMachineGuestCommandA2) (vmprovider.VirtualMachineGuestCommandResultA2, error) {
+
+					called = true
+					return cmdResult, cmdErr
+				}
+
+				result, err := reconciler.ReconcileNormal(cmdCtx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.RequeueAfter).To(BeZero())
+				Expect(called).To(BeFalse())
+
+				Expect(cmdReq.Status.ExitCode).To(BeNil())
+				Expect(cmdReq.Status.CompletionTime.IsZero()).To(BeFalse())
+				Expect(conditions.IsTrue(cmdReq, vmopv1.VirtualMachineGuestCommandRequestConditionComplete)).To(BeTrue())
+				Expect(conditions.IsFalse(cmdReq, vmopv1.VirtualMachineGuestCommandRequestConditionSucceeded)).To(BeTrue())
+				Expect(conditions.GetReason(cmdReq, vmopv1.VirtualMachineGuestCommandRequestConditionSucceeded)).To(Equal(vmopv1.GuestCommandInterruptedReason))
+			})
+		})
+
+		When("the request uploads files", func() {
+			BeforeEach(func() {
+				cmdReq.Spec.Files = []vmopv1.VirtualMachineGuestCommandFile{
+					{
+						Path:      "/tmp/script.sh",
+						ConfigMap: &vmopv1.VirtualMachineGuestCommandFileKeySelector{Name: "dummy-cm", Key: "script.sh"},
+					},
+					{
+						Path:   "/tmp/key.pem",
+						Secret: &vmopv1.VirtualMachineGuestCommandFileKeySelector{Name: "dummy-credentials", Key: "key.pem"},
+					},
+				}
+				secret.Data["key.pem"] = []byte("secret-key")
+				initObjects = append(initObjects, &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "dummy-cm",
+						Namespace: namespace,
+					},
+					Data: map[string]string{
+						"script.sh": "echo hello",
+					},
+				})
+			})
+
+			It("passes the data of the files", func() {
+				reconcileUntilComplete()
+
+				Expect(cmdArgs.Files).To(Equal([]vmprovider.VirtualMachineGuestFileA2{
+					{Path: "/tmp/script.sh", Data: []byte("echo hello")},
+					{Path: "/tmp/key.pem", Data: []byte("secret-key")},
+				}))
+			})
+
+			When("the ConfigMap is missing the key", func() {
+				BeforeEach(func() {
+					cmdReq.Spec.Files[0].ConfigMap.Key = "other.sh"
+				})
+
+				It("marks the inputs as not valid", func() {
+					_, err := reconciler.ReconcileNormal(cmdCtx)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(conditions.IsFalse(cmdReq, vmopv1.VirtualMachineGuestCommandRequestConditionInputsValid)).To(BeTrue())
+					Expect(conditions.GetReason(cmdReq, vmopv1.VirtualMachineGuestCommandRequestConditionInputsValid)).To(Equal(vmopv1.GuestCommandFileInvalidReason))
+				})
+			})
+		})
+
+		When("the credentials secret is missing a key", func() {
+			BeforeEach(func() {
+				delete(secret.Data, vmopv1.VirtualMachineGuestCredentialsPasswordKey)
+			})
+
+			It("marks the inputs as not valid", func() {
+				_, err := reconciler.ReconcileNormal(cmdCtx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(conditions.IsFalse(cmdReq, vmopv1.VirtualMachineGuestCommandRequestConditionInputsValid)).To(BeTrue())
+				Expect(conditions.GetReason(cmdReq, vmopv1.VirtualMachineGuestCommandRequestConditionInputsValid)).To(Equal(vmopv1.GuestCredentialsInvalidReason))
+				Expect(conditions.Has(cmdReq, vmopv1.VirtualMachineGuestCommandRequestConditionSucceeded)).To(BeFalse())
+			})
+		})
+
+		assertSourceNotValid := func(reason string) {
+			result, err := reconciler.ReconcileNormal(cmdCtx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.RequeueAfter).ToNot(BeZero())
+			Expect(conditions.IsFalse(cmdReq, vmopv1.VirtualMachineGuestCommandRequestConditionSourceValid)).To(BeTrue())
+			Expect(conditions.GetReason(cmdReq, vmopv1.VirtualMachineGuestCommandRequestConditionSourceValid)).To(Equal(reason))
+			Expect(conditions.Has(cmdReq, vmopv1.VirtualMachineGuestCommandRequestConditionSucceeded)).To(BeFalse())
+		}
+
+		When("the VM does not exist", func() {
+			BeforeEach(func() {
+				initObjects = []client.Object{secret}
+			})
+
+			It("marks the source as not valid", func() {
+				assertSourceNotValid(vmopv1.SourceVirtualMachineNotExistReason)
+			})
+		})
+
+		When("the VM is not created", func() {
+			BeforeEach(func() {
+				vm.Status.UniqueID = ""
+			})
+
+			It("marks the source as not valid", func() {
+				assertSourceNotValid(vmopv1.SourceVirtualMachineNotCreatedReason)
+			})
+		})
+
+		When("the VM is powered off", func() {
+			BeforeEach(func() {
+				vm.Status.PowerState = vmopv1.VirtualMachinePowerStateOff
+			})
+
+			It("marks the source as not valid", func() {
+				assertSourceNotValid(vmopv1.SourceVirtualMachinePoweredOffReason)
+			})
+		})
+
+		When("VMware Tools is not running", func() {
+			BeforeEach(func() {
+				conditions.MarkFalse(vm, vmopv1.VirtualMachineToolsCondition, vmopv1.VirtualMachineToolsNotRunningReason, "")
+			})
+
+			It("marks the source as not valid", func() {
+				assertSourceNotValid(vmopv1.SourceVirtualMachineToolsNotRunningReason)
+			})
+		})
+
+		When("the request is complete", func() {
+			BeforeEach(func() {
+				conditions.MarkTrue(cmdReq, vmopv1.VirtualMachineGuestCommandRequestConditionComplete)
+				cmdReq.Status.CompletionTime = metav1.Now()
+				initObjects = append(initObjects, cmdReq)
+			})
+
+			It("does not run the command", func() {
+				result, err := reconciler.ReconcileNormal(cmdCtx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.RequeueAfter).To(BeZero())
+				Expect(cmdReq.Status.StartTime.IsZero()).To(BeTrue())
+			})
+
+			When("the TTL has not expired", func() {
+				BeforeEach(func() {
+					cmdReq.Spec.TTLSecondsAfterFinished = new(int64)
+					*cmdReq.Spec.TTLSecondsAfterFinished = 3600
+				})
+
+				It("requeues until the TTL expires", func() {
+					result, err := reconciler.ReconcileNormal(cmdCtx)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(result.RequeueAfter).To(BeNumerically("~", time.Hour, time.Minute))
+					Expect(ctx.Client.Get(ctx, client.ObjectKeyFromObject(cmdReq), &vmopv1.VirtualMachineGuestCommandRequest{})).To(Succeed())
+				})
+			})
+
+			When("the TTL has expired", func() {
+				BeforeEach(func() {
+					cmdReq.Spec.TTLSecondsAfterFinished = new(int64)
+				})
+
+				It("deletes the request", func() {
+					_, err := reconciler.ReconcileNormal(cmdCtx)
+					Expect(err).ToNot(HaveOccurred())
+					err = ctx.Client.Get(ctx, client.ObjectKeyFromObject(cmdReq), &vmopv1.VirtualMachineGuestCommandRequest{})
+					Expect(apierrors.IsNotFound(err)).To(BeTrue())
+				})
+			})
+		})
+	})
+}
@@ -0,0 +1,626 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha2
+
+import (
+	goctx "context"
+	"fmt"
+	"net/url"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/vapi/library"
+
+	imgregv1a1 "github.com/vmware-tanzu/image-registry-operator-api/api/v1alpha1"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	conditions "github.com/vmware-tanzu/vm-operator/pkg/conditions2"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	patch "github.com/vmware-tanzu/vm-operator/pkg/patch2"
+	"github.com/vmware-tanzu/vm-operator/pkg/record"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
+)
+
+const (
+	finalizerName = "virtualmachineimageimportrequest.vmoperator.vmware.com"
+
+	// ItemDescriptionRegexString is used to filter the import request UID
+	// from the content library item description.
+	ItemDescriptionRegexString = "virtualmachineimageimportrequest\\.vmoperator\\.vmware\\.com: ([a-z0-9-]*)"
+)
+
+var (
+	itemDescriptionReg = regexp.MustCompile(ItemDescriptionRegexString)
+)
+
+// AddToManager adds this package's controller to the provided manager.
+func AddToManager(ctx *context.ControllerManagerContext, mgr manager.Manager) error {
+	var (
+		controlledType     = &vmopv1.VirtualMachineImageImportRequest{}
+		controlledTypeName = reflect.TypeOf(controlledType).Elem().Name()
+
+		controllerNameShort = fmt.Sprintf("%s-controller", strings.ToLower(controlledTypeName))
+		controllerNameLong  = fmt.Sprintf("%s/%s/%s", ctx.Namespace, ctx.Name, controllerNameShort)
+	)
+
+	r := NewReconciler(
+		mgr.GetClient(),
+		ctrl.Log.WithName("controllers").WithName(controlledTypeName),
+		record.New(mgr.GetEventRecorderFor(controllerNameLong)),
+		ctx.VMProviderA2,
+	)
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(controlledType).
+		WithOptions(controller.Options{MaxConcurrentReconciles: ctx.MaxConcurrentReconciles}).
+		Watches(&vmopv1.VirtualMachineImage{},
+			handler.EnqueueRequestsFromMapFunc(vmiToImportRequestMapperFn(ctx, r.Client))).
+		Complete(r)
+}
+
+// vmiToImportRequestMapperFn returns a mapper function that can be used to
+// queue reconcile requests for the VirtualMachineImageImportRequests in
+// response to an event on the VirtualMachineImage resource realized from the
+// imported content library item.
+func vmiToImportRequestMapperFn(ctx *context.ControllerManagerContext, c client.Client) func(_ goctx.Context, o client.Object) []reconcile.Request {
+	return func(_ goctx.Context, o client.Object) []reconcile.Request {
+		vmi := o.(*vmopv1.VirtualMachineImage)
+		if vmi.Status.ProviderItemID == "" {
+			return nil
+		}
+
+		logger := ctx.Logger.WithValues("name", vmi.Name, "namespace", vmi.Namespace)
+		logger.V(4).Info("Reconciling all VirtualMachineImageImportRequests importing the item of this VirtualMachineImage")
+
+		importReqList := &vmopv1.VirtualMachineImageImportRequestList{}
+		if err := c.List(ctx, importReqList, client.InNamespace(vmi.Namespace)); err != nil {
+			logger.Error(err, "Failed to list VirtualMachineImageImportRequests for reconciliation due to VirtualMachineImage watch")
+			return nil
+		}
+
+		var reconcileRequests []reconcile.Request
+		for _, importReq := range importReqList.Items {
+			if importReq.Status.ItemID == vmi.Status.ProviderItemID {
+				key := client.ObjectKey{Namespace: importReq.Namespace, Name: importReq.Name}
+				reconcileRequests = append(reconcileRequests, reconcile.Request{NamespacedName: key})
+			}
+		}
+
+		logger.V(4).Info("Returning VirtualMachineImageImportRequest reconcile requests due to VirtualMachineImage watch",
+			"requests", reconcileRequests)
+		return reconcileRequests
+	}
+}
+
+func NewReconciler(
+	client client.Client,
+	logger logr.Logger,
+	recorder record.Recorder,
+	vmProvider vmprovider.VirtualMachineProviderInterfaceA2) *Reconciler {
+
+	return &Reconciler{
+		Client:     client,
+		Logger:     logger,
+		Recorder:   recorder,
+		VMProvider: vmProvider,
+	}
+}
+
+// Reconciler reconciles a VirtualMachineImageImportRequest object.
+type Reconciler struct {
+	client.Client
+	Logger     logr.Logger
+	Recorder   record.Recorder
+	VMProvider vmprovider.VirtualMachineProviderInterfaceA2
+}
+
+func requeueResult(ctx *context.VirtualMachineImageImportRequestContextA2) ctrl.Result {
+	importReq := ctx.ImportRequest
+
+	// No need to requeue to trigger another reconcile if the target item
+	// already exists, or the image failed to upload.
+	if isFailed(importReq) {
+		return ctrl.Result{}
+	}
+
+	// While the image is being pulled into the content library, or the
+	// VirtualMachineImage is being realized from the item, requeue after a
+	// short wait time to update the progress.
+	if conditions.GetReason(importReq, vmopv1.VirtualMachineImageImportRequestConditionUploaded) == vmopv1.UploadingReason ||
+		conditions.IsTrue(importReq, vmopv1.VirtualMachineImageImportRequestConditionUploaded) {
+		return ctrl.Result{RequeueAfter: 10 * time.Second}
+	}
+
+	return ctrl.Result{RequeueAfter: 60 * time.Second}
+}
+
+// isFailed returns true if the import request failed and will not be retried.
+func isFailed(importReq *vmopv1.VirtualMachineImageImportRequest) bool {
+	if conditions.GetReason(importReq, vmopv1.VirtualMachineImageImportRequestConditionTargetValid) == vmopv1.TargetItemAlreadyExistsReason {
+		return true
+	}
+
+	switch conditions.GetReason(importReq, vmopv1.VirtualMachineImageImportRequestConditionUploaded) {
+	case vmopv1.UploadFailureReason, vmopv1.UploadChecksumMismatchReason, vmopv1.UploadChecksumUnverifiedReason:
+		return true
+	}
+
+	return false
+}
+
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachineimageimportrequests,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachineimageimportrequests/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachineimages,verbs=get;list;watch
+// +kubebuilder:rbac:groups=imageregistry.vmware.com,resources=contentlibraries,verbs=get;list;watch
+
+func (r *Reconciler) Reconcile(ctx goctx.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
+	importReq := &vmopv1.VirtualMachineImageImportRequest{}
+	if err := r.Get(ctx, req.NamespacedName, importReq); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	importCtx := &context.VirtualMachineImageImportRequestContextA2{
+		Context:       ctx,
+		Logger:        ctrl.Log.WithName("VirtualMachineImageImportRequest").WithValues("name", req.NamespacedName),
+		ImportRequest: importReq,
+	}
+
+	patchHelper, err := patch.NewHelper(importReq, r.Client)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to init patch helper for %s", importReq.NamespacedName())
+	}
+	defer func() {
+		if err := patchHelper.Patch(ctx, importReq); err != nil {
+			if reterr == nil {
+				reterr = err
+			}
+			importCtx.Logger.Error(err, "patch failed")
+		}
+	}()
+
+	if !importReq.DeletionTimestamp.IsZero() {
+		return r.ReconcileDelete(importCtx)
+	}
+
+	return r.ReconcileNormal(importCtx)
+}
+
+func (r *Reconciler) ReconcileNormal(ctx *context.VirtualMachineImageImportRequestContextA2) (ctrl.Result, error) {
+	ctx.Logger.Info("Reconciling VirtualMachineImageImportRequest")
+	importReq := ctx.ImportRequest
+
+	if !controllerutil.ContainsFinalizer(importReq, finalizerName) {
+		// The finalizer must be present before proceeding in order to ensure
+		// that a partially imported item is cleaned up. Return immediately
+		// after here to let the patcher helper update the object, and then
+		// we'll proceed on the next reconciliation.
+		controllerutil.AddFinalizer(importReq, finalizerName)
+		return ctrl.Result{}, nil
+	}
+
+	if conditions.IsTrue(importReq, vmopv1.VirtualMachineImageImportRequestConditionComplete) || isFailed(importReq) {
+		return ctrl.Result{}, nil
+	}
+
+	if importReq.Status.StartTime.IsZero() {
+		importReq.Status.StartTime = metav1.Now()
+	}
+
+	if importReq.Status.ItemID == "" {
+		if err := r.checkIsTargetValid(ctx); err != nil {
+			ctx.Logger.Error(err, "failed to check if target is valid")
+			return ctrl.Result{}, err
+		}
+
+		if !conditions.IsTrue(importReq, vmopv1.VirtualMachineImageImportRequestConditionTargetValid) {
+			return requeueResult(ctx), nil
+		}
+
+		if err := r.importItem(ctx); err != nil {
+			ctx.Logger.Error(err, "failed to import item")
+			return ctrl.Result{}, err
+		}
+
+		return requeueResult(ctx), nil
+	}
+
+	if err := r.checkIsUploaded(ctx); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := r.checkIsImageAvailable(ctx); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if r.checkIsComplete(ctx) {
+		r.Recorder.EmitEvent(importReq, "Import", nil, false)
+		return ctrl.Result{}, nil
+	}
+
+	return requeueResult(ctx), nil
+}
+
+func (r *Reconciler) ReconcileDelete(ctx *context.VirtualMachineImageImportRequestContextA2) (ctrl.Result, error) {
+	importReq := ctx.ImportRequest
+
+	if controllerutil.ContainsFinalizer(importReq, finalizerName) {
+		// The imported item is the image once the import has completed, so
+		// only an item that has not been fully imported is deleted.
+		if importReq.Status.ItemID != "" &&
+			!conditions.IsTrue(importReq, vmopv1.VirtualMachineImageImportRequestConditionUploaded) {
+
+			if err := r.VMProvider.DeleteContentLibraryItem(ctx, importReq.Status.ItemID); err != nil {
+				ctx.Logger.Error(err, "failed to delete partially imported item", "itemID", importReq.Status.ItemID)
+				return ctrl.Result{}, err
+			}
+		}
+		controllerutil.RemoveFinalizer(importReq, finalizerName)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// getTargetItemName returns the name of the content library item, which
+// defaults to the name of the import request.
+func getTargetItemName(importReq *vmopv1.VirtualMachineImageImportRequest) string {
+	if name := importReq.Spec.Target.Item.Name; name != "" {
+		return name
+	}
+	return importReq.Name
+}
+
+// getSourceFileName returns the name of the file pulled into the content
+// library item, which is the last element of the source URL's path.
+func getSourceFileName(importReq *vmopv1.VirtualMachineImageImportRequest) string {
+	u, err := url.Parse(importReq.Spec.Source.URL)
+	if err != nil {
+		return ""
+	}
+	return path.Base(u.Path)
+}
+
+// checkIsTargetValid checks if the target item is valid. It is invalid if the
+// content library doesn't exist, is not writable or ready, or an item with
+// the same name in the content library exists.
+func (r *Reconciler) checkIsTargetValid(ctx *context.VirtualMachineImageImportRequestContextA2) error {
+	importReq := ctx.ImportRequest
+	contentLibrary := &imgregv1a1.ContentLibrary{}
+	targetItemName := getTargetItemName(importReq)
+	objKey := client.ObjectKey{Name: importReq.Spec.Target.Location.Name, Namespace: importReq.Namespace}
+	if err := r.Get(ctx, objKey, contentLibrary); err != nil {
+		ctx.Logger.Error(err, "failed to get ContentLibrary", "cl", objKey)
+		if apiErrors.IsNotFound(err) {
+			conditions.MarkFalse(importReq,
+				vmopv1.VirtualMachineImageImportRequestConditionTargetValid,
+				vmopv1.TargetContentLibraryNotExistReason,
+				err.Error())
+		}
+		return err
+	}
+
+	if !contentLibrary.Spec.Writable {
+		err := fmt.Errorf("target location %s is not writable", contentLibrary.Status.Name)
+		conditions.MarkFalse(importReq,
+			vmopv1.VirtualMachineImageImportRequestConditionTargetValid,
+			vmopv1.TargetContentLibraryNotWritableReason,
+			err.Error())
+		return err
+	}
+
+	isReady := false
+	for _, condition := range contentLibrary.Status.Conditions {
+		if condition.Type == imgregv1a1.ReadyCondition {
+			isReady = condition.Status == corev1.ConditionTrue
+			break
+		}
+	}
+
+	if !isReady {
+		err := fmt.Errorf("target location %s is not ready", contentLibrary.Status.Name)
+		conditions.MarkFalse(importReq,
+			vmopv1.VirtualMachineImageImportRequestConditionTargetValid,
+			vmopv1.TargetContentLibraryNotReadyReason,
+			err.Error())
+		return err
+	}
+
+	ctx.ContentLibrary = contentLibrary
+	item, err := r.VMProvider.GetItemFromLibraryByName(ctx, string(contentLibrary.Spec.UUID), targetItemName)
+	if err != nil {
+		ctx.Logger.Error(err, "failed to find item", "cl", objKey, "item name", targetItemName)
+		return err
+	}
+
+	if item != nil {
+		// The item may have been created by this request before the item ID
+		// could be recorded in the status, ex. the status patch failed. The
+		// update session of such an item is unknown, so the item is deleted
+		// and the import is started again.
+		if r.isItemCorrelatedWithImportRequest(ctx, item) {
+			ctx.Logger.Info("deleting existing target item created by this request", "itemID", item.ID)
+			if err := r.VMProvider.DeleteContentLibraryItem(ctx, item.ID); err != nil {
+				ctx.Logger.Error(err, "failed to delete item", "itemID", item.ID)
+				return err
+			}
+			conditions.MarkTrue(importReq, vmopv1.VirtualMachineImageImportRequestConditionTargetValid)
+			return nil
+		}
+
+		// If duplicate item name exists, give up at this point.
+		conditions.MarkFalse(importReq,
+			vmopv1.VirtualMachineImageImportRequestConditionTargetValid,
+			vmopv1.TargetItemAlreadyExistsReason,
+			fmt.Sprintf("item with name %s already exists in the content library %s", targetItemName,
+				contentLibrary.Status.Name))
+		r.Recorder.Warn(importReq, "ImportFailure", conditions.GetMessage(importReq,
+			vmopv1.VirtualMachineImageImportRequestConditionTargetValid))
+		return nil
+	}
+
+	conditions.MarkTrue(importReq, vmopv1.VirtualMachineImageImportRequestConditionTargetValid)
+	return nil
+}
+
+// isItemCorrelatedWithImportRequest checks if the item was created by this
+// import request by checking the item description. The import request UID is
+// added to the item description until the import completes.
+func (r *Reconciler) isItemCorrelatedWithImportRequest(ctx *context.VirtualMachineImageImportRequestContextA2,
+	item *library.Item) bool {
+	if item.Description != nil {
+		descriptions := itemDescriptionReg.FindStringSubmatch(*item.Description)
+		if len(descriptions) > 1 && descriptions[1] == string(ctx.ImportRequest.UID) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// importItem creates the content library item and starts pulling the image
+// from the source URL into it.
+func (r *Reconciler) importItem(ctx *context.VirtualMachineImageImportRequestContextA2) error {
+	importReq := ctx.ImportRequest
+
+	item := vmprovider.ContentLibraryItemImportA2{
+		Name:              getTargetItemName(importReq),
+		Description:       fmt.Sprintf("%s: %s", finalizerName, importReq.UID),
+		URL:               importReq.Spec.Source.URL,
+		ChecksumAlgorithm: string(importReq.Spec.Source.Checksum.Algorithm),
+		Checksum:          importReq.Spec.Source.Checksum.Value,
+	}
+	if item.ChecksumAlgorithm == "" {
+		item.ChecksumAlgorithm = string(vmopv1.VirtualMachineImageImportChecksumAlgorithmSHA256)
+	}
+
+	itemID, sessionID, err := r.VMProvider.ImportContentLibraryItem(ctx, string(ctx.ContentLibrary.Spec.UUID), item)
+	if err != nil {
+		if itemID != "" {
+			if err := r.VMProvider.DeleteContentLibraryItem(ctx, itemID); err != nil {
+				ctx.Logger.Error(err, "failed to delete item", "itemID", itemID)
+			}
+		}
+		conditions.MarkFalse(importReq,
+			vmopv1.VirtualMachineImageImportRequestConditionUploaded,
+			vmopv1.UploadTaskNotStartedReason,
+			err.Error())
+		r.Recorder.EmitEvent(importReq, "Import", err, false)
+		return err
+	}
+
+	ctx.Logger.Info("started importing item", "itemID", itemID, "sessionID", sessionID)
+	importReq.Status.ItemID = itemID
+	importReq.Status.UpdateSessionID = sessionID
+	importReq.Status.Progress = 0
+	conditions.MarkFalse(importReq,
+		vmopv1.VirtualMachineImageImportRequestConditionUploaded,
+		vmopv1.UploadingReason,
+		"Pulling image into content library.")
+
+	return nil
+}
+
+// checkIsUploaded checks the status of the update session pulling the image
+// into the content library item, and verifies the checksum of the transferred
+// file before completing the session.
+func (r *Reconciler) checkIsUploaded(ctx *context.VirtualMachineImageImportRequestContextA2) error {
+	importReq := ctx.ImportRequest
+	if conditions.IsTrue(importReq, vmopv1.VirtualMachineImageImportRequestConditionUploaded) {
+		return nil
+	}
+
+	status, err := r.VMProvider.GetContentLibraryItemImportStatus(ctx,
+		importReq.Status.ItemID, importReq.Status.UpdateSessionID, getSourceFileName(importReq))
+	if err != nil {
+		ctx.Logger.Error(err, "failed to get import status", "itemID", importReq.Status.ItemID)
+		return err
+	}
+
+	switch status.State {
+	case vmprovider.ContentLibraryItemImportStateActive:
+		importReq.Status.Progress = status.Progress
+		conditions.MarkFalse(importReq,
+			vmopv1.VirtualMachineImageImportRequestConditionUploaded,
+			vmopv1.UploadingReason,
+			"Pulling image into content library.")
+		return nil
+	case vmprovider.ContentLibraryItemImportStateError:
+		msg := status.Message
+		if msg == "" {
+			msg = "failed to pull image into content library"
+		}
+		return r.failUpload(ctx, vmopv1.UploadFailureReason, msg)
+	case vmprovider.ContentLibraryItemImportStateTransferred:
+		if reason, msg := verifyChecksum(importReq, status.File); reason != "" {
+			return r.failUpload(ctx, reason, msg)
+		}
+
+		if err := r.VMProvider.CompleteContentLibraryItemImport(ctx, importReq.Status.UpdateSessionID); err != nil {
+			ctx.Logger.Error(err, "failed to complete import", "sessionID", importReq.Status.UpdateSessionID)
+			return err
+		}
+
+		importReq.Status.Progress = status.Progress
+		conditions.MarkFalse(importReq,
+			vmopv1.VirtualMachineImageImportRequestConditionUploaded,
+			vmopv1.UploadingReason,
+			"Adding image to content library item.")
+		return nil
+	case vmprovider.ContentLibraryItemImportStateDone:
+	default:
+		return fmt.Errorf("unexpected update session state %q", status.State)
+	}
+
+	// Update the item description to remove the import request UID from it.
+	// Update the description before marking Uploaded, otherwise we may lose
+	// track of the item if this update fails.
+	if err := r.VMProvider.UpdateContentLibraryItem(ctx, importReq.Status.ItemID, "",
+		&importReq.Spec.Target.Item.Description); err != nil {
+		ctx.Logger.Error(err, "failed to update item description", "itemID", importReq.Status.ItemID)
+		return err
+	}
+
+	importReq.Status.Progress = 100
+	conditions.MarkTrue(importReq, vmopv1.VirtualMachineImageImportRequestConditionUploaded)
+	return nil
+}
+
+// failUpload marks the upload as failed, and fails the update session and
+// deletes the item. The failure is terminal and the import is not retried.
+func (r *Reconciler) failUpload(ctx *context.VirtualMachineImageImportRequestContextA2, reason, msg string) error {
+	importReq := ctx.ImportRequest
+
+	ctx.Logger.Info("import failed", "reason", reason, "message", msg)
+
+	// The session may have already failed or expired, and the item is deleted
+	// regardless.
+	if err := r.VMProvider.FailContentLibraryItemImport(ctx, importReq.Status.UpdateSessionID); err != nil {
+		ctx.Logger.Error(err, "failed to fail import", "sessionID", importReq.Status.UpdateSessionID)
+	}
+
+	if err := r.VMProvider.DeleteContentLibraryItem(ctx, importReq.Status.ItemID); err != nil {
+		ctx.Logger.Error(err, "failed to delete item", "itemID", importReq.Status.ItemID)
+		return err
+	}
+
+	conditions.MarkFalse(importReq,
+		vmopv1.VirtualMachineImageImportRequestConditionUploaded,
+		reason,
+		msg)
+	r.Recorder.Warn(importReq, "ImportFailure", msg)
+	return nil
+}
+
+// verifyChecksum returns the reason and a message describing the failure if
+// the checksum the content library validated the transferred file against
+// does not match the expected checksum, or if the content library does not
+// report a checksum with the expected algorithm.
+func verifyChecksum(importReq *vmopv1.VirtualMachineImageImportRequest, file *library.UpdateFile) (string, string) {
+	algorithm := string(importReq.Spec.Source.Checksum.Algorithm)
+	if algorithm == "" {
+		algorithm = string(vmopv1.VirtualMachineImageImportChecksumAlgorithmSHA256)
+	}
+
+	fileName := getSourceFileName(importReq)
+	if file == nil || file.Checksum == nil || !strings.EqualFold(file.Checksum.Algorithm, algorithm) {
+		return vmopv1.UploadChecksumUnverifiedReason,
+			fmt.Sprintf("content library did not report a %s checksum of %s", algorithm, fileName)
+	}
+
+	if !strings.EqualFold(file.Checksum.Checksum, importReq.Spec.Source.Checksum.Value) {
+		return vmopv1.UploadChecksumMismatchReason, fmt.Sprintf("%s checksum of %s is %s, expected %s",
+			algorithm, fileName, file.Checksum.Checksum, importReq.Spec.Source.Checksum.Value)
+	}
+
+	return "", ""
+}
+
+// checkIsImageAvailable checks if the VirtualMachineImage resource realized
+// from the imported item is ready.
+func (r *Reconciler) checkIsImageAvailable(ctx *context.VirtualMachineImageImportRequestContextA2) error {
+	importReq := ctx.ImportRequest
+	if !conditions.IsTrue(importReq, vmopv1.VirtualMachineImageImportRequestConditionUploaded) {
+		return nil
+	}
+
+	if conditions.IsTrue(importReq, vmopv1.VirtualMachineImageImportRequestConditionImageAvailable) {
+		return nil
+	}
+
+	vmiList := &vmopv1.VirtualMachineImageList{}
+	if err := r.List(ctx, vmiList, client.InNamespace(importReq.Namespace)); err != nil {
+		ctx.Logger.Error(err, "failed to list VirtualMachineImage")
+		return err
+	}
+
+	for i := range vmiList.Items {
+		vmi := &vmiList.Items[i]
+		if vmi.Status.ProviderItemID != importReq.Status.ItemID {
+			continue
+		}
+
+		importReq.Status.ImageName = vmi.Name
+		if !conditions.IsTrue(vmi, vmopv1.ReadyConditionType) {
+			conditions.MarkFalse(importReq,
+				vmopv1.VirtualMachineImageImportRequestConditionImageAvailable,
+				vmopv1.TargetVirtualMachineImageNotReadyReason,
+				"VirtualMachineImage %s is not ready", vmi.Name)
+			return nil
+		}
+
+		conditions.MarkTrue(importReq, vmopv1.VirtualMachineImageImportRequestConditionImageAvailable)
+		ctx.Logger.Info("VirtualMachineImage is available", "vmiName", vmi.Name)
+		return nil
+	}
+
+	conditions.MarkFalse(importReq,
+		vmopv1.VirtualMachineImageImportRequestConditionImageAvailable,
+		vmopv1.TargetVirtualMachineImageNotFoundReason,
+		"VirtualMachineImage not found")
+	return nil
+}
+
+// checkIsComplete checks if condition Complete can be marked to true.
+// The condition's status is set to true only when all other conditions present on the resource have a truthy status.
+func (r *Reconciler) checkIsComplete(ctx *context.VirtualMachineImageImportRequestContextA2) bool {
+	importReq := ctx.ImportRequest
+
+	if !conditions.IsTrue(importReq, vmopv1.VirtualMachineImageImportRequestConditionUploaded) {
+		conditions.MarkFalse(importReq,
+			vmopv1.VirtualMachineImageImportRequestConditionComplete,
+			vmopv1.HasNotBeenUploadedReason,
+			"item hasn't been uploaded yet")
+		return false
+	}
+
+	if !conditions.IsTrue(importReq, vmopv1.VirtualMachineImageImportRequestConditionImageAvailable) {
+		conditions.MarkFalse(importReq,
+			vmopv1.VirtualMachineImageImportRequestConditionComplete,
+			vmopv1.ImageUnavailableReason,
+			"VirtualMachineImage is not available")
+		return false
+	}
+
+	conditions.MarkTrue(importReq, vmopv1.VirtualMachineImageImportRequestConditionComplete)
+	importReq.Status.Ready = true
+	importReq.Status.CompletionTime = metav1.Now()
+	ctx.Logger.Info("VM image import request completed", "time", importReq.Status.CompletionTime)
+
+	return true
+}
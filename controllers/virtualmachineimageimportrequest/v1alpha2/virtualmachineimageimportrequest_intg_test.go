@@ -0,0 +1,122 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha2_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	imgregv1a1 "github.com/vmware-tanzu/image-registry-operator-api/api/v1alpha1"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	conditions "github.com/vmware-tanzu/vm-operator/pkg/conditions2"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func intgTests() {
+	Describe("Invoking VirtualMachineImageImportRequest controller tests", intgTestsReconcile)
+}
+
+func intgTestsReconcile() {
+	const itemID = "dummy-item-id"
+
+	var (
+		ctx       *builder.IntegrationTestContext
+		importReq *vmopv1.VirtualMachineImageImportRequest
+		cl        *imgregv1a1.ContentLibrary
+	)
+
+	getImportRequest := func(ctx *builder.IntegrationTestContext, objKey client.ObjectKey) *vmopv1.VirtualMachineImageImportRequest {
+		importReq := &vmopv1.VirtualMachineImageImportRequest{}
+		if err := ctx.Client.Get(ctx, objKey, importReq); err != nil {
+			return nil
+		}
+		return importReq
+	}
+
+	BeforeEach(func() {
+		ctx = suite.NewIntegrationTestContext()
+		cl = builder.DummyContentLibrary("dummy-cl", ctx.Namespace, "dummy-cl-uuid")
+		importReq = builder.DummyVirtualMachineImageImportRequest(ctx.Namespace, "dummy-import", cl.Name)
+	})
+
+	AfterEach(func() {
+		ctx.AfterEach()
+		ctx = nil
+		fakeVMProvider.Reset()
+	})
+
+	Context("Reconcile", func() {
+		BeforeEach(func() {
+			fakeVMProvider.Lock()
+			fakeVMProvider.ImportContentLibraryItemFn = func(
+				_ context.Context,
+				_ string,
+				_ vmprovider.ContentLibraryItemImportA2) (string, string, error) {
+
+				return itemID, "dummy-session-id", nil
+			}
+			fakeVMProvider.GetContentLibraryItemImportStatusFn = func(
+				_ context.Context,
+				_, _, _ string) (vmprovider.ContentLibraryItemImportStatusA2, error) {
+
+				return vmprovider.ContentLibraryItemImportStatusA2{
+					State:    vmprovider.ContentLibraryItemImportStateDone,
+					Progress: 100,
+				}, nil
+			}
+			fakeVMProvider.Unlock()
+
+			Expect(ctx.Client.Create(ctx, cl)).To(Succeed())
+			cl.Status.Conditions = []imgregv1a1.Condition{
+				{
+					Type:   imgregv1a1.ReadyCondition,
+					Status: corev1.ConditionTrue,
+				},
+			}
+			Expect(ctx.Client.Status().Update(ctx, cl)).To(Succeed())
+
+			Expect(ctx.Client.Create(ctx, importReq)).To(Succeed())
+		})
+
+		It("imports the image", func() {
+			objKey := client.ObjectKeyFromObject(importReq)
+
+			Eventually(func() bool {
+				if obj := getImportRequest(ctx, objKey); obj != nil {
+					return conditions.IsTrue(obj, vmopv1.VirtualMachineImageImportRequestConditionUploaded)
+				}
+				return false
+			}).Should(BeTrue())
+
+			By("Simulate VirtualMachineImage reconcile", func() {
+				vmi := builder.DummyVirtualMachineImageA2("vmi-dummy")
+				vmi.Namespace = ctx.Namespace
+				Expect(ctx.Client.Create(ctx, vmi)).To(Succeed())
+				vmi.Status.ProviderItemID = itemID
+				conditions.MarkTrue(vmi, vmopv1.ReadyConditionType)
+				Expect(ctx.Client.Status().Update(ctx, vmi)).To(Succeed())
+			})
+
+			Eventually(func() bool {
+				if obj := getImportRequest(ctx, objKey); obj != nil {
+					return conditions.IsTrue(obj, vmopv1.VirtualMachineImageImportRequestConditionComplete)
+				}
+				return false
+			}).Should(BeTrue())
+
+			obj := getImportRequest(ctx, objKey)
+			Expect(obj.Status.Ready).To(BeTrue())
+			Expect(obj.Status.ItemID).To(Equal(itemID))
+			Expect(obj.Status.ImageName).To(Equal("vmi-dummy"))
+			Expect(obj.Status.Progress).To(BeEquivalentTo(100))
+		})
+	})
+}
@@ -0,0 +1,38 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha2_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+
+	ctrlmgr "sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/vmware-tanzu/vm-operator/controllers/virtualmachineimageimportrequest/v1alpha2"
+	ctrlContext "github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/lib"
+	providerfake "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/fake"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+var fakeVMProvider = providerfake.NewVMProviderA2()
+
+var suite = builder.NewTestSuiteForControllerWithFSS(
+	v1alpha2.AddToManager,
+	func(ctx *ctrlContext.ControllerManagerContext, _ ctrlmgr.Manager) error {
+		ctx.VMProviderA2 = fakeVMProvider
+		return nil
+	},
+	map[string]bool{
+		lib.VMImageRegistryFSS:   true,
+		lib.VMServiceV1Alpha2FSS: true})
+
+func TestVirtualMachineImageImportRequest(t *testing.T) {
+	suite.Register(t, "VirtualMachineImageImportRequest controller suite", intgTests, unitTests)
+}
+
+var _ = BeforeSuite(suite.BeforeSuite)
+
+var _ = AfterSuite(suite.AfterSuite)
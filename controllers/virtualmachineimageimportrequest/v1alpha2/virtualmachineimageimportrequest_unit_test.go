@@ -0,0 +1,413 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha2_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware/govmomi/vapi/library"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	imgregv1a1 "github.com/vmware-tanzu/image-registry-operator-api/api/v1alpha1"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	virtualmachineimageimportrequest "github.com/vmware-tanzu/vm-operator/controllers/virtualmachineimageimportrequest/v1alpha2"
+	conditions "github.com/vmware-tanzu/vm-operator/pkg/conditions2"
+	vmopContext "github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
+	providerfake "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/fake"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func unitTests() {
+	Describe("Invoking VirtualMachineImageImportRequest Reconcile", unitTestsReconcile)
+}
+
+func unitTestsReconcile() {
+	const (
+		namespace = "dummy-ns"
+		itemID    = "dummy-item-id"
+		sessionID = "dummy-session-id"
+		checksum  = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	)
+
+	var (
+		initObjects []client.Object
+		ctx         *builder.UnitTestContextForController
+
+		reconciler *virtualmachineimageimportrequest.Reconciler
+		importCtx  *vmopContext.VirtualMachineImageImportRequestContextA2
+		importReq  *vmopv1.VirtualMachineImageImportRequest
+		cl         *imgregv1a1.ContentLibrary
+
+		importArgs         vmprovider.ContentLibraryItemImportA2
+		importStatus       vmprovider.ContentLibraryItemImportStatusA2
+		existingItem       *library.Item
+		deletedItems       []string
+		updatedItemID      string
+		completedSessionID string
+		failedSessionID    string
+	)
+
+	BeforeEach(func() {
+		cl = builder.DummyContentLibrary("dummy-cl", namespace, "dummy-cl-uuid")
+		importReq = builder.DummyVirtualMachineImageImportRequest(namespace, "dummy-import", cl.Name)
+		importReq.UID = types.UID("dummy-uid")
+		importReq.Finalizers = []string{"virtualmachineimageimportrequest.vmoperator.vmware.com"}
+		initObjects = []client.Object{cl}
+
+		importArgs = vmprovider.ContentLibraryItemImportA2{}
+		importStatus = vmprovider.ContentLibraryItemImportStatusA2{
+			State:    vmprovider.ContentLibraryItemImportStateActive,
+			Progress: 42,
+		}
+		existingItem = nil
+		deletedItems = nil
+		updatedItemID = ""
+		completedSessionID = ""
+		failedSessionID = ""
+	})
+
+	JustBeforeEach(func() {
+		ctx = suite.NewUnitTestContextForController(initObjects...)
+		reconciler = virtualmachineimageimportrequest.NewReconciler(
+			ctx.Client,
+			ctx.Logger,
+			ctx.Recorder,
+			ctx.VMProviderA2,
+		)
+		fakeVMProvider = ctx.VMProviderA2.(*providerfake.VMProviderA2)
+		fakeVMProvider.GetItemFromLibraryByNameFn = func(_ context.Context, _, _ string) (*library.Item, error) {
+			return existingItem, nil
+		}
+		fakeVMProvider.ImportContentLibraryItemFn = func(
+			_ context.Context,
+			_ string,
+			item vmprovider.ContentLibraryItemImportA2) (string, string, error) {
+
+			importArgs = item
+			return itemID, sessionID, nil
+		}
+		fakeVMProvider.GetContentLibraryItemImportStatusFn = func(
+			_ context.Context,
+			_, _, _ string) (vmprovider.ContentLibraryItemImportStatusA2, error) {
+
+			return importStatus, nil
+		}
+		fakeVMProvider.CompleteContentLibraryItemImportFn = func(_ context.Context, id string) error {
+			completedSessionID = id
+			return nil
+		}
+		fakeVMProvider.FailContentLibraryItemImportFn = func(_ context.Context, id string) error {
+			failedSessionID = id
+			return nil
+		}
+		fakeVMProvider.DeleteContentLibraryItemFn = func(_ context.Context, id string) error {
+			deletedItems = append(deletedItems, id)
+			return nil
+		}
+		fakeVMProvider.UpdateContentLibraryItemFn = func(_ context.Context, id, _ string, _ *string) error {
+			updatedItemID = id
+			return nil
+		}
+
+		importCtx = &vmopContext.VirtualMachineImageImportRequestContextA2{
+			Context:       ctx,
+			Logger:        ctx.Logger.WithName(importReq.Name),
+			ImportRequest: importReq,
+		}
+	})
+
+	AfterEach(func() {
+		ctx.AfterEach()
+		ctx = nil
+		initObjects = nil
+		reconciler = nil
+	})
+
+	Context("ReconcileNormal", func() {
+
+		It("starts importing the item", func() {
+			result, err := reconciler.ReconcileNormal(importCtx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.RequeueAfter).ToNot(BeZero())
+
+			Expect(importArgs.Name).To(Equal(importReq.Name))
+			Expect(importArgs.URL).To(Equal(importReq.Spec.Source.URL))
+			Expect(importArgs.ChecksumAlgorithm).To(Equal("SHA256"))
+			Expect(importArgs.Checksum).To(Equal(checksum))
+			Expect(importArgs.Description).To(ContainSubstring("dummy-uid"))
+
+			Expect(importReq.Status.StartTime.IsZero()).To(BeFalse())
+			Expect(importReq.Status.ItemID).To(Equal(itemID))
+			Expect(importReq.Status.UpdateSessionID).To(Equal(sessionID))
+			Expect(conditions.IsTrue(importReq, vmopv1.VirtualMachineImageImportRequestConditionTargetValid)).To(BeTrue())
+			Expect(conditions.GetReason(importReq, vmopv1.VirtualMachineImageImportRequestConditionUploaded)).To(Equal(vmopv1.UploadingReason))
+		})
+
+		When("the import fails to start", func() {
+			JustBeforeEach(func() {
+				fakeVMProvider.ImportContentLibraryItemFn = func(
+					_ context.Context,
+					_ string,
+					_ vmprovider.ContentLibraryItemImportA2) (string, string, error) {
+
+					return itemID, "", errors.New("fake")
+				}
+			})
+
+			It("deletes the item and returns an error", func() {
+				_, err := reconciler.ReconcileNormal(importCtx)
+				Expect(err).To(HaveOccurred())
+				Expect(deletedItems).To(ConsistOf(itemID))
+				Expect(importReq.Status.ItemID).To(BeEmpty())
+				Expect(conditions.GetReason(importReq, vmopv1.VirtualMachineImageImportRequestConditionUploaded)).To(Equal(vmopv1.UploadTaskNotStartedReason))
+			})
+		})
+
+		When("the content library does not exist", func() {
+			BeforeEach(func() {
+				initObjects = nil
+			})
+
+			It("marks the target as not valid", func() {
+				_, err := reconciler.ReconcileNormal(importCtx)
+				Expect(err).To(HaveOccurred())
+				Expect(conditions.GetReason(importReq, vmopv1.VirtualMachineImageImportRequestConditionTargetValid)).To(Equal(vmopv1.TargetContentLibraryNotExistReason))
+			})
+		})
+
+		When("the content library is not writable", func() {
+			BeforeEach(func() {
+				cl.Spec.Writable = false
+			})
+
+			It("marks the target as not valid", func() {
+				_, err := reconciler.ReconcileNormal(importCtx)
+				Expect(err).To(HaveOccurred())
+				Expect(conditions.GetReason(importReq, vmopv1.VirtualMachineImageImportRequestConditionTargetValid)).To(Equal(vmopv1.TargetContentLibraryNotWritableReason))
+			})
+		})
+
+		When("an item with the same name already exists", func() {
+			BeforeEach(func() {
+				existingItem = &library.Item{ID: "other-item-id", Name: importReq.Name}
+			})
+
+			It("marks the target as not valid and does not requeue", func() {
+				result, err := reconciler.ReconcileNormal(importCtx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.RequeueAfter).To(BeZero())
+				Expect(conditions.GetReason(importReq, vmopv1.VirtualMachineImageImportRequestConditionTargetValid)).To(Equal(vmopv1.TargetItemAlreadyExistsReason))
+				Expect(importArgs.Name).To(BeEmpty())
+			})
+
+			When("the item was created by this request", func() {
+				BeforeEach(func() {
+					desc := fmt.Sprintf("virtualmachineimageimportrequest.vmoperator.vmware.com: %s", importReq.UID)
+					existingItem.Description = &desc
+				})
+
+				It("deletes the item and starts importing again", func() {
+					_, err := reconciler.ReconcileNormal(importCtx)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(deletedItems).To(ConsistOf("other-item-id"))
+					Expect(importReq.Status.ItemID).To(Equal(itemID))
+				})
+			})
+		})
+
+		When("the import is in progress", func() {
+			BeforeEach(func() {
+				importReq.Status.ItemID = itemID
+				importReq.Status.UpdateSessionID = sessionID
+			})
+
+			It("updates the progress", func() {
+				result, err := reconciler.ReconcileNormal(importCtx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.RequeueAfter).ToNot(BeZero())
+				Expect(importReq.Status.Progress).To(BeEquivalentTo(42))
+				Expect(conditions.GetReason(importReq, vmopv1.VirtualMachineImageImportRequestConditionUploaded)).To(Equal(vmopv1.UploadingReason))
+				Expect(conditions.GetReason(importReq, vmopv1.VirtualMachineImageImportRequestConditionComplete)).To(Equal(vmopv1.HasNotBeenUploadedReason))
+			})
+
+			When("the import fails", func() {
+				BeforeEach(func() {
+					importStatus = vmprovider.ContentLibraryItemImportStatusA2{
+						State:   vmprovider.ContentLibraryItemImportStateError,
+						Message: "fake",
+					}
+				})
+
+				It("deletes the item and does not requeue", func() {
+					result, err := reconciler.ReconcileNormal(importCtx)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(result.RequeueAfter).To(BeZero())
+					Expect(deletedItems).To(ConsistOf(itemID))
+					Expect(conditions.GetReason(importReq, vmopv1.VirtualMachineImageImportRequestConditionUploaded)).To(Equal(vmopv1.UploadFailureReason))
+					Expect(conditions.GetMessage(importReq, vmopv1.VirtualMachineImageImportRequestConditionUploaded)).To(Equal("fake"))
+
+					By("does not import again")
+					_, err = reconciler.ReconcileNormal(importCtx)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(importArgs.Name).To(BeEmpty())
+				})
+			})
+
+			When("the file is transferred", func() {
+				var fileChecksum string
+
+				BeforeEach(func() {
+					fileChecksum = checksum
+				})
+
+				JustBeforeEach(func() {
+					importStatus = vmprovider.ContentLibraryItemImportStatusA2{
+						State:    vmprovider.ContentLibraryItemImportStateTransferred,
+						Progress: 100,
+						File: &library.UpdateFile{
+							Name:     "dummy-image.ova",
+							Status:   "READY",
+							Checksum: &library.Checksum{Algorithm: "SHA256", Checksum: fileChecksum},
+						},
+					}
+				})
+
+				It("completes the update session", func() {
+					result, err := reconciler.ReconcileNormal(importCtx)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(result.RequeueAfter).ToNot(BeZero())
+					Expect(completedSessionID).To(Equal(sessionID))
+					Expect(failedSessionID).To(BeEmpty())
+					Expect(deletedItems).To(BeEmpty())
+					Expect(importReq.Status.Progress).To(BeEquivalentTo(100))
+					Expect(conditions.GetReason(importReq, vmopv1.VirtualMachineImageImportRequestConditionUploaded)).To(Equal(vmopv1.UploadingReason))
+				})
+
+				When("the checksum does not match", func() {
+					BeforeEach(func() {
+						fileChecksum = "0000"
+					})
+
+					It("fails the update session, deletes the item and does not requeue", func() {
+						result, err := reconciler.ReconcileNormal(importCtx)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(result.RequeueAfter).To(BeZero())
+						Expect(completedSessionID).To(BeEmpty())
+						Expect(failedSessionID).To(Equal(sessionID))
+						Expect(deletedItems).To(ConsistOf(itemID))
+						Expect(conditions.GetReason(importReq, vmopv1.VirtualMachineImageImportRequestConditionUploaded)).To(Equal(vmopv1.UploadChecksumMismatchReason))
+					})
+				})
+
+				When("the content library does not report a checksum with the expected algorithm", func() {
+					JustBeforeEach(func() {
+						importStatus.File.Checksum.Algorithm = "SHA1"
+					})
+
+					It("fails the update session, deletes the item and does not requeue", func() {
+						result, err := reconciler.ReconcileNormal(importCtx)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(result.RequeueAfter).To(BeZero())
+						Expect(completedSessionID).To(BeEmpty())
+						Expect(failedSessionID).To(Equal(sessionID))
+						Expect(deletedItems).To(ConsistOf(itemID))
+						Expect(conditions.GetReason(importReq, vmopv1.VirtualMachineImageImportRequestConditionUploaded)).To(Equal(vmopv1.UploadChecksumUnverifiedReason))
+					})
+				})
+			})
+
+			When("the import is done", func() {
+				BeforeEach(func() {
+					importStatus = vmprovider.ContentLibraryItemImportStatusA2{
+						State:    vmprovider.ContentLibraryItemImportStateDone,
+						Progress: 100,
+					}
+				})
+
+				It("marks the item as uploaded and waits for the image", func() {
+					_, err := reconciler.ReconcileNormal(importCtx)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(updatedItemID).To(Equal(itemID))
+					Expect(importReq.Status.Progress).To(BeEquivalentTo(100))
+					Expect(conditions.IsTrue(importReq, vmopv1.VirtualMachineImageImportRequestConditionUploaded)).To(BeTrue())
+					Expect(conditions.GetReason(importReq, vmopv1.VirtualMachineImageImportRequestConditionImageAvailable)).To(Equal(vmopv1.TargetVirtualMachineImageNotFoundReason))
+					Expect(conditions.GetReason(importReq, vmopv1.VirtualMachineImageImportRequestConditionComplete)).To(Equal(vmopv1.ImageUnavailableReason))
+				})
+
+				When("the image exists", func() {
+					var vmi *vmopv1.VirtualMachineImage
+
+					BeforeEach(func() {
+						vmi = builder.DummyVirtualMachineImageA2("vmi-dummy")
+						vmi.Namespace = namespace
+						vmi.Status.ProviderItemID = itemID
+						initObjects = append(initObjects, vmi)
+					})
+
+					It("waits for the image to be ready", func() {
+						_, err := reconciler.ReconcileNormal(importCtx)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(importReq.Status.ImageName).To(Equal(vmi.Name))
+						Expect(conditions.GetReason(importReq, vmopv1.VirtualMachineImageImportRequestConditionImageAvailable)).To(Equal(vmopv1.TargetVirtualMachineImageNotReadyReason))
+						Expect(importReq.Status.Ready).To(BeFalse())
+					})
+
+					When("the image is ready", func() {
+						BeforeEach(func() {
+							conditions.MarkTrue(vmi, vmopv1.ReadyConditionType)
+						})
+
+						It("completes the request", func() {
+							result, err := reconciler.ReconcileNormal(importCtx)
+							Expect(err).ToNot(HaveOccurred())
+							Expect(result.RequeueAfter).To(BeZero())
+							Expect(importReq.Status.ImageName).To(Equal(vmi.Name))
+							Expect(importReq.Status.Ready).To(BeTrue())
+							Expect(importReq.Status.CompletionTime.IsZero()).To(BeFalse())
+							Expect(conditions.IsTrue(importReq, vmopv1.VirtualMachineImageImportRequestConditionImageAvailable)).To(BeTrue())
+							Expect(conditions.IsTrue(importReq, vmopv1.VirtualMachineImageImportRequestConditionComplete)).To(BeTrue())
+						})
+					})
+				})
+			})
+		})
+	})
+
+	Context("ReconcileDelete", func() {
+		BeforeEach(func() {
+			importReq.Status.ItemID = itemID
+			now := metav1.Now()
+			importReq.DeletionTimestamp = &now
+		})
+
+		It("deletes the partially imported item", func() {
+			_, err := reconciler.ReconcileDelete(importCtx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(deletedItems).To(ConsistOf(itemID))
+			Expect(importReq.Finalizers).To(BeEmpty())
+		})
+
+		When("the item has been uploaded", func() {
+			BeforeEach(func() {
+				conditions.MarkTrue(importReq, vmopv1.VirtualMachineImageImportRequestConditionUploaded)
+			})
+
+			It("does not delete the item", func() {
+				_, err := reconciler.ReconcileDelete(importCtx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(deletedItems).To(BeEmpty())
+				Expect(importReq.Finalizers).To(BeEmpty())
+			})
+		})
+	})
+}
@@ -0,0 +1,361 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha2
+
+import (
+	goctx "context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	"github.com/robfig/cron/v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	k8serrors "k8s.io/apimachinery/pkg/util/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	conditions "github.com/vmware-tanzu/vm-operator/pkg/conditions2"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	patch "github.com/vmware-tanzu/vm-operator/pkg/patch2"
+	"github.com/vmware-tanzu/vm-operator/pkg/record"
+)
+
+const (
+	// nextRestartTimeNow is the value of a VM's spec.nextRestartTime that
+	// the VM mutation webhook replaces with the current time to restart it.
+	nextRestartTimeNow = "now"
+)
+
+// AddToManager adds this package's controller to the provided manager.
+func AddToManager(ctx *context.ControllerManagerContext, mgr manager.Manager) error {
+	var (
+		controlledType     = &vmopv1.VirtualMachinePowerSchedule{}
+		controlledTypeName = reflect.TypeOf(controlledType).Elem().Name()
+
+		controllerNameShort = fmt.Sprintf("%s-controller", strings.ToLower(controlledTypeName))
+		controllerNameLong  = fmt.Sprintf("%s/%s/%s", ctx.Namespace, ctx.Name, controllerNameShort)
+	)
+
+	r := NewReconciler(
+		mgr.GetClient(),
+		ctrl.Log.WithName("controllers").WithName(controlledTypeName),
+		record.New(mgr.GetEventRecorderFor(controllerNameLong)),
+	)
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(controlledType).
+		WithOptions(controller.Options{MaxConcurrentReconciles: ctx.MaxConcurrentReconciles}).
+		Complete(r)
+}
+
+func NewReconciler(
+	client client.Client,
+	logger logr.Logger,
+	recorder record.Recorder) *Reconciler {
+
+	return &Reconciler{
+		Client:   client,
+		Logger:   logger,
+		Recorder: recorder,
+	}
+}
+
+// Reconciler reconciles a VirtualMachinePowerSchedule object.
+type Reconciler struct {
+	client.Client
+	Logger   logr.Logger
+	Recorder record.Recorder
+}
+
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachinepowerschedules,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachinepowerschedules/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachines,verbs=get;list;watch;update;patch
+
+func (r *Reconciler) Reconcile(ctx goctx.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
+	ps := &vmopv1.VirtualMachinePowerSchedule{}
+	if err := r.Get(ctx, req.NamespacedName, ps); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if !ps.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	psCtx := &context.VirtualMachinePowerScheduleContextA2{
+		Context:       ctx,
+		Logger:        ctrl.Log.WithName("VirtualMachinePowerSchedule").WithValues("name", ps.NamespacedName()),
+		PowerSchedule: ps,
+	}
+
+	patchHelper, err := patch.NewHelper(ps, r.Client)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to init patch helper for %s", psCtx.String())
+	}
+	defer func() {
+		if err := patchHelper.Patch(ctx, ps); err != nil {
+			if reterr == nil {
+				reterr = err
+			}
+			psCtx.Logger.Error(err, "patch failed")
+		}
+	}()
+
+	return r.ReconcileNormal(psCtx, time.Now())
+}
+
+// scheduledOperation is a power operation and the schedule on which it runs.
+type scheduledOperation struct {
+	operation vmopv1.VirtualMachinePowerScheduleOperation
+	schedule  cron.Schedule
+}
+
+// ReconcileNormal applies the most recent power operation that was due to run
+// at or before now, and returns a result that requeues the schedule when the
+// next power operation is due.
+func (r *Reconciler) ReconcileNormal(
+	ctx *context.VirtualMachinePowerScheduleContextA2,
+	now time.Time) (ctrl.Result, error) {
+
+	ctx.Logger.Info("Reconciling VirtualMachinePowerSchedule")
+	defer func() {
+		ctx.Logger.Info("Finished Reconciling VirtualMachinePowerSchedule")
+	}()
+
+	ps := ctx.PowerSchedule
+	ps.Status.ObservedGeneration = ps.Generation
+
+	loc, operations, err := parseSchedule(ps)
+	if err != nil {
+		// An invalid schedule is not retried until the spec is updated.
+		conditions.MarkFalse(ps, vmopv1.VirtualMachinePowerScheduleConditionReady,
+			vmopv1.VirtualMachinePowerScheduleInvalidScheduleReason, err.Error())
+		ps.Status.NextRunTime = nil
+		ps.Status.NextRunOperation = ""
+		return ctrl.Result{}, nil
+	}
+	now = now.In(loc)
+
+	if ps.Spec.Suspend {
+		conditions.MarkFalse(ps, vmopv1.VirtualMachinePowerScheduleConditionReady,
+			vmopv1.VirtualMachinePowerScheduleSuspendedReason, "")
+		ps.Status.NextRunTime = nil
+		ps.Status.NextRunOperation = ""
+		return ctrl.Result{}, nil
+	}
+
+	// The power operations that were due since the last run, or since the
+	// schedule was created, are coalesced into the most recent one since it
+	// determines the state the VMs should be in now.
+	since := ps.CreationTimestamp.Time
+	if ps.Status.LastRunTime != nil {
+		since = ps.Status.LastRunTime.Time
+	}
+
+	// The power operations that were due before the starting deadline are
+	// skipped.
+	if d := ps.Spec.StartingDeadlineSeconds; d != nil {
+		if deadline := now.Add(-time.Duration(*d) * time.Second); deadline.After(since) {
+			if op, runTime := mostRecentOperation(operations, since.In(loc), deadline); op != "" {
+				ctx.Logger.Info("Skipping power operation that missed its starting deadline",
+					"operation", op, "runTime", runTime)
+			}
+			since = deadline
+		}
+	}
+
+	if op, runTime := mostRecentOperation(operations, since.In(loc), now); op != "" {
+		if err := r.runOperation(ctx, op); err != nil {
+			conditions.MarkFalse(ps, vmopv1.VirtualMachinePowerScheduleConditionReady,
+				vmopv1.VirtualMachinePowerScheduleOperationFailedReason, err.Error())
+			r.Recorder.EmitEvent(ps, string(op), err, false)
+			return ctrl.Result{}, err
+		}
+
+		ps.Status.LastRunTime = &metav1.Time{Time: runTime}
+		ps.Status.LastRunOperation = op
+		r.Recorder.EmitEvent(ps, string(op), nil, false)
+	}
+
+	conditions.MarkTrue(ps, vmopv1.VirtualMachinePowerScheduleConditionReady)
+
+	op, nextRunTime := nextOperation(operations, now)
+	ps.Status.NextRunTime = &metav1.Time{Time: nextRunTime}
+	ps.Status.NextRunOperation = op
+
+	return ctrl.Result{RequeueAfter: nextRunTime.Sub(now)}, nil
+}
+
+// parseSchedule returns the time zone and power operations of the schedule.
+func parseSchedule(ps *vmopv1.VirtualMachinePowerSchedule) (*time.Location, []scheduledOperation, error) {
+	loc := time.UTC
+	if tz := ps.Spec.TimeZone; tz != "" {
+		var err error
+		if loc, err = time.LoadLocation(tz); err != nil {
+			return nil, nil, fmt.Errorf("invalid time zone %q: %w", tz, err)
+		}
+	}
+
+	var operations []scheduledOperation
+	for _, s := range []struct {
+		operation vmopv1.VirtualMachinePowerScheduleOperation
+		spec      string
+	}{
+		{vmopv1.VirtualMachinePowerScheduleOperationPoweredOn, ps.Spec.PoweredOn},
+		{vmopv1.VirtualMachinePowerScheduleOperationPoweredOff, ps.Spec.PoweredOff},
+		{vmopv1.VirtualMachinePowerScheduleOperationSuspended, ps.Spec.Suspended},
+		{vmopv1.VirtualMachinePowerScheduleOperationRestart, ps.Spec.Restart},
+	} {
+		if s.spec == "" {
+			continue
+		}
+		schedule, err := cron.ParseStandard(s.spec)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid %s schedule %q: %w", s.operation, s.spec, err)
+		}
+		operations = append(operations, scheduledOperation{operation: s.operation, schedule: schedule})
+	}
+
+	if len(operations) == 0 {
+		return nil, nil, errors.New("no schedules specified")
+	}
+
+	return loc, operations, nil
+}
+
+// mostRecentOperation returns the power operation that was most recently due
+// to run in the (since, now] interval, and the time it was due.
+func mostRecentOperation(
+	operations []scheduledOperation,
+	since, now time.Time) (vmopv1.VirtualMachinePowerScheduleOperation, time.Time) {
+
+	var (
+		op      vmopv1.VirtualMachinePowerScheduleOperation
+		runTime time.Time
+	)
+
+	for _, o := range operations {
+		if last := lastScheduleTime(o.schedule, since, now); !last.IsZero() && last.After(runTime) {
+			op, runTime = o.operation, last
+		}
+	}
+
+	return op, runTime
+}
+
+// lastScheduleTime returns the most recent time the schedule was due in the
+// (since, now] interval, or the zero time if it was not due. Rather than
+// walking every time the schedule was due since a schedule that was suspended
+// for a long time, only the times in a window before now are walked, and the
+// window starts at the interval between the first two missed times and is
+// doubled until it contains one.
+func lastScheduleTime(schedule cron.Schedule, since, now time.Time) time.Time {
+	t1 := schedule.Next(since)
+	if t1.IsZero() || t1.After(now) {
+		return time.Time{}
+	}
+
+	t2 := schedule.Next(t1)
+	if t2.IsZero() || t2.After(now) {
+		return t1
+	}
+
+	for window := t2.Sub(t1); ; window *= 2 {
+		start := now.Add(-window)
+		if !start.After(since) {
+			start = since
+		}
+
+		var last time.Time
+		for t := schedule.Next(start); !t.IsZero() && !t.After(now); t = schedule.Next(t) {
+			last = t
+		}
+		if !last.IsZero() || start.Equal(since) {
+			return last
+		}
+	}
+}
+
+// nextOperation returns the power operation that is next due to run after
+// now, and the time it is due.
+func nextOperation(
+	operations []scheduledOperation,
+	now time.Time) (vmopv1.VirtualMachinePowerScheduleOperation, time.Time) {
+
+	var (
+		op      vmopv1.VirtualMachinePowerScheduleOperation
+		runTime time.Time
+	)
+
+	for _, o := range operations {
+		if t := o.schedule.Next(now); !t.IsZero() && (runTime.IsZero() || t.Before(runTime)) {
+			op, runTime = o.operation, t
+		}
+	}
+
+	return op, runTime
+}
+
+// runOperation applies the power operation to the VMs selected by the
+// schedule.
+func (r *Reconciler) runOperation(
+	ctx *context.VirtualMachinePowerScheduleContextA2,
+	op vmopv1.VirtualMachinePowerScheduleOperation) error {
+
+	ps := ctx.PowerSchedule
+
+	selector, err := metav1.LabelSelectorAsSelector(ps.Spec.Selector)
+	if err != nil {
+		return errors.Wrap(err, "invalid selector")
+	}
+	if selector.Empty() {
+		// Do not apply the operation to every VM in the namespace.
+		selector = labels.Nothing()
+	}
+
+	vmList := &vmopv1.VirtualMachineList{}
+	if err := r.List(ctx, vmList,
+		client.InNamespace(ps.Namespace),
+		client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return errors.Wrap(err, "failed to list VirtualMachines")
+	}
+
+	var errs []error
+	for i := range vmList.Items {
+		vm := &vmList.Items[i]
+		if !vm.DeletionTimestamp.IsZero() {
+			continue
+		}
+
+		vmPatch := client.MergeFrom(vm.DeepCopy())
+
+		switch op {
+		case vmopv1.VirtualMachinePowerScheduleOperationRestart:
+			// Only a VM that is powered on can be restarted.
+			if vm.Spec.PowerState != vmopv1.VirtualMachinePowerStateOn {
+				continue
+			}
+			vm.Spec.NextRestartTime = nextRestartTimeNow
+		default:
+			powerState := vmopv1.VirtualMachinePowerState(op)
+			if vm.Spec.PowerState == powerState {
+				continue
+			}
+			vm.Spec.PowerState = powerState
+		}
+
+		ctx.Logger.Info("Applying scheduled power operation", "vmName", vm.Name, "operation", op)
+		if err := r.Patch(ctx, vm, vmPatch); err != nil {
+			errs = append(errs, fmt.Errorf("failed to apply %s to VirtualMachine %s: %w", op, vm.Name, err))
+		}
+	}
+
+	return k8serrors.NewAggregate(errs)
+}
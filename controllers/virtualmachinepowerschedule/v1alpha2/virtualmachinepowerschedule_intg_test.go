@@ -0,0 +1,56 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha2_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func intgTests() {
+	Describe("Invoking VirtualMachinePowerSchedule controller tests", intgTestsReconcile)
+}
+
+func intgTestsReconcile() {
+	var (
+		ctx *builder.IntegrationTestContext
+		ps  *vmopv1.VirtualMachinePowerSchedule
+	)
+
+	BeforeEach(func() {
+		ctx = suite.NewIntegrationTestContext()
+		ps = builder.DummyVirtualMachinePowerSchedule(ctx.Namespace, "dummy-schedule")
+	})
+
+	AfterEach(func() {
+		ctx.AfterEach()
+		ctx = nil
+	})
+
+	Context("Reconcile", func() {
+		BeforeEach(func() {
+			Expect(ctx.Client.Create(ctx, ps)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			err := ctx.Client.Delete(ctx, ps)
+			Expect(client.IgnoreNotFound(err)).To(Succeed())
+		})
+
+		It("reports the next power operation in the status", func() {
+			Eventually(func() vmopv1.VirtualMachinePowerScheduleOperation {
+				obj := &vmopv1.VirtualMachinePowerSchedule{}
+				if err := ctx.Client.Get(ctx, client.ObjectKeyFromObject(ps), obj); err != nil {
+					return ""
+				}
+				return obj.Status.NextRunOperation
+			}).ShouldNot(BeEmpty())
+		})
+	})
+}
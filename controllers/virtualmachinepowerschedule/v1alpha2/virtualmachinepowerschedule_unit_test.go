@@ -0,0 +1,273 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha2_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	virtualmachinepowerschedule "github.com/vmware-tanzu/vm-operator/controllers/virtualmachinepowerschedule/v1alpha2"
+	conditions "github.com/vmware-tanzu/vm-operator/pkg/conditions2"
+	vmopContext "github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func unitTests() {
+	Describe("Invoking VirtualMachinePowerSchedule Reconcile", unitTestsReconcile)
+}
+
+func unitTestsReconcile() {
+	var (
+		initObjects []client.Object
+		ctx         *builder.UnitTestContextForController
+
+		reconciler *virtualmachinepowerschedule.Reconciler
+		psCtx      *vmopContext.VirtualMachinePowerScheduleContextA2
+		ps         *vmopv1.VirtualMachinePowerSchedule
+
+		// Monday, October 16th 2023.
+		monday = func(hour, min int) time.Time {
+			return time.Date(2023, time.October, 16, hour, min, 0, 0, time.UTC)
+		}
+	)
+
+	newVM := func(name string, powerState vmopv1.VirtualMachinePowerState, labels map[string]string) *vmopv1.VirtualMachine {
+		return &vmopv1.VirtualMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: ps.Namespace,
+				Labels:    labels,
+			},
+			Spec: vmopv1.VirtualMachineSpec{
+				ImageName:  "dummy-image",
+				ClassName:  "dummy-class",
+				PowerState: powerState,
+			},
+		}
+	}
+
+	getVM := func(name string) *vmopv1.VirtualMachine {
+		vm := &vmopv1.VirtualMachine{}
+		Expect(ctx.Client.Get(ctx, client.ObjectKey{Namespace: ps.Namespace, Name: name}, vm)).To(Succeed())
+		return vm
+	}
+
+	BeforeEach(func() {
+		ps = builder.DummyVirtualMachinePowerSchedule("dummy-ns", "dummy-schedule")
+		ps.CreationTimestamp = metav1.NewTime(monday(7, 0))
+
+		initObjects = append(initObjects,
+			newVM("dev-vm", vmopv1.VirtualMachinePowerStateOff, map[string]string{"env": "dev"}),
+			newVM("prod-vm", vmopv1.VirtualMachinePowerStateOff, map[string]string{"env": "prod"}))
+	})
+
+	JustBeforeEach(func() {
+		ctx = suite.NewUnitTestContextForController(initObjects...)
+		reconciler = virtualmachinepowerschedule.NewReconciler(
+			ctx.Client,
+			ctx.Logger,
+			ctx.Recorder,
+		)
+
+		psCtx = &vmopContext.VirtualMachinePowerScheduleContextA2{
+			Context:       ctx,
+			Logger:        ctx.Logger.WithName(ps.Name),
+			PowerSchedule: ps,
+		}
+	})
+
+	AfterEach(func() {
+		ctx.AfterEach()
+		ctx = nil
+		initObjects = nil
+		reconciler = nil
+	})
+
+	Context("ReconcileNormal", func() {
+
+		When("a power operation is due", func() {
+			It("applies the operation to the selected VMs", func() {
+				result, err := reconciler.ReconcileNormal(psCtx, monday(9, 0))
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(getVM("dev-vm").Spec.PowerState).To(Equal(vmopv1.VirtualMachinePowerStateOn))
+				Expect(getVM("prod-vm").Spec.PowerState).To(Equal(vmopv1.VirtualMachinePowerStateOff))
+
+				Expect(ps.Status.LastRunTime).ToNot(BeNil())
+				Expect(ps.Status.LastRunTime.Time).To(BeTemporally("==", monday(8, 0)))
+				Expect(ps.Status.LastRunOperation).To(Equal(vmopv1.VirtualMachinePowerScheduleOperationPoweredOn))
+				Expect(ps.Status.NextRunTime).ToNot(BeNil())
+				Expect(ps.Status.NextRunTime.Time).To(BeTemporally("==", monday(19, 0)))
+				Expect(ps.Status.NextRunOperation).To(Equal(vmopv1.VirtualMachinePowerScheduleOperationPoweredOff))
+				Expect(result.RequeueAfter).To(Equal(10 * time.Hour))
+				Expect(conditions.IsTrue(ps, vmopv1.VirtualMachinePowerScheduleConditionReady)).To(BeTrue())
+			})
+
+			When("the schedule is in a time zone", func() {
+				BeforeEach(func() {
+					ps.Spec.TimeZone = "America/New_York"
+				})
+
+				It("evaluates the schedule in the time zone", func() {
+					_, err := reconciler.ReconcileNormal(psCtx, monday(9, 0))
+					Expect(err).ToNot(HaveOccurred())
+					Expect(getVM("dev-vm").Spec.PowerState).To(Equal(vmopv1.VirtualMachinePowerStateOff))
+					Expect(ps.Status.LastRunTime).To(BeNil())
+					Expect(ps.Status.NextRunTime.Time).To(BeTemporally("==", monday(12, 0)))
+
+					// 8am EDT.
+					_, err = reconciler.ReconcileNormal(psCtx, monday(12, 30))
+					Expect(err).ToNot(HaveOccurred())
+					Expect(getVM("dev-vm").Spec.PowerState).To(Equal(vmopv1.VirtualMachinePowerStateOn))
+					Expect(ps.Status.LastRunTime.Time).To(BeTemporally("==", monday(12, 0)))
+				})
+			})
+
+			When("the operation was due within the starting deadline", func() {
+				BeforeEach(func() {
+					ps.Spec.StartingDeadlineSeconds = pointer.Int64(2 * 60 * 60)
+				})
+
+				It("applies the operation to the selected VMs", func() {
+					_, err := reconciler.ReconcileNormal(psCtx, monday(9, 0))
+					Expect(err).ToNot(HaveOccurred())
+					Expect(getVM("dev-vm").Spec.PowerState).To(Equal(vmopv1.VirtualMachinePowerStateOn))
+					Expect(ps.Status.LastRunTime.Time).To(BeTemporally("==", monday(8, 0)))
+				})
+			})
+
+			When("the operation missed its starting deadline", func() {
+				BeforeEach(func() {
+					ps.Spec.StartingDeadlineSeconds = pointer.Int64(30 * 60)
+				})
+
+				It("does not apply the operation", func() {
+					result, err := reconciler.ReconcileNormal(psCtx, monday(9, 0))
+					Expect(err).ToNot(HaveOccurred())
+
+					Expect(getVM("dev-vm").Spec.PowerState).To(Equal(vmopv1.VirtualMachinePowerStateOff))
+					Expect(ps.Status.LastRunTime).To(BeNil())
+					Expect(ps.Status.NextRunTime.Time).To(BeTemporally("==", monday(19, 0)))
+					Expect(result.RequeueAfter).To(Equal(10 * time.Hour))
+					Expect(conditions.IsTrue(ps, vmopv1.VirtualMachinePowerScheduleConditionReady)).To(BeTrue())
+				})
+			})
+		})
+
+		When("no power operation is due", func() {
+			It("does not change the VMs", func() {
+				result, err := reconciler.ReconcileNormal(psCtx, monday(7, 30))
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(getVM("dev-vm").Spec.PowerState).To(Equal(vmopv1.VirtualMachinePowerStateOff))
+				Expect(ps.Status.LastRunTime).To(BeNil())
+				Expect(ps.Status.NextRunTime.Time).To(BeTemporally("==", monday(8, 0)))
+				Expect(ps.Status.NextRunOperation).To(Equal(vmopv1.VirtualMachinePowerScheduleOperationPoweredOn))
+				Expect(result.RequeueAfter).To(Equal(30 * time.Minute))
+			})
+		})
+
+		When("several power operations were missed", func() {
+			BeforeEach(func() {
+				// Friday 8am.
+				ps.Status.LastRunTime = &metav1.Time{Time: monday(8, 0).AddDate(0, 0, -3)}
+				initObjects = []client.Object{
+					newVM("dev-vm", vmopv1.VirtualMachinePowerStateOn, map[string]string{"env": "dev"}),
+				}
+			})
+
+			It("only applies the most recent operation", func() {
+				_, err := reconciler.ReconcileNormal(psCtx, monday(7, 0))
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(getVM("dev-vm").Spec.PowerState).To(Equal(vmopv1.VirtualMachinePowerStateOff))
+				Expect(ps.Status.LastRunTime.Time).To(BeTemporally("==", monday(19, 0).AddDate(0, 0, -3)))
+				Expect(ps.Status.LastRunOperation).To(Equal(vmopv1.VirtualMachinePowerScheduleOperationPoweredOff))
+			})
+		})
+
+		When("the power operations of several years were missed", func() {
+			BeforeEach(func() {
+				ps.Spec.PoweredOn = ""
+				ps.Spec.PoweredOff = ""
+				ps.Spec.Restart = "*/5 * * * *"
+				ps.Status.LastRunTime = &metav1.Time{Time: monday(8, 0).AddDate(-10, 0, 0)}
+			})
+
+			It("applies the most recent operation", func() {
+				_, err := reconciler.ReconcileNormal(psCtx, monday(9, 2))
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(ps.Status.LastRunTime.Time).To(BeTemporally("==", monday(9, 0)))
+				Expect(ps.Status.LastRunOperation).To(Equal(vmopv1.VirtualMachinePowerScheduleOperationRestart))
+				Expect(ps.Status.NextRunTime.Time).To(BeTemporally("==", monday(9, 5)))
+			})
+		})
+
+		When("a restart is due", func() {
+			BeforeEach(func() {
+				ps.Spec.PoweredOn = ""
+				ps.Spec.PoweredOff = ""
+				ps.Spec.Restart = "0 8 * * *"
+				initObjects = []client.Object{
+					newVM("on-vm", vmopv1.VirtualMachinePowerStateOn, map[string]string{"env": "dev"}),
+					newVM("off-vm", vmopv1.VirtualMachinePowerStateOff, map[string]string{"env": "dev"}),
+				}
+			})
+
+			It("restarts the selected VMs that are powered on", func() {
+				_, err := reconciler.ReconcileNormal(psCtx, monday(9, 0))
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(getVM("on-vm").Spec.NextRestartTime).To(Equal("now"))
+				Expect(getVM("off-vm").Spec.NextRestartTime).To(BeEmpty())
+				Expect(ps.Status.LastRunOperation).To(Equal(vmopv1.VirtualMachinePowerScheduleOperationRestart))
+			})
+		})
+
+		When("the schedule is suspended", func() {
+			BeforeEach(func() {
+				ps.Spec.Suspend = true
+			})
+
+			It("does not apply any operation", func() {
+				result, err := reconciler.ReconcileNormal(psCtx, monday(9, 0))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.RequeueAfter).To(BeZero())
+
+				Expect(getVM("dev-vm").Spec.PowerState).To(Equal(vmopv1.VirtualMachinePowerStateOff))
+				Expect(ps.Status.LastRunTime).To(BeNil())
+				Expect(ps.Status.NextRunTime).To(BeNil())
+				c := conditions.Get(ps, vmopv1.VirtualMachinePowerScheduleConditionReady)
+				Expect(c).ToNot(BeNil())
+				Expect(c.Reason).To(Equal(vmopv1.VirtualMachinePowerScheduleSuspendedReason))
+			})
+		})
+
+		When("the schedule is invalid", func() {
+			BeforeEach(func() {
+				ps.Spec.PoweredOn = "not a schedule"
+			})
+
+			It("marks the schedule as invalid", func() {
+				result, err := reconciler.ReconcileNormal(psCtx, monday(9, 0))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.RequeueAfter).To(BeZero())
+
+				Expect(getVM("dev-vm").Spec.PowerState).To(Equal(vmopv1.VirtualMachinePowerStateOff))
+				c := conditions.Get(ps, vmopv1.VirtualMachinePowerScheduleConditionReady)
+				Expect(c).ToNot(BeNil())
+				Expect(c.Status).To(Equal(metav1.ConditionFalse))
+				Expect(c.Reason).To(Equal(vmopv1.VirtualMachinePowerScheduleInvalidScheduleReason))
+			})
+		})
+	})
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha2
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// expectationsTimeout is how long the replicas a replica set created or
+// deleted are waited on to show up in, or disappear from, the cache before
+// the replica set is scaled again regardless.
+const expectationsTimeout = 5 * time.Minute
+
+// expectations tracks, per replica set, the VMs that were created or deleted
+// but whose creation or deletion has not yet been observed in the cache. This
+// guards against scaling again from a stale list, which would create or delete
+// too many replicas.
+type expectations struct {
+	mu    sync.Mutex
+	items map[string]*replicaExpectations
+}
+
+type replicaExpectations struct {
+	add       sets.Set[string]
+	del       sets.Set[string]
+	timestamp time.Time
+}
+
+func newExpectations() *expectations {
+	return &expectations{
+		items: map[string]*replicaExpectations{},
+	}
+}
+
+// ExpectCreations records that the named VMs were created for the replica set.
+func (e *expectations) ExpectCreations(key string, names ...string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.get(key).add.Insert(names...)
+}
+
+// ExpectDeletions records that the named VMs were deleted for the replica set.
+func (e *expectations) ExpectDeletions(key string, names ...string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.get(key).del.Insert(names...)
+}
+
+// Satisfied observes the replica set's current replicas, and returns true if
+// all the expected creations and deletions have been observed, or if they have
+// not been observed in time.
+func (e *expectations) Satisfied(key string, replicas sets.Set[string]) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	exp, ok := e.items[key]
+	if !ok {
+		return true
+	}
+
+	exp.add = exp.add.Difference(replicas)
+	exp.del = exp.del.Intersection(replicas)
+
+	if exp.add.Len() == 0 && exp.del.Len() == 0 || time.Since(exp.timestamp) > expectationsTimeout {
+		delete(e.items, key)
+		return true
+	}
+
+	return false
+}
+
+// Delete forgets the replica set's expectations.
+func (e *expectations) Delete(key string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.items, key)
+}
+
+func (e *expectations) get(key string) *replicaExpectations {
+	exp, ok := e.items[key]
+	if !ok {
+		exp = &replicaExpectations{
+			add: sets.New[string](),
+			del: sets.New[string](),
+		}
+		e.items[key] = exp
+	}
+	exp.timestamp = time.Now()
+	return exp
+}
@@ -0,0 +1,334 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha2
+
+import (
+	goctx "context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	"github.com/vmware-tanzu/vm-operator/pkg"
+	conditions "github.com/vmware-tanzu/vm-operator/pkg/conditions2"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	patch "github.com/vmware-tanzu/vm-operator/pkg/patch2"
+	"github.com/vmware-tanzu/vm-operator/pkg/record"
+)
+
+// AddToManager adds this package's controller to the provided manager.
+func AddToManager(ctx *context.ControllerManagerContext, mgr manager.Manager) error {
+	var (
+		controlledType     = &vmopv1.VirtualMachineReplicaSet{}
+		controlledTypeName = reflect.TypeOf(controlledType).Elem().Name()
+
+		controllerNameShort = fmt.Sprintf("%s-controller", strings.ToLower(controlledTypeName))
+		controllerNameLong  = fmt.Sprintf("%s/%s/%s", ctx.Namespace, ctx.Name, controllerNameShort)
+	)
+
+	r := NewReconciler(
+		mgr.GetClient(),
+		ctrl.Log.WithName("controllers").WithName(controlledTypeName),
+		record.New(mgr.GetEventRecorderFor(controllerNameLong)),
+	)
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(controlledType).
+		WithOptions(controller.Options{MaxConcurrentReconciles: ctx.MaxConcurrentReconciles}).
+		// The replicas' readiness is reported in the status, so the replica
+		// set is reconciled whenever one of its VMs changes.
+		Owns(&vmopv1.VirtualMachine{}).
+		Complete(r)
+}
+
+func NewReconciler(
+	client client.Client,
+	logger logr.Logger,
+	recorder record.Recorder) *Reconciler {
+
+	return &Reconciler{
+		Client:       client,
+		Logger:       logger,
+		Recorder:     recorder,
+		expectations: newExpectations(),
+	}
+}
+
+// Reconciler reconciles a VirtualMachineReplicaSet object.
+type Reconciler struct {
+	client.Client
+	Logger   logr.Logger
+	Recorder record.Recorder
+
+	expectations *expectations
+}
+
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachinereplicasets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachinereplicasets/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachinereplicasets/scale,verbs=get;update;patch
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachines,verbs=get;list;watch;create;update;patch;delete
+
+func (r *Reconciler) Reconcile(ctx goctx.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
+	rs := &vmopv1.VirtualMachineReplicaSet{}
+	if err := r.Get(ctx, req.NamespacedName, rs); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.expectations.Delete(req.NamespacedName.String())
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// The replicas are garbage collected through their owner reference.
+	if !rs.DeletionTimestamp.IsZero() {
+		r.expectations.Delete(req.NamespacedName.String())
+		return ctrl.Result{}, nil
+	}
+
+	rsCtx := &context.VirtualMachineReplicaSetContextA2{
+		Context:    ctx,
+		Logger:     ctrl.Log.WithName("VirtualMachineReplicaSet").WithValues("name", rs.NamespacedName()),
+		ReplicaSet: rs,
+	}
+
+	patchHelper, err := patch.NewHelper(rs, r.Client)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to init patch helper for %s", rsCtx.String())
+	}
+	defer func() {
+		if err := patchHelper.Patch(ctx, rs); err != nil {
+			if reterr == nil {
+				reterr = err
+			}
+			rsCtx.Logger.Error(err, "patch failed")
+		}
+	}()
+
+	return ctrl.Result{}, r.ReconcileNormal(rsCtx)
+}
+
+func (r *Reconciler) ReconcileNormal(ctx *context.VirtualMachineReplicaSetContextA2) error {
+	ctx.Logger.Info("Reconciling VirtualMachineReplicaSet")
+	defer func() {
+		ctx.Logger.Info("Finished Reconciling VirtualMachineReplicaSet")
+	}()
+
+	rs := ctx.ReplicaSet
+
+	selector, err := metav1.LabelSelectorAsSelector(rs.Spec.Selector)
+	if err != nil {
+		return errors.Wrap(err, "invalid selector")
+	}
+	rs.Status.Selector = selector.String()
+	rs.Status.ObservedGeneration = rs.Generation
+
+	replicas, err := r.getReplicas(ctx, selector)
+	if err != nil {
+		return err
+	}
+
+	desired := int32(1)
+	if rs.Spec.Replicas != nil {
+		desired = *rs.Spec.Replicas
+	}
+
+	// Do not scale until the cache reflects the replicas created or deleted by
+	// a previous reconcile. The replica set is reconciled again when the cache
+	// observes them.
+	key := rs.NamespacedName()
+	replicaNames := sets.New[string]()
+	for _, vm := range replicas {
+		replicaNames.Insert(vm.Name)
+	}
+	diff := 0
+	if r.expectations.Satisfied(key, replicaNames) {
+		diff = int(desired) - len(replicas)
+	} else {
+		ctx.Logger.V(4).Info("Waiting for replica creations and deletions to be observed")
+	}
+
+	var scaleErr error
+	switch {
+	case diff > 0:
+		for i := 0; i < diff; i++ {
+			vm, err := r.createReplica(ctx)
+			if err != nil {
+				conditions.MarkFalse(rs, vmopv1.VirtualMachineReplicaSetConditionReplicasReady,
+					vmopv1.VirtualMachineReplicaSetReplicaCreateFailedReason, err.Error())
+				r.Recorder.EmitEvent(rs, "CreateReplica", err, false)
+				scaleErr = err
+				break
+			}
+			r.expectations.ExpectCreations(key, vm.Name)
+			replicas = append(replicas, vm)
+		}
+	case diff < 0:
+		sortReplicasForDeletion(replicas)
+		for i := 0; i < -diff; i++ {
+			if err := r.Delete(ctx, replicas[0]); err != nil && !apierrors.IsNotFound(err) {
+				conditions.MarkFalse(rs, vmopv1.VirtualMachineReplicaSetConditionReplicasReady,
+					vmopv1.VirtualMachineReplicaSetReplicaDeleteFailedReason, err.Error())
+				r.Recorder.EmitEvent(rs, "DeleteReplica", err, false)
+				scaleErr = err
+				break
+			}
+			r.expectations.ExpectDeletions(key, replicas[0].Name)
+			replicas = replicas[1:]
+		}
+	}
+
+	readyReplicas := int32(0)
+	for _, vm := range replicas {
+		if isReplicaReady(vm) {
+			readyReplicas++
+		}
+	}
+	rs.Status.Replicas = int32(len(replicas))
+	rs.Status.ReadyReplicas = readyReplicas
+
+	if scaleErr != nil {
+		return scaleErr
+	}
+
+	if readyReplicas == desired {
+		conditions.MarkTrue(rs, vmopv1.VirtualMachineReplicaSetConditionReplicasReady)
+	} else {
+		conditions.MarkFalse(rs, vmopv1.VirtualMachineReplicaSetConditionReplicasReady,
+			vmopv1.VirtualMachineReplicaSetReplicasNotReadyReason, "%d of %d replicas are ready", readyReplicas, desired)
+	}
+
+	return nil
+}
+
+// getReplicas returns the VMs selected by and controlled by the replica set
+// that are not being deleted.
+func (r *Reconciler) getReplicas(
+	ctx *context.VirtualMachineReplicaSetContextA2,
+	selector labels.Selector) ([]*vmopv1.VirtualMachine, error) {
+
+	vmList := &vmopv1.VirtualMachineList{}
+	if err := r.List(ctx, vmList,
+		client.InNamespace(ctx.ReplicaSet.Namespace),
+		client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, errors.Wrap(err, "failed to list VirtualMachines")
+	}
+
+	var replicas []*vmopv1.VirtualMachine
+	for i := range vmList.Items {
+		vm := &vmList.Items[i]
+		if !vm.DeletionTimestamp.IsZero() || !metav1.IsControlledBy(vm, ctx.ReplicaSet) {
+			continue
+		}
+		replicas = append(replicas, vm)
+	}
+
+	return replicas, nil
+}
+
+// createReplica creates a VM from the replica set's template.
+func (r *Reconciler) createReplica(ctx *context.VirtualMachineReplicaSetContextA2) (*vmopv1.VirtualMachine, error) {
+	rs := ctx.ReplicaSet
+	template := rs.Spec.Template.DeepCopy()
+
+	// The name is generated here rather than by the API server so the replica's
+	// host name can be derived from it.
+	vm := &vmopv1.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        replicaName(rs.Name),
+			Namespace:   rs.Namespace,
+			Labels:      template.Labels,
+			Annotations: template.Annotations,
+		},
+		Spec: template.Spec,
+	}
+
+	// Each replica needs its own host name, so one set in the template is not
+	// copied verbatim.
+	if vm.Spec.Network != nil {
+		vm.Spec.Network.HostName = vm.Name
+	}
+
+	if vm.Labels == nil {
+		vm.Labels = map[string]string{}
+	}
+	vm.Labels[vmopv1.VirtualMachineReplicaSetNameLabel] = rs.Name
+
+	// Place all the replicas in the resource pool and folder of the resource
+	// policy, and in its cluster module so they are kept on separate hosts.
+	if rs.Spec.ResourcePolicyName != "" {
+		if vm.Spec.Reserved == nil {
+			vm.Spec.Reserved = &vmopv1.VirtualMachineReservedSpec{}
+		}
+		vm.Spec.Reserved.ResourcePolicyName = rs.Spec.ResourcePolicyName
+	}
+	if rs.Spec.ClusterModuleGroup != "" {
+		if vm.Annotations == nil {
+			vm.Annotations = map[string]string{}
+		}
+		vm.Annotations[pkg.ClusterModuleNameKey] = rs.Spec.ClusterModuleGroup
+	}
+
+	if err := controllerutil.SetControllerReference(rs, vm, r.Scheme()); err != nil {
+		return nil, err
+	}
+
+	if err := r.Create(ctx, vm); err != nil {
+		return nil, errors.Wrap(err, "failed to create VirtualMachine")
+	}
+
+	ctx.Logger.Info("Created replica VirtualMachine", "vmName", vm.Name)
+	return vm, nil
+}
+
+// replicaName returns a new name for a replica of the named replica set.
+func replicaName(rsName string) string {
+	const maxNameLength = 63
+	const randomLength = 5
+
+	// Keep the name a valid DNS label so it can be used as the host name.
+	if maxPrefix := maxNameLength - randomLength - 1; len(rsName) > maxPrefix {
+		rsName = rsName[:maxPrefix]
+	}
+	return rsName + "-" + utilrand.String(randomLength)
+}
+
+// isReplicaReady returns true if the VM is ready. A VM with a readiness probe
+// is ready when the prober has marked its Ready condition true. Otherwise, the
+// VM is ready once it has been created and is in its desired power state.
+func isReplicaReady(vm *vmopv1.VirtualMachine) bool {
+	if vm.Spec.ReadinessProbe != nil {
+		return conditions.IsTrue(vm, vmopv1.ReadyConditionType)
+	}
+	return conditions.IsTrue(vm, vmopv1.VirtualMachineConditionCreated) &&
+		vm.Status.PowerState == vm.Spec.PowerState
+}
+
+// sortReplicasForDeletion sorts the replicas so that the ones that should be
+// deleted first when scaling down come first: VMs that have not been created,
+// then VMs that are not ready, and then the newest VMs.
+func sortReplicasForDeletion(replicas []*vmopv1.VirtualMachine) {
+	sort.SliceStable(replicas, func(i, j int) bool {
+		a, b := replicas[i], replicas[j]
+		if ac, bc := conditions.IsTrue(a, vmopv1.VirtualMachineConditionCreated),
+			conditions.IsTrue(b, vmopv1.VirtualMachineConditionCreated); ac != bc {
+			return !ac
+		}
+		if ar, br := isReplicaReady(a), isReplicaReady(b); ar != br {
+			return !ar
+		}
+		return b.CreationTimestamp.Before(&a.CreationTimestamp)
+	})
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha2_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func intgTests() {
+	Describe("Invoking VirtualMachineReplicaSet controller tests", intgTestsReconcile)
+}
+
+func intgTestsReconcile() {
+	var (
+		ctx *builder.IntegrationTestContext
+		rs  *vmopv1.VirtualMachineReplicaSet
+	)
+
+	getReplicas := func(ctx *builder.IntegrationTestContext, rs *vmopv1.VirtualMachineReplicaSet) []vmopv1.VirtualMachine {
+		vmList := &vmopv1.VirtualMachineList{}
+		if err := ctx.Client.List(ctx, vmList, client.InNamespace(rs.Namespace),
+			client.MatchingLabels{vmopv1.VirtualMachineReplicaSetNameLabel: rs.Name}); err != nil {
+			return nil
+		}
+		return vmList.Items
+	}
+
+	BeforeEach(func() {
+		ctx = suite.NewIntegrationTestContext()
+		rs = builder.DummyVirtualMachineReplicaSet(ctx.Namespace, "dummy-rs")
+		rs.Spec.Replicas = pointer.Int32(2)
+	})
+
+	AfterEach(func() {
+		ctx.AfterEach()
+		ctx = nil
+	})
+
+	Context("Reconcile", func() {
+		BeforeEach(func() {
+			Expect(ctx.Client.Create(ctx, rs)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			err := ctx.Client.Delete(ctx, rs)
+			Expect(client.IgnoreNotFound(err)).To(Succeed())
+		})
+
+		It("creates and scales the replicas", func() {
+			By("creating the desired replicas", func() {
+				Eventually(func() []vmopv1.VirtualMachine {
+					return getReplicas(ctx, rs)
+				}).Should(HaveLen(2))
+			})
+
+			By("reporting the replicas in the status", func() {
+				Eventually(func() int32 {
+					obj := &vmopv1.VirtualMachineReplicaSet{}
+					if err := ctx.Client.Get(ctx, client.ObjectKeyFromObject(rs), obj); err != nil {
+						return 0
+					}
+					return obj.Status.Replicas
+				}).Should(BeEquivalentTo(2))
+			})
+
+			By("deleting replicas when scaled down", func() {
+				obj := &vmopv1.VirtualMachineReplicaSet{}
+				Expect(ctx.Client.Get(ctx, client.ObjectKeyFromObject(rs), obj)).To(Succeed())
+				obj.Spec.Replicas = pointer.Int32(1)
+				Expect(ctx.Client.Update(ctx, obj)).To(Succeed())
+
+				Eventually(func() int {
+					var count int
+					for _, vm := range getReplicas(ctx, rs) {
+						if vm.DeletionTimestamp.IsZero() && metav1.IsControlledBy(&vm, obj) {
+							count++
+						}
+					}
+					return count
+				}).Should(Equal(1))
+			})
+		})
+	})
+}
@@ -0,0 +1,258 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha2_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	virtualmachinereplicaset "github.com/vmware-tanzu/vm-operator/controllers/virtualmachinereplicaset/v1alpha2"
+	"github.com/vmware-tanzu/vm-operator/pkg"
+	conditions "github.com/vmware-tanzu/vm-operator/pkg/conditions2"
+	vmopContext "github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func unitTests() {
+	Describe("Invoking VirtualMachineReplicaSet Reconcile", unitTestsReconcile)
+}
+
+func unitTestsReconcile() {
+	var (
+		initObjects []client.Object
+		ctx         *builder.UnitTestContextForController
+
+		reconciler *virtualmachinereplicaset.Reconciler
+		rsCtx      *vmopContext.VirtualMachineReplicaSetContextA2
+		rs         *vmopv1.VirtualMachineReplicaSet
+	)
+
+	BeforeEach(func() {
+		rs = builder.DummyVirtualMachineReplicaSet("dummy-ns", "dummy-rs")
+		rs.UID = "dummy-uid"
+	})
+
+	JustBeforeEach(func() {
+		ctx = suite.NewUnitTestContextForController(initObjects...)
+		reconciler = virtualmachinereplicaset.NewReconciler(
+			ctx.Client,
+			ctx.Logger,
+			ctx.Recorder,
+		)
+
+		rsCtx = &vmopContext.VirtualMachineReplicaSetContextA2{
+			Context:    ctx,
+			Logger:     ctx.Logger.WithName(rs.Name),
+			ReplicaSet: rs,
+		}
+	})
+
+	AfterEach(func() {
+		ctx.AfterEach()
+		ctx = nil
+		initObjects = nil
+		reconciler = nil
+	})
+
+	getReplicas := func() []vmopv1.VirtualMachine {
+		vmList := &vmopv1.VirtualMachineList{}
+		Expect(ctx.Client.List(ctx, vmList, client.InNamespace(rs.Namespace))).To(Succeed())
+		var replicas []vmopv1.VirtualMachine
+		for _, vm := range vmList.Items {
+			if metav1.IsControlledBy(&vm, rs) {
+				replicas = append(replicas, vm)
+			}
+		}
+		return replicas
+	}
+
+	newReplica := func(name string, created, ready bool) *vmopv1.VirtualMachine {
+		vm := &vmopv1.VirtualMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: rs.Namespace,
+				Labels:    rs.Spec.Template.Labels,
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(rs, vmopv1.SchemeGroupVersion.WithKind("VirtualMachineReplicaSet")),
+				},
+			},
+			Spec: rs.Spec.Template.Spec,
+		}
+		if created {
+			conditions.MarkTrue(vm, vmopv1.VirtualMachineConditionCreated)
+		}
+		if ready {
+			vm.Status.PowerState = vm.Spec.PowerState
+		}
+		return vm
+	}
+
+	Context("ReconcileNormal", func() {
+
+		When("there are fewer replicas than desired", func() {
+			BeforeEach(func() {
+				rs.Spec.Replicas = pointer.Int32(3)
+			})
+
+			It("creates the missing replicas from the template", func() {
+				Expect(reconciler.ReconcileNormal(rsCtx)).To(Succeed())
+
+				replicas := getReplicas()
+				Expect(replicas).To(HaveLen(3))
+				for _, vm := range replicas {
+					Expect(vm.Name).To(HavePrefix(rs.Name + "-"))
+					Expect(vm.Labels).To(HaveKeyWithValue("app", "dummy"))
+					Expect(vm.Labels).To(HaveKeyWithValue(vmopv1.VirtualMachineReplicaSetNameLabel, rs.Name))
+					Expect(vm.Spec.ClassName).To(Equal(rs.Spec.Template.Spec.ClassName))
+					Expect(vm.Spec.ImageName).To(Equal(rs.Spec.Template.Spec.ImageName))
+				}
+
+				Expect(rs.Status.Replicas).To(BeEquivalentTo(3))
+				Expect(rs.Status.ReadyReplicas).To(BeEquivalentTo(0))
+				Expect(rs.Status.Selector).To(Equal("app=dummy"))
+				c := conditions.Get(rs, vmopv1.VirtualMachineReplicaSetConditionReplicasReady)
+				Expect(c).ToNot(BeNil())
+				Expect(c.Status).To(Equal(metav1.ConditionFalse))
+				Expect(c.Reason).To(Equal(vmopv1.VirtualMachineReplicaSetReplicasNotReadyReason))
+			})
+
+			It("does not create more replicas until the created replicas are observed", func() {
+				Expect(reconciler.ReconcileNormal(rsCtx)).To(Succeed())
+				replicas := getReplicas()
+				Expect(replicas).To(HaveLen(3))
+
+				// Simulate a cache that does not yet have one of the created VMs.
+				Expect(ctx.Client.Delete(ctx, &replicas[0])).To(Succeed())
+
+				Expect(reconciler.ReconcileNormal(rsCtx)).To(Succeed())
+				Expect(getReplicas()).To(HaveLen(2))
+				Expect(rs.Status.Replicas).To(BeEquivalentTo(2))
+			})
+
+			When("the template has a host name", func() {
+				BeforeEach(func() {
+					rs.Spec.Template.Spec.Network = &vmopv1.VirtualMachineNetworkSpec{
+						HostName: "dummy-host",
+					}
+				})
+
+				It("gives each replica a host name derived from its name", func() {
+					Expect(reconciler.ReconcileNormal(rsCtx)).To(Succeed())
+
+					replicas := getReplicas()
+					Expect(replicas).To(HaveLen(3))
+					for _, vm := range replicas {
+						Expect(vm.Spec.Network).ToNot(BeNil())
+						Expect(vm.Spec.Network.HostName).To(Equal(vm.Name))
+					}
+				})
+			})
+
+			When("a resource policy and cluster module group are specified", func() {
+				BeforeEach(func() {
+					rs.Spec.ResourcePolicyName = "dummy-policy"
+					rs.Spec.ClusterModuleGroup = "dummy-group"
+				})
+
+				It("places the replicas in the resource policy's cluster module", func() {
+					Expect(reconciler.ReconcileNormal(rsCtx)).To(Succeed())
+
+					replicas := getReplicas()
+					Expect(replicas).To(HaveLen(3))
+					for _, vm := range replicas {
+						Expect(vm.Spec.Reserved).ToNot(BeNil())
+						Expect(vm.Spec.Reserved.ResourcePolicyName).To(Equal("dummy-policy"))
+						Expect(vm.Annotations).To(HaveKeyWithValue(pkg.ClusterModuleNameKey, "dummy-group"))
+					}
+				})
+			})
+		})
+
+		When("there are more replicas than desired", func() {
+			BeforeEach(func() {
+				rs.Spec.Replicas = pointer.Int32(1)
+
+				older := newReplica("older", true, true)
+				older.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Hour))
+				newer := newReplica("newer", true, true)
+				newer.CreationTimestamp = metav1.NewTime(time.Now())
+				notReady := newReplica("not-ready", true, false)
+				notCreated := newReplica("not-created", false, false)
+				initObjects = append(initObjects, older, newer, notReady, notCreated)
+			})
+
+			It("deletes the not created, not ready and then newest replicas", func() {
+				Expect(reconciler.ReconcileNormal(rsCtx)).To(Succeed())
+
+				replicas := getReplicas()
+				Expect(replicas).To(HaveLen(1))
+				Expect(replicas[0].Name).To(Equal("older"))
+
+				Expect(rs.Status.Replicas).To(BeEquivalentTo(1))
+				Expect(rs.Status.ReadyReplicas).To(BeEquivalentTo(1))
+				Expect(conditions.IsTrue(rs, vmopv1.VirtualMachineReplicaSetConditionReplicasReady)).To(BeTrue())
+			})
+
+			It("does not delete more replicas until the deleted replicas are observed", func() {
+				Expect(reconciler.ReconcileNormal(rsCtx)).To(Succeed())
+				Expect(getReplicas()).To(HaveLen(1))
+
+				// Simulate a cache that still has one of the deleted VMs.
+				Expect(ctx.Client.Create(ctx, newReplica("newer", true, true))).To(Succeed())
+
+				Expect(reconciler.ReconcileNormal(rsCtx)).To(Succeed())
+				Expect(getReplicas()).To(HaveLen(2))
+			})
+		})
+
+		When("the replicas have a readiness probe", func() {
+			BeforeEach(func() {
+				rs.Spec.Replicas = pointer.Int32(2)
+				rs.Spec.Template.Spec.ReadinessProbe = &vmopv1.VirtualMachineReadinessProbeSpec{
+					GuestHeartbeat: &vmopv1.GuestHeartbeatAction{},
+				}
+
+				ready := newReplica("ready", true, true)
+				conditions.MarkTrue(ready, vmopv1.ReadyConditionType)
+				notReady := newReplica("not-ready", true, true)
+				conditions.MarkFalse(notReady, vmopv1.ReadyConditionType, "NotReady", "")
+				initObjects = append(initObjects, ready, notReady)
+			})
+
+			It("counts the replicas the readiness probe marked ready", func() {
+				Expect(reconciler.ReconcileNormal(rsCtx)).To(Succeed())
+
+				Expect(getReplicas()).To(HaveLen(2))
+				Expect(rs.Status.Replicas).To(BeEquivalentTo(2))
+				Expect(rs.Status.ReadyReplicas).To(BeEquivalentTo(1))
+				Expect(conditions.IsTrue(rs, vmopv1.VirtualMachineReplicaSetConditionReplicasReady)).To(BeFalse())
+			})
+		})
+
+		When("a selected VM is not controlled by the replica set", func() {
+			BeforeEach(func() {
+				rs.Spec.Replicas = pointer.Int32(1)
+
+				vm := newReplica("other", true, true)
+				vm.OwnerReferences = nil
+				initObjects = append(initObjects, vm)
+			})
+
+			It("does not count the VM as a replica", func() {
+				Expect(reconciler.ReconcileNormal(rsCtx)).To(Succeed())
+
+				replicas := getReplicas()
+				Expect(replicas).To(HaveLen(1))
+				Expect(replicas[0].Name).ToNot(Equal("other"))
+			})
+		})
+	})
+}
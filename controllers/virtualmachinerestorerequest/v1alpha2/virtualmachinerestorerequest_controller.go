@@ -0,0 +1,417 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha2
+
+import (
+	goctx "context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	conditions "github.com/vmware-tanzu/vm-operator/pkg/conditions2"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	patch "github.com/vmware-tanzu/vm-operator/pkg/patch2"
+	"github.com/vmware-tanzu/vm-operator/pkg/record"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
+)
+
+// AddToManager adds this package's controller to the provided manager.
+func AddToManager(ctx *context.ControllerManagerContext, mgr manager.Manager) error {
+	var (
+		controlledType     = &vmopv1.VirtualMachineRestoreRequest{}
+		controlledTypeName = reflect.TypeOf(controlledType).Elem().Name()
+
+		controllerNameShort = fmt.Sprintf("%s-controller", strings.ToLower(controlledTypeName))
+		controllerNameLong  = fmt.Sprintf("%s/%s/%s", ctx.Namespace, ctx.Name, controllerNameShort)
+	)
+
+	r := NewReconciler(
+		mgr.GetClient(),
+		ctrl.Log.WithName("controllers").WithName(controlledTypeName),
+		record.New(mgr.GetEventRecorderFor(controllerNameLong)),
+		ctx.VMProviderA2,
+	)
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(controlledType).
+		WithOptions(controller.Options{MaxConcurrentReconciles: ctx.MaxConcurrentReconciles}).
+		Complete(r)
+}
+
+func NewReconciler(
+	client client.Client,
+	logger logr.Logger,
+	recorder record.Recorder,
+	vmProvider vmprovider.VirtualMachineProviderInterfaceA2) *Reconciler {
+
+	return &Reconciler{
+		Client:     client,
+		Logger:     logger,
+		Recorder:   recorder,
+		VMProvider: vmProvider,
+	}
+}
+
+// Reconciler reconciles a VirtualMachineRestoreRequest object.
+type Reconciler struct {
+	client.Client
+	Logger     logr.Logger
+	Recorder   record.Recorder
+	VMProvider vmprovider.VirtualMachineProviderInterfaceA2
+}
+
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachinerestorerequests,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachinerestorerequests/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachines,verbs=get;list;watch;create
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create
+// +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch
+
+func (r *Reconciler) Reconcile(ctx goctx.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
+	restoreReq := &vmopv1.VirtualMachineRestoreRequest{}
+	if err := r.Get(ctx, req.NamespacedName, restoreReq); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if !restoreReq.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	restoreCtx := &context.VirtualMachineRestoreRequestContextA2{
+		Context:        ctx,
+		Logger:         ctrl.Log.WithName("VirtualMachineRestoreRequest").WithValues("name", restoreReq.NamespacedName()),
+		RestoreRequest: restoreReq,
+	}
+
+	patchHelper, err := patch.NewHelper(restoreReq, r.Client)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to init patch helper for %s", restoreCtx.String())
+	}
+	defer func() {
+		if err := patchHelper.Patch(ctx, restoreReq); err != nil {
+			if reterr == nil {
+				reterr = err
+			}
+			restoreCtx.Logger.Error(err, "patch failed")
+		}
+	}()
+
+	return ctrl.Result{}, r.ReconcileNormal(restoreCtx)
+}
+
+// ReconcileNormal restores the VMs that have backup data on the underlying
+// infrastructure but no VirtualMachine resource. A request is only processed
+// once: after it is complete, a new request must be created to restore VMs
+// again.
+func (r *Reconciler) ReconcileNormal(ctx *context.VirtualMachineRestoreRequestContextA2) error {
+	restoreReq := ctx.RestoreRequest
+	if conditions.IsTrue(restoreReq, vmopv1.VirtualMachineRestoreRequestConditionComplete) {
+		return nil
+	}
+
+	ctx.Logger.Info("Reconciling VirtualMachineRestoreRequest")
+	defer func() {
+		ctx.Logger.Info("Finished Reconciling VirtualMachineRestoreRequest")
+	}()
+
+	if restoreReq.Status.StartTime.IsZero() {
+		restoreReq.Status.StartTime = metav1.Now()
+	}
+
+	backups, err := r.VMProvider.ListVirtualMachineBackups(ctx, restoreReq.Namespace)
+	if err != nil {
+		ctx.Logger.Error(err, "Failed to list VM backups")
+		return err
+	}
+
+	names := map[string]struct{}{}
+	for _, name := range restoreReq.Spec.VirtualMachineNames {
+		names[name] = struct{}{}
+	}
+
+	found := map[string]struct{}{}
+	for i := range backups {
+		backup := &backups[i]
+
+		name := backup.Name
+		if backup.VM != nil {
+			name = backup.VM.Name
+		}
+		if len(names) > 0 {
+			if _, ok := names[name]; !ok {
+				continue
+			}
+		}
+		found[name] = struct{}{}
+
+		// A VM that was already restored by a previous reconcile of this
+		// request is not restored again.
+		if status := getRestoreStatus(restoreReq, name); status != nil && status.Result != vmopv1.VirtualMachineRestoreResultFailed {
+			continue
+		}
+
+		status, err := r.restoreVM(ctx, name, backup)
+		if err != nil {
+			ctx.Logger.Error(err, "Failed to restore VM", "vmName", name)
+			return err
+		}
+		if status != nil {
+			setRestoreStatus(restoreReq, *status)
+		}
+	}
+
+	for _, name := range restoreReq.Spec.VirtualMachineNames {
+		if _, ok := found[name]; !ok {
+			setRestoreStatus(restoreReq, vmopv1.VirtualMachineRestoreStatus{
+				Name:    name,
+				Result:  vmopv1.VirtualMachineRestoreResultFailed,
+				Message: "VM not found on the underlying infrastructure",
+			})
+		}
+	}
+
+	markRestoredCondition(restoreReq)
+	conditions.MarkTrue(restoreReq, vmopv1.VirtualMachineRestoreRequestConditionComplete)
+	restoreReq.Status.CompletionTime = metav1.Now()
+
+	var restoreErr error
+	if c := conditions.Get(restoreReq, vmopv1.VirtualMachineRestoreRequestConditionRestored); c != nil && c.Status != metav1.ConditionTrue {
+		restoreErr = errors.New(c.Message)
+	}
+	r.Recorder.EmitEvent(restoreReq, "Restore", restoreErr, false)
+
+	return nil
+}
+
+// restoreVM restores the VirtualMachine resource, and the resources it
+// references, from the VM's backup data. The returned status is nil when the
+// VM already has a matching VirtualMachine resource. An error is returned
+// when the restore should be retried.
+func (r *Reconciler) restoreVM(
+	ctx *context.VirtualMachineRestoreRequestContextA2,
+	name string,
+	backup *vmprovider.VirtualMachineBackupA2) (*vmopv1.VirtualMachineRestoreStatus, error) {
+
+	status := &vmopv1.VirtualMachineRestoreStatus{
+		Name:     name,
+		UniqueID: backup.UniqueID,
+	}
+
+	namespace := ctx.RestoreRequest.Namespace
+
+	// A VM that still has its VirtualMachine resource does not need to be
+	// restored, whether or not it has backup data.
+	existingVM := &vmopv1.VirtualMachine{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, existingVM); err == nil {
+		if existingVM.Status.UniqueID == backup.UniqueID {
+			return nil, nil
+		}
+	} else if !apierrors.IsNotFound(err) {
+		return nil, err
+	} else {
+		existingVM = nil
+	}
+
+	if backup.Err != nil {
+		status.Result = vmopv1.VirtualMachineRestoreResultFailed
+		status.Message = backup.Err.Error()
+		return status, nil
+	}
+	if backup.VM == nil {
+		status.Result = vmopv1.VirtualMachineRestoreResultFailed
+		status.Message = "VM has no backup data"
+		return status, nil
+	}
+
+	if existingVM != nil {
+		status.Result = vmopv1.VirtualMachineRestoreResultConflict
+		status.Message = fmt.Sprintf("a different VirtualMachine %q already exists", name)
+		return status, nil
+	}
+
+	vm := &vmopv1.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   namespace,
+			Labels:      backup.VM.Labels,
+			Annotations: backup.VM.Annotations,
+		},
+		Spec: *backup.VM.Spec.DeepCopy(),
+	}
+	// Keep the Cloud-Init instance ID of the VM so the guest is not
+	// customized again.
+	if backup.CloudInitInstanceID != "" {
+		if vm.Annotations == nil {
+			vm.Annotations = map[string]string{}
+		}
+		vm.Annotations[vmopv1.InstanceIDAnnotation] = backup.CloudInitInstanceID
+	}
+	// Do not replay an operation that was requested on the VM before the backup.
+	vm.Spec.NextRestartTime = ""
+	vm.Spec.CurrentSnapshotName = ""
+
+	var missing []string
+
+	if secretName := bootstrapSecretName(vm); secretName != "" {
+		restored, err := r.restoreBootstrapSecret(ctx, secretName, backup.BootstrapData)
+		if err != nil {
+			return nil, err
+		}
+		if !restored {
+			missing = append(missing, fmt.Sprintf("bootstrap Secret %q", secretName))
+		}
+	}
+
+	for _, vol := range vm.Spec.Volumes {
+		pvc := vol.PersistentVolumeClaim
+		if pvc == nil || pvc.InstanceVolumeClaim != nil {
+			continue
+		}
+
+		key := client.ObjectKey{Namespace: namespace, Name: pvc.ClaimName}
+		if err := r.Get(ctx, key, &corev1.PersistentVolumeClaim{}); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return nil, err
+			}
+			msg := fmt.Sprintf("PersistentVolumeClaim %q", pvc.ClaimName)
+			if fileName := backup.DiskFileNames[pvc.ClaimName]; fileName != "" {
+				msg += fmt.Sprintf(" (disk %q)", fileName)
+			}
+			missing = append(missing, msg)
+		}
+	}
+
+	if err := r.Create(ctx, vm); err != nil {
+		return nil, err
+	}
+
+	if len(missing) > 0 {
+		status.Result = vmopv1.VirtualMachineRestoreResultPartiallyRestored
+		status.Message = "missing " + strings.Join(missing, ", ")
+	} else {
+		status.Result = vmopv1.VirtualMachineRestoreResultRestored
+	}
+
+	return status, nil
+}
+
+// restoreBootstrapSecret creates the VM's bootstrap Secret from its backup
+// data when the Secret does not exist. It returns false if the Secret does
+// not exist and there is no data to restore it from.
+func (r *Reconciler) restoreBootstrapSecret(
+	ctx *context.VirtualMachineRestoreRequestContextA2,
+	name string,
+	data map[string]string) (bool, error) {
+
+	namespace := ctx.RestoreRequest.Namespace
+
+	if err := r.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, &corev1.Secret{}); err == nil {
+		return true, nil
+	} else if !apierrors.IsNotFound(err) {
+		return false, err
+	}
+
+	if len(data) == 0 {
+		return false, nil
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		StringData: data,
+	}
+	if err := r.Create(ctx, secret); err != nil && !apierrors.IsAlreadyExists(err) {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// bootstrapSecretName returns the name of the Secret that contains the raw
+// bootstrap data of the VM, which is the data included in the VM's backup.
+func bootstrapSecretName(vm *vmopv1.VirtualMachine) string {
+	bootstrap := vm.Spec.Bootstrap
+	if bootstrap == nil {
+		return ""
+	}
+
+	if ci := bootstrap.CloudInit; ci != nil && ci.RawCloudConfig != nil {
+		return ci.RawCloudConfig.Name
+	}
+	if sp := bootstrap.Sysprep; sp != nil && sp.RawSysprep != nil {
+		return sp.RawSysprep.Name
+	}
+
+	return ""
+}
+
+func getRestoreStatus(
+	restoreReq *vmopv1.VirtualMachineRestoreRequest,
+	name string) *vmopv1.VirtualMachineRestoreStatus {
+
+	for i := range restoreReq.Status.VirtualMachines {
+		if restoreReq.Status.VirtualMachines[i].Name == name {
+			return &restoreReq.Status.VirtualMachines[i]
+		}
+	}
+	return nil
+}
+
+func setRestoreStatus(
+	restoreReq *vmopv1.VirtualMachineRestoreRequest,
+	status vmopv1.VirtualMachineRestoreStatus) {
+
+	if existing := getRestoreStatus(restoreReq, status.Name); existing != nil {
+		*existing = status
+		return
+	}
+
+	restoreReq.Status.VirtualMachines = append(restoreReq.Status.VirtualMachines, status)
+	sort.Slice(restoreReq.Status.VirtualMachines, func(i, j int) bool {
+		return restoreReq.Status.VirtualMachines[i].Name < restoreReq.Status.VirtualMachines[j].Name
+	})
+}
+
+// markRestoredCondition sets the Restored condition from the result of
+// restoring each VM. The reason is that of the most severe result.
+func markRestoredCondition(restoreReq *vmopv1.VirtualMachineRestoreRequest) {
+	var failed, conflict, partial []string
+	for _, status := range restoreReq.Status.VirtualMachines {
+		switch status.Result {
+		case vmopv1.VirtualMachineRestoreResultFailed:
+			failed = append(failed, status.Name)
+		case vmopv1.VirtualMachineRestoreResultConflict:
+			conflict = append(conflict, status.Name)
+		case vmopv1.VirtualMachineRestoreResultPartiallyRestored:
+			partial = append(partial, status.Name)
+		}
+	}
+
+	switch {
+	case len(failed) > 0:
+		conditions.MarkFalse(restoreReq, vmopv1.VirtualMachineRestoreRequestConditionRestored,
+			vmopv1.VirtualMachineRestoreFailedReason, "failed to restore VMs: %s", strings.Join(failed, ", "))
+	case len(conflict) > 0:
+		conditions.MarkFalse(restoreReq, vmopv1.VirtualMachineRestoreRequestConditionRestored,
+			vmopv1.VirtualMachineRestoreConflictReason, "conflicting VMs: %s", strings.Join(conflict, ", "))
+	case len(partial) > 0:
+		conditions.MarkFalse(restoreReq, vmopv1.VirtualMachineRestoreRequestConditionRestored,
+			vmopv1.VirtualMachineRestorePartiallyRestoredReason, "partially restored VMs: %s", strings.Join(partial, ", "))
+	default:
+		conditions.MarkTrue(restoreReq, vmopv1.VirtualMachineRestoreRequestConditionRestored)
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha2_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	conditions "github.com/vmware-tanzu/vm-operator/pkg/conditions2"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func intgTests() {
+	Describe("Invoking VirtualMachineRestoreRequest controller tests", intgTestsReconcile)
+}
+
+func intgTestsReconcile() {
+	var (
+		ctx        *builder.IntegrationTestContext
+		restoreReq *vmopv1.VirtualMachineRestoreRequest
+	)
+
+	getRestoreRequest := func(ctx *builder.IntegrationTestContext, objKey client.ObjectKey) *vmopv1.VirtualMachineRestoreRequest {
+		restoreReq := &vmopv1.VirtualMachineRestoreRequest{}
+		if err := ctx.Client.Get(ctx, objKey, restoreReq); err != nil {
+			return nil
+		}
+		return restoreReq
+	}
+
+	BeforeEach(func() {
+		ctx = suite.NewIntegrationTestContext()
+		restoreReq = builder.DummyVirtualMachineRestoreRequest(ctx.Namespace, "dummy-restore")
+	})
+
+	AfterEach(func() {
+		ctx.AfterEach()
+		ctx = nil
+		fakeVMProvider.Reset()
+	})
+
+	Context("Reconcile", func() {
+		BeforeEach(func() {
+			namespace := ctx.Namespace
+
+			fakeVMProvider.Lock()
+			fakeVMProvider.ListVirtualMachineBackupsFn = func(_ context.Context, _ string) ([]vmprovider.VirtualMachineBackupA2, error) {
+				return []vmprovider.VirtualMachineBackupA2{
+					{
+						Name:     "dummy-vm",
+						UniqueID: "vm-42",
+						VM: &vmopv1.VirtualMachine{
+							ObjectMeta: metav1.ObjectMeta{
+								Name:      "dummy-vm",
+								Namespace: namespace,
+							},
+							Spec: vmopv1.VirtualMachineSpec{
+								ImageName:  "dummy-image",
+								ClassName:  "dummy-class",
+								PowerState: vmopv1.VirtualMachinePowerStateOn,
+							},
+						},
+					},
+				}, nil
+			}
+			fakeVMProvider.Unlock()
+
+			Expect(ctx.Client.Create(ctx, restoreReq)).To(Succeed())
+		})
+
+		It("restores the VMs", func() {
+			objKey := client.ObjectKeyFromObject(restoreReq)
+
+			Eventually(func() bool {
+				if obj := getRestoreRequest(ctx, objKey); obj != nil {
+					return conditions.IsTrue(obj, vmopv1.VirtualMachineRestoreRequestConditionComplete)
+				}
+				return false
+			}).Should(BeTrue())
+
+			obj := getRestoreRequest(ctx, objKey)
+			Expect(conditions.IsTrue(obj, vmopv1.VirtualMachineRestoreRequestConditionRestored)).To(BeTrue())
+			Expect(obj.Status.VirtualMachines).To(HaveLen(1))
+			Expect(obj.Status.VirtualMachines[0].Result).To(Equal(vmopv1.VirtualMachineRestoreResultRestored))
+
+			vm := &vmopv1.VirtualMachine{}
+			Expect(ctx.Client.Get(ctx, client.ObjectKey{Namespace: ctx.Namespace, Name: "dummy-vm"}, vm)).To(Succeed())
+		})
+	})
+}
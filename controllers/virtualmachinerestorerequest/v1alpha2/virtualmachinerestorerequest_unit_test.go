@@ -0,0 +1,375 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha2_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	"github.com/vmware-tanzu/vm-operator/api/v1alpha2/common"
+	virtualmachinerestorerequest "github.com/vmware-tanzu/vm-operator/controllers/virtualmachinerestorerequest/v1alpha2"
+	conditions "github.com/vmware-tanzu/vm-operator/pkg/conditions2"
+	vmopContext "github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
+	providerfake "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/fake"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func unitTests() {
+	Describe("Invoking VirtualMachineRestoreRequest Reconcile", unitTestsReconcile)
+}
+
+func unitTestsReconcile() {
+	const (
+		namespace = "dummy-ns"
+	)
+
+	var (
+		initObjects []client.Object
+		ctx         *builder.UnitTestContextForController
+
+		reconciler *virtualmachinerestorerequest.Reconciler
+		restoreCtx *vmopContext.VirtualMachineRestoreRequestContextA2
+		restoreReq *vmopv1.VirtualMachineRestoreRequest
+		backups    []vmprovider.VirtualMachineBackupA2
+	)
+
+	newBackup := func(name, uniqueID string) vmprovider.VirtualMachineBackupA2 {
+		return vmprovider.VirtualMachineBackupA2{
+			Name:     name,
+			UniqueID: uniqueID,
+			VM: &vmopv1.VirtualMachine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+					UID:       "old-uid",
+					Labels:    map[string]string{"env": "dev"},
+				},
+				Spec: vmopv1.VirtualMachineSpec{
+					ImageName:       "dummy-image",
+					ClassName:       "dummy-class",
+					PowerState:      vmopv1.VirtualMachinePowerStateOn,
+					NextRestartTime: "2023-10-16T08:00:00Z",
+				},
+			},
+			CloudInitInstanceID: "my-instance-id",
+		}
+	}
+
+	getVM := func(name string) *vmopv1.VirtualMachine {
+		vm := &vmopv1.VirtualMachine{}
+		if err := ctx.Client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, vm); err != nil {
+			return nil
+		}
+		return vm
+	}
+
+	getRestoreStatus := func(name string) *vmopv1.VirtualMachineRestoreStatus {
+		for i := range restoreReq.Status.VirtualMachines {
+			if restoreReq.Status.VirtualMachines[i].Name == name {
+				return &restoreReq.Status.VirtualMachines[i]
+			}
+		}
+		return nil
+	}
+
+	BeforeEach(func() {
+		restoreReq = builder.DummyVirtualMachineRestoreRequest(namespace, "dummy-restore")
+		backups = []vmprovider.VirtualMachineBackupA2{newBackup("dummy-vm", "vm-42")}
+	})
+
+	JustBeforeEach(func() {
+		ctx = suite.NewUnitTestContextForController(initObjects...)
+		reconciler = virtualmachinerestorerequest.NewReconciler(
+			ctx.Client,
+			ctx.Logger,
+			ctx.Recorder,
+			ctx.VMProviderA2,
+		)
+		fakeVMProvider = ctx.VMProviderA2.(*providerfake.VMProviderA2)
+		fakeVMProvider.ListVirtualMachineBackupsFn = func(_ context.Context, ns string) ([]vmprovider.VirtualMachineBackupA2, error) {
+			Expect(ns).To(Equal(namespace))
+			return backups, nil
+		}
+
+		restoreCtx = &vmopContext.VirtualMachineRestoreRequestContextA2{
+			Context:        ctx,
+			Logger:         ctx.Logger.WithName(restoreReq.Name),
+			RestoreRequest: restoreReq,
+		}
+	})
+
+	AfterEach(func() {
+		ctx.AfterEach()
+		ctx = nil
+		initObjects = nil
+		reconciler = nil
+	})
+
+	Context("ReconcileNormal", func() {
+
+		It("restores the VM", func() {
+			Expect(reconciler.ReconcileNormal(restoreCtx)).To(Succeed())
+
+			vm := getVM("dummy-vm")
+			Expect(vm).ToNot(BeNil())
+			Expect(vm.UID).ToNot(BeEquivalentTo("old-uid"))
+			Expect(vm.Labels).To(HaveKeyWithValue("env", "dev"))
+			Expect(vm.Annotations).To(HaveKeyWithValue(vmopv1.InstanceIDAnnotation, "my-instance-id"))
+			Expect(vm.Spec.ImageName).To(Equal("dummy-image"))
+			Expect(vm.Spec.NextRestartTime).To(BeEmpty())
+
+			status := getRestoreStatus("dummy-vm")
+			Expect(status).ToNot(BeNil())
+			Expect(status.UniqueID).To(Equal("vm-42"))
+			Expect(status.Result).To(Equal(vmopv1.VirtualMachineRestoreResultRestored))
+
+			Expect(restoreReq.Status.StartTime.IsZero()).To(BeFalse())
+			Expect(restoreReq.Status.CompletionTime.IsZero()).To(BeFalse())
+			Expect(conditions.IsTrue(restoreReq, vmopv1.VirtualMachineRestoreRequestConditionRestored)).To(BeTrue())
+			Expect(conditions.IsTrue(restoreReq, vmopv1.VirtualMachineRestoreRequestConditionComplete)).To(BeTrue())
+		})
+
+		When("the request is complete", func() {
+			BeforeEach(func() {
+				conditions.MarkTrue(restoreReq, vmopv1.VirtualMachineRestoreRequestConditionComplete)
+			})
+
+			It("does not restore the VM", func() {
+				Expect(reconciler.ReconcileNormal(restoreCtx)).To(Succeed())
+				Expect(getVM("dummy-vm")).To(BeNil())
+			})
+		})
+
+		When("listing the backups fails", func() {
+			JustBeforeEach(func() {
+				fakeVMProvider.ListVirtualMachineBackupsFn = func(_ context.Context, _ string) ([]vmprovider.VirtualMachineBackupA2, error) {
+					return nil, errors.New("fake")
+				}
+			})
+
+			It("returns an error", func() {
+				Expect(reconciler.ReconcileNormal(restoreCtx)).To(MatchError("fake"))
+				Expect(conditions.Has(restoreReq, vmopv1.VirtualMachineRestoreRequestConditionComplete)).To(BeFalse())
+			})
+		})
+
+		When("the VM names are specified", func() {
+			BeforeEach(func() {
+				backups = append(backups, newBackup("other-vm", "vm-43"))
+				restoreReq.Spec.VirtualMachineNames = []string{"other-vm", "missing-vm"}
+			})
+
+			It("restores only the specified VMs", func() {
+				Expect(reconciler.ReconcileNormal(restoreCtx)).To(Succeed())
+
+				Expect(getVM("dummy-vm")).To(BeNil())
+				Expect(getVM("other-vm")).ToNot(BeNil())
+				Expect(getRestoreStatus("dummy-vm")).To(BeNil())
+
+				status := getRestoreStatus("missing-vm")
+				Expect(status).ToNot(BeNil())
+				Expect(status.Result).To(Equal(vmopv1.VirtualMachineRestoreResultFailed))
+
+				c := conditions.Get(restoreReq, vmopv1.VirtualMachineRestoreRequestConditionRestored)
+				Expect(c).ToNot(BeNil())
+				Expect(c.Status).To(Equal(metav1.ConditionFalse))
+				Expect(c.Reason).To(Equal(vmopv1.VirtualMachineRestoreFailedReason))
+			})
+		})
+
+		When("the VM has no backup data", func() {
+			BeforeEach(func() {
+				backups[0].VM = nil
+			})
+
+			It("marks the VM as failed", func() {
+				Expect(reconciler.ReconcileNormal(restoreCtx)).To(Succeed())
+
+				status := getRestoreStatus("dummy-vm")
+				Expect(status).ToNot(BeNil())
+				Expect(status.Result).To(Equal(vmopv1.VirtualMachineRestoreResultFailed))
+				Expect(status.Message).To(ContainSubstring("no backup data"))
+				Expect(conditions.IsTrue(restoreReq, vmopv1.VirtualMachineRestoreRequestConditionComplete)).To(BeTrue())
+			})
+		})
+
+		When("the VM backup data is invalid", func() {
+			BeforeEach(func() {
+				backups[0].VM = nil
+				backups[0].Err = errors.New("invalid data")
+			})
+
+			It("marks the VM as failed", func() {
+				Expect(reconciler.ReconcileNormal(restoreCtx)).To(Succeed())
+
+				status := getRestoreStatus("dummy-vm")
+				Expect(status).ToNot(BeNil())
+				Expect(status.Result).To(Equal(vmopv1.VirtualMachineRestoreResultFailed))
+				Expect(status.Message).To(Equal("invalid data"))
+			})
+		})
+
+		When("the VM already exists", func() {
+			var existingVM *vmopv1.VirtualMachine
+
+			BeforeEach(func() {
+				existingVM = &vmopv1.VirtualMachine{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "dummy-vm",
+						Namespace: namespace,
+					},
+				}
+				initObjects = append(initObjects, existingVM)
+			})
+
+			When("the VM is the same VM", func() {
+				BeforeEach(func() {
+					existingVM.Status.UniqueID = "vm-42"
+				})
+
+				It("skips the VM", func() {
+					Expect(reconciler.ReconcileNormal(restoreCtx)).To(Succeed())
+					Expect(getRestoreStatus("dummy-vm")).To(BeNil())
+					Expect(conditions.IsTrue(restoreReq, vmopv1.VirtualMachineRestoreRequestConditionRestored)).To(BeTrue())
+				})
+
+				When("the VM has no backup data", func() {
+					BeforeEach(func() {
+						backups[0].VM = nil
+					})
+
+					It("skips the VM", func() {
+						Expect(reconciler.ReconcileNormal(restoreCtx)).To(Succeed())
+						Expect(getRestoreStatus("dummy-vm")).To(BeNil())
+						Expect(conditions.IsTrue(restoreReq, vmopv1.VirtualMachineRestoreRequestConditionRestored)).To(BeTrue())
+					})
+				})
+			})
+
+			When("the VM is a different VM", func() {
+				BeforeEach(func() {
+					existingVM.Status.UniqueID = "vm-99"
+				})
+
+				It("reports a conflict", func() {
+					Expect(reconciler.ReconcileNormal(restoreCtx)).To(Succeed())
+
+					status := getRestoreStatus("dummy-vm")
+					Expect(status).ToNot(BeNil())
+					Expect(status.Result).To(Equal(vmopv1.VirtualMachineRestoreResultConflict))
+
+					c := conditions.Get(restoreReq, vmopv1.VirtualMachineRestoreRequestConditionRestored)
+					Expect(c).ToNot(BeNil())
+					Expect(c.Status).To(Equal(metav1.ConditionFalse))
+					Expect(c.Reason).To(Equal(vmopv1.VirtualMachineRestoreConflictReason))
+				})
+			})
+		})
+
+		When("the VM has a bootstrap Secret", func() {
+			BeforeEach(func() {
+				backups[0].VM.Spec.Bootstrap = &vmopv1.VirtualMachineBootstrapSpec{
+					CloudInit: &vmopv1.VirtualMachineBootstrapCloudInitSpec{
+						RawCloudConfig: &common.SecretKeySelector{
+							Name: "my-bootstrap-data",
+							Key:  "user-data",
+						},
+					},
+				}
+			})
+
+			When("the Secret was backed up", func() {
+				BeforeEach(func() {
+					backups[0].BootstrapData = map[string]string{"user-data": "my-user-data"}
+				})
+
+				It("restores the Secret", func() {
+					Expect(reconciler.ReconcileNormal(restoreCtx)).To(Succeed())
+
+					secret := &corev1.Secret{}
+					Expect(ctx.Client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: "my-bootstrap-data"}, secret)).To(Succeed())
+					Expect(secret.StringData).To(HaveKeyWithValue("user-data", "my-user-data"))
+
+					status := getRestoreStatus("dummy-vm")
+					Expect(status).ToNot(BeNil())
+					Expect(status.Result).To(Equal(vmopv1.VirtualMachineRestoreResultRestored))
+				})
+			})
+
+			When("the Secret was not backed up", func() {
+				It("partially restores the VM", func() {
+					Expect(reconciler.ReconcileNormal(restoreCtx)).To(Succeed())
+					Expect(getVM("dummy-vm")).ToNot(BeNil())
+
+					status := getRestoreStatus("dummy-vm")
+					Expect(status).ToNot(BeNil())
+					Expect(status.Result).To(Equal(vmopv1.VirtualMachineRestoreResultPartiallyRestored))
+					Expect(status.Message).To(ContainSubstring(`bootstrap Secret "my-bootstrap-data"`))
+
+					c := conditions.Get(restoreReq, vmopv1.VirtualMachineRestoreRequestConditionRestored)
+					Expect(c).ToNot(BeNil())
+					Expect(c.Reason).To(Equal(vmopv1.VirtualMachineRestorePartiallyRestoredReason))
+				})
+			})
+		})
+
+		When("the VM has a PVC", func() {
+			BeforeEach(func() {
+				backups[0].VM.Spec.Volumes = []vmopv1.VirtualMachineVolume{
+					{
+						Name: "my-vol",
+						VirtualMachineVolumeSource: vmopv1.VirtualMachineVolumeSource{
+							PersistentVolumeClaim: &vmopv1.PersistentVolumeClaimVolumeSource{
+								PersistentVolumeClaimVolumeSource: corev1.PersistentVolumeClaimVolumeSource{
+									ClaimName: "my-pvc",
+								},
+							},
+						},
+					},
+				}
+				backups[0].DiskFileNames = map[string]string{"my-pvc": "[ds] vm/disk.vmdk"}
+			})
+
+			When("the PVC exists", func() {
+				BeforeEach(func() {
+					initObjects = append(initObjects, &corev1.PersistentVolumeClaim{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "my-pvc",
+							Namespace: namespace,
+						},
+					})
+				})
+
+				It("restores the VM", func() {
+					Expect(reconciler.ReconcileNormal(restoreCtx)).To(Succeed())
+
+					status := getRestoreStatus("dummy-vm")
+					Expect(status).ToNot(BeNil())
+					Expect(status.Result).To(Equal(vmopv1.VirtualMachineRestoreResultRestored))
+				})
+			})
+
+			When("the PVC does not exist", func() {
+				It("partially restores the VM", func() {
+					Expect(reconciler.ReconcileNormal(restoreCtx)).To(Succeed())
+					Expect(getVM("dummy-vm")).ToNot(BeNil())
+
+					status := getRestoreStatus("dummy-vm")
+					Expect(status).ToNot(BeNil())
+					Expect(status.Result).To(Equal(vmopv1.VirtualMachineRestoreResultPartiallyRestored))
+					Expect(status.Message).To(ContainSubstring(`PersistentVolumeClaim "my-pvc" (disk "[ds] vm/disk.vmdk")`))
+				})
+			})
+		})
+	})
+}
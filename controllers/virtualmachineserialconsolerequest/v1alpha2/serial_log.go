@@ -0,0 +1,144 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha2
+
+import (
+	"bytes"
+	goctx "context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/serialconsole"
+)
+
+const (
+	// serialLogDialTimeout is how long to wait for the connection to the
+	// serial port.
+	serialLogDialTimeout = 10 * time.Second
+
+	// serialLogRetryDelay is how long to wait before connecting to the serial
+	// port again after the connection was closed.
+	serialLogRetryDelay = 10 * time.Second
+)
+
+// DialFunc connects to the serial console proxy and opens the serial console
+// with the given ticket.
+type DialFunc func(ctx goctx.Context, ticket string) (net.Conn, error)
+
+// LogBuffer keeps the last bytes written to it, up to a maximum size.
+type LogBuffer struct {
+	mu       sync.Mutex
+	data     []byte
+	maxBytes int
+}
+
+// NewLogBuffer returns a LogBuffer that keeps the last maxBytes written to it.
+func NewLogBuffer(maxBytes int) *LogBuffer {
+	return &LogBuffer{maxBytes: maxBytes}
+}
+
+func (b *LogBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.data = append(b.data, p...)
+	if over := len(b.data) - b.maxBytes; over > 0 {
+		b.data = append(b.data[:0], b.data[over:]...)
+	}
+	return len(p), nil
+}
+
+func (b *LogBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return string(b.data)
+}
+
+// serialLog writes the output of a VM's network-backed serial port into a
+// LogBuffer until it is stopped. The connection to the serial port is retried
+// when it is closed.
+type serialLog struct {
+	serviceURI string
+	ticket     string
+	buffer     *LogBuffer
+	cancel     goctx.CancelFunc
+
+	mu        sync.Mutex
+	connected bool
+	err       error
+}
+
+func (l *serialLog) setConnected(connected bool, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.connected = connected
+	l.err = err
+}
+
+// status returns whether the serial port is connected, and otherwise the
+// error the connection was closed with.
+func (l *serialLog) status() (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.connected, l.err
+}
+
+func (l *serialLog) run(ctx goctx.Context, logger logr.Logger, dial DialFunc) {
+	for {
+		err := l.stream(ctx, dial)
+		if ctx.Err() != nil {
+			return
+		}
+		logger.Info("Serial port connection closed", "serviceURI", l.serviceURI, "err", err)
+		l.setConnected(false, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(serialLogRetryDelay):
+		}
+	}
+}
+
+func (l *serialLog) stream(ctx goctx.Context, dial DialFunc) error {
+	dialCtx, cancel := goctx.WithTimeout(ctx, serialLogDialTimeout)
+	conn, err := dial(dialCtx, l.ticket)
+	cancel()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// Close the connection when stopped to unblock the read.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.Close()
+		case <-done:
+		}
+	}()
+
+	l.setConnected(true, nil)
+
+	buf := make([]byte, 4096)
+	for first := true; ; first = false {
+		n, err := conn.Read(buf)
+		if first && bytes.HasPrefix(buf[:n], []byte(serialconsole.ErrInvalidTicket.Error())) {
+			// The log ticket is not valid until the request is updated
+			// with its hash.
+			return serialconsole.ErrInvalidTicket
+		}
+		if n > 0 {
+			_, _ = l.buffer.Write(buf[:n])
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,573 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha2
+
+import (
+	goctx "context"
+	"fmt"
+	"net"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	conditions "github.com/vmware-tanzu/vm-operator/pkg/conditions2"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/lib"
+	patch "github.com/vmware-tanzu/vm-operator/pkg/patch2"
+	"github.com/vmware-tanzu/vm-operator/pkg/record"
+	"github.com/vmware-tanzu/vm-operator/pkg/serialconsole"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
+)
+
+const (
+	DefaultExpiryTime = time.Second * 120
+
+	// SerialConsoleOpenedReason is the reason of the Event recorded on a
+	// VirtualMachineSerialConsoleRequest when its serial console is opened.
+	SerialConsoleOpenedReason = "SerialConsoleOpened"
+
+	// requeueDelay is how long to wait before checking again whether the
+	// serial port can be added to the VM.
+	requeueDelay = 10 * time.Second
+
+	// logUpdateInterval is how often the log in the status is updated with
+	// the output of the serial port.
+	logUpdateInterval = 10 * time.Second
+
+	// defaultLogMaxBytes is the size of the log when the request does not
+	// specify one.
+	defaultLogMaxBytes = 64 * 1024
+
+	// serverCertName and serverKeyName are the names of the files of the
+	// operator's serving certificate and key that the proxy serves with.
+	serverCertName = "tls.crt"
+	serverKeyName  = "tls.key"
+)
+
+// AddToManager adds this package's controller to the provided manager.
+func AddToManager(ctx *context.ControllerManagerContext, mgr manager.Manager) error {
+	var (
+		controlledType     = &vmopv1.VirtualMachineSerialConsoleRequest{}
+		controlledTypeName = reflect.TypeOf(controlledType).Elem().Name()
+
+		controllerNameShort = fmt.Sprintf("%s-controller", strings.ToLower(controlledTypeName))
+		controllerNameLong  = fmt.Sprintf("%s/%s/%s", ctx.Namespace, ctx.Name, controllerNameShort)
+	)
+
+	r := NewReconciler(
+		mgr.GetClient(),
+		ctrl.Log.WithName("controllers").WithName(controlledTypeName),
+		record.New(mgr.GetEventRecorderFor(controllerNameLong)),
+		ctx.VMProviderA2,
+	)
+
+	// The serial ports connect to the proxy, which forwards the connections
+	// of clients with a valid ticket. The proxy serves clients with the
+	// operator's serving certificate, and runs in every replica, while the
+	// log is read through the proxy by the leader like by any other client.
+	if proxyURI := lib.GetSerialConsoleProxyURI(); proxyURI != "" {
+		certFile := filepath.Join(ctx.WebhookSecretVolumeMountPath, serverCertName)
+		keyFile := filepath.Join(ctx.WebhookSecretVolumeMountPath, serverKeyName)
+
+		proxy := serialconsole.NewProxy(r.Logger.WithName("proxy"), r.ValidateTicket, r.ValidatePeer, certFile, keyFile)
+		if err := mgr.Add(proxy); err != nil {
+			return err
+		}
+
+		consoleAddr, err := serialconsole.ConsoleAddr(proxyURI)
+		if err != nil {
+			return err
+		}
+		r.Dial = serialconsole.Dialer{Addr: consoleAddr, CertFile: certFile}.Dial
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(controlledType).
+		WithOptions(controller.Options{MaxConcurrentReconciles: ctx.MaxConcurrentReconciles}).
+		Watches(&vmopv1.VirtualMachine{},
+			handler.EnqueueRequestsFromMapFunc(r.virtualMachineToSerialConsoleRequestMapper())).
+		Complete(r)
+}
+
+func NewReconciler(
+	client client.Client,
+	logger logr.Logger,
+	recorder record.Recorder,
+	vmProvider vmprovider.VirtualMachineProviderInterfaceA2) *Reconciler {
+
+	return &Reconciler{
+		Client:     client,
+		Logger:     logger,
+		Recorder:   recorder,
+		VMProvider: vmProvider,
+		Dial: func(_ goctx.Context, _ string) (net.Conn, error) {
+			return nil, errors.New("serial console proxy is not running")
+		},
+		logs: map[string]*serialLog{},
+	}
+}
+
+// Reconciler reconciles a VirtualMachineSerialConsoleRequest object.
+type Reconciler struct {
+	client.Client
+	Logger     logr.Logger
+	Recorder   record.Recorder
+	VMProvider vmprovider.VirtualMachineProviderInterfaceA2
+
+	// Dial connects to the serial console proxy that the output of the
+	// network-backed serial port is read from for the log.
+	Dial DialFunc
+
+	// logs are the serial port logs being written, keyed by the namespaced
+	// name of their request. A log is written by its own goroutine for as
+	// long as the request exists.
+	logsMu sync.Mutex
+	logs   map[string]*serialLog
+}
+
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachineserialconsolerequests,verbs=get;list;watch;update;patch;delete
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachineserialconsolerequests/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachines,verbs=get;list;watch
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachineclasses,verbs=get;list
+
+func (r *Reconciler) Reconcile(ctx goctx.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
+	scr := &vmopv1.VirtualMachineSerialConsoleRequest{}
+	if err := r.Get(ctx, req.NamespacedName, scr); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.stopLog(req.NamespacedName.String())
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if !scr.DeletionTimestamp.IsZero() {
+		r.stopLog(scr.NamespacedName())
+		return ctrl.Result{}, nil
+	}
+
+	scrCtx := &context.VirtualMachineSerialConsoleRequestContextA2{
+		Context:              ctx,
+		Logger:               ctrl.Log.WithName("VirtualMachineSerialConsoleRequest").WithValues("name", scr.NamespacedName()),
+		SerialConsoleRequest: scr,
+	}
+
+	if done, err := r.ReconcileExpiry(scrCtx); err != nil || done {
+		return ctrl.Result{}, err
+	}
+
+	patchHelper, err := patch.NewHelper(scr, r.Client)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to init patch helper for %s", scrCtx.String())
+	}
+	defer func() {
+		if err := patchHelper.Patch(ctx, scr); err != nil {
+			if reterr == nil {
+				reterr = err
+			}
+			scrCtx.Logger.Error(err, "patch failed")
+		}
+	}()
+
+	return r.ReconcileNormal(scrCtx)
+}
+
+// ReconcileExpiry deletes a request whose access has expired. A request that
+// writes the serial port log is kept after its ticket expired.
+func (r *Reconciler) ReconcileExpiry(ctx *context.VirtualMachineSerialConsoleRequestContextA2) (bool, error) {
+	scr := ctx.SerialConsoleRequest
+	if scr.Spec.Log != nil {
+		return false, nil
+	}
+
+	expiryTime := scr.Status.ExpiryTime
+	nowTime := metav1.Now()
+	if expiryTime.IsZero() || nowTime.Before(&expiryTime) {
+		return false, nil
+	}
+
+	if err := r.Delete(ctx, scr); client.IgnoreNotFound(err) != nil {
+		return false, errors.Wrapf(err, "failed to delete serialconsolerequest")
+	}
+	ctx.Logger.Info("Deleted expired VirtualMachineSerialConsoleRequest")
+	return true, nil
+}
+
+// ReconcileNormal adds a network-backed serial port to the VM when its class
+// allows it, and then returns the encrypted one-time ticket of the serial
+// console with the address of the proxy it is accessed through. When the
+// request writes the serial port log, the log in the status is updated until
+// the request is deleted.
+func (r *Reconciler) ReconcileNormal(ctx *context.VirtualMachineSerialConsoleRequestContextA2) (ctrl.Result, error) {
+	scr := ctx.SerialConsoleRequest
+
+	ctx.Logger.Info("Reconciling VirtualMachineSerialConsoleRequest")
+	defer func() {
+		ctx.Logger.Info("Finished reconciling VirtualMachineSerialConsoleRequest")
+	}()
+
+	vm := &vmopv1.VirtualMachine{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: scr.Namespace, Name: scr.Spec.Name}, vm); err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to get subject vm %s", scr.Spec.Name)
+	}
+	ctx.VM = vm
+
+	if vm.Status.UniqueID == "" {
+		conditions.MarkFalse(scr, vmopv1.VirtualMachineSerialConsoleRequestConditionSerialPortReady,
+			vmopv1.SourceVirtualMachineNotCreatedReason, "")
+		return ctrl.Result{RequeueAfter: requeueDelay}, nil
+	}
+
+	if ok, err := r.isSerialConsoleAllowed(ctx); err != nil || !ok {
+		return ctrl.Result{}, err
+	}
+
+	key := scr.NamespacedName()
+	if scr.Status.Response == "" || (scr.Spec.Log != nil && r.getLog(key) == nil) {
+		var ticket serialconsole.Ticket
+		var ticketStr string
+		if scr.Status.Response == "" {
+			var err error
+			if ticket, err = serialconsole.NewTicket(scr.Namespace, string(scr.UID)); err != nil {
+				return ctrl.Result{}, err
+			}
+			ticketStr = ticket.String()
+		}
+
+		serialConsole, err := r.VMProvider.GetVirtualMachineSerialConsole(ctx, vm, scr.Spec.PublicKey, ticketStr)
+		if err != nil {
+			if errors.Is(err, serialconsole.ErrVMPoweredOn) {
+				// The VM is watched, so the request is reconciled again when
+				// the VM is powered off.
+				conditions.MarkFalse(scr, vmopv1.VirtualMachineSerialConsoleRequestConditionSerialPortReady,
+					vmopv1.SerialPortVirtualMachinePoweredOnReason, "The VM must be powered off to add the serial port")
+				return ctrl.Result{}, nil
+			}
+			ctx.Logger.Error(err, "Failed to get serial console")
+			conditions.MarkFalse(scr, vmopv1.VirtualMachineSerialConsoleRequestConditionSerialPortReady,
+				vmopv1.SerialPortAddFailedReason, err.Error())
+			return ctrl.Result{RequeueAfter: requeueDelay}, nil
+		}
+		conditions.MarkTrue(scr, vmopv1.VirtualMachineSerialConsoleRequestConditionSerialPortReady)
+
+		if scr.Status.Response == "" {
+			if err := r.reconcileResponse(ctx, serialConsole, ticket); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+
+		if scr.Spec.Log != nil {
+			if err := r.startLog(ctx, serialConsole.ServiceURI); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+	}
+
+	if scr.Spec.Log == nil {
+		return ctrl.Result{RequeueAfter: DefaultExpiryTime}, nil
+	}
+
+	r.updateLog(ctx)
+	return ctrl.Result{RequeueAfter: logUpdateInterval}, nil
+}
+
+// isSerialConsoleAllowed returns true when the VirtualMachineClass of the VM
+// allows a serial console with the AllowSerialConsoleAnnotation.
+func (r *Reconciler) isSerialConsoleAllowed(ctx *context.VirtualMachineSerialConsoleRequestContextA2) (bool, error) {
+	scr := ctx.SerialConsoleRequest
+
+	vmClass := &vmopv1.VirtualMachineClass{}
+	key := client.ObjectKey{Namespace: ctx.VM.Namespace, Name: ctx.VM.Spec.ClassName}
+	if err := r.Get(ctx, key, vmClass); err != nil {
+		if apierrors.IsNotFound(err) {
+			conditions.MarkFalse(scr, vmopv1.VirtualMachineSerialConsoleRequestConditionSerialPortReady,
+				vmopv1.SerialConsoleNotAllowedReason, "VirtualMachineClass %s does not exist", key.Name)
+			return false, nil
+		}
+		return false, err
+	}
+
+	if vmClass.Annotations[vmopv1.AllowSerialConsoleAnnotation] != "true" {
+		conditions.MarkFalse(scr, vmopv1.VirtualMachineSerialConsoleRequestConditionSerialPortReady,
+			vmopv1.SerialConsoleNotAllowedReason, "VirtualMachineClass %s does not allow a serial console", key.Name)
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// reconcileResponse sets the encrypted ticket of the serial console and the
+// address of the proxy it is accessed through. Only the hash of the ticket's
+// secret is stored in the request.
+func (r *Reconciler) reconcileResponse(
+	ctx *context.VirtualMachineSerialConsoleRequestContextA2,
+	serialConsole vmprovider.VirtualMachineSerialConsoleA2,
+	ticket serialconsole.Ticket) error {
+
+	scr := ctx.SerialConsoleRequest
+
+	// Advertise only the address the proxy is served at, which is the host
+	// that the serial ports connect to the proxy at.
+	proxyAddr, err := serialconsole.ConsoleAddr(lib.GetSerialConsoleProxyURI())
+	if err != nil {
+		return err
+	}
+
+	if scr.Annotations == nil {
+		scr.Annotations = make(map[string]string)
+	}
+	scr.Annotations[serialconsole.TicketHashAnnotationKey] = ticket.Hash()
+
+	scr.Status.Response = serialConsole.Response
+	scr.Status.ProxyAddr = proxyAddr
+	scr.Status.ExpiryTime = metav1.NewTime(metav1.Now().Add(DefaultExpiryTime))
+	r.Recorder.EmitEvent(scr, "Acquired Serial Console", nil, false)
+
+	// Add UUID as a Label to the request after acquiring the serial console.
+	// This will be used when validating the connection request from users to
+	// the serial console through the proxy.
+	if scr.Labels == nil {
+		scr.Labels = make(map[string]string)
+	}
+	scr.Labels[serialconsole.UUIDLabelKey] = string(scr.UID)
+
+	isController := true
+	scr.SetOwnerReferences([]metav1.OwnerReference{
+		{
+			APIVersion: vmopv1.SchemeGroupVersion.String(),
+			Kind:       "VirtualMachine",
+			Name:       ctx.VM.Name,
+			UID:        ctx.VM.UID,
+			Controller: &isController,
+		},
+	})
+
+	return nil
+}
+
+// startLog starts writing the output of the serial port with the given
+// service URI to the request's log in a new goroutine. The output is read
+// through the proxy with a new log ticket, which replaces the log ticket of a
+// previous leader.
+func (r *Reconciler) startLog(ctx *context.VirtualMachineSerialConsoleRequestContextA2, serviceURI string) error {
+	scr := ctx.SerialConsoleRequest
+
+	ticket, err := serialconsole.NewTicket(scr.Namespace, string(scr.UID))
+	if err != nil {
+		return err
+	}
+	if scr.Annotations == nil {
+		scr.Annotations = make(map[string]string)
+	}
+	scr.Annotations[serialconsole.LogTicketHashAnnotationKey] = ticket.Hash()
+
+	maxBytes := scr.Spec.Log.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultLogMaxBytes
+	}
+
+	logCtx, cancel := goctx.WithCancel(goctx.Background())
+	l := &serialLog{
+		serviceURI: serviceURI,
+		ticket:     ticket.String(),
+		buffer:     NewLogBuffer(int(maxBytes)),
+		cancel:     cancel,
+	}
+	// Keep the log written before the controller restarted until the serial
+	// port writes more output.
+	_, _ = l.buffer.Write([]byte(scr.Status.Log))
+
+	r.logsMu.Lock()
+	r.logs[scr.NamespacedName()] = l
+	r.logsMu.Unlock()
+
+	go l.run(logCtx, ctx.Logger, r.Dial)
+	return nil
+}
+
+// updateLog copies the output of the serial port to the request's status.
+func (r *Reconciler) updateLog(ctx *context.VirtualMachineSerialConsoleRequestContextA2) {
+	scr := ctx.SerialConsoleRequest
+
+	l := r.getLog(scr.NamespacedName())
+	if l == nil {
+		return
+	}
+
+	if log := l.buffer.String(); log != scr.Status.Log {
+		scr.Status.Log = log
+		scr.Status.LogUpdateTime = metav1.Now()
+	}
+
+	if connected, err := l.status(); connected {
+		conditions.MarkTrue(scr, vmopv1.VirtualMachineSerialConsoleRequestConditionLogStreaming)
+	} else if err != nil {
+		conditions.MarkFalse(scr, vmopv1.VirtualMachineSerialConsoleRequestConditionLogStreaming,
+			vmopv1.SerialLogDisconnectedReason, err.Error())
+	}
+}
+
+func (r *Reconciler) getLog(key string) *serialLog {
+	r.logsMu.Lock()
+	defer r.logsMu.Unlock()
+	return r.logs[key]
+}
+
+// stopLog stops writing the log of a request that was deleted.
+func (r *Reconciler) stopLog(key string) {
+	r.logsMu.Lock()
+	defer r.logsMu.Unlock()
+	if l, ok := r.logs[key]; ok {
+		l.cancel()
+		delete(r.logs, key)
+	}
+}
+
+// ValidateTicket validates the ticket a client connects to a serial console
+// with, records the opening of the serial console as an Event on the request,
+// and returns the service URI of the VM's serial port and whether the ticket
+// is the request's read-only log ticket. The ticket is only valid while the
+// serial port of the VM it was issued for is ready.
+func (r *Reconciler) ValidateTicket(ctx goctx.Context, ticket, clientAddr string) (string, bool, error) {
+	now := time.Now()
+
+	scr, readOnly, err := serialconsole.ValidateTicket(ctx, r.Client, ticket, now)
+	if err != nil {
+		return "", false, err
+	}
+
+	if !conditions.IsTrue(scr, vmopv1.VirtualMachineSerialConsoleRequestConditionSerialPortReady) {
+		return "", false, fmt.Errorf("%w: serial port is not ready", serialconsole.ErrInvalidTicket)
+	}
+
+	vm := &vmopv1.VirtualMachine{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: scr.Namespace, Name: scr.Spec.Name}, vm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", false, fmt.Errorf("%w: vm %s does not exist", serialconsole.ErrInvalidTicket, scr.Spec.Name)
+		}
+		return "", false, errors.Wrapf(err, "failed to get subject vm %s", scr.Spec.Name)
+	}
+	if !isOwnedBy(scr, vm) {
+		return "", false, fmt.Errorf("%w: issued for another vm %s", serialconsole.ErrInvalidTicket, vm.Name)
+	}
+	if vm.Status.InstanceUUID == "" {
+		return "", false, fmt.Errorf("instance UUID of vm %s is not available", vm.Name)
+	}
+	serviceURI := serialconsole.ServiceURI(vm.Status.InstanceUUID)
+
+	if readOnly {
+		return serviceURI, true, nil
+	}
+
+	r.Logger.Info("Serial console opened",
+		"name", scr.NamespacedName(),
+		"virtualMachine", vm.Name,
+		"client", clientAddr,
+		"time", now.UTC().Format(time.RFC3339))
+	r.Recorder.Eventf(scr, SerialConsoleOpenedReason,
+		"Serial console of VirtualMachine %s opened by %s at %s",
+		vm.Name, clientAddr, now.UTC().Format(time.RFC3339))
+
+	return serviceURI, false, nil
+}
+
+// ValidatePeer validates that the serial port of the VM with the given
+// instance UUID connects from one of the ESXi hosts of the VM's cluster. Only
+// the VMs that a request added the serial port to are accepted.
+func (r *Reconciler) ValidatePeer(ctx goctx.Context, vmUUID, peerAddr string) error {
+	peerIP := net.ParseIP(peerAddr)
+	if peerIP == nil {
+		return fmt.Errorf("invalid peer address %q", peerAddr)
+	}
+
+	scrList := &vmopv1.VirtualMachineSerialConsoleRequestList{}
+	if err := r.List(ctx, scrList, client.MatchingLabels{serialconsole.UUIDLabelKey: vmUUID}); err != nil {
+		return errors.Wrap(err, "failed to list serial console requests")
+	}
+
+	checked := map[client.ObjectKey]struct{}{}
+	for i := range scrList.Items {
+		scr := &scrList.Items[i]
+		key := client.ObjectKey{Namespace: scr.Namespace, Name: scr.Spec.Name}
+		if _, ok := checked[key]; ok {
+			continue
+		}
+		checked[key] = struct{}{}
+
+		vm := &vmopv1.VirtualMachine{}
+		if err := r.Get(ctx, key, vm); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return errors.Wrapf(err, "failed to get vm %s", key)
+		}
+		if vm.Status.InstanceUUID != vmUUID {
+			continue
+		}
+
+		addrs, err := r.VMProvider.GetVirtualMachineClusterHostAddresses(ctx, vm)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get the host addresses of vm %s", key)
+		}
+		for _, addr := range addrs {
+			if peerIP.Equal(net.ParseIP(addr)) {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s is not a host of the cluster of vm %s", peerAddr, key)
+	}
+
+	return fmt.Errorf("no serial console request for vm %s", vmUUID)
+}
+
+// isOwnedBy returns true when the request's ticket was issued for the VM,
+// which is the controller owner of the request.
+func isOwnedBy(scr *vmopv1.VirtualMachineSerialConsoleRequest, vm *vmopv1.VirtualMachine) bool {
+	owner := metav1.GetControllerOf(scr)
+	return owner != nil && owner.Kind == "VirtualMachine" && owner.Name == vm.Name && owner.UID == vm.UID
+}
+
+// virtualMachineToSerialConsoleRequestMapper returns the requests of a VM that
+// wait for the VM to be powered off to add the serial port.
+func (r *Reconciler) virtualMachineToSerialConsoleRequestMapper() func(_ goctx.Context, o client.Object) []reconcile.Request {
+	return func(ctx goctx.Context, o client.Object) []reconcile.Request {
+		vm := o.(*vmopv1.VirtualMachine)
+		if vm.Status.PowerState == vmopv1.VirtualMachinePowerStateOn {
+			return nil
+		}
+
+		scrList := &vmopv1.VirtualMachineSerialConsoleRequestList{}
+		if err := r.List(ctx, scrList, client.InNamespace(vm.Namespace)); err != nil {
+			return nil
+		}
+
+		var requests []reconcile.Request
+		for i := range scrList.Items {
+			scr := &scrList.Items[i]
+			if scr.Spec.Name != vm.Name {
+				continue
+			}
+			if conditions.GetReason(scr, vmopv1.VirtualMachineSerialConsoleRequestConditionSerialPortReady) !=
+				vmopv1.SerialPortVirtualMachinePoweredOnReason {
+				continue
+			}
+			requests = append(requests, reconcile.Request{
+				NamespacedName: client.ObjectKey{Namespace: scr.Namespace, Name: scr.Name},
+			})
+		}
+		return requests
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha2_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	serialconsolerequest "github.com/vmware-tanzu/vm-operator/controllers/virtualmachineserialconsolerequest/v1alpha2"
+	"github.com/vmware-tanzu/vm-operator/pkg/lib"
+	"github.com/vmware-tanzu/vm-operator/pkg/serialconsole"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func intgTests() {
+	Describe("Invoking SerialConsoleRequest controller tests", serialConsoleRequestReconcile)
+}
+
+func serialConsoleRequestReconcile() {
+	var (
+		ctx     *builder.IntegrationTestContext
+		scr     *vmopv1.VirtualMachineSerialConsoleRequest
+		vm      *vmopv1.VirtualMachine
+		vmClass *vmopv1.VirtualMachineClass
+
+		oldGetProxyURI func() string
+	)
+
+	getSerialConsoleRequest := func(ctx *builder.IntegrationTestContext, objKey types.NamespacedName) *vmopv1.VirtualMachineSerialConsoleRequest {
+		scr := &vmopv1.VirtualMachineSerialConsoleRequest{}
+		if err := ctx.Client.Get(ctx, objKey, scr); err != nil {
+			return nil
+		}
+		return scr
+	}
+
+	BeforeEach(func() {
+		ctx = suite.NewIntegrationTestContext()
+
+		vmClass = builder.DummyVirtualMachineClass2A2("dummy-class")
+		vmClass.Namespace = ctx.Namespace
+		vmClass.Annotations = map[string]string{
+			vmopv1.AllowSerialConsoleAnnotation: "true",
+		}
+
+		vm = &vmopv1.VirtualMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "dummy-vm",
+				Namespace: ctx.Namespace,
+			},
+			Spec: vmopv1.VirtualMachineSpec{
+				ClassName:  vmClass.Name,
+				ImageName:  "dummy-image",
+				PowerState: vmopv1.VirtualMachinePowerStateOff,
+			},
+		}
+
+		_, publicKeyPem := builder.WebConsoleRequestKeyPair()
+		scr = builder.DummyVirtualMachineSerialConsoleRequest(ctx.Namespace, "dummy-scr", vm.Name, publicKeyPem)
+
+		oldGetProxyURI = lib.GetSerialConsoleProxyURI
+		lib.GetSerialConsoleProxyURI = func() string { return "telnet://192.168.0.1:8023" }
+
+		fakeVMProvider.Lock()
+		defer fakeVMProvider.Unlock()
+		fakeVMProvider.GetVirtualMachineSerialConsoleFn = func(
+			ctx context.Context,
+			vm *vmopv1.VirtualMachine,
+			pubKey, ticket string) (vmprovider.VirtualMachineSerialConsoleA2, error) {
+
+			return vmprovider.VirtualMachineSerialConsoleA2{
+				ServiceURI: "dummy-service-uri",
+				Response:   "some-fake-response",
+			}, nil
+		}
+	})
+
+	AfterEach(func() {
+		ctx.AfterEach()
+		ctx = nil
+		fakeVMProvider.Reset()
+		lib.GetSerialConsoleProxyURI = oldGetProxyURI
+	})
+
+	Context("Reconcile", func() {
+		BeforeEach(func() {
+			Expect(ctx.Client.Create(ctx, vmClass)).To(Succeed())
+			Expect(ctx.Client.Create(ctx, vm)).To(Succeed())
+			vm.Status.UniqueID = "vm-42"
+			Expect(ctx.Client.Status().Update(ctx, vm)).To(Succeed())
+			Expect(ctx.Client.Create(ctx, scr)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			err := ctx.Client.Delete(ctx, scr)
+			Expect(err == nil || k8serrors.IsNotFound(err)).To(BeTrue())
+			err = ctx.Client.Delete(ctx, vm)
+			Expect(err == nil || k8serrors.IsNotFound(err)).To(BeTrue())
+			err = ctx.Client.Delete(ctx, vmClass)
+			Expect(err == nil || k8serrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("resource successfully created", func() {
+			Eventually(func() bool {
+				scr = getSerialConsoleRequest(ctx, types.NamespacedName{Name: scr.Name, Namespace: scr.Namespace})
+				if scr != nil && scr.Status.Response != "" {
+					return true
+				}
+				return false
+			}).Should(BeTrue(), "waiting for serialconsolerequest to be")
+			Expect(scr.Status.ProxyAddr).To(Equal("192.168.0.1:8024"))
+			Expect(scr.Status.Response).To(Equal("some-fake-response"))
+			Expect(scr.Status.ExpiryTime.Time).To(BeTemporally("~", time.Now(), serialconsolerequest.DefaultExpiryTime))
+			Expect(scr.Labels).To(HaveKeyWithValue(serialconsole.UUIDLabelKey, string(scr.UID)))
+		})
+	})
+}
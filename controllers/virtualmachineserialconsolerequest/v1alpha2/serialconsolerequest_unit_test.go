@@ -0,0 +1,486 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha2_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	serialconsolerequest "github.com/vmware-tanzu/vm-operator/controllers/virtualmachineserialconsolerequest/v1alpha2"
+	conditions "github.com/vmware-tanzu/vm-operator/pkg/conditions2"
+	vmopContext "github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/lib"
+	"github.com/vmware-tanzu/vm-operator/pkg/serialconsole"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
+	providerfake "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/fake"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func unitTests() {
+	Describe("Invoking SerialConsoleRequest Reconcile", unitTestsReconcile)
+	Describe("SerialConsoleRequest LogBuffer", unitTestsLogBuffer)
+}
+
+func unitTestsReconcile() {
+
+	var (
+		initObjects []client.Object
+		ctx         *builder.UnitTestContextForController
+
+		reconciler *serialconsolerequest.Reconciler
+		scrCtx     *vmopContext.VirtualMachineSerialConsoleRequestContextA2
+		scr        *vmopv1.VirtualMachineSerialConsoleRequest
+		vm         *vmopv1.VirtualMachine
+		vmClass    *vmopv1.VirtualMachineClass
+
+		oldGetProxyURI func() string
+	)
+
+	BeforeEach(func() {
+		vmClass = builder.DummyVirtualMachineClass2A2("dummy-class")
+		vmClass.Annotations = map[string]string{
+			vmopv1.AllowSerialConsoleAnnotation: "true",
+		}
+
+		vm = &vmopv1.VirtualMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "dummy-vm",
+			},
+			Spec: vmopv1.VirtualMachineSpec{
+				ClassName: vmClass.Name,
+			},
+			Status: vmopv1.VirtualMachineStatus{
+				UniqueID: "vm-42",
+			},
+		}
+
+		scr = builder.DummyVirtualMachineSerialConsoleRequest("", "dummy-scr", vm.Name, "")
+	})
+
+	JustBeforeEach(func() {
+		ctx = suite.NewUnitTestContextForController(initObjects...)
+		reconciler = serialconsolerequest.NewReconciler(
+			ctx.Client,
+			ctx.Logger,
+			ctx.Recorder,
+			ctx.VMProviderA2,
+		)
+		fakeVMProvider = ctx.VMProviderA2.(*providerfake.VMProviderA2)
+		fakeVMProvider.GetVirtualMachineSerialConsoleFn = func(
+			ctx context.Context,
+			vm *vmopv1.VirtualMachine,
+			pubKey, ticket string) (vmprovider.VirtualMachineSerialConsoleA2, error) {
+
+			return vmprovider.VirtualMachineSerialConsoleA2{
+				ServiceURI: "dummy-service-uri",
+				Response:   "some-fake-response",
+			}, nil
+		}
+
+		scrCtx = &vmopContext.VirtualMachineSerialConsoleRequestContextA2{
+			Context:              ctx,
+			Logger:               ctx.Logger.WithName(scr.Name),
+			SerialConsoleRequest: scr,
+		}
+	})
+
+	AfterEach(func() {
+		ctx.AfterEach()
+		ctx = nil
+		initObjects = nil
+		reconciler = nil
+		fakeVMProvider.Reset()
+	})
+
+	Context("ReconcileNormal", func() {
+		BeforeEach(func() {
+			initObjects = append(initObjects, scr, vm, vmClass)
+
+			oldGetProxyURI = lib.GetSerialConsoleProxyURI
+			lib.GetSerialConsoleProxyURI = func() string { return "telnet://192.168.0.1:8023" }
+		})
+
+		AfterEach(func() {
+			lib.GetSerialConsoleProxyURI = oldGetProxyURI
+		})
+
+		When("the class allows a serial console", func() {
+			It("returns the serial console", func() {
+				var ticketStr string
+				fakeVMProvider.GetVirtualMachineSerialConsoleFn = func(
+					ctx context.Context,
+					vm *vmopv1.VirtualMachine,
+					pubKey, ticket string) (vmprovider.VirtualMachineSerialConsoleA2, error) {
+
+					ticketStr = ticket
+					return vmprovider.VirtualMachineSerialConsoleA2{
+						ServiceURI: "dummy-service-uri",
+						Response:   "some-fake-response",
+					}, nil
+				}
+
+				result, err := reconciler.ReconcileNormal(scrCtx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.RequeueAfter).To(Equal(serialconsolerequest.DefaultExpiryTime))
+
+				Expect(scr.Status.ProxyAddr).To(Equal("192.168.0.1:8024"))
+				Expect(scr.Status.Response).To(Equal("some-fake-response"))
+
+				// Only the hash of the ticket's secret is stored.
+				Expect(ticketStr).ToNot(BeEmpty())
+				ticket := serialconsole.Ticket{Secret: ticketStr[strings.LastIndex(ticketStr, "/")+1:]}
+				Expect(scr.Annotations).To(HaveKeyWithValue(serialconsole.TicketHashAnnotationKey, ticket.Hash()))
+				Expect(scr.Status.ExpiryTime.Time).To(BeTemporally("~", time.Now(), serialconsolerequest.DefaultExpiryTime))
+				// Checking the label key only because UID will not be set to a resource during unit test.
+				Expect(scr.Labels).To(HaveKey(serialconsole.UUIDLabelKey))
+				Expect(conditions.IsTrue(scr, vmopv1.VirtualMachineSerialConsoleRequestConditionSerialPortReady)).To(BeTrue())
+			})
+		})
+
+		When("the serial console proxy is not configured", func() {
+			BeforeEach(func() {
+				lib.GetSerialConsoleProxyURI = func() string { return "" }
+			})
+
+			It("does not advertise a proxy address", func() {
+				_, err := reconciler.ReconcileNormal(scrCtx)
+				Expect(err).To(MatchError(ContainSubstring("serial console proxy is not configured")))
+				Expect(scr.Status.ProxyAddr).To(BeEmpty())
+				Expect(scr.Status.Response).To(BeEmpty())
+			})
+		})
+
+		When("the class does not allow a serial console", func() {
+			BeforeEach(func() {
+				vmClass.Annotations = nil
+			})
+
+			It("does not add the serial port", func() {
+				called := false
+				fakeVMProvider.GetVirtualMachineSerialConsoleFn = func(
+					ctx context.Context,
+					vm *vmopv1.VirtualMachine,
+					pubKey, ticket string) (vmprovider.VirtualMachineSerialConsoleA2, error) {
+
+					called = true
+					return vmprovider.VirtualMachineSerialConsoleA2{}, nil
+				}
+
+				_, err := reconciler.ReconcileNormal(scrCtx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(called).To(BeFalse())
+				Expect(scr.Status.Response).To(BeEmpty())
+
+				c := conditions.Get(scr, vmopv1.VirtualMachineSerialConsoleRequestConditionSerialPortReady)
+				Expect(c).ToNot(BeNil())
+				Expect(c.Status).To(Equal(metav1.ConditionFalse))
+				Expect(c.Reason).To(Equal(vmopv1.SerialConsoleNotAllowedReason))
+			})
+		})
+
+		When("the VM has not been created", func() {
+			BeforeEach(func() {
+				vm.Status.UniqueID = ""
+			})
+
+			It("requeues", func() {
+				result, err := reconciler.ReconcileNormal(scrCtx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.RequeueAfter).ToNot(BeZero())
+				Expect(scr.Status.Response).To(BeEmpty())
+
+				c := conditions.Get(scr, vmopv1.VirtualMachineSerialConsoleRequestConditionSerialPortReady)
+				Expect(c).ToNot(BeNil())
+				Expect(c.Reason).To(Equal(vmopv1.SourceVirtualMachineNotCreatedReason))
+			})
+		})
+
+		When("the VM is powered on", func() {
+			It("waits for the VM to be powered off", func() {
+				fakeVMProvider.GetVirtualMachineSerialConsoleFn = func(
+					ctx context.Context,
+					vm *vmopv1.VirtualMachine,
+					pubKey, ticket string) (vmprovider.VirtualMachineSerialConsoleA2, error) {
+
+					return vmprovider.VirtualMachineSerialConsoleA2{}, serialconsole.ErrVMPoweredOn
+				}
+
+				result, err := reconciler.ReconcileNormal(scrCtx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.IsZero()).To(BeTrue())
+				Expect(scr.Status.Response).To(BeEmpty())
+
+				c := conditions.Get(scr, vmopv1.VirtualMachineSerialConsoleRequestConditionSerialPortReady)
+				Expect(c).ToNot(BeNil())
+				Expect(c.Status).To(Equal(metav1.ConditionFalse))
+				Expect(c.Reason).To(Equal(vmopv1.SerialPortVirtualMachinePoweredOnReason))
+			})
+		})
+
+		When("the serial port cannot be added", func() {
+			It("requeues", func() {
+				fakeVMProvider.GetVirtualMachineSerialConsoleFn = func(
+					ctx context.Context,
+					vm *vmopv1.VirtualMachine,
+					pubKey, ticket string) (vmprovider.VirtualMachineSerialConsoleA2, error) {
+
+					return vmprovider.VirtualMachineSerialConsoleA2{}, errors.New("vm has no free slot")
+				}
+
+				result, err := reconciler.ReconcileNormal(scrCtx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.RequeueAfter).ToNot(BeZero())
+				Expect(scr.Status.Response).To(BeEmpty())
+
+				c := conditions.Get(scr, vmopv1.VirtualMachineSerialConsoleRequestConditionSerialPortReady)
+				Expect(c).ToNot(BeNil())
+				Expect(c.Status).To(Equal(metav1.ConditionFalse))
+				Expect(c.Reason).To(Equal(vmopv1.SerialPortAddFailedReason))
+				Expect(c.Message).To(ContainSubstring("vm has no free slot"))
+			})
+		})
+
+		When("the serial port log is written", func() {
+			var (
+				server    net.Conn
+				logTicket string
+			)
+
+			BeforeEach(func() {
+				scr.Spec.Log = &vmopv1.VirtualMachineSerialConsoleLogSpec{MaxBytes: 1024}
+			})
+
+			JustBeforeEach(func() {
+				var clientConn net.Conn
+				clientConn, server = net.Pipe()
+				reconciler.Dial = func(_ context.Context, ticket string) (net.Conn, error) {
+					logTicket = ticket
+					if clientConn == nil {
+						return nil, errors.New("already connected")
+					}
+					conn := clientConn
+					clientConn = nil
+					return conn, nil
+				}
+			})
+
+			AfterEach(func() {
+				_ = server.Close()
+			})
+
+			It("writes the output of the serial port to the status", func() {
+				result, err := reconciler.ReconcileNormal(scrCtx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.RequeueAfter).ToNot(BeZero())
+				Expect(scr.Status.Response).To(Equal("some-fake-response"))
+				// The ticket expires, but the request is kept for the log.
+				Expect(scr.Status.ExpiryTime.IsZero()).To(BeFalse())
+
+				go func() {
+					defer GinkgoRecover()
+					_, _ = server.Write([]byte("login: "))
+				}()
+
+				Eventually(func() string {
+					_, err := reconciler.ReconcileNormal(scrCtx)
+					Expect(err).ToNot(HaveOccurred())
+					return scr.Status.Log
+				}).Should(Equal("login: "))
+				// The log is read with a log ticket whose hash is in the request.
+				ticket := serialconsole.Ticket{Secret: logTicket[strings.LastIndex(logTicket, "/")+1:]}
+				Expect(scr.Annotations).To(HaveKeyWithValue(serialconsole.LogTicketHashAnnotationKey, ticket.Hash()))
+				Expect(scr.Status.LogUpdateTime.IsZero()).To(BeFalse())
+				Expect(conditions.IsTrue(scr, vmopv1.VirtualMachineSerialConsoleRequestConditionLogStreaming)).To(BeTrue())
+
+				By("stops the log when the request is deleted", func() {
+					Expect(ctx.Client.Delete(ctx, scr)).To(Succeed())
+					_, err := reconciler.Reconcile(ctx, ctrl.Request{
+						NamespacedName: types.NamespacedName{Namespace: scr.Namespace, Name: scr.Name},
+					})
+					Expect(err).ToNot(HaveOccurred())
+
+					// The connection is closed once the log is stopped.
+					Eventually(func() error {
+						_, err := server.Write([]byte("x"))
+						return err
+					}).Should(HaveOccurred())
+				})
+			})
+		})
+	})
+
+	Context("ValidateTicket", func() {
+		const instanceUUID = "42307e5f-4d4b-4b3a-9f67-6fa4f5a1c3a2"
+
+		var ticket serialconsole.Ticket
+
+		BeforeEach(func() {
+			vm.Namespace = "dummy-ns"
+			vm.UID = "dummy-vm-uid"
+			vm.Status.InstanceUUID = instanceUUID
+
+			scr.Namespace = vm.Namespace
+			scr.UID = "dummy-scr-uid"
+			var err error
+			ticket, err = serialconsole.NewTicket(scr.Namespace, string(scr.UID))
+			Expect(err).ToNot(HaveOccurred())
+
+			isController := true
+			scr.OwnerReferences = []metav1.OwnerReference{
+				{
+					APIVersion: vmopv1.SchemeGroupVersion.String(),
+					Kind:       "VirtualMachine",
+					Name:       vm.Name,
+					UID:        vm.UID,
+					Controller: &isController,
+				},
+			}
+			scr.Labels = map[string]string{serialconsole.UUIDLabelKey: string(scr.UID)}
+			scr.Annotations = map[string]string{serialconsole.TicketHashAnnotationKey: ticket.Hash()}
+			scr.Status.ExpiryTime = metav1.NewTime(time.Now().Add(time.Minute))
+			conditions.MarkTrue(scr, vmopv1.VirtualMachineSerialConsoleRequestConditionSerialPortReady)
+
+			initObjects = append(initObjects, scr, vm)
+		})
+
+		It("returns the service URI of the VM's serial port", func() {
+			serviceURI, readOnly, err := reconciler.ValidateTicket(ctx, ticket.String(), "10.0.0.1")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(readOnly).To(BeFalse())
+			Expect(serviceURI).To(Equal(serialconsole.ServiceURI(instanceUUID)))
+		})
+
+		When("the ticket is the log ticket", func() {
+			BeforeEach(func() {
+				scr.Annotations[serialconsole.LogTicketHashAnnotationKey] = ticket.Hash()
+				delete(scr.Annotations, serialconsole.TicketHashAnnotationKey)
+			})
+
+			It("returns the service URI of the VM's serial port as read-only", func() {
+				serviceURI, readOnly, err := reconciler.ValidateTicket(ctx, ticket.String(), "10.0.0.1")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(readOnly).To(BeTrue())
+				Expect(serviceURI).To(Equal(serialconsole.ServiceURI(instanceUUID)))
+			})
+		})
+
+		When("the serial port is not ready", func() {
+			BeforeEach(func() {
+				conditions.MarkFalse(scr, vmopv1.VirtualMachineSerialConsoleRequestConditionSerialPortReady,
+					vmopv1.SerialPortVirtualMachinePoweredOnReason, "")
+			})
+
+			It("rejects the ticket", func() {
+				_, _, err := reconciler.ValidateTicket(ctx, ticket.String(), "10.0.0.1")
+				Expect(err).To(MatchError(serialconsole.ErrInvalidTicket))
+			})
+		})
+
+		When("the VM was replaced after the ticket was issued", func() {
+			BeforeEach(func() {
+				vm.UID = "other-vm-uid"
+			})
+
+			It("rejects the ticket", func() {
+				_, _, err := reconciler.ValidateTicket(ctx, ticket.String(), "10.0.0.1")
+				Expect(err).To(MatchError(serialconsole.ErrInvalidTicket))
+			})
+		})
+	})
+
+	Context("ValidatePeer", func() {
+		const instanceUUID = "42307e5f-4d4b-4b3a-9f67-6fa4f5a1c3a2"
+
+		BeforeEach(func() {
+			vm.Status.InstanceUUID = instanceUUID
+			scr.Labels = map[string]string{serialconsole.UUIDLabelKey: instanceUUID}
+			initObjects = append(initObjects, scr, vm)
+		})
+
+		JustBeforeEach(func() {
+			fakeVMProvider.GetVirtualMachineClusterHostAddressesFn = func(
+				_ context.Context,
+				vm *vmopv1.VirtualMachine) ([]string, error) {
+
+				Expect(vm.Status.InstanceUUID).To(Equal(instanceUUID))
+				return []string{"10.0.0.1", "fd00::1"}, nil
+			}
+		})
+
+		It("accepts a host of the VM's cluster", func() {
+			Expect(reconciler.ValidatePeer(ctx, instanceUUID, "10.0.0.1")).To(Succeed())
+			Expect(reconciler.ValidatePeer(ctx, instanceUUID, "fd00:0::1")).To(Succeed())
+		})
+
+		It("rejects another host", func() {
+			Expect(reconciler.ValidatePeer(ctx, instanceUUID, "10.0.0.2")).ToNot(Succeed())
+		})
+
+		It("rejects a VM without a serial console request", func() {
+			Expect(reconciler.ValidatePeer(ctx, "42300000-0000-0000-0000-000000000001", "10.0.0.1")).ToNot(Succeed())
+		})
+
+		When("the instance UUID of the VM does not match the label", func() {
+			BeforeEach(func() {
+				vm.Status.InstanceUUID = "42300000-0000-0000-0000-000000000001"
+			})
+
+			It("rejects the serial port", func() {
+				Expect(reconciler.ValidatePeer(ctx, instanceUUID, "10.0.0.1")).ToNot(Succeed())
+			})
+		})
+	})
+
+	Context("ReconcileExpiry", func() {
+		BeforeEach(func() {
+			scr.Status.ExpiryTime = metav1.NewTime(time.Now().Add(-time.Minute))
+			initObjects = append(initObjects, scr)
+		})
+
+		It("deletes the expired request", func() {
+			done, err := reconciler.ReconcileExpiry(scrCtx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(done).To(BeTrue())
+
+			err = ctx.Client.Get(ctx, client.ObjectKeyFromObject(scr), &vmopv1.VirtualMachineSerialConsoleRequest{})
+			Expect(err).To(HaveOccurred())
+		})
+
+		When("the request writes the serial port log", func() {
+			BeforeEach(func() {
+				scr.Spec.Log = &vmopv1.VirtualMachineSerialConsoleLogSpec{}
+			})
+
+			It("does not expire", func() {
+				done, err := reconciler.ReconcileExpiry(scrCtx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(done).To(BeFalse())
+			})
+		})
+	})
+}
+
+func unitTestsLogBuffer() {
+	It("keeps the last bytes written", func() {
+		b := serialconsolerequest.NewLogBuffer(8)
+		_, _ = b.Write([]byte("hello "))
+		Expect(b.String()).To(Equal("hello "))
+		_, _ = b.Write([]byte("world"))
+		Expect(b.String()).To(Equal("lo world"))
+		_, _ = b.Write([]byte("0123456789"))
+		Expect(b.String()).To(Equal("23456789"))
+	})
+}
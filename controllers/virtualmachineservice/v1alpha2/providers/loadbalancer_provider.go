@@ -31,6 +31,10 @@ const (
 type LoadbalancerProvider interface {
 	EnsureLoadBalancer(ctx context.Context, vmService *vmopv1.VirtualMachineService) error
 
+	// EnsureLoadBalancerDeleted removes the VirtualMachineService from its load balancer
+	// when the VirtualMachineService is deleted.
+	EnsureLoadBalancerDeleted(ctx context.Context, vmService *vmopv1.VirtualMachineService) error
+
 	// GetServiceLabels returns the labels, if any, to place on a Service.
 	// This is applicable when VirtualMachineService is translated to a
 	// Service and we would like to apply the provider specific labels
@@ -82,6 +86,10 @@ func (NoopLoadbalancerProvider) EnsureLoadBalancer(context.Context, *vmopv1.Virt
 	return nil
 }
 
+func (NoopLoadbalancerProvider) EnsureLoadBalancerDeleted(context.Context, *vmopv1.VirtualMachineService) error {
+	return nil
+}
+
 func (NoopLoadbalancerProvider) GetServiceLabels(ctx context.Context, vmService *vmopv1.VirtualMachineService) (map[string]string, error) {
 	return nil, nil
 }
@@ -110,6 +118,10 @@ func (nl *NsxtLoadbalancerProvider) EnsureLoadBalancer(ctx context.Context, vmSe
 	return nil
 }
 
+func (nl *NsxtLoadbalancerProvider) EnsureLoadBalancerDeleted(ctx context.Context, vmService *vmopv1.VirtualMachineService) error {
+	return nil
+}
+
 // GetServiceLabels provides the intended NSX-T specific labels on Service. The
 // responsibility is left to the caller to actually set them.
 func (nl *NsxtLoadbalancerProvider) GetServiceLabels(ctx context.Context, vmService *vmopv1.VirtualMachineService) (map[string]string, error) {
@@ -9,8 +9,6 @@ import (
 	"text/template"
 
 	"sigs.k8s.io/yaml"
-
-	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
 )
 
 const XdsNodePort = 31799
@@ -19,45 +17,30 @@ var envoyBootstrapConfigTemplate, _ = template.New("envoyBootstrapConfig").Parse
 
 type lbConfigParams struct {
 	NodeID      string
-	Ports       []vmopv1.VirtualMachineServicePort
 	CPNodes     []string
 	XdsNodePort int
 }
 
+// The listeners and clusters of the LB VM are discovered from the xDS servers, so
+// that they follow the ports of the VirtualMachineServices of the LB VM.
 const envoyBootstrapConfig = `node:
   id: {{.NodeID}}
   cluster: vmop-simple-lb
+dynamic_resources:
+  lds_config:
+    api_config_source:
+      api_type: GRPC
+      grpc_services:
+        envoy_grpc:
+          cluster_name: xds_cluster
+  cds_config:
+    api_config_source:
+      api_type: GRPC
+      grpc_services:
+        envoy_grpc:
+          cluster_name: xds_cluster
 static_resources:
-  listeners:
-  # {{- range .Ports}}
-  - name: {{.Name}}
-    address:
-      socket_address:
-        address: 0.0.0.0
-        port_value: {{.Port}}
-    filter_chains:
-    - filters:
-      - name: envoy.tcp_proxy
-        config:
-          stat_prefix: ingress_tcp
-          cluster: {{.Name}}
-  # {{- end}}
-
   clusters:
-  # {{- range .Ports}}
-  - name: {{.Name}}
-    connect_timeout: 0.25s
-    type: EDS
-    lb_policy: ROUND_ROBIN
-    eds_cluster_config:
-      eds_config:
-        api_config_source:
-          api_type: GRPC
-          grpc_services:
-            envoy_grpc:
-              cluster_name: xds_cluster
-  # {{- end}}
-
   - name: xds_cluster
     connect_timeout: 0.25s
     type: STATIC
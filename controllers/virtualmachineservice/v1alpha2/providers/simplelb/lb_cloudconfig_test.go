@@ -10,6 +10,7 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/yaml"
 
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
@@ -32,14 +33,13 @@ var _ = Describe("lbCloudConfig", func() {
 						Name:       "apiserver",
 						Protocol:   "TCP",
 						Port:       6443,
-						TargetPort: 6443,
+						TargetPort: intstr.FromInt(6443),
 					}},
 				},
 			}
 
 			params := lbConfigParams{
 				NodeID:      vmService.NamespacedName(),
-				Ports:       vmService.Spec.Ports,
 				CPNodes:     []string{"10.10.00.3"},
 				XdsNodePort: XdsNodePort,
 			}
@@ -53,6 +53,15 @@ var _ = Describe("lbCloudConfig", func() {
 				Expect(s).ToNot(ContainSubstring("\t"))
 			})
 
+			It("should discover the listeners and clusters from the xDS servers", func() {
+				config := map[string]interface{}{}
+				Expect(yaml.Unmarshal([]byte(s), &config)).To(Succeed())
+				Expect(config).To(HaveKey("dynamic_resources"))
+				Expect(config["dynamic_resources"]).To(HaveKey("lds_config"))
+				Expect(config["dynamic_resources"]).To(HaveKey("cds_config"))
+				Expect(config["static_resources"]).ToNot(HaveKey("listeners"))
+			})
+
 			Context("renderAndBase64EncodeLBCloudConfig()", func() {
 				It("should encode into valid base64 cloud-config", func() {
 					b64s := renderAndBase64EncodeLBCloudConfig(params)
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"sync"
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
@@ -22,16 +23,59 @@ import (
 
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
 	vmopv1common "github.com/vmware-tanzu/vm-operator/api/v1alpha2/common"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/record"
+)
+
+const (
+	// LoadBalancerNameAnnotation is the annotation on a VirtualMachineService with the name of its
+	// LB VM. The VirtualMachineServices in a namespace with the same name share one LB VM. Defaults
+	// to the name of the VirtualMachineService.
+	LoadBalancerNameAnnotation = "simplelb.vmoperator.vmware.com/load-balancer-name"
+
+	// TLSPassthroughServerNamesAnnotation is the annotation on a VirtualMachineService with a comma
+	// separated list of TLS server names. When set, the TCP ports of the VirtualMachineService only
+	// route the TLS connections to these server names (SNI), without terminating TLS, so that the
+	// VirtualMachineServices that share an LB VM can use the same port.
+	TLSPassthroughServerNamesAnnotation = "simplelb.vmoperator.vmware.com/tls-passthrough-server-names"
+
+	// PortSkippedReason is the reason of the warning Event that is recorded on a
+	// VirtualMachineService when a port of it is not load balanced, because the port conflicts with
+	// the port of another VirtualMachineService that shares the LB VM, or its protocol is not
+	// supported.
+	PortSkippedReason = "LoadBalancerPortSkipped"
 )
 
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;create;patch
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachines,verbs=get;create;patch
+
 type Provider struct {
 	client       client.Client
 	controlPlane loadbalancerControlPlane
+	recorder     record.Recorder
 	log          logr.Logger
+
+	mu sync.Mutex
+	// lbNames are the names of the LB VMs that the ports of the VirtualMachineServices were last
+	// added to by namespace and name, so that the ports are removed from an LB VM that a
+	// VirtualMachineService no longer uses.
+	lbNames map[string]string
 }
 
 type loadbalancerControlPlane interface {
-	UpdateEndpoints(*corev1.Service, *corev1.Endpoints) error
+	// UpdateLoadBalancer updates the LB VM with the given node ID with the Services, and returns
+	// the errors of the ports that were skipped by the name of their Service.
+	UpdateLoadBalancer(nodeID string, services []lbService) (map[string]error, error)
+}
+
+// lbService is a Service that is load balanced by an LB VM.
+type lbService struct {
+	Service   *corev1.Service
+	Endpoints *corev1.Endpoints
+	// VMs are the VirtualMachines of the Endpoints, that are health checked by the LB VM.
+	VMs []*vmopv1.VirtualMachine
+	// TLSServerNames are the server names that the TCP ports of the Service are routed by.
+	TLSServerNames []string
 }
 
 func New(mgr manager.Manager) *Provider {
@@ -39,6 +83,7 @@ func New(mgr manager.Manager) *Provider {
 	return &Provider{
 		client:       mgr.GetClient(),
 		controlPlane: NewXdsServer(mgr, log),
+		recorder:     record.New(mgr.GetEventRecorderFor("simple-lb")),
 		log:          log,
 	}
 }
@@ -120,25 +165,46 @@ func (s *Provider) GetToBeRemovedServiceAnnotations(ctx context.Context, vmServi
 }
 
 func (s *Provider) ensureLBVM(ctx context.Context, vm *vmopv1.VirtualMachine, secret *corev1.Secret) error {
-	if err := s.client.Get(ctx, types.NamespacedName{Namespace: secret.Namespace, Name: secret.Name}, secret); err != nil {
+	if err := s.ensureLBObject(ctx, secret); err != nil {
+		return err
+	}
+	return s.ensureLBObject(ctx, vm)
+}
+
+// ensureLBObject creates the object of the LB VM, or adds the owner reference of the
+// VirtualMachineService to it when the LB VM is shared, so that the object is deleted
+// with the last VirtualMachineService of the LB VM.
+func (s *Provider) ensureLBObject(ctx context.Context, obj client.Object) error {
+	ownerRefs := obj.GetOwnerReferences()
+	if err := s.client.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
 		if !apierrors.IsNotFound(err) {
 			return err
 		}
-		if err := s.client.Create(ctx, secret); err != nil {
-			return err
-		}
+		return s.client.Create(ctx, obj)
 	}
-	if err := s.client.Get(ctx, types.NamespacedName{Namespace: vm.Namespace, Name: vm.Name}, vm); err != nil {
-		if !apierrors.IsNotFound(err) {
-			return err
+
+	for _, ownerRef := range ownerRefs {
+		if hasOwnerRef(obj.GetOwnerReferences(), ownerRef) {
+			continue
 		}
-		if err := s.client.Create(ctx, vm); err != nil {
+		patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+		obj.SetOwnerReferences(append(obj.GetOwnerReferences(), ownerRef))
+		if err := s.client.Patch(ctx, obj, patch); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+func hasOwnerRef(ownerRefs []metav1.OwnerReference, ownerRef metav1.OwnerReference) bool {
+	for _, ref := range ownerRefs {
+		if ref.UID == ownerRef.UID && ref.Name == ownerRef.Name {
+			return true
+		}
+	}
+	return false
+}
+
 func makeVMServiceOwnerRef(vmService *vmopv1.VirtualMachineService) metav1.OwnerReference {
 	virtualMachineServiceKind := reflect.TypeOf(vmopv1.VirtualMachineService{}).Name()
 	virtualMachineServiceAPIVersion := vmopv1.SchemeGroupVersion.String()
@@ -156,7 +222,7 @@ func makeVMServiceOwnerRef(vmService *vmopv1.VirtualMachineService) metav1.Owner
 func loadbalancerVM(vmService *vmopv1.VirtualMachineService, vmClassName, vmImageName string) *vmopv1.VirtualMachine {
 	return &vmopv1.VirtualMachine{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:            vmService.Name + "-lb",
+			Name:            loadBalancerName(vmService) + "-lb",
 			Namespace:       vmService.Namespace,
 			OwnerReferences: []metav1.OwnerReference{makeVMServiceOwnerRef(vmService)},
 		},
@@ -191,7 +257,37 @@ func loadbalancerSecret(vmService *vmopv1.VirtualMachineService, params lbConfig
 }
 
 func metadataCMName(vmService *vmopv1.VirtualMachineService) string {
-	return vmService.Name + "-lb" + "-cloud-init"
+	return loadBalancerName(vmService) + "-lb" + "-cloud-init"
+}
+
+// loadBalancerName returns the name of the LB VM of the VirtualMachineService.
+func loadBalancerName(vmService *vmopv1.VirtualMachineService) string {
+	if name := vmService.Annotations[LoadBalancerNameAnnotation]; name != "" {
+		return name
+	}
+	return vmService.Name
+}
+
+// loadBalancerNodeID returns the xDS node ID of the LB VM of the VirtualMachineService.
+func loadBalancerNodeID(vmService *vmopv1.VirtualMachineService) string {
+	return lbNodeID(vmService.Namespace, loadBalancerName(vmService))
+}
+
+// lbNodeID returns the xDS node ID of the LB VM with the given name.
+func lbNodeID(namespace, lbName string) string {
+	return types.NamespacedName{Namespace: namespace, Name: lbName}.String()
+}
+
+// tlsServerNames returns the TLS server names that the TCP ports of the VirtualMachineService are
+// routed by.
+func tlsServerNames(vmService *vmopv1.VirtualMachineService) []string {
+	var serverNames []string
+	for _, name := range strings.Split(vmService.Annotations[TLSPassthroughServerNamesAnnotation], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			serverNames = append(serverNames, name)
+		}
+	}
+	return serverNames
 }
 
 func (s *Provider) ensureLBIP(ctx context.Context, vmService *vmopv1.VirtualMachineService, vm *vmopv1.VirtualMachine) error {
@@ -218,28 +314,165 @@ func (s *Provider) ensureLBIP(ctx context.Context, vmService *vmopv1.VirtualMach
 	return nil
 }
 
+// updateLBConfig updates the xDS control plane with the Services of all the VirtualMachineServices
+// that share the LB VM of the VirtualMachineService. When the LB VM of the VirtualMachineService
+// changed, its ports are removed from the previous LB VM.
 func (s *Provider) updateLBConfig(ctx context.Context, vmService *vmopv1.VirtualMachineService) error {
-	service := &corev1.Service{}
-	endpoints := &corev1.Endpoints{}
-	if err := s.client.Get(ctx, types.NamespacedName{
-		Namespace: vmService.Namespace,
-		Name:      vmService.Name,
-	}, service); err != nil {
-		if apierrors.IsNotFound(err) {
-			return nil // service is not ready yet
+	service, err := s.getLBService(ctx, vmService)
+	if err != nil || service == nil {
+		return err // service or endpoints are not ready yet
+	}
+
+	lbName := loadBalancerName(vmService)
+	if err := s.rebuildLBConfig(ctx, lbName, vmService, service); err != nil {
+		return err
+	}
+
+	if prevLBName := s.getLBName(vmService); prevLBName != "" && prevLBName != lbName {
+		if err := s.rebuildLBConfig(ctx, prevLBName, vmService, nil); err != nil {
+			return err
+		}
+	}
+	s.setLBName(vmService, lbName)
+
+	return nil
+}
+
+// EnsureLoadBalancerDeleted removes the ports of the deleted VirtualMachineService from its LB VM
+// when the LB VM is shared with other VirtualMachineServices. The LB VM itself is deleted with its
+// last VirtualMachineService by the owner references.
+func (s *Provider) EnsureLoadBalancerDeleted(ctx context.Context, vmService *vmopv1.VirtualMachineService) error {
+	s.log.Info("ensure load balancer deleted", "VMService", vmService.Name)
+	lbName := loadBalancerName(vmService)
+	if err := s.rebuildLBConfig(ctx, lbName, vmService, nil); err != nil {
+		return err
+	}
+
+	if prevLBName := s.getLBName(vmService); prevLBName != "" && prevLBName != lbName {
+		if err := s.rebuildLBConfig(ctx, prevLBName, vmService, nil); err != nil {
+			return err
 		}
+	}
+	s.setLBName(vmService, "")
+
+	return nil
+}
+
+// rebuildLBConfig updates the xDS control plane of the LB VM with the given name with the Services
+// of the other VirtualMachineServices that share the LB VM, and with the given Service of the
+// VirtualMachineService when it is not nil. A warning Event is recorded on the VirtualMachineServices
+// with ports that were skipped.
+func (s *Provider) rebuildLBConfig(
+	ctx context.Context,
+	lbName string,
+	vmService *vmopv1.VirtualMachineService,
+	service *lbService) error {
+
+	var services []lbService
+	vmServicesByName := map[string]*vmopv1.VirtualMachineService{}
+	if service != nil {
+		services = append(services, *service)
+		vmServicesByName[vmService.Name] = vmService
+	}
+
+	vmServices := &vmopv1.VirtualMachineServiceList{}
+	if err := s.client.List(ctx, vmServices, client.InNamespace(vmService.Namespace)); err != nil {
 		return err
 	}
-	if err := s.client.Get(ctx, types.NamespacedName{
-		Namespace: vmService.Namespace,
-		Name:      vmService.Name,
-	}, endpoints); err != nil {
-		if apierrors.IsNotFound(err) {
-			return nil // endpoints are not ready yet
+
+	for i := range vmServices.Items {
+		other := &vmServices.Items[i]
+		if other.Name == vmService.Name ||
+			other.Spec.Type != vmopv1.VirtualMachineServiceTypeLoadBalancer ||
+			!other.DeletionTimestamp.IsZero() ||
+			loadBalancerName(other) != lbName {
+			continue
 		}
+
+		service, err := s.getLBService(ctx, other)
+		if err != nil {
+			return err
+		}
+		if service != nil {
+			services = append(services, *service)
+			vmServicesByName[other.Name] = other
+		}
+	}
+
+	skipped, err := s.controlPlane.UpdateLoadBalancer(lbNodeID(vmService.Namespace, lbName), services)
+	if err != nil {
 		return err
 	}
-	return s.controlPlane.UpdateEndpoints(service, endpoints)
+
+	for name, err := range skipped {
+		if vmSvc, ok := vmServicesByName[name]; ok {
+			s.recorder.Warnf(vmSvc, PortSkippedReason, "Ports are not load balanced by LB VM %s: %v", lbName, err)
+		}
+	}
+	return nil
+}
+
+// getLBName returns the name of the LB VM that the ports of the VirtualMachineService were last
+// added to.
+func (s *Provider) getLBName(vmService *vmopv1.VirtualMachineService) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lbNames[vmService.NamespacedName()]
+}
+
+// setLBName sets the name of the LB VM that the ports of the VirtualMachineService were last added
+// to, or forgets it when the name is empty.
+func (s *Provider) setLBName(vmService *vmopv1.VirtualMachineService, lbName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if lbName == "" {
+		delete(s.lbNames, vmService.NamespacedName())
+		return
+	}
+	if s.lbNames == nil {
+		s.lbNames = map[string]string{}
+	}
+	s.lbNames[vmService.NamespacedName()] = lbName
+}
+
+// getLBService returns the Service and Endpoints of the VirtualMachineService, and the VMs of the
+// Endpoints. It returns nil when the Service or Endpoints do not exist yet.
+func (s *Provider) getLBService(ctx context.Context, vmService *vmopv1.VirtualMachineService) (*lbService, error) {
+	key := types.NamespacedName{Namespace: vmService.Namespace, Name: vmService.Name}
+
+	service := &corev1.Service{}
+	if err := s.client.Get(ctx, key, service); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	endpoints := &corev1.Endpoints{}
+	if err := s.client.Get(ctx, key, endpoints); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+
+	var vms []*vmopv1.VirtualMachine
+	for _, subset := range endpoints.Subsets {
+		for _, address := range subset.Addresses {
+			if address.TargetRef == nil {
+				continue
+			}
+			vm := &vmopv1.VirtualMachine{}
+			if err := s.client.Get(ctx, types.NamespacedName{Namespace: vmService.Namespace, Name: address.TargetRef.Name}, vm); err != nil {
+				if apierrors.IsNotFound(err) {
+					continue
+				}
+				return nil, err
+			}
+			vms = append(vms, vm)
+		}
+	}
+
+	return &lbService{
+		Service:        service,
+		Endpoints:      endpoints,
+		VMs:            vms,
+		TLSServerNames: tlsServerNames(vmService),
+	}, nil
 }
 
 func (s *Provider) getXDSNodes(ctx context.Context) ([]corev1.Node, error) {
@@ -250,30 +483,13 @@ func (s *Provider) getXDSNodes(ctx context.Context) ([]corev1.Node, error) {
 	return nodeList.Items, nil
 }
 
-func portName(vmSvcPort vmopv1.VirtualMachineServicePort) string {
-	if vmSvcPort.Name == "" {
-		protocol := vmSvcPort.Protocol
-		if protocol == "" {
-			protocol = string(corev1.ProtocolTCP)
-		}
-		return fmt.Sprintf("%s-%v", protocol, vmSvcPort.Port)
-	}
-	return vmSvcPort.Name
-}
-
 func getLBConfigParams(vmService *vmopv1.VirtualMachineService, nodes []corev1.Node) lbConfigParams {
 	var cpNodes = make([]string, len(nodes))
 	for i, node := range nodes {
 		cpNodes[i] = node.Status.Addresses[0].Address
 	}
-	ports := make([]vmopv1.VirtualMachineServicePort, len(vmService.Spec.Ports))
-	for i, port := range vmService.Spec.Ports {
-		port.Name = portName(port)
-		ports[i] = port
-	}
 	return lbConfigParams{
-		NodeID:      vmService.NamespacedName(),
-		Ports:       ports,
+		NodeID:      loadBalancerNodeID(vmService),
 		CPNodes:     cpNodes,
 		XdsNodePort: XdsNodePort,
 	}
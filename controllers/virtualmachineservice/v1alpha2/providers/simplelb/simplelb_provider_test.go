@@ -5,6 +5,7 @@ package simplelb
 
 import (
 	"context"
+	"errors"
 
 	"github.com/go-logr/logr"
 	. "github.com/onsi/ginkgo"
@@ -21,20 +22,21 @@ import (
 )
 
 type cpArgs struct {
-	service   *corev1.Service
-	endpoints *corev1.Endpoints
+	nodeID   string
+	services []lbService
 }
 
 type fakeControlPlane struct {
-	calls []cpArgs
+	calls   []cpArgs
+	skipped map[string]error
 }
 
-func (cp *fakeControlPlane) UpdateEndpoints(service *corev1.Service, endpoints *corev1.Endpoints) error {
+func (cp *fakeControlPlane) UpdateLoadBalancer(nodeID string, services []lbService) (map[string]error, error) {
 	cp.calls = append(cp.calls, cpArgs{
-		service:   service,
-		endpoints: endpoints,
+		nodeID:   nodeID,
+		services: services,
 	})
-	return nil
+	return cp.skipped, nil
 }
 
 var _ = Describe("", func() {
@@ -47,13 +49,15 @@ var _ = Describe("", func() {
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: testNs,
 			Name:      testSvc,
+			UID:       "test-svc-uid",
 		},
 		Spec: vmopv1.VirtualMachineServiceSpec{
+			Type: vmopv1.VirtualMachineServiceTypeLoadBalancer,
 			Ports: []vmopv1.VirtualMachineServicePort{{
 				Name:       "apiserver",
 				Port:       6443,
 				Protocol:   "TCP",
-				TargetPort: 6443,
+				TargetPort: intstr.FromInt(6443),
 			}},
 		},
 	}
@@ -61,9 +65,11 @@ var _ = Describe("", func() {
 	vm := &vmopv1.VirtualMachine{}
 	client := builder.NewFakeClient(vmService)
 	controlPlane := &fakeControlPlane{}
+	recorder, events := builder.NewFakeRecorder()
 	simpleLbProvider := Provider{
 		client:       client,
 		controlPlane: controlPlane,
+		recorder:     recorder,
 		log:          logr.Discard(),
 	}
 	vmImage := &vmopv1.VirtualMachineImage{
@@ -178,9 +184,126 @@ var _ = Describe("", func() {
 				Expect(err).ToNot(HaveOccurred())
 
 				Expect(controlPlane.calls).To(HaveLen(1))
-				Expect(controlPlane.calls[0].service.Name).To(Equal(svc.Name))
-				Expect(controlPlane.calls[0].endpoints.Name).To(Equal(eps.Name))
+				Expect(controlPlane.calls[0].nodeID).To(Equal(vmService.NamespacedName()))
+				Expect(controlPlane.calls[0].services).To(HaveLen(1))
+				Expect(controlPlane.calls[0].services[0].Service.Name).To(Equal(svc.Name))
+				Expect(controlPlane.calls[0].services[0].Endpoints.Name).To(Equal(eps.Name))
 			})
 		})
+
+		When("another VMService shares the LB VM", func() {
+			const (
+				otherSvc = "other-svc"
+				port     = 443
+			)
+			otherVMService := &vmopv1.VirtualMachineService{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: testNs,
+					Name:      otherSvc,
+					UID:       "other-svc-uid",
+					Annotations: map[string]string{
+						LoadBalancerNameAnnotation:          testSvc,
+						TLSPassthroughServerNamesAnnotation: "a.example.com, b.example.com",
+					},
+				},
+				Spec: vmopv1.VirtualMachineServiceSpec{
+					Type: vmopv1.VirtualMachineServiceTypeLoadBalancer,
+					Ports: []vmopv1.VirtualMachineServicePort{{
+						Name:       "https",
+						Port:       port,
+						Protocol:   "TCP",
+						TargetPort: intstr.FromInt(port),
+					}},
+				},
+			}
+			svc := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: testNs,
+					Name:      otherSvc,
+				},
+			}
+			eps := &corev1.Endpoints{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: testNs,
+					Name:      otherSvc,
+				},
+			}
+
+			It("should update the LB control plane with both Services", func() {
+				Expect(client.Create(context.TODO(), svc)).To(Succeed())
+				Expect(client.Create(context.TODO(), eps)).To(Succeed())
+				controlPlane.calls = nil
+
+				err := simpleLbProvider.EnsureLoadBalancer(context.TODO(), otherVMService)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(client.Get(context.TODO(), vmKey, vm)).To(Succeed())
+				Expect(vm.OwnerReferences).To(HaveLen(2))
+				Expect(vm.OwnerReferences[0].Name).To(Equal(testSvc))
+				Expect(vm.OwnerReferences[1].Name).To(Equal(otherSvc))
+
+				Expect(controlPlane.calls).To(HaveLen(1))
+				Expect(controlPlane.calls[0].nodeID).To(Equal(vmService.NamespacedName()))
+				Expect(controlPlane.calls[0].services).To(HaveLen(2))
+				Expect(controlPlane.calls[0].services[0].Service.Name).To(Equal(otherSvc))
+				Expect(controlPlane.calls[0].services[0].TLSServerNames).To(Equal([]string{"a.example.com", "b.example.com"}))
+				Expect(controlPlane.calls[0].services[1].Service.Name).To(Equal(testSvc))
+				Expect(controlPlane.calls[0].services[1].TLSServerNames).To(BeEmpty())
+			})
+
+			It("should record a warning Event on the VMService with skipped ports", func() {
+				controlPlane.calls = nil
+				controlPlane.skipped = map[string]error{testSvc: errors.New("listener TCP-443 is already used")}
+				defer func() {
+					controlPlane.skipped = nil
+				}()
+
+				Expect(simpleLbProvider.EnsureLoadBalancer(context.TODO(), otherVMService)).To(Succeed())
+
+				Expect(events).To(Receive(And(
+					ContainSubstring(PortSkippedReason),
+					ContainSubstring("listener TCP-443 is already used"),
+				)))
+				Expect(events).ToNot(Receive())
+			})
+
+			It("should remove the ports of the VMService from the previous LB VM when its LB VM changes", func() {
+				movedVMService := otherVMService.DeepCopy()
+				movedVMService.Annotations[LoadBalancerNameAnnotation] = "new-lb"
+				controlPlane.calls = nil
+
+				Expect(simpleLbProvider.updateLBConfig(context.TODO(), movedVMService)).To(Succeed())
+
+				Expect(controlPlane.calls).To(HaveLen(2))
+				Expect(controlPlane.calls[0].nodeID).To(Equal(testNs + "/new-lb"))
+				Expect(controlPlane.calls[0].services).To(HaveLen(1))
+				Expect(controlPlane.calls[0].services[0].Service.Name).To(Equal(otherSvc))
+				Expect(controlPlane.calls[1].nodeID).To(Equal(vmService.NamespacedName()))
+				Expect(controlPlane.calls[1].services).To(HaveLen(1))
+				Expect(controlPlane.calls[1].services[0].Service.Name).To(Equal(testSvc))
+
+				By("removing the ports of the VMService when it is deleted", func() {
+					controlPlane.calls = nil
+
+					Expect(simpleLbProvider.EnsureLoadBalancerDeleted(context.TODO(), movedVMService)).To(Succeed())
+
+					Expect(controlPlane.calls).To(HaveLen(1))
+					Expect(controlPlane.calls[0].nodeID).To(Equal(testNs + "/new-lb"))
+					Expect(controlPlane.calls[0].services).To(BeEmpty())
+				})
+			})
+		})
+	})
+
+	Context("EnsureLoadBalancerDeleted()", func() {
+		It("should remove the ports of the VMService from the LB VM", func() {
+			controlPlane.calls = nil
+
+			Expect(simpleLbProvider.EnsureLoadBalancerDeleted(context.TODO(), vmService)).To(Succeed())
+
+			Expect(controlPlane.calls).To(HaveLen(1))
+			Expect(controlPlane.calls[0].nodeID).To(Equal(vmService.NamespacedName()))
+			Expect(controlPlane.calls[0].services).To(BeEmpty())
+		})
 	})
 })
@@ -6,18 +6,56 @@ package simplelb
 import (
 	"context"
 	"fmt"
+	"hash/fnv"
 	"net"
+	"sort"
+	"time"
 
 	envoy_api_v2 "github.com/envoyproxy/go-control-plane/envoy/api/v2"
 	envoy_api_v2_core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
 	envoy_api_v2_endpoint "github.com/envoyproxy/go-control-plane/envoy/api/v2/endpoint"
+	envoy_api_v2_listener "github.com/envoyproxy/go-control-plane/envoy/api/v2/listener"
+	envoy_config_filter_listener_tls_inspector_v2 "github.com/envoyproxy/go-control-plane/envoy/config/filter/listener/tls_inspector/v2"
+	envoy_config_filter_network_tcp_proxy_v2 "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/tcp_proxy/v2"
+	envoy_config_filter_udp_udp_proxy_v2alpha "github.com/envoyproxy/go-control-plane/envoy/config/filter/udp/udp_proxy/v2alpha"
+	envoy_type "github.com/envoyproxy/go-control-plane/envoy/type"
 	"github.com/envoyproxy/go-control-plane/pkg/cache"
 	xds "github.com/envoyproxy/go-control-plane/pkg/server"
+	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
 	"github.com/go-logr/logr"
 	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/runtime/protoimpl"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/types"
+	k8serrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+)
+
+const (
+	// xdsClusterName is the name of the static cluster of the xDS servers in the Envoy bootstrap config.
+	xdsClusterName = "xds_cluster"
+
+	// udpProxyListenerFilter is the name of the UDP proxy listener filter. There is no well known
+	// name for it in this version of the control plane.
+	udpProxyListenerFilter = "envoy.filters.udp_listener.udp_proxy"
+
+	// tlsTransportProtocol is the transport protocol that the TLS inspector detects for TLS
+	// connections.
+	tlsTransportProtocol = "tls"
+
+	clusterConnectTimeout = 250 * time.Millisecond
+
+	// The defaults of the VM readiness probe.
+	defaultHealthCheckTimeout  = 10 * time.Second
+	defaultHealthCheckInterval = 10 * time.Second
+	defaultMinHealthyStatus    = 200
+	defaultMaxHealthyStatus    = 399
 )
 
 type XdsServer struct {
@@ -43,6 +81,8 @@ func (x *XdsServer) Start(ctx context.Context) error {
 		return err
 	}
 
+	envoy_api_v2.RegisterListenerDiscoveryServiceServer(grpcServer, server)
+	envoy_api_v2.RegisterClusterDiscoveryServiceServer(grpcServer, server)
 	envoy_api_v2.RegisterEndpointDiscoveryServiceServer(grpcServer, server)
 
 	go func() {
@@ -53,79 +93,470 @@ func (x *XdsServer) Start(ctx context.Context) error {
 	return grpcServer.Serve(lis)
 }
 
-func (x *XdsServer) UpdateEndpoints(svc *corev1.Service, eps *corev1.Endpoints) error {
-	clusters := make([]cache.Resource, len(svc.Spec.Ports))
-	endpoints := make([]cache.Resource, len(svc.Spec.Ports))
-	for i, svcPort := range svc.Spec.Ports {
-		clusters[i] = cluster(svcPort)
-		endpoints[i] = clusterEndpoints(svcPort, eps.Subsets)
+// UpdateLoadBalancer sets the xDS snapshot of the LB VM with the given node ID to the listeners,
+// clusters and endpoints of the given Services. It returns the errors of the ports that were
+// skipped by the name of their Service.
+func (x *XdsServer) UpdateLoadBalancer(nodeID string, services []lbService) (map[string]error, error) {
+	snapshot, skipped, err := x.snapshot(services)
+	if err != nil {
+		return nil, err
 	}
 
-	nodeID := nodeID(svc)
-	snapshot := cache.NewSnapshot(eps.ResourceVersion, endpoints, clusters, nil, nil, nil)
-
 	x.log.V(5).Info("setting xds snapshot", "nodeID", nodeID, "snapshot", snapshot)
-	return x.snapshotCache.SetSnapshot(nodeID, snapshot)
+	return skipped, x.snapshotCache.SetSnapshot(nodeID, snapshot)
+}
+
+// listenerKey identifies a listener of the LB VM. The Services that share the LB VM share the
+// listener of a port.
+type listenerKey struct {
+	protocol corev1.Protocol
+	port     int32
+}
+
+func (k listenerKey) name() string {
+	return fmt.Sprintf("%s-%v", k.protocol, k.port)
+}
+
+// snapshot returns the snapshot of the Services. A port that conflicts with the port of an earlier
+// Service by name, or that has an unsupported protocol, is skipped, and its error is returned by
+// the name of its Service.
+func (x *XdsServer) snapshot(services []lbService) (cache.Snapshot, map[string]error, error) {
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].Service.Name < services[j].Service.Name
+	})
+
+	var clusters, endpoints []cache.Resource
+	listeners := map[listenerKey]*envoy_api_v2.Listener{}
+	skippedErrs := map[string][]error{}
+
+	for _, s := range services {
+		healthCheck, healthCheckPorts := healthCheck(s.VMs)
+
+		for _, svcPort := range s.Service.Spec.Ports {
+			key := listenerKey{protocol: svcPort.Protocol, port: svcPort.Port}
+			if key.protocol == "" {
+				key.protocol = corev1.ProtocolTCP
+			}
+			name := clusterName(s.Service, svcPort)
+
+			var err error
+			switch key.protocol {
+			case corev1.ProtocolTCP:
+				err = addTCPFilterChain(listeners, key, name, s.TLSServerNames)
+			case corev1.ProtocolUDP:
+				err = addUDPListener(listeners, key, name)
+			default:
+				err = fmt.Errorf("protocol %s is not supported", key.protocol)
+			}
+			if err != nil {
+				// Skip the port rather than failing the LB VM for the other Services.
+				x.log.Error(err, "skipping service port", "service", s.Service.Name, "port", svcPort.Name)
+				skippedErrs[s.Service.Name] = append(skippedErrs[s.Service.Name],
+					fmt.Errorf("port %s: %w", key.name(), err))
+				continue
+			}
+
+			clusters = append(clusters, cluster(name, healthCheck))
+			endpoints = append(endpoints, clusterEndpoints(name, svcPort, s.Endpoints.Subsets, healthCheckPorts))
+		}
+	}
+
+	keys := make([]listenerKey, 0, len(listeners))
+	for key := range listeners {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].name() < keys[j].name()
+	})
+	listenerResources := make([]cache.Resource, 0, len(keys))
+	for _, key := range keys {
+		listenerResources = append(listenerResources, listeners[key])
+	}
+
+	version, err := snapshotVersion(listenerResources, clusters, endpoints)
+	if err != nil {
+		return cache.Snapshot{}, nil, err
+	}
+
+	skipped := make(map[string]error, len(skippedErrs))
+	for name, errs := range skippedErrs {
+		skipped[name] = k8serrors.NewAggregate(errs)
+	}
+
+	return cache.NewSnapshot(version, endpoints, clusters, nil, listenerResources, nil), skipped, nil
 }
 
-func nodeID(svc *corev1.Service) string {
-	return types.NamespacedName{Namespace: svc.Namespace, Name: svc.Name}.String()
+// snapshotVersion returns a hash of the resources, so that the version only changes when the
+// resources do.
+func snapshotVersion(resources ...[]cache.Resource) (string, error) {
+	h := fnv.New64a()
+	for _, rs := range resources {
+		for _, r := range rs {
+			b, err := proto.MarshalOptions{Deterministic: true}.Marshal(protoimpl.X.ProtoMessageV2Of(r))
+			if err != nil {
+				return "", err
+			}
+			_, _ = h.Write(b)
+		}
+	}
+	return fmt.Sprintf("%x", h.Sum64()), nil
 }
 
-func clusterName(svcPort corev1.ServicePort) string {
-	if svcPort.Name == "" {
+func clusterName(svc *corev1.Service, svcPort corev1.ServicePort) string {
+	portName := svcPort.Name
+	if portName == "" {
 		protocol := string(svcPort.Protocol)
 		if protocol == "" {
 			protocol = string(corev1.ProtocolTCP)
 		}
-		return fmt.Sprintf("%s-%v", protocol, svcPort.Port)
+		portName = fmt.Sprintf("%s-%v", protocol, svcPort.Port)
 	}
-	return svcPort.Name
+	return svc.Name + "/" + portName
 }
 
-func clusterEndpoints(svcPort corev1.ServicePort, subsets []corev1.EndpointSubset) *envoy_api_v2.ClusterLoadAssignment {
+// addTCPFilterChain adds a filter chain that proxies TCP connections to the cluster to the
+// listener of the key. When the Service has TLS server names, the filter chain only matches TLS
+// connections to these server names, so that the listener can route the connections of several
+// Services without terminating TLS.
+func addTCPFilterChain(
+	listeners map[listenerKey]*envoy_api_v2.Listener,
+	key listenerKey,
+	clusterName string,
+	tlsServerNames []string) error {
+
+	l, ok := listeners[key]
+	if !ok {
+		l = listener(key, envoy_api_v2_core.SocketAddress_TCP)
+	}
+
+	for _, fc := range l.FilterChains {
+		if fc.FilterChainMatch == nil {
+			if len(tlsServerNames) == 0 {
+				return fmt.Errorf("listener %s is already used by cluster %s", key.name(), fc.Name)
+			}
+			continue
+		}
+		for _, existing := range fc.FilterChainMatch.ServerNames {
+			for _, serverName := range tlsServerNames {
+				if existing == serverName {
+					return fmt.Errorf("TLS server name %s of listener %s is already used by cluster %s",
+						serverName, key.name(), fc.Name)
+				}
+			}
+		}
+	}
+
+	tcpProxy, err := marshalAny(&envoy_config_filter_network_tcp_proxy_v2.TcpProxy{
+		StatPrefix: clusterName,
+		ClusterSpecifier: &envoy_config_filter_network_tcp_proxy_v2.TcpProxy_Cluster{
+			Cluster: clusterName,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	fc := &envoy_api_v2_listener.FilterChain{
+		Name: clusterName,
+		Filters: []*envoy_api_v2_listener.Filter{{
+			Name:       wellknown.TCPProxy,
+			ConfigType: &envoy_api_v2_listener.Filter_TypedConfig{TypedConfig: tcpProxy},
+		}},
+	}
+
+	if len(tlsServerNames) > 0 {
+		fc.FilterChainMatch = &envoy_api_v2_listener.FilterChainMatch{
+			ServerNames:       tlsServerNames,
+			TransportProtocol: tlsTransportProtocol,
+		}
+
+		if len(l.ListenerFilters) == 0 {
+			tlsInspector, err := marshalAny(&envoy_config_filter_listener_tls_inspector_v2.TlsInspector{})
+			if err != nil {
+				return err
+			}
+			l.ListenerFilters = []*envoy_api_v2_listener.ListenerFilter{{
+				Name:       wellknown.TlsInspector,
+				ConfigType: &envoy_api_v2_listener.ListenerFilter_TypedConfig{TypedConfig: tlsInspector},
+			}}
+		}
+	}
+
+	l.FilterChains = append(l.FilterChains, fc)
+	sort.SliceStable(l.FilterChains, func(i, j int) bool {
+		return l.FilterChains[i].Name < l.FilterChains[j].Name
+	})
+
+	// The TLS inspector waits for a ClientHello, so the connections of protocols where the server
+	// speaks first only fall through to the filter chain without server names on its timeout.
+	for _, fc := range l.FilterChains {
+		if fc.FilterChainMatch == nil && len(l.ListenerFilters) > 0 {
+			l.ContinueOnListenerFiltersTimeout = true
+		}
+	}
+
+	listeners[key] = l
+	return nil
+}
+
+// addUDPListener adds a listener that proxies the UDP datagrams to the cluster.
+func addUDPListener(
+	listeners map[listenerKey]*envoy_api_v2.Listener,
+	key listenerKey,
+	clusterName string) error {
+
+	if _, ok := listeners[key]; ok {
+		return fmt.Errorf("listener %s is already used", key.name())
+	}
+
+	udpProxy, err := marshalAny(&envoy_config_filter_udp_udp_proxy_v2alpha.UdpProxyConfig{
+		StatPrefix: clusterName,
+		RouteSpecifier: &envoy_config_filter_udp_udp_proxy_v2alpha.UdpProxyConfig_Cluster{
+			Cluster: clusterName,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	l := listener(key, envoy_api_v2_core.SocketAddress_UDP)
+	l.ListenerFilters = []*envoy_api_v2_listener.ListenerFilter{{
+		Name:       udpProxyListenerFilter,
+		ConfigType: &envoy_api_v2_listener.ListenerFilter_TypedConfig{TypedConfig: udpProxy},
+	}}
+
+	listeners[key] = l
+	return nil
+}
+
+func listener(key listenerKey, protocol envoy_api_v2_core.SocketAddress_Protocol) *envoy_api_v2.Listener {
+	return &envoy_api_v2.Listener{
+		Name: key.name(),
+		Address: &envoy_api_v2_core.Address{
+			Address: &envoy_api_v2_core.Address_SocketAddress{
+				SocketAddress: &envoy_api_v2_core.SocketAddress{
+					Protocol: protocol,
+					Address:  "0.0.0.0",
+					PortSpecifier: &envoy_api_v2_core.SocketAddress_PortValue{
+						PortValue: uint32(key.port),
+					},
+				},
+			},
+		},
+	}
+}
+
+// marshalAny marshals the Envoy config into an Any. The Envoy configs of this version of the
+// control plane are not generated with the protobuf reflection API, so they need to be wrapped.
+func marshalAny(m cache.Resource) (*anypb.Any, error) {
+	return anypb.New(protoimpl.X.ProtoMessageV2Of(m))
+}
+
+func clusterEndpoints(
+	clusterName string,
+	svcPort corev1.ServicePort,
+	subsets []corev1.EndpointSubset,
+	healthCheckPorts map[string]uint32) *envoy_api_v2.ClusterLoadAssignment {
+
 	var lbEndpoints []*envoy_api_v2_endpoint.LbEndpoint
 
 	for _, subset := range subsets {
 		for _, endpointPort := range subset.Ports {
-			if endpointPort.Port != svcPort.TargetPort.IntVal {
+			// The target port may be named and resolved to a different port for each VM, so
+			// match the Endpoints port by the name of the Service port.
+			if endpointPort.Name != svcPort.Name {
 				continue
 			}
 			for _, endpointAddress := range subset.Addresses {
-				lbEndpoints = append(lbEndpoints, &envoy_api_v2_endpoint.LbEndpoint{
-					HostIdentifier: &envoy_api_v2_endpoint.LbEndpoint_Endpoint{
-						Endpoint: &envoy_api_v2_endpoint.Endpoint{
-							Address: &envoy_api_v2_core.Address{
-								Address: &envoy_api_v2_core.Address_SocketAddress{
-									SocketAddress: &envoy_api_v2_core.SocketAddress{
-										Protocol: envoy_api_v2_core.SocketAddress_TCP,
-										Address:  endpointAddress.IP,
-										PortSpecifier: &envoy_api_v2_core.SocketAddress_PortValue{
-											PortValue: uint32(endpointPort.Port),
-										},
-									},
+				endpoint := &envoy_api_v2_endpoint.Endpoint{
+					Address: &envoy_api_v2_core.Address{
+						Address: &envoy_api_v2_core.Address_SocketAddress{
+							SocketAddress: &envoy_api_v2_core.SocketAddress{
+								Protocol: envoy_api_v2_core.SocketAddress_TCP,
+								Address:  endpointAddress.IP,
+								PortSpecifier: &envoy_api_v2_core.SocketAddress_PortValue{
+									PortValue: uint32(endpointPort.Port),
 								},
 							},
 						},
 					},
+				}
+				if ref := endpointAddress.TargetRef; ref != nil {
+					if port, ok := healthCheckPorts[ref.Name]; ok {
+						endpoint.HealthCheckConfig = &envoy_api_v2_endpoint.Endpoint_HealthCheckConfig{
+							PortValue: port,
+						}
+					}
+				}
+
+				lbEndpoints = append(lbEndpoints, &envoy_api_v2_endpoint.LbEndpoint{
+					HostIdentifier: &envoy_api_v2_endpoint.LbEndpoint_Endpoint{
+						Endpoint: endpoint,
+					},
 				})
 			}
 		}
 	}
 
 	return &envoy_api_v2.ClusterLoadAssignment{
-		ClusterName: clusterName(svcPort),
+		ClusterName: clusterName,
 		Endpoints: []*envoy_api_v2_endpoint.LocalityLbEndpoints{{
 			LbEndpoints: lbEndpoints,
 		}},
 	}
 }
 
-func cluster(svcPort corev1.ServicePort) *envoy_api_v2.Cluster {
-	return &envoy_api_v2.Cluster{
-		Name: clusterName(svcPort),
+func cluster(name string, healthCheck *envoy_api_v2_core.HealthCheck) *envoy_api_v2.Cluster {
+	c := &envoy_api_v2.Cluster{
+		Name: name,
 		ClusterDiscoveryType: &envoy_api_v2.Cluster_Type{
 			Type: envoy_api_v2.Cluster_EDS,
 		},
+		EdsClusterConfig: &envoy_api_v2.Cluster_EdsClusterConfig{
+			EdsConfig: &envoy_api_v2_core.ConfigSource{
+				ConfigSourceSpecifier: &envoy_api_v2_core.ConfigSource_ApiConfigSource{
+					ApiConfigSource: &envoy_api_v2_core.ApiConfigSource{
+						ApiType: envoy_api_v2_core.ApiConfigSource_GRPC,
+						GrpcServices: []*envoy_api_v2_core.GrpcService{{
+							TargetSpecifier: &envoy_api_v2_core.GrpcService_EnvoyGrpc_{
+								EnvoyGrpc: &envoy_api_v2_core.GrpcService_EnvoyGrpc{
+									ClusterName: xdsClusterName,
+								},
+							},
+						}},
+					},
+				},
+			},
+		},
+		ConnectTimeout: durationpb.New(clusterConnectTimeout),
+		LbPolicy:       envoy_api_v2.Cluster_ROUND_ROBIN,
+	}
+
+	if healthCheck != nil {
+		c.HealthChecks = []*envoy_api_v2_core.HealthCheck{healthCheck}
+	}
+
+	return c
+}
+
+// healthCheck returns the active health check of the clusters of a Service, mapped from the
+// readiness probes of the VMs of the Service, and the port of the health check of each VM by
+// name. The VMs are only health checked when they all have a TCP socket or all have an HTTP GET
+// readiness probe. The settings of the health check are from the probe of the first VM by name,
+// but each VM is checked on the port of its own probe.
+func healthCheck(vms []*vmopv1.VirtualMachine) (*envoy_api_v2_core.HealthCheck, map[string]uint32) {
+	if len(vms) == 0 {
+		return nil, nil
+	}
+
+	sort.Slice(vms, func(i, j int) bool {
+		return vms[i].Name < vms[j].Name
+	})
+
+	probe := vms[0].Spec.ReadinessProbe
+	if probe == nil || (probe.TCPSocket == nil && probe.HTTPGet == nil) {
+		return nil, nil
+	}
+
+	ports := make(map[string]uint32, len(vms))
+	for _, vm := range vms {
+		p := vm.Spec.ReadinessProbe
+		if p == nil {
+			return nil, nil
+		}
+
+		var port intstr.IntOrString
+		switch {
+		case probe.TCPSocket != nil && p.TCPSocket != nil:
+			port = p.TCPSocket.Port
+		case probe.HTTPGet != nil && p.HTTPGet != nil:
+			port = p.HTTPGet.Port
+		default:
+			return nil, nil
+		}
+
+		portNum, ok := vmPortNum(vm, port)
+		if !ok {
+			return nil, nil
+		}
+		ports[vm.Name] = portNum
+	}
+
+	timeout := defaultHealthCheckTimeout
+	if probe.TimeoutSeconds > 0 {
+		timeout = time.Duration(probe.TimeoutSeconds) * time.Second
+	}
+	interval := defaultHealthCheckInterval
+	if probe.PeriodSeconds > 0 {
+		interval = time.Duration(probe.PeriodSeconds) * time.Second
+	}
+
+	// Like the VM readiness, the health changes on a single check.
+	hc := &envoy_api_v2_core.HealthCheck{
+		Timeout:            durationpb.New(timeout),
+		Interval:           durationpb.New(interval),
+		UnhealthyThreshold: wrapperspb.UInt32(1),
+		HealthyThreshold:   wrapperspb.UInt32(1),
+	}
+
+	// The HTTPS probes are only checked for a connection, because the TLS is passed through to the VMs.
+	if probe.HTTPGet != nil && probe.HTTPGet.Scheme != vmopv1.URISchemeHTTPS {
+		hc.HealthChecker = &envoy_api_v2_core.HealthCheck_HttpHealthCheck_{
+			HttpHealthCheck: httpHealthCheck(probe.HTTPGet),
+		}
+	} else {
+		hc.HealthChecker = &envoy_api_v2_core.HealthCheck_TcpHealthCheck_{
+			TcpHealthCheck: &envoy_api_v2_core.HealthCheck_TcpHealthCheck{},
+		}
+	}
+
+	return hc, ports
+}
+
+func httpHealthCheck(httpGet *vmopv1.HTTPGetAction) *envoy_api_v2_core.HealthCheck_HttpHealthCheck {
+	path := httpGet.Path
+	if path == "" {
+		path = "/"
+	}
+
+	minStatus, maxStatus := int64(defaultMinHealthyStatus), int64(defaultMaxHealthyStatus)
+	if httpGet.ExpectedStatus != nil {
+		minStatus, maxStatus = int64(httpGet.ExpectedStatus.Min), int64(httpGet.ExpectedStatus.Max)
+	}
+
+	hc := &envoy_api_v2_core.HealthCheck_HttpHealthCheck{
+		Path: path,
+		ExpectedStatuses: []*envoy_type.Int64Range{{
+			Start: minStatus,
+			End:   maxStatus + 1,
+		}},
+	}
+
+	for _, header := range httpGet.HTTPHeaders {
+		hc.RequestHeadersToAdd = append(hc.RequestHeadersToAdd, &envoy_api_v2_core.HeaderValueOption{
+			Header: &envoy_api_v2_core.HeaderValue{
+				Key:   header.Name,
+				Value: header.Value,
+			},
+			Append: wrapperspb.Bool(false),
+		})
+	}
+
+	return hc
+}
+
+// vmPortNum returns the number of the port of the VM, resolving a named port from the ports of
+// the VM.
+func vmPortNum(vm *vmopv1.VirtualMachine, port intstr.IntOrString) (uint32, bool) {
+	if port.Type == intstr.Int {
+		return uint32(port.IntVal), port.IntVal > 0
+	}
+
+	for _, vmPort := range vm.Spec.Ports {
+		if vmPort.Name == port.StrVal &&
+			(vmPort.Protocol == "" || vmPort.Protocol == string(corev1.ProtocolTCP)) {
+			return uint32(vmPort.Port), true
+		}
 	}
+	return 0, false
 }
@@ -4,7 +4,10 @@
 package simplelb
 
 import (
+	envoy_api_v2 "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	envoy_api_v2_core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
 	"github.com/envoyproxy/go-control-plane/pkg/cache"
+	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
 	"github.com/go-logr/logr"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -12,75 +15,401 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
 )
 
 var _ = Describe("xdsServer", func() {
 	const (
-		testNs       = "test-ns"
-		testSvc      = "test-svc"
-		epResVersion = "123"
-		port         = 6443
-		portName     = "apiserver"
-		ip1          = "10.11.12.13"
-		ip2          = "21.22.23.24"
+		testNs   = "test-ns"
+		testSvc  = "test-svc"
+		testNode = testNs + "/" + testSvc
+		port     = 6443
+		portName = "apiserver"
+		ip1      = "10.11.12.13"
+		ip2      = "21.22.23.24"
+		vm1      = "vm1"
+		vm2      = "vm2"
+	)
+
+	var (
+		x        *XdsServer
+		services []lbService
+		skipped  map[string]error
 	)
 
-	x := &XdsServer{
-		snapshotCache: cache.NewSnapshotCache(false, cache.IDHash{}, nil),
-		log:           logr.Discard(),
+	updateLoadBalancer := func() {
+		var err error
+		skipped, err = x.UpdateLoadBalancer(testNode, services)
+		Expect(err).ToNot(HaveOccurred())
 	}
 
-	svc := &corev1.Service{
-		ObjectMeta: metav1.ObjectMeta{
-			Namespace: testNs,
-			Name:      testSvc,
-		},
-		Spec: corev1.ServiceSpec{
-			Ports: []corev1.ServicePort{{
-				Name:     portName,
-				Protocol: "TCP",
-				Port:     port,
-				TargetPort: intstr.IntOrString{
-					Type:   intstr.Int,
-					IntVal: port,
+	newService := func(name string, ports ...corev1.ServicePort) lbService {
+		svc := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: testNs,
+				Name:      name,
+			},
+			Spec: corev1.ServiceSpec{
+				Ports: ports,
+			},
+		}
+
+		eps := &corev1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: testNs,
+				Name:      name,
+			},
+		}
+		for _, p := range ports {
+			eps.Subsets = append(eps.Subsets, corev1.EndpointSubset{
+				Addresses: []corev1.EndpointAddress{
+					{IP: ip1, TargetRef: &corev1.ObjectReference{Name: vm1}},
+					{IP: ip2, TargetRef: &corev1.ObjectReference{Name: vm2}},
 				},
-			}},
-		},
-	}
-	eps := &corev1.Endpoints{
-		ObjectMeta: metav1.ObjectMeta{
-			Namespace:       testNs,
-			Name:            testSvc,
-			ResourceVersion: epResVersion,
-		},
-		Subsets: []corev1.EndpointSubset{{
-			Addresses: []corev1.EndpointAddress{{IP: ip1}, {IP: ip2}},
-			Ports: []corev1.EndpointPort{{
-				Name: portName,
-				Port: port,
-			}},
-		}},
-	}
+				Ports: []corev1.EndpointPort{{
+					Name:     p.Name,
+					Protocol: p.Protocol,
+					Port:     p.TargetPort.IntVal,
+				}},
+			})
+		}
 
-	It("UpdateEndpoints()", func() {
-		err := x.UpdateEndpoints(svc, eps)
-		Expect(err).ToNot(HaveOccurred())
+		return lbService{Service: svc, Endpoints: eps}
+	}
 
-		snapshot, err := x.snapshotCache.GetSnapshot(nodeID(svc))
-		Expect(err).ToNot(HaveOccurred())
+	servicePort := func(name string, protocol corev1.Protocol, port int32) corev1.ServicePort {
+		return corev1.ServicePort{
+			Name:       name,
+			Protocol:   protocol,
+			Port:       port,
+			TargetPort: intstr.FromInt(int(port)),
+		}
+	}
 
-		err = snapshot.Consistent()
+	getSnapshot := func() cache.Snapshot {
+		snapshot, err := x.snapshotCache.GetSnapshot(testNode)
 		Expect(err).ToNot(HaveOccurred())
+		Expect(snapshot.Consistent()).To(Succeed())
+		return snapshot
+	}
 
-		Expect(snapshot.GetVersion(cache.EndpointType)).To(Equal(epResVersion))
-		Expect(snapshot.GetVersion(cache.ClusterType)).To(Equal(epResVersion))
+	getListener := func(snapshot cache.Snapshot, name string) *envoy_api_v2.Listener {
+		listeners := snapshot.GetResources(cache.ListenerType)
+		Expect(listeners).To(HaveKey(name))
+		return listeners[name].(*envoy_api_v2.Listener)
+	}
 
+	getCluster := func(snapshot cache.Snapshot, name string) *envoy_api_v2.Cluster {
 		clusters := snapshot.GetResources(cache.ClusterType)
+		Expect(clusters).To(HaveKey(name))
+		return clusters[name].(*envoy_api_v2.Cluster)
+	}
+
+	getEndpoints := func(snapshot cache.Snapshot, name string) *envoy_api_v2.ClusterLoadAssignment {
 		endpoints := snapshot.GetResources(cache.EndpointType)
-		Expect(clusters).To(HaveLen(1))
-		Expect(endpoints).To(HaveLen(1))
-		Expect(endpoints[portName]).ToNot(BeNil())
-		Expect(endpoints[portName].String()).To(ContainSubstring(ip1))
-		Expect(endpoints[portName].String()).To(ContainSubstring(ip2))
+		Expect(endpoints).To(HaveKey(name))
+		return endpoints[name].(*envoy_api_v2.ClusterLoadAssignment)
+	}
+
+	BeforeEach(func() {
+		x = &XdsServer{
+			snapshotCache: cache.NewSnapshotCache(false, cache.IDHash{}, nil),
+			log:           logr.Discard(),
+		}
+		services = []lbService{
+			newService(testSvc, servicePort(portName, corev1.ProtocolTCP, port)),
+		}
+	})
+
+	JustBeforeEach(func() {
+		updateLoadBalancer()
+	})
+
+	It("UpdateLoadBalancer()", func() {
+		snapshot := getSnapshot()
+
+		version := snapshot.GetVersion(cache.EndpointType)
+		Expect(version).ToNot(BeEmpty())
+		Expect(snapshot.GetVersion(cache.ClusterType)).To(Equal(version))
+		Expect(snapshot.GetVersion(cache.ListenerType)).To(Equal(version))
+
+		Expect(snapshot.GetResources(cache.ListenerType)).To(HaveLen(1))
+		Expect(snapshot.GetResources(cache.ClusterType)).To(HaveLen(1))
+		Expect(snapshot.GetResources(cache.EndpointType)).To(HaveLen(1))
+
+		clusterName := testSvc + "/" + portName
+		listener := getListener(snapshot, "TCP-6443")
+		Expect(listener.Address.GetSocketAddress().Protocol).To(Equal(envoy_api_v2_core.SocketAddress_TCP))
+		Expect(listener.Address.GetSocketAddress().GetPortValue()).To(BeEquivalentTo(port))
+		Expect(listener.ListenerFilters).To(BeEmpty())
+		Expect(listener.FilterChains).To(HaveLen(1))
+		Expect(listener.FilterChains[0].FilterChainMatch).To(BeNil())
+		Expect(listener.FilterChains[0].Filters).To(HaveLen(1))
+		Expect(listener.FilterChains[0].Filters[0].Name).To(Equal(wellknown.TCPProxy))
+		Expect(listener.FilterChains[0].Filters[0].GetTypedConfig().String()).To(ContainSubstring(clusterName))
+
+		cluster := getCluster(snapshot, clusterName)
+		Expect(cluster.GetType()).To(Equal(envoy_api_v2.Cluster_EDS))
+		Expect(cluster.EdsClusterConfig.EdsConfig.GetApiConfigSource().GrpcServices[0].GetEnvoyGrpc().ClusterName).To(Equal(xdsClusterName))
+		Expect(cluster.HealthChecks).To(BeEmpty())
+
+		endpoints := getEndpoints(snapshot, clusterName)
+		Expect(endpoints.String()).To(ContainSubstring(ip1))
+		Expect(endpoints.String()).To(ContainSubstring(ip2))
+	})
+
+	It("keeps the version when the resources are unchanged", func() {
+		getVersion := func() string {
+			snapshot := getSnapshot()
+			return snapshot.GetVersion(cache.ListenerType)
+		}
+		version := getVersion()
+
+		updateLoadBalancer()
+		Expect(getVersion()).To(Equal(version))
+
+		services[0].Endpoints.Subsets[0].Addresses = services[0].Endpoints.Subsets[0].Addresses[:1]
+		updateLoadBalancer()
+		Expect(getVersion()).ToNot(Equal(version))
+	})
+
+	When("the Service has a UDP port", func() {
+		BeforeEach(func() {
+			services = []lbService{
+				newService(testSvc, servicePort("dns", corev1.ProtocolUDP, 53), servicePort("dns-tcp", corev1.ProtocolTCP, 53)),
+			}
+		})
+
+		It("has a UDP listener", func() {
+			snapshot := getSnapshot()
+			Expect(snapshot.GetResources(cache.ListenerType)).To(HaveLen(2))
+			Expect(snapshot.GetResources(cache.ClusterType)).To(HaveLen(2))
+
+			listener := getListener(snapshot, "UDP-53")
+			Expect(listener.Address.GetSocketAddress().Protocol).To(Equal(envoy_api_v2_core.SocketAddress_UDP))
+			Expect(listener.FilterChains).To(BeEmpty())
+			Expect(listener.ListenerFilters).To(HaveLen(1))
+			Expect(listener.ListenerFilters[0].Name).To(Equal(udpProxyListenerFilter))
+			Expect(listener.ListenerFilters[0].GetTypedConfig().String()).To(ContainSubstring(testSvc + "/dns"))
+
+			listener = getListener(snapshot, "TCP-53")
+			Expect(listener.Address.GetSocketAddress().Protocol).To(Equal(envoy_api_v2_core.SocketAddress_TCP))
+		})
+	})
+
+	When("the Service has an SCTP port", func() {
+		BeforeEach(func() {
+			services[0] = newService(testSvc, servicePort(portName, corev1.ProtocolTCP, port), servicePort("sctp", corev1.ProtocolSCTP, 9999))
+		})
+
+		It("skips the port", func() {
+			Expect(skipped).To(HaveLen(1))
+			Expect(skipped).To(HaveKeyWithValue(testSvc, MatchError(ContainSubstring("protocol SCTP is not supported"))))
+
+			snapshot := getSnapshot()
+			Expect(snapshot.GetResources(cache.ListenerType)).To(HaveLen(1))
+			Expect(snapshot.GetResources(cache.ClusterType)).To(HaveLen(1))
+			Expect(snapshot.GetResources(cache.EndpointType)).To(HaveLen(1))
+		})
+	})
+
+	Context("Health checks", func() {
+		var vms []*vmopv1.VirtualMachine
+
+		BeforeEach(func() {
+			vms = []*vmopv1.VirtualMachine{
+				{ObjectMeta: metav1.ObjectMeta{Name: vm2}},
+				{ObjectMeta: metav1.ObjectMeta{Name: vm1}},
+			}
+		})
+
+		JustBeforeEach(func() {
+			services[0].VMs = vms
+			updateLoadBalancer()
+		})
+
+		When("the VMs have TCP readiness probes", func() {
+			BeforeEach(func() {
+				vms[0].Spec.ReadinessProbe = &vmopv1.VirtualMachineReadinessProbeSpec{
+					TCPSocket: &vmopv1.TCPSocketAction{Port: intstr.FromInt(8080)},
+				}
+				vms[1].Spec.Ports = []vmopv1.VirtualMachinePort{{Name: "health", Port: 9090}}
+				vms[1].Spec.ReadinessProbe = &vmopv1.VirtualMachineReadinessProbeSpec{
+					TCPSocket:      &vmopv1.TCPSocketAction{Port: intstr.FromString("health")},
+					TimeoutSeconds: 5,
+					PeriodSeconds:  30,
+				}
+			})
+
+			It("has a TCP health check on the probe port of each VM", func() {
+				snapshot := getSnapshot()
+
+				cluster := getCluster(snapshot, testSvc+"/"+portName)
+				Expect(cluster.HealthChecks).To(HaveLen(1))
+				hc := cluster.HealthChecks[0]
+				Expect(hc.GetTcpHealthCheck()).ToNot(BeNil())
+				Expect(hc.Timeout.AsDuration().Seconds()).To(BeEquivalentTo(5))
+				Expect(hc.Interval.AsDuration().Seconds()).To(BeEquivalentTo(30))
+				Expect(hc.UnhealthyThreshold.GetValue()).To(BeEquivalentTo(1))
+				Expect(hc.HealthyThreshold.GetValue()).To(BeEquivalentTo(1))
+
+				lbEndpoints := getEndpoints(snapshot, testSvc+"/"+portName).Endpoints[0].LbEndpoints
+				Expect(lbEndpoints).To(HaveLen(2))
+				Expect(lbEndpoints[0].GetEndpoint().Address.GetSocketAddress().Address).To(Equal(ip1))
+				Expect(lbEndpoints[0].GetEndpoint().HealthCheckConfig.PortValue).To(BeEquivalentTo(9090))
+				Expect(lbEndpoints[1].GetEndpoint().Address.GetSocketAddress().Address).To(Equal(ip2))
+				Expect(lbEndpoints[1].GetEndpoint().HealthCheckConfig.PortValue).To(BeEquivalentTo(8080))
+			})
+
+			When("a VM does not have a readiness probe", func() {
+				BeforeEach(func() {
+					vms[0].Spec.ReadinessProbe = nil
+				})
+
+				It("does not have a health check", func() {
+					snapshot := getSnapshot()
+					Expect(getCluster(snapshot, testSvc+"/"+portName).HealthChecks).To(BeEmpty())
+
+					for _, lbEndpoint := range getEndpoints(snapshot, testSvc+"/"+portName).Endpoints[0].LbEndpoints {
+						Expect(lbEndpoint.GetEndpoint().HealthCheckConfig).To(BeNil())
+					}
+				})
+			})
+		})
+
+		When("the VMs have HTTP GET readiness probes", func() {
+			BeforeEach(func() {
+				for _, vm := range vms {
+					vm.Spec.ReadinessProbe = &vmopv1.VirtualMachineReadinessProbeSpec{
+						HTTPGet: &vmopv1.HTTPGetAction{
+							Path: "/healthz",
+							Port: intstr.FromInt(8080),
+							HTTPHeaders: []vmopv1.HTTPHeader{
+								{Name: "X-Probe", Value: "lb"},
+							},
+							ExpectedStatus: &vmopv1.HTTPStatusCodeRange{Min: 200, Max: 204},
+						},
+					}
+				}
+			})
+
+			It("has an HTTP health check", func() {
+				cluster := getCluster(getSnapshot(), testSvc+"/"+portName)
+				Expect(cluster.HealthChecks).To(HaveLen(1))
+				hc := cluster.HealthChecks[0]
+				Expect(hc.Timeout.AsDuration()).To(Equal(defaultHealthCheckTimeout))
+				Expect(hc.Interval.AsDuration()).To(Equal(defaultHealthCheckInterval))
+
+				httpHC := hc.GetHttpHealthCheck()
+				Expect(httpHC).ToNot(BeNil())
+				Expect(httpHC.Path).To(Equal("/healthz"))
+				Expect(httpHC.ExpectedStatuses).To(HaveLen(1))
+				Expect(httpHC.ExpectedStatuses[0].Start).To(BeEquivalentTo(200))
+				Expect(httpHC.ExpectedStatuses[0].End).To(BeEquivalentTo(205))
+				Expect(httpHC.RequestHeadersToAdd).To(HaveLen(1))
+				Expect(httpHC.RequestHeadersToAdd[0].Header.Key).To(Equal("X-Probe"))
+				Expect(httpHC.RequestHeadersToAdd[0].Header.Value).To(Equal("lb"))
+			})
+
+			When("the probes use HTTPS", func() {
+				BeforeEach(func() {
+					for _, vm := range vms {
+						vm.Spec.ReadinessProbe.HTTPGet.Scheme = vmopv1.URISchemeHTTPS
+					}
+				})
+
+				It("has a TCP health check", func() {
+					cluster := getCluster(getSnapshot(), testSvc+"/"+portName)
+					Expect(cluster.HealthChecks).To(HaveLen(1))
+					Expect(cluster.HealthChecks[0].GetTcpHealthCheck()).ToNot(BeNil())
+				})
+			})
+
+			When("the VMs have different kinds of readiness probes", func() {
+				BeforeEach(func() {
+					vms[0].Spec.ReadinessProbe = &vmopv1.VirtualMachineReadinessProbeSpec{
+						TCPSocket: &vmopv1.TCPSocketAction{Port: intstr.FromInt(8080)},
+					}
+				})
+
+				It("does not have a health check", func() {
+					Expect(getCluster(getSnapshot(), testSvc+"/"+portName).HealthChecks).To(BeEmpty())
+				})
+			})
+		})
+	})
+
+	Context("Services sharing the LB VM", func() {
+		const otherSvc = "other-svc"
+
+		When("the Services use TLS passthrough on the same port", func() {
+			BeforeEach(func() {
+				services[0].TLSServerNames = []string{"a.example.com"}
+				other := newService(otherSvc, servicePort("https", corev1.ProtocolTCP, port))
+				other.TLSServerNames = []string{"b.example.com", "c.example.com"}
+				services = append(services, other)
+			})
+
+			It("routes the connections by the server name", func() {
+				snapshot := getSnapshot()
+				Expect(snapshot.GetResources(cache.ListenerType)).To(HaveLen(1))
+				Expect(snapshot.GetResources(cache.ClusterType)).To(HaveLen(2))
+				Expect(snapshot.GetResources(cache.EndpointType)).To(HaveLen(2))
+
+				listener := getListener(snapshot, "TCP-6443")
+				Expect(listener.ListenerFilters).To(HaveLen(1))
+				Expect(listener.ListenerFilters[0].Name).To(Equal(wellknown.TlsInspector))
+				Expect(listener.ContinueOnListenerFiltersTimeout).To(BeFalse())
+
+				Expect(listener.FilterChains).To(HaveLen(2))
+				Expect(listener.FilterChains[0].Name).To(Equal(otherSvc + "/https"))
+				Expect(listener.FilterChains[0].FilterChainMatch.ServerNames).To(Equal([]string{"b.example.com", "c.example.com"}))
+				Expect(listener.FilterChains[0].FilterChainMatch.TransportProtocol).To(Equal(tlsTransportProtocol))
+				Expect(listener.FilterChains[1].Name).To(Equal(testSvc + "/" + portName))
+				Expect(listener.FilterChains[1].FilterChainMatch.ServerNames).To(Equal([]string{"a.example.com"}))
+			})
+		})
+
+		When("one of the Services does not use TLS passthrough", func() {
+			BeforeEach(func() {
+				other := newService(otherSvc, servicePort("https", corev1.ProtocolTCP, port))
+				other.TLSServerNames = []string{"b.example.com"}
+				services = append(services, other)
+			})
+
+			It("routes the other connections to the Service without server names", func() {
+				listener := getListener(getSnapshot(), "TCP-6443")
+				Expect(listener.ListenerFilters).To(HaveLen(1))
+				Expect(listener.ContinueOnListenerFiltersTimeout).To(BeTrue())
+				Expect(listener.FilterChains).To(HaveLen(2))
+				Expect(listener.FilterChains[1].Name).To(Equal(testSvc + "/" + portName))
+				Expect(listener.FilterChains[1].FilterChainMatch).To(BeNil())
+			})
+		})
+
+		When("the Services conflict on the same port", func() {
+			BeforeEach(func() {
+				services = append(services,
+					newService(otherSvc, servicePort("https", corev1.ProtocolTCP, port), servicePort("dns", corev1.ProtocolUDP, 53)),
+					newService("z-svc", servicePort("dns", corev1.ProtocolUDP, 53)))
+			})
+
+			It("skips the ports of the later Services", func() {
+				Expect(skipped).To(HaveLen(2))
+				Expect(skipped).To(HaveKeyWithValue(testSvc, MatchError(ContainSubstring("listener TCP-6443 is already used"))))
+				Expect(skipped).To(HaveKeyWithValue("z-svc", MatchError(ContainSubstring("listener UDP-53 is already used"))))
+
+				snapshot := getSnapshot()
+				Expect(snapshot.GetResources(cache.ListenerType)).To(HaveLen(2))
+				Expect(snapshot.GetResources(cache.ClusterType)).To(HaveLen(2))
+
+				listener := getListener(snapshot, "TCP-6443")
+				Expect(listener.FilterChains).To(HaveLen(1))
+				Expect(listener.FilterChains[0].Name).To(Equal(otherSvc + "/https"))
+
+				getCluster(snapshot, otherSvc+"/dns")
+			})
+		})
 	})
 })
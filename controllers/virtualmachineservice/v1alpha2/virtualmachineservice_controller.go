@@ -6,16 +6,23 @@ package v1alpha2
 import (
 	goctx "context"
 	"fmt"
+	"net"
 	"os"
 	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	utilnet "k8s.io/utils/net"
 	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -38,9 +45,17 @@ import (
 const (
 	finalizerName = "virtualmachineservice.vmoperator.vmware.com"
 
+	// EndpointSliceManagedBy is the value of the managed-by label of the EndpointSlices that
+	// are managed by this controller.
+	EndpointSliceManagedBy = "virtualmachineservice.vmoperator.vmware.com"
+
 	OpCreate = "CreateK8sService"
 	OpDelete = "DeleteK8sService"
 	OpUpdate = "UpdateK8sService"
+
+	// VMPortNotFoundReason is the reason of the warning Event that is recorded when a VM is
+	// skipped because it does not have a named target port of the VirtualMachineService.
+	VMPortNotFoundReason = "VirtualMachinePortNotFound"
 )
 
 func AddToManager(ctx *context.ControllerManagerContext, mgr manager.Manager) error {
@@ -79,6 +94,8 @@ func AddToManager(ctx *context.ControllerManagerContext, mgr manager.Manager) er
 			handler.EnqueueRequestForOwner(mgr.GetScheme(), mgr.GetRESTMapper(), &vmopv1.VirtualMachineService{})).
 		Watches(&corev1.Endpoints{},
 			handler.EnqueueRequestForOwner(mgr.GetScheme(), mgr.GetRESTMapper(), &vmopv1.VirtualMachineService{})).
+		Watches(&discoveryv1.EndpointSlice{},
+			handler.EnqueueRequestForOwner(mgr.GetScheme(), mgr.GetRESTMapper(), &vmopv1.VirtualMachineService{})).
 		Watches(&vmopv1.VirtualMachine{},
 			handler.EnqueueRequestsFromMapFunc(r.virtualMachineToVirtualMachineServiceMapper())).
 		Complete(r)
@@ -115,6 +132,7 @@ type ReconcileVirtualMachineService struct {
 // +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=services/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups="",resources=endpoints,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=discovery.k8s.io,resources=endpointslices,verbs=get;list;watch;create;update;patch;delete;deletecollection
 
 func (r *ReconcileVirtualMachineService) Reconcile(ctx goctx.Context, request reconcile.Request) (_ reconcile.Result, reterr error) {
 	vmService := &vmopv1.VirtualMachineService{}
@@ -161,12 +179,26 @@ func (r *ReconcileVirtualMachineService) ReconcileDelete(ctx *context.VirtualMac
 			return err
 		}
 
+		endpointSlice := &discoveryv1.EndpointSlice{}
+		if err := r.Client.DeleteAllOf(ctx, endpointSlice, client.InNamespace(objectMeta.Namespace),
+			client.MatchingLabels(endpointSliceLabelSelector(objectMeta.Name))); err != nil {
+			ctx.Logger.Error(err, "Failed to delete EndpointSlices")
+			return err
+		}
+
 		service := &corev1.Service{ObjectMeta: objectMeta}
 		if err := r.Client.Delete(ctx, service); client.IgnoreNotFound(err) != nil {
 			ctx.Logger.Error(err, "Failed to delete Service")
 			return err
 		}
 
+		if ctx.VMService.Spec.Type == vmopv1.VirtualMachineServiceTypeLoadBalancer {
+			if err := r.loadbalancerProvider.EnsureLoadBalancerDeleted(ctx, ctx.VMService); err != nil {
+				ctx.Logger.Error(err, "Failed to delete LoadBalancer")
+				return err
+			}
+		}
+
 		ctx.Logger.Info("Delete VirtualMachineService")
 		r.recorder.EmitEvent(ctx.VMService, OpDelete, nil, false)
 		controllerutil.RemoveFinalizer(ctx.VMService, finalizerName)
@@ -260,6 +292,12 @@ func (r *ReconcileVirtualMachineService) reconcileVMService(ctx *context.Virtual
 		return err
 	}
 
+	err = r.createOrUpdateEndpointSlices(ctx, service)
+	if err != nil {
+		ctx.Logger.Error(err, "Failed to update VirtualMachineService EndpointSlices")
+		return err
+	}
+
 	err = r.updateVMService(ctx, service)
 	if err != nil {
 		ctx.Logger.Error(err, "Failed to update VirtualMachineService Status")
@@ -388,7 +426,13 @@ func (r *ReconcileVirtualMachineService) createOrUpdateService(ctx *context.Virt
 			return err
 		}
 
-		service.Spec.Type = corev1.ServiceType(vmService.Spec.Type)
+		switch vmService.Spec.Type {
+		case vmopv1.VirtualMachineServiceTypeHeadless:
+			// A headless Service is a ClusterIP Service without a cluster IP.
+			service.Spec.Type = corev1.ServiceTypeClusterIP
+		default:
+			service.Spec.Type = corev1.ServiceType(vmService.Spec.Type)
+		}
 		service.Spec.ExternalName = vmService.Spec.ExternalName
 		service.Spec.LoadBalancerIP = vmService.Spec.LoadBalancerIP
 		service.Spec.LoadBalancerSourceRanges = vmService.Spec.LoadBalancerSourceRanges
@@ -398,11 +442,24 @@ func (r *ReconcileVirtualMachineService) createOrUpdateService(ctx *context.Virt
 			service.Spec.AllocateLoadBalancerNodePorts = nil
 		}
 
+		// The IP families are defaulted by k8s based on the policy and the cluster configuration,
+		// so only set them when requested. The requested families replace the Service's so that
+		// the secondary family is removed when the VirtualMachineService no longer has it.
+		if len(vmService.Spec.IPFamilies) > 0 {
+			service.Spec.IPFamilies = toCoreIPFamilies(vmService.Spec.IPFamilies)
+		}
+		if policy := vmService.Spec.IPFamilyPolicy; policy != nil {
+			service.Spec.IPFamilyPolicy = (*corev1.IPFamilyPolicy)(policy)
+		}
+
 		// Parts of the Service.Spec can be updated by k8s after creation, and we need to
 		// preserve those fields.
 		if service.ResourceVersion == "" {
 			// ClusterIP cannot be changed through update.
 			service.Spec.ClusterIP = vmService.Spec.ClusterIP
+			if vmService.Spec.Type == vmopv1.VirtualMachineServiceTypeHeadless {
+				service.Spec.ClusterIP = corev1.ClusterIPNone
+			}
 		}
 
 		// Maintain the existing mapping of ServicePort -> NodePort as un-setting it will cause
@@ -418,14 +475,17 @@ func (r *ReconcileVirtualMachineService) createOrUpdateService(ctx *context.Virt
 				Name:       vmPort.Name,
 				Protocol:   corev1.Protocol(vmPort.Protocol),
 				Port:       vmPort.Port,
-				TargetPort: intstr.FromInt(int(vmPort.TargetPort)),
+				TargetPort: vmPort.TargetPort,
 				NodePort:   nodePortMap[vmPort.Name],
 			}
+			if vmPort.NodePort != 0 && service.Spec.Type == corev1.ServiceTypeNodePort {
+				servicePort.NodePort = vmPort.NodePort
+			}
 			servicePorts = append(servicePorts, servicePort)
 		}
 		service.Spec.Ports = servicePorts
 
-		// This is the default that k8s would otherwise set (note that we don't really support NodePort).
+		// This is the default that k8s would otherwise set.
 		// The only real purpose of this is if the AnnotationServiceExternalTrafficPolicyKey annotation
 		// below is removed, so that we switch the Service back to the default.
 		if service.Spec.Type == corev1.ServiceTypeNodePort || service.Spec.Type == corev1.ServiceTypeLoadBalancer {
@@ -555,7 +615,12 @@ func (r *ReconcileVirtualMachineService) createOrUpdateEndpoints(ctx *context.Vi
 
 		// NCP apparently needs the same Labels as what is present on the Service, and I'm not aware
 		// of anything else setting Labels, so just sync the Labels (and Annotations) with the Service.
-		endpoints.Labels = service.Labels
+		endpoints.Labels = make(map[string]string, len(service.Labels)+1)
+		for k, v := range service.Labels {
+			endpoints.Labels[k] = v
+		}
+		// We manage the EndpointSlices ourselves so the Endpoints must not be mirrored.
+		endpoints.Labels[discoveryv1.LabelSkipMirror] = "true"
 		endpoints.Annotations = service.Annotations
 		endpoints.Subsets = subsets
 		return nil
@@ -575,10 +640,21 @@ func (r *ReconcileVirtualMachineService) createOrUpdateEndpoints(ctx *context.Vi
 	return nil
 }
 
-func findVMPortNum(_ *vmopv1.VirtualMachine, port intstr.IntOrString, _ corev1.Protocol) (int, error) {
+// findVMPortNum returns the number of the VM's port for the target port of a Service. A named
+// port is resolved from the VM's ports with the same name and protocol.
+func findVMPortNum(vm *vmopv1.VirtualMachine, port intstr.IntOrString, portProto corev1.Protocol) (int, error) {
 	switch port.Type {
 	case intstr.String:
-		// Not supported.
+		for _, vmPort := range vm.Spec.Ports {
+			protocol := corev1.Protocol(vmPort.Protocol)
+			if protocol == "" {
+				protocol = corev1.ProtocolTCP
+			}
+			if vmPort.Name == port.StrVal && protocol == portProto {
+				return int(vmPort.Port), nil
+			}
+		}
+		return 0, fmt.Errorf("VM does not have port %q with protocol %s", port.StrVal, portProto)
 	case intstr.Int:
 		return port.IntValue(), nil
 	}
@@ -586,6 +662,22 @@ func findVMPortNum(_ *vmopv1.VirtualMachine, port intstr.IntOrString, _ corev1.P
 	return 0, fmt.Errorf("no matching port on VM")
 }
 
+// vmEndpointPorts returns the Endpoints ports of the VM for the ports of the Service. An error is
+// returned if a target port of the Service cannot be resolved for the VM.
+func vmEndpointPorts(vm *vmopv1.VirtualMachine, service *corev1.Service) ([]corev1.EndpointPort, error) {
+	ports := make([]corev1.EndpointPort, 0, len(service.Spec.Ports))
+	for _, servicePort := range service.Spec.Ports {
+		portNum, err := findVMPortNum(vm, servicePort.TargetPort, servicePort.Protocol)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find target port of service port %q: %w", servicePort.Name, err)
+		}
+
+		ports = append(ports,
+			corev1.EndpointPort{Name: servicePort.Name, Port: int32(portNum), Protocol: servicePort.Protocol})
+	}
+	return ports, nil
+}
+
 // generateSubsetsForService generates Endpoints subsets for a given Service.
 func (r *ReconcileVirtualMachineService) generateSubsetsForService(
 	ctx *context.VirtualMachineServiceContextA2,
@@ -624,75 +716,387 @@ func (r *ReconcileVirtualMachineService) generateSubsetsForService(
 			continue
 		}
 
-		// If the VM has a ReadinessProbe and Ready condition, ready is a reflection of the condition
-		// status. If the VM has a ReadinessProbe but no condition, we assume that the prober just
-		// hasn't run against the VM yet, so infer the VM's readiness if it was previously in the EP;
-		// this is to handle upgrade scenarios.
-		// Otherwise, a VM that does not have a ReadinessProbe is implicitly ready.
-		ready := true
-
-		if probe := vm.Spec.ReadinessProbe; probe != nil && (probe.TCPSocket != nil || probe.GuestHeartbeat != nil || len(probe.GuestInfo) != 0) {
-			if condition := conditions.Get(&vm, vmopv1.ReadyConditionType); condition == nil {
-				if vmInSubsetsMap == nil {
-					vmInSubsetsMap = r.getVMsReferencedByServiceEndpoints(ctx, service)
-				}
-
-				// If this VM was previously in the EP subset, preserve its readiness until prober
-				// updates the condition (the probe used to be done inline here before we had a
-				// Ready condition).
-				_, ready = vmInSubsetsMap[vm.UID]
-			} else {
-				ready = condition.Status == metav1.ConditionTrue
-			}
+		ports, err := vmEndpointPorts(&vm, service)
+		if err != nil {
+			logger.Info("Skipping VM without the ports of the service", "error", err.Error())
+			r.recorder.Warnf(ctx.VMService, VMPortNotFoundReason,
+				"Skipping VirtualMachine %s: %v", vm.Name, err)
+			continue
 		}
 
+		ready := r.isVMReady(ctx, service, &vm, &vmInSubsetsMap)
+
 		epa := corev1.EndpointAddress{
-			IP: vmIP,
-			TargetRef: &corev1.ObjectReference{
-				APIVersion: vm.APIVersion,
-				Kind:       vm.Kind,
-				Namespace:  vm.Namespace,
-				Name:       vm.Name,
-				UID:        vm.UID,
-				// NOTE: This currently isn't set to limit downstream reconcile churn in things
-				// watching these Endpoints but isn't ideal. We should be smarter and only update
-				// this when something relevant to the service, e.g. the VM's IP, changes.
-				// ResourceVersion: vm.ResourceVersion,
-			},
+			IP:        vmIP,
+			Hostname:  endpointHostName(logger, ctx.VMService, &vm),
+			TargetRef: vmObjectReference(&vm),
 		}
 
 		// Populate the EP subset for this VM. We create one subset for each VM, and then our
 		// caller will repack the subsets that have identical ports.
-		subset := corev1.EndpointSubset{}
+		subset := corev1.EndpointSubset{Ports: ports}
 		if ready {
 			subset.Addresses = []corev1.EndpointAddress{epa}
 		} else {
 			subset.NotReadyAddresses = []corev1.EndpointAddress{epa}
 		}
 
-		// TODO: Headless support
-		for _, servicePort := range service.Spec.Ports {
-			portName := servicePort.Name
-			portProto := servicePort.Protocol
+		subsets = append(subsets, subset)
+	}
+
+	return subsets, nil
+}
+
+// createOrUpdateEndpointSlices updates the EndpointSlices for VirtualMachineService. There is one
+// EndpointSlice for each IP family of the Service and each distinct set of ports of its VMs, that
+// has the addresses of that family of the selected VMs.
+func (r *ReconcileVirtualMachineService) createOrUpdateEndpointSlices(ctx *context.VirtualMachineServiceContextA2, service *corev1.Service) error {
+	ctx.Logger.V(5).Info("Updating VirtualMachineService EndpointSlices")
+	defer ctx.Logger.V(5).Info("Finished updating VirtualMachineService EndpointSlices")
+
+	if len(ctx.VMService.Spec.Selector) == 0 {
+		ctx.Logger.V(5).Info("Selectorless VirtualMachineService so skipping EndpointSlices reconciliation")
+		return nil
+	}
+
+	desiredSlices, err := r.generateEndpointSlicesForService(ctx, service)
+	if err != nil {
+		return err
+	}
+
+	existingSlices := &discoveryv1.EndpointSliceList{}
+	if err := r.List(ctx, existingSlices, client.InNamespace(service.Namespace),
+		client.MatchingLabels(endpointSliceLabelSelector(service.Name))); err != nil {
+		return err
+	}
+
+	for i := range desiredSlices {
+		desired := &desiredSlices[i]
+
+		var slice *discoveryv1.EndpointSlice
+		for j := range existingSlices.Items {
+			existing := &existingSlices.Items[j]
+			if existing.AddressType == desired.AddressType && apiequality.Semantic.DeepEqual(existing.Ports, desired.Ports) {
+				slice = existing
+				existingSlices.Items = append(existingSlices.Items[:j], existingSlices.Items[j+1:]...)
+				break
+			}
+		}
+
+		if slice == nil {
+			slice = &discoveryv1.EndpointSlice{
+				ObjectMeta: metav1.ObjectMeta{
+					GenerateName: service.Name + "-",
+					Namespace:    service.Namespace,
+				},
+				AddressType: desired.AddressType,
+			}
+		}
 
-			logger.V(5).Info("ServicePort for VirtualMachine",
-				"port name", portName, "port proto", portProto)
+		origSlice := slice.DeepCopy()
+		if err := controllerutil.SetControllerReference(ctx.VMService, slice, r.Client.Scheme()); err != nil {
+			return err
+		}
+		slice.Labels = desired.Labels
+		slice.Ports = desired.Ports
+		slice.Endpoints = desired.Endpoints
 
-			portNum, err := findVMPortNum(&vm, servicePort.TargetPort, portProto)
-			if err != nil {
-				logger.Info("Failed to find port for service",
-					"name", portName, "protocol", portProto, "error", err)
+		if slice.Name == "" {
+			if err := r.Create(ctx, slice); err != nil {
+				return err
+			}
+			ctx.Logger.Info("Creating Service EndpointSlice", "endpointSlice", slice)
+		} else if !apiequality.Semantic.DeepEqual(origSlice, slice) {
+			if err := r.Patch(ctx, slice, client.MergeFrom(origSlice)); err != nil {
+				return err
+			}
+			ctx.Logger.Info("Updating Service EndpointSlice", "endpointSlice", slice)
+		}
+	}
+
+	// Delete the EndpointSlices of the IP families and ports that are no longer in use.
+	for i := range existingSlices.Items {
+		slice := &existingSlices.Items[i]
+		if err := r.Delete(ctx, slice); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		ctx.Logger.Info("Deleting Service EndpointSlice", "endpointSlice", slice)
+	}
+
+	return nil
+}
+
+// generateEndpointSlicesForService generates the EndpointSlices for a given Service.
+func (r *ReconcileVirtualMachineService) generateEndpointSlicesForService(
+	ctx *context.VirtualMachineServiceContextA2,
+	service *corev1.Service) ([]discoveryv1.EndpointSlice, error) {
+
+	vmList, err := r.getVirtualMachinesSelectedByVMService(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sliceLabels := make(map[string]string, len(service.Labels)+2)
+	for k, v := range service.Labels {
+		sliceLabels[k] = v
+	}
+	for k, v := range endpointSliceLabelSelector(service.Name) {
+		sliceLabels[k] = v
+	}
+
+	ipFamilies := serviceIPFamilies(ctx.VMService, service)
+
+	var slices []discoveryv1.EndpointSlice
+	var vmInSubsetsMap map[types.UID]struct{}
+
+	for i := range vmList.Items {
+		vm := &vmList.Items[i]
+		logger := ctx.Logger.WithValues("virtualMachine", vm.NamespacedName())
+
+		addresses := vmAddressesByFamily(vm)
+		if len(addresses) == 0 {
+			logger.V(5).Info("Skipping VM without IP assigned for EndpointSlices")
+			continue
+		}
+
+		// A warning Event is recorded for a VM without the ports when the Endpoints are updated.
+		endpointPorts, err := vmEndpointPorts(vm, service)
+		if err != nil {
+			logger.V(5).Info("Skipping VM without the ports of the service for EndpointSlices", "error", err.Error())
+			continue
+		}
+
+		ports := make([]discoveryv1.EndpointPort, 0, len(endpointPorts))
+		for i := range endpointPorts {
+			ports = append(ports, discoveryv1.EndpointPort{
+				Name:     &endpointPorts[i].Name,
+				Protocol: &endpointPorts[i].Protocol,
+				Port:     &endpointPorts[i].Port,
+			})
+		}
+
+		epConditions := r.endpointConditions(ctx, service, vm, &vmInSubsetsMap)
+		var hostName *string
+		if h := endpointHostName(logger, ctx.VMService, vm); h != "" {
+			hostName = pointer.String(h)
+		}
+
+		for _, ipFamily := range ipFamilies {
+			if len(addresses[ipFamily]) == 0 {
 				continue
 			}
 
-			subset.Ports = append(subset.Ports,
-				corev1.EndpointPort{Name: portName, Port: int32(portNum), Protocol: portProto})
+			endpoint := discoveryv1.Endpoint{
+				Addresses:  addresses[ipFamily],
+				Conditions: epConditions,
+				Hostname:   hostName,
+				TargetRef:  vmObjectReference(vm),
+			}
+
+			addressType := discoveryv1.AddressType(ipFamily)
+			var slice *discoveryv1.EndpointSlice
+			for j := range slices {
+				if slices[j].AddressType == addressType && apiequality.Semantic.DeepEqual(slices[j].Ports, ports) {
+					slice = &slices[j]
+					break
+				}
+			}
+			if slice == nil {
+				slices = append(slices, discoveryv1.EndpointSlice{
+					ObjectMeta:  metav1.ObjectMeta{Labels: sliceLabels},
+					AddressType: addressType,
+					Ports:       ports,
+				})
+				slice = &slices[len(slices)-1]
+			}
+			slice.Endpoints = append(slice.Endpoints, endpoint)
 		}
+	}
 
-		subsets = append(subsets, subset)
+	// Keep the order of the endpoints stable to not update the EndpointSlices needlessly.
+	for i := range slices {
+		endpoints := slices[i].Endpoints
+		sort.Slice(endpoints, func(a, b int) bool {
+			return endpoints[a].TargetRef.Name < endpoints[b].TargetRef.Name
+		})
 	}
 
-	return subsets, nil
+	return slices, nil
+}
+
+// endpointConditions returns the conditions of the endpoint of a VM. A VM is serving when it is
+// powered on and ready, and terminating when it is being deleted or powered off. Only a serving
+// VM that is not terminating is ready.
+func (r *ReconcileVirtualMachineService) endpointConditions(
+	ctx *context.VirtualMachineServiceContextA2,
+	service *corev1.Service,
+	vm *vmopv1.VirtualMachine,
+	vmInSubsetsMap *map[types.UID]struct{}) discoveryv1.EndpointConditions {
+
+	poweredOn := vm.Status.PowerState == vmopv1.VirtualMachinePowerStateOn
+	serving := poweredOn && r.isVMReady(ctx, service, vm, vmInSubsetsMap)
+	terminating := !vm.DeletionTimestamp.IsZero() ||
+		(poweredOn && vm.Spec.PowerState != "" && vm.Spec.PowerState != vmopv1.VirtualMachinePowerStateOn)
+
+	return discoveryv1.EndpointConditions{
+		Ready:       pointer.Bool(serving && !terminating),
+		Serving:     pointer.Bool(serving),
+		Terminating: pointer.Bool(terminating),
+	}
+}
+
+// vmAddressesByFamily returns the IP addresses of the VM for each IP family. The primary IP of
+// a family is first, followed by the other addresses of the VM's network interfaces. Loopback,
+// link-local and unusable addresses are ignored.
+func vmAddressesByFamily(vm *vmopv1.VirtualMachine) map[corev1.IPFamily][]string {
+	addresses := map[corev1.IPFamily][]string{}
+	if vm.Status.Network == nil {
+		return addresses
+	}
+
+	seen := sets.Set[string]{}
+	addAddress := func(addr string) {
+		ip := net.ParseIP(addr)
+		if ip == nil {
+			// The addresses of the interfaces include the network prefix length.
+			ip, _, _ = net.ParseCIDR(addr)
+		}
+		if ip == nil || ip.IsUnspecified() || ip.IsLoopback() || ip.IsLinkLocalUnicast() {
+			return
+		}
+
+		ipStr := ip.String()
+		if seen.Has(ipStr) {
+			return
+		}
+		seen.Insert(ipStr)
+
+		ipFamily := corev1.IPv4Protocol
+		if utilnet.IsIPv6(ip) {
+			ipFamily = corev1.IPv6Protocol
+		}
+		addresses[ipFamily] = append(addresses[ipFamily], ipStr)
+	}
+
+	addAddress(vm.Status.Network.PrimaryIP4)
+	addAddress(vm.Status.Network.PrimaryIP6)
+	for _, iface := range vm.Status.Network.Interfaces {
+		for _, addr := range iface.IP.Addresses {
+			switch addr.State {
+			case "", "preferred", "unknown":
+				addAddress(addr.Address)
+			}
+		}
+	}
+
+	return addresses
+}
+
+// serviceIPFamilies returns the IP families of the Service. These are defaulted by k8s when
+// the Service is created, otherwise the families requested by the VirtualMachineService or
+// IPv4 are assumed.
+func serviceIPFamilies(vmService *vmopv1.VirtualMachineService, service *corev1.Service) []corev1.IPFamily {
+	if len(service.Spec.IPFamilies) > 0 {
+		return service.Spec.IPFamilies
+	}
+	if len(vmService.Spec.IPFamilies) > 0 {
+		return toCoreIPFamilies(vmService.Spec.IPFamilies)
+	}
+	return []corev1.IPFamily{corev1.IPv4Protocol}
+}
+
+func toCoreIPFamilies(ipFamilies []vmopv1.IPFamily) []corev1.IPFamily {
+	if len(ipFamilies) == 0 {
+		return nil
+	}
+
+	coreIPFamilies := make([]corev1.IPFamily, 0, len(ipFamilies))
+	for _, ipFamily := range ipFamilies {
+		coreIPFamilies = append(coreIPFamilies, corev1.IPFamily(ipFamily))
+	}
+	return coreIPFamilies
+}
+
+// endpointSliceLabelSelector returns the labels of the EndpointSlices of the Service that are
+// managed by this controller.
+func endpointSliceLabelSelector(serviceName string) map[string]string {
+	return map[string]string{
+		discoveryv1.LabelServiceName: serviceName,
+		discoveryv1.LabelManagedBy:   EndpointSliceManagedBy,
+	}
+}
+
+// isVMReady returns whether the VM is ready to receive traffic for the Service.
+// If the VM has a ReadinessProbe and Ready condition, ready is a reflection of the condition
+// status. If the VM has a ReadinessProbe but no condition, we assume that the prober just
+// hasn't run against the VM yet, so infer the VM's readiness if it was previously in the EP;
+// this is to handle upgrade scenarios.
+// Otherwise, a VM that does not have a ReadinessProbe is implicitly ready.
+func (r *ReconcileVirtualMachineService) isVMReady(
+	ctx *context.VirtualMachineServiceContextA2,
+	service *corev1.Service,
+	vm *vmopv1.VirtualMachine,
+	vmInSubsetsMap *map[types.UID]struct{}) bool {
+
+	probe := vm.Spec.ReadinessProbe
+	if probe == nil || (probe.TCPSocket == nil && probe.HTTPGet == nil &&
+		probe.GuestHeartbeat == nil && len(probe.GuestInfo) == 0) {
+		return true
+	}
+
+	if condition := conditions.Get(vm, vmopv1.ReadyConditionType); condition != nil {
+		return condition.Status == metav1.ConditionTrue
+	}
+
+	if *vmInSubsetsMap == nil {
+		*vmInSubsetsMap = r.getVMsReferencedByServiceEndpoints(ctx, service)
+	}
+
+	// If this VM was previously in the EP subset, preserve its readiness until prober
+	// updates the condition (the probe used to be done inline here before we had a
+	// Ready condition).
+	_, ready := (*vmInSubsetsMap)[vm.UID]
+	return ready
+}
+
+// vmObjectReference returns the reference to the VM that is the target of an endpoint.
+func vmObjectReference(vm *vmopv1.VirtualMachine) *corev1.ObjectReference {
+	return &corev1.ObjectReference{
+		APIVersion: vm.APIVersion,
+		Kind:       vm.Kind,
+		Namespace:  vm.Namespace,
+		Name:       vm.Name,
+		UID:        vm.UID,
+		// NOTE: This currently isn't set to limit downstream reconcile churn in things
+		// watching these Endpoints but isn't ideal. We should be smarter and only update
+		// this when something relevant to the service, e.g. the VM's IP, changes.
+		// ResourceVersion: vm.ResourceVersion,
+	}
+}
+
+// endpointHostName returns the host name the VM is published with in the DNS of a headless
+// Service, so that each VM has its own DNS name. An empty string is returned if the Service
+// is not headless or the host name is not a valid DNS label.
+func endpointHostName(logger logr.Logger, vmService *vmopv1.VirtualMachineService, vm *vmopv1.VirtualMachine) string {
+	if vmService.Spec.Type != vmopv1.VirtualMachineServiceTypeHeadless {
+		return ""
+	}
+
+	hostName := vmHostName(vm)
+	if errs := validation.IsDNS1123Label(hostName); len(errs) != 0 {
+		logger.Info("Skipping invalid host name for headless Service", "hostName", hostName, "errors", errs)
+		return ""
+	}
+	return hostName
+}
+
+// vmHostName returns the host name of the VM's guest, which is the name of the
+// VM unless a host name is specified.
+func vmHostName(vm *vmopv1.VirtualMachine) string {
+	if vm.Spec.Network != nil && vm.Spec.Network.HostName != "" {
+		return vm.Spec.Network.HostName
+	}
+	return vm.Name
 }
 
 // updateVMService syncs the VirtualMachineService Status from the Service status.
@@ -9,6 +9,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
@@ -43,7 +44,7 @@ func intgTests() {
 			Name:       "port1",
 			Protocol:   "TCP",
 			Port:       42,
-			TargetPort: 142,
+			TargetPort: intstr.FromInt(142),
 		}
 	})
 
@@ -228,7 +229,7 @@ func intgTests() {
 					Expect(subset.Ports).To(HaveLen(1))
 					port := subset.Ports[0]
 					Expect(port.Name).To(Equal(port.Name))
-					Expect(port.Port).To(BeEquivalentTo(vmServicePort.TargetPort))
+					Expect(port.Port).To(BeEquivalentTo(vmServicePort.TargetPort.IntVal))
 					Expect(port.Protocol).To(BeEquivalentTo(corev1.ProtocolTCP))
 				})
 
@@ -351,7 +352,7 @@ func intgTests() {
 					Expect(subset.Ports).To(HaveLen(1))
 					port := subset.Ports[0]
 					Expect(port.Name).To(Equal(port.Name))
-					Expect(port.Port).To(BeEquivalentTo(vmServicePort.TargetPort))
+					Expect(port.Port).To(BeEquivalentTo(vmServicePort.TargetPort.IntVal))
 					Expect(port.Protocol).To(BeEquivalentTo(corev1.ProtocolTCP))
 				})
 
@@ -10,9 +10,11 @@ import (
 	"github.com/onsi/gomega/types"
 
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	apiEquality "k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -85,14 +87,14 @@ func unitTestsReconcile() {
 			Name:       "port1",
 			Protocol:   "TCP",
 			Port:       42,
-			TargetPort: 142,
+			TargetPort: intstr.FromInt(142),
 		}
 
 		vmServicePort2 = vmopv1.VirtualMachineServicePort{
 			Name:       "port2",
 			Protocol:   "UDP",
 			Port:       1042,
-			TargetPort: 1142,
+			TargetPort: intstr.FromInt(1142),
 		}
 
 		lbSourceRanges = []string{"1.1.1.0/24", "2.2.0.0/16"}
@@ -193,13 +195,43 @@ func unitTestsReconcile() {
 					Expect(port.Name).To(Equal(vmServicePort1.Name))
 					Expect(port.Protocol).To(BeEquivalentTo(vmServicePort1.Protocol))
 					Expect(port.Port).To(Equal(vmServicePort1.Port))
-					Expect(port.TargetPort.IntValue()).To(Equal(int(vmServicePort1.TargetPort)))
+					Expect(port.TargetPort.IntValue()).To(Equal(vmServicePort1.TargetPort.IntValue()))
 
 					port = ports[1]
 					Expect(port.Name).To(Equal(vmServicePort2.Name))
 					Expect(port.Protocol).To(BeEquivalentTo(vmServicePort2.Protocol))
 					Expect(port.Port).To(Equal(vmServicePort2.Port))
-					Expect(port.TargetPort.IntValue()).To(Equal(int(vmServicePort2.TargetPort)))
+					Expect(port.TargetPort.IntValue()).To(Equal(vmServicePort2.TargetPort.IntValue()))
+				})
+			})
+
+			Context("Headless VirtualMachineService", func() {
+				BeforeEach(func() {
+					vmService.Spec.Type = vmopv1.VirtualMachineServiceTypeHeadless
+					vmService.Spec.ClusterIP = ""
+				})
+
+				It("Service is ClusterIP without cluster IP", func() {
+					Expect(service.Spec.Type).To(Equal(corev1.ServiceTypeClusterIP))
+					Expect(service.Spec.ClusterIP).To(Equal(corev1.ClusterIPNone))
+				})
+			})
+
+			Context("NodePort VirtualMachineService", func() {
+				BeforeEach(func() {
+					vmService.Spec.Type = vmopv1.VirtualMachineServiceTypeNodePort
+					vmServicePort1.NodePort = 30042
+					vmService.Spec.Ports = []vmopv1.VirtualMachineServicePort{
+						vmServicePort1,
+						vmServicePort2,
+					}
+				})
+
+				It("Service is NodePort with the specified NodePort", func() {
+					Expect(service.Spec.Type).To(Equal(corev1.ServiceTypeNodePort))
+					Expect(service.Spec.Ports).To(HaveLen(2))
+					Expect(service.Spec.Ports[0].NodePort).To(BeNumerically("==", 30042))
+					Expect(service.Spec.Ports[1].NodePort).To(BeZero())
 				})
 			})
 
@@ -374,7 +406,7 @@ func unitTestsReconcile() {
 					Expect(port.Name).To(Equal(vmServicePort1.Name))
 					Expect(port.Protocol).To(BeEquivalentTo(vmServicePort1.Protocol))
 					Expect(port.Port).To(Equal(vmServicePort1.Port))
-					Expect(port.TargetPort.IntValue()).To(Equal(int(vmServicePort1.TargetPort)))
+					Expect(port.TargetPort.IntValue()).To(Equal(vmServicePort1.TargetPort.IntValue()))
 					Expect(port.NodePort).To(BeNumerically("==", 10000))
 				})
 			})
@@ -536,6 +568,30 @@ func unitTestsReconcile() {
 					Expect(subset.NotReadyAddresses).To(BeEmpty())
 				})
 
+				When("Service is Headless", func() {
+					BeforeEach(func() {
+						vmService.Spec.Type = vmopv1.VirtualMachineServiceTypeHeadless
+						vmService.Spec.ClusterIP = corev1.ClusterIPNone
+						vm1.Spec.Network = &vmopv1.VirtualMachineNetworkSpec{
+							HostName: "my-host",
+						}
+						vm2.Spec.Network = &vmopv1.VirtualMachineNetworkSpec{
+							HostName: "Not_A_Label",
+						}
+					})
+
+					It("Expected subsets have the VM host names", func() {
+						Expect(endpoints.Subsets).To(HaveLen(1))
+						subset := endpoints.Subsets[0]
+
+						Expect(subset.Addresses).To(HaveLen(2))
+						Expect(subset.Addresses[0].IP).To(Equal(vm1.Status.Network.PrimaryIP4))
+						Expect(subset.Addresses[0].Hostname).To(Equal("my-host"))
+						Expect(subset.Addresses[1].IP).To(Equal(vm2.Status.Network.PrimaryIP4))
+						Expect(subset.Addresses[1].Hostname).To(BeEmpty())
+					})
+				})
+
 				When("Service has multiple ports", func() {
 					BeforeEach(func() {
 						vmService.Spec.Ports = append(vmService.Spec.Ports, vmServicePort2)
@@ -556,6 +612,50 @@ func unitTestsReconcile() {
 						Expect(subset.NotReadyAddresses).To(BeEmpty())
 					})
 				})
+
+				When("Service has a named target port", func() {
+					BeforeEach(func() {
+						vmService.Spec.Ports[0].TargetPort = intstr.FromString("http")
+						vm1.Spec.Ports = []vmopv1.VirtualMachinePort{
+							{Name: "http", Port: 8080, Protocol: "TCP"},
+						}
+						vm2.Spec.Ports = []vmopv1.VirtualMachinePort{
+							{Name: "http", Port: 9090},
+						}
+					})
+
+					It("Expected subsets have the port of each VM", func() {
+						Expect(endpoints.Subsets).To(HaveLen(2))
+
+						portsByIP := map[string]int32{}
+						for _, subset := range endpoints.Subsets {
+							Expect(subset.Ports).To(HaveLen(1))
+							Expect(subset.Addresses).To(HaveLen(1))
+							portsByIP[subset.Addresses[0].IP] = subset.Ports[0].Port
+						}
+						Expect(portsByIP).To(HaveKeyWithValue(vm1.Status.Network.PrimaryIP4, int32(8080)))
+						Expect(portsByIP).To(HaveKeyWithValue(vm2.Status.Network.PrimaryIP4, int32(9090)))
+					})
+
+					When("A VM does not have the named port", func() {
+						BeforeEach(func() {
+							vm2.Spec.Ports[0].Name = "https"
+						})
+
+						It("VM is skipped with an Event", func() {
+							Expect(endpoints.Subsets).To(HaveLen(1))
+							subset := endpoints.Subsets[0]
+							Expect(subset.Ports).To(HaveLen(1))
+							Expect(subset.Ports[0].Port).To(BeEquivalentTo(8080))
+							Expect(subset.Addresses).To(HaveLen(1))
+							assertEPAddrFromVM(subset.Addresses[0], vm1)
+
+							Expect(ctx.Events).Should(Receive(And(
+								ContainSubstring(virtualmachineservice.VMPortNotFoundReason),
+								ContainSubstring(vm2.Name))))
+						})
+					})
+				})
 			})
 
 			Context("When VMs have Readiness Probe", func() {
@@ -623,6 +723,25 @@ func unitTestsReconcile() {
 						assertEPAddrFromVM(subset.NotReadyAddresses[0], vm2)
 					})
 				})
+
+				Context("Unready VM with only an HTTPGet probe", func() {
+					BeforeEach(func() {
+						vm1.Spec.ReadinessProbe = &vmopv1.VirtualMachineReadinessProbeSpec{
+							HTTPGet: &vmopv1.HTTPGetAction{},
+						}
+						conditions.MarkFalse(vm1, vmopv1.ReadyConditionType, "reason", "")
+					})
+
+					It("With expected Subsets", func() {
+						Expect(endpoints.Subsets).To(HaveLen(1))
+						subset := endpoints.Subsets[0]
+
+						Expect(subset.Addresses).To(BeEmpty())
+						Expect(subset.NotReadyAddresses).To(HaveLen(2))
+						assertEPAddrFromVM(subset.NotReadyAddresses[0], vm1)
+						assertEPAddrFromVM(subset.NotReadyAddresses[1], vm2)
+					})
+				})
 			})
 
 			Context("Preserve VMs in Endpoints that have Probe but hasn't run yet", func() {
@@ -671,6 +790,239 @@ func unitTestsReconcile() {
 			})
 		})
 
+		Context("Creates expected EndpointSlices", func() {
+			var vm1, vm2 *vmopv1.VirtualMachine
+
+			getEndpointSlices := func() map[discoveryv1.AddressType]discoveryv1.EndpointSlice {
+				sliceList := &discoveryv1.EndpointSliceList{}
+				Expect(ctx.Client.List(ctx, sliceList, client.InNamespace(vmService.Namespace))).To(Succeed())
+
+				slices := map[discoveryv1.AddressType]discoveryv1.EndpointSlice{}
+				for _, slice := range sliceList.Items {
+					Expect(slices).ToNot(HaveKey(slice.AddressType))
+					slices[slice.AddressType] = slice
+				}
+				return slices
+			}
+
+			BeforeEach(func() {
+				labelSelector := map[string]string{"my-app": "dummy-label"}
+
+				vmService.Spec.Selector = labelSelector
+				vmService.Spec.Ports = []vmopv1.VirtualMachineServicePort{
+					vmServicePort1,
+				}
+				vmService.Spec.IPFamilies = []vmopv1.IPFamily{vmopv1.IPv4Protocol, vmopv1.IPv6Protocol}
+
+				vm1 = &vmopv1.VirtualMachine{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "dummy-vm1",
+						Namespace: vmService.Namespace,
+						Labels:    labelSelector,
+					},
+					Spec: vmopv1.VirtualMachineSpec{
+						PowerState: vmopv1.VirtualMachinePowerStateOn,
+					},
+					Status: vmopv1.VirtualMachineStatus{
+						PowerState: vmopv1.VirtualMachinePowerStateOn,
+						Network: &vmopv1.VirtualMachineNetworkStatus{
+							PrimaryIP4: "1.1.1.1",
+							PrimaryIP6: "fd00::1",
+							Interfaces: []vmopv1.VirtualMachineNetworkInterfaceStatus{
+								{
+									Name: "eth0",
+									IP: vmopv1.VirtualMachineNetworkInterfaceIPStatus{
+										Addresses: []vmopv1.VirtualMachineNetworkInterfaceIPAddrStatus{
+											{Address: "1.1.1.1/24"},
+											{Address: "fe80::1/64"},
+											{Address: "fd00::1/64"},
+										},
+									},
+								},
+								{
+									Name: "eth1",
+									IP: vmopv1.VirtualMachineNetworkInterfaceIPStatus{
+										Addresses: []vmopv1.VirtualMachineNetworkInterfaceIPAddrStatus{
+											{Address: "10.0.0.1/16"},
+											{Address: "fd00::2/64"},
+											{Address: "fd00::3/64", State: "tentative"},
+										},
+									},
+								},
+							},
+						},
+					},
+				}
+
+				vm2 = &vmopv1.VirtualMachine{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "dummy-vm2",
+						Namespace: vmService.Namespace,
+						Labels:    labelSelector,
+					},
+					Spec: vmopv1.VirtualMachineSpec{
+						PowerState: vmopv1.VirtualMachinePowerStateOn,
+					},
+					Status: vmopv1.VirtualMachineStatus{
+						PowerState: vmopv1.VirtualMachinePowerStateOn,
+						Network: &vmopv1.VirtualMachineNetworkStatus{
+							PrimaryIP4: "2.2.2.2",
+						},
+					},
+				}
+
+				initObjects = append(initObjects, vm1, vm2)
+			})
+
+			JustBeforeEach(func() {
+				err := reconciler.ReconcileNormal(vmServiceCtx)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("One EndpointSlice for each IP family", func() {
+				slices := getEndpointSlices()
+				Expect(slices).To(HaveLen(2))
+
+				ipv4Slice := slices[discoveryv1.AddressTypeIPv4]
+				Expect(ipv4Slice.Labels).To(HaveKeyWithValue(discoveryv1.LabelServiceName, vmService.Name))
+				Expect(ipv4Slice.Labels).To(HaveKeyWithValue(discoveryv1.LabelManagedBy, virtualmachineservice.EndpointSliceManagedBy))
+				Expect(ipv4Slice.OwnerReferences).To(HaveLen(1))
+				Expect(ipv4Slice.OwnerReferences[0].Name).To(Equal(vmService.Name))
+				Expect(ipv4Slice.Ports).To(HaveLen(1))
+				Expect(ipv4Slice.Ports[0].Name).To(Equal(pointer.String(vmServicePort1.Name)))
+				Expect(ipv4Slice.Ports[0].Port).To(Equal(pointer.Int32(vmServicePort1.TargetPort.IntVal)))
+				Expect(ipv4Slice.Endpoints).To(HaveLen(2))
+				Expect(ipv4Slice.Endpoints[0].Addresses).To(Equal([]string{"1.1.1.1", "10.0.0.1"}))
+				Expect(ipv4Slice.Endpoints[0].TargetRef.Name).To(Equal(vm1.Name))
+				Expect(ipv4Slice.Endpoints[1].Addresses).To(Equal([]string{"2.2.2.2"}))
+				Expect(ipv4Slice.Endpoints[1].TargetRef.Name).To(Equal(vm2.Name))
+
+				ipv6Slice := slices[discoveryv1.AddressTypeIPv6]
+				Expect(ipv6Slice.Endpoints).To(HaveLen(1))
+				Expect(ipv6Slice.Endpoints[0].Addresses).To(Equal([]string{"fd00::1", "fd00::2"}))
+				Expect(ipv6Slice.Endpoints[0].TargetRef.Name).To(Equal(vm1.Name))
+
+				for _, ep := range append(ipv4Slice.Endpoints, ipv6Slice.Endpoints...) {
+					Expect(ep.Conditions.Ready).To(Equal(pointer.Bool(true)))
+					Expect(ep.Conditions.Serving).To(Equal(pointer.Bool(true)))
+					Expect(ep.Conditions.Terminating).To(Equal(pointer.Bool(false)))
+				}
+			})
+
+			It("Endpoints are not mirrored", func() {
+				endpoints := &corev1.Endpoints{}
+				Expect(ctx.Client.Get(ctx, objKey, endpoints)).To(Succeed())
+				Expect(endpoints.Labels).To(HaveKeyWithValue(discoveryv1.LabelSkipMirror, "true"))
+			})
+
+			It("Deletes the EndpointSlice of an IP family without addresses", func() {
+				vm1.Status.Network.PrimaryIP6 = ""
+				vm1.Status.Network.Interfaces = nil
+				Expect(ctx.Client.Status().Update(ctx, vm1)).To(Succeed())
+
+				err := reconciler.ReconcileNormal(vmServiceCtx)
+				Expect(err).NotTo(HaveOccurred())
+
+				slices := getEndpointSlices()
+				Expect(slices).To(HaveLen(1))
+				Expect(slices).To(HaveKey(discoveryv1.AddressTypeIPv4))
+				Expect(slices[discoveryv1.AddressTypeIPv4].Endpoints).To(HaveLen(2))
+			})
+
+			It("Removes the secondary IP family when the Service is changed to single-stack", func() {
+				vmService.Spec.IPFamilies = []vmopv1.IPFamily{vmopv1.IPv4Protocol}
+				vmService.Spec.IPFamilyPolicy = (*vmopv1.IPFamilyPolicy)(pointer.String(string(vmopv1.IPFamilyPolicySingleStack)))
+
+				err := reconciler.ReconcileNormal(vmServiceCtx)
+				Expect(err).NotTo(HaveOccurred())
+
+				service := &corev1.Service{}
+				Expect(ctx.Client.Get(ctx, objKey, service)).To(Succeed())
+				Expect(service.Spec.IPFamilies).To(Equal([]corev1.IPFamily{corev1.IPv4Protocol}))
+				Expect(service.Spec.IPFamilyPolicy).To(Equal((*corev1.IPFamilyPolicy)(pointer.String(string(corev1.IPFamilyPolicySingleStack)))))
+
+				slices := getEndpointSlices()
+				Expect(slices).To(HaveLen(1))
+				Expect(slices).To(HaveKey(discoveryv1.AddressTypeIPv4))
+			})
+
+			When("Service is IPv6 single-stack", func() {
+				BeforeEach(func() {
+					vmService.Spec.IPFamilies = []vmopv1.IPFamily{vmopv1.IPv6Protocol}
+					vmService.Spec.IPFamilyPolicy = (*vmopv1.IPFamilyPolicy)(pointer.String(string(vmopv1.IPFamilyPolicySingleStack)))
+				})
+
+				It("Service and EndpointSlice are IPv6", func() {
+					service := &corev1.Service{}
+					Expect(ctx.Client.Get(ctx, objKey, service)).To(Succeed())
+					Expect(service.Spec.IPFamilies).To(Equal([]corev1.IPFamily{corev1.IPv6Protocol}))
+					Expect(service.Spec.IPFamilyPolicy).To(Equal((*corev1.IPFamilyPolicy)(pointer.String(string(corev1.IPFamilyPolicySingleStack)))))
+
+					slices := getEndpointSlices()
+					Expect(slices).To(HaveLen(1))
+					Expect(slices).To(HaveKey(discoveryv1.AddressTypeIPv6))
+				})
+
+				It("Adds the secondary IP family when the Service is changed to dual-stack", func() {
+					vmService.Spec.IPFamilies = []vmopv1.IPFamily{vmopv1.IPv6Protocol, vmopv1.IPv4Protocol}
+					vmService.Spec.IPFamilyPolicy = (*vmopv1.IPFamilyPolicy)(pointer.String(string(vmopv1.IPFamilyPolicyPreferDualStack)))
+
+					err := reconciler.ReconcileNormal(vmServiceCtx)
+					Expect(err).NotTo(HaveOccurred())
+
+					service := &corev1.Service{}
+					Expect(ctx.Client.Get(ctx, objKey, service)).To(Succeed())
+					Expect(service.Spec.IPFamilies).To(Equal([]corev1.IPFamily{corev1.IPv6Protocol, corev1.IPv4Protocol}))
+					Expect(service.Spec.IPFamilyPolicy).To(Equal((*corev1.IPFamilyPolicy)(pointer.String(string(corev1.IPFamilyPolicyPreferDualStack)))))
+
+					slices := getEndpointSlices()
+					Expect(slices).To(HaveLen(2))
+				})
+			})
+
+			When("VMs are powered off or being powered off", func() {
+				BeforeEach(func() {
+					vm1.Spec.PowerState = vmopv1.VirtualMachinePowerStateOff
+					vm2.Spec.PowerState = vmopv1.VirtualMachinePowerStateOff
+					vm2.Status.PowerState = vmopv1.VirtualMachinePowerStateOff
+				})
+
+				It("Expected endpoint conditions", func() {
+					ipv4Slice := getEndpointSlices()[discoveryv1.AddressTypeIPv4]
+					Expect(ipv4Slice.Endpoints).To(HaveLen(2))
+
+					epConditions := ipv4Slice.Endpoints[0].Conditions
+					Expect(epConditions.Ready).To(Equal(pointer.Bool(false)))
+					Expect(epConditions.Serving).To(Equal(pointer.Bool(true)))
+					Expect(epConditions.Terminating).To(Equal(pointer.Bool(true)))
+
+					epConditions = ipv4Slice.Endpoints[1].Conditions
+					Expect(epConditions.Ready).To(Equal(pointer.Bool(false)))
+					Expect(epConditions.Serving).To(Equal(pointer.Bool(false)))
+					Expect(epConditions.Terminating).To(Equal(pointer.Bool(false)))
+				})
+			})
+
+			When("VM has a Ready condition that is false", func() {
+				BeforeEach(func() {
+					vm2.Spec.ReadinessProbe = &vmopv1.VirtualMachineReadinessProbeSpec{
+						TCPSocket: &vmopv1.TCPSocketAction{},
+					}
+					conditions.MarkFalse(vm2, vmopv1.ReadyConditionType, "reason", "")
+				})
+
+				It("Expected endpoint conditions", func() {
+					ipv4Slice := getEndpointSlices()[discoveryv1.AddressTypeIPv4]
+					Expect(ipv4Slice.Endpoints).To(HaveLen(2))
+
+					epConditions := ipv4Slice.Endpoints[1].Conditions
+					Expect(epConditions.Ready).To(Equal(pointer.Bool(false)))
+					Expect(epConditions.Serving).To(Equal(pointer.Bool(false)))
+					Expect(epConditions.Terminating).To(Equal(pointer.Bool(false)))
+				})
+			})
+		})
+
 		Context("Selectorless VirtualMachineService", func() {
 			var vm1 *vmopv1.VirtualMachine
 			var labelSelector, vmLabels map[string]string
@@ -807,7 +1159,7 @@ func nsxtLBProviderTestsReconcile() {
 			Name:       "port1",
 			Protocol:   "TCP",
 			Port:       42,
-			TargetPort: 142,
+			TargetPort: intstr.FromInt(142),
 		}
 
 		vmService = &vmopv1.VirtualMachineService{
@@ -988,7 +1340,7 @@ func assertEPPortFromVMServicePort(
 
 	ExpectWithOffset(1, port.Name).To(Equal(vmServicePort.Name))
 	ExpectWithOffset(1, port.Protocol).To(BeEquivalentTo(vmServicePort.Protocol))
-	ExpectWithOffset(1, port.Port).To(Equal(vmServicePort.TargetPort))
+	ExpectWithOffset(1, port.Port).To(Equal(vmServicePort.TargetPort.IntVal))
 }
 
 func assertEPAddrFromVM(
@@ -0,0 +1,290 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha2
+
+import (
+	goctx "context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlbuilder "sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	conditions "github.com/vmware-tanzu/vm-operator/pkg/conditions2"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	patch "github.com/vmware-tanzu/vm-operator/pkg/patch2"
+	"github.com/vmware-tanzu/vm-operator/pkg/record"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
+)
+
+const (
+	finalizerName = "virtualmachinesnapshot.vmoperator.vmware.com"
+)
+
+// AddToManager adds this package's controller to the provided manager.
+func AddToManager(ctx *context.ControllerManagerContext, mgr manager.Manager) error {
+	var (
+		controlledType     = &vmopv1.VirtualMachineSnapshot{}
+		controlledTypeName = reflect.TypeOf(controlledType).Elem().Name()
+
+		controllerNameShort = fmt.Sprintf("%s-controller", strings.ToLower(controlledTypeName))
+		controllerNameLong  = fmt.Sprintf("%s/%s/%s", ctx.Namespace, ctx.Name, controllerNameShort)
+	)
+
+	r := NewReconciler(
+		mgr.GetClient(),
+		ctrl.Log.WithName("controllers").WithName(controlledTypeName),
+		record.New(mgr.GetEventRecorderFor(controllerNameLong)),
+		ctx.VMProviderA2,
+	)
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(controlledType).
+		WithOptions(controller.Options{MaxConcurrentReconciles: ctx.MaxConcurrentReconciles}).
+		// Taking, reverting to, or removing a snapshot changes the VM's snapshot
+		// tree, so the other snapshots of the same VM are reconciled to refresh
+		// their status.
+		Watches(&vmopv1.VirtualMachineSnapshot{},
+			handler.EnqueueRequestsFromMapFunc(snapshotToSiblingSnapshotsMapperFn(ctx, r.Client)),
+			ctrlbuilder.WithPredicates(predicate.Funcs{
+				CreateFunc:  func(event.CreateEvent) bool { return false },
+				GenericFunc: func(event.GenericEvent) bool { return false },
+				UpdateFunc: func(e event.UpdateEvent) bool {
+					oldObj, newObj := e.ObjectOld.(*vmopv1.VirtualMachineSnapshot), e.ObjectNew.(*vmopv1.VirtualMachineSnapshot)
+					return oldObj.Status.UniqueID != newObj.Status.UniqueID
+				},
+			})).
+		Watches(&vmopv1.VirtualMachine{},
+			handler.EnqueueRequestsFromMapFunc(vmToSnapshotsMapperFn(ctx, r.Client)),
+			ctrlbuilder.WithPredicates(predicate.Funcs{
+				CreateFunc:  func(event.CreateEvent) bool { return false },
+				GenericFunc: func(event.GenericEvent) bool { return false },
+				UpdateFunc: func(e event.UpdateEvent) bool {
+					oldObj, newObj := e.ObjectOld.(*vmopv1.VirtualMachine), e.ObjectNew.(*vmopv1.VirtualMachine)
+					return oldObj.Status.UniqueID != newObj.Status.UniqueID ||
+						!reflect.DeepEqual(oldObj.Status.CurrentSnapshot, newObj.Status.CurrentSnapshot)
+				},
+			})).
+		Complete(r)
+}
+
+// snapshotToSiblingSnapshotsMapperFn returns a mapper function that can be used to queue reconcile
+// requests for the other VirtualMachineSnapshots of the same VM in response to an event on a
+// VirtualMachineSnapshot resource.
+func snapshotToSiblingSnapshotsMapperFn(ctx *context.ControllerManagerContext, c client.Client) func(_ goctx.Context, o client.Object) []reconcile.Request {
+	return func(_ goctx.Context, o client.Object) []reconcile.Request {
+		vmSnapshot := o.(*vmopv1.VirtualMachineSnapshot)
+		return snapshotsForVM(ctx, c, vmSnapshot.Namespace, vmSnapshot.Spec.VMName, vmSnapshot.Name)
+	}
+}
+
+// vmToSnapshotsMapperFn returns a mapper function that can be used to queue reconcile requests
+// for the VirtualMachineSnapshots of a VM in response to an event on the VirtualMachine resource.
+func vmToSnapshotsMapperFn(ctx *context.ControllerManagerContext, c client.Client) func(_ goctx.Context, o client.Object) []reconcile.Request {
+	return func(_ goctx.Context, o client.Object) []reconcile.Request {
+		vm := o.(*vmopv1.VirtualMachine)
+		return snapshotsForVM(ctx, c, vm.Namespace, vm.Name, "")
+	}
+}
+
+func snapshotsForVM(
+	ctx *context.ControllerManagerContext,
+	c client.Client,
+	namespace, vmName, skipName string) []reconcile.Request {
+
+	logger := ctx.Logger.WithValues("vmName", vmName, "namespace", namespace)
+
+	vmSnapshotList := &vmopv1.VirtualMachineSnapshotList{}
+	if err := c.List(ctx, vmSnapshotList, client.InNamespace(namespace)); err != nil {
+		logger.Error(err, "Failed to list VirtualMachineSnapshots for reconciliation due to watch")
+		return nil
+	}
+
+	var reconcileRequests []reconcile.Request
+	for _, vmSnapshot := range vmSnapshotList.Items {
+		if vmSnapshot.Spec.VMName == vmName && vmSnapshot.Name != skipName {
+			key := client.ObjectKey{Namespace: vmSnapshot.Namespace, Name: vmSnapshot.Name}
+			reconcileRequests = append(reconcileRequests, reconcile.Request{NamespacedName: key})
+		}
+	}
+
+	logger.V(4).Info("Returning VirtualMachineSnapshot reconcile requests due to watch", "requests", reconcileRequests)
+	return reconcileRequests
+}
+
+func NewReconciler(
+	client client.Client,
+	logger logr.Logger,
+	recorder record.Recorder,
+	vmProvider vmprovider.VirtualMachineProviderInterfaceA2) *Reconciler {
+
+	return &Reconciler{
+		Client:     client,
+		Logger:     logger,
+		Recorder:   recorder,
+		VMProvider: vmProvider,
+	}
+}
+
+// Reconciler reconciles a VirtualMachineSnapshot object.
+type Reconciler struct {
+	client.Client
+	Logger     logr.Logger
+	Recorder   record.Recorder
+	VMProvider vmprovider.VirtualMachineProviderInterfaceA2
+}
+
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachinesnapshots,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachinesnapshots/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachines,verbs=get;list;watch
+
+func (r *Reconciler) Reconcile(ctx goctx.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
+	vmSnapshot := &vmopv1.VirtualMachineSnapshot{}
+	if err := r.Get(ctx, req.NamespacedName, vmSnapshot); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	vmSnapshotCtx := &context.VirtualMachineSnapshotContextA2{
+		Context:    ctx,
+		Logger:     ctrl.Log.WithName("VirtualMachineSnapshot").WithValues("name", vmSnapshot.NamespacedName()),
+		VMSnapshot: vmSnapshot,
+	}
+
+	patchHelper, err := patch.NewHelper(vmSnapshot, r.Client)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to init patch helper for %s", vmSnapshotCtx.String())
+	}
+	defer func() {
+		if err := patchHelper.Patch(ctx, vmSnapshot); err != nil {
+			if reterr == nil {
+				reterr = err
+			}
+			vmSnapshotCtx.Logger.Error(err, "patch failed")
+		}
+	}()
+
+	if !vmSnapshot.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, r.ReconcileDelete(vmSnapshotCtx)
+	}
+
+	return ctrl.Result{}, r.ReconcileNormal(vmSnapshotCtx)
+}
+
+// getVM fetches the VM the snapshot is of. The returned VM is nil if it does
+// not exist.
+func (r *Reconciler) getVM(ctx *context.VirtualMachineSnapshotContextA2) (*vmopv1.VirtualMachine, error) {
+	vm := &vmopv1.VirtualMachine{}
+	key := client.ObjectKey{Namespace: ctx.VMSnapshot.Namespace, Name: ctx.VMSnapshot.Spec.VMName}
+	if err := r.Get(ctx, key, vm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return vm, nil
+}
+
+func (r *Reconciler) ReconcileNormal(ctx *context.VirtualMachineSnapshotContextA2) error {
+	if !controllerutil.ContainsFinalizer(ctx.VMSnapshot, finalizerName) {
+		// The finalizer must be present before proceeding in order to ensure that the snapshot
+		// will be removed from the VM. Return immediately after here to let the patcher helper
+		// update the object, and then we'll proceed on the next reconciliation.
+		controllerutil.AddFinalizer(ctx.VMSnapshot, finalizerName)
+		return nil
+	}
+
+	ctx.Logger.Info("Reconciling VirtualMachineSnapshot")
+	defer func() {
+		ctx.Logger.Info("Finished Reconciling VirtualMachineSnapshot")
+	}()
+
+	vm, err := r.getVM(ctx)
+	if err != nil {
+		return err
+	}
+
+	if vm == nil {
+		conditions.MarkFalse(ctx.VMSnapshot, vmopv1.VirtualMachineSnapshotConditionCreated,
+			vmopv1.VirtualMachineSnapshotVMNotFoundReason, "VirtualMachine %q does not exist", ctx.VMSnapshot.Spec.VMName)
+		ctx.VMSnapshot.Status.Ready = false
+		return errors.Errorf("VirtualMachine %q does not exist", ctx.VMSnapshot.Spec.VMName)
+	}
+	ctx.VM = vm
+
+	if vm.Status.UniqueID == "" {
+		conditions.MarkFalse(ctx.VMSnapshot, vmopv1.VirtualMachineSnapshotConditionCreated,
+			vmopv1.VirtualMachineSnapshotVMNotCreatedReason, "VirtualMachine %q has not been created", vm.Name)
+		ctx.VMSnapshot.Status.Ready = false
+		return errors.Errorf("VirtualMachine %q has not been created", vm.Name)
+	}
+
+	// The snapshot is removed by the infrastructure along with the VM, so have
+	// the snapshot resource be garbage collected with the VM resource too.
+	if err := controllerutil.SetOwnerReference(vm, ctx.VMSnapshot, r.Scheme()); err != nil {
+		return err
+	}
+
+	if err := r.VMProvider.CreateOrUpdateVirtualMachineSnapshot(ctx, vm, ctx.VMSnapshot); err != nil {
+		conditions.MarkFalse(ctx.VMSnapshot, vmopv1.VirtualMachineSnapshotConditionCreated,
+			vmopv1.VirtualMachineSnapshotCreateFailedReason, err.Error())
+		ctx.VMSnapshot.Status.Ready = false
+		r.Recorder.EmitEvent(ctx.VMSnapshot, "CreateOrUpdate", err, false)
+		return err
+	}
+
+	if !conditions.IsTrue(ctx.VMSnapshot, vmopv1.VirtualMachineSnapshotConditionCreated) {
+		r.Recorder.EmitEvent(ctx.VMSnapshot, "Create", nil, false)
+	}
+	conditions.MarkTrue(ctx.VMSnapshot, vmopv1.VirtualMachineSnapshotConditionCreated)
+	ctx.VMSnapshot.Status.Ready = true
+
+	return nil
+}
+
+func (r *Reconciler) ReconcileDelete(ctx *context.VirtualMachineSnapshotContextA2) (reterr error) {
+	if !controllerutil.ContainsFinalizer(ctx.VMSnapshot, finalizerName) {
+		return nil
+	}
+
+	ctx.Logger.Info("Reconciling VirtualMachineSnapshot Deletion")
+	defer func() {
+		ctx.Logger.Info("Finished Reconciling VirtualMachineSnapshot Deletion")
+	}()
+
+	vm, err := r.getVM(ctx)
+	if err != nil {
+		return err
+	}
+
+	// There is nothing to remove if the VM or the snapshot was never created.
+	if vm != nil && vm.Status.UniqueID != "" && ctx.VMSnapshot.Status.UniqueID != "" {
+		ctx.VM = vm
+
+		defer func() {
+			r.Recorder.EmitEvent(ctx.VMSnapshot, "Delete", reterr, false)
+		}()
+
+		if err := r.VMProvider.DeleteVirtualMachineSnapshot(ctx, vm, ctx.VMSnapshot); err != nil {
+			ctx.Logger.Error(err, "Failed to delete VirtualMachineSnapshot")
+			return err
+		}
+	}
+
+	controllerutil.RemoveFinalizer(ctx.VMSnapshot, finalizerName)
+	return nil
+}
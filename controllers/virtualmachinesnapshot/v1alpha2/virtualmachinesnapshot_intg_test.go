@@ -0,0 +1,125 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha2_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func intgTests() {
+	Describe("Invoking VirtualMachineSnapshot controller tests", intgTestsReconcile)
+}
+
+func intgTestsReconcile() {
+	var (
+		ctx        *builder.IntegrationTestContext
+		vm         *vmopv1.VirtualMachine
+		vmSnapshot *vmopv1.VirtualMachineSnapshot
+	)
+
+	getVMSnapshot := func(ctx *builder.IntegrationTestContext, objKey client.ObjectKey) *vmopv1.VirtualMachineSnapshot {
+		vmSnapshot := &vmopv1.VirtualMachineSnapshot{}
+		if err := ctx.Client.Get(ctx, objKey, vmSnapshot); err != nil {
+			return nil
+		}
+		return vmSnapshot
+	}
+
+	BeforeEach(func() {
+		ctx = suite.NewIntegrationTestContext()
+
+		vm = &vmopv1.VirtualMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "dummy-vm",
+				Namespace: ctx.Namespace,
+			},
+			Spec: vmopv1.VirtualMachineSpec{
+				ImageName:  "dummy-image",
+				ClassName:  "dummy-class",
+				PowerState: vmopv1.VirtualMachinePowerStateOn,
+			},
+		}
+
+		vmSnapshot = &vmopv1.VirtualMachineSnapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "dummy-snapshot",
+				Namespace: ctx.Namespace,
+			},
+			Spec: vmopv1.VirtualMachineSnapshotSpec{
+				VMName: vm.Name,
+			},
+		}
+	})
+
+	AfterEach(func() {
+		ctx.AfterEach()
+		ctx = nil
+		fakeVMProvider.Reset()
+	})
+
+	Context("Reconcile", func() {
+		var deleteCalled bool
+
+		BeforeEach(func() {
+			deleteCalled = false
+			fakeVMProvider.Lock()
+			fakeVMProvider.DeleteVirtualMachineSnapshotFn = func(
+				_ context.Context, _ *vmopv1.VirtualMachine, _ *vmopv1.VirtualMachineSnapshot) error {
+				deleteCalled = true
+				return nil
+			}
+			fakeVMProvider.Unlock()
+
+			Expect(ctx.Client.Create(ctx, vm)).To(Succeed())
+			vm.Status.UniqueID = "vm-42"
+			Expect(ctx.Client.Status().Update(ctx, vm)).To(Succeed())
+			Expect(ctx.Client.Create(ctx, vmSnapshot)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			err := ctx.Client.Delete(ctx, vmSnapshot)
+			Expect(err == nil || k8serrors.IsNotFound(err)).To(BeTrue())
+			err = ctx.Client.Delete(ctx, vm)
+			Expect(err == nil || k8serrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("reconciles the snapshot", func() {
+			objKey := client.ObjectKeyFromObject(vmSnapshot)
+
+			By("snapshot should be created", func() {
+				Eventually(func() bool {
+					if obj := getVMSnapshot(ctx, objKey); obj != nil {
+						return obj.Status.Ready
+					}
+					return false
+				}).Should(BeTrue())
+
+				obj := getVMSnapshot(ctx, objKey)
+				Expect(obj.Status.UniqueID).ToNot(BeEmpty())
+				Expect(obj.GetFinalizers()).To(ContainElement(finalizerName))
+			})
+
+			By("snapshot should be removed when deleted", func() {
+				Expect(ctx.Client.Delete(ctx, vmSnapshot)).To(Succeed())
+				Eventually(func() bool {
+					return getVMSnapshot(ctx, objKey) == nil
+				}).Should(BeTrue())
+
+				fakeVMProvider.Lock()
+				defer fakeVMProvider.Unlock()
+				Expect(deleteCalled).To(BeTrue())
+			})
+		})
+	})
+}
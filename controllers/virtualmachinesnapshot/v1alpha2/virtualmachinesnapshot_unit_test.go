@@ -0,0 +1,204 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha2_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	virtualmachinesnapshot "github.com/vmware-tanzu/vm-operator/controllers/virtualmachinesnapshot/v1alpha2"
+	conditions "github.com/vmware-tanzu/vm-operator/pkg/conditions2"
+	vmopContext "github.com/vmware-tanzu/vm-operator/pkg/context"
+	providerfake "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/fake"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+const finalizerName = "virtualmachinesnapshot.vmoperator.vmware.com"
+
+func unitTests() {
+	Describe("Invoking VirtualMachineSnapshot Reconcile", unitTestsReconcile)
+}
+
+func unitTestsReconcile() {
+	var (
+		initObjects []client.Object
+		ctx         *builder.UnitTestContextForController
+
+		reconciler    *virtualmachinesnapshot.Reconciler
+		vmSnapshotCtx *vmopContext.VirtualMachineSnapshotContextA2
+		vmSnapshot    *vmopv1.VirtualMachineSnapshot
+		vm            *vmopv1.VirtualMachine
+	)
+
+	BeforeEach(func() {
+		vm = &vmopv1.VirtualMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "dummy-vm",
+				Namespace: "dummy-ns",
+			},
+			Status: vmopv1.VirtualMachineStatus{
+				UniqueID: "vm-42",
+			},
+		}
+
+		vmSnapshot = &vmopv1.VirtualMachineSnapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "dummy-snapshot",
+				Namespace:  vm.Namespace,
+				Finalizers: []string{finalizerName},
+			},
+			Spec: vmopv1.VirtualMachineSnapshotSpec{
+				VMName: vm.Name,
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		ctx = suite.NewUnitTestContextForController(initObjects...)
+		reconciler = virtualmachinesnapshot.NewReconciler(
+			ctx.Client,
+			ctx.Logger,
+			ctx.Recorder,
+			ctx.VMProviderA2,
+		)
+		fakeVMProvider = ctx.VMProviderA2.(*providerfake.VMProviderA2)
+
+		vmSnapshotCtx = &vmopContext.VirtualMachineSnapshotContextA2{
+			Context:    ctx,
+			Logger:     ctx.Logger.WithName(vmSnapshot.Name),
+			VMSnapshot: vmSnapshot,
+		}
+	})
+
+	AfterEach(func() {
+		ctx.AfterEach()
+		ctx = nil
+		initObjects = nil
+		reconciler = nil
+		fakeVMProvider.Reset()
+	})
+
+	Context("ReconcileNormal", func() {
+		When("the finalizer is not present", func() {
+			BeforeEach(func() {
+				vmSnapshot.Finalizers = nil
+				initObjects = append(initObjects, vm, vmSnapshot)
+			})
+
+			It("adds the finalizer", func() {
+				Expect(reconciler.ReconcileNormal(vmSnapshotCtx)).To(Succeed())
+				Expect(vmSnapshot.GetFinalizers()).To(ContainElement(finalizerName))
+			})
+		})
+
+		When("the VM does not exist", func() {
+			BeforeEach(func() {
+				initObjects = append(initObjects, vmSnapshot)
+			})
+
+			It("returns an error and marks the condition false", func() {
+				err := reconciler.ReconcileNormal(vmSnapshotCtx)
+				Expect(err).To(HaveOccurred())
+				Expect(vmSnapshot.Status.Ready).To(BeFalse())
+
+				c := conditions.Get(vmSnapshot, vmopv1.VirtualMachineSnapshotConditionCreated)
+				Expect(c).ToNot(BeNil())
+				Expect(c.Status).To(Equal(metav1.ConditionFalse))
+				Expect(c.Reason).To(Equal(vmopv1.VirtualMachineSnapshotVMNotFoundReason))
+			})
+		})
+
+		When("the VM has not been created", func() {
+			BeforeEach(func() {
+				vm.Status.UniqueID = ""
+				initObjects = append(initObjects, vm, vmSnapshot)
+			})
+
+			It("returns an error and marks the condition false", func() {
+				err := reconciler.ReconcileNormal(vmSnapshotCtx)
+				Expect(err).To(HaveOccurred())
+
+				c := conditions.Get(vmSnapshot, vmopv1.VirtualMachineSnapshotConditionCreated)
+				Expect(c).ToNot(BeNil())
+				Expect(c.Reason).To(Equal(vmopv1.VirtualMachineSnapshotVMNotCreatedReason))
+			})
+		})
+
+		When("the VM exists", func() {
+			BeforeEach(func() {
+				initObjects = append(initObjects, vm, vmSnapshot)
+			})
+
+			It("creates the snapshot", func() {
+				Expect(reconciler.ReconcileNormal(vmSnapshotCtx)).To(Succeed())
+				Expect(vmSnapshot.Status.Ready).To(BeTrue())
+				Expect(vmSnapshot.Status.UniqueID).ToNot(BeEmpty())
+				Expect(conditions.IsTrue(vmSnapshot, vmopv1.VirtualMachineSnapshotConditionCreated)).To(BeTrue())
+				Expect(vmSnapshot.OwnerReferences).To(HaveLen(1))
+				Expect(vmSnapshot.OwnerReferences[0].Name).To(Equal(vm.Name))
+				Expect(ctx.Events).To(Receive(ContainSubstring("CreateSuccess")))
+			})
+
+			When("the provider returns an error", func() {
+				JustBeforeEach(func() {
+					fakeVMProvider.CreateOrUpdateVirtualMachineSnapshotFn = func(
+						_ context.Context, _ *vmopv1.VirtualMachine, _ *vmopv1.VirtualMachineSnapshot) error {
+						return errors.New("fake")
+					}
+				})
+
+				It("returns an error and marks the condition false", func() {
+					err := reconciler.ReconcileNormal(vmSnapshotCtx)
+					Expect(err).To(HaveOccurred())
+					Expect(vmSnapshot.Status.Ready).To(BeFalse())
+
+					c := conditions.Get(vmSnapshot, vmopv1.VirtualMachineSnapshotConditionCreated)
+					Expect(c).ToNot(BeNil())
+					Expect(c.Reason).To(Equal(vmopv1.VirtualMachineSnapshotCreateFailedReason))
+					Expect(ctx.Events).To(Receive(ContainSubstring("CreateOrUpdateFailure")))
+				})
+			})
+		})
+	})
+
+	Context("ReconcileDelete", func() {
+		BeforeEach(func() {
+			vmSnapshot.Status.UniqueID = "snapshot-1"
+			initObjects = append(initObjects, vm, vmSnapshot)
+		})
+
+		It("deletes the snapshot and removes the finalizer", func() {
+			called := false
+			fakeVMProvider.DeleteVirtualMachineSnapshotFn = func(
+				_ context.Context, _ *vmopv1.VirtualMachine, _ *vmopv1.VirtualMachineSnapshot) error {
+				called = true
+				return nil
+			}
+
+			Expect(reconciler.ReconcileDelete(vmSnapshotCtx)).To(Succeed())
+			Expect(called).To(BeTrue())
+			Expect(controllerutil.ContainsFinalizer(vmSnapshot, finalizerName)).To(BeFalse())
+		})
+
+		When("the provider returns an error", func() {
+			It("returns an error and keeps the finalizer", func() {
+				fakeVMProvider.DeleteVirtualMachineSnapshotFn = func(
+					_ context.Context, _ *vmopv1.VirtualMachine, _ *vmopv1.VirtualMachineSnapshot) error {
+					return errors.New("fake")
+				}
+
+				Expect(reconciler.ReconcileDelete(vmSnapshotCtx)).ToNot(Succeed())
+				Expect(controllerutil.ContainsFinalizer(vmSnapshot, finalizerName)).To(BeTrue())
+			})
+		})
+	})
+}
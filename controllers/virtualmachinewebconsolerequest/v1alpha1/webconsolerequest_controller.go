@@ -13,7 +13,6 @@ import (
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
-	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -26,6 +25,7 @@ import (
 	"github.com/vmware-tanzu/vm-operator/pkg/lib"
 	"github.com/vmware-tanzu/vm-operator/pkg/patch"
 	"github.com/vmware-tanzu/vm-operator/pkg/record"
+	"github.com/vmware-tanzu/vm-operator/pkg/util/kube"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
 )
 
@@ -33,8 +33,8 @@ const (
 	DefaultExpiryTime = time.Second * 120
 	UUIDLabelKey      = "vmoperator.vmware.com/webconsolerequest-uuid"
 
-	ProxyAddrServiceName      = "kube-apiserver-lb-svc"
-	ProxyAddrServiceNamespace = "kube-system"
+	ProxyAddrServiceName      = kube.ProxyAddrServiceName
+	ProxyAddrServiceNamespace = kube.ProxyAddrServiceNamespace
 )
 
 // AddToManager adds this package's controller to the provided manager.
@@ -193,17 +193,12 @@ func (r *Reconciler) ReconcileNormal(ctx *context.WebConsoleRequestContext) erro
 	ctx.WebConsoleRequest.Status.ExpiryTime = metav1.NewTime(metav1.Now().Add(DefaultExpiryTime))
 
 	// Retrieve the proxy address from the load balancer service ingress IP.
-	proxySvc := &corev1.Service{}
-	proxySvcObjectKey := client.ObjectKey{Name: ProxyAddrServiceName, Namespace: ProxyAddrServiceNamespace}
-	err = r.Get(ctx, proxySvcObjectKey, proxySvc)
+	proxyAddr, err := kube.GetProxyAddr(ctx, r.Client)
 	if err != nil {
-		return errors.Wrapf(err, "failed to get proxy address service  %s", proxySvcObjectKey)
-	}
-	if len(proxySvc.Status.LoadBalancer.Ingress) == 0 {
-		return errors.Errorf("no ingress found for proxy address service %s", proxySvcObjectKey)
+		return err
 	}
 
-	ctx.WebConsoleRequest.Status.ProxyAddr = proxySvc.Status.LoadBalancer.Ingress[0].IP
+	ctx.WebConsoleRequest.Status.ProxyAddr = proxyAddr
 
 	// Add UUID as a Label to the current WebConsoleRequest resource after acquiring the ticket.
 	// This will be used when validating the connection request from users to the web console URL.
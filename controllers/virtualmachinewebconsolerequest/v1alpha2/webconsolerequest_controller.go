@@ -13,7 +13,6 @@ import (
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
-	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -24,6 +23,7 @@ import (
 	"github.com/vmware-tanzu/vm-operator/pkg/context"
 	"github.com/vmware-tanzu/vm-operator/pkg/patch"
 	"github.com/vmware-tanzu/vm-operator/pkg/record"
+	"github.com/vmware-tanzu/vm-operator/pkg/util/kube"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
 )
 
@@ -31,8 +31,12 @@ const (
 	DefaultExpiryTime = time.Second * 120
 	UUIDLabelKey      = "vmoperator.vmware.com/webconsolerequest-uuid"
 
-	ProxyAddrServiceName      = "kube-apiserver-lb-svc"
-	ProxyAddrServiceNamespace = "kube-system"
+	// UsedAnnotationKey is set by the web console validation server to the
+	// time the web console was opened. A request can only be used once.
+	UsedAnnotationKey = "vmoperator.vmware.com/webconsolerequest-used"
+
+	ProxyAddrServiceName      = kube.ProxyAddrServiceName
+	ProxyAddrServiceNamespace = kube.ProxyAddrServiceNamespace
 )
 
 // AddToManager adds this package's controller to the provided manager.
@@ -174,17 +178,12 @@ func (r *Reconciler) ReconcileNormal(ctx *context.WebConsoleRequestContextA2) er
 	ctx.WebConsoleRequest.Status.ExpiryTime = metav1.NewTime(metav1.Now().Add(DefaultExpiryTime))
 
 	// Retrieve the proxy address from the load balancer service ingress IP.
-	proxySvc := &corev1.Service{}
-	proxySvcObjectKey := client.ObjectKey{Name: ProxyAddrServiceName, Namespace: ProxyAddrServiceNamespace}
-	err = r.Get(ctx, proxySvcObjectKey, proxySvc)
+	proxyAddr, err := kube.GetProxyAddr(ctx, r.Client)
 	if err != nil {
-		return errors.Wrapf(err, "failed to get proxy address service  %s", proxySvcObjectKey)
-	}
-	if len(proxySvc.Status.LoadBalancer.Ingress) == 0 {
-		return errors.Errorf("no ingress found for proxy address service %s", proxySvcObjectKey)
+		return err
 	}
 
-	ctx.WebConsoleRequest.Status.ProxyAddr = proxySvc.Status.LoadBalancer.Ingress[0].IP
+	ctx.WebConsoleRequest.Status.ProxyAddr = proxyAddr
 
 	// Add UUID as a Label to the current WebConsoleRequest resource after acquiring the ticket.
 	// This will be used when validating the connection request from users to the web console URL.
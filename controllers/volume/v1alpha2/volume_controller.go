@@ -82,13 +82,11 @@ func AddToManager(ctx *context.ControllerManagerContext, mgr manager.Manager) er
 		return err
 	}
 
-	// Watch for changes for PersistentVolumeClaim, and enqueue VirtualMachine which is the owner of PersistentVolumeClaim.
+	// Watch for changes for PersistentVolumeClaim, and enqueue the VirtualMachines that reference the
+	// PersistentVolumeClaim in their volumes. This includes the instance storage PVCs owned by the VM,
+	// and PVCs being expanded so the VM's volume status reflects the resize.
 	err = c.Watch(source.Kind(mgr.GetCache(), &corev1.PersistentVolumeClaim{}),
-		handler.EnqueueRequestForOwner(
-			mgr.GetScheme(),
-			mgr.GetRESTMapper(),
-			&vmopv1.VirtualMachine{},
-			handler.OnlyControllerOwner()))
+		handler.EnqueueRequestsFromMapFunc(pvcToVMMapperFn(ctx, r.Client)))
 	if err != nil {
 		return err
 	}
@@ -96,6 +94,34 @@ func AddToManager(ctx *context.ControllerManagerContext, mgr manager.Manager) er
 	return nil
 }
 
+func pvcToVMMapperFn(ctx *context.ControllerManagerContext, c client.Client) func(_ goctx.Context, o client.Object) []reconcile.Request {
+	// For a given PersistentVolumeClaim, return reconcile requests
+	// for those VirtualMachines with volumes that reference the claim.
+	return func(_ goctx.Context, o client.Object) []reconcile.Request {
+		pvc := o.(*corev1.PersistentVolumeClaim)
+		logger := ctx.Logger.WithValues("name", pvc.Name, "namespace", pvc.Namespace)
+
+		vmList := &vmopv1.VirtualMachineList{}
+		if err := c.List(ctx, vmList, client.InNamespace(pvc.Namespace)); err != nil {
+			logger.Error(err, "Failed to list VirtualMachines for reconciliation due to PersistentVolumeClaim watch")
+			return nil
+		}
+
+		var reconcileRequests []reconcile.Request
+		for _, vm := range vmList.Items {
+			for _, volume := range vm.Spec.Volumes {
+				if volume.PersistentVolumeClaim != nil && volume.PersistentVolumeClaim.ClaimName == pvc.Name {
+					key := client.ObjectKey{Namespace: vm.Namespace, Name: vm.Name}
+					reconcileRequests = append(reconcileRequests, reconcile.Request{NamespacedName: key})
+					break
+				}
+			}
+		}
+
+		return reconcileRequests
+	}
+}
+
 func NewReconciler(
 	client client.Client,
 	logger logr.Logger,
@@ -543,7 +569,11 @@ func (r *Reconciler) processAttachments(
 			// but the old code didn't and let's match that behavior until we need to do otherwise.
 			// Also, the CNS attachment controller doesn't reconcile Spec changes once the volume
 			// is attached.
-			volumeStatus = append(volumeStatus, attachmentToVolumeStatus(volume.Name, attachment))
+			status := attachmentToVolumeStatus(volume.Name, attachment)
+			if err := r.updateVolumeCapacityStatus(ctx, volume, &status); err != nil {
+				createErrs = append(createErrs, err)
+			}
+			volumeStatus = append(volumeStatus, status)
 			hasPendingAttachment = hasPendingAttachment || !attachment.Status.Attached
 			continue
 		}
@@ -606,6 +636,76 @@ func (r *Reconciler) processAttachments(
 	return k8serrors.NewAggregate(createErrs)
 }
 
+// updateVolumeCapacityStatus updates the volume status with the capacity
+// requested by the volume's PVC, and the capacity of the attached disk as
+// reported by CSI. An event is emitted when the online expansion of the
+// attached disk finishes.
+func (r *Reconciler) updateVolumeCapacityStatus(
+	ctx *context.VolumeContextA2,
+	volume vmopv1.VirtualMachineVolume,
+	status *vmopv1.VirtualMachineVolumeStatus) error {
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	objKey := client.ObjectKey{Namespace: ctx.VM.Namespace, Name: volume.PersistentVolumeClaim.ClaimName}
+	if err := r.Get(ctx, objKey, pvc); err != nil {
+		if apiErrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "Cannot get PersistentVolumeClaim %s", objKey.Name)
+	}
+
+	if requested, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; ok {
+		status.RequestedCapacity = &requested
+	}
+
+	if !status.Attached {
+		return nil
+	}
+
+	if capacity, ok := pvc.Status.Capacity[corev1.ResourceStorage]; ok {
+		status.AttachedCapacity = &capacity
+	}
+	status.ResizeState = pvcResizeState(pvc, status.RequestedCapacity, status.AttachedCapacity)
+
+	if status.ResizeState == "" {
+		for _, oldStatus := range ctx.VM.Status.Volumes {
+			if oldStatus.Name == status.Name && oldStatus.ResizeState != "" && status.AttachedCapacity != nil {
+				r.recorder.Eventf(ctx.VM, "VolumeResized", "Volume %s expanded to %s",
+					status.Name, status.AttachedCapacity.String())
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// pvcResizeState returns the resize state of the PVC from its CSI resize
+// conditions, or if the PVC's capacity is still less than requested.
+func pvcResizeState(
+	pvc *corev1.PersistentVolumeClaim,
+	requested, capacity *resource.Quantity) vmopv1.VirtualMachineVolumeResizeState {
+
+	for _, c := range pvc.Status.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+
+		switch c.Type {
+		case corev1.PersistentVolumeClaimResizing:
+			return vmopv1.VirtualMachineVolumeResizeInProgress
+		case corev1.PersistentVolumeClaimFileSystemResizePending:
+			return vmopv1.VirtualMachineVolumeResizeFileSystemPending
+		}
+	}
+
+	if requested != nil && capacity != nil && capacity.Cmp(*requested) < 0 {
+		return vmopv1.VirtualMachineVolumeResizeInProgress
+	}
+
+	return ""
+}
+
 func (r *Reconciler) createCNSAttachment(
 	ctx *context.VolumeContextA2,
 	attachmentName string,
@@ -570,6 +570,105 @@ func unitTestsReconcile() {
 			})
 		})
 
+		When("VM Spec.Volumes has CNS volume with a PVC that is being expanded", func() {
+			var pvc *corev1.PersistentVolumeClaim
+
+			BeforeEach(func() {
+				vmVol = *vmVolumeWithPVC1
+				vm.Spec.Volumes = append(vm.Spec.Volumes, vmVol)
+
+				attachment := cnsAttachmentForVMVolume(vm, vmVol)
+				attachment.Status.Attached = true
+				attachment.Status.AttachmentMetadata = map[string]string{
+					volume.AttributeFirstClassDiskUUID: dummyDiskUUID,
+				}
+
+				pvc = &corev1.PersistentVolumeClaim{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      vmVol.PersistentVolumeClaim.ClaimName,
+						Namespace: vm.Namespace,
+					},
+					Spec: corev1.PersistentVolumeClaimSpec{
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceStorage: resource.MustParse("20Gi"),
+							},
+						},
+					},
+					Status: corev1.PersistentVolumeClaimStatus{
+						Phase: corev1.ClaimBound,
+						Capacity: corev1.ResourceList{
+							corev1.ResourceStorage: resource.MustParse("10Gi"),
+						},
+						Conditions: []corev1.PersistentVolumeClaimCondition{
+							{
+								Type:   corev1.PersistentVolumeClaimResizing,
+								Status: corev1.ConditionTrue,
+							},
+						},
+					},
+				}
+
+				initObjects = append(initObjects, attachment, pvc)
+			})
+
+			It("returns success with the requested and attached capacity", func() {
+				err := reconciler.ReconcileNormal(volCtx)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(vm.Status.Volumes).To(HaveLen(1))
+				volStatus := vm.Status.Volumes[0]
+				Expect(volStatus.RequestedCapacity).ToNot(BeNil())
+				Expect(volStatus.RequestedCapacity.String()).To(Equal("20Gi"))
+				Expect(volStatus.AttachedCapacity).ToNot(BeNil())
+				Expect(volStatus.AttachedCapacity.String()).To(Equal("10Gi"))
+				Expect(volStatus.ResizeState).To(Equal(vmopv1.VirtualMachineVolumeResizeInProgress))
+			})
+
+			When("the file system resize is pending", func() {
+				BeforeEach(func() {
+					pvc.Status.Conditions[0].Type = corev1.PersistentVolumeClaimFileSystemResizePending
+				})
+
+				It("returns success with the file system resize pending", func() {
+					err := reconciler.ReconcileNormal(volCtx)
+					Expect(err).ToNot(HaveOccurred())
+
+					Expect(vm.Status.Volumes).To(HaveLen(1))
+					Expect(vm.Status.Volumes[0].ResizeState).To(Equal(vmopv1.VirtualMachineVolumeResizeFileSystemPending))
+				})
+			})
+
+			When("the online expansion has finished", func() {
+				BeforeEach(func() {
+					pvc.Status.Capacity[corev1.ResourceStorage] = resource.MustParse("20Gi")
+					pvc.Status.Conditions = nil
+
+					vm.Status.Volumes = []vmopv1.VirtualMachineVolumeStatus{
+						{
+							Name:        vmVol.Name,
+							Attached:    true,
+							DiskUUID:    dummyDiskUUID,
+							ResizeState: vmopv1.VirtualMachineVolumeResizeInProgress,
+						},
+					}
+				})
+
+				It("returns success and emits an event", func() {
+					err := reconciler.ReconcileNormal(volCtx)
+					Expect(err).ToNot(HaveOccurred())
+
+					Expect(vm.Status.Volumes).To(HaveLen(1))
+					volStatus := vm.Status.Volumes[0]
+					Expect(volStatus.AttachedCapacity).ToNot(BeNil())
+					Expect(volStatus.AttachedCapacity.String()).To(Equal("20Gi"))
+					Expect(volStatus.ResizeState).To(BeEmpty())
+
+					Expect(ctx.Events).To(Receive(ContainSubstring("VolumeResized")))
+				})
+			})
+		})
+
 		When("VM Spec.Volumes has CNS volume with an existing CnsNodeVmAttachment for a different VM", func() {
 
 			When("CnsNodeVmAttachment has OwnerRef of different VM", func() {
@@ -64,6 +64,10 @@ type ControllerManagerContext struct {
 	// WebhookSecretName is the name of the webhook secret.
 	WebhookSecretName string
 
+	// WebhookSecretVolumeMountPath is the filesystem path to which the webhook
+	// secret, and so the serving certificate, is mounted.
+	WebhookSecretVolumeMountPath string
+
 	// ContainerNode should be true if we're running guest cluster nodes in containers.
 	ContainerNode bool
 
@@ -0,0 +1,25 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package context
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+)
+
+// VirtualMachineExportRequestContextA2 is the context used for VirtualMachineExportRequestControllers.
+type VirtualMachineExportRequestContextA2 struct {
+	context.Context
+	Logger        logr.Logger
+	ExportRequest *vmopv1.VirtualMachineExportRequest
+	VM            *vmopv1.VirtualMachine
+}
+
+func (v *VirtualMachineExportRequestContextA2) String() string {
+	return fmt.Sprintf("%s %s/%s", v.ExportRequest.GroupVersionKind(), v.ExportRequest.Namespace, v.ExportRequest.Name)
+}
@@ -0,0 +1,25 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package context
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+)
+
+// VirtualMachineGuestCommandRequestContextA2 is the context used for VirtualMachineGuestCommandRequestControllers.
+type VirtualMachineGuestCommandRequestContextA2 struct {
+	context.Context
+	Logger         logr.Logger
+	CommandRequest *vmopv1.VirtualMachineGuestCommandRequest
+	VM             *vmopv1.VirtualMachine
+}
+
+func (v *VirtualMachineGuestCommandRequestContextA2) String() string {
+	return fmt.Sprintf("%s %s/%s", v.CommandRequest.GroupVersionKind(), v.CommandRequest.Namespace, v.CommandRequest.Name)
+}
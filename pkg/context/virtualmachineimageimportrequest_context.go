@@ -0,0 +1,27 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package context
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+
+	imgregv1a1 "github.com/vmware-tanzu/image-registry-operator-api/api/v1alpha1"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+)
+
+// VirtualMachineImageImportRequestContextA2 is the context used for VirtualMachineImageImportRequestControllers.
+type VirtualMachineImageImportRequestContextA2 struct {
+	context.Context
+	Logger         logr.Logger
+	ImportRequest  *vmopv1.VirtualMachineImageImportRequest
+	ContentLibrary *imgregv1a1.ContentLibrary
+}
+
+func (v *VirtualMachineImageImportRequestContextA2) String() string {
+	return fmt.Sprintf("%s %s/%s", v.ImportRequest.GroupVersionKind(), v.ImportRequest.Namespace, v.ImportRequest.Name)
+}
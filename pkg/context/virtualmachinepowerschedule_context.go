@@ -0,0 +1,24 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package context
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+)
+
+// VirtualMachinePowerScheduleContextA2 is the context used for VirtualMachinePowerScheduleControllers.
+type VirtualMachinePowerScheduleContextA2 struct {
+	context.Context
+	Logger        logr.Logger
+	PowerSchedule *vmopv1.VirtualMachinePowerSchedule
+}
+
+func (v *VirtualMachinePowerScheduleContextA2) String() string {
+	return fmt.Sprintf("%s %s/%s", v.PowerSchedule.GroupVersionKind(), v.PowerSchedule.Namespace, v.PowerSchedule.Name)
+}
@@ -0,0 +1,24 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package context
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+)
+
+// VirtualMachineRestoreRequestContextA2 is the context used for VirtualMachineRestoreRequestControllers.
+type VirtualMachineRestoreRequestContextA2 struct {
+	context.Context
+	Logger         logr.Logger
+	RestoreRequest *vmopv1.VirtualMachineRestoreRequest
+}
+
+func (v *VirtualMachineRestoreRequestContextA2) String() string {
+	return fmt.Sprintf("%s %s/%s", v.RestoreRequest.GroupVersionKind(), v.RestoreRequest.Namespace, v.RestoreRequest.Name)
+}
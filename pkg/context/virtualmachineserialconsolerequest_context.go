@@ -0,0 +1,25 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package context
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+)
+
+// VirtualMachineSerialConsoleRequestContextA2 is the context used for VirtualMachineSerialConsoleRequestControllers.
+type VirtualMachineSerialConsoleRequestContextA2 struct {
+	context.Context
+	Logger               logr.Logger
+	SerialConsoleRequest *vmopv1.VirtualMachineSerialConsoleRequest
+	VM                   *vmopv1.VirtualMachine
+}
+
+func (v *VirtualMachineSerialConsoleRequestContextA2) String() string {
+	return fmt.Sprintf("%s %s/%s", v.SerialConsoleRequest.GroupVersionKind(), v.SerialConsoleRequest.Namespace, v.SerialConsoleRequest.Name)
+}
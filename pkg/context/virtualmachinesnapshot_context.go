@@ -0,0 +1,25 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package context
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+)
+
+// VirtualMachineSnapshotContextA2 is the context used for VirtualMachineSnapshotControllers.
+type VirtualMachineSnapshotContextA2 struct {
+	context.Context
+	Logger     logr.Logger
+	VMSnapshot *vmopv1.VirtualMachineSnapshot
+	VM         *vmopv1.VirtualMachine
+}
+
+func (v *VirtualMachineSnapshotContextA2) String() string {
+	return fmt.Sprintf("%s %s/%s", v.VMSnapshot.GroupVersionKind(), v.VMSnapshot.Namespace, v.VMSnapshot.Name)
+}
@@ -0,0 +1,176 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package exporttransfer
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// certificateValidity is how long the certificate of a transfer server
+	// is valid for, which must be longer than an export may take.
+	certificateValidity = 30 * 24 * time.Hour
+
+	dialTimeout           = 30 * time.Second
+	responseHeaderTimeout = 5 * time.Minute
+)
+
+// NewSecretData returns the data of the Secret of a transfer Pod: a new
+// token, and a new self-signed certificate and its key that the transfer
+// server is served with. The certificate is pinned by the Client, so it is
+// not issued for the address of the Pod.
+func NewSecretData() (map[string][]byte, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return nil, err
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: "vm-operator-export-transfer"},
+		NotBefore:    now.Add(-time.Minute),
+		NotAfter:     now.Add(certificateValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string][]byte{
+		SecretTokenKey:          []byte(hex.EncodeToString(token)),
+		corev1.TLSCertKey:       pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}),
+		corev1.TLSPrivateKeyKey: pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}, nil
+}
+
+// Client uploads files to a transfer server.
+type Client struct {
+	// Host is the IP address of the transfer Pod.
+	Host string
+
+	// Port is the port of the transfer server. Defaults to DefaultPort.
+	Port int
+
+	// Token is the token the requests are authenticated with.
+	Token string
+
+	// CertPEM is the certificate of the transfer server. Only a server that
+	// presents this certificate is uploaded to.
+	CertPEM []byte
+
+	httpClient *http.Client
+}
+
+// NewClient returns a client for the transfer server of a Pod with the given
+// IP address and the data of its Secret.
+func NewClient(host string, secretData map[string][]byte) (*Client, error) {
+	c := &Client{
+		Host:    host,
+		Port:    DefaultPort,
+		Token:   string(secretData[SecretTokenKey]),
+		CertPEM: secretData[corev1.TLSCertKey],
+	}
+	if c.Token == "" {
+		return nil, errors.New("transfer secret has no token")
+	}
+
+	block, _ := pem.Decode(c.CertPEM)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("transfer secret has no certificate")
+	}
+
+	c.httpClient = &http.Client{
+		Transport: &http.Transport{
+			DialContext:           (&net.Dialer{Timeout: dialTimeout}).DialContext,
+			TLSHandshakeTimeout:   dialTimeout,
+			ResponseHeaderTimeout: responseHeaderTimeout,
+			TLSClientConfig: &tls.Config{
+				MinVersion: tls.VersionTLS12,
+				// The certificate is not issued for the address of the Pod,
+				// so it is pinned instead of verified.
+				InsecureSkipVerify: true, //nolint:gosec
+				VerifyConnection: func(cs tls.ConnectionState) error {
+					if len(cs.PeerCertificates) == 0 || !bytes.Equal(cs.PeerCertificates[0].Raw, block.Bytes) {
+						return errors.New("transfer server presented an unknown certificate")
+					}
+					return nil
+				},
+			},
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	return c, nil
+}
+
+// WriteFile uploads the file with the given name and size in bytes. The size
+// is -1 when it is not known in advance.
+func (c *Client) WriteFile(ctx context.Context, name string, size int64, r io.Reader) error {
+	port := c.Port
+	if port == 0 {
+		port = DefaultPort
+	}
+	u := url.URL{
+		Scheme: "https",
+		Host:   net.JoinHostPort(c.Host, strconv.Itoa(port)),
+		Path:   FilesPath + name,
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u.String(), r)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("failed to write %s to the transfer pod with status %d: %s", name, resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	return nil
+}
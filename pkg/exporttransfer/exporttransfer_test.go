@@ -0,0 +1,152 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package exporttransfer_test
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/exporttransfer"
+)
+
+func unitTests() {
+	var (
+		dir        string
+		secretData map[string][]byte
+		httpServer *httptest.Server
+		client     *exporttransfer.Client
+	)
+
+	newTLSServer := func(data map[string][]byte) *httptest.Server {
+		cert, err := tls.X509KeyPair(data[corev1.TLSCertKey], data[corev1.TLSPrivateKeyKey])
+		Expect(err).ToNot(HaveOccurred())
+
+		server := httptest.NewUnstartedServer(&exporttransfer.Server{
+			Dir:    filepath.Join(dir, "my-export"),
+			Token:  string(data[exporttransfer.SecretTokenKey]),
+			Logger: logr.Discard(),
+		})
+		server.TLS = &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+		server.StartTLS()
+		return server
+	}
+
+	newClient := func(data map[string][]byte) *exporttransfer.Client {
+		host, port, err := net.SplitHostPort(httpServer.Listener.Addr().String())
+		Expect(err).ToNot(HaveOccurred())
+
+		c, err := exporttransfer.NewClient(host, data)
+		Expect(err).ToNot(HaveOccurred())
+		c.Port, err = strconv.Atoi(port)
+		Expect(err).ToNot(HaveOccurred())
+		return c
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "export-transfer-")
+		Expect(err).ToNot(HaveOccurred())
+
+		secretData, err = exporttransfer.NewSecretData()
+		Expect(err).ToNot(HaveOccurred())
+
+		httpServer = newTLSServer(secretData)
+		client = newClient(secretData)
+	})
+
+	AfterEach(func() {
+		httpServer.Close()
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("writes the file to the directory", func() {
+		Expect(client.WriteFile(context.Background(), "my-vm.ovf", 5, strings.NewReader("hello"))).To(Succeed())
+
+		content, err := os.ReadFile(filepath.Join(dir, "my-export", "my-vm.ovf"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(content)).To(Equal("hello"))
+	})
+
+	It("writes a file of unknown size", func() {
+		Expect(client.WriteFile(context.Background(), "my-vm-disk-0.vmdk", -1, strings.NewReader("0123456789"))).To(Succeed())
+
+		content, err := os.ReadFile(filepath.Join(dir, "my-export", "my-vm-disk-0.vmdk"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(content)).To(Equal("0123456789"))
+	})
+
+	It("rejects a name that is not a file in the directory", func() {
+		for _, name := range []string{"..", "../my-vm.ovf", "sub/my-vm.ovf", `..\my-vm.ovf`} {
+			err := client.WriteFile(context.Background(), name, 5, strings.NewReader("hello"))
+			Expect(err).To(HaveOccurred(), name)
+		}
+
+		entries, err := os.ReadDir(dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).To(BeEmpty())
+	})
+
+	It("rejects a request with another token", func() {
+		client.Token = "other-token"
+
+		err := client.WriteFile(context.Background(), "my-vm.ovf", 5, strings.NewReader("hello"))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("401"))
+		Expect(filepath.Join(dir, "my-export")).ToNot(BeADirectory())
+	})
+
+	It("rejects a request that is not a PUT", func() {
+		req, err := http.NewRequest(http.MethodGet, httpServer.URL+exporttransfer.FilesPath+"my-vm.ovf", nil)
+		Expect(err).ToNot(HaveOccurred())
+		req.Header.Set("Authorization", "Bearer "+client.Token)
+
+		httpClient := &http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, //nolint:gosec
+		}}
+		resp, err := httpClient.Do(req)
+		Expect(err).ToNot(HaveOccurred())
+		_ = resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusMethodNotAllowed))
+	})
+
+	It("does not upload to a server with another certificate", func() {
+		otherData, err := exporttransfer.NewSecretData()
+		Expect(err).ToNot(HaveOccurred())
+		otherData[exporttransfer.SecretTokenKey] = secretData[exporttransfer.SecretTokenKey]
+
+		httpServer.Close()
+		httpServer = newTLSServer(otherData)
+		client = newClient(secretData)
+
+		err = client.WriteFile(context.Background(), "my-vm.ovf", 5, strings.NewReader("hello"))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("unknown certificate"))
+		Expect(filepath.Join(dir, "my-export")).ToNot(BeADirectory())
+	})
+
+	It("requires a token and a certificate in the secret", func() {
+		_, err := exporttransfer.NewClient("127.0.0.1", map[string][]byte{
+			corev1.TLSCertKey: secretData[corev1.TLSCertKey],
+		})
+		Expect(err).To(HaveOccurred())
+
+		_, err = exporttransfer.NewClient("127.0.0.1", map[string][]byte{
+			exporttransfer.SecretTokenKey: secretData[exporttransfer.SecretTokenKey],
+		})
+		Expect(err).To(HaveOccurred())
+	})
+}
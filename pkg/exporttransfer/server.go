@@ -0,0 +1,149 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package exporttransfer
+
+import (
+	"crypto/subtle"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+const (
+	// DefaultPort is the port the transfer server listens on.
+	DefaultPort = 9443
+
+	// FilesPath is the path prefix of the requests that write a file. The
+	// name of the file follows the prefix.
+	FilesPath = "/files/"
+
+	// SecretTokenKey is the key in the Secret of a transfer Pod that
+	// contains the token the requests to the transfer server are
+	// authenticated with.
+	SecretTokenKey = "token"
+
+	// TokenEnv is the name of the environment variable the transfer server
+	// reads the token from.
+	TokenEnv = "EXPORT_TRANSFER_TOKEN"
+
+	readHeaderTimeout = 30 * time.Second
+)
+
+// Server writes the files uploaded by VM Operator to a directory, which is
+// on the PersistentVolumeClaim mounted in the transfer Pod.
+//
+// Only PUT requests for a file directly in the directory are served, and
+// every request must carry the token as a bearer token.
+type Server struct {
+	// Dir is the directory the files are written to. It is created when the
+	// first file is written.
+	Dir string
+
+	// Token is the token the requests must be authenticated with.
+	Token string
+
+	// Logger logs the files that are written.
+	Logger logr.Logger
+}
+
+// ServeHTTP writes the file in the request to the directory.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if r.Method != http.MethodPut {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name, ok := fileName(r.URL.Path)
+	if !ok {
+		http.Error(w, "invalid file name", http.StatusBadRequest)
+		return
+	}
+
+	n, err := s.writeFile(name, r.Body, r.ContentLength)
+	if err != nil {
+		s.Logger.Error(err, "Failed to write file", "name", name)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.Logger.Info("Wrote file", "name", name, "size", n)
+	w.WriteHeader(http.StatusCreated)
+}
+
+// Run serves the requests on the address over TLS with the certificate and
+// key in the given files.
+func (s *Server) Run(addr, certFile, keyFile string) error {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           s,
+		ReadHeaderTimeout: readHeaderTimeout,
+		TLSConfig: &tls.Config{
+			MinVersion: tls.VersionTLS12,
+		},
+	}
+	return server.ListenAndServeTLS(certFile, keyFile)
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && s.Token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.Token)) == 1
+}
+
+// fileName returns the name of the file in the path of a request. The name
+// must not contain a path separator so a file is never written outside of
+// the directory.
+func fileName(urlPath string) (string, bool) {
+	name, ok := strings.CutPrefix(urlPath, FilesPath)
+	if !ok || name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return "", false
+	}
+	return name, true
+}
+
+// writeFile writes the file to a temporary file that is renamed once it is
+// complete, so a file with the name is only ever complete.
+func (s *Server) writeFile(name string, r io.Reader, size int64) (_ int64, reterr error) {
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return 0, err
+	}
+
+	f, err := os.CreateTemp(s.Dir, "."+name+".*")
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if reterr != nil {
+			_ = f.Close()
+			_ = os.Remove(f.Name())
+		}
+	}()
+
+	n, err := io.Copy(f, r)
+	if err != nil {
+		return n, err
+	}
+	if size >= 0 && n != size {
+		return n, fmt.Errorf("wrote %d bytes of %d", n, size)
+	}
+	if err := f.Sync(); err != nil {
+		return n, err
+	}
+	if err := f.Close(); err != nil {
+		return n, err
+	}
+
+	return n, os.Rename(f.Name(), filepath.Join(s.Dir, name))
+}
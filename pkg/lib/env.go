@@ -5,6 +5,7 @@ package lib
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
@@ -72,6 +73,30 @@ const (
 	// If the environment variable is not set or empty it will be treated as
 	// if it contains vmoperator.vmware.com/vsphere.
 	DefaultVirtualMachineClassControllerNameEnv = "DEFAULT_VM_CLASS_CONTROLLER_NAME"
+
+	// VMExportTransferImageEnv is the name of the environment variable that
+	// contains the container image of the Pod that writes the files of an
+	// exported VM to a PersistentVolumeClaim. The image must provide the
+	// /export-transfer binary, which is part of the VM Operator image.
+	VMExportTransferImageEnv = "VM_EXPORT_TRANSFER_IMAGE"
+
+	// VMExportTransferClusterRoleEnv is the name of the environment variable
+	// that contains the name of the ClusterRole that VM Operator binds to
+	// itself in the namespace of an export request while it writes the files
+	// of the exported VM to a PersistentVolumeClaim.
+	VMExportTransferClusterRoleEnv = "VM_EXPORT_TRANSFER_CLUSTER_ROLE"
+
+	// VMExportObjectStoreEndpointsEnv is the name of the environment variable
+	// that contains the comma-separated URLs, ex. https://s3.example.com, of
+	// the object stores that VMs may be exported to. Only the scheme and the
+	// host of an endpoint are compared.
+	VMExportObjectStoreEndpointsEnv = "VM_EXPORT_OBJECT_STORE_ENDPOINTS"
+
+	// SerialConsoleProxyURIEnv is the name of the environment variable that
+	// contains the URI, for example telnet://10.0.0.10:8023, that the serial
+	// ports added by a VirtualMachineSerialConsoleRequest connect to the
+	// serial console proxy run by VM Operator with.
+	SerialConsoleProxyURIEnv = "SERIAL_CONSOLE_PROXY_URI"
 )
 
 // SetVMOpNamespaceEnv sets the VM Operator pod's namespace in the environment.
@@ -213,3 +238,53 @@ var GetDefaultVirtualMachineClassControllerName = func() string {
 	}
 	return v
 }
+
+// GetVMExportTransferImage returns the container image of the Pod that
+// writes the files of an exported VM to a PersistentVolumeClaim. An empty
+// string is returned if the image is not configured.
+var GetVMExportTransferImage = func() string {
+	return os.Getenv(VMExportTransferImageEnv)
+}
+
+// GetVMExportTransferClusterRole returns the name of the ClusterRole that
+// VM Operator binds to itself to write the files of an exported VM to a
+// PersistentVolumeClaim. An empty string is returned if it is not configured.
+var GetVMExportTransferClusterRole = func() string {
+	return os.Getenv(VMExportTransferClusterRoleEnv)
+}
+
+// GetVMExportObjectStoreEndpoints returns the URLs of the object stores that
+// VMs may be exported to. No object store is allowed if the environment
+// variable is not set.
+var GetVMExportObjectStoreEndpoints = func() []string {
+	var endpoints []string
+	for _, part := range strings.Split(os.Getenv(VMExportObjectStoreEndpointsEnv), ",") {
+		if part := strings.TrimSpace(part); part != "" {
+			endpoints = append(endpoints, part)
+		}
+	}
+	return endpoints
+}
+
+// IsVMExportObjectStoreEndpointAllowed returns true if the scheme and the host
+// of the endpoint match one of the object stores that VMs may be exported to.
+func IsVMExportObjectStoreEndpointAllowed(endpoint string) bool {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	for _, allowed := range GetVMExportObjectStoreEndpoints() {
+		a, err := url.Parse(allowed)
+		if err == nil && strings.EqualFold(a.Scheme, u.Scheme) && strings.EqualFold(a.Host, u.Host) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetSerialConsoleProxyURI returns the URI that serial ports connect to the
+// serial console proxy with. An empty string is returned if the proxy is not
+// configured.
+var GetSerialConsoleProxyURI = func() string {
+	return os.Getenv(SerialConsoleProxyURIEnv)
+}
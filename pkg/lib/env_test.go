@@ -42,3 +42,25 @@ var _ = Describe("MaxAllowedConcurrentVMsOnProvider", func() {
 		})
 	})
 })
+
+var _ = Describe("IsVMExportObjectStoreEndpointAllowed", func() {
+	AfterEach(func() {
+		Expect(os.Unsetenv(VMExportObjectStoreEndpointsEnv)).To(Succeed())
+	})
+
+	It("allows no endpoint when the env is not set", func() {
+		Expect(IsVMExportObjectStoreEndpointAllowed("https://s3.example.com")).To(BeFalse())
+	})
+
+	It("allows only the endpoints with the scheme and host of an allowed endpoint", func() {
+		Expect(os.Setenv(VMExportObjectStoreEndpointsEnv, "https://s3.example.com, http://minio.local:9000")).To(Succeed())
+
+		Expect(IsVMExportObjectStoreEndpointAllowed("https://s3.example.com")).To(BeTrue())
+		Expect(IsVMExportObjectStoreEndpointAllowed("https://S3.example.com/path")).To(BeTrue())
+		Expect(IsVMExportObjectStoreEndpointAllowed("http://minio.local:9000")).To(BeTrue())
+		Expect(IsVMExportObjectStoreEndpointAllowed("http://s3.example.com")).To(BeFalse())
+		Expect(IsVMExportObjectStoreEndpointAllowed("http://minio.local")).To(BeFalse())
+		Expect(IsVMExportObjectStoreEndpointAllowed("https://169.254.169.254")).To(BeFalse())
+		Expect(IsVMExportObjectStoreEndpointAllowed("s3.example.com")).To(BeFalse())
+	})
+})
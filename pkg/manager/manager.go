@@ -84,17 +84,18 @@ func New(opts Options) (Manager, error) {
 
 	// Build the controller manager context.
 	controllerManagerContext := &context.ControllerManagerContext{
-		Context:                 goctx.Background(),
-		Namespace:               opts.PodNamespace,
-		Name:                    opts.PodName,
-		ServiceAccountName:      opts.PodServiceAccountName,
-		LeaderElectionID:        opts.LeaderElectionID,
-		LeaderElectionNamespace: opts.PodNamespace,
-		MaxConcurrentReconciles: opts.MaxConcurrentReconciles,
-		Logger:                  opts.Logger.WithName(opts.PodName),
-		Recorder:                record.New(mgr.GetEventRecorderFor(fmt.Sprintf("%s/%s", opts.PodNamespace, opts.PodName))),
-		ContainerNode:           opts.ContainerNode,
-		SyncPeriod:              opts.SyncPeriod,
+		Context:                      goctx.Background(),
+		Namespace:                    opts.PodNamespace,
+		Name:                         opts.PodName,
+		ServiceAccountName:           opts.PodServiceAccountName,
+		LeaderElectionID:             opts.LeaderElectionID,
+		LeaderElectionNamespace:      opts.PodNamespace,
+		MaxConcurrentReconciles:      opts.MaxConcurrentReconciles,
+		WebhookSecretVolumeMountPath: opts.WebhookSecretVolumeMountPath,
+		Logger:                       opts.Logger.WithName(opts.PodName),
+		Recorder:                     record.New(mgr.GetEventRecorderFor(fmt.Sprintf("%s/%s", opts.PodNamespace, opts.PodName))),
+		ContainerNode:                opts.ContainerNode,
+		SyncPeriod:                   opts.SyncPeriod,
 	}
 
 	if err := opts.InitializeProviders(controllerManagerContext, mgr); err != nil {
@@ -14,6 +14,7 @@ const (
 	conditionReasonLabel = "condition_reason"
 	specLabel            = "spec"
 	statusLabel          = "status"
+	pathLabel            = "path"
 
 	// VMImage related metrics labels (from image registry service).
 	vmiNameLabel      = "vmi_name"
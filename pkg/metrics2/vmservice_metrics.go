@@ -24,6 +24,13 @@ type VMMetrics struct {
 	statusConditionStatus *prometheus.GaugeVec
 	powerState            *prometheus.GaugeVec
 	statusIP              *prometheus.GaugeVec
+
+	guestFilesystemCapacity  *prometheus.GaugeVec
+	guestFilesystemFreeSpace *prometheus.GaugeVec
+	consumedMemory           *prometheus.GaugeVec
+	cpuUsage                 *prometheus.GaugeVec
+	committedStorage         *prometheus.GaugeVec
+	uncommittedStorage       *prometheus.GaugeVec
 }
 
 func NewVMMetrics() *VMMetrics {
@@ -51,12 +58,61 @@ func NewVMMetrics() *VMMetrics {
 					Help:      "IP address assignment status of a VM resource"},
 				[]string{vmNameLabel, vmNamespaceLabel},
 			),
+
+			guestFilesystemCapacity: prometheus.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: metricsNamespace,
+					Name:      "vm_guest_filesystem_capacity_bytes",
+					Help:      "Capacity of a filesystem in the guest of a VM resource"},
+				[]string{vmNameLabel, vmNamespaceLabel, pathLabel},
+			),
+			guestFilesystemFreeSpace: prometheus.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: metricsNamespace,
+					Name:      "vm_guest_filesystem_free_bytes",
+					Help:      "Free space of a filesystem in the guest of a VM resource"},
+				[]string{vmNameLabel, vmNamespaceLabel, pathLabel},
+			),
+			consumedMemory: prometheus.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: metricsNamespace,
+					Name:      "vm_consumed_memory_bytes",
+					Help:      "Host memory consumed by a VM resource"},
+				[]string{vmNameLabel, vmNamespaceLabel},
+			),
+			cpuUsage: prometheus.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: metricsNamespace,
+					Name:      "vm_cpu_usage_mhz",
+					Help:      "CPU usage in MHz of a VM resource"},
+				[]string{vmNameLabel, vmNamespaceLabel},
+			),
+			committedStorage: prometheus.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: metricsNamespace,
+					Name:      "vm_committed_storage_bytes",
+					Help:      "Datastore storage space used by a VM resource"},
+				[]string{vmNameLabel, vmNamespaceLabel},
+			),
+			uncommittedStorage: prometheus.NewGaugeVec(
+				prometheus.GaugeOpts{
+					Namespace: metricsNamespace,
+					Name:      "vm_uncommitted_storage_bytes",
+					Help:      "Additional datastore storage space that may be used by a VM resource"},
+				[]string{vmNameLabel, vmNamespaceLabel},
+			),
 		}
 
 		metrics.Registry.MustRegister(
 			vmMetrics.statusConditionStatus,
 			vmMetrics.powerState,
 			vmMetrics.statusIP,
+			vmMetrics.guestFilesystemCapacity,
+			vmMetrics.guestFilesystemFreeSpace,
+			vmMetrics.consumedMemory,
+			vmMetrics.cpuUsage,
+			vmMetrics.committedStorage,
+			vmMetrics.uncommittedStorage,
 		)
 	})
 
@@ -67,6 +123,7 @@ func (vmm *VMMetrics) RegisterVMCreateOrUpdateMetrics(vmCtx *context.VirtualMach
 	vmm.registerVMStatusConditions(vmCtx)
 	vmm.registerVMPowerState(vmCtx)
 	vmm.registerVMStatusIP(vmCtx)
+	vmm.registerVMGuestStatus(vmCtx)
 }
 
 // DeleteMetrics deletes metrics for a specific VM post deletion reconcile.
@@ -88,6 +145,9 @@ func (vmm *VMMetrics) DeleteMetrics(vmCtx *context.VirtualMachineContextA2) {
 
 	// Delete the 'vm.status.ip' metrics.
 	vmm.statusIP.DeletePartialMatch(labels)
+
+	// Delete the 'vm.status.guest' metrics.
+	vmm.deleteVMGuestStatus(labels)
 }
 
 func (vmm *VMMetrics) registerVMStatusConditions(vmCtx *context.VirtualMachineContextA2) {
@@ -163,3 +223,55 @@ func (vmm *VMMetrics) registerVMStatusIP(vmCtx *context.VirtualMachineContextA2)
 		return 1
 	}())
 }
+
+func (vmm *VMMetrics) registerVMGuestStatus(vmCtx *context.VirtualMachineContextA2) {
+	vm := vmCtx.VM
+	vmCtx.Logger.V(5).Info("Adding metrics for VM guest status")
+
+	// Delete the existing guest metrics to address any filesystem that has
+	// been unmounted, or any value that is no longer reported.
+	labels := prometheus.Labels{
+		vmNameLabel:      vm.Name,
+		vmNamespaceLabel: vm.Namespace,
+	}
+	vmm.deleteVMGuestStatus(labels)
+
+	guest := vm.Status.Guest
+	if guest == nil {
+		return
+	}
+
+	for _, fs := range guest.Filesystems {
+		fsLabels := prometheus.Labels{
+			vmNameLabel:      vm.Name,
+			vmNamespaceLabel: vm.Namespace,
+			pathLabel:        fs.Path,
+		}
+		if fs.Capacity != nil {
+			vmm.guestFilesystemCapacity.With(fsLabels).Set(float64(fs.Capacity.Value()))
+		}
+		if fs.FreeSpace != nil {
+			vmm.guestFilesystemFreeSpace.With(fsLabels).Set(float64(fs.FreeSpace.Value()))
+		}
+	}
+
+	if guest.ConsumedMemory != nil {
+		vmm.consumedMemory.With(labels).Set(float64(guest.ConsumedMemory.Value()))
+	}
+	vmm.cpuUsage.With(labels).Set(float64(guest.CPUUsageMHz))
+	if guest.CommittedStorage != nil {
+		vmm.committedStorage.With(labels).Set(float64(guest.CommittedStorage.Value()))
+	}
+	if guest.UncommittedStorage != nil {
+		vmm.uncommittedStorage.With(labels).Set(float64(guest.UncommittedStorage.Value()))
+	}
+}
+
+func (vmm *VMMetrics) deleteVMGuestStatus(labels prometheus.Labels) {
+	vmm.guestFilesystemCapacity.DeletePartialMatch(labels)
+	vmm.guestFilesystemFreeSpace.DeletePartialMatch(labels)
+	vmm.consumedMemory.DeletePartialMatch(labels)
+	vmm.cpuUsage.DeletePartialMatch(labels)
+	vmm.committedStorage.DeletePartialMatch(labels)
+	vmm.uncommittedStorage.DeletePartialMatch(labels)
+}
@@ -25,7 +25,7 @@ func (gip guestInfoProber) Probe(ctx *context.ProbeContext) (Result, error) {
 		return Unknown, err
 	}
 
-	for _, info := range ctx.VM.Spec.ReadinessProbe.GuestInfo {
+	for _, info := range ctx.GetProbeSpec().GuestInfo {
 		key := "guestinfo." + info.Key
 
 		val, ok := vmGuestInfo[key]
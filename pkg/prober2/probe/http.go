@@ -0,0 +1,147 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package probe
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	"github.com/vmware-tanzu/vm-operator/pkg/prober2/context"
+)
+
+const (
+	defaultMinSuccessStatusCode = http.StatusOK
+	defaultMaxSuccessStatusCode = http.StatusBadRequest - 1
+
+	// maxResponseBodyBytes is the number of bytes of the response body that
+	// are read so the connection can be reused.
+	maxResponseBodyBytes = 10 * 1024
+)
+
+// httpProber implements the Probe interface.
+type httpProber struct {
+	transport *http.Transport
+}
+
+// NewHTTPProber creates a new http prober which implements the Probe interface to execute http probes.
+func NewHTTPProber() Probe {
+	return &httpProber{
+		transport: &http.Transport{
+			//nolint:gosec // The probe checks the VM is serving, not that it is trusted.
+			TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+			DisableKeepAlives: true,
+		},
+	}
+}
+
+func (pr httpProber) Probe(ctx *context.ProbeContext) (Result, error) {
+	vm := ctx.VM
+	p := ctx.GetProbeSpec()
+
+	portNum, err := findPort(vm, p.HTTPGet.Port, corev1.ProtocolTCP)
+	if err != nil {
+		return Failure, err
+	}
+
+	host := p.HTTPGet.Host
+	if host == "" {
+		ctx.Logger.V(4).Info("HTTPGet Host not specified, using VM IP", "probe", ctx.String())
+		if host = vmIP(vm); host == "" {
+			return Failure, fmt.Errorf("VM %s doesn't have an IP assigned", vm.NamespacedName())
+		}
+	} else if !isVMIP(vm, host) {
+		// The request is sent from the operator, so it is only ever sent to
+		// the VM rather than to any host the user specifies.
+		return Failure, fmt.Errorf("HTTPGet host %s is not an IP of VM %s", host, vm.NamespacedName())
+	}
+
+	scheme := "http"
+	if p.HTTPGet.Scheme == vmopv1.URISchemeHTTPS {
+		scheme = "https"
+	}
+
+	// The path is parsed so a query string in it is kept rather than escaped.
+	reqURL, err := url.Parse(p.HTTPGet.Path)
+	if err != nil {
+		return Failure, err
+	}
+	reqURL.Scheme = scheme
+	reqURL.Host = net.JoinHostPort(host, strconv.Itoa(portNum))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL.String(), nil)
+	if err != nil {
+		return Failure, err
+	}
+	for _, h := range p.HTTPGet.HTTPHeaders {
+		if strings.EqualFold(h.Name, "Host") {
+			req.Host = h.Value
+			continue
+		}
+		req.Header.Add(h.Name, h.Value)
+	}
+
+	timeout := defaultConnectTimeout
+	if p.TimeoutSeconds > 0 {
+		timeout = time.Duration(p.TimeoutSeconds) * time.Second
+	}
+
+	client := &http.Client{
+		Timeout:   timeout,
+		Transport: pr.transport,
+		// Redirects are not followed so the probe only checks the VM.
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return Failure, err
+	}
+	defer res.Body.Close()
+	_, _ = io.CopyN(io.Discard, res.Body, maxResponseBodyBytes)
+
+	minCode, maxCode := int32(defaultMinSuccessStatusCode), int32(defaultMaxSuccessStatusCode)
+	if r := p.HTTPGet.ExpectedStatus; r != nil {
+		minCode, maxCode = r.Min, r.Max
+	}
+
+	if code := int32(res.StatusCode); code < minCode || code > maxCode {
+		return Failure, fmt.Errorf("HTTP probe failed with status code %d, expected %d-%d", code, minCode, maxCode)
+	}
+
+	return Success, nil
+}
+
+// isVMIP returns true if host is one of the IPs in the VM's network status.
+func isVMIP(vm *vmopv1.VirtualMachine, host string) bool {
+	ip := net.ParseIP(host)
+	if ip == nil || vm.Status.Network == nil {
+		return false
+	}
+
+	addrs := []string{vm.Status.Network.PrimaryIP4, vm.Status.Network.PrimaryIP6}
+	for _, iface := range vm.Status.Network.Interfaces {
+		for _, addr := range iface.IP.Addresses {
+			addrs = append(addrs, addr.Address)
+		}
+	}
+
+	for _, addr := range addrs {
+		if vmIP := net.ParseIP(addr); vmIP != nil && vmIP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
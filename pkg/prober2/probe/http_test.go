@@ -0,0 +1,230 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package probe
+
+import (
+	goctx "context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/prober2/context"
+)
+
+var _ = Describe("HTTP probe", func() {
+	var (
+		vm            *vmopv1.VirtualMachine
+		testHTTPProbe Probe
+		probeCtx      *context.ProbeContext
+
+		testServer *httptest.Server
+		testHost   string
+		testPort   int
+		statusCode int
+		lastReq    *http.Request
+	)
+
+	BeforeEach(func() {
+		vm = &vmopv1.VirtualMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "dummy-vm",
+				Namespace: "dummy-ns",
+			},
+			Spec: vmopv1.VirtualMachineSpec{
+				ClassName: "dummy-vmclass",
+			},
+			Status: vmopv1.VirtualMachineStatus{
+				Network: &vmopv1.VirtualMachineNetworkStatus{},
+			},
+		}
+
+		statusCode = http.StatusOK
+		lastReq = nil
+		testServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			lastReq = r
+			w.WriteHeader(statusCode)
+		}))
+		host, port, err := net.SplitHostPort(testServer.Listener.Addr().String())
+		Expect(err).NotTo(HaveOccurred())
+		testHost = host
+		testPort, err = strconv.Atoi(port)
+		Expect(err).NotTo(HaveOccurred())
+		vm.Status.Network.PrimaryIP4 = testHost
+
+		testHTTPProbe = NewHTTPProber()
+	})
+
+	JustBeforeEach(func() {
+		probeCtx = &context.ProbeContext{
+			Context: goctx.Background(),
+			VM:      vm,
+			Logger:  ctrl.Log.WithName("Probe").WithValues("name", vm.NamespacedName()),
+		}
+	})
+
+	AfterEach(func() {
+		testServer.Close()
+	})
+
+	When("the host is set in the VM spec", func() {
+		BeforeEach(func() {
+			vm.Spec.ReadinessProbe = getVirtualMachineReadinessHTTPProbe(testHost, testPort)
+			vm.Spec.ReadinessProbe.HTTPGet.Path = "/healthz?verbose=true"
+			vm.Spec.ReadinessProbe.HTTPGet.HTTPHeaders = []vmopv1.HTTPHeader{
+				{Name: "X-Probe", Value: "readiness"},
+				{Name: "host", Value: "probe.example.com"},
+			}
+		})
+
+		It("succeeds and sends the request to the path with the query and headers", func() {
+			res, err := testHTTPProbe.Probe(probeCtx)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(res).To(Equal(Success))
+
+			Expect(lastReq).ToNot(BeNil())
+			Expect(lastReq.URL.Path).To(Equal("/healthz"))
+			Expect(lastReq.URL.RawQuery).To(Equal("verbose=true"))
+			Expect(lastReq.Header.Get("X-Probe")).To(Equal("readiness"))
+			Expect(lastReq.Host).To(Equal("probe.example.com"))
+		})
+
+		When("the host is an IP of one of the VM's interfaces", func() {
+			BeforeEach(func() {
+				vm.Status.Network.PrimaryIP4 = "192.0.2.10"
+				vm.Status.Network.Interfaces = []vmopv1.VirtualMachineNetworkInterfaceStatus{
+					{
+						Name: "eth0",
+						IP: vmopv1.VirtualMachineNetworkInterfaceIPStatus{
+							Addresses: []vmopv1.VirtualMachineNetworkInterfaceIPAddrStatus{
+								{Address: "192.0.2.10"},
+								{Address: testHost},
+							},
+						},
+					},
+				}
+			})
+
+			It("succeeds", func() {
+				res, err := testHTTPProbe.Probe(probeCtx)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(res).To(Equal(Success))
+			})
+		})
+
+		When("the host is not an IP of the VM", func() {
+			BeforeEach(func() {
+				vm.Status.Network.PrimaryIP4 = "192.0.2.10"
+			})
+
+			It("fails without sending the request", func() {
+				res, err := testHTTPProbe.Probe(probeCtx)
+				Expect(err).Should(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("is not an IP of VM"))
+				Expect(res).To(Equal(Failure))
+				Expect(lastReq).To(BeNil())
+			})
+		})
+
+		When("the host is a host name", func() {
+			BeforeEach(func() {
+				vm.Spec.ReadinessProbe.HTTPGet.Host = "localhost"
+			})
+
+			It("fails without sending the request", func() {
+				res, err := testHTTPProbe.Probe(probeCtx)
+				Expect(err).Should(HaveOccurred())
+				Expect(res).To(Equal(Failure))
+				Expect(lastReq).To(BeNil())
+			})
+		})
+	})
+
+	When("the host is not set in the VM spec", func() {
+		BeforeEach(func() {
+			vm.Status.Network.PrimaryIP4 = testHost
+			vm.Spec.ReadinessProbe = getVirtualMachineReadinessHTTPProbe("", testPort)
+		})
+
+		It("succeeds using the VM IP", func() {
+			res, err := testHTTPProbe.Probe(probeCtx)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(res).To(Equal(Success))
+		})
+
+		When("the VM does not have an IP", func() {
+			BeforeEach(func() {
+				vm.Status.Network.PrimaryIP4 = ""
+			})
+
+			It("fails", func() {
+				res, err := testHTTPProbe.Probe(probeCtx)
+				Expect(err).Should(HaveOccurred())
+				Expect(res).To(Equal(Failure))
+			})
+		})
+	})
+
+	When("the server returns a status code outside the default range", func() {
+		BeforeEach(func() {
+			statusCode = http.StatusServiceUnavailable
+			vm.Spec.ReadinessProbe = getVirtualMachineReadinessHTTPProbe(testHost, testPort)
+		})
+
+		It("fails", func() {
+			res, err := testHTTPProbe.Probe(probeCtx)
+			Expect(err).Should(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("503"))
+			Expect(res).To(Equal(Failure))
+		})
+	})
+
+	When("the server returns a status code in the expected range", func() {
+		BeforeEach(func() {
+			statusCode = http.StatusUnauthorized
+			vm.Spec.ReadinessProbe = getVirtualMachineReadinessHTTPProbe(testHost, testPort)
+			vm.Spec.ReadinessProbe.HTTPGet.ExpectedStatus = &vmopv1.HTTPStatusCodeRange{
+				Min: 200,
+				Max: 401,
+			}
+		})
+
+		It("succeeds", func() {
+			res, err := testHTTPProbe.Probe(probeCtx)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(res).To(Equal(Success))
+		})
+	})
+
+	When("the server is not listening", func() {
+		BeforeEach(func() {
+			vm.Spec.ReadinessProbe = getVirtualMachineReadinessHTTPProbe(testHost, 10001)
+		})
+
+		It("fails", func() {
+			res, err := testHTTPProbe.Probe(probeCtx)
+			Expect(err).Should(HaveOccurred())
+			Expect(res).To(Equal(Failure))
+		})
+	})
+})
+
+func getVirtualMachineReadinessHTTPProbe(host string, port int) *vmopv1.VirtualMachineReadinessProbeSpec {
+	return &vmopv1.VirtualMachineReadinessProbeSpec{
+		HTTPGet: &vmopv1.HTTPGetAction{
+			Host: host,
+			Port: intstr.FromInt(port),
+		},
+		PeriodSeconds: 1,
+	}
+}
@@ -26,7 +26,7 @@ func NewTCPProber() Probe {
 
 func (pr tcpProber) Probe(ctx *context.ProbeContext) (Result, error) {
 	vm := ctx.VM
-	p := ctx.VM.Spec.ReadinessProbe
+	p := ctx.GetProbeSpec()
 
 	portProto := corev1.ProtocolTCP
 	portNum, err := findPort(vm, p.TCPSocket.Port, portProto)
@@ -37,13 +37,7 @@ func (pr tcpProber) Probe(ctx *context.ProbeContext) (Result, error) {
 	ip := p.TCPSocket.Host
 	if ip == "" {
 		ctx.Logger.V(4).Info("TCPSocket Host not specified, using VM IP", "probe", ctx.String())
-		if vm.Status.Network != nil {
-			ip = vm.Status.Network.PrimaryIP4
-			if ip == "" {
-				ip = vm.Status.Network.PrimaryIP6
-			}
-		}
-		if ip == "" {
+		if ip = vmIP(vm); ip == "" {
 			return Failure, fmt.Errorf("VM %s doesn't have an IP assigned", vm.NamespacedName())
 		}
 	}
@@ -73,6 +67,17 @@ func findPort(vm *vmopv1.VirtualMachine, portName intstr.IntOrString, _ corev1.P
 	return 0, fmt.Errorf("no suitable port for manifest: %s", vm.UID)
 }
 
+// vmIP returns the VM's primary IP, preferring IPv4.
+func vmIP(vm *vmopv1.VirtualMachine) string {
+	if vm.Status.Network == nil {
+		return ""
+	}
+	if ip := vm.Status.Network.PrimaryIP4; ip != "" {
+		return ip
+	}
+	return vm.Status.Network.PrimaryIP6
+}
+
 func checkConnection(proto, host, port string, timeout time.Duration) error {
 	address := net.JoinHostPort(host, port)
 	conn, err := net.DialTimeout(proto, address, timeout)
@@ -29,6 +29,7 @@ import (
 const (
 	proberManagerName       = "virtualmachine-prober-manager"
 	readinessProbeQueueName = "readinessProbeQueue"
+	livenessProbeQueueName  = "livenessProbeQueue"
 
 	// defaultPeriodSeconds represents the default value for the frequency (in seconds) to perform the probe.
 	// We use the same default value as the kubernetes container probe.
@@ -37,6 +38,9 @@ const (
 	// the number of readiness workers.
 	// TODO: find a way to calibrate it.
 	numberOfReadinessWorkers = 5
+
+	// the number of liveness workers.
+	numberOfLivenessWorkers = 5
 )
 
 // Manager represents a prober manager interface.
@@ -50,6 +54,7 @@ type Manager interface {
 type manager struct {
 	client         client.Client
 	readinessQueue workqueue.DelayingInterface
+	livenessQueue  workqueue.DelayingInterface
 	prober         *probe.Prober
 	log            logr.Logger
 	recorder       vmoprecord.Recorder
@@ -61,6 +66,10 @@ type manager struct {
 	// adding VMs to the readiness queue when this VM is already in the heap but not in the queue.
 	readinessMutex       sync.Mutex
 	vmReadinessProbeList map[string]vmopv1.VirtualMachineReadinessProbeSpec
+
+	// vmLivenessProbeList serves the same purpose for the liveness queue.
+	livenessMutex       sync.Mutex
+	vmLivenessProbeList map[string]vmopv1.VirtualMachineLivenessProbeSpec
 }
 
 // NewManger initializes a prober manager.
@@ -72,10 +81,12 @@ func NewManger(
 	probeManager := &manager{
 		client:               client,
 		readinessQueue:       workqueue.NewNamedDelayingQueue(readinessProbeQueueName),
+		livenessQueue:        workqueue.NewNamedDelayingQueue(livenessProbeQueueName),
 		prober:               probe.NewProber(vmProvider),
 		log:                  ctrl.Log.WithName(proberManagerName),
 		recorder:             record,
 		vmReadinessProbeList: make(map[string]vmopv1.VirtualMachineReadinessProbeSpec),
+		vmLivenessProbeList:  make(map[string]vmopv1.VirtualMachineLivenessProbeSpec),
 	}
 	return probeManager
 }
@@ -98,11 +109,19 @@ func (m *manager) AddToProberManager(vm *vmopv1.VirtualMachine) {
 	vmName := vm.NamespacedName()
 	m.log.V(4).Info("Add to prober manager", "vm", vmName)
 
+	m.addToReadinessQueue(vm)
+	m.addToLivenessQueue(vm)
+}
+
+func (m *manager) addToReadinessQueue(vm *vmopv1.VirtualMachine) {
+	vmName := vm.NamespacedName()
+
 	m.readinessMutex.Lock()
 	defer m.readinessMutex.Unlock()
 
 	if vm.Spec.ReadinessProbe != nil &&
-		(vm.Spec.ReadinessProbe.TCPSocket != nil || vm.Spec.ReadinessProbe.GuestHeartbeat != nil || len(vm.Spec.ReadinessProbe.GuestInfo) != 0) {
+		(vm.Spec.ReadinessProbe.TCPSocket != nil || vm.Spec.ReadinessProbe.HTTPGet != nil ||
+			vm.Spec.ReadinessProbe.GuestHeartbeat != nil || len(vm.Spec.ReadinessProbe.GuestInfo) != 0) {
 		// if the VM is not in the list, or its readiness probe spec has been updated, immediately add it to the queue
 		// otherwise, ignore it.
 		if oldProbe, ok := m.vmReadinessProbeList[vmName]; ok && reflect.DeepEqual(oldProbe, vm.Spec.ReadinessProbe) {
@@ -117,14 +136,40 @@ func (m *manager) AddToProberManager(vm *vmopv1.VirtualMachine) {
 	}
 }
 
+func (m *manager) addToLivenessQueue(vm *vmopv1.VirtualMachine) {
+	vmName := vm.NamespacedName()
+
+	m.livenessMutex.Lock()
+	defer m.livenessMutex.Unlock()
+
+	if p := vm.Spec.LivenessProbe; p != nil &&
+		(p.TCPSocket != nil || p.HTTPGet != nil || p.GuestHeartbeat != nil || len(p.GuestInfo) != 0) {
+		// if the VM is not in the list, or its liveness probe spec has been updated, immediately add it to the queue
+		// otherwise, ignore it.
+		if oldProbe, ok := m.vmLivenessProbeList[vmName]; ok && reflect.DeepEqual(oldProbe, *p) {
+			m.log.V(4).Info("VM is already in the liveness probe list and its probe spec is not updated, skip it", "vm", vmName)
+			return
+		}
+
+		m.livenessQueue.Add(client.ObjectKey{Name: vm.Name, Namespace: vm.Namespace})
+		m.vmLivenessProbeList[vmName] = *p
+	} else {
+		delete(m.vmLivenessProbeList, vmName)
+	}
+}
+
 // RemoveFromProberManager removes a VM from the prober manager.
 func (m *manager) RemoveFromProberManager(vm *vmopv1.VirtualMachine) {
 	vmName := vm.NamespacedName()
 	m.log.V(4).Info("Remove from prober manager", "vm", vmName)
 
 	m.readinessMutex.Lock()
-	defer m.readinessMutex.Unlock()
 	delete(m.vmReadinessProbeList, vmName)
+	m.readinessMutex.Unlock()
+
+	m.livenessMutex.Lock()
+	delete(m.vmLivenessProbeList, vmName)
+	m.livenessMutex.Unlock()
 }
 
 // Start starts the probe manager.
@@ -139,9 +184,17 @@ func (m *manager) Start(ctx goctx.Context) error {
 		m.worker(readinessWorker)
 	}
 
+	m.log.Info("Starting liveness workers", "count", numberOfLivenessWorkers)
+	m.workersWG.Add(numberOfLivenessWorkers)
+	for i := 0; i < numberOfLivenessWorkers; i++ {
+		livenessWorker := worker.NewLivenessWorker(m.livenessQueue, m.prober, m.client, m.recorder)
+		m.worker(livenessWorker)
+	}
+
 	<-ctx.Done()
 
 	m.readinessQueue.ShutDown()
+	m.livenessQueue.ShutDown()
 	m.workersWG.Wait()
 	return nil
 }
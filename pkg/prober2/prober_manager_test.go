@@ -218,6 +218,52 @@ var _ = Describe("VirtualMachine probes", func() {
 			})
 		})
 
+		When("VM with an HTTP GET probe is first time being added to the prober manager", func() {
+			It("Should add to the queue and list", func() {
+				vm.Spec.ReadinessProbe = &vmopv1.VirtualMachineReadinessProbeSpec{
+					HTTPGet: &vmopv1.HTTPGetAction{
+						Port: intstr.FromInt(10001),
+					},
+				}
+				testManager.AddToProberManager(vm)
+
+				Expect(testManager.readinessQueue.Len()).To(Equal(1))
+				testManager.readinessMutex.Lock()
+				Expect(testManager.vmReadinessProbeList).Should(HaveKey(vm.NamespacedName()))
+				testManager.readinessMutex.Unlock()
+			})
+		})
+
+		When("VM with a liveness probe is first time being added to the prober manager", func() {
+			It("Should add to the liveness queue and list", func() {
+				vm.Spec.ReadinessProbe = nil
+				vm.Spec.LivenessProbe = &vmopv1.VirtualMachineLivenessProbeSpec{
+					VirtualMachineReadinessProbeSpec: vmopv1.VirtualMachineReadinessProbeSpec{
+						GuestHeartbeat: &vmopv1.GuestHeartbeatAction{},
+					},
+				}
+				testManager.AddToProberManager(vm)
+
+				Expect(testManager.readinessQueue.Len()).To(Equal(0))
+				Expect(testManager.livenessQueue.Len()).To(Equal(1))
+				testManager.livenessMutex.Lock()
+				Expect(testManager.vmLivenessProbeList).Should(HaveKey(vm.NamespacedName()))
+				testManager.livenessMutex.Unlock()
+
+				By("Should do nothing if VM liveness probe is not updated", func() {
+					testManager.AddToProberManager(vm)
+					Expect(testManager.livenessQueue.Len()).To(Equal(1))
+				})
+
+				By("Should remove from the manager when the VM is removed", func() {
+					testManager.RemoveFromProberManager(vm)
+					testManager.livenessMutex.Lock()
+					Expect(testManager.vmLivenessProbeList).ShouldNot(HaveKey(vm.NamespacedName()))
+					testManager.livenessMutex.Unlock()
+				})
+			})
+		})
+
 		When("VM has already been added to the prober manager", func() {
 			var newVM *vmopv1.VirtualMachine
 			JustBeforeEach(func() {
@@ -0,0 +1,243 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package worker
+
+import (
+	goctx "context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	patch "github.com/vmware-tanzu/vm-operator/pkg/patch2"
+	"github.com/vmware-tanzu/vm-operator/pkg/prober2/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/prober2/probe"
+	vmoprecord "github.com/vmware-tanzu/vm-operator/pkg/record"
+)
+
+const (
+	// livenessProbeFailedReason and livenessRestartReason represent reasons for liveness probe events.
+	livenessProbeFailedReason string = "LivenessProbeFailed"
+	livenessRestartReason     string = "LivenessRestart"
+
+	// defaultFailureThreshold is the number of consecutive failures after which the VM is
+	// considered dead when the liveness probe does not specify a failure threshold.
+	defaultFailureThreshold = 3
+
+	// defaultPeriodSeconds is the probe period, in seconds, when the liveness
+	// probe does not specify one.
+	defaultPeriodSeconds = 10
+
+	// maxRestartBackoff is the longest the probe waits, in addition to the
+	// initial delay, after restarting the VM before it is run again.
+	maxRestartBackoff = 5 * time.Minute
+
+	// nextRestartTimeNow is the value of spec.nextRestartTime that requests the VM be restarted.
+	nextRestartTimeNow = "now"
+)
+
+// livenessWorker implements Worker interface.
+type livenessWorker struct {
+	queue    workqueue.DelayingInterface
+	prober   *probe.Prober
+	client   client.Client
+	recorder vmoprecord.Recorder
+}
+
+// NewLivenessWorker creates a new liveness worker to run liveness probes.
+func NewLivenessWorker(
+	queue workqueue.DelayingInterface,
+	prober *probe.Prober,
+	client client.Client,
+	recorder vmoprecord.Recorder,
+) Worker {
+	return &livenessWorker{
+		queue:    queue,
+		prober:   prober,
+		client:   client,
+		recorder: recorder,
+	}
+}
+
+func (w *livenessWorker) GetQueue() workqueue.DelayingInterface {
+	return w.queue
+}
+
+// CreateProbeContext creates a probe context for liveness probe.
+func (w *livenessWorker) CreateProbeContext(vm *vmopv1.VirtualMachine) (*context.ProbeContext, error) {
+	p := vm.Spec.LivenessProbe
+	if p == nil || getProbe(w.prober, &p.VirtualMachineReadinessProbeSpec) == nil {
+		return nil, nil
+	}
+
+	patchHelper, err := patch.NewHelper(vm, w.client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &context.ProbeContext{
+		Context:       goctx.Background(),
+		Logger:        ctrl.Log.WithName("liveness-probe").WithValues("vmName", vm.NamespacedName()),
+		PatchHelper:   patchHelper,
+		VM:            vm,
+		ProbeType:     "liveness",
+		PeriodSeconds: p.PeriodSeconds,
+		ProbeSpec:     &p.VirtualMachineReadinessProbeSpec,
+	}, nil
+}
+
+// ProcessProbeResult records the probe result in the VM's liveness status and
+// requests the VM be restarted once the probe has failed FailureThreshold
+// times in a row.
+func (w *livenessWorker) ProcessProbeResult(ctx *context.ProbeContext, res probe.Result, resErr error) error {
+	vm := ctx.VM
+	p := vm.Spec.LivenessProbe
+
+	if vm.Status.Liveness == nil {
+		vm.Status.Liveness = &vmopv1.VirtualMachineLivenessStatus{}
+	}
+	status := vm.Status.Liveness
+
+	poweredOn := vm.Status.PowerState == vmopv1.VirtualMachinePowerStateOn
+	if !poweredOn {
+		status.PoweredOnTime = nil
+	} else if status.PoweredOnTime == nil {
+		now := metav1.Now()
+		status.PoweredOnTime = &now
+	}
+
+	switch {
+	case vm.Spec.PowerState != vmopv1.VirtualMachinePowerStateOn || !poweredOn:
+		// A VM that is not meant to be powered on is not expected to be alive,
+		// and only a powered on VM may be restarted.
+		status.ConsecutiveFailures = 0
+
+	case inGracePeriod(vm, time.Now()):
+		// The guest may still be booting, so the result is ignored.
+		ctx.Logger.V(4).Info("VM is within its liveness probe grace period, ignoring result", "result", res)
+
+	case res == probe.Success:
+		status.ConsecutiveFailures = 0
+
+	case res == probe.Failure:
+		now := metav1.Now()
+		status.ConsecutiveFailures++
+		status.LastFailureTime = &now
+		status.LastFailureReason = "liveness probe failed"
+		if resErr != nil {
+			status.LastFailureReason = resErr.Error()
+		}
+
+		failureThreshold := p.FailureThreshold
+		if failureThreshold <= 0 {
+			failureThreshold = defaultFailureThreshold
+		}
+
+		if status.ConsecutiveFailures >= failureThreshold {
+			w.recorder.Eventf(vm, livenessProbeFailedReason,
+				"liveness probe failed %d times in a row: %s", status.ConsecutiveFailures, status.LastFailureReason)
+
+			if p.RestartPolicy != vmopv1.VirtualMachineLivenessRestartPolicyNever {
+				ctx.Logger.Info("VM resource LIVENESS probe failed, restarting VM",
+					"consecutiveFailures", status.ConsecutiveFailures, "reason", status.LastFailureReason)
+
+				// The VM controller restarts the VM in accordance with its
+				// restart mode. The mutating webhook replaces "now" with the
+				// current time.
+				vm.Spec.NextRestartTime = nextRestartTimeNow
+				status.RestartCount++
+				status.LastRestartTime = &now
+				status.ConsecutiveFailures = 0
+				w.recorder.Eventf(vm, livenessRestartReason, "restarting VM with restart mode %s", vm.Spec.RestartMode)
+			}
+		}
+
+	default: // probe.Unknown
+		// The liveness of the VM could not be determined, for example, because
+		// vSphere could not be reached, so this does not count as a failure.
+	}
+
+	if err := ctx.PatchHelper.Patch(ctx, vm); err != nil {
+		return errors.Wrapf(err, "patched failed")
+	}
+
+	return nil
+}
+
+func (w *livenessWorker) DoProbe(ctx *context.ProbeContext) error {
+	if inGracePeriod(ctx.VM, time.Now()) {
+		return w.ProcessProbeResult(ctx, probe.Unknown, nil)
+	}
+
+	res, err := w.runProbe(ctx)
+	if err != nil {
+		ctx.Logger.Error(err, "liveness probe fails", "result", res)
+	}
+	return w.ProcessProbeResult(ctx, res, err)
+}
+
+// inGracePeriod returns true if the VM was powered on less than the probe's
+// initial delay ago, or was restarted by the probe less than the initial delay
+// plus the restart back-off ago.
+func inGracePeriod(vm *vmopv1.VirtualMachine, now time.Time) bool {
+	p := vm.Spec.LivenessProbe
+	if p == nil {
+		return false
+	}
+
+	initialDelay := time.Duration(p.InitialDelaySeconds) * time.Second
+
+	status := vm.Status.Liveness
+	if status == nil {
+		status = &vmopv1.VirtualMachineLivenessStatus{}
+	}
+
+	// A VM that has not yet been observed powered on was just powered on.
+	poweredOnTime := now
+	if status.PoweredOnTime != nil {
+		poweredOnTime = status.PoweredOnTime.Time
+	}
+	if now.Before(poweredOnTime.Add(initialDelay)) {
+		return true
+	}
+
+	if t := status.LastRestartTime; t != nil && status.RestartCount > 0 {
+		if now.Before(t.Add(initialDelay + restartBackoff(p.PeriodSeconds, status.RestartCount))) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// restartBackoff returns how long the probe waits after the VM's restartCount
+// restart, which doubles with each restart starting at the probe's period.
+func restartBackoff(periodSeconds, restartCount int32) time.Duration {
+	if periodSeconds <= 0 {
+		periodSeconds = defaultPeriodSeconds
+	}
+
+	backoff := time.Duration(periodSeconds) * time.Second
+	for i := int32(1); i < restartCount && backoff < maxRestartBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxRestartBackoff {
+		backoff = maxRestartBackoff
+	}
+	return backoff
+}
+
+// runProbe runs a specific type of probe based on the VM liveness probe spec.
+func (w *livenessWorker) runProbe(ctx *context.ProbeContext) (probe.Result, error) {
+	if p := getProbe(w.prober, ctx.ProbeSpec); p != nil {
+		return p.Probe(ctx)
+	}
+
+	return probe.Unknown, fmt.Errorf("unknown action specified for VM %s liveness probe", ctx.VM.NamespacedName())
+}
@@ -0,0 +1,262 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package worker
+
+import (
+	goctx "context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgorecord "k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/prober2/context"
+	fakeprobe "github.com/vmware-tanzu/vm-operator/pkg/prober2/fake/probe"
+	"github.com/vmware-tanzu/vm-operator/pkg/prober2/probe"
+	"github.com/vmware-tanzu/vm-operator/pkg/record"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+var _ = Describe("VirtualMachine liveness probes", func() {
+	var (
+		testWorker Worker
+
+		vm    *vmopv1.VirtualMachine
+		vmKey client.ObjectKey
+		ctx   *context.ProbeContext
+
+		fakeClient         client.Client
+		fakeEvents         chan string
+		fakeHeartbeatProbe *fakeprobe.FakeProbe
+	)
+
+	BeforeEach(func() {
+		vm = &vmopv1.VirtualMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "dummy-vm",
+				Namespace: "dummy-ns",
+			},
+			Spec: vmopv1.VirtualMachineSpec{
+				ClassName:   "dummy-vmclass",
+				PowerState:  vmopv1.VirtualMachinePowerStateOn,
+				RestartMode: vmopv1.VirtualMachinePowerOpModeHard,
+				LivenessProbe: &vmopv1.VirtualMachineLivenessProbeSpec{
+					VirtualMachineReadinessProbeSpec: vmopv1.VirtualMachineReadinessProbeSpec{
+						GuestHeartbeat: &vmopv1.GuestHeartbeatAction{},
+						PeriodSeconds:  1,
+					},
+					FailureThreshold: 2,
+				},
+			},
+			Status: vmopv1.VirtualMachineStatus{
+				PowerState: vmopv1.VirtualMachinePowerStateOn,
+			},
+		}
+		vmKey = client.ObjectKey{Name: vm.Name, Namespace: vm.Namespace}
+
+		fakeClient = builder.NewFakeClient()
+		eventRecorder := clientgorecord.NewFakeRecorder(1024)
+		fakeEvents = eventRecorder.Events
+
+		queue := workqueue.NewNamedDelayingQueue("test")
+		fakeHeartbeatProbe = fakeprobe.NewFakeProbe().(*fakeprobe.FakeProbe)
+		prober := &probe.Prober{
+			GuestHeartbeat: fakeHeartbeatProbe,
+		}
+		testWorker = NewLivenessWorker(queue, prober, fakeClient, record.New(eventRecorder))
+	})
+
+	JustBeforeEach(func() {
+		Expect(fakeClient.Create(goctx.Background(), vm)).Should(Succeed())
+		Expect(fakeClient.Get(goctx.Background(), vmKey, vm)).Should(Succeed())
+	})
+
+	doProbe := func() *vmopv1.VirtualMachine {
+		var err error
+		ctx, err = testWorker.CreateProbeContext(vm)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(ctx).ToNot(BeNil())
+		Expect(testWorker.DoProbe(ctx)).Should(Succeed())
+
+		vm = &vmopv1.VirtualMachine{}
+		Expect(fakeClient.Get(goctx.Background(), vmKey, vm)).Should(Succeed())
+		return vm
+	}
+
+	When("the VM does not have a liveness probe", func() {
+		BeforeEach(func() {
+			vm.Spec.LivenessProbe = nil
+		})
+
+		It("Should not create a probe context", func() {
+			ctx, err := testWorker.CreateProbeContext(vm)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ctx).To(BeNil())
+		})
+	})
+
+	When("the probe succeeds", func() {
+		BeforeEach(func() {
+			vm.Status.Liveness = &vmopv1.VirtualMachineLivenessStatus{ConsecutiveFailures: 1}
+			fakeHeartbeatProbe.ProbeFn = func(ctx *context.ProbeContext) (probe.Result, error) {
+				return probe.Success, nil
+			}
+		})
+
+		It("Should reset the consecutive failures", func() {
+			vm := doProbe()
+			Expect(vm.Status.Liveness).ToNot(BeNil())
+			Expect(vm.Status.Liveness.ConsecutiveFailures).To(BeZero())
+			Expect(vm.Spec.NextRestartTime).To(BeEmpty())
+		})
+	})
+
+	When("the probe fails", func() {
+		BeforeEach(func() {
+			fakeHeartbeatProbe.ProbeFn = func(ctx *context.ProbeContext) (probe.Result, error) {
+				return probe.Failure, fmt.Errorf("heartbeat status \"red\" is below threshold")
+			}
+		})
+
+		It("Should record the failure and restart the VM once the failure threshold is reached", func() {
+			By("recording the first failure", func() {
+				vm := doProbe()
+				Expect(vm.Status.Liveness).ToNot(BeNil())
+				Expect(vm.Status.Liveness.ConsecutiveFailures).To(BeEquivalentTo(1))
+				Expect(vm.Status.Liveness.LastFailureReason).To(ContainSubstring("below threshold"))
+				Expect(vm.Status.Liveness.LastFailureTime).ToNot(BeNil())
+				Expect(vm.Status.Liveness.RestartCount).To(BeZero())
+				Expect(vm.Spec.NextRestartTime).To(BeEmpty())
+			})
+
+			By("requesting a restart on the second failure", func() {
+				vm := doProbe()
+				Expect(vm.Status.Liveness.ConsecutiveFailures).To(BeZero())
+				Expect(vm.Status.Liveness.RestartCount).To(BeEquivalentTo(1))
+				Expect(vm.Status.Liveness.LastRestartTime).ToNot(BeNil())
+				Expect(vm.Spec.NextRestartTime).To(Equal("now"))
+				Expect(fakeEvents).Should(Receive(ContainSubstring(livenessProbeFailedReason)))
+				Expect(fakeEvents).Should(Receive(ContainSubstring(livenessRestartReason)))
+			})
+		})
+
+		When("the restart policy is Never", func() {
+			BeforeEach(func() {
+				vm.Spec.LivenessProbe.FailureThreshold = 1
+				vm.Spec.LivenessProbe.RestartPolicy = vmopv1.VirtualMachineLivenessRestartPolicyNever
+			})
+
+			It("Should only record the failure", func() {
+				vm := doProbe()
+				Expect(vm.Status.Liveness.ConsecutiveFailures).To(BeEquivalentTo(1))
+				Expect(vm.Status.Liveness.RestartCount).To(BeZero())
+				Expect(vm.Spec.NextRestartTime).To(BeEmpty())
+				Expect(fakeEvents).Should(Receive(ContainSubstring(livenessProbeFailedReason)))
+			})
+		})
+
+		When("the VM is not meant to be powered on", func() {
+			BeforeEach(func() {
+				vm.Spec.LivenessProbe.FailureThreshold = 1
+				vm.Spec.PowerState = vmopv1.VirtualMachinePowerStateOff
+			})
+
+			It("Should not count the failure", func() {
+				vm := doProbe()
+				Expect(vm.Status.Liveness.ConsecutiveFailures).To(BeZero())
+				Expect(vm.Spec.NextRestartTime).To(BeEmpty())
+			})
+		})
+	})
+
+	When("the VM was powered on less than the initial delay ago", func() {
+		var probed bool
+
+		BeforeEach(func() {
+			probed = false
+			vm.Spec.LivenessProbe.FailureThreshold = 1
+			vm.Spec.LivenessProbe.InitialDelaySeconds = 60
+			fakeHeartbeatProbe.ProbeFn = func(ctx *context.ProbeContext) (probe.Result, error) {
+				probed = true
+				return probe.Failure, fmt.Errorf("heartbeat status \"red\" is below threshold")
+			}
+		})
+
+		It("Should not run the probe", func() {
+			vm := doProbe()
+			Expect(vm.Status.Liveness).ToNot(BeNil())
+			Expect(vm.Status.Liveness.PoweredOnTime).ToNot(BeNil())
+			Expect(vm.Status.Liveness.ConsecutiveFailures).To(BeZero())
+			Expect(vm.Spec.NextRestartTime).To(BeEmpty())
+			Expect(probed).To(BeFalse())
+		})
+	})
+
+	When("the probe restarted the VM", func() {
+		var probed bool
+
+		BeforeEach(func() {
+			probed = false
+			vm.Spec.LivenessProbe.FailureThreshold = 1
+			fakeHeartbeatProbe.ProbeFn = func(ctx *context.ProbeContext) (probe.Result, error) {
+				probed = true
+				return probe.Failure, fmt.Errorf("heartbeat status \"red\" is below threshold")
+			}
+		})
+
+		When("the restart was within the back-off period", func() {
+			BeforeEach(func() {
+				// The third restart waits 4 periods.
+				lastRestartTime := metav1.NewTime(time.Now().Add(-3 * time.Second))
+				vm.Status.Liveness = &vmopv1.VirtualMachineLivenessStatus{
+					RestartCount:    3,
+					LastRestartTime: &lastRestartTime,
+				}
+			})
+
+			It("Should not run the probe", func() {
+				vm := doProbe()
+				Expect(vm.Status.Liveness.RestartCount).To(BeEquivalentTo(3))
+				Expect(vm.Spec.NextRestartTime).To(BeEmpty())
+				Expect(probed).To(BeFalse())
+			})
+		})
+
+		When("the restart was before the back-off period", func() {
+			BeforeEach(func() {
+				lastRestartTime := metav1.NewTime(time.Now().Add(-5 * time.Second))
+				vm.Status.Liveness = &vmopv1.VirtualMachineLivenessStatus{
+					RestartCount:    3,
+					LastRestartTime: &lastRestartTime,
+				}
+			})
+
+			It("Should restart the VM again", func() {
+				vm := doProbe()
+				Expect(vm.Status.Liveness.RestartCount).To(BeEquivalentTo(4))
+				Expect(vm.Spec.NextRestartTime).To(Equal("now"))
+			})
+		})
+	})
+
+	When("the probe result is unknown", func() {
+		BeforeEach(func() {
+			fakeHeartbeatProbe.ProbeFn = func(ctx *context.ProbeContext) (probe.Result, error) {
+				return probe.Unknown, fmt.Errorf("no heartbeat value")
+			}
+		})
+
+		It("Should not count the failure", func() {
+			vm := doProbe()
+			Expect(vm.Status.Liveness.ConsecutiveFailures).To(BeZero())
+		})
+	})
+})
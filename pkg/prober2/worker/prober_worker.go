@@ -19,3 +19,26 @@ type Worker interface {
 	DoProbe(ctx *context.ProbeContext) error
 	ProcessProbeResult(ctx *context.ProbeContext, res probe.Result, resErr error) error
 }
+
+// getProbe returns the probe from the prober that runs the action specified
+// in the probe spec.
+func getProbe(prober *probe.Prober, probeSpec *vmopv1.VirtualMachineReadinessProbeSpec) probe.Probe {
+	if probeSpec == nil {
+		return nil
+	}
+
+	if probeSpec.TCPSocket != nil {
+		return prober.TCPProbe
+	}
+	if probeSpec.HTTPGet != nil {
+		return prober.HTTPGetProbe
+	}
+	if probeSpec.GuestHeartbeat != nil {
+		return prober.GuestHeartbeat
+	}
+	if len(probeSpec.GuestInfo) != 0 {
+		return prober.GuestInfo
+	}
+
+	return nil
+}
@@ -60,7 +60,7 @@ func (w *readinessWorker) GetQueue() workqueue.DelayingInterface {
 func (w *readinessWorker) CreateProbeContext(vm *vmopv1.VirtualMachine) (*context.ProbeContext, error) {
 	p := vm.Spec.ReadinessProbe
 
-	if p.TCPSocket == nil && p.GuestHeartbeat == nil && len(p.GuestInfo) == 0 {
+	if p.TCPSocket == nil && p.HTTPGet == nil && p.GuestHeartbeat == nil && len(p.GuestInfo) == 0 {
 		return nil, nil
 	}
 
@@ -121,21 +121,7 @@ func (w *readinessWorker) DoProbe(ctx *context.ProbeContext) error {
 
 // getProbe returns a specific type of probe method.
 func (w *readinessWorker) getProbe(probeSpec *vmopv1.VirtualMachineReadinessProbeSpec) probe.Probe {
-	if probeSpec == nil {
-		return nil
-	}
-
-	if probeSpec.TCPSocket != nil {
-		return w.prober.TCPProbe
-	}
-	if probeSpec.GuestHeartbeat != nil {
-		return w.prober.GuestHeartbeat
-	}
-	if len(probeSpec.GuestInfo) != 0 {
-		return w.prober.GuestInfo
-	}
-
-	return nil
+	return getProbe(w.prober, probeSpec)
 }
 
 // runProbe runs a specific type of probe based on the VM probe spec.
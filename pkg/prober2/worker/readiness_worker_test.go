@@ -39,6 +39,7 @@ var _ = Describe("VirtualMachine readiness probes", func() {
 		fakeRecorder       record.Recorder
 		fakeEvents         chan string
 		fakeTCPProbe       *fakeprobe.FakeProbe
+		fakeHTTPGetProbe   *fakeprobe.FakeProbe
 		fakeHeartbeatProbe *fakeprobe.FakeProbe
 	)
 
@@ -62,9 +63,11 @@ var _ = Describe("VirtualMachine readiness probes", func() {
 
 		queue := workqueue.NewNamedDelayingQueue("test")
 		fakeTCPProbe = fakeprobe.NewFakeProbe().(*fakeprobe.FakeProbe)
+		fakeHTTPGetProbe = fakeprobe.NewFakeProbe().(*fakeprobe.FakeProbe)
 		fakeHeartbeatProbe = fakeprobe.NewFakeProbe().(*fakeprobe.FakeProbe)
 		prober := &probe.Prober{
 			TCPProbe:       fakeTCPProbe,
+			HTTPGetProbe:   fakeHTTPGetProbe,
 			GuestHeartbeat: fakeHeartbeatProbe,
 		}
 		testWorker = NewReadinessWorker(queue, prober, fakeClient, fakeRecorder)
@@ -160,6 +163,31 @@ var _ = Describe("VirtualMachine readiness probes", func() {
 		})
 	})
 
+	Context("HTTP GET Probe", func() {
+
+		BeforeEach(func() {
+			vm.Spec.ReadinessProbe = getVirtualMachineReadinessHTTPGetProbe(8080)
+			Expect(fakeClient.Create(goctx.Background(), vm)).Should(Succeed())
+			Expect(fakeClient.Get(goctx.Background(), vmKey, vm)).Should(Succeed())
+			var err error
+			ctx, err = testWorker.CreateProbeContext(vm)
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+
+		// Just need to test for probe selection.
+		It("Should update ReadyCondition when probe fails", func() {
+			fakeHTTPGetProbe.ProbeFn = func(ctx *context.ProbeContext) (probe.Result, error) {
+				return probe.Failure, fmt.Errorf("http error")
+			}
+
+			Expect(testWorker.DoProbe(ctx)).Should(Succeed())
+			Expect(fakeClient.Get(ctx, vmKey, vm)).Should(Succeed())
+			condition := conditions.Get(vm, vmopv1.ReadyConditionType)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Message).To(ContainSubstring("http error"))
+		})
+	})
+
 	Context("Guest heartbeat Probe", func() {
 
 		BeforeEach(func() {
@@ -200,6 +228,15 @@ func getVirtualMachineReadinessTCPProbe(port int) *vmopv1.VirtualMachineReadines
 	}
 }
 
+func getVirtualMachineReadinessHTTPGetProbe(port int) *vmopv1.VirtualMachineReadinessProbeSpec {
+	return &vmopv1.VirtualMachineReadinessProbeSpec{
+		HTTPGet: &vmopv1.HTTPGetAction{
+			Port: intstr.FromInt(port),
+		},
+		PeriodSeconds: 1,
+	}
+}
+
 func getVirtualMachineHeartbeatProbe() *vmopv1.VirtualMachineReadinessProbeSpec {
 	return &vmopv1.VirtualMachineReadinessProbeSpec{
 		GuestHeartbeat: &vmopv1.GuestHeartbeatAction{},
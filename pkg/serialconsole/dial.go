@@ -0,0 +1,66 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package serialconsole
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+)
+
+// Dialer connects to a serial console through the proxy like a client.
+type Dialer struct {
+	// Addr is the address the proxy accepts the connections of clients on.
+	Addr string
+
+	// CertFile is the file of the certificate the proxy serves clients with.
+	// Only a proxy that presents this certificate is connected to.
+	CertFile string
+}
+
+// Dial connects to the proxy over TLS and opens the serial console with the
+// given ticket.
+func (d Dialer) Dial(ctx context.Context, ticket string) (net.Conn, error) {
+	certPEM, err := os.ReadFile(d.CertFile)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(certPEM)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("no certificate in %s", d.CertFile)
+	}
+
+	td := tls.Dialer{
+		Config: &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			// The certificate of the proxy is not issued for the address
+			// that the serial ports connect to, so the certificate is pinned
+			// instead of verified.
+			InsecureSkipVerify: true, //nolint:gosec
+			VerifyConnection: func(cs tls.ConnectionState) error {
+				if len(cs.PeerCertificates) == 0 || !bytes.Equal(cs.PeerCertificates[0].Raw, block.Bytes) {
+					return errors.New("serial console proxy presented an unknown certificate")
+				}
+				return nil
+			},
+		},
+	}
+
+	conn, err := td.DialContext(ctx, "tcp", d.Addr)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(conn, ticket+"\r\n"); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return conn, nil
+}
@@ -0,0 +1,10 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package serialconsole
+
+// IsConnected returns true when the serial port with the given service URI is
+// connected to the proxy.
+func (p *Proxy) IsConnected(serviceURI string) bool {
+	return p.getPort(serviceURI) != nil
+}
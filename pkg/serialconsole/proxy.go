@@ -0,0 +1,477 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package serialconsole
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
+)
+
+const (
+	// ticketTimeout is how long a client has to send its ticket after it
+	// connected.
+	ticketTimeout = 30 * time.Second
+
+	// maxTicketLength is the maximum length of the line a client sends its
+	// ticket in.
+	maxTicketLength = 1024
+
+	// clientWriteTimeout is how long writing the output of a serial port to
+	// a client may block before the client is disconnected.
+	clientWriteTimeout = 5 * time.Second
+)
+
+// ValidateFunc validates the ticket that a client connects to a serial
+// console with, and returns the service URI of the serial port the client
+// is connected to, and whether the client only reads the output of the serial
+// port. ErrInvalidTicket is returned when the ticket is not valid.
+type ValidateFunc func(ctx context.Context, ticket, clientAddr string) (string, bool, error)
+
+// PeerValidateFunc validates that the serial port of the VM with the given
+// instance UUID connects to the proxy from peerAddr, the address of the ESXi
+// host the VM runs on.
+type PeerValidateFunc func(ctx context.Context, vmUUID, peerAddr string) error
+
+// errPortConnected is returned when a serial port connects while the serial
+// port is still connected, and the connection is not the destination of a
+// vMotion of the VM.
+var errPortConnected = errors.New("serial port is already connected")
+
+// Proxy is the virtual serial port concentrator (vSPC) that the serial ports
+// of VMs connect to. A client connects to a serial port through the proxy over
+// TLS by sending a one-time ticket on the first line of its connection. The
+// ticket is validated before any data is forwarded to or from the serial port.
+//
+// The state of the tickets is kept in the API, so the proxy runs in every
+// replica of the controller manager rather than only in the leader.
+type Proxy struct {
+	Logger logr.Logger
+
+	// Validate validates the tickets of clients.
+	Validate ValidateFunc
+
+	// ValidatePeer validates the addresses the serial ports connect from.
+	// Serial ports are not accepted when it is nil.
+	ValidatePeer PeerValidateFunc
+
+	// VSPCAddr is the address Start accepts the connections of the serial
+	// ports on.
+	VSPCAddr string
+
+	// ConsoleAddr is the address Start accepts the connections of clients on.
+	ConsoleAddr string
+
+	// CertFile and KeyFile are the files of the certificate and the key that
+	// Start serves the connections of clients over TLS with. The files are
+	// reloaded when they change.
+	CertFile string
+	KeyFile  string
+
+	mu       sync.Mutex
+	ports    map[string]*serialPort
+	vmotions map[string]string
+}
+
+// NewProxy returns a Proxy that listens on the default addresses, and serves
+// clients with the certificate and key in the given files.
+func NewProxy(
+	logger logr.Logger,
+	validate ValidateFunc,
+	validatePeer PeerValidateFunc,
+	certFile, keyFile string) *Proxy {
+
+	return &Proxy{
+		Logger:       logger,
+		Validate:     validate,
+		ValidatePeer: validatePeer,
+		VSPCAddr:     DefaultVSPCAddr,
+		ConsoleAddr:  ":" + strconv.Itoa(DefaultConsolePort),
+		CertFile:     certFile,
+		KeyFile:      keyFile,
+	}
+}
+
+// NeedLeaderElection returns false so that the proxy runs in every replica of
+// the controller manager. The serial ports and the clients connect to the
+// replica that runs on the host of the proxy's address.
+func (p *Proxy) NeedLeaderElection() bool {
+	return false
+}
+
+// Start listens on the proxy's addresses and serves the connections until the
+// context is done.
+func (p *Proxy) Start(ctx context.Context) error {
+	watcher, err := certwatcher.New(p.CertFile, p.KeyFile)
+	if err != nil {
+		return err
+	}
+	go func() {
+		if err := watcher.Start(ctx); err != nil {
+			p.Logger.Error(err, "Failed to watch the serial console proxy certificate")
+		}
+	}()
+
+	vspcListener, err := net.Listen("tcp", p.VSPCAddr)
+	if err != nil {
+		return err
+	}
+
+	consoleListener, err := net.Listen("tcp", p.ConsoleAddr)
+	if err != nil {
+		_ = vspcListener.Close()
+		return err
+	}
+	consoleListener = tls.NewListener(consoleListener, &tls.Config{
+		GetCertificate: watcher.GetCertificate,
+		MinVersion:     tls.VersionTLS12,
+	})
+
+	return p.Serve(ctx, vspcListener, consoleListener)
+}
+
+// Serve accepts the connections of the serial ports on vspcListener and the
+// connections of clients on consoleListener until the context is done.
+func (p *Proxy) Serve(ctx context.Context, vspcListener, consoleListener net.Listener) error {
+	errCh := make(chan error, 2)
+	go func() {
+		errCh <- p.accept(ctx, vspcListener, p.handleVSPC)
+	}()
+	go func() {
+		errCh <- p.accept(ctx, consoleListener, p.handleConsole)
+	}()
+
+	var err error
+	select {
+	case <-ctx.Done():
+	case err = <-errCh:
+	}
+
+	_ = vspcListener.Close()
+	_ = consoleListener.Close()
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+func (p *Proxy) accept(ctx context.Context, l net.Listener, handle func(context.Context, net.Conn)) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go handle(ctx, conn)
+	}
+}
+
+// handleVSPC serves the connection of the serial port of a VM.
+func (p *Proxy) handleVSPC(ctx context.Context, c net.Conn) {
+	conn := &lockedConn{Conn: c}
+	defer conn.Close()
+	defer closeOnDone(ctx, conn)()
+
+	logger := p.Logger.WithValues("remoteAddr", c.RemoteAddr().String())
+
+	peerAddr := c.RemoteAddr().String()
+	if host, _, err := net.SplitHostPort(peerAddr); err == nil {
+		peerAddr = host
+	}
+
+	s := &vspcSession{ctx: ctx, proxy: p, conn: conn, peerAddr: peerAddr}
+	defer func() {
+		if s.port != nil {
+			p.unregister(s.port, conn)
+		}
+	}()
+
+	// Negotiate the binary mode and the VMware telnet extension.
+	var negotiation []byte
+	negotiation = append(negotiation, telnetCommand(telnetDO, telnetOptBinary)...)
+	negotiation = append(negotiation, telnetCommand(telnetWILL, telnetOptBinary)...)
+	negotiation = append(negotiation, telnetCommand(telnetDO, telnetOptSGA)...)
+	negotiation = append(negotiation, telnetCommand(telnetWILL, telnetOptSGA)...)
+	negotiation = append(negotiation, telnetCommand(telnetDO, telnetOptVMwareExt)...)
+	if _, err := conn.Write(negotiation); err != nil {
+		return
+	}
+
+	buf := make([]byte, 4096)
+	for {
+		n, err := conn.Read(buf)
+		if n > 0 {
+			out := s.parser.parse(buf[:n], s)
+			if s.pending {
+				s.register()
+			}
+			if len(s.reply) > 0 {
+				if _, err := conn.Write(s.reply); err != nil {
+					return
+				}
+				s.reply = s.reply[:0]
+			}
+			if s.err == nil && len(out) > 0 && s.pending {
+				s.err = errPortConnected
+			}
+			if s.err != nil {
+				logger.Info("Closing serial port connection", "serviceURI", s.serviceURI, "err", s.err)
+				return
+			}
+			if len(out) > 0 && s.port != nil {
+				s.port.broadcast(out)
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// handleConsole serves the connection of a client. The client is connected
+// to a serial port only after the ticket on the first line is validated. The
+// input of a read-only client is discarded.
+func (p *Proxy) handleConsole(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+	defer closeOnDone(ctx, conn)()
+
+	clientAddr := conn.RemoteAddr().String()
+	if host, _, err := net.SplitHostPort(clientAddr); err == nil {
+		clientAddr = host
+	}
+	logger := p.Logger.WithValues("client", clientAddr)
+
+	_ = conn.SetReadDeadline(time.Now().Add(ticketTimeout))
+	r := bufio.NewReaderSize(conn, maxTicketLength)
+	line, err := r.ReadSlice('\n')
+	if err != nil {
+		logger.Info("Failed to read serial console ticket", "err", err)
+		return
+	}
+	_ = conn.SetReadDeadline(time.Time{})
+
+	serviceURI, readOnly, err := p.Validate(ctx, strings.TrimSpace(string(line)), clientAddr)
+	if err != nil {
+		logger.Info("Rejecting serial console connection", "err", err)
+		if errors.Is(err, ErrInvalidTicket) {
+			_, _ = io.WriteString(conn, ErrInvalidTicket.Error()+"\r\n")
+		}
+		return
+	}
+
+	port := p.getPort(serviceURI)
+	if port == nil {
+		logger.Info("Serial port is not connected", "serviceURI", serviceURI)
+		_, _ = fmt.Fprintf(conn, "serial port is not connected\r\n")
+		return
+	}
+
+	logger.Info("Serial console connected", "serviceURI", serviceURI, "readOnly", readOnly)
+	port.addClient(conn)
+	defer port.removeClient(conn)
+
+	buf := make([]byte, 4096)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 && !readOnly {
+			if err := port.write(buf[:n]); err != nil {
+				return
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// validatePeer validates the address the serial port of a VM connects from.
+func (p *Proxy) validatePeer(ctx context.Context, vmUUID, peerAddr string) error {
+	if p.ValidatePeer == nil {
+		return errors.New("serial port connections are not accepted")
+	}
+	return p.ValidatePeer(ctx, vmUUID, peerAddr)
+}
+
+// register sets the connection of the serial port with the given service URI.
+// The connection of a serial port that is still connected is only replaced
+// by the destination host of a vMotion of the VM, which keeps the clients of
+// the serial port. errPortConnected is returned otherwise.
+func (p *Proxy) register(serviceURI string, conn net.Conn, vmotion bool) (*serialPort, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.ports == nil {
+		p.ports = map[string]*serialPort{}
+	}
+	port, ok := p.ports[serviceURI]
+	if !ok {
+		port = &serialPort{clients: map[net.Conn]struct{}{}}
+		p.ports[serviceURI] = port
+	} else if !vmotion && port.connected() {
+		return nil, errPortConnected
+	}
+	port.setConn(conn)
+	return port, nil
+}
+
+// unregister removes the serial port when the given connection is still its
+// connection, and disconnects its clients.
+func (p *Proxy) unregister(port *serialPort, conn net.Conn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !port.closeIfConn(conn) {
+		return
+	}
+	for serviceURI, pp := range p.ports {
+		if pp == port {
+			delete(p.ports, serviceURI)
+		}
+	}
+}
+
+func (p *Proxy) getPort(serviceURI string) *serialPort {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.ports[serviceURI]
+}
+
+func (p *Proxy) addVMotion(cookie, serviceURI string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.vmotions == nil {
+		p.vmotions = map[string]string{}
+	}
+	p.vmotions[cookie] = serviceURI
+}
+
+// getVMotion returns the service URI of the serial port that began the vMotion
+// with the given cookie.
+func (p *Proxy) getVMotion(cookie string) (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	serviceURI, ok := p.vmotions[cookie]
+	return serviceURI, ok
+}
+
+func (p *Proxy) removeVMotion(cookie string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.vmotions, cookie)
+}
+
+// serialPort is the serial port of a VM that is connected to the proxy, and
+// the clients its output is written to.
+type serialPort struct {
+	mu      sync.Mutex
+	conn    net.Conn
+	clients map[net.Conn]struct{}
+}
+
+func (sp *serialPort) setConn(conn net.Conn) {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	sp.conn = conn
+}
+
+func (sp *serialPort) connected() bool {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	return sp.conn != nil
+}
+
+// closeIfConn disconnects the clients and returns true when the given
+// connection is the connection of the serial port.
+func (sp *serialPort) closeIfConn(conn net.Conn) bool {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	if sp.conn != conn {
+		return false
+	}
+	sp.conn = nil
+	for c := range sp.clients {
+		_ = c.Close()
+		delete(sp.clients, c)
+	}
+	return true
+}
+
+func (sp *serialPort) addClient(conn net.Conn) {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	sp.clients[conn] = struct{}{}
+}
+
+func (sp *serialPort) removeClient(conn net.Conn) {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	_ = conn.Close()
+	delete(sp.clients, conn)
+}
+
+// write writes the input of a client to the serial port.
+func (sp *serialPort) write(p []byte) error {
+	sp.mu.Lock()
+	conn := sp.conn
+	sp.mu.Unlock()
+
+	if conn == nil {
+		return errors.New("serial port is not connected")
+	}
+	_, err := conn.Write(telnetEscape(p))
+	return err
+}
+
+// broadcast writes the output of the serial port to its clients. A client
+// that does not keep up with the output is disconnected.
+func (sp *serialPort) broadcast(p []byte) {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	for c := range sp.clients {
+		_ = c.SetWriteDeadline(time.Now().Add(clientWriteTimeout))
+		if _, err := c.Write(p); err != nil {
+			_ = c.Close()
+			delete(sp.clients, c)
+		}
+	}
+}
+
+// lockedConn serializes the writes to a connection that are made both by the
+// session of the serial port and by its clients.
+type lockedConn struct {
+	net.Conn
+	mu sync.Mutex
+}
+
+func (c *lockedConn) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.Conn.Write(p)
+}
+
+// closeOnDone closes the connection when the context is done, and returns a
+// function that stops waiting for the context.
+func closeOnDone(ctx context.Context, conn net.Conn) func() {
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.Close()
+		case <-done:
+		}
+	}()
+	return func() { close(done) }
+}
@@ -0,0 +1,328 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package serialconsole_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/serialconsole"
+)
+
+const (
+	instanceUUID = "42307e5f-4d4b-4b3a-9f67-6fa4f5a1c3a2"
+	vcUUID       = "42 30 7e 5f 4d 4b 4b 3a-9f 67 6f a4 f5 a1 c3 a2"
+	otherVCUUID  = "42 30 00 00 00 00 00 00-00 00 00 00 00 00 00 01"
+	validTicket  = "ns/uuid/secret"
+	logTicket    = "ns/uuid/log"
+)
+
+func vmwareCommand(subcommand byte, data string) []byte {
+	b := []byte{255, 250, 232, subcommand}
+	b = append(b, bytes.ReplaceAll([]byte(data), []byte{255}, []byte{255, 255})...)
+	return append(b, 255, 240)
+}
+
+// vmwareCommandData returns the data of the first subcommand in p.
+func vmwareCommandData(p []byte, subcommand byte) (string, bool) {
+	i := bytes.Index(p, []byte{255, 250, 232, subcommand})
+	if i < 0 {
+		return "", false
+	}
+	p = p[i+4:]
+	var data []byte
+	for j := 0; j < len(p)-1; j++ {
+		if p[j] == 255 {
+			if p[j+1] == 240 {
+				return string(data), true
+			}
+			j++
+		}
+		data = append(data, p[j])
+	}
+	return "", false
+}
+
+var _ = Describe("Proxy", func() {
+
+	var (
+		cancel          context.CancelFunc
+		proxy           *serialconsole.Proxy
+		vspcListener    net.Listener
+		consoleListener net.Listener
+		serviceURI      string
+		peerAddr        string
+	)
+
+	// connectSerialPort connects to the proxy like the serial port of the VM
+	// with the given VC UUID.
+	connectSerialPort := func(vmUUID string) (net.Conn, *gbytes.Buffer) {
+		conn, err := net.Dial("tcp", vspcListener.Addr().String())
+		Expect(err).ToNot(HaveOccurred())
+		buf := gbytes.BufferReader(conn)
+
+		_, err = conn.Write([]byte{255, 251, 232})
+		Expect(err).ToNot(HaveOccurred())
+		_, err = conn.Write(vmwareCommand(0, string([]byte{0, 1, 70, 71, 73, 80, 81})))
+		Expect(err).ToNot(HaveOccurred())
+		_, err = conn.Write(vmwareCommand(70, "S"+serviceURI))
+		Expect(err).ToNot(HaveOccurred())
+		_, err = conn.Write(vmwareCommand(80, vmUUID))
+		Expect(err).ToNot(HaveOccurred())
+
+		return conn, buf
+	}
+
+	connectClient := func(ticket string) (net.Conn, *gbytes.Buffer) {
+		dialer := serialconsole.Dialer{Addr: consoleListener.Addr().String(), CertFile: certFile}
+		conn, err := dialer.Dial(context.Background(), ticket)
+		Expect(err).ToNot(HaveOccurred())
+		return conn, gbytes.BufferReader(conn)
+	}
+
+	BeforeEach(func() {
+		serviceURI = serialconsole.ServiceURI(instanceUUID)
+		peerAddr = "127.0.0.1"
+
+		var err error
+		vspcListener, err = net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).ToNot(HaveOccurred())
+		consoleListener, err = net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).ToNot(HaveOccurred())
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		Expect(err).ToNot(HaveOccurred())
+		consoleListener = tls.NewListener(consoleListener, &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS12,
+		})
+
+		proxy = serialconsole.NewProxy(ctrllog.Log, func(_ context.Context, ticket, _ string) (string, bool, error) {
+			switch ticket {
+			case validTicket:
+				return serviceURI, false, nil
+			case logTicket:
+				return serviceURI, true, nil
+			}
+			return "", false, serialconsole.ErrInvalidTicket
+		}, func(_ context.Context, vmUUID, addr string) error {
+			if vmUUID != instanceUUID || addr != peerAddr {
+				return errors.New("not a host of the VM")
+			}
+			return nil
+		}, certFile, keyFile)
+
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(context.Background())
+		go func(p *serialconsole.Proxy, vspcListener, consoleListener net.Listener) {
+			_ = p.Serve(ctx, vspcListener, consoleListener)
+		}(proxy, vspcListener, consoleListener)
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	When("the serial port of a VM is connected", func() {
+		var (
+			vmConn net.Conn
+			vmBuf  *gbytes.Buffer
+		)
+
+		BeforeEach(func() {
+			vmConn, vmBuf = connectSerialPort(vcUUID)
+			Eventually(vmBuf.Contents).Should(ContainSubstring(string(vmwareCommand(71, ""))))
+			Eventually(func() bool {
+				return proxy.IsConnected(serviceURI)
+			}).Should(BeTrue())
+		})
+
+		AfterEach(func() {
+			_ = vmConn.Close()
+		})
+
+		It("forwards the serial port to a client with a valid ticket", func() {
+			client, clientBuf := connectClient(validTicket)
+			defer client.Close()
+
+			_, err := io.WriteString(client, "root\n")
+			Expect(err).ToNot(HaveOccurred())
+			Eventually(vmBuf.Contents).Should(ContainSubstring("root\n"))
+
+			_, err = vmConn.Write([]byte("Password: \xff\xff"))
+			Expect(err).ToNot(HaveOccurred())
+			Eventually(clientBuf.Contents).Should(ContainSubstring("Password: \xff"))
+		})
+
+		It("rejects a client with an invalid ticket", func() {
+			client, clientBuf := connectClient("ns/uuid/wrong")
+			defer client.Close()
+
+			Eventually(clientBuf.Contents).Should(ContainSubstring(serialconsole.ErrInvalidTicket.Error()))
+			Eventually(clientBuf.Closed).Should(BeTrue())
+		})
+
+		It("writes the output of the serial port to a read-only client", func() {
+			conn, connBuf := connectClient(logTicket)
+			defer conn.Close()
+
+			// The client is connected to the serial port once its ticket is
+			// validated.
+			Eventually(func() []byte {
+				_, err := vmConn.Write([]byte("login: "))
+				Expect(err).ToNot(HaveOccurred())
+				return connBuf.Contents()
+			}).Should(ContainSubstring("login: "))
+
+			_, err := io.WriteString(conn, "root\n")
+			Expect(err).ToNot(HaveOccurred())
+			Consistently(vmBuf.Contents).ShouldNot(ContainSubstring("root\n"))
+
+			By("closes the connection when the serial port disconnects", func() {
+				Expect(vmConn.Close()).To(Succeed())
+				Eventually(connBuf.Closed).Should(BeTrue())
+				Eventually(func() bool {
+					return proxy.IsConnected(serviceURI)
+				}).Should(BeFalse())
+			})
+		})
+
+		It("rejects the serial port of another VM with the same service URI", func() {
+			otherConn, otherBuf := connectSerialPort(otherVCUUID)
+			defer otherConn.Close()
+
+			Eventually(otherBuf.Closed).Should(BeTrue())
+
+			// The serial port of the VM stays connected.
+			Expect(proxy.IsConnected(serviceURI)).To(BeTrue())
+		})
+
+		It("does not replace the connection of the serial port", func() {
+			otherConn, otherBuf := connectSerialPort(vcUUID)
+			defer otherConn.Close()
+
+			client, clientBuf := connectClient(validTicket)
+			defer client.Close()
+
+			_, err := io.WriteString(client, "root\n")
+			Expect(err).ToNot(HaveOccurred())
+			Eventually(vmBuf.Contents).Should(ContainSubstring("root\n"))
+			Consistently(otherBuf.Contents).ShouldNot(ContainSubstring("root\n"))
+
+			By("closes the connection when it sends output", func() {
+				_, err := otherConn.Write([]byte("login: "))
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(otherBuf.Closed).Should(BeTrue())
+				Consistently(clientBuf.Contents).ShouldNot(ContainSubstring("login: "))
+			})
+		})
+
+		It("connects the serial port on the destination host of a vMotion", func() {
+			_, err := vmConn.Write(vmwareCommand(40, "seq"))
+			Expect(err).ToNot(HaveOccurred())
+			var cookie string
+			Eventually(func() bool {
+				var ok bool
+				cookie, ok = vmwareCommandData(vmBuf.Contents(), 41)
+				return ok
+			}).Should(BeTrue())
+			Expect(cookie).To(HavePrefix("seq"))
+
+			dstConn, dstBuf := connectSerialPort(vcUUID)
+			defer dstConn.Close()
+			_, err = dstConn.Write(vmwareCommand(44, cookie))
+			Expect(err).ToNot(HaveOccurred())
+			Eventually(dstBuf.Contents).Should(ContainSubstring(string(vmwareCommand(45, cookie))))
+
+			client, _ := connectClient(validTicket)
+			defer client.Close()
+			_, err = io.WriteString(client, "root\n")
+			Expect(err).ToNot(HaveOccurred())
+			Eventually(dstBuf.Contents).Should(ContainSubstring("root\n"))
+		})
+
+		It("rejects an unknown vMotion cookie", func() {
+			otherConn, otherBuf := connectSerialPort(vcUUID)
+			defer otherConn.Close()
+
+			_, err := otherConn.Write(vmwareCommand(44, "seq1234"))
+			Expect(err).ToNot(HaveOccurred())
+			Eventually(otherBuf.Closed).Should(BeTrue())
+		})
+	})
+
+	It("rejects the serial port of a VM from another host", func() {
+		peerAddr = "10.0.0.1"
+
+		conn, buf := connectSerialPort(vcUUID)
+		defer conn.Close()
+
+		Eventually(buf.Closed).Should(BeTrue())
+		Expect(proxy.IsConnected(serviceURI)).To(BeFalse())
+	})
+
+	It("runs in every replica of the controller manager", func() {
+		Expect(proxy.NeedLeaderElection()).To(BeFalse())
+	})
+
+	It("does not connect a serial port with an unknown service URI", func() {
+		serviceURI = "some-other-uri"
+
+		conn, buf := connectSerialPort(vcUUID)
+		defer conn.Close()
+
+		Eventually(buf.Contents).Should(ContainSubstring(string(vmwareCommand(73, ""))))
+		Eventually(buf.Closed).Should(BeTrue())
+	})
+})
+
+var _ = Describe("Ticket", func() {
+
+	It("round trips", func() {
+		ticket, err := serialconsole.NewTicket("my-ns", "my-uuid")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ticket.Secret).ToNot(BeEmpty())
+
+		parsed, err := serialconsole.ParseTicket(ticket.String())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(parsed).To(Equal(ticket))
+		Expect(parsed.Matches(ticket.Hash())).To(BeTrue())
+
+		other, err := serialconsole.NewTicket("my-ns", "my-uuid")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(other.Matches(ticket.Hash())).To(BeFalse())
+	})
+
+	It("rejects a malformed ticket", func() {
+		_, err := serialconsole.ParseTicket("my-ns/my-uuid")
+		Expect(err).To(MatchError(serialconsole.ErrInvalidTicket))
+		_, err = serialconsole.ParseTicket("my-ns//secret")
+		Expect(err).To(MatchError(serialconsole.ErrInvalidTicket))
+	})
+})
+
+var _ = Describe("ConsoleAddr", func() {
+
+	It("returns the console port of the proxy URI's host", func() {
+		addr, err := serialconsole.ConsoleAddr("telnet://10.0.0.10:8023")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(addr).To(Equal("10.0.0.10:8024"))
+	})
+
+	It("returns an error when the proxy is not configured", func() {
+		_, err := serialconsole.ConsoleAddr("")
+		Expect(err).To(HaveOccurred())
+		_, err = serialconsole.ConsoleAddr("telnet://:8023")
+		Expect(err).To(HaveOccurred())
+	})
+})
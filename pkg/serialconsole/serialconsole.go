@@ -0,0 +1,121 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package serialconsole implements the proxy that the network-backed serial
+// ports of VMs are accessed through. The serial port of a VM connects to the
+// proxy as a virtual serial port concentrator (vSPC), and a client is only
+// connected to the serial port with a valid one-time ticket.
+package serialconsole
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultVSPCAddr is the address the proxy accepts the connections of the
+	// serial ports of VMs on.
+	DefaultVSPCAddr = ":8023"
+
+	// DefaultConsolePort is the port the proxy accepts the connections of
+	// clients to a serial console on.
+	DefaultConsolePort = 8024
+
+	serviceURIPrefix = "vmoperator-"
+
+	ticketSecretBytes = 32
+)
+
+// ErrVMPoweredOn is returned when a serial port cannot be added to a VM
+// because the VM is powered on.
+var ErrVMPoweredOn = errors.New("a serial port cannot be added to a powered on VM")
+
+// ErrInvalidTicket is returned when a ticket is malformed, unknown, expired or
+// already used.
+var ErrInvalidTicket = errors.New("invalid serial console ticket")
+
+// ServiceURI returns the service URI that the serial port of the VM with the
+// given instance UUID identifies itself with to the proxy. The URI is unique
+// per VM so that the serial ports of different VMs cannot collide.
+func ServiceURI(instanceUUID string) string {
+	return serviceURIPrefix + instanceUUID
+}
+
+// IsServiceURI returns true when the service URI was returned by ServiceURI.
+func IsServiceURI(serviceURI string) bool {
+	return strings.HasPrefix(serviceURI, serviceURIPrefix)
+}
+
+// ConsoleAddr returns the address that clients connect to a serial console
+// with. The proxy accepts the connections of clients on DefaultConsolePort of
+// the same host that the serial ports connect to it at with the given URI.
+func ConsoleAddr(proxyURI string) (string, error) {
+	if proxyURI == "" {
+		return "", errors.New("serial console proxy is not configured")
+	}
+	u, err := url.Parse(proxyURI)
+	if err != nil {
+		return "", fmt.Errorf("invalid serial console proxy URI %q: %w", proxyURI, err)
+	}
+	if u.Hostname() == "" {
+		return "", fmt.Errorf("serial console proxy URI %q has no host", proxyURI)
+	}
+	return net.JoinHostPort(u.Hostname(), strconv.Itoa(DefaultConsolePort)), nil
+}
+
+// Ticket is the one-time ticket that a client connects to a serial console
+// with. The namespace and UUID identify the VirtualMachineSerialConsoleRequest
+// the ticket was issued for, and the secret authenticates the client.
+type Ticket struct {
+	Namespace string
+	UUID      string
+	Secret    string
+}
+
+// NewTicket returns a ticket with a random secret for the request with the
+// given namespace and UUID.
+func NewTicket(namespace, uuid string) (Ticket, error) {
+	b := make([]byte, ticketSecretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return Ticket{}, err
+	}
+	return Ticket{
+		Namespace: namespace,
+		UUID:      uuid,
+		Secret:    base64.RawURLEncoding.EncodeToString(b),
+	}, nil
+}
+
+// ParseTicket parses a ticket returned by Ticket.String.
+func ParseTicket(s string) (Ticket, error) {
+	parts := strings.Split(s, "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return Ticket{}, ErrInvalidTicket
+	}
+	return Ticket{Namespace: parts[0], UUID: parts[1], Secret: parts[2]}, nil
+}
+
+func (t Ticket) String() string {
+	return fmt.Sprintf("%s/%s/%s", t.Namespace, t.UUID, t.Secret)
+}
+
+// Hash returns the hash of the ticket's secret that is stored instead of the
+// secret itself.
+func (t Ticket) Hash() string {
+	sum := sha256.Sum256([]byte(t.Secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// Matches returns true when the ticket's secret has the given hash.
+func (t Ticket) Matches(hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(t.Hash()), []byte(hash)) == 1
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package serialconsole_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+var (
+	// certFile and keyFile are the serving certificate and key the proxy
+	// serves clients with.
+	certFile string
+	keyFile  string
+)
+
+func TestSerialConsole(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Serial Console Test Suite")
+}
+
+var _ = BeforeSuite(func() {
+	certPEM, keyPEM, err := builder.NewServingCertificate()
+	Expect(err).ToNot(HaveOccurred())
+
+	dir, err := os.MkdirTemp("", "serialconsole")
+	Expect(err).ToNot(HaveOccurred())
+	certFile = filepath.Join(dir, "tls.crt")
+	keyFile = filepath.Join(dir, "tls.key")
+	Expect(os.WriteFile(certFile, certPEM, 0400)).To(Succeed())
+	Expect(os.WriteFile(keyFile, keyPEM, 0400)).To(Succeed())
+})
+
+var _ = AfterSuite(func() {
+	if certFile != "" {
+		Expect(os.RemoveAll(filepath.Dir(certFile))).To(Succeed())
+	}
+})
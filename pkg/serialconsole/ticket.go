@@ -0,0 +1,96 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package serialconsole
+
+import (
+	goctx "context"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+)
+
+const (
+	// UUIDLabelKey is set to the UID of a VirtualMachineSerialConsoleRequest
+	// so that the request of a ticket can be looked up by the ticket's UUID.
+	UUIDLabelKey = "vmoperator.vmware.com/serialconsolerequest-uuid"
+
+	// TicketHashAnnotationKey is set to the hash of the secret of the
+	// request's one-time ticket.
+	TicketHashAnnotationKey = "vmoperator.vmware.com/serialconsolerequest-ticket-hash"
+
+	// UsedAnnotationKey is set to the time the request's ticket was used to
+	// open the serial console. A ticket is only valid once.
+	UsedAnnotationKey = "vmoperator.vmware.com/serialconsolerequest-used"
+
+	// LogTicketHashAnnotationKey is set to the hash of the secret of the
+	// ticket that the controller reads the output of the serial port for the
+	// request's log with. The ticket is valid until it is replaced, and only
+	// reads the output of the serial port.
+	LogTicketHashAnnotationKey = "vmoperator.vmware.com/serialconsolerequest-log-ticket-hash"
+)
+
+// ValidateTicket returns the VirtualMachineSerialConsoleRequest that the
+// ticket was issued for, and whether the ticket is the request's log ticket,
+// which only reads the output of the serial port. The request is marked as
+// used so that its one-time ticket is valid only once. ErrInvalidTicket is
+// returned when the ticket is malformed, or its request does not exist, has
+// expired or was already used.
+func ValidateTicket(
+	ctx goctx.Context,
+	c client.Client,
+	ticketStr string,
+	now time.Time) (*vmopv1.VirtualMachineSerialConsoleRequest, bool, error) {
+
+	ticket, err := ParseTicket(ticketStr)
+	if err != nil {
+		return nil, false, err
+	}
+
+	scrList := &vmopv1.VirtualMachineSerialConsoleRequestList{}
+	labelSelector := client.MatchingLabels{UUIDLabelKey: ticket.UUID}
+	if err := c.List(ctx, scrList, client.InNamespace(ticket.Namespace), labelSelector); err != nil {
+		return nil, false, err
+	}
+	if len(scrList.Items) == 0 {
+		return nil, false, fmt.Errorf("%w: request not found", ErrInvalidTicket)
+	}
+	scr := &scrList.Items[0]
+
+	if ticket.Matches(scr.Annotations[LogTicketHashAnnotationKey]) {
+		return scr, true, nil
+	}
+
+	if !ticket.Matches(scr.Annotations[TicketHashAnnotationKey]) {
+		return nil, false, fmt.Errorf("%w: secret does not match", ErrInvalidTicket)
+	}
+
+	// A ticket without an expiry time was not issued by the controller.
+	if expiryTime := scr.Status.ExpiryTime; expiryTime.IsZero() {
+		return nil, false, fmt.Errorf("%w: no expiry time", ErrInvalidTicket)
+	} else if !now.Before(expiryTime.Time) {
+		return nil, false, fmt.Errorf("%w: expired at %s", ErrInvalidTicket, expiryTime)
+	}
+
+	if usedTime := scr.Annotations[UsedAnnotationKey]; usedTime != "" {
+		return nil, false, fmt.Errorf("%w: already used at %s", ErrInvalidTicket, usedTime)
+	}
+
+	// The patch fails with a conflict when the request was changed since it
+	// was read, so that the ticket is used at most once even when it is
+	// validated concurrently.
+	patch := client.MergeFromWithOptions(scr.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	scr.Annotations[UsedAnnotationKey] = now.UTC().Format(time.RFC3339)
+	if err := c.Patch(ctx, scr, patch); err != nil {
+		if apierrors.IsConflict(err) || apierrors.IsNotFound(err) {
+			return nil, false, fmt.Errorf("%w: used or deleted concurrently", ErrInvalidTicket)
+		}
+		return nil, false, err
+	}
+
+	return scr, false, nil
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package serialconsole_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	"github.com/vmware-tanzu/vm-operator/pkg/serialconsole"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+var _ = Describe("ValidateTicket", func() {
+
+	var (
+		k8sClient client.Client
+		scr       *vmopv1.VirtualMachineSerialConsoleRequest
+		ticket    serialconsole.Ticket
+		now       time.Time
+	)
+
+	BeforeEach(func() {
+		now = time.Now()
+
+		scr = builder.DummyVirtualMachineSerialConsoleRequest("dummy-ns", "dummy-scr", "dummy-vm", "")
+		scr.UID = "dummy-uid"
+		scr.Status.ExpiryTime = metav1.NewTime(now.Add(time.Minute))
+
+		var err error
+		ticket, err = serialconsole.NewTicket(scr.Namespace, string(scr.UID))
+		Expect(err).ToNot(HaveOccurred())
+
+		scr.Labels = map[string]string{serialconsole.UUIDLabelKey: string(scr.UID)}
+		scr.Annotations = map[string]string{serialconsole.TicketHashAnnotationKey: ticket.Hash()}
+	})
+
+	JustBeforeEach(func() {
+		k8sClient = builder.NewFakeClient(scr)
+	})
+
+	It("returns the request of a valid ticket only once", func() {
+		validated, readOnly, err := serialconsole.ValidateTicket(context.Background(), k8sClient, ticket.String(), now)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(readOnly).To(BeFalse())
+		Expect(validated.Name).To(Equal(scr.Name))
+		Expect(validated.Annotations).To(HaveKey(serialconsole.UsedAnnotationKey))
+
+		_, _, err = serialconsole.ValidateTicket(context.Background(), k8sClient, ticket.String(), now)
+		Expect(err).To(MatchError(serialconsole.ErrInvalidTicket))
+	})
+
+	It("rejects a ticket with the wrong secret", func() {
+		ticket.Secret = "wrong"
+		_, _, err := serialconsole.ValidateTicket(context.Background(), k8sClient, ticket.String(), now)
+		Expect(err).To(MatchError(serialconsole.ErrInvalidTicket))
+	})
+
+	It("rejects a ticket of an unknown request", func() {
+		ticket.UUID = "unknown-uid"
+		_, _, err := serialconsole.ValidateTicket(context.Background(), k8sClient, ticket.String(), now)
+		Expect(err).To(MatchError(serialconsole.ErrInvalidTicket))
+	})
+
+	It("rejects an expired ticket", func() {
+		_, _, err := serialconsole.ValidateTicket(context.Background(), k8sClient, ticket.String(), now.Add(time.Hour))
+		Expect(err).To(MatchError(serialconsole.ErrInvalidTicket))
+	})
+
+	When("the ticket is the log ticket of the request", func() {
+		var logTicket serialconsole.Ticket
+
+		BeforeEach(func() {
+			var err error
+			logTicket, err = serialconsole.NewTicket(scr.Namespace, string(scr.UID))
+			Expect(err).ToNot(HaveOccurred())
+			scr.Annotations[serialconsole.LogTicketHashAnnotationKey] = logTicket.Hash()
+			scr.Annotations[serialconsole.UsedAnnotationKey] = now.UTC().Format(time.RFC3339)
+		})
+
+		It("returns the request as read-only after the one-time ticket was used", func() {
+			for i := 0; i < 2; i++ {
+				validated, readOnly, err := serialconsole.ValidateTicket(context.Background(), k8sClient, logTicket.String(), now.Add(time.Hour))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(readOnly).To(BeTrue())
+				Expect(validated.Name).To(Equal(scr.Name))
+			}
+		})
+	})
+
+	When("the request has no expiry time", func() {
+		BeforeEach(func() {
+			scr.Status.ExpiryTime = metav1.Time{}
+		})
+
+		It("rejects the ticket", func() {
+			_, _, err := serialconsole.ValidateTicket(context.Background(), k8sClient, ticket.String(), now)
+			Expect(err).To(MatchError(serialconsole.ErrInvalidTicket))
+		})
+	})
+})
@@ -0,0 +1,335 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package serialconsole
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// pendingTimeout is how long a serial port that connects while its previous
+// connection is still registered waits for the previous connection to close,
+// or to present the cookie of a vMotion.
+const pendingTimeout = 30 * time.Second
+
+// Telnet commands and options.
+const (
+	telnetIAC  = 255
+	telnetDONT = 254
+	telnetDO   = 253
+	telnetWONT = 252
+	telnetWILL = 251
+	telnetSB   = 250
+	telnetSE   = 240
+
+	telnetOptBinary    = 0
+	telnetOptSGA       = 3
+	telnetOptVMwareExt = 232
+)
+
+// Subcommands of the VMware telnet extension that the serial port of a VM
+// uses to talk to a virtual serial port concentrator.
+const (
+	vmwareKnownSuboptions1 = 0
+	vmwareKnownSuboptions2 = 1
+	vmwareVMotionBegin     = 40
+	vmwareVMotionGoahead   = 41
+	vmwareVMotionPeer      = 44
+	vmwareVMotionPeerOK    = 45
+	vmwareVMotionComplete  = 46
+	vmwareVMotionAbort     = 48
+	vmwareDoProxy          = 70
+	vmwareWillProxy        = 71
+	vmwareWontProxy        = 73
+	vmwareVMVCUUID         = 80
+	vmwareGetVMVCUUID      = 81
+)
+
+// vmwareSuboptions are the subcommands of the VMware telnet extension that
+// the proxy supports.
+var vmwareSuboptions = []byte{
+	vmwareKnownSuboptions1,
+	vmwareKnownSuboptions2,
+	vmwareVMotionBegin,
+	vmwareVMotionGoahead,
+	vmwareVMotionPeer,
+	vmwareVMotionPeerOK,
+	vmwareVMotionComplete,
+	vmwareVMotionAbort,
+	vmwareDoProxy,
+	vmwareWillProxy,
+	vmwareWontProxy,
+	vmwareVMVCUUID,
+	vmwareGetVMVCUUID,
+}
+
+const (
+	telnetStateData = iota
+	telnetStateIAC
+	telnetStateOption
+	telnetStateSubnegotiation
+	telnetStateSubnegotiationIAC
+)
+
+// telnetParser splits the data received from the serial port of a VM into the
+// output of the serial port, option negotiations and subnegotiations. The
+// parser keeps its state across reads since a command can be split across
+// reads from the connection.
+type telnetParser struct {
+	state   int
+	command byte
+	sb      []byte
+}
+
+// telnetHandler handles the commands found by a telnetParser.
+type telnetHandler interface {
+	option(command, option byte)
+	subnegotiation(data []byte)
+}
+
+// parse returns the output of the serial port in p and passes the commands in
+// p to the handler.
+func (t *telnetParser) parse(p []byte, h telnetHandler) []byte {
+	out := make([]byte, 0, len(p))
+
+	for _, c := range p {
+		switch t.state {
+		case telnetStateData:
+			if c == telnetIAC {
+				t.state = telnetStateIAC
+			} else {
+				out = append(out, c)
+			}
+		case telnetStateIAC:
+			switch c {
+			case telnetIAC:
+				// An escaped 0xFF data byte.
+				out = append(out, c)
+				t.state = telnetStateData
+			case telnetDO, telnetDONT, telnetWILL, telnetWONT:
+				t.command = c
+				t.state = telnetStateOption
+			case telnetSB:
+				t.sb = t.sb[:0]
+				t.state = telnetStateSubnegotiation
+			default:
+				t.state = telnetStateData
+			}
+		case telnetStateOption:
+			h.option(t.command, c)
+			t.state = telnetStateData
+		case telnetStateSubnegotiation:
+			if c == telnetIAC {
+				t.state = telnetStateSubnegotiationIAC
+			} else {
+				t.sb = append(t.sb, c)
+			}
+		case telnetStateSubnegotiationIAC:
+			switch c {
+			case telnetSE:
+				h.subnegotiation(t.sb)
+				t.state = telnetStateData
+			case telnetIAC:
+				t.sb = append(t.sb, c)
+				t.state = telnetStateSubnegotiation
+			default:
+				t.state = telnetStateSubnegotiation
+			}
+		}
+	}
+
+	return out
+}
+
+// telnetEscape escapes the 0xFF bytes in data sent to the serial port.
+func telnetEscape(p []byte) []byte {
+	return bytes.ReplaceAll(p, []byte{telnetIAC}, []byte{telnetIAC, telnetIAC})
+}
+
+func telnetCommand(command, option byte) []byte {
+	return []byte{telnetIAC, command, option}
+}
+
+func vmwareCommand(subcommand byte, data []byte) []byte {
+	b := []byte{telnetIAC, telnetSB, telnetOptVMwareExt, subcommand}
+	b = append(b, telnetEscape(data)...)
+	return append(b, telnetIAC, telnetSE)
+}
+
+// vspcSession is the telnet session of the serial port of a VM that is
+// connected to the proxy. The session negotiates the VMware telnet extension
+// that the serial port identifies itself with and that vMotion of the VM is
+// coordinated with.
+type vspcSession struct {
+	ctx   context.Context
+	proxy *Proxy
+	conn  net.Conn
+
+	// peerAddr is the address of the ESXi host the serial port connects from.
+	peerAddr string
+
+	parser telnetParser
+
+	serviceURI string
+	vmUUID     string
+
+	// peerValidated is set once the address the serial port connects from is
+	// validated for its VM.
+	peerValidated bool
+	// vmotionServiceURI is the service URI of the serial port whose vMotion
+	// cookie the session presented as the destination of the vMotion.
+	vmotionServiceURI string
+	// pending is set while the serial port waits for its connection that is
+	// still registered to close, or to present its vMotion cookie.
+	pending bool
+
+	// port is the serial port the session is registered as the connection of
+	// once the serial port has identified itself.
+	port *serialPort
+	// reply is the data that is sent to the serial port in response to the
+	// commands received from it.
+	reply []byte
+	// err is set when the session is closed because the serial port cannot
+	// be connected to the proxy.
+	err error
+}
+
+func (s *vspcSession) option(command, option byte) {
+	switch command {
+	case telnetWILL:
+		switch option {
+		case telnetOptBinary, telnetOptSGA, telnetOptVMwareExt:
+		default:
+			s.reply = append(s.reply, telnetCommand(telnetDONT, option)...)
+		}
+	case telnetDO:
+		switch option {
+		case telnetOptBinary, telnetOptSGA:
+		default:
+			s.reply = append(s.reply, telnetCommand(telnetWONT, option)...)
+		}
+	}
+}
+
+func (s *vspcSession) subnegotiation(data []byte) {
+	if len(data) < 2 || data[0] != telnetOptVMwareExt {
+		return
+	}
+	subcommand, data := data[1], data[2:]
+
+	switch subcommand {
+	case vmwareKnownSuboptions1:
+		s.reply = append(s.reply, vmwareCommand(vmwareKnownSuboptions2, vmwareSuboptions)...)
+		s.reply = append(s.reply, vmwareCommand(vmwareGetVMVCUUID, nil)...)
+
+	case vmwareDoProxy:
+		if len(data) < 1 {
+			return
+		}
+		// The first byte is the direction of the serial port.
+		s.serviceURI = string(data[1:])
+		if !IsServiceURI(s.serviceURI) {
+			s.reply = append(s.reply, vmwareCommand(vmwareWontProxy, nil)...)
+			s.err = fmt.Errorf("unknown service URI %q", s.serviceURI)
+			return
+		}
+		s.reply = append(s.reply, vmwareCommand(vmwareWillProxy, nil)...)
+		s.register()
+
+	case vmwareVMVCUUID:
+		s.vmUUID = normalizeUUID(string(data))
+		s.register()
+
+	case vmwareVMotionBegin:
+		if s.port == nil {
+			s.err = errors.New("vMotion of a serial port that is not connected")
+			return
+		}
+		// Echo the sequence with a secret that the serial port of the VM on
+		// the destination host presents to continue the session.
+		secret := make([]byte, 4)
+		if _, err := rand.Read(secret); err != nil {
+			s.err = err
+			return
+		}
+		cookie := append(append([]byte{}, data...), secret...)
+		s.proxy.addVMotion(string(cookie), s.serviceURI)
+		s.reply = append(s.reply, vmwareCommand(vmwareVMotionGoahead, cookie)...)
+
+	case vmwareVMotionPeer:
+		serviceURI, ok := s.proxy.getVMotion(string(data))
+		if !ok {
+			s.err = errors.New("unknown vMotion peer")
+			return
+		}
+		s.vmotionServiceURI = serviceURI
+		s.reply = append(s.reply, vmwareCommand(vmwareVMotionPeerOK, data)...)
+		s.register()
+
+	case vmwareVMotionComplete, vmwareVMotionAbort:
+		s.proxy.removeVMotion(string(data))
+	}
+}
+
+// register registers the session as the connection of its serial port once
+// the serial port has identified both itself and its VM. The service URI of a
+// serial port contains the instance UUID of its VM, so a serial port that was
+// copied to another VM, for example by a clone, collides with the serial port
+// of the original VM and is rejected. Only the ESXi hosts the VM may run on
+// can connect its serial port, and a serial port that is still connected is
+// only taken over by the destination host of a vMotion of the VM.
+func (s *vspcSession) register() {
+	if s.port != nil || s.serviceURI == "" || s.vmUUID == "" {
+		return
+	}
+	if s.serviceURI != ServiceURI(s.vmUUID) {
+		s.err = fmt.Errorf("service URI %q collides with the serial port of another VM", s.serviceURI)
+		return
+	}
+
+	if !s.peerValidated {
+		if err := s.proxy.validatePeer(s.ctx, s.vmUUID, s.peerAddr); err != nil {
+			s.err = fmt.Errorf("serial port of VM %s is not accepted from %s: %w", s.vmUUID, s.peerAddr, err)
+			return
+		}
+		s.peerValidated = true
+	}
+
+	port, err := s.proxy.register(s.serviceURI, s.conn, s.vmotionServiceURI == s.serviceURI)
+	if err != nil {
+		if errors.Is(err, errPortConnected) && s.vmotionServiceURI == "" {
+			// The destination host of a vMotion presents its cookie after
+			// the serial port identified itself. The serial port is
+			// disconnected when it does not do so in time.
+			if !s.pending {
+				s.pending = true
+				_ = s.conn.SetReadDeadline(time.Now().Add(pendingTimeout))
+			}
+			return
+		}
+		s.err = err
+		return
+	}
+	if s.pending {
+		s.pending = false
+		_ = s.conn.SetReadDeadline(time.Time{})
+	}
+	s.port = port
+}
+
+// normalizeUUID returns the UUID the serial port identifies the VM with in
+// the format of the VM's instance UUID.
+func normalizeUUID(uuid string) string {
+	uuid = strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(uuid))
+	if len(uuid) != 32 {
+		return uuid
+	}
+	return uuid[:8] + "-" + uuid[8:12] + "-" + uuid[12:16] + "-" + uuid[16:20] + "-" + uuid[20:]
+}
@@ -0,0 +1,35 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package kube
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ProxyAddrServiceName and ProxyAddrServiceNamespace identify the load
+	// balancer Service whose ingress address is the proxy that VM consoles
+	// are accessed through.
+	ProxyAddrServiceName      = "kube-apiserver-lb-svc"
+	ProxyAddrServiceNamespace = "kube-system"
+)
+
+// GetProxyAddr returns the address of the proxy that VM consoles are accessed
+// through, which is the ingress IP of the proxy address load balancer Service.
+func GetProxyAddr(ctx context.Context, k8sClient client.Client) (string, error) {
+	proxySvc := &corev1.Service{}
+	proxySvcObjectKey := client.ObjectKey{Name: ProxyAddrServiceName, Namespace: ProxyAddrServiceNamespace}
+	if err := k8sClient.Get(ctx, proxySvcObjectKey, proxySvc); err != nil {
+		return "", fmt.Errorf("failed to get proxy address service %s: %w", proxySvcObjectKey, err)
+	}
+	if len(proxySvc.Status.LoadBalancer.Ingress) == 0 {
+		return "", fmt.Errorf("no ingress found for proxy address service %s", proxySvcObjectKey)
+	}
+
+	return proxySvc.Status.LoadBalancer.Ingress[0].IP, nil
+}
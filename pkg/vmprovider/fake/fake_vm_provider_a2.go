@@ -31,10 +31,12 @@ type funcsA2 struct {
 	DeleteVirtualMachineFn         func(ctx context.Context, vm *vmopv1.VirtualMachine) error
 	PublishVirtualMachineFn        func(ctx context.Context, vm *vmopv1.VirtualMachine,
 		vmPub *vmopv1.VirtualMachinePublishRequest, cl *imgregv1a1.ContentLibrary, actID string) (string, error)
-	GetVirtualMachineGuestHeartbeatFn  func(ctx context.Context, vm *vmopv1.VirtualMachine) (vmopv1.GuestHeartbeatStatus, error)
-	GetVirtualMachineGuestInfoFn       func(ctx context.Context, vm *vmopv1.VirtualMachine) (map[string]string, error)
-	GetVirtualMachineWebMKSTicketFn    func(ctx context.Context, vm *vmopv1.VirtualMachine, pubKey string) (string, error)
-	GetVirtualMachineHardwareVersionFn func(ctx context.Context, vm *vmopv1.VirtualMachine) (int32, error)
+	GetVirtualMachineGuestHeartbeatFn       func(ctx context.Context, vm *vmopv1.VirtualMachine) (vmopv1.GuestHeartbeatStatus, error)
+	GetVirtualMachineGuestInfoFn            func(ctx context.Context, vm *vmopv1.VirtualMachine) (map[string]string, error)
+	GetVirtualMachineWebMKSTicketFn         func(ctx context.Context, vm *vmopv1.VirtualMachine, pubKey string) (string, error)
+	GetVirtualMachineSerialConsoleFn        func(ctx context.Context, vm *vmopv1.VirtualMachine, pubKey, ticket string) (vmprovider.VirtualMachineSerialConsoleA2, error)
+	GetVirtualMachineClusterHostAddressesFn func(ctx context.Context, vm *vmopv1.VirtualMachine) ([]string, error)
+	GetVirtualMachineHardwareVersionFn      func(ctx context.Context, vm *vmopv1.VirtualMachine) (int32, error)
 
 	// ListItemsFromContentLibraryFn              func(ctx context.Context, contentLibrary *vmopv1.ContentLibraryProvider) ([]string, error)
 	// GetVirtualMachineImageFromContentLibraryFn func(ctx context.Context, contentLibrary *vmopv1.ContentLibraryProvider, itemID string,
@@ -44,6 +46,12 @@ type funcsA2 struct {
 	UpdateContentLibraryItemFn func(ctx context.Context, itemID, newName string, newDescription *string) error
 	SyncVirtualMachineImageFn  func(ctx context.Context, cli, vmi client.Object) error
 
+	ImportContentLibraryItemFn          func(ctx context.Context, contentLibrary string, item vmprovider.ContentLibraryItemImportA2) (string, string, error)
+	GetContentLibraryItemImportStatusFn func(ctx context.Context, itemID, sessionID, fileName string) (vmprovider.ContentLibraryItemImportStatusA2, error)
+	CompleteContentLibraryItemImportFn  func(ctx context.Context, sessionID string) error
+	FailContentLibraryItemImportFn      func(ctx context.Context, sessionID string) error
+	DeleteContentLibraryItemFn          func(ctx context.Context, itemID string) error
+
 	UpdateVcPNIDFn  func(ctx context.Context, vcPNID, vcPort string) error
 	ResetVcClientFn func(ctx context.Context)
 
@@ -52,6 +60,16 @@ type funcsA2 struct {
 	DeleteVirtualMachineSetResourcePolicyFn         func(ctx context.Context, rp *vmopv1.VirtualMachineSetResourcePolicy) error
 	ComputeCPUMinFrequencyFn                        func(ctx context.Context) error
 
+	CreateOrUpdateVirtualMachineSnapshotFn func(ctx context.Context, vm *vmopv1.VirtualMachine, vmSnapshot *vmopv1.VirtualMachineSnapshot) error
+	RevertVirtualMachineSnapshotFn         func(ctx context.Context, vm *vmopv1.VirtualMachine, vmSnapshot *vmopv1.VirtualMachineSnapshot) error
+	DeleteVirtualMachineSnapshotFn         func(ctx context.Context, vm *vmopv1.VirtualMachine, vmSnapshot *vmopv1.VirtualMachineSnapshot) error
+
+	ListVirtualMachineBackupsFn func(ctx context.Context, namespace string) ([]vmprovider.VirtualMachineBackupA2, error)
+
+	ExportVirtualMachineFn func(ctx context.Context, vm *vmopv1.VirtualMachine, export vmprovider.VirtualMachineExportA2) (vmprovider.VirtualMachineExportResultA2, error)
+
+	RunVirtualMachineGuestCommandFn func(ctx context.Context, vm *vmopv1.VirtualMachine, cmd vmprovider.VirtualMachineGuestCommandA2) (vmprovider.VirtualMachineGuestCommandResultA2, error)
+
 	GetTasksByActIDFn func(ctx context.Context, actID string) (tasksInfo []vimTypes.TaskInfo, retErr error)
 }
 
@@ -60,6 +78,7 @@ type VMProviderA2 struct {
 	funcsA2
 	vmMap             map[client.ObjectKey]*vmopv1.VirtualMachine
 	resourcePolicyMap map[client.ObjectKey]*vmopv1.VirtualMachineSetResourcePolicy
+	vmSnapshotMap     map[client.ObjectKey]*vmopv1.VirtualMachineSnapshot
 	vmPubMap          map[string]vimTypes.TaskInfoState
 
 	isPublishVMCalled bool
@@ -74,6 +93,7 @@ func (s *VMProviderA2) Reset() {
 	s.funcsA2 = funcsA2{}
 	s.vmMap = make(map[client.ObjectKey]*vmopv1.VirtualMachine)
 	s.resourcePolicyMap = make(map[client.ObjectKey]*vmopv1.VirtualMachineSetResourcePolicy)
+	s.vmSnapshotMap = make(map[client.ObjectKey]*vmopv1.VirtualMachineSnapshot)
 	s.vmPubMap = make(map[string]vimTypes.TaskInfoState)
 	s.isPublishVMCalled = false
 }
@@ -140,6 +160,24 @@ func (s *VMProviderA2) GetVirtualMachineWebMKSTicket(ctx context.Context, vm *vm
 	return "", nil
 }
 
+func (s *VMProviderA2) GetVirtualMachineSerialConsole(ctx context.Context, vm *vmopv1.VirtualMachine, pubKey, ticket string) (vmprovider.VirtualMachineSerialConsoleA2, error) {
+	s.Lock()
+	defer s.Unlock()
+	if s.GetVirtualMachineSerialConsoleFn != nil {
+		return s.GetVirtualMachineSerialConsoleFn(ctx, vm, pubKey, ticket)
+	}
+	return vmprovider.VirtualMachineSerialConsoleA2{}, nil
+}
+
+func (s *VMProviderA2) GetVirtualMachineClusterHostAddresses(ctx context.Context, vm *vmopv1.VirtualMachine) ([]string, error) {
+	s.Lock()
+	defer s.Unlock()
+	if s.GetVirtualMachineClusterHostAddressesFn != nil {
+		return s.GetVirtualMachineClusterHostAddressesFn(ctx, vm)
+	}
+	return nil, nil
+}
+
 func (s *VMProviderA2) GetVirtualMachineHardwareVersion(ctx context.Context, vm *vmopv1.VirtualMachine) (int32, error) {
 	s.Lock()
 	defer s.Unlock()
@@ -189,6 +227,92 @@ func (s *VMProviderA2) DeleteVirtualMachineSetResourcePolicy(ctx context.Context
 	return nil
 }
 
+func (s *VMProviderA2) CreateOrUpdateVirtualMachineSnapshot(ctx context.Context, vm *vmopv1.VirtualMachine, vmSnapshot *vmopv1.VirtualMachineSnapshot) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.CreateOrUpdateVirtualMachineSnapshotFn != nil {
+		return s.CreateOrUpdateVirtualMachineSnapshotFn(ctx, vm, vmSnapshot)
+	}
+	if vmSnapshot.Status.UniqueID == "" {
+		vmSnapshot.Status.UniqueID = "snapshot-" + vmSnapshot.Name
+	}
+	s.addToVMSnapshotMap(vmSnapshot)
+
+	return nil
+}
+
+func (s *VMProviderA2) RevertVirtualMachineSnapshot(ctx context.Context, vm *vmopv1.VirtualMachine, vmSnapshot *vmopv1.VirtualMachineSnapshot) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.RevertVirtualMachineSnapshotFn != nil {
+		return s.RevertVirtualMachineSnapshotFn(ctx, vm, vmSnapshot)
+	}
+	objectKey := client.ObjectKey{
+		Namespace: vmSnapshot.Namespace,
+		Name:      vmSnapshot.Name,
+	}
+	if _, found := s.vmSnapshotMap[objectKey]; !found {
+		return fmt.Errorf("snapshot %q not found", vmSnapshot.Name)
+	}
+
+	return nil
+}
+
+func (s *VMProviderA2) DeleteVirtualMachineSnapshot(ctx context.Context, vm *vmopv1.VirtualMachine, vmSnapshot *vmopv1.VirtualMachineSnapshot) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.DeleteVirtualMachineSnapshotFn != nil {
+		return s.DeleteVirtualMachineSnapshotFn(ctx, vm, vmSnapshot)
+	}
+	s.deleteFromVMSnapshotMap(vmSnapshot)
+
+	return nil
+}
+
+func (s *VMProviderA2) ListVirtualMachineBackups(ctx context.Context, namespace string) ([]vmprovider.VirtualMachineBackupA2, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.ListVirtualMachineBackupsFn != nil {
+		return s.ListVirtualMachineBackupsFn(ctx, namespace)
+	}
+
+	return nil, nil
+}
+
+func (s *VMProviderA2) ExportVirtualMachine(
+	ctx context.Context,
+	vm *vmopv1.VirtualMachine,
+	export vmprovider.VirtualMachineExportA2) (vmprovider.VirtualMachineExportResultA2, error) {
+
+	s.Lock()
+	defer s.Unlock()
+
+	if s.ExportVirtualMachineFn != nil {
+		return s.ExportVirtualMachineFn(ctx, vm, export)
+	}
+
+	return vmprovider.VirtualMachineExportResultA2{}, nil
+}
+
+func (s *VMProviderA2) RunVirtualMachineGuestCommand(
+	ctx context.Context,
+	vm *vmopv1.VirtualMachine,
+	cmd vmprovider.VirtualMachineGuestCommandA2) (vmprovider.VirtualMachineGuestCommandResultA2, error) {
+
+	s.Lock()
+	defer s.Unlock()
+
+	if s.RunVirtualMachineGuestCommandFn != nil {
+		return s.RunVirtualMachineGuestCommandFn(ctx, vm, cmd)
+	}
+
+	return vmprovider.VirtualMachineGuestCommandResultA2{}, nil
+}
+
 func (s *VMProviderA2) ComputeCPUMinFrequency(ctx context.Context) error {
 	s.Lock()
 	defer s.Unlock()
@@ -277,6 +401,58 @@ func (s *VMProviderA2) UpdateContentLibraryItem(ctx context.Context, itemID, new
 	return nil
 }
 
+func (s *VMProviderA2) ImportContentLibraryItem(ctx context.Context,
+	contentLibrary string, item vmprovider.ContentLibraryItemImportA2) (string, string, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.ImportContentLibraryItemFn != nil {
+		return s.ImportContentLibraryItemFn(ctx, contentLibrary, item)
+	}
+	return "", "", nil
+}
+
+func (s *VMProviderA2) GetContentLibraryItemImportStatus(ctx context.Context,
+	itemID, sessionID, fileName string) (vmprovider.ContentLibraryItemImportStatusA2, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.GetContentLibraryItemImportStatusFn != nil {
+		return s.GetContentLibraryItemImportStatusFn(ctx, itemID, sessionID, fileName)
+	}
+	return vmprovider.ContentLibraryItemImportStatusA2{}, nil
+}
+
+func (s *VMProviderA2) CompleteContentLibraryItemImport(ctx context.Context, sessionID string) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.CompleteContentLibraryItemImportFn != nil {
+		return s.CompleteContentLibraryItemImportFn(ctx, sessionID)
+	}
+	return nil
+}
+
+func (s *VMProviderA2) FailContentLibraryItemImport(ctx context.Context, sessionID string) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.FailContentLibraryItemImportFn != nil {
+		return s.FailContentLibraryItemImportFn(ctx, sessionID)
+	}
+	return nil
+}
+
+func (s *VMProviderA2) DeleteContentLibraryItem(ctx context.Context, itemID string) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.DeleteContentLibraryItemFn != nil {
+		return s.DeleteContentLibraryItemFn(ctx, itemID)
+	}
+	return nil
+}
+
 func (s *VMProviderA2) GetTasksByActID(ctx context.Context, actID string) (tasksInfo []vimTypes.TaskInfo, retErr error) {
 	s.Lock()
 	defer s.Unlock()
@@ -332,6 +508,22 @@ func (s *VMProviderA2) deleteFromResourcePolicyMap(rp *vmopv1.VirtualMachineSetR
 	delete(s.resourcePolicyMap, objectKey)
 }
 
+func (s *VMProviderA2) addToVMSnapshotMap(vmSnapshot *vmopv1.VirtualMachineSnapshot) {
+	objectKey := client.ObjectKey{
+		Namespace: vmSnapshot.Namespace,
+		Name:      vmSnapshot.Name,
+	}
+	s.vmSnapshotMap[objectKey] = vmSnapshot
+}
+
+func (s *VMProviderA2) deleteFromVMSnapshotMap(vmSnapshot *vmopv1.VirtualMachineSnapshot) {
+	objectKey := client.ObjectKey{
+		Namespace: vmSnapshot.Namespace,
+		Name:      vmSnapshot.Name,
+	}
+	delete(s.vmSnapshotMap, objectKey)
+}
+
 func (s *VMProviderA2) AddToVMPublishMap(actID string, result vimTypes.TaskInfoState) {
 	s.vmPubMap[actID] = result
 }
@@ -359,6 +551,7 @@ func NewVMProviderA2() *VMProviderA2 {
 	provider := VMProviderA2{
 		vmMap:             map[client.ObjectKey]*vmopv1.VirtualMachine{},
 		resourcePolicyMap: map[client.ObjectKey]*vmopv1.VirtualMachineSetResourcePolicy{},
+		vmSnapshotMap:     map[client.ObjectKey]*vmopv1.VirtualMachineSnapshot{},
 		vmPubMap:          map[string]vimTypes.TaskInfoState{},
 	}
 	return &provider
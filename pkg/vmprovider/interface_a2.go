@@ -5,6 +5,8 @@ package vmprovider
 
 import (
 	"context"
+	"io"
+	"time"
 
 	"github.com/vmware/govmomi/vapi/library"
 	vimTypes "github.com/vmware/govmomi/vim25/types"
@@ -24,12 +26,24 @@ type VirtualMachineProviderInterfaceA2 interface {
 	GetVirtualMachineGuestHeartbeat(ctx context.Context, vm *v1alpha2.VirtualMachine) (v1alpha2.GuestHeartbeatStatus, error)
 	GetVirtualMachineGuestInfo(ctx context.Context, vm *v1alpha2.VirtualMachine) (map[string]string, error)
 	GetVirtualMachineWebMKSTicket(ctx context.Context, vm *v1alpha2.VirtualMachine, pubKey string) (string, error)
+	GetVirtualMachineSerialConsole(ctx context.Context, vm *v1alpha2.VirtualMachine, pubKey, ticket string) (VirtualMachineSerialConsoleA2, error)
+	GetVirtualMachineClusterHostAddresses(ctx context.Context, vm *v1alpha2.VirtualMachine) ([]string, error)
 	GetVirtualMachineHardwareVersion(ctx context.Context, vm *v1alpha2.VirtualMachine) (int32, error)
 
 	CreateOrUpdateVirtualMachineSetResourcePolicy(ctx context.Context, resourcePolicy *v1alpha2.VirtualMachineSetResourcePolicy) error
 	IsVirtualMachineSetResourcePolicyReady(ctx context.Context, availabilityZoneName string, resourcePolicy *v1alpha2.VirtualMachineSetResourcePolicy) (bool, error)
 	DeleteVirtualMachineSetResourcePolicy(ctx context.Context, resourcePolicy *v1alpha2.VirtualMachineSetResourcePolicy) error
 
+	CreateOrUpdateVirtualMachineSnapshot(ctx context.Context, vm *v1alpha2.VirtualMachine, vmSnapshot *v1alpha2.VirtualMachineSnapshot) error
+	RevertVirtualMachineSnapshot(ctx context.Context, vm *v1alpha2.VirtualMachine, vmSnapshot *v1alpha2.VirtualMachineSnapshot) error
+	DeleteVirtualMachineSnapshot(ctx context.Context, vm *v1alpha2.VirtualMachine, vmSnapshot *v1alpha2.VirtualMachineSnapshot) error
+
+	ListVirtualMachineBackups(ctx context.Context, namespace string) ([]VirtualMachineBackupA2, error)
+
+	ExportVirtualMachine(ctx context.Context, vm *v1alpha2.VirtualMachine, export VirtualMachineExportA2) (VirtualMachineExportResultA2, error)
+
+	RunVirtualMachineGuestCommand(ctx context.Context, vm *v1alpha2.VirtualMachine, cmd VirtualMachineGuestCommandA2) (VirtualMachineGuestCommandResultA2, error)
+
 	// "Infra" related
 	UpdateVcPNID(ctx context.Context, vcPNID, vcPort string) error
 	ResetVcClient(ctx context.Context)
@@ -37,7 +51,184 @@ type VirtualMachineProviderInterfaceA2 interface {
 
 	GetItemFromLibraryByName(ctx context.Context, contentLibrary, itemName string) (*library.Item, error)
 	UpdateContentLibraryItem(ctx context.Context, itemID, newName string, newDescription *string) error
+	ImportContentLibraryItem(ctx context.Context, contentLibrary string, item ContentLibraryItemImportA2) (itemID, sessionID string, err error)
+	GetContentLibraryItemImportStatus(ctx context.Context, itemID, sessionID, fileName string) (ContentLibraryItemImportStatusA2, error)
+	CompleteContentLibraryItemImport(ctx context.Context, sessionID string) error
+	FailContentLibraryItemImport(ctx context.Context, sessionID string) error
+	DeleteContentLibraryItem(ctx context.Context, itemID string) error
 	SyncVirtualMachineImage(ctx context.Context, cli, vmi client.Object) error
 
 	GetTasksByActID(ctx context.Context, actID string) (tasksInfo []vimTypes.TaskInfo, retErr error)
 }
+
+// VirtualMachineBackupA2 is the data that was backed up in the ExtraConfig of
+// a VM managed by VM Service.
+type VirtualMachineBackupA2 struct {
+	// Name is the name of the VM on the underlying infrastructure.
+	Name string
+	// UniqueID is the unique identifier of the VM on the underlying
+	// infrastructure.
+	UniqueID string
+	// VM is the backed up VirtualMachine resource. It is nil when the VM has
+	// no backup data.
+	VM *v1alpha2.VirtualMachine
+	// BootstrapData is the backed up data of the VM's bootstrap Secret.
+	BootstrapData map[string]string
+	// DiskFileNames maps the name of each PersistentVolumeClaim attached to
+	// the VM to the datastore path of its disk.
+	DiskFileNames map[string]string
+	// CloudInitInstanceID is the backed up Cloud-Init instance ID of the VM.
+	CloudInitInstanceID string
+	// Err is set when the VM's backup data could not be decoded.
+	Err error
+}
+
+// VirtualMachineExportTargetA2 is where the files of an exported VM are
+// written.
+type VirtualMachineExportTargetA2 interface {
+	// WriteFile writes the file with the given name and size in bytes. The
+	// size is -1 when it is not known in advance.
+	WriteFile(ctx context.Context, name string, size int64, r io.Reader) error
+}
+
+// VirtualMachineExportA2 describes how a VM is exported.
+type VirtualMachineExportA2 struct {
+	// Name is the base name of the exported files, ex. the OVF descriptor is
+	// written to <Name>.ovf.
+	Name string
+	// Format is the format the VM is exported to.
+	Format v1alpha2.VirtualMachineExportFormat
+	// Target is where the exported files are written.
+	Target VirtualMachineExportTargetA2
+	// Progress, if not nil, is called with the estimated percentage of the
+	// export that is complete.
+	Progress func(percent int32)
+	// Written are the files that an earlier attempt of the export wrote to
+	// the target. The disks of an OVF among them are not written again.
+	Written []v1alpha2.VirtualMachineExportFile
+	// FileWritten, if not nil, is called with each file of an OVF once it
+	// has been written to the target.
+	FileWritten func(file v1alpha2.VirtualMachineExportFile)
+}
+
+// VirtualMachineExportResultA2 is the result of exporting a VM.
+type VirtualMachineExportResultA2 struct {
+	// Files describes the exported files. For the OVA format, these are the
+	// files contained in the OVA file.
+	Files []v1alpha2.VirtualMachineExportFile
+	// Manifest is the content of the OVF manifest.
+	Manifest string
+}
+
+// VirtualMachineSerialConsoleA2 is the network-backed serial port of a VM.
+type VirtualMachineSerialConsoleA2 struct {
+	// ServiceURI is the service URI the serial port is connected to the
+	// serial console proxy with.
+	ServiceURI string
+	// Response is the ticket of the serial console, encrypted with the
+	// public key of the request. Response is empty when no ticket is given.
+	Response string
+}
+
+// VirtualMachineGuestFileA2 is a file that is uploaded into the guest of a VM.
+type VirtualMachineGuestFileA2 struct {
+	// Path is the absolute path in the guest the file is written to.
+	Path string
+	// Data is the content of the file.
+	Data []byte
+}
+
+// VirtualMachineGuestCommandA2 describes a command run in the guest of a VM.
+type VirtualMachineGuestCommandA2 struct {
+	// Username and Password are the credentials of the guest account the
+	// command is run as.
+	Username string
+	Password string
+	// Command is the absolute path in the guest of the program to run.
+	Command string
+	// Args is the list of arguments passed to the command.
+	Args []string
+	// Env is the list of environment variables, in the NAME=value form, set
+	// for the command.
+	Env []string
+	// WorkingDir is the directory in the guest the command is run in.
+	WorkingDir string
+	// Files are uploaded into the guest before the command is run.
+	Files []VirtualMachineGuestFileA2
+	// Timeout is how long the command is allowed to run before it is
+	// terminated.
+	Timeout time.Duration
+	// MaxOutputBytes is the maximum size of the tail of the standard output
+	// and standard error of the command that is returned.
+	MaxOutputBytes int64
+}
+
+// VirtualMachineGuestCommandResultA2 is the result of a command run in the
+// guest of a VM.
+type VirtualMachineGuestCommandResultA2 struct {
+	// ExitCode is the exit code of the command.
+	ExitCode int32
+	// Stdout is the tail of the standard output of the command.
+	Stdout string
+	// StdoutTruncated is true when the standard output was larger than
+	// MaxOutputBytes.
+	StdoutTruncated bool
+	// Stderr is the tail of the standard error of the command.
+	Stderr string
+	// StderrTruncated is true when the standard error was larger than
+	// MaxOutputBytes.
+	StderrTruncated bool
+	// TimedOut is true when the command was terminated because it did not
+	// exit before the timeout.
+	TimedOut bool
+}
+
+// ContentLibraryItemImportA2 describes a content library item that is created
+// by pulling a file from a URL.
+type ContentLibraryItemImportA2 struct {
+	// Name is the name of the content library item.
+	Name string
+	// Description is the description of the content library item.
+	Description string
+	// URL is the HTTP or HTTPS URL of the file that is pulled into the item.
+	// The name of the file in the item is the last element of the URL's
+	// path.
+	URL string
+	// ChecksumAlgorithm is the algorithm of Checksum, ex. SHA256.
+	ChecksumAlgorithm string
+	// Checksum is the expected checksum of the file in hex.
+	Checksum string
+}
+
+const (
+	// ContentLibraryItemImportStateActive is the state of an import that is
+	// in progress.
+	ContentLibraryItemImportStateActive = "ACTIVE"
+	// ContentLibraryItemImportStateTransferred is the state of an import
+	// whose file was transferred into the update session. The session must be
+	// completed for the file to be added to the item.
+	ContentLibraryItemImportStateTransferred = "TRANSFERRED"
+	// ContentLibraryItemImportStateDone is the state of an import that
+	// succeeded.
+	ContentLibraryItemImportStateDone = "DONE"
+	// ContentLibraryItemImportStateError is the state of an import that
+	// failed.
+	ContentLibraryItemImportStateError = "ERROR"
+)
+
+// ContentLibraryItemImportStatusA2 is the status of a content library item
+// import.
+type ContentLibraryItemImportStatusA2 struct {
+	// State is the state of the update session: ACTIVE, TRANSFERRED, DONE or
+	// ERROR. The state is DONE if the session no longer exists but the item
+	// has files.
+	State string
+	// Progress is the percentage of the file that has been transferred.
+	Progress int32
+	// Message is the error message when the State is ERROR.
+	Message string
+	// File is the transferred file of the update session, including the
+	// checksum the content library validated it against, when the State is
+	// TRANSFERRED.
+	File *library.UpdateFile
+}
@@ -25,6 +25,14 @@ const (
 	VMOperatorV1Alpha1ConfigReady    = "ready"
 	VMOperatorV1Alpha1ConfigEnabled  = "enabled"
 
+	// BootDiskCapacityExtraConfigKey ExtraConfig key that hints the capacity, in bytes, of the boot disk
+	// to the guest after the boot disk has been extended.
+	BootDiskCapacityExtraConfigKey = "guestinfo.vmservice.boot-disk-capacity"
+
+	// VolumeDiskExtraConfigKeyPrefix is the prefix of the ExtraConfig key that records the controller key
+	// and unit number of the disk created for an ephemeral or image volume. The volume name is the suffix.
+	VolumeDiskExtraConfigKeyPrefix = "vmservice.volume-disk."
+
 	// GOSCPendingExtraConfigKey and GOSCIgnoreToolsCheckExtraConfigKey are GOSC Related ExtraConfig keys.
 	GOSCPendingExtraConfigKey          = "tools.deployPkg.fileName"
 	GOSCIgnoreToolsCheckExtraConfigKey = "vmware.tools.gosc.ignoretoolscheck"
@@ -45,6 +53,27 @@ const (
 	// VMImageCLVersionAnnotationVersion is the version of the VMImageCLVersionAnnotation for the VirtualMachineImage.
 	VMImageCLVersionAnnotationVersion = 1
 
+	// StorageRelocateTaskAnnotation is the VM annotation with the ID of the
+	// task that relocates the VM's storage to its storage class.
+	StorageRelocateTaskAnnotation = pkg.VMOperatorKey + "/storage-relocate-task"
+	// StorageRelocateStorageClassAnnotation is the VM annotation with the
+	// storage class the task in StorageRelocateTaskAnnotation relocates the
+	// VM's storage to.
+	StorageRelocateStorageClassAnnotation = pkg.VMOperatorKey + "/storage-relocate-storage-class"
+	// ZoneMigrateTaskAnnotation is the VM annotation with the ID of the task
+	// that migrates the VM to the zone of its zone label.
+	ZoneMigrateTaskAnnotation = pkg.VMOperatorKey + "/zone-migrate-task"
+	// ImageVolumeCopyTaskAnnotation is the VM annotation with the ID of the
+	// task that copies the image disk of an image volume.
+	ImageVolumeCopyTaskAnnotation = pkg.VMOperatorKey + "/image-volume-copy-task"
+	// ImageVolumeCopyVolumeAnnotation is the VM annotation with the name of
+	// the image volume whose disk the task in ImageVolumeCopyTaskAnnotation
+	// copies.
+	ImageVolumeCopyVolumeAnnotation = pkg.VMOperatorKey + "/image-volume-copy-volume"
+	// ImageVolumeCopyDiskAnnotation is the VM annotation with the datastore
+	// path the task in ImageVolumeCopyTaskAnnotation copies the disk to.
+	ImageVolumeCopyDiskAnnotation = pkg.VMOperatorKey + "/image-volume-copy-disk"
+
 	PCIPassthruMMIOOverrideAnnotation = pkg.VMOperatorKey + "/pci-passthru-64bit-mmio-size"
 	PCIPassthruMMIOExtraConfigKey     = "pciPassthru.use64bitMMIO"    //nolint:gosec
 	PCIPassthruMMIOSizeExtraConfigKey = "pciPassthru.64bitMMIOSizeGB" //nolint:gosec
@@ -6,10 +6,13 @@ package contentlibrary
 import (
 	"context"
 	"io"
+	"net/http"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	k8serrors "k8s.io/apimachinery/pkg/util/errors"
@@ -34,6 +37,16 @@ type Provider interface {
 	UpdateLibraryItem(ctx context.Context, itemID, newName string, newDescription *string) error
 	RetrieveOvfEnvelopeFromLibraryItem(ctx context.Context, item *library.Item) (*ovf.Envelope, error)
 	RetrieveOvfEnvelopeByLibraryItemID(ctx context.Context, itemID string) (*ovf.Envelope, error)
+	ImportLibraryItemFromURL(ctx context.Context, libraryItem library.Item, fileURL string,
+		checksum *library.Checksum) (string, string, error)
+	GetLibraryItemUpdateSession(ctx context.Context, sessionID string) (*library.Session, error)
+	GetLibraryItemUpdateSessionFile(ctx context.Context, sessionID, fileName string) (*library.UpdateFile, error)
+	KeepAliveLibraryItemUpdateSession(ctx context.Context, sessionID string) error
+	CompleteLibraryItemUpdateSession(ctx context.Context, sessionID string) error
+	FailLibraryItemUpdateSession(ctx context.Context, sessionID string) error
+	ListLibraryItemFiles(ctx context.Context, itemID string) ([]library.File, error)
+	GetLibraryItemDiskStorage(ctx context.Context, itemID string) ([]LibraryItemFileStorage, error)
+	DeleteLibraryItem(ctx context.Context, itemID string) error
 
 	// TODO: Testing only. Remove these from this file.
 	CreateLibraryItem(ctx context.Context, libraryItem library.Item, path string) error
@@ -243,6 +256,182 @@ func (cs *provider) UpdateLibraryItem(ctx context.Context, itemID, newName strin
 	return cs.libMgr.UpdateLibraryItem(ctx, item)
 }
 
+// ImportLibraryItemFromURL creates the content library item and an update
+// session that pulls the file at the URL into the item. The content library
+// service performs the transfer in the background, and the session must be
+// completed once the file is transferred for the file to be added to the item.
+// The IDs of the item and the session are returned.
+//
+// The URL is only ever accessed by the content library service, which must
+// trust the certificate of an HTTPS server, and not from the control plane.
+func (cs *provider) ImportLibraryItemFromURL(ctx context.Context, libraryItem library.Item,
+	fileURL string, checksum *library.Checksum) (string, string, error) {
+	log.Info("Importing Library Item", "item", libraryItem, "url", fileURL)
+
+	u, err := url.Parse(fileURL)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "failed to parse url: %s", fileURL)
+	}
+
+	itemID, err := cs.libMgr.CreateLibraryItem(ctx, libraryItem)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "failed to create library item: %s", libraryItem.Name)
+	}
+
+	sessionID, err := cs.libMgr.CreateLibraryItemUpdateSession(ctx, library.Session{LibraryItemID: itemID})
+	if err != nil {
+		return itemID, "", errors.Wrapf(err, "failed to create update session for library item: %s", itemID)
+	}
+
+	info := library.UpdateFile{
+		Name:       path.Base(u.Path),
+		SourceType: "PULL",
+		Checksum:   checksum,
+		SourceEndpoint: &library.TransferEndpoint{
+			URI: fileURL,
+		},
+	}
+
+	if _, err := cs.libMgr.AddLibraryItemFile(ctx, sessionID, info); err != nil {
+		_ = cs.libMgr.FailLibraryItemUpdateSession(ctx, sessionID)
+		return itemID, sessionID, errors.Wrapf(err, "failed to add file to library item: %s", itemID)
+	}
+
+	return itemID, sessionID, nil
+}
+
+// GetLibraryItemUpdateSession returns the update session with the given ID.
+// Nil is returned if the session does not exist, ex. it expired.
+func (cs *provider) GetLibraryItemUpdateSession(ctx context.Context, sessionID string) (*library.Session, error) {
+	session, err := cs.libMgr.GetLibraryItemUpdateSession(ctx, sessionID)
+	if err != nil {
+		if lib.IsNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to get update session: %s", sessionID)
+	}
+
+	return session, nil
+}
+
+// GetLibraryItemUpdateSessionFile returns the transfer status of the file in
+// the update session.
+func (cs *provider) GetLibraryItemUpdateSessionFile(ctx context.Context,
+	sessionID, fileName string) (*library.UpdateFile, error) {
+	file, err := cs.libMgr.GetLibraryItemUpdateSessionFile(ctx, sessionID, fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get file %s of update session: %s", fileName, sessionID)
+	}
+
+	return file, nil
+}
+
+// KeepAliveLibraryItemUpdateSession keeps the update session from expiring
+// while its files are transferred.
+func (cs *provider) KeepAliveLibraryItemUpdateSession(ctx context.Context, sessionID string) error {
+	if err := cs.libMgr.KeepAliveLibraryItemUpdateSession(ctx, sessionID); err != nil {
+		return errors.Wrapf(err, "failed to keep alive update session: %s", sessionID)
+	}
+
+	return nil
+}
+
+// CompleteLibraryItemUpdateSession completes the update session, adding its
+// transferred files to the content library item.
+func (cs *provider) CompleteLibraryItemUpdateSession(ctx context.Context, sessionID string) error {
+	if err := cs.libMgr.CompleteLibraryItemUpdateSession(ctx, sessionID); err != nil {
+		return errors.Wrapf(err, "failed to complete update session: %s", sessionID)
+	}
+
+	return nil
+}
+
+// FailLibraryItemUpdateSession fails the update session, discarding its
+// transferred files.
+func (cs *provider) FailLibraryItemUpdateSession(ctx context.Context, sessionID string) error {
+	if err := cs.libMgr.FailLibraryItemUpdateSession(ctx, sessionID); err != nil {
+		return errors.Wrapf(err, "failed to fail update session: %s", sessionID)
+	}
+
+	return nil
+}
+
+// ListLibraryItemFiles returns the files of the content library item.
+func (cs *provider) ListLibraryItemFiles(ctx context.Context, itemID string) ([]library.File, error) {
+	files, err := cs.libMgr.ListLibraryItemFiles(ctx, itemID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list files of library item: %s", itemID)
+	}
+
+	return files, nil
+}
+
+// LibraryItemFileStorage describes where a file of a content library item is
+// stored.
+type LibraryItemFileStorage struct {
+	// Name is the name of the library item file.
+	Name string `json:"name"`
+	// StorageBacking is the storage backing of the library that stores the file.
+	StorageBacking library.StorageBackings `json:"storage_backing"`
+	// StorageURIs are the URIs of the file on the storage backing. The file
+	// name on the datastore is not the library item file name.
+	StorageURIs []string `json:"storage_uris,omitempty"`
+}
+
+// libraryItemStoragePath is the vAPI path of the library item storage service.
+const libraryItemStoragePath = "/com/vmware/content/library/item/storage"
+
+// ListLibraryItemStorage returns where each of the files of the content library
+// item is stored.
+func (cs *provider) ListLibraryItemStorage(ctx context.Context, itemID string) ([]LibraryItemFileStorage, error) {
+	url := cs.libMgr.Resource(libraryItemStoragePath).WithParam("library_item_id", itemID)
+
+	var storage []LibraryItemFileStorage
+	if err := cs.libMgr.Do(ctx, url.Request(http.MethodGet), &storage); err != nil {
+		return nil, errors.Wrapf(err, "failed to list storage of library item: %s", itemID)
+	}
+
+	return storage, nil
+}
+
+// GetLibraryItemDiskStorage returns where the disk files of the content
+// library item are stored, in the order the storage service returns them.
+func (cs *provider) GetLibraryItemDiskStorage(ctx context.Context, itemID string) ([]LibraryItemFileStorage, error) {
+	storage, err := cs.ListLibraryItemStorage(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	var disks []LibraryItemFileStorage
+	for _, fileStorage := range storage {
+		if !strings.EqualFold(path.Ext(fileStorage.Name), ".vmdk") {
+			continue
+		}
+		if fileStorage.StorageBacking.DatastoreID == "" || len(fileStorage.StorageURIs) == 0 {
+			return nil, errors.Errorf("disk %s of library item %s is not stored on a datastore", fileStorage.Name, itemID)
+		}
+		disks = append(disks, fileStorage)
+	}
+
+	return disks, nil
+}
+
+// DeleteLibraryItem deletes the content library item. It is not an error if
+// the item does not exist.
+func (cs *provider) DeleteLibraryItem(ctx context.Context, itemID string) error {
+	log.Info("Deleting Library Item", "itemID", itemID)
+
+	item, err := cs.libMgr.GetLibraryItem(ctx, itemID)
+	if err != nil {
+		if lib.IsNotFoundError(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to get library item: %s", itemID)
+	}
+
+	return cs.libMgr.DeleteLibraryItem(ctx, item)
+}
+
 // Only used in testing.
 func (cs *provider) CreateLibraryItem(ctx context.Context, libraryItem library.Item, path string) error {
 	log.Info("Creating Library Item", "item", libraryItem, "path", path)
@@ -4,19 +4,60 @@
 package contentlibrary_test
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	"github.com/google/uuid"
+	"github.com/vmware/govmomi/simulator"
 	"github.com/vmware/govmomi/vapi/library"
+	"github.com/vmware/govmomi/vapi/rest"
+	vapisimulator "github.com/vmware/govmomi/vapi/simulator"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere2/contentlibrary"
 	"github.com/vmware-tanzu/vm-operator/test/builder"
 )
 
+// storageFileSuffixRegex matches the suffix vCenter adds to the names of the
+// files of a library item on its datastore.
+var storageFileSuffixRegex = regexp.MustCompile(`_[0-9a-f-]{36}(\.[^.]+)$`)
+
+func init() {
+	// vC Sim does not implement the library item storage service, so list the
+	// files in the item's directory on the datastore like vCenter would.
+	simulator.RegisterEndpoint(func(s *simulator.Service, r *simulator.Registry) {
+		s.HandleFunc(rest.Path+"/com/vmware/content/library/item/storage", func(w http.ResponseWriter, req *http.Request) {
+			itemID := req.URL.Query().Get("library_item_id")
+
+			var storage []contentlibrary.LibraryItemFileStorage
+			for _, e := range r.All("Datastore") {
+				ds := e.(*simulator.Datastore)
+				dsURL := ds.Summary.Url
+				files, _ := filepath.Glob(filepath.Join(dsURL, "contentlib-*", itemID, "*"))
+				for _, file := range files {
+					storage = append(storage, contentlibrary.LibraryItemFileStorage{
+						Name: storageFileSuffixRegex.ReplaceAllString(filepath.Base(file), "$1"),
+						StorageBacking: library.StorageBackings{
+							DatastoreID: ds.Self.Value,
+							Type:        "DATASTORE",
+						},
+						StorageURIs: []string{file},
+					})
+				}
+			}
+
+			vapisimulator.OK(w, storage)
+		})
+	})
+}
+
 func clTests() {
 	Describe("Content Library", func() {
 
@@ -135,5 +176,113 @@ func clTests() {
 				Expect(ovfEnvelope).To(BeNil())
 			})
 		})
+
+		Context("when an item is imported from a URL", func() {
+			var server *httptest.Server
+
+			BeforeEach(func() {
+				server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					_, _ = w.Write([]byte("<Envelope></Envelope>"))
+				}))
+			})
+
+			AfterEach(func() {
+				server.Close()
+			})
+
+			It("pulls the file into the item", func() {
+				libItem := library.Item{
+					Name:      "imported-item",
+					Type:      library.ItemTypeOVF,
+					LibraryID: ctx.ContentLibraryID,
+				}
+
+				itemID, sessionID, err := clProvider.ImportLibraryItemFromURL(ctx, libItem, server.URL+"/images/imported.ovf", nil)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(itemID).ToNot(BeEmpty())
+				Expect(sessionID).ToNot(BeEmpty())
+
+				session, err := clProvider.GetLibraryItemUpdateSession(ctx, sessionID)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(session).ToNot(BeNil())
+				Expect(session.LibraryItemID).To(Equal(itemID))
+
+				Eventually(func() []library.File {
+					files, err := clProvider.ListLibraryItemFiles(ctx, itemID)
+					Expect(err).ToNot(HaveOccurred())
+					return files
+				}).Should(ContainElement(HaveField("Name", "imported.ovf")))
+
+				Expect(clProvider.DeleteLibraryItem(ctx, itemID)).To(Succeed())
+				item, err := clProvider.GetLibraryItem(ctx, ctx.ContentLibraryID, libItem.Name, false)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(item).To(BeNil())
+
+				By("does not return an error when the item is already deleted")
+				Expect(clProvider.DeleteLibraryItem(ctx, itemID)).To(Succeed())
+			})
+
+			It("returns the storage of the item's disk files", func() {
+				libItem := library.Item{
+					Name:      "imported-disk-item",
+					Type:      library.ItemTypeOVF,
+					LibraryID: ctx.ContentLibraryID,
+				}
+
+				itemID, _, err := clProvider.ImportLibraryItemFromURL(ctx, libItem, server.URL+"/images/disk-0.vmdk", nil)
+				Expect(err).ToNot(HaveOccurred())
+
+				Eventually(func() []library.File {
+					files, err := clProvider.ListLibraryItemFiles(ctx, itemID)
+					Expect(err).ToNot(HaveOccurred())
+					return files
+				}).Should(ContainElement(HaveField("Name", "disk-0.vmdk")))
+
+				disks, err := clProvider.GetLibraryItemDiskStorage(ctx, itemID)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(disks).To(HaveLen(1))
+				Expect(disks[0].StorageURIs).To(HaveLen(1))
+
+				By("renaming the disk file on the datastore like vCenter does")
+				storedFile := strings.TrimSuffix(disks[0].StorageURIs[0], ".vmdk") + "_" + uuid.NewString() + ".vmdk"
+				Expect(os.Rename(disks[0].StorageURIs[0], storedFile)).To(Succeed())
+
+				disks, err = clProvider.GetLibraryItemDiskStorage(ctx, itemID)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(disks).To(HaveLen(1))
+				Expect(disks[0].Name).To(Equal("disk-0.vmdk"))
+				Expect(disks[0].StorageBacking.DatastoreID).ToNot(BeEmpty())
+				Expect(disks[0].StorageURIs).To(ConsistOf(storedFile))
+
+				Expect(clProvider.DeleteLibraryItem(ctx, itemID)).To(Succeed())
+			})
+
+			It("leaves accessing the URL to the content library service", func() {
+				// Nothing listens on the URL.
+				serverURL := server.URL
+				server.Close()
+
+				libItem := library.Item{
+					Name:      "imported-item",
+					Type:      library.ItemTypeOVF,
+					LibraryID: ctx.ContentLibraryID,
+				}
+
+				itemID, sessionID, err := clProvider.ImportLibraryItemFromURL(ctx, libItem, serverURL+"/images/imported.ovf", nil)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(itemID).ToNot(BeEmpty())
+				Expect(sessionID).ToNot(BeEmpty())
+
+				Expect(clProvider.DeleteLibraryItem(ctx, itemID)).To(Succeed())
+			})
+		})
+
+		Context("when the update session does not exist", func() {
+			It("returns nil", func() {
+				session, err := clProvider.GetLibraryItemUpdateSession(ctx, "dummy-session")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(session).To(BeNil())
+			})
+		})
 	})
 }
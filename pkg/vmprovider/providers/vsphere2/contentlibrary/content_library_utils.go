@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/vmware/govmomi/ovf"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
@@ -17,6 +18,10 @@ import (
 
 var vmxRe = regexp.MustCompile(`vmx-(\d+)`)
 
+// ovfDiskDriveResourceType is the CIM resource type of a disk drive in the
+// virtual hardware of an OVF.
+const ovfDiskDriveResourceType = 17
+
 // ParseVirtualHardwareVersion parses the virtual hardware version
 // For eg. "vmx-15" returns 15.
 func ParseVirtualHardwareVersion(vmxVersion string) int32 {
@@ -50,6 +55,102 @@ func UpdateVmiWithOvfEnvelope(vmi client.Object, ovfEnvelope ovf.Envelope) {
 	if ovfEnvelope.VirtualSystem != nil {
 		initImageStatusFromOVFVirtualSystem(status, ovfEnvelope.VirtualSystem)
 	}
+
+	if ovfEnvelope.Disk != nil {
+		initImageStatusFromOVFDiskSection(status, ovfEnvelope.Disk, ovfEnvelope.VirtualSystem)
+	}
+}
+
+func initImageStatusFromOVFDiskSection(
+	imageStatus *vmopv1.VirtualMachineImageStatus,
+	ovfDiskSection *ovf.DiskSection,
+	ovfVirtualSystem *ovf.VirtualSystem) {
+
+	bootDiskID := getOVFBootDiskID(ovfVirtualSystem)
+	if bootDiskID == "" && len(ovfDiskSection.Disks) > 0 {
+		bootDiskID = ovfDiskSection.Disks[0].DiskID
+	}
+
+	imageStatus.Disks = nil
+	for i := range ovfDiskSection.Disks {
+		disk := &ovfDiskSection.Disks[i]
+
+		diskInfo := vmopv1.VirtualMachineImageDiskInfo{
+			Boot: disk.DiskID == bootDiskID,
+		}
+		if capacity := getOVFDiskCapacity(disk); capacity > 0 {
+			diskInfo.Capacity = resource.NewQuantity(capacity, resource.BinarySI)
+		}
+		if disk.PopulatedSize != nil {
+			diskInfo.Size = resource.NewQuantity(int64(*disk.PopulatedSize), resource.BinarySI)
+		}
+
+		imageStatus.Disks = append(imageStatus.Disks, diskInfo)
+	}
+}
+
+// getOVFBootDiskID returns the ID of the disk that is the boot disk of a VM
+// deployed from the OVF, which is the disk of the first disk drive in the OVF's
+// virtual hardware since that is the VM's first disk. An empty string is
+// returned if the virtual hardware does not have a disk drive.
+func getOVFBootDiskID(ovfVirtualSystem *ovf.VirtualSystem) string {
+	if ovfVirtualSystem == nil || len(ovfVirtualSystem.VirtualHardware) == 0 {
+		return ""
+	}
+
+	for _, item := range ovfVirtualSystem.VirtualHardware[0].Item {
+		if item.ResourceType == nil || *item.ResourceType != ovfDiskDriveResourceType {
+			continue
+		}
+		for _, hostResource := range item.HostResource {
+			// The disk is referenced as "ovf:/disk/<id>", or "/disk/<id>" by
+			// older OVFs.
+			if diskID := strings.TrimPrefix(strings.TrimPrefix(hostResource, "ovf:"), "/disk/"); diskID != hostResource {
+				return diskID
+			}
+		}
+	}
+
+	return ""
+}
+
+// getOVFDiskCapacity returns the capacity of the OVF disk in bytes. The capacity
+// is in the disk's allocation units, for example "byte * 2^30", and defaults to
+// bytes.
+func getOVFDiskCapacity(disk *ovf.VirtualDiskDesc) int64 {
+	capacity, err := strconv.ParseInt(disk.Capacity, 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	if disk.CapacityAllocationUnits == nil {
+		return capacity
+	}
+
+	units := strings.Fields(*disk.CapacityAllocationUnits)
+	if len(units) != 3 || units[0] != "byte" || units[1] != "*" {
+		return capacity
+	}
+
+	baseAndExp := strings.Split(units[2], "^")
+	base, err := strconv.ParseInt(baseAndExp[0], 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	multiplier := base
+	if len(baseAndExp) == 2 {
+		exp, err := strconv.Atoi(baseAndExp[1])
+		if err != nil {
+			return 0
+		}
+		multiplier = 1
+		for i := 0; i < exp; i++ {
+			multiplier *= base
+		}
+	}
+
+	return capacity * multiplier
 }
 
 func initImageStatusFromOVFVirtualSystem(
@@ -146,4 +146,71 @@ var _ = Describe("UpdateVmiWithOvfEnvelope", func() {
 		Expect(image.Status.VMwareSystemProperties[0].Key).Should(Equal(versionKey))
 		Expect(image.Status.VMwareSystemProperties[0].Value).Should(Equal(versionVal))
 	})
+
+	When("the OVF has disks", func() {
+		BeforeEach(func() {
+			ovfEnvelope.Disk = &ovf.DiskSection{
+				Disks: []ovf.VirtualDiskDesc{
+					{
+						DiskID:                  "vmdisk1",
+						Capacity:                "10",
+						CapacityAllocationUnits: pointer.String("byte * 2^30"),
+						PopulatedSize:           pointer.Int(1024),
+					},
+					{
+						DiskID:   "vmdisk2",
+						Capacity: "1048576",
+					},
+				},
+			}
+		})
+
+		It("Image status should have the disks' capacity and size", func() {
+			Expect(image.Status.Disks).To(HaveLen(2))
+			Expect(image.Status.Disks[0].Capacity).ToNot(BeNil())
+			Expect(image.Status.Disks[0].Capacity.Value()).To(BeEquivalentTo(10 * 1024 * 1024 * 1024))
+			Expect(image.Status.Disks[0].Size).ToNot(BeNil())
+			Expect(image.Status.Disks[0].Size.Value()).To(BeEquivalentTo(1024))
+			Expect(image.Status.Disks[1].Capacity).ToNot(BeNil())
+			Expect(image.Status.Disks[1].Capacity.Value()).To(BeEquivalentTo(1048576))
+			Expect(image.Status.Disks[1].Size).To(BeNil())
+		})
+
+		It("Image status should mark the first disk as the boot disk", func() {
+			Expect(image.Status.Disks).To(HaveLen(2))
+			Expect(image.Status.Disks[0].Boot).To(BeTrue())
+			Expect(image.Status.Disks[1].Boot).To(BeFalse())
+		})
+
+		When("the virtual hardware has disk drives", func() {
+			BeforeEach(func() {
+				resourceType := func(t uint16) *uint16 { return &t }
+				ovfEnvelope.VirtualSystem.VirtualHardware[0].Item = []ovf.ResourceAllocationSettingData{
+					{
+						CIMResourceAllocationSettingData: ovf.CIMResourceAllocationSettingData{
+							ResourceType: resourceType(6),
+						},
+					},
+					{
+						CIMResourceAllocationSettingData: ovf.CIMResourceAllocationSettingData{
+							ResourceType: resourceType(17),
+							HostResource: []string{"ovf:/disk/vmdisk2"},
+						},
+					},
+					{
+						CIMResourceAllocationSettingData: ovf.CIMResourceAllocationSettingData{
+							ResourceType: resourceType(17),
+							HostResource: []string{"ovf:/disk/vmdisk1"},
+						},
+					},
+				}
+			})
+
+			It("Image status should mark the disk of the first disk drive as the boot disk", func() {
+				Expect(image.Status.Disks).To(HaveLen(2))
+				Expect(image.Status.Disks[0].Boot).To(BeFalse())
+				Expect(image.Status.Disks[1].Boot).To(BeTrue())
+			})
+		})
+	})
 })
@@ -17,6 +17,7 @@ import (
 	vimtypes "github.com/vmware/govmomi/vim25/types"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -141,15 +142,80 @@ func CreateAndWaitForNetworkInterfaces(
 		results = append(results, *result)
 	}
 
-	// TODO: Once we really support network changing on the fly, we need to keep track of now
-	// unused network interface CRDs so they can be deleted after they're removed from the VM
-	// via Reconfigure, instead of delaying that until the VM is deleted via GC.
-
 	return NetworkInterfaceResults{
 		Results: results,
 	}, nil
 }
 
+// DeleteUnusedNetworkInterfaces deletes the VM's NetOP or NCP network interface CRs
+// that do not belong to any of the interfaces. This is used after interfaces are
+// hot-removed from the VM via Reconfigure, instead of leaving the CRs, and the IPs
+// they hold, around until the VM is deleted.
+func DeleteUnusedNetworkInterfaces(
+	vmCtx context.VirtualMachineContextA2,
+	client ctrlruntime.Client,
+	interfaces []vmopv1.VirtualMachineNetworkInterfaceSpec) error {
+
+	var list ctrlruntime.ObjectList
+	var crName func(vmName, networkName, interfaceName string, isV1A1 bool) string
+
+	switch lib.GetNetworkProviderType() {
+	case lib.NetworkProviderTypeVDS:
+		list, crName = &netopv1alpha1.NetworkInterfaceList{}, NetOPCRName
+	case lib.NetworkProviderTypeNSXT:
+		list, crName = &ncpv1alpha1.VirtualNetworkInterfaceList{}, NCPCRName
+	default:
+		// Named networks do not have any CRs.
+		return nil
+	}
+
+	// Keep the CRs with either naming convention since an interface may still
+	// be using its v1a1 named CR.
+	inUse := map[string]struct{}{}
+	for i := range interfaces {
+		networkName := interfaces[i].Network.Name
+		inUse[crName(vmCtx.VM.Name, networkName, interfaces[i].Name, true)] = struct{}{}
+		inUse[crName(vmCtx.VM.Name, networkName, interfaces[i].Name, false)] = struct{}{}
+	}
+
+	if err := client.List(vmCtx, list, ctrlruntime.InNamespace(vmCtx.VM.Namespace)); err != nil {
+		return err
+	}
+
+	objs, err := meta.ExtractList(list)
+	if err != nil {
+		return err
+	}
+
+	for _, o := range objs {
+		obj, ok := o.(ctrlruntime.Object)
+		if !ok || !isOwnedBy(obj, vmCtx.VM) {
+			continue
+		}
+		if _, ok := inUse[obj.GetName()]; ok {
+			continue
+		}
+
+		vmCtx.Logger.Info("Deleting unused network interface", "name", obj.GetName())
+		if err := client.Delete(vmCtx, obj); ctrlruntime.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete network interface %q: %w", obj.GetName(), err)
+		}
+	}
+
+	return nil
+}
+
+// isOwnedBy returns true if the owner is one of the object's owners. The CRs are
+// created with a non-controller owner reference to the VM.
+func isOwnedBy(obj metav1.Object, owner metav1.Object) bool {
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.UID == owner.GetUID() && ref.Name == owner.GetName() {
+			return true
+		}
+	}
+	return false
+}
+
 // applyInterfaceSpecToResult applies the InterfaceSpec to results. Much of the InterfaceSpec - like DHCP -
 // cannot be specified to the underlying network provider so apply those overrides to the results.
 func applyInterfaceSpecToResult(
@@ -12,6 +12,7 @@ import (
 
 	"github.com/vmware/govmomi/vim25/types"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -294,6 +295,37 @@ var _ = Describe("CreateAndWaitForNetworkInterfaces", func() {
 				Expect(ipConfig.Gateway).To(Equal("fd1a:6c85:79fe:7c98:0000:0000:0000:0001"))
 			})
 
+			It("deletes the network interfaces that are no longer in the spec", func() {
+				netIfKey := client.ObjectKey{
+					Namespace: vm.Namespace,
+					Name:      network.NetOPCRName(vm.Name, networkName, interfaceName, false),
+				}
+				Expect(ctx.Client.Get(ctx, netIfKey, &netopv1alpha1.NetworkInterface{})).To(Succeed())
+
+				otherNetIf := &netopv1alpha1.NetworkInterface{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "other-vm-eth0",
+						Namespace: vm.Namespace,
+					},
+				}
+				Expect(ctx.Client.Create(ctx, otherNetIf)).To(Succeed())
+
+				By("keeps the network interfaces in the spec", func() {
+					Expect(network.DeleteUnusedNetworkInterfaces(vmCtx, ctx.Client, interfaceSpecs)).To(Succeed())
+					Expect(ctx.Client.Get(ctx, netIfKey, &netopv1alpha1.NetworkInterface{})).To(Succeed())
+				})
+
+				By("deletes the network interface removed from the spec", func() {
+					Expect(network.DeleteUnusedNetworkInterfaces(vmCtx, ctx.Client, nil)).To(Succeed())
+					err := ctx.Client.Get(ctx, netIfKey, &netopv1alpha1.NetworkInterface{})
+					Expect(apierrors.IsNotFound(err)).To(BeTrue())
+				})
+
+				By("does not delete the network interfaces of other VMs", func() {
+					Expect(ctx.Client.Get(ctx, client.ObjectKeyFromObject(otherNetIf), otherNetIf)).To(Succeed())
+				})
+			})
+
 			When("v1a1 network interface exists", func() {
 				BeforeEach(func() {
 					netIf := &netopv1alpha1.NetworkInterface{
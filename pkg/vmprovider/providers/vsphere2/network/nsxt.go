@@ -66,6 +66,85 @@ func ResolveBackingPostPlacement(
 	return fixedUp, nil
 }
 
+// ResolveBackingForCluster returns the backing of the network interface in the CCR. This is
+// used when a VM is migrated to another CCR, where the network the interface is backed by is
+// not necessarily available: for NSX-T the DVPG is looked up by the interface's network ID,
+// and otherwise the CCR network with the same name as the current backing is used.
+func ResolveBackingForCluster(
+	ctx goctx.Context,
+	vimClient *vim25.Client,
+	clusterMoRef vimtypes.ManagedObjectReference,
+	result *NetworkInterfaceResult) (object.NetworkReference, error) {
+
+	ccr := object.NewClusterComputeResource(vimClient, clusterMoRef)
+
+	switch networkType := lib.GetNetworkProviderType(); networkType {
+	case lib.NetworkProviderTypeNSXT:
+		return searchNsxtNetworkReference(ctx, ccr, result.NetworkID)
+	case lib.NetworkProviderTypeVDS, lib.NetworkProviderTypeNamed:
+		if result.Backing == nil {
+			return nil, fmt.Errorf("network interface %q does not have a backing", result.Name)
+		}
+		return searchClusterNetworkReference(ctx, ccr, result.Backing)
+	default:
+		return nil, fmt.Errorf("unsupported network provider envvar value: %q", networkType)
+	}
+}
+
+// searchClusterNetworkReference returns the backing if it is available in the CCR, and
+// otherwise the CCR network with the same name as the backing.
+func searchClusterNetworkReference(
+	ctx goctx.Context,
+	ccr *object.ClusterComputeResource,
+	backing object.NetworkReference) (object.NetworkReference, error) {
+
+	var obj mo.ClusterComputeResource
+	if err := ccr.Properties(ctx, ccr.Reference(), []string{"network"}, &obj); err != nil {
+		return nil, err
+	}
+
+	for _, n := range obj.Network {
+		if n == backing.Reference() {
+			return backing, nil
+		}
+	}
+
+	pc := property.DefaultCollector(ccr.Client())
+
+	var network mo.Network
+	if err := pc.RetrieveOne(ctx, backing.Reference(), []string{"name"}, &network); err != nil {
+		return nil, err
+	}
+
+	var networks []mo.Network
+	if len(obj.Network) > 0 {
+		if err := pc.Retrieve(ctx, obj.Network, []string{"name"}, &networks); err != nil {
+			return nil, err
+		}
+	}
+
+	var networkRefs []vimtypes.ManagedObjectReference
+	for _, n := range networks {
+		if n.Name == network.Name {
+			networkRefs = append(networkRefs, n.Reference())
+		}
+	}
+
+	switch len(networkRefs) {
+	case 1:
+		if ref, ok := object.NewReference(ccr.Client(), networkRefs[0]).(object.NetworkReference); ok {
+			return ref, nil
+		}
+		return nil, fmt.Errorf("network %q is not a supported backing", network.Name)
+	case 0:
+		return nil, fmt.Errorf("network %q is not available in ClusterComputeResource %s",
+			network.Name, ccr.Reference().Value)
+	default:
+		return nil, fmt.Errorf("multiple networks (%d) named %q found in ClusterComputeResource %s",
+			len(networkRefs), network.Name, ccr.Reference().Value)
+	}
+}
+
 // searchNsxtNetworkReference takes in NSX-T LogicalSwitchUUID and returns the reference of the network.
 func searchNsxtNetworkReference(
 	ctx goctx.Context,
@@ -7,6 +7,8 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	"github.com/vmware/govmomi/object"
+
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere2/network"
 	"github.com/vmware-tanzu/vm-operator/test/builder"
 )
@@ -69,3 +71,66 @@ var _ = Describe("ResolveBackingPostPlacement", func() {
 		})
 	})
 })
+
+var _ = Describe("ResolveBackingForCluster", func() {
+
+	var (
+		testConfig builder.VCSimTestConfig
+		ctx        *builder.TestContextForVCSim
+
+		result  *network.NetworkInterfaceResult
+		backing object.NetworkReference
+		err     error
+	)
+
+	BeforeEach(func() {
+		testConfig = builder.VCSimTestConfig{WithV1A2: true}
+	})
+
+	JustBeforeEach(func() {
+		ctx = suite.NewTestContextForVCSim(testConfig)
+
+		if result.Backing == nil && result.NetworkID == "" {
+			result.Backing = ctx.NetworkRef
+		}
+
+		backing, err = network.ResolveBackingForCluster(
+			ctx,
+			ctx.VCClient.Client,
+			ctx.GetSingleClusterCompute().Reference(),
+			result)
+	})
+
+	AfterEach(func() {
+		ctx.AfterEach()
+		ctx = nil
+	})
+
+	Context("NSX-T", func() {
+		BeforeEach(func() {
+			testConfig.WithNetworkEnv = builder.NetworkEnvNSXT
+			result = &network.NetworkInterfaceResult{
+				NetworkID: builder.NsxTLogicalSwitchUUID,
+			}
+		})
+
+		It("returns the DVPG of the network ID", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(backing).ToNot(BeNil())
+			Expect(backing.Reference()).To(Equal(ctx.NetworkRef.Reference()))
+		})
+	})
+
+	Context("VDS", func() {
+		BeforeEach(func() {
+			testConfig.WithNetworkEnv = builder.NetworkEnvVDS
+			result = &network.NetworkInterfaceResult{}
+		})
+
+		It("returns the backing available in the cluster", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(backing).ToNot(BeNil())
+			Expect(backing.Reference()).To(Equal(ctx.NetworkRef.Reference()))
+		})
+	})
+})
@@ -31,13 +31,19 @@ type Result struct {
 	// TODO: Datastore, whatever else as we need it.
 }
 
-func doesVMNeedPlacement(vmCtx context.VirtualMachineContextA2) (res Result, needZonePlacement, needInstanceStoragePlacement bool) {
+func doesVMNeedPlacement(vmCtx context.VirtualMachineContextA2) (res Result, needZonePlacement, needZoneMigration, needInstanceStoragePlacement bool) {
 	if lib.IsWcpFaultDomainsFSSEnabled() {
 		res.ZonePlacement = true
 
 		if zoneName := vmCtx.VM.Labels[topology.KubernetesTopologyZoneLabelKey]; zoneName != "" {
 			// Zone has already been selected.
 			res.ZoneName = zoneName
+
+			if zone := vmCtx.VM.Status.Zone; zone != "" && zone != zoneName {
+				// The zone was changed after the VM was created so the VM is being
+				// migrated to the new zone and needs to be placed within it.
+				needZoneMigration = true
+			}
 		} else {
 			// VM does not have a zone already assigned so we need to select one.
 			needZonePlacement = true
@@ -283,7 +289,8 @@ func MakePlacementDecision(recommendations map[string][]Recommendation) (string,
 }
 
 // Placement determines if the VM needs placement, and if so, determines where to place the VM
-// and updates the Labels and Annotations with the placement decision.
+// and updates the Labels and Annotations with the placement decision. A VM whose zone label no
+// longer matches its status.zone needs placement within the new zone it is being migrated to.
 func Placement(
 	vmCtx context.VirtualMachineContextA2,
 	client ctrlclient.Client,
@@ -291,8 +298,8 @@ func Placement(
 	configSpec *types.VirtualMachineConfigSpec,
 	childRPName string) (*Result, error) {
 
-	existingRes, zonePlacement, instanceStoragePlacement := doesVMNeedPlacement(vmCtx)
-	if !zonePlacement && !instanceStoragePlacement {
+	existingRes, zonePlacement, zoneMigration, instanceStoragePlacement := doesVMNeedPlacement(vmCtx)
+	if !zonePlacement && !zoneMigration && !instanceStoragePlacement {
 		return &existingRes, nil
 	}
 
@@ -309,7 +316,7 @@ func Placement(
 	needsHost := instanceStoragePlacement
 
 	var recommendations map[string][]Recommendation
-	if zonePlacement {
+	if zonePlacement || zoneMigration {
 		recommendations = getZonalPlacementRecommendations(vmCtx, vcClient, candidates, configSpec, needsHost)
 	} else /* instanceStoragePlacement */ {
 		recommendations = getPlacementRecommendations(vmCtx, vcClient, candidates, configSpec)
@@ -322,7 +329,7 @@ func Placement(
 	vmCtx.Logger.V(5).Info("Placement decision result", "zone", zoneName, "recommendation", rec)
 
 	result := &Result{
-		ZonePlacement:            zonePlacement,
+		ZonePlacement:            zonePlacement || zoneMigration,
 		InstanceStoragePlacement: instanceStoragePlacement,
 		ZoneName:                 zoneName,
 		PoolMoRef:                rec.PoolMoRef,
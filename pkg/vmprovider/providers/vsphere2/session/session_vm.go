@@ -1,23 +1,44 @@
-// Copyright (c) 2018-2022 VMware, Inc. All Rights Reserved.
+// Copyright (c) 2018-2023 VMware, Inc. All Rights Reserved.
 // SPDX-License-Identifier: Apache-2.0
 
 package session
 
 import (
+	"errors"
 	"fmt"
+	"path"
+	"strconv"
 
 	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/soap"
 	vimTypes "github.com/vmware/govmomi/vim25/types"
+	"k8s.io/apimachinery/pkg/api/resource"
 
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	conditions "github.com/vmware-tanzu/vm-operator/pkg/conditions2"
 	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/util"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere2/constants"
+	res "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere2/resources"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere2/virtualmachine"
 )
 
+// updateVirtualDiskDeviceChanges returns the device change that extends the
+// boot disk to the VM's boot disk capacity, if it is larger than the disk. A
+// capacity less than that of the disk is ignored and reported in the VM's
+// VirtualMachineBootDiskResized condition.
 func updateVirtualDiskDeviceChanges(
 	vmCtx context.VirtualMachineContextA2,
 	virtualDisks object.VirtualDeviceList) ([]vimTypes.BaseVirtualDeviceConfigSpec, error) {
 
-	capacity := vmCtx.VM.Spec.Advanced.BootDiskCapacity
+	var capacity *resource.Quantity
+	if vmCtx.VM.Spec.Advanced != nil {
+		capacity = vmCtx.VM.Spec.Advanced.BootDiskCapacity
+	}
 	if capacity == nil || capacity.IsZero() {
+		conditions.Delete(vmCtx.VM, vmopv1.VirtualMachineConditionBootDiskResized)
 		return nil, nil
 	}
 
@@ -33,19 +54,23 @@ func updateVirtualDiskDeviceChanges(
 		// looking at the disk path or whatever else later.
 		// TODO: De-dupe this with resizeBootDiskDeviceChange() in the clone path.
 
+		// The disk may have been extended outside of VM Service. Disks cannot
+		// be shrunk so just report the capacity is ignored instead of failing
+		// every reconcile.
 		newCapacityInBytes := capacity.Value()
-		if newCapacityInBytes < vmDisk.CapacityInBytes {
-			err := fmt.Errorf("cannot shrink boot disk from %d bytes to %d bytes",
-				vmDisk.CapacityInBytes, newCapacityInBytes)
-			return nil, err
-		}
-
-		if vmDisk.CapacityInBytes < newCapacityInBytes {
+		switch {
+		case newCapacityInBytes < vmDisk.CapacityInBytes:
+			conditions.MarkFalse(vmCtx.VM, vmopv1.VirtualMachineConditionBootDiskResized,
+				vmopv1.VirtualMachineBootDiskShrinkNotSupportedReason,
+				"Cannot shrink boot disk from %d bytes to %d bytes", vmDisk.CapacityInBytes, newCapacityInBytes)
+		case newCapacityInBytes > vmDisk.CapacityInBytes:
 			vmDisk.CapacityInBytes = newCapacityInBytes
 			deviceChanges = append(deviceChanges, &vimTypes.VirtualDeviceConfigSpec{
 				Operation: vimTypes.VirtualDeviceConfigSpecOperationEdit,
 				Device:    vmDisk,
 			})
+		default:
+			conditions.MarkTrue(vmCtx.VM, vmopv1.VirtualMachineConditionBootDiskResized)
 		}
 
 		found = true
@@ -58,3 +83,336 @@ func updateVirtualDiskDeviceChanges(
 
 	return deviceChanges, nil
 }
+
+// updateConfigSpecBootDiskCapacity adds the boot disk device changes to the
+// ConfigSpec, along with the ExtraConfig hint of the new capacity for the
+// guest.
+func updateConfigSpecBootDiskCapacity(
+	vmCtx context.VirtualMachineContextA2,
+	configSpec *vimTypes.VirtualMachineConfigSpec,
+	virtualDisks object.VirtualDeviceList) error {
+
+	deviceChanges, err := updateVirtualDiskDeviceChanges(vmCtx, virtualDisks)
+	if err != nil {
+		return err
+	}
+
+	if len(deviceChanges) == 0 {
+		return nil
+	}
+
+	configSpec.DeviceChange = append(configSpec.DeviceChange, deviceChanges...)
+	configSpec.ExtraConfig = append(configSpec.ExtraConfig, &vimTypes.OptionValue{
+		Key:   constants.BootDiskCapacityExtraConfigKey,
+		Value: strconv.FormatInt(vmCtx.VM.Spec.Advanced.BootDiskCapacity.Value(), 10),
+	})
+
+	return nil
+}
+
+// updateConfigSpecVolumeDisks adds the disks of the VM's ephemeral and image
+// volumes that have not yet been added to the VM. Each disk is placed on a
+// datastore compatible with the storage policy of the volume's storage class.
+// The image disk of an image volume is copied to the VM's directory on that
+// datastore and the copy is added once the copy has completed. Each disk is assigned to a disk controller
+// so the ExtraConfig can record which disk backs the volume. The ExtraConfig
+// entry also ensures the disk is only ever added once. The disk is assigned a
+// unit that is free in devices, which are the devices the VM has after the
+// other changes in the ConfigSpec, and is appended to them.
+func (s *Session) updateConfigSpecVolumeDisks(
+	vmCtx context.VirtualMachineContextA2,
+	resVM *res.VirtualMachine,
+	configSpec *vimTypes.VirtualMachineConfigSpec,
+	config *vimTypes.VirtualMachineConfigInfo,
+	desiredConfigSpec *vimTypes.VirtualMachineConfigSpec,
+	devices *object.VirtualDeviceList) error {
+
+	volumes := virtualmachine.FilterVolumeDiskVolumes(vmCtx.VM)
+	if len(volumes) == 0 || desiredConfigSpec == nil {
+		return nil
+	}
+
+	desiredDisks := map[int32]*vimTypes.VirtualDeviceConfigSpec{}
+	for _, devChange := range desiredConfigSpec.DeviceChange {
+		dSpec := devChange.GetVirtualDeviceConfigSpec()
+		if virtualmachine.IsVolumeDiskDevice(dSpec.Device) {
+			desiredDisks[dSpec.Device.GetVirtualDevice().Key] = dSpec
+		}
+	}
+
+	ecMap := util.ExtraConfigToMap(config.ExtraConfig)
+
+	for idx, volume := range volumes {
+		ecKey := virtualmachine.VolumeDiskExtraConfigKey(volume.Name)
+		if _, ok := ecMap[ecKey]; ok {
+			continue
+		}
+
+		dSpec, ok := desiredDisks[virtualmachine.VolumeDiskDeviceKey(idx)]
+		if !ok {
+			continue
+		}
+
+		controller, err := findVolumeDiskController(*devices)
+		if err != nil {
+			return fmt.Errorf("failed to find a disk controller for volume %s: %w", volume.Name, err)
+		}
+
+		disk := *dSpec.Device.(*vimTypes.VirtualDisk)
+		backing := *disk.Backing.(*vimTypes.VirtualDiskFlatVer2BackingInfo)
+		if volume.Image != nil {
+			diskPath, err := s.copyImageVolumeDisk(vmCtx, resVM, config, volume, dSpec, backing.FileName)
+			if err != nil {
+				return fmt.Errorf("failed to copy the image disk of volume %s: %w", volume.Name, err)
+			}
+			if diskPath == "" {
+				// The disk is added once its copy has completed.
+				continue
+			}
+			backing.FileName = diskPath
+		} else {
+			datastoreName, err := s.getVolumeDiskDatastoreName(vmCtx, resVM, config, volume, dSpec)
+			if err != nil {
+				return fmt.Errorf("failed to get the datastore of volume %s: %w", volume.Name, err)
+			}
+			// The disk is created in the VM's directory on the datastore.
+			backing.FileName = (&object.DatastorePath{Datastore: datastoreName}).String()
+		}
+		disk.Backing = &backing
+		devices.AssignController(&disk, controller)
+		*devices = append(*devices, &disk)
+
+		deviceChange := *dSpec
+		deviceChange.Device = &disk
+		configSpec.DeviceChange = append(configSpec.DeviceChange, &deviceChange)
+		configSpec.ExtraConfig = append(configSpec.ExtraConfig, &vimTypes.OptionValue{
+			Key:   ecKey,
+			Value: virtualmachine.VolumeDiskExtraConfigValue(&disk),
+		})
+	}
+
+	return nil
+}
+
+// getVolumeDiskDatastoreName returns the name of the datastore the disk of
+// the volume is placed on. The datastore is selected out of the datastores of
+// the VM's host the same way as when the VM's storage is relocated, so the VM's
+// home datastore is used if it is compatible with the storage policy of the
+// disk. The VM's home datastore is also used if the disk does not have a
+// storage policy.
+func (s *Session) getVolumeDiskDatastoreName(
+	vmCtx context.VirtualMachineContextA2,
+	resVM *res.VirtualMachine,
+	config *vimTypes.VirtualMachineConfigInfo,
+	volume vmopv1.VirtualMachineVolume,
+	dSpec *vimTypes.VirtualDeviceConfigSpec) (string, error) {
+
+	var policyID string
+	for _, profile := range dSpec.Profile {
+		if p, ok := profile.(*vimTypes.VirtualMachineDefinedProfileSpec); ok {
+			policyID = p.ProfileId
+		}
+	}
+
+	moVM := &mo.VirtualMachine{Config: config}
+	if policyID == "" {
+		return getHomeDatastoreName(moVM), nil
+	}
+
+	var storageClass string
+	switch {
+	case volume.Ephemeral != nil:
+		storageClass = volume.Ephemeral.StorageClass
+	case volume.Image != nil:
+		storageClass = volume.Image.StorageClass
+		if storageClass == "" {
+			storageClass = vmCtx.VM.Spec.StorageClass
+		}
+	}
+
+	runtimeVM, err := resVM.GetProperties(vmCtx, []string{"runtime.host"})
+	if err != nil {
+		return "", err
+	}
+	if runtimeVM.Runtime.Host == nil {
+		return "", fmt.Errorf("VM does not have a host")
+	}
+
+	var host mo.HostSystem
+	pc := property.DefaultCollector(s.Client.VimClient())
+	if err := pc.RetrieveOne(vmCtx, *runtimeVM.Runtime.Host, []string{"datastore"}, &host); err != nil {
+		return "", err
+	}
+
+	datastore, err := s.getRelocateDatastore(vmCtx, moVM, storageClass, policyID, host.Datastore)
+	if err != nil {
+		return "", err
+	}
+
+	var ds mo.Datastore
+	if err := pc.RetrieveOne(vmCtx, datastore, []string{"name"}, &ds); err != nil {
+		return "", err
+	}
+
+	return ds.Name, nil
+}
+
+// errImageVolumeCopyInProgress is returned when the VM cannot be powered on
+// because the disk of one of its image volumes is still being copied.
+var errImageVolumeCopyInProgress = errors.New("image volume disk copy in progress")
+
+// copyImageVolumeDisk copies the image disk of the image volume to the VM's
+// directory on a datastore compatible with the volume's storage class, and
+// returns the datastore path of the copy. The image disk is a content library
+// disk, which may be stream-optimized, so it cannot back the VM's disk
+// directly. The copy task is recorded in the VM's annotations and an empty path
+// is returned while it is running, so the copy is tracked by subsequent
+// reconciles instead of blocking this one. Only one disk is copied at a time.
+// The VM's directory is created if the datastore is not the VM's home
+// datastore. An existing copy, left by a failed reconfigure, is overwritten.
+func (s *Session) copyImageVolumeDisk(
+	vmCtx context.VirtualMachineContextA2,
+	resVM *res.VirtualMachine,
+	config *vimTypes.VirtualMachineConfigInfo,
+	volume vmopv1.VirtualMachineVolume,
+	dSpec *vimTypes.VirtualDeviceConfigSpec,
+	imageDiskPath string) (string, error) {
+
+	vm := vmCtx.VM
+
+	task, err := s.getVMTask(vmCtx, constants.ImageVolumeCopyTaskAnnotation)
+	if err != nil {
+		return "", err
+	}
+
+	if task != nil {
+		// The task may copy the disk of another volume, whose copy completes
+		// before the disk of this volume is copied.
+		copyVolumeName := vm.Annotations[constants.ImageVolumeCopyVolumeAnnotation]
+		copyDiskPath := vm.Annotations[constants.ImageVolumeCopyDiskAnnotation]
+
+		switch task.State {
+		case vimTypes.TaskInfoStateQueued, vimTypes.TaskInfoStateRunning:
+			if copyVolumeName == volume.Name {
+				markImageVolumeCopyInProgress(vm, volume.Name, task.Progress)
+			}
+			return "", nil
+		case vimTypes.TaskInfoStateSuccess:
+			clearImageVolumeCopyTask(vm)
+			if copyVolumeName == volume.Name {
+				return copyDiskPath, nil
+			}
+		case vimTypes.TaskInfoStateError:
+			clearImageVolumeCopyTask(vm)
+			if copyVolumeName == volume.Name {
+				msg := "unknown error"
+				if task.Error != nil && task.Error.LocalizedMessage != "" {
+					msg = task.Error.LocalizedMessage
+				}
+				markImageVolumeCopyFailed(vm, volume.Name, msg)
+				return "", errors.New(msg)
+			}
+		}
+	}
+	clearImageVolumeCopyTask(vm)
+
+	var vmPath object.DatastorePath
+	if config.Files.VmPathName == "" || !vmPath.FromString(config.Files.VmPathName) {
+		return "", fmt.Errorf("invalid VM path %q", config.Files.VmPathName)
+	}
+
+	datastoreName, err := s.getVolumeDiskDatastoreName(vmCtx, resVM, config, volume, dSpec)
+	if err != nil {
+		return "", fmt.Errorf("failed to get the datastore: %w", err)
+	}
+
+	datacenter := s.Client.Datacenter()
+
+	vmDir := object.DatastorePath{
+		Datastore: datastoreName,
+		Path:      path.Dir(vmPath.Path),
+	}
+	if datastoreName != vmPath.Datastore {
+		err := object.NewFileManager(s.Client.VimClient()).MakeDirectory(vmCtx, vmDir.String(), datacenter, true)
+		if err != nil && !isFileAlreadyExists(err) {
+			return "", err
+		}
+	}
+
+	diskPath := object.DatastorePath{
+		Datastore: datastoreName,
+		Path:      path.Join(vmDir.Path, fmt.Sprintf("%s-%s.vmdk", vm.Name, volume.Name)),
+	}
+
+	vmCtx.Logger.Info("Copying image volume disk", "volume", volume.Name, "source", imageDiskPath, "destination", diskPath.String())
+
+	markImageVolumeCopyInProgress(vm, volume.Name, 0)
+
+	copyTask, err := object.NewVirtualDiskManager(s.Client.VimClient()).CopyVirtualDisk(
+		vmCtx, imageDiskPath, datacenter, diskPath.String(), datacenter, nil, true)
+	if err != nil {
+		markImageVolumeCopyFailed(vm, volume.Name, err.Error())
+		return "", err
+	}
+
+	setVMTask(vm, constants.ImageVolumeCopyTaskAnnotation, copyTask)
+	vm.Annotations[constants.ImageVolumeCopyVolumeAnnotation] = volume.Name
+	vm.Annotations[constants.ImageVolumeCopyDiskAnnotation] = diskPath.String()
+
+	done, err := waitForRelocateTask(vmCtx, copyTask)
+	if err != nil {
+		clearImageVolumeCopyTask(vm)
+		markImageVolumeCopyFailed(vm, volume.Name, err.Error())
+		return "", err
+	}
+
+	if !done {
+		// The copy is still running. Its progress is reported on subsequent
+		// reconciles.
+		return "", nil
+	}
+
+	clearImageVolumeCopyTask(vm)
+	return diskPath.String(), nil
+}
+
+// clearImageVolumeCopyTask removes the image volume disk copy task, its volume
+// and its disk from the VM's annotations.
+func clearImageVolumeCopyTask(vm *vmopv1.VirtualMachine) {
+	delete(vm.Annotations, constants.ImageVolumeCopyTaskAnnotation)
+	delete(vm.Annotations, constants.ImageVolumeCopyVolumeAnnotation)
+	delete(vm.Annotations, constants.ImageVolumeCopyDiskAnnotation)
+}
+
+func markImageVolumeCopyInProgress(vm *vmopv1.VirtualMachine, volumeName string, progress int32) {
+	conditions.MarkFalse(vm, vmopv1.VirtualMachineConditionImageVolumesCopied,
+		vmopv1.VirtualMachineImageVolumeCopyInProgressReason,
+		"Copying the image disk of volume %s: %d%% complete", volumeName, progress)
+}
+
+func markImageVolumeCopyFailed(vm *vmopv1.VirtualMachine, volumeName, msg string) {
+	conditions.MarkFalse(vm, vmopv1.VirtualMachineConditionImageVolumesCopied,
+		vmopv1.VirtualMachineImageVolumeCopyFailedReason,
+		"Copying the image disk of volume %s failed: %s", volumeName, msg)
+}
+
+func isFileAlreadyExists(err error) bool {
+	if soap.IsSoapFault(err) {
+		_, ok := soap.ToSoapFault(err).VimFault().(vimTypes.FileAlreadyExists)
+		return ok
+	}
+	return false
+}
+
+// findVolumeDiskController returns the first disk controller with a free unit,
+// trying the SCSI, NVME, SATA and then IDE controllers.
+func findVolumeDiskController(devices object.VirtualDeviceList) (vimTypes.BaseVirtualController, error) {
+	var err error
+	for _, name := range []string{"scsi", "nvme", "sata", "ide"} {
+		var controller vimTypes.BaseVirtualController
+		if controller, err = devices.FindDiskController(name); err == nil {
+			return controller, nil
+		}
+	}
+	return nil, err
+}
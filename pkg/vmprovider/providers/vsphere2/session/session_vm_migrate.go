@@ -0,0 +1,502 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package session
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	vimTypes "github.com/vmware/govmomi/vim25/types"
+	corev1 "k8s.io/api/core/v1"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	conditions "github.com/vmware-tanzu/vm-operator/pkg/conditions2"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/lib"
+	"github.com/vmware-tanzu/vm-operator/pkg/topology"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere2/constants"
+	network2 "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere2/network"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere2/placement"
+	res "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere2/resources"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere2/storage"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere2/virtualmachine"
+)
+
+// isZoneMigrationNeeded returns true if the VM's zone label has changed since
+// the VM was placed or last migrated.
+func isZoneMigrationNeeded(vm *vmopv1.VirtualMachine) bool {
+	if !lib.IsWcpFaultDomainsFSSEnabled() {
+		return false
+	}
+
+	zoneName := vm.Labels[topology.KubernetesTopologyZoneLabelKey]
+	return vm.Status.Zone != "" && zoneName != "" && zoneName != vm.Status.Zone
+}
+
+// migrateZone migrates the VM to a cluster in the availability zone specified
+// by the VM's zone label. The cluster and resource pool are selected by
+// placement in the zone's namespace resource pools, and the VM's network
+// interfaces are re-resolved for the new cluster. Disks backing PVCs stay on
+// their current datastore when the new cluster can access it, and are
+// otherwise moved to a datastore in the new cluster compatible with their
+// storage class. The migration fails if the topology of a PVC's volume does
+// not allow the volume to be used in the new zone.
+//
+// Returns false while the migration is pending, that is while the VM is still
+// being migrated or the migration is backing off after it failed. The failure
+// and the time of the last attempt are recorded in the VM's ZoneMigrated
+// condition, and the migration is not retried until relocateRetryBackoff has
+// passed since then.
+func (s *Session) migrateZone(
+	vmCtx context.VirtualMachineContextA2,
+	resVM *res.VirtualMachine,
+	getUpdateArgsFn func() (*VMUpdateArgs, error)) (bool, error) {
+
+	vm := vmCtx.VM
+	if !isZoneMigrationNeeded(vm) {
+		return true, nil
+	}
+
+	zoneName := vm.Labels[topology.KubernetesTopologyZoneLabelKey]
+
+	moVM, err := resVM.GetProperties(vmCtx,
+		[]string{"config.files", "config.hardware", "datastore"})
+	if err != nil {
+		return false, err
+	}
+
+	task, err := s.getVMTask(vmCtx, constants.ZoneMigrateTaskAnnotation)
+	if err != nil {
+		return false, err
+	}
+
+	if task != nil {
+		switch task.State {
+		case vimTypes.TaskInfoStateQueued, vimTypes.TaskInfoStateRunning:
+			markZoneMigrationInProgress(vm, zoneName, task.Progress)
+			return false, nil
+		case vimTypes.TaskInfoStateSuccess:
+			delete(vm.Annotations, constants.ZoneMigrateTaskAnnotation)
+			return true, s.markZoneMigrated(vmCtx, resVM)
+		case vimTypes.TaskInfoStateError:
+			// The migration is retried once it has backed off.
+			delete(vm.Annotations, constants.ZoneMigrateTaskAnnotation)
+			msg := "unknown error"
+			if task.Error != nil && task.Error.LocalizedMessage != "" {
+				msg = task.Error.LocalizedMessage
+			}
+			conditions.MarkFalse(vm, vmopv1.VirtualMachineConditionZoneMigrated,
+				vmopv1.VirtualMachineZoneMigrationFailedReason,
+				"Migrating VM to zone %s failed: %s", zoneName, msg)
+			return false, nil
+		}
+	}
+
+	if isRelocateBackingOff(vm, vmopv1.VirtualMachineConditionZoneMigrated, vmopv1.VirtualMachineZoneMigrationFailedReason) {
+		return false, nil
+	}
+	markZoneMigrationInProgress(vm, zoneName, 0)
+
+	relocateSpec, err := s.zoneMigrationRelocateSpec(vmCtx, resVM, moVM, getUpdateArgsFn)
+	if err != nil {
+		conditions.MarkFalse(vm, vmopv1.VirtualMachineConditionZoneMigrated,
+			vmopv1.VirtualMachineZoneMigrationFailedReason,
+			"Migrating VM to zone %s failed: %v", zoneName, err)
+		return false, err
+	}
+
+	vmCtx.Logger.Info("Migrating VM to zone", "zone", zoneName,
+		"resourcePool", relocateSpec.Pool.Value, "datastore", relocateSpec.Datastore.Value)
+
+	relocateTask, err := resVM.VcVM().Relocate(vmCtx, *relocateSpec, vimTypes.VirtualMachineMovePriorityDefaultPriority)
+	if err != nil {
+		vmCtx.Logger.Error(err, "migrate VM to zone failed")
+		conditions.MarkFalse(vm, vmopv1.VirtualMachineConditionZoneMigrated,
+			vmopv1.VirtualMachineZoneMigrationFailedReason,
+			"Migrating VM to zone %s failed: %v", zoneName, err)
+		return false, err
+	}
+
+	setVMTask(vm, constants.ZoneMigrateTaskAnnotation, relocateTask)
+
+	done, err := waitForRelocateTask(vmCtx, relocateTask)
+	if err != nil {
+		vmCtx.Logger.Error(err, "migrate VM to zone task failed")
+		delete(vm.Annotations, constants.ZoneMigrateTaskAnnotation)
+		conditions.MarkFalse(vm, vmopv1.VirtualMachineConditionZoneMigrated,
+			vmopv1.VirtualMachineZoneMigrationFailedReason,
+			"Migrating VM to zone %s failed: %v", zoneName, err)
+		return false, err
+	}
+
+	if !done {
+		// The migration is still running. Its progress is reported on
+		// subsequent reconciles.
+		return false, nil
+	}
+
+	delete(vm.Annotations, constants.ZoneMigrateTaskAnnotation)
+	return true, s.markZoneMigrated(vmCtx, resVM)
+}
+
+// zoneMigrationRelocateSpec returns the RelocateSpec that migrates the VM to
+// the resource pool, folder and datastore selected in the VM's new zone.
+func (s *Session) zoneMigrationRelocateSpec(
+	vmCtx context.VirtualMachineContextA2,
+	resVM *res.VirtualMachine,
+	moVM *mo.VirtualMachine,
+	getUpdateArgsFn func() (*VMUpdateArgs, error)) (*vimTypes.VirtualMachineRelocateSpec, error) {
+
+	vm := vmCtx.VM
+
+	updateArgs, err := getUpdateArgsFn()
+	if err != nil {
+		return nil, err
+	}
+
+	var policyID string
+	if vm.Spec.StorageClass != "" {
+		policyID, err = storage.GetStoragePolicyID(vmCtx, s.K8sClient, vm.Spec.StorageClass)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var childRPName string
+	if updateArgs.ResourcePolicy != nil {
+		childRPName = updateArgs.ResourcePolicy.Spec.ResourcePool.Name
+	}
+
+	result, err := placement.Placement(
+		vmCtx,
+		s.K8sClient,
+		s.Client.VimClient(),
+		zoneMigrationPlacementConfigSpec(vmCtx, moVM, policyID),
+		childRPName)
+	if err != nil {
+		return nil, err
+	}
+
+	folderMoID, _, err := topology.GetNamespaceFolderAndRPMoID(vmCtx, s.K8sClient, result.ZoneName, vm.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	cluster, err := object.NewResourcePool(s.Client.VimClient(), result.PoolMoRef).Owner(vmCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ccr mo.ClusterComputeResource
+	pc := property.DefaultCollector(s.Client.VimClient())
+	if err := pc.RetrieveOne(vmCtx, cluster.Reference(), []string{"datastore"}, &ccr); err != nil {
+		return nil, err
+	}
+
+	datastore, err := s.getRelocateDatastore(vmCtx, moVM, vm.Spec.StorageClass, policyID, ccr.Datastore)
+	if err != nil {
+		return nil, err
+	}
+
+	// The network interfaces are resolved for the VM's current cluster so they
+	// match up with the VM's ethernet cards, whose backings are then
+	// re-resolved for the new cluster.
+	netIfList, err := s.ensureNetworkInterfaces(vmCtx, updateArgs.ConfigSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	ethCards, err := resVM.GetNetworkDevices(vmCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	ethCardChanges, err := zoneMigrationEthCardChanges(vmCtx, s.Client.VimClient(), cluster.Reference(), netIfList, ethCards)
+	if err != nil {
+		return nil, err
+	}
+
+	folderRef := vimTypes.ManagedObjectReference{Type: "Folder", Value: folderMoID}
+
+	relocateSpec := storageRelocateSpec(vm, moVM, datastore, policyID)
+	relocateSpec.Pool = &result.PoolMoRef
+	relocateSpec.Host = result.HostMoRef
+	relocateSpec.Folder = &folderRef
+	relocateSpec.DeviceChange = ethCardChanges
+
+	if err := s.zoneMigrationPVCDiskLocators(vmCtx, moVM, result.ZoneName, ccr.Datastore, &relocateSpec); err != nil {
+		return nil, err
+	}
+
+	return &relocateSpec, nil
+}
+
+// zoneMigrationPVCDiskLocators updates the disk locators of the disks backing
+// PVCs for the VM's new zone. A disk stays on its current datastore if the new
+// cluster can access it, otherwise, like with a cluster-local vSAN datastore,
+// the disk is moved to a datastore of the new cluster that is compatible with
+// the PVC's storage class. An error is returned if the topology of a PVC's
+// volume does not allow it to be used in the new zone.
+func (s *Session) zoneMigrationPVCDiskLocators(
+	vmCtx context.VirtualMachineContextA2,
+	moVM *mo.VirtualMachine,
+	zoneName string,
+	datastores []vimTypes.ManagedObjectReference,
+	relocateSpec *vimTypes.VirtualMachineRelocateSpec) error {
+
+	vm := vmCtx.VM
+	if moVM.Config == nil {
+		return nil
+	}
+
+	claimNames := map[string]string{}
+	for _, vol := range vm.Spec.Volumes {
+		if vol.PersistentVolumeClaim != nil {
+			claimNames[vol.Name] = vol.PersistentVolumeClaim.ClaimName
+		}
+	}
+
+	diskClaimNames := map[string]string{}
+	for _, vol := range vm.Status.Volumes {
+		if claimName, ok := claimNames[vol.Name]; ok && vol.DiskUUID != "" {
+			diskClaimNames[vol.DiskUUID] = claimName
+		}
+	}
+
+	accessible := map[vimTypes.ManagedObjectReference]struct{}{}
+	for _, ds := range datastores {
+		accessible[ds] = struct{}{}
+	}
+
+	disks := map[int32]*vimTypes.VirtualDisk{}
+	for _, dev := range moVM.Config.Hardware.Device {
+		if disk, ok := dev.(*vimTypes.VirtualDisk); ok {
+			disks[disk.Key] = disk
+		}
+	}
+
+	for i := range relocateSpec.Disk {
+		locator := &relocateSpec.Disk[i]
+
+		disk, ok := disks[locator.DiskId]
+		if !ok {
+			continue
+		}
+		b, ok := disk.Backing.(*vimTypes.VirtualDiskFlatVer2BackingInfo)
+		if !ok {
+			continue
+		}
+		claimName, ok := diskClaimNames[b.Uuid]
+		if !ok {
+			continue
+		}
+
+		pvc := &corev1.PersistentVolumeClaim{}
+		if err := s.K8sClient.Get(vmCtx, ctrlclient.ObjectKey{Namespace: vm.Namespace, Name: claimName}, pvc); err != nil {
+			return err
+		}
+
+		if pvc.Spec.VolumeName != "" {
+			pv := &corev1.PersistentVolume{}
+			if err := s.K8sClient.Get(vmCtx, ctrlclient.ObjectKey{Name: pvc.Spec.VolumeName}, pv); err != nil {
+				return err
+			}
+			if !isPVAccessibleInZone(pv, zoneName) {
+				return fmt.Errorf("volume %s of PVC %s is not accessible in zone %s", pv.Name, claimName, zoneName)
+			}
+		}
+
+		if _, ok := accessible[locator.Datastore]; ok {
+			continue
+		}
+
+		var storageClass, policyID string
+		if pvc.Spec.StorageClassName != nil {
+			storageClass = *pvc.Spec.StorageClassName
+		}
+		if storageClass != "" {
+			var err error
+			policyID, err = storage.GetStoragePolicyID(vmCtx, s.K8sClient, storageClass)
+			if err != nil {
+				return err
+			}
+		}
+
+		datastore, err := s.getRelocateDatastore(vmCtx, moVM, storageClass, policyID, datastores)
+		if err != nil {
+			return fmt.Errorf("PVC %s: %w", claimName, err)
+		}
+
+		locator.Datastore = datastore
+		locator.Profile = nil
+		if policyID != "" {
+			locator.Profile = []vimTypes.BaseVirtualMachineProfileSpec{
+				&vimTypes.VirtualMachineDefinedProfileSpec{ProfileId: policyID},
+			}
+		}
+	}
+
+	return nil
+}
+
+// isPVAccessibleInZone returns true if the node affinity of the PV allows it
+// to be used in the zone.
+func isPVAccessibleInZone(pv *corev1.PersistentVolume, zoneName string) bool {
+	if pv.Spec.NodeAffinity == nil || pv.Spec.NodeAffinity.Required == nil ||
+		len(pv.Spec.NodeAffinity.Required.NodeSelectorTerms) == 0 {
+		return true
+	}
+
+	// The terms are ORed while a term's expressions are ANDed.
+	for _, term := range pv.Spec.NodeAffinity.Required.NodeSelectorTerms {
+		if isNodeSelectorTermAccessibleInZone(term, zoneName) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isNodeSelectorTermAccessibleInZone(term corev1.NodeSelectorTerm, zoneName string) bool {
+	for _, expr := range term.MatchExpressions {
+		if expr.Key != topology.KubernetesTopologyZoneLabelKey {
+			continue
+		}
+
+		inValues := false
+		for _, v := range expr.Values {
+			if v == zoneName {
+				inValues = true
+				break
+			}
+		}
+
+		switch expr.Operator {
+		case corev1.NodeSelectorOpIn:
+			if !inValues {
+				return false
+			}
+		case corev1.NodeSelectorOpNotIn:
+			if inValues {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// zoneMigrationPlacementConfigSpec returns the ConfigSpec used to place the VM
+// in its new zone.
+func zoneMigrationPlacementConfigSpec(
+	vmCtx context.VirtualMachineContextA2,
+	moVM *mo.VirtualMachine,
+	policyID string) *vimTypes.VirtualMachineConfigSpec {
+
+	configSpec := &vimTypes.VirtualMachineConfigSpec{
+		Name: vmCtx.VM.Name,
+	}
+
+	if moVM.Config != nil {
+		configSpec.NumCPUs = moVM.Config.Hardware.NumCPU
+		configSpec.MemoryMB = int64(moVM.Config.Hardware.MemoryMB)
+	}
+
+	return virtualmachine.CreateConfigSpecForPlacement(
+		vmCtx,
+		configSpec,
+		map[string]string{vmCtx.VM.Spec.StorageClass: policyID})
+}
+
+// zoneMigrationEthCardChanges returns the device changes that update the
+// backings of the VM's ethernet cards to the backings resolved for the new
+// cluster. The cards are matched to the interfaces by their MAC address when
+// the network provider assigned one, and otherwise by their current backing.
+func zoneMigrationEthCardChanges(
+	vmCtx context.VirtualMachineContextA2,
+	vimClient *vim25.Client,
+	clusterMoRef vimTypes.ManagedObjectReference,
+	netIfList network2.NetworkInterfaceResults,
+	ethCards object.VirtualDeviceList) ([]vimTypes.BaseVirtualDeviceConfigSpec, error) {
+
+	var deviceChanges []vimTypes.BaseVirtualDeviceConfigSpec
+
+	for idx := range netIfList.Results {
+		result := &netIfList.Results[idx]
+		if result.Device == nil {
+			continue
+		}
+
+		matchingIdx := -1
+		if result.MacAddress != "" {
+			for i := range ethCards {
+				ethCard := ethCards[i].(vimTypes.BaseVirtualEthernetCard).GetVirtualEthernetCard()
+				if strings.EqualFold(ethCard.MacAddress, result.MacAddress) {
+					matchingIdx = i
+					break
+				}
+			}
+		}
+		if matchingIdx == -1 {
+			matchingIdx = findMatchingEthCard(result.Device, ethCards)
+		}
+		if matchingIdx == -1 {
+			// The interface's card is added once the VM is reconfigured in
+			// its new cluster.
+			continue
+		}
+
+		matchingDev := ethCards[matchingIdx]
+		ethCards = append(ethCards[:matchingIdx], ethCards[matchingIdx+1:]...)
+
+		backing, err := network2.ResolveBackingForCluster(vmCtx, vimClient, clusterMoRef, result)
+		if err != nil {
+			return nil, fmt.Errorf("network interface %q error: %w", result.Name, err)
+		}
+
+		backingInfo, err := backing.EthernetCardBackingInfo(vmCtx)
+		if err != nil {
+			return nil, fmt.Errorf("network interface %q error: %w", result.Name, err)
+		}
+
+		ethCard := matchingDev.(vimTypes.BaseVirtualEthernetCard).GetVirtualEthernetCard()
+		ethCard.Backing = backingInfo
+		ethCard.ExternalId = result.ExternalID
+
+		deviceChanges = append(deviceChanges, &vimTypes.VirtualDeviceConfigSpec{
+			Operation: vimTypes.VirtualDeviceConfigSpecOperationEdit,
+			Device:    matchingDev,
+		})
+	}
+
+	return deviceChanges, nil
+}
+
+// markZoneMigrated records that the VM has been migrated to its zone, and
+// updates the Session's cluster to the VM's new cluster.
+func (s *Session) markZoneMigrated(
+	vmCtx context.VirtualMachineContextA2,
+	resVM *res.VirtualMachine) error {
+
+	cluster, err := virtualmachine.GetVMClusterComputeResource(vmCtx, resVM.VcVM())
+	if err != nil {
+		return err
+	}
+	s.Cluster = cluster
+
+	vmCtx.VM.Status.Zone = vmCtx.VM.Labels[topology.KubernetesTopologyZoneLabelKey]
+	conditions.MarkTrue(vmCtx.VM, vmopv1.VirtualMachineConditionZoneMigrated)
+	return nil
+}
+
+func markZoneMigrationInProgress(vm *vmopv1.VirtualMachine, zoneName string, progress int32) {
+	conditions.MarkFalse(vm, vmopv1.VirtualMachineConditionZoneMigrated,
+		vmopv1.VirtualMachineZoneMigrationInProgressReason,
+		"Migrating VM to zone %s: %d%% complete", zoneName, progress)
+}
@@ -0,0 +1,503 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package session
+
+import (
+	goctx "context"
+	"fmt"
+	"time"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/soap"
+	vimTypes "github.com/vmware/govmomi/vim25/types"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	conditions "github.com/vmware-tanzu/vm-operator/pkg/conditions2"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere2/constants"
+	res "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere2/resources"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere2/storage"
+)
+
+const (
+	// relocateWaitTimeout is how long a reconcile waits for a newly started
+	// relocation to complete before the relocation is reported as in progress
+	// and tracked through the task recorded in the VM's annotations instead.
+	relocateWaitTimeout = 10 * time.Second
+
+	// relocateRetryBackoff is how long a failed relocation or zone migration
+	// is not retried for, so one that keeps failing is not restarted on every
+	// reconcile.
+	relocateRetryBackoff = 5 * time.Minute
+)
+
+// isStorageRelocationNeeded returns true if the VM's storage class has changed
+// since the VM was created or its storage was last relocated.
+func isStorageRelocationNeeded(vm *vmopv1.VirtualMachine) bool {
+	return vm.Status.StorageClass != "" && vm.Status.StorageClass != vm.Spec.StorageClass
+}
+
+// relocateStorage relocates the VM's home and boot disks to a datastore
+// compatible with the storage policy of the VM's storage class. The VM is
+// moved with a Storage vMotion so a powered on VM keeps running. Disks backing
+// PVCs are left on their current datastore.
+func (s *Session) relocateStorage(
+	vmCtx context.VirtualMachineContextA2,
+	resVM *res.VirtualMachine) error {
+
+	vm := vmCtx.VM
+	// A started relocation is tracked to its end even if the spec's storage
+	// class has since changed back to the VM's current storage class.
+	if !isStorageRelocationNeeded(vm) && vm.Annotations[constants.StorageRelocateTaskAnnotation] == "" {
+		return nil
+	}
+
+	task, err := s.getVMTask(vmCtx, constants.StorageRelocateTaskAnnotation)
+	if err != nil {
+		return err
+	}
+
+	if task != nil {
+		// The task relocates the storage to the storage class the spec had
+		// when the task was started, which may since have changed.
+		storageClass := vm.Annotations[constants.StorageRelocateStorageClassAnnotation]
+		if storageClass == "" {
+			storageClass = vm.Spec.StorageClass
+		}
+
+		switch task.State {
+		case vimTypes.TaskInfoStateQueued, vimTypes.TaskInfoStateRunning:
+			markStorageRelocationInProgress(vm, storageClass, task.Progress)
+			return nil
+		case vimTypes.TaskInfoStateSuccess:
+			clearStorageRelocateTask(vm)
+			return s.markStorageRelocated(vmCtx, resVM, storageClass)
+		case vimTypes.TaskInfoStateError:
+			// The relocation is retried once it has backed off.
+			clearStorageRelocateTask(vm)
+			markStorageRelocationFailed(vm, storageClass, task.Error)
+			return nil
+		}
+	}
+	delete(vm.Annotations, constants.StorageRelocateStorageClassAnnotation)
+
+	if !isStorageRelocationNeeded(vm) {
+		return nil
+	}
+
+	if isRelocateBackingOff(vm, vmopv1.VirtualMachineConditionStorageRelocated, vmopv1.VirtualMachineStorageRelocationFailedReason) {
+		return nil
+	}
+
+	moVM, err := resVM.GetProperties(vmCtx,
+		[]string{"config.files", "config.hardware.device", "runtime.host", "datastore"})
+	if err != nil {
+		return err
+	}
+
+	storageClass := vm.Spec.StorageClass
+	policyID, err := storage.GetStoragePolicyID(vmCtx, s.K8sClient, storageClass)
+	if err != nil {
+		return err
+	}
+
+	if moVM.Runtime.Host == nil {
+		return fmt.Errorf("VM does not have a host")
+	}
+
+	var host mo.HostSystem
+	pc := property.DefaultCollector(s.Client.VimClient())
+	if err := pc.RetrieveOne(vmCtx, *moVM.Runtime.Host, []string{"datastore"}, &host); err != nil {
+		return err
+	}
+
+	datastore, err := s.getRelocateDatastore(vmCtx, moVM, storageClass, policyID, host.Datastore)
+	if err != nil {
+		return err
+	}
+
+	relocateSpec := storageRelocateSpec(vm, moVM, datastore, policyID)
+
+	vmCtx.Logger.Info("Relocating VM storage",
+		"storageClass", storageClass, "datastore", datastore.Value)
+
+	// Marking the relocation as in progress before it is started makes the
+	// condition's last transition time the time of the last attempt.
+	markStorageRelocationInProgress(vm, storageClass, 0)
+
+	relocateTask, err := resVM.VcVM().Relocate(vmCtx, relocateSpec, vimTypes.VirtualMachineMovePriorityDefaultPriority)
+	if err != nil {
+		vmCtx.Logger.Error(err, "relocate VM storage failed")
+		conditions.MarkFalse(vm, vmopv1.VirtualMachineConditionStorageRelocated,
+			vmopv1.VirtualMachineStorageRelocationFailedReason,
+			"Relocating VM storage to storage class %s failed: %v", storageClass, err)
+		return err
+	}
+
+	setVMTask(vm, constants.StorageRelocateTaskAnnotation, relocateTask)
+	vm.Annotations[constants.StorageRelocateStorageClassAnnotation] = storageClass
+
+	done, err := waitForRelocateTask(vmCtx, relocateTask)
+	if err != nil {
+		vmCtx.Logger.Error(err, "relocate VM storage task failed")
+		clearStorageRelocateTask(vm)
+		conditions.MarkFalse(vm, vmopv1.VirtualMachineConditionStorageRelocated,
+			vmopv1.VirtualMachineStorageRelocationFailedReason,
+			"Relocating VM storage to storage class %s failed: %v", storageClass, err)
+		return err
+	}
+
+	if !done {
+		// The relocation is still running. Its progress is reported on
+		// subsequent reconciles.
+		return nil
+	}
+
+	clearStorageRelocateTask(vm)
+	return s.markStorageRelocated(vmCtx, resVM, storageClass)
+}
+
+// clearStorageRelocateTask removes the storage relocation task and its storage
+// class from the VM's annotations.
+func clearStorageRelocateTask(vm *vmopv1.VirtualMachine) {
+	delete(vm.Annotations, constants.StorageRelocateTaskAnnotation)
+	delete(vm.Annotations, constants.StorageRelocateStorageClassAnnotation)
+}
+
+// isRelocateBackingOff returns true if the condition reports that the last
+// attempt of a relocation or zone migration failed less than
+// relocateRetryBackoff ago. Every attempt first marks the condition as in
+// progress, so the condition's last transition time is when the last attempt
+// failed.
+func isRelocateBackingOff(vm *vmopv1.VirtualMachine, conditionType, failedReason string) bool {
+	c := conditions.Get(vm, conditionType)
+	return c != nil && c.Reason == failedReason && time.Since(c.LastTransitionTime.Time) < relocateRetryBackoff
+}
+
+// waitForRelocateTask waits a short time for a newly started relocate task to
+// complete. It returns false if the task is still running once the wait times
+// out, in which case the task is tracked through the VM's recent tasks.
+func waitForRelocateTask(
+	vmCtx context.VirtualMachineContextA2,
+	task *object.Task) (bool, error) {
+
+	waitCtx, cancel := goctx.WithTimeout(vmCtx, relocateWaitTimeout)
+	defer cancel()
+
+	if _, err := task.WaitForResult(waitCtx); err != nil {
+		if waitCtx.Err() != nil && vmCtx.Err() == nil {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// setVMTask records the task in the VM's annotation with the given key, so
+// that the task is tracked by subsequent reconciles. Each operation records its
+// task under its own key, so an operation does not mistake the task of another
+// operation, or a task started outside of VM Operator, for its own.
+func setVMTask(vm *vmopv1.VirtualMachine, key string, task *object.Task) {
+	if vm.Annotations == nil {
+		vm.Annotations = map[string]string{}
+	}
+	vm.Annotations[key] = task.Reference().Value
+}
+
+// getVMTask returns the info of the task recorded in the VM's annotation with
+// the given key, or nil if there is not one. The annotation is removed when the
+// task no longer exists.
+func (s *Session) getVMTask(
+	vmCtx context.VirtualMachineContextA2,
+	key string) (*vimTypes.TaskInfo, error) {
+
+	taskID := vmCtx.VM.Annotations[key]
+	if taskID == "" {
+		return nil, nil
+	}
+
+	var task mo.Task
+	ref := vimTypes.ManagedObjectReference{Type: "Task", Value: taskID}
+	pc := property.DefaultCollector(s.Client.VimClient())
+	if err := pc.RetrieveOne(vmCtx, ref, []string{"info"}, &task); err != nil {
+		if isManagedObjectNotFound(err) {
+			vmCtx.Logger.Info("Task no longer exists", "task", taskID)
+			delete(vmCtx.VM.Annotations, key)
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &task.Info, nil
+}
+
+func isManagedObjectNotFound(err error) bool {
+	if soap.IsSoapFault(err) {
+		_, ok := soap.ToSoapFault(err).VimFault().(vimTypes.ManagedObjectNotFound)
+		return ok
+	}
+	return false
+}
+
+// getRelocateDatastore returns the datastore out of the candidates the VM's
+// storage, or a disk with the storage class, is relocated to. The VM's current
+// home datastore is preferred if it is compatible with the storage policy,
+// otherwise the compatible datastore with the most free space is selected.
+func (s *Session) getRelocateDatastore(
+	vmCtx context.VirtualMachineContextA2,
+	moVM *mo.VirtualMachine,
+	storageClass string,
+	policyID string,
+	candidates []vimTypes.ManagedObjectReference) (vimTypes.ManagedObjectReference, error) {
+
+	compatible := candidates
+	if policyID != "" {
+		var err error
+		compatible, err = storage.GetCompatibleDatastores(vmCtx, s.Client, policyID, candidates)
+		if err != nil {
+			return vimTypes.ManagedObjectReference{}, err
+		}
+	}
+	if len(compatible) == 0 {
+		return vimTypes.ManagedObjectReference{}, fmt.Errorf(
+			"no datastore compatible with storage class %s is available", storageClass)
+	}
+
+	var datastores []mo.Datastore
+	pc := property.DefaultCollector(s.Client.VimClient())
+	if err := pc.Retrieve(vmCtx, compatible, []string{"name", "summary"}, &datastores); err != nil {
+		return vimTypes.ManagedObjectReference{}, err
+	}
+
+	homeDatastoreName := getHomeDatastoreName(moVM)
+
+	var selected *mo.Datastore
+	for i := range datastores {
+		ds := &datastores[i]
+		if !ds.Summary.Accessible {
+			continue
+		}
+		if ds.Name == homeDatastoreName {
+			return ds.Reference(), nil
+		}
+		if selected == nil || ds.Summary.FreeSpace > selected.Summary.FreeSpace {
+			selected = ds
+		}
+	}
+
+	if selected == nil {
+		return vimTypes.ManagedObjectReference{}, fmt.Errorf(
+			"no datastore compatible with storage class %s is accessible", storageClass)
+	}
+
+	return selected.Reference(), nil
+}
+
+// getHomeDatastoreName returns the name of the datastore of the VM's home
+// directory.
+func getHomeDatastoreName(moVM *mo.VirtualMachine) string {
+	if moVM.Config == nil {
+		return ""
+	}
+
+	var dsPath object.DatastorePath
+	if !dsPath.FromString(moVM.Config.Files.VmPathName) {
+		return ""
+	}
+
+	return dsPath.Datastore
+}
+
+// getPVCDiskUUIDs returns the UUIDs of the VM's disks that back PVCs.
+func getPVCDiskUUIDs(vm *vmopv1.VirtualMachine) map[string]struct{} {
+	uuids := map[string]struct{}{}
+	for _, vol := range vm.Status.Volumes {
+		if vol.DiskUUID != "" {
+			uuids[vol.DiskUUID] = struct{}{}
+		}
+	}
+	return uuids
+}
+
+// getNonPVCDisks returns the VM's disks that do not back PVCs, ie the boot
+// disk and any other disks from the VM's image.
+func getNonPVCDisks(
+	vm *vmopv1.VirtualMachine,
+	moVM *mo.VirtualMachine) []*vimTypes.VirtualDisk {
+
+	if moVM.Config == nil {
+		return nil
+	}
+
+	pvcDiskUUIDs := getPVCDiskUUIDs(vm)
+
+	var disks []*vimTypes.VirtualDisk
+	for _, dev := range moVM.Config.Hardware.Device {
+		if disk, ok := dev.(*vimTypes.VirtualDisk); ok && !isPVCDisk(disk, pvcDiskUUIDs) {
+			disks = append(disks, disk)
+		}
+	}
+
+	return disks
+}
+
+func isPVCDisk(disk *vimTypes.VirtualDisk, pvcDiskUUIDs map[string]struct{}) bool {
+	if b, ok := disk.Backing.(*vimTypes.VirtualDiskFlatVer2BackingInfo); ok {
+		_, ok := pvcDiskUUIDs[b.Uuid]
+		return ok
+	}
+	return false
+}
+
+// storageRelocateSpec returns the RelocateSpec that moves the VM's home and
+// non-PVC disks to the datastore with the storage policy, if any. PVC disks
+// are kept on their current datastore with their current policy.
+func storageRelocateSpec(
+	vm *vmopv1.VirtualMachine,
+	moVM *mo.VirtualMachine,
+	datastore vimTypes.ManagedObjectReference,
+	policyID string) vimTypes.VirtualMachineRelocateSpec {
+
+	var profile []vimTypes.BaseVirtualMachineProfileSpec
+	if policyID != "" {
+		profile = append(profile, &vimTypes.VirtualMachineDefinedProfileSpec{ProfileId: policyID})
+	}
+
+	relocateSpec := vimTypes.VirtualMachineRelocateSpec{
+		Datastore: &datastore,
+		Profile:   profile,
+	}
+
+	if moVM.Config == nil {
+		return relocateSpec
+	}
+
+	pvcDiskUUIDs := getPVCDiskUUIDs(vm)
+
+	for _, dev := range moVM.Config.Hardware.Device {
+		disk, ok := dev.(*vimTypes.VirtualDisk)
+		if !ok {
+			continue
+		}
+
+		locator := vimTypes.VirtualMachineRelocateSpecDiskLocator{
+			DiskId:    disk.Key,
+			Datastore: datastore,
+			Profile:   profile,
+		}
+
+		if isPVCDisk(disk, pvcDiskUUIDs) {
+			b, ok := disk.Backing.(vimTypes.BaseVirtualDeviceFileBackingInfo)
+			if !ok || b.GetVirtualDeviceFileBackingInfo().Datastore == nil {
+				continue
+			}
+			locator.Datastore = *b.GetVirtualDeviceFileBackingInfo().Datastore
+			locator.Profile = nil
+		}
+
+		relocateSpec.Disk = append(relocateSpec.Disk, locator)
+	}
+
+	return relocateSpec
+}
+
+// isStorageCompliant returns true if the datastores of the VM's home and
+// non-PVC disks are compatible with the storage policy.
+func (s *Session) isStorageCompliant(
+	vmCtx context.VirtualMachineContextA2,
+	resVM *res.VirtualMachine,
+	policyID string) (bool, error) {
+
+	moVM, err := resVM.GetProperties(vmCtx, []string{"config.files", "config.hardware.device", "datastore"})
+	if err != nil {
+		return false, err
+	}
+
+	var vmDatastores []mo.Datastore
+	if len(moVM.Datastore) > 0 {
+		pc := property.DefaultCollector(s.Client.VimClient())
+		if err := pc.Retrieve(vmCtx, moVM.Datastore, []string{"name"}, &vmDatastores); err != nil {
+			return false, err
+		}
+	}
+
+	datastores := map[vimTypes.ManagedObjectReference]struct{}{}
+
+	homeDatastoreName := getHomeDatastoreName(moVM)
+	for _, ds := range vmDatastores {
+		if ds.Name == homeDatastoreName {
+			datastores[ds.Reference()] = struct{}{}
+		}
+	}
+
+	for _, disk := range getNonPVCDisks(vmCtx.VM, moVM) {
+		if b, ok := disk.Backing.(vimTypes.BaseVirtualDeviceFileBackingInfo); ok {
+			if ds := b.GetVirtualDeviceFileBackingInfo().Datastore; ds != nil {
+				datastores[*ds] = struct{}{}
+			}
+		}
+	}
+
+	refs := make([]vimTypes.ManagedObjectReference, 0, len(datastores))
+	for ref := range datastores {
+		refs = append(refs, ref)
+	}
+
+	compatible, err := storage.GetCompatibleDatastores(vmCtx, s.Client, policyID, refs)
+	if err != nil {
+		return false, err
+	}
+
+	return len(compatible) == len(refs), nil
+}
+
+// markStorageRelocated records that the VM's storage has been relocated to
+// the storage class and whether the storage is compliant with its policy.
+func (s *Session) markStorageRelocated(
+	vmCtx context.VirtualMachineContextA2,
+	resVM *res.VirtualMachine,
+	storageClass string) error {
+
+	vm := vmCtx.VM
+	vm.Status.StorageClass = storageClass
+
+	policyID, err := storage.GetStoragePolicyID(vmCtx, s.K8sClient, storageClass)
+	if err != nil {
+		return err
+	}
+
+	compliant, err := s.isStorageCompliant(vmCtx, resVM, policyID)
+	if err != nil {
+		return err
+	}
+
+	if !compliant {
+		conditions.MarkFalse(vm, vmopv1.VirtualMachineConditionStorageRelocated,
+			vmopv1.VirtualMachineStorageNotCompliantReason,
+			"VM storage is not compliant with the storage policy of storage class %s", storageClass)
+		return nil
+	}
+
+	conditions.MarkTrue(vm, vmopv1.VirtualMachineConditionStorageRelocated)
+	return nil
+}
+
+func markStorageRelocationInProgress(vm *vmopv1.VirtualMachine, storageClass string, progress int32) {
+	conditions.MarkFalse(vm, vmopv1.VirtualMachineConditionStorageRelocated,
+		vmopv1.VirtualMachineStorageRelocationInProgressReason,
+		"Relocating VM storage to storage class %s: %d%% complete", storageClass, progress)
+}
+
+func markStorageRelocationFailed(vm *vmopv1.VirtualMachine, storageClass string, fault *vimTypes.LocalizedMethodFault) {
+	msg := "unknown error"
+	if fault != nil && fault.LocalizedMessage != "" {
+		msg = fault.LocalizedMessage
+	}
+	conditions.MarkFalse(vm, vmopv1.VirtualMachineConditionStorageRelocated,
+		vmopv1.VirtualMachineStorageRelocationFailedReason,
+		"Relocating VM storage to storage class %s failed: %s", storageClass, msg)
+}
@@ -4,6 +4,9 @@
 package session
 
 import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
 	"time"
@@ -17,7 +20,9 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	"github.com/vmware-tanzu/vm-operator/api/v1alpha2/common"
 	"github.com/vmware-tanzu/vm-operator/pkg"
+	conditions "github.com/vmware-tanzu/vm-operator/pkg/conditions2"
 	"github.com/vmware-tanzu/vm-operator/pkg/context"
 	"github.com/vmware-tanzu/vm-operator/pkg/lib"
 	"github.com/vmware-tanzu/vm-operator/pkg/util"
@@ -31,6 +36,10 @@ import (
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere2/vmlifecycle"
 )
 
+// minHotResizeHardwareVersion is the minimum hardware version that supports
+// hot-adding CPU and memory.
+const minHotResizeHardwareVersion = 7
+
 // VMUpdateArgs contains the arguments needed to update a VM on VC.
 type VMUpdateArgs struct {
 	VMClass        *vmopv1.VirtualMachineClass
@@ -77,63 +86,71 @@ func ethCardMatch(newBaseEthCard, curBaseEthCard vimTypes.BaseVirtualEthernetCar
 	return true
 }
 
-func UpdateEthCardDeviceChanges(
-	expectedEthCards object.VirtualDeviceList,
-	currentEthCards object.VirtualDeviceList) ([]vimTypes.BaseVirtualDeviceConfigSpec, error) {
-
-	var deviceChanges []vimTypes.BaseVirtualDeviceConfigSpec
-	for _, expectedDev := range expectedEthCards {
-		expectedNic := expectedDev.(vimTypes.BaseVirtualEthernetCard)
-		expectedBacking := expectedNic.GetVirtualEthernetCard().Backing
-		expectedBackingType := reflect.TypeOf(expectedBacking)
+// findMatchingEthCard returns the index of the card in currentEthCards that
+// matches the expected card, or -1 if there is no match.
+func findMatchingEthCard(
+	expectedDev vimTypes.BaseVirtualDevice,
+	currentEthCards object.VirtualDeviceList) int {
+
+	expectedNic := expectedDev.(vimTypes.BaseVirtualEthernetCard)
+	expectedBacking := expectedNic.GetVirtualEthernetCard().Backing
+	expectedBackingType := reflect.TypeOf(expectedBacking)
+
+	// Try to match the expected NIC with an existing NIC but this isn't that great. We mostly
+	// depend on the backing but we can improve that later on. When not generated, we could use
+	// the MAC address. When we support something other than just vmxnet3 we should compare
+	// those types too. And we should make this truly reconcile as well by comparing the full
+	// state (support EDIT instead of only ADD/REMOVE operations).
+	//
+	// Another tack we could take is force the VM's device order to match the Spec order, but
+	// that could lead to spurious removals. Or reorder the NetIfList to not be that of the
+	// Spec, but in VM device order.
+	for idx, curDev := range currentEthCards {
+		nic := curDev.(vimTypes.BaseVirtualEthernetCard)
+
+		// This assumes we don't have multiple NICs in the same backing network. This is kind of, sort
+		// of enforced by the webhook, but we lack a guaranteed way to match up the NICs.
+
+		if !ethCardMatch(expectedNic, nic) {
+			continue
+		}
 
-		var matchingIdx = -1
+		db := nic.GetVirtualEthernetCard().Backing
+		if db == nil || reflect.TypeOf(db) != expectedBackingType {
+			continue
+		}
 
-		// Try to match the expected NIC with an existing NIC but this isn't that great. We mostly
-		// depend on the backing but we can improve that later on. When not generated, we could use
-		// the MAC address. When we support something other than just vmxnet3 we should compare
-		// those types too. And we should make this truly reconcile as well by comparing the full
-		// state (support EDIT instead of only ADD/REMOVE operations).
-		//
-		// Another tack we could take is force the VM's device order to match the Spec order, but
-		// that could lead to spurious removals. Or reorder the NetIfList to not be that of the
-		// Spec, but in VM device order.
-		for idx, curDev := range currentEthCards {
-			nic := curDev.(vimTypes.BaseVirtualEthernetCard)
-
-			// This assumes we don't have multiple NICs in the same backing network. This is kind of, sort
-			// of enforced by the webhook, but we lack a guaranteed way to match up the NICs.
-
-			if !ethCardMatch(expectedNic, nic) {
-				continue
-			}
+		var backingMatch bool
+
+		// Cribbed from VirtualDeviceList.SelectByBackingInfo().
+		switch a := db.(type) {
+		case *vimTypes.VirtualEthernetCardNetworkBackingInfo:
+			// This backing is only used in testing.
+			b := expectedBacking.(*vimTypes.VirtualEthernetCardNetworkBackingInfo)
+			backingMatch = a.DeviceName == b.DeviceName
+		case *vimTypes.VirtualEthernetCardDistributedVirtualPortBackingInfo:
+			b := expectedBacking.(*vimTypes.VirtualEthernetCardDistributedVirtualPortBackingInfo)
+			backingMatch = a.Port.SwitchUuid == b.Port.SwitchUuid && a.Port.PortgroupKey == b.Port.PortgroupKey
+		case *vimTypes.VirtualEthernetCardOpaqueNetworkBackingInfo:
+			b := expectedBacking.(*vimTypes.VirtualEthernetCardOpaqueNetworkBackingInfo)
+			backingMatch = a.OpaqueNetworkId == b.OpaqueNetworkId
+		}
 
-			db := nic.GetVirtualEthernetCard().Backing
-			if db == nil || reflect.TypeOf(db) != expectedBackingType {
-				continue
-			}
+		if backingMatch {
+			return idx
+		}
+	}
 
-			var backingMatch bool
+	return -1
+}
 
-			// Cribbed from VirtualDeviceList.SelectByBackingInfo().
-			switch a := db.(type) {
-			case *vimTypes.VirtualEthernetCardNetworkBackingInfo:
-				// This backing is only used in testing.
-				b := expectedBacking.(*vimTypes.VirtualEthernetCardNetworkBackingInfo)
-				backingMatch = a.DeviceName == b.DeviceName
-			case *vimTypes.VirtualEthernetCardDistributedVirtualPortBackingInfo:
-				b := expectedBacking.(*vimTypes.VirtualEthernetCardDistributedVirtualPortBackingInfo)
-				backingMatch = a.Port.SwitchUuid == b.Port.SwitchUuid && a.Port.PortgroupKey == b.Port.PortgroupKey
-			case *vimTypes.VirtualEthernetCardOpaqueNetworkBackingInfo:
-				b := expectedBacking.(*vimTypes.VirtualEthernetCardOpaqueNetworkBackingInfo)
-				backingMatch = a.OpaqueNetworkId == b.OpaqueNetworkId
-			}
+func UpdateEthCardDeviceChanges(
+	expectedEthCards object.VirtualDeviceList,
+	currentEthCards object.VirtualDeviceList) ([]vimTypes.BaseVirtualDeviceConfigSpec, error) {
 
-			if backingMatch {
-				matchingIdx = idx
-				break
-			}
-		}
+	var deviceChanges []vimTypes.BaseVirtualDeviceConfigSpec
+	for _, expectedDev := range expectedEthCards {
+		matchingIdx := findMatchingEthCard(expectedDev, currentEthCards)
 
 		if matchingIdx == -1 {
 			// No matching backing found so add new card.
@@ -228,6 +245,240 @@ func UpdatePCIDeviceChanges(
 	return append(removeDeviceChanges, deviceChanges...), nil
 }
 
+// volumeDiskModes maps the volume disk modes to the vSphere disk modes.
+var volumeDiskModes = map[vmopv1.VirtualMachineVolumeDiskMode]vimTypes.VirtualDiskMode{
+	vmopv1.VirtualMachineVolumeDiskModePersistent:               vimTypes.VirtualDiskModePersistent,
+	vmopv1.VirtualMachineVolumeDiskModeIndependentPersistent:    vimTypes.VirtualDiskModeIndependent_persistent,
+	vmopv1.VirtualMachineVolumeDiskModeIndependentNonPersistent: vimTypes.VirtualDiskModeIndependent_nonpersistent,
+}
+
+// volumeSharingModes maps the volume sharing modes to the vSphere disk sharing
+// modes.
+var volumeSharingModes = map[vmopv1.VirtualMachineVolumeSharingMode]vimTypes.VirtualDiskSharing{
+	vmopv1.VirtualMachineVolumeSharingModeNone:        vimTypes.VirtualDiskSharingSharingNone,
+	vmopv1.VirtualMachineVolumeSharingModeMultiWriter: vimTypes.VirtualDiskSharingSharingMultiWriter,
+}
+
+// UpdateVolumeAttachOptionsDeviceChanges returns the device changes that apply
+// the attach options of the VM's PVC volumes to the disks CNS attached to the
+// VM: the disk is moved to the requested controller, which is added if the VM
+// does not have it, and its disk and sharing modes are set. The disks can only
+// be changed while the VM is powered off, so the options are applied before
+// the VM is powered on and are not applied to volumes hot-attached to a
+// powered on VM. Volumes that are not attached yet are skipped. The added
+// controllers are appended to devices, and the moved disks are updated in it.
+func UpdateVolumeAttachOptionsDeviceChanges(
+	vm *vmopv1.VirtualMachine,
+	devices *object.VirtualDeviceList) ([]vimTypes.BaseVirtualDeviceConfigSpec, error) {
+
+	diskUUIDs := map[string]string{}
+	for _, volStatus := range vm.Status.Volumes {
+		if volStatus.Attached && volStatus.DiskUUID != "" {
+			diskUUIDs[volStatus.Name] = volStatus.DiskUUID
+		}
+	}
+
+	disks := map[string]*vimTypes.VirtualDisk{}
+	for _, dev := range devices.SelectByType((*vimTypes.VirtualDisk)(nil)) {
+		disk := dev.(*vimTypes.VirtualDisk)
+		if backing, ok := disk.Backing.(*vimTypes.VirtualDiskFlatVer2BackingInfo); ok && backing.Uuid != "" {
+			disks[backing.Uuid] = disk
+		}
+	}
+
+	var deviceChanges []vimTypes.BaseVirtualDeviceConfigSpec
+	for _, volume := range vm.Spec.Volumes {
+		claim := volume.PersistentVolumeClaim
+		if claim == nil {
+			continue
+		}
+
+		disk, ok := disks[diskUUIDs[volume.Name]]
+		if !ok {
+			continue
+		}
+		backing := disk.Backing.(*vimTypes.VirtualDiskFlatVer2BackingInfo)
+
+		changed := false
+
+		if claim.ControllerType != "" {
+			controller, controllerChanges, err := ensureVolumeController(*devices, claim)
+			if err != nil {
+				return nil, fmt.Errorf("volume %s: %w", volume.Name, err)
+			}
+			for _, c := range controllerChanges {
+				*devices = append(*devices, c.GetVirtualDeviceConfigSpec().Device)
+			}
+			deviceChanges = append(deviceChanges, controllerChanges...)
+
+			controllerMoved, err := assignVolumeController(*devices, disk, controller, claim.UnitNumber)
+			if err != nil {
+				return nil, fmt.Errorf("volume %s: %w", volume.Name, err)
+			}
+			changed = changed || controllerMoved
+		}
+
+		if diskMode, ok := volumeDiskModes[claim.DiskMode]; ok && backing.DiskMode != string(diskMode) {
+			backing.DiskMode = string(diskMode)
+			changed = true
+		}
+
+		if sharing, ok := volumeSharingModes[claim.SharingMode]; ok && backing.Sharing != string(sharing) {
+			backing.Sharing = string(sharing)
+			changed = true
+		}
+
+		if changed {
+			deviceChanges = append(deviceChanges, &vimTypes.VirtualDeviceConfigSpec{
+				Operation: vimTypes.VirtualDeviceConfigSpecOperationEdit,
+				Device:    disk,
+			})
+		}
+	}
+
+	return deviceChanges, nil
+}
+
+// ensureVolumeController returns the VM's controller of the claim's controller
+// type and bus number. If the VM does not have the controller, the device
+// change that adds the controller is returned as well.
+func ensureVolumeController(
+	devices object.VirtualDeviceList,
+	claim *vmopv1.PersistentVolumeClaimVolumeSource) (vimTypes.BaseVirtualController, []vimTypes.BaseVirtualDeviceConfigSpec, error) {
+
+	isSCSI := false
+	var deviceType string
+	switch claim.ControllerType {
+	case vmopv1.VirtualMachineVolumeControllerTypeParaVirtualSCSI:
+		isSCSI, deviceType = true, "pvscsi"
+	case vmopv1.VirtualMachineVolumeControllerTypeLsiLogic:
+		isSCSI, deviceType = true, "lsilogic"
+	case vmopv1.VirtualMachineVolumeControllerTypeLsiLogicSAS:
+		isSCSI, deviceType = true, "lsilogic-sas"
+	case vmopv1.VirtualMachineVolumeControllerTypeBusLogic:
+		isSCSI, deviceType = true, "buslogic"
+	case vmopv1.VirtualMachineVolumeControllerTypeNVME:
+		deviceType = "nvme"
+	case vmopv1.VirtualMachineVolumeControllerTypeSATA:
+		deviceType = "ahci"
+	default:
+		return nil, nil, fmt.Errorf("unsupported controller type %s", claim.ControllerType)
+	}
+
+	for _, dev := range devices {
+		controller, ok := dev.(vimTypes.BaseVirtualController)
+		if !ok {
+			continue
+		}
+
+		_, devIsSCSI := dev.(vimTypes.BaseVirtualSCSIController)
+		sameBus := devIsSCSI && isSCSI || devices.Type(dev) == deviceType
+		if !sameBus {
+			continue
+		}
+
+		busNumber := controller.GetVirtualController().BusNumber
+		if claim.ControllerBusNumber != nil && busNumber != *claim.ControllerBusNumber {
+			continue
+		}
+		if devices.Type(dev) != deviceType {
+			if claim.ControllerBusNumber == nil {
+				continue
+			}
+			return nil, nil, fmt.Errorf("SCSI bus %d already has a %s controller", busNumber, devices.Type(dev))
+		}
+
+		return controller, nil, nil
+	}
+
+	var newController vimTypes.BaseVirtualDevice
+	var err error
+	switch {
+	case isSCSI:
+		newController, err = devices.CreateSCSIController(deviceType)
+	case deviceType == "nvme":
+		newController, err = devices.CreateNVMEController()
+	default:
+		newController = &vimTypes.VirtualAHCIController{
+			VirtualSATAController: vimTypes.VirtualSATAController{
+				VirtualController: vimTypes.VirtualController{
+					VirtualDevice: vimTypes.VirtualDevice{Key: devices.NewKey()},
+				},
+			},
+		}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	controller := newController.(vimTypes.BaseVirtualController)
+	if claim.ControllerBusNumber != nil {
+		controller.GetVirtualController().BusNumber = *claim.ControllerBusNumber
+	} else if deviceType == "ahci" {
+		controller.GetVirtualController().BusNumber = newSATABusNumber(devices)
+	}
+	if controller.GetVirtualController().BusNumber < 0 {
+		return nil, nil, fmt.Errorf("no free bus for a new %s controller", claim.ControllerType)
+	}
+
+	return controller, []vimTypes.BaseVirtualDeviceConfigSpec{
+		&vimTypes.VirtualDeviceConfigSpec{
+			Operation: vimTypes.VirtualDeviceConfigSpecOperationAdd,
+			Device:    newController,
+		},
+	}, nil
+}
+
+// newSATABusNumber returns the first bus number not used by the VM's SATA
+// controllers, or -1 if all of them are used.
+func newSATABusNumber(devices object.VirtualDeviceList) int32 {
+	used := map[int32]bool{}
+	for _, dev := range devices.SelectByType((*vimTypes.VirtualAHCIController)(nil)) {
+		used[dev.(vimTypes.BaseVirtualController).GetVirtualController().BusNumber] = true
+	}
+
+	for busNumber := int32(0); busNumber < 4; busNumber++ {
+		if !used[busNumber] {
+			return busNumber
+		}
+	}
+
+	return -1
+}
+
+// assignVolumeController assigns the disk to the unit of the controller, or to
+// a free unit if unitNumber is nil, and returns true if the disk was moved.
+func assignVolumeController(
+	devices object.VirtualDeviceList,
+	disk *vimTypes.VirtualDisk,
+	controller vimTypes.BaseVirtualController,
+	unitNumber *int32) (bool, error) {
+
+	controllerKey := controller.GetVirtualController().Key
+
+	if disk.ControllerKey == controllerKey && disk.UnitNumber != nil &&
+		(unitNumber == nil || *disk.UnitNumber == *unitNumber) {
+		return false, nil
+	}
+
+	if unitNumber == nil {
+		devices.AssignController(disk, controller)
+		return true, nil
+	}
+
+	for _, dev := range devices {
+		d := dev.GetVirtualDevice()
+		if d.Key != disk.Key && d.ControllerKey == controllerKey && d.UnitNumber != nil && *d.UnitNumber == *unitNumber {
+			return false, fmt.Errorf("unit %d of the %s controller is in use by %s",
+				*unitNumber, devices.Name(controller.(vimTypes.BaseVirtualDevice)), devices.Name(dev))
+		}
+	}
+
+	disk.ControllerKey = controllerKey
+	disk.UnitNumber = pointer.Int32(*unitNumber)
+	return true, nil
+}
+
 func UpdateConfigSpecCPUAllocation(
 	config *vimTypes.VirtualMachineConfigInfo,
 	configSpec *vimTypes.VirtualMachineConfigSpec,
@@ -404,6 +655,44 @@ func UpdateHardwareConfigSpec(
 	}
 }
 
+// CanHotResize returns true if the CPU and memory changes in the ConfigSpec
+// may be applied to the VM while it is powered on. Hot-add is only possible
+// when it is enabled on the VM, which vSphere only allows for guests and
+// hardware versions that support it. Memory cannot be hot-removed.
+func CanHotResize(
+	config *vimTypes.VirtualMachineConfigInfo,
+	configSpec *vimTypes.VirtualMachineConfigSpec) bool {
+
+	if configSpec.NumCPUs == 0 && configSpec.MemoryMB == 0 {
+		// Reservations and limits may always be changed online.
+		return true
+	}
+
+	if util.ParseVirtualHardwareVersion(config.Version) < minHotResizeHardwareVersion {
+		return false
+	}
+
+	if nCPUs := configSpec.NumCPUs; nCPUs != 0 {
+		if nCPUs > config.Hardware.NumCPU && !pointer.BoolDeref(config.CpuHotAddEnabled, false) {
+			return false
+		}
+		if nCPUs < config.Hardware.NumCPU && !pointer.BoolDeref(config.CpuHotRemoveEnabled, false) {
+			return false
+		}
+	}
+
+	if memMB := configSpec.MemoryMB; memMB != 0 {
+		if memMB < int64(config.Hardware.MemoryMB) || !pointer.BoolDeref(config.MemoryHotAddEnabled, false) {
+			return false
+		}
+		if limit := config.HotPlugMemoryLimit; limit != 0 && memMB > limit {
+			return false
+		}
+	}
+
+	return true
+}
+
 func UpdateConfigSpecAnnotation(
 	config *vimTypes.VirtualMachineConfigInfo,
 	configSpec *vimTypes.VirtualMachineConfigSpec) {
@@ -449,8 +738,9 @@ func updateConfigSpec(
 	// reconfigured to match the desired CPU and memory reservation.  Maintain that
 	// behavior.  With the FSS enabled, VMs will be _created_ with desired HW spec, and we
 	// will not modify the hardware of the VM post creation.  So, don't populate the
-	// Hardware config and CPU/Memory reservation.
-	if !lib.IsVMClassAsConfigFSSDaynDateEnabled() {
+	// Hardware config and CPU/Memory reservation, unless the VM's class has been changed
+	// and the VM needs to be resized to the new class.
+	if !lib.IsVMClassAsConfigFSSDaynDateEnabled() || isResizeNeeded(vmCtx.VM) {
 		UpdateHardwareConfigSpec(config, configSpec, &vmClassSpec)
 		UpdateConfigSpecCPUAllocation(config, configSpec, &vmClassSpec, updateArgs.MinCPUFreq)
 		UpdateConfigSpecMemoryAllocation(config, configSpec, &vmClassSpec)
@@ -468,6 +758,7 @@ func updateConfigSpec(
 
 func (s *Session) prePowerOnVMConfigSpec(
 	vmCtx context.VirtualMachineContextA2,
+	resVM *res.VirtualMachine,
 	config *vimTypes.VirtualMachineConfigInfo,
 	updateArgs *VMUpdateArgs) (*vimTypes.VirtualMachineConfigSpec, error) {
 
@@ -478,11 +769,25 @@ func (s *Session) prePowerOnVMConfigSpec(
 	currentEthCards := virtualDevices.SelectByType((*vimTypes.VirtualEthernetCard)(nil))
 	currentPciDevices := virtualDevices.SelectByType((*vimTypes.VirtualPCIPassthrough)(nil))
 
-	diskDeviceChanges, err := updateVirtualDiskDeviceChanges(vmCtx, currentDisks)
+	if err := updateConfigSpecBootDiskCapacity(vmCtx, configSpec, currentDisks); err != nil {
+		return nil, err
+	}
+
+	// The volume disks are assigned controller units out of the devices the
+	// VM will have after the reconfigure, so the disks are added to and moved
+	// within one list. The attach options are applied first so the units the
+	// volumes request are taken before free units are assigned to new disks.
+	devices := append(object.VirtualDeviceList(nil), virtualDevices...)
+
+	attachOptionsDeviceChanges, err := UpdateVolumeAttachOptionsDeviceChanges(vmCtx.VM, &devices)
 	if err != nil {
 		return nil, err
 	}
-	configSpec.DeviceChange = append(configSpec.DeviceChange, diskDeviceChanges...)
+	configSpec.DeviceChange = append(configSpec.DeviceChange, attachOptionsDeviceChanges...)
+
+	if err := s.updateConfigSpecVolumeDisks(vmCtx, resVM, configSpec, config, updateArgs.ConfigSpec, &devices); err != nil {
+		return nil, err
+	}
 
 	var expectedEthCards object.VirtualDeviceList
 	for idx := range updateArgs.NetworkResults.Results {
@@ -520,7 +825,7 @@ func (s *Session) prePowerOnVMReconfigure(
 	config *vimTypes.VirtualMachineConfigInfo,
 	updateArgs *VMUpdateArgs) error {
 
-	configSpec, err := s.prePowerOnVMConfigSpec(vmCtx, config, updateArgs)
+	configSpec, err := s.prePowerOnVMConfigSpec(vmCtx, resVM, config, updateArgs)
 	if err != nil {
 		return err
 	}
@@ -537,6 +842,230 @@ func (s *Session) prePowerOnVMReconfigure(
 	return nil
 }
 
+// isResizeNeeded returns true if the VM's class has changed since the VM was
+// created or last resized.
+func isResizeNeeded(vm *vmopv1.VirtualMachine) bool {
+	return vm.Status.Class != nil && vm.Status.Class.Name != vm.Spec.ClassName
+}
+
+// markResized records that the VM has been resized to its class.
+func markResized(vm *vmopv1.VirtualMachine, vmClass *vmopv1.VirtualMachineClass) {
+	vm.Status.Class = &common.LocalObjectRef{
+		APIVersion: vmopv1.SchemeGroupVersion.String(),
+		Kind:       "VirtualMachineClass",
+		Name:       vmClass.Name,
+	}
+	conditions.MarkTrue(vm, vmopv1.VirtualMachineConditionResized)
+}
+
+// resizeConfigSpec returns the ConfigSpec to resize the VM's CPU and memory
+// to its class.
+func resizeConfigSpec(
+	config *vimTypes.VirtualMachineConfigInfo,
+	updateArgs *VMUpdateArgs) *vimTypes.VirtualMachineConfigSpec {
+
+	configSpec := &vimTypes.VirtualMachineConfigSpec{}
+	vmClassSpec := updateArgs.VMClass.Spec
+
+	UpdateHardwareConfigSpec(config, configSpec, &vmClassSpec)
+	UpdateConfigSpecCPUAllocation(config, configSpec, &vmClassSpec, updateArgs.MinCPUFreq)
+	UpdateConfigSpecMemoryAllocation(config, configSpec, &vmClassSpec)
+
+	return configSpec
+}
+
+// poweredOnVMResize resizes a powered on VM to its class when the resize can
+// be hot-added. Otherwise the resize is left to be applied the next time the
+// VM is powered on.
+func (s *Session) poweredOnVMResize(
+	vmCtx context.VirtualMachineContextA2,
+	resVM *res.VirtualMachine,
+	config *vimTypes.VirtualMachineConfigInfo,
+	getUpdateArgsFn func() (*VMUpdateArgs, error)) error {
+
+	updateArgs, err := getUpdateArgsFn()
+	if err != nil {
+		return err
+	}
+
+	configSpec := resizeConfigSpec(config, updateArgs)
+
+	defaultConfigSpec := &vimTypes.VirtualMachineConfigSpec{}
+	if !apiEquality.Semantic.DeepEqual(configSpec, defaultConfigSpec) {
+		if !CanHotResize(config, configSpec) {
+			conditions.MarkFalse(vmCtx.VM, vmopv1.VirtualMachineConditionResized,
+				vmopv1.VirtualMachineResizeRestartRequiredReason,
+				"VM must be powered off and on to be resized to class %s", updateArgs.VMClass.Name)
+			return nil
+		}
+
+		vmCtx.Logger.Info("PoweredOn Resize", "configSpec", configSpec)
+		if err := resVM.Reconfigure(vmCtx, configSpec); err != nil {
+			vmCtx.Logger.Error(err, "powered on resize failed")
+			return err
+		}
+	}
+
+	markResized(vmCtx.VM, updateArgs.VMClass)
+	return nil
+}
+
+// poweredOnVMNetworkReconfigure hot-adds and hot-removes the ethernet cards of
+// a powered on VM so they match the interfaces in the VM's spec. The
+// hot-added interfaces are marked as pending guest config in the VM's status,
+// and the network interface CRs of the hot-removed interfaces are deleted.
+// This is only done when the network spec changed since the VM's network was
+// last reconfigured.
+func (s *Session) poweredOnVMNetworkReconfigure(
+	vmCtx context.VirtualMachineContextA2,
+	resVM *res.VirtualMachine,
+	config *vimTypes.VirtualMachineConfigInfo,
+	getUpdateArgsFn func() (*VMUpdateArgs, error)) error {
+
+	// The webhook does not allow the interfaces to change from or to the
+	// default interface while the VM is powered on.
+	networkSpec := vmCtx.VM.Spec.Network
+	if networkSpec == nil || networkSpec.Disabled || len(networkSpec.Interfaces) == 0 {
+		return nil
+	}
+
+	networkSpecHash, err := getNetworkSpecHash(networkSpec)
+	if err != nil {
+		return err
+	}
+	if vmCtx.VM.Annotations[vmopv1.NetworkSpecHashAnnotation] == networkSpecHash {
+		return nil
+	}
+
+	updateArgs, err := getUpdateArgsFn()
+	if err != nil {
+		return err
+	}
+
+	netIfList, err := s.ensureNetworkInterfaces(vmCtx, updateArgs.ConfigSpec)
+	if err != nil {
+		return err
+	}
+
+	var expectedEthCards object.VirtualDeviceList
+	for idx := range netIfList.Results {
+		expectedEthCards = append(expectedEthCards, netIfList.Results[idx].Device)
+	}
+
+	currentEthCards := object.VirtualDeviceList(config.Hardware.Device).SelectByType((*vimTypes.VirtualEthernetCard)(nil))
+	ethCardDeviceChanges, err := UpdateEthCardDeviceChanges(expectedEthCards, currentEthCards)
+	if err != nil {
+		return err
+	}
+
+	// The cards already match when a previous reconcile reconfigured the VM
+	// but failed in a later step, so the steps below are still done until the
+	// hash is recorded.
+	if len(ethCardDeviceChanges) > 0 {
+		configSpec := &vimTypes.VirtualMachineConfigSpec{DeviceChange: ethCardDeviceChanges}
+		vmCtx.Logger.Info("PoweredOn Network Reconfigure", "configSpec", configSpec)
+		if err := resVM.Reconfigure(vmCtx, configSpec); err != nil {
+			vmCtx.Logger.Error(err, "powered on network reconfigure failed")
+			return err
+		}
+	}
+
+	// Now that the removed interfaces' cards are gone from the VM, release
+	// their network interface CRs.
+	if err := network2.DeleteUnusedNetworkInterfaces(vmCtx, s.K8sClient, networkSpec.Interfaces); err != nil {
+		return err
+	}
+
+	// The guest network config matches the interfaces by MAC address, so get
+	// the addresses assigned to the cards, including the hot-added ones.
+	ethCards, err := resVM.GetNetworkDevices(vmCtx)
+	if err != nil {
+		return err
+	}
+
+	var pending []vmopv1.VirtualMachineNetworkInterfacePendingStatus
+	for idx := range netIfList.Results {
+		result := &netIfList.Results[idx]
+
+		if matchingIdx := findMatchingEthCard(result.Device, ethCards); matchingIdx != -1 {
+			if result.MacAddress == "" {
+				ethCard := ethCards[matchingIdx].(vimTypes.BaseVirtualEthernetCard).GetVirtualEthernetCard()
+				result.MacAddress = ethCard.MacAddress
+			}
+			ethCards = append(ethCards[:matchingIdx], ethCards[matchingIdx+1:]...)
+		}
+
+		for _, deviceChange := range ethCardDeviceChanges {
+			spec := deviceChange.GetVirtualDeviceConfigSpec()
+			if spec.Operation == vimTypes.VirtualDeviceConfigSpecOperationAdd && spec.Device == result.Device {
+				pending = append(pending, vmopv1.VirtualMachineNetworkInterfacePendingStatus{
+					Name:    result.Name,
+					MACAddr: result.MacAddress,
+				})
+				break
+			}
+		}
+	}
+
+	markNetworkInterfacesPendingGuestConfig(vmCtx.VM, pending)
+
+	err = vmlifecycle.DoBootstrapNetworkUpdate(vmCtx, resVM.VcVM(), config, s.K8sClient, netIfList, updateArgs.BootstrapData)
+	if err != nil {
+		return err
+	}
+
+	setNetworkSpecHash(vmCtx.VM, networkSpecHash)
+	return nil
+}
+
+// getNetworkSpecHash returns a hash of the network spec that changes when any
+// of the VM's network settings change.
+func getNetworkSpecHash(networkSpec *vmopv1.VirtualMachineNetworkSpec) (string, error) {
+	data, err := json.Marshal(networkSpec)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(data)), nil
+}
+
+// setNetworkSpecHash records the hash of the network spec the VM's network was
+// reconfigured with.
+func setNetworkSpecHash(vm *vmopv1.VirtualMachine, networkSpecHash string) {
+	if vm.Annotations == nil {
+		vm.Annotations = map[string]string{}
+	}
+	vm.Annotations[vmopv1.NetworkSpecHashAnnotation] = networkSpecHash
+}
+
+// markNetworkInterfacesPendingGuestConfig records the hot-added interfaces in
+// the VM's status until their IP configuration is observed in the guest.
+func markNetworkInterfacesPendingGuestConfig(
+	vm *vmopv1.VirtualMachine,
+	interfaces []vmopv1.VirtualMachineNetworkInterfacePendingStatus) {
+
+	if len(interfaces) == 0 {
+		return
+	}
+
+	if vm.Status.Network == nil {
+		vm.Status.Network = &vmopv1.VirtualMachineNetworkStatus{}
+	}
+
+	for _, newPending := range interfaces {
+		found := false
+		for i := range vm.Status.Network.PendingGuestConfig {
+			if vm.Status.Network.PendingGuestConfig[i].Name == newPending.Name {
+				vm.Status.Network.PendingGuestConfig[i] = newPending
+				found = true
+				break
+			}
+		}
+		if !found {
+			vm.Status.Network.PendingGuestConfig = append(vm.Status.Network.PendingGuestConfig, newPending)
+		}
+	}
+}
+
 func (s *Session) ensureNetworkInterfaces(
 	vmCtx context.VirtualMachineContextA2,
 	configSpec *vimTypes.VirtualMachineConfigSpec) (network2.NetworkInterfaceResults, error) {
@@ -676,9 +1205,73 @@ func (s *Session) customize(
 		return err
 	}
 
+	// Record the data the VM was customized with so a failed customization is
+	// only retried once the data changes.
+	dataHash, err := vmlifecycle.GetBootstrapDataHash(updateArgs.BootstrapData)
+	if err != nil {
+		return err
+	}
+	if vmCtx.VM.Status.GuestCustomization == nil {
+		vmCtx.VM.Status.GuestCustomization = &vmopv1.VirtualMachineGuestCustomizationStatus{}
+	}
+	vmCtx.VM.Status.GuestCustomization.DataHash = dataHash
+
 	return nil
 }
 
+// retryFailedCustomization powers off a VM whose guest customization failed when
+// the VM opted in to retries and its bootstrap data changed since it was last
+// customized. The VM is customized again with the new data when it is powered back
+// on. Returns true if the VM was powered off.
+func (s *Session) retryFailedCustomization(
+	vmCtx context.VirtualMachineContextA2,
+	resVM *res.VirtualMachine,
+	getUpdateArgsFn func() (*VMUpdateArgs, error)) (bool, error) {
+
+	bootstrap := vmCtx.VM.Spec.Bootstrap
+	if bootstrap == nil || bootstrap.Customization == nil || !bootstrap.Customization.RetryOnSecretChange {
+		return false, nil
+	}
+
+	if !conditions.IsFalse(vmCtx.VM, vmopv1.GuestCustomizationCondition) ||
+		conditions.GetReason(vmCtx.VM, vmopv1.GuestCustomizationCondition) != vmopv1.GuestCustomizationFailedReason {
+		return false, nil
+	}
+
+	// Without the hash of the data the VM was customized with, it is not known
+	// whether the data has changed.
+	status := vmCtx.VM.Status.GuestCustomization
+	if status == nil || status.DataHash == "" {
+		return false, nil
+	}
+	lastDataHash := status.DataHash
+
+	updateArgs, err := getUpdateArgsFn()
+	if err != nil {
+		return false, err
+	}
+
+	dataHash, err := vmlifecycle.GetBootstrapDataHash(updateArgs.BootstrapData)
+	if err != nil {
+		return false, err
+	}
+
+	if dataHash == lastDataHash {
+		return false, nil
+	}
+
+	vmCtx.Logger.Info("Powering off VM to retry failed guest customization with the changed bootstrap data")
+	if err := resVM.SetPowerState(
+		logr.NewContext(vmCtx, vmCtx.Logger),
+		vmopv1.VirtualMachinePowerStateOn,
+		vmopv1.VirtualMachinePowerStateOff,
+		vmopv1.VirtualMachinePowerOpModeHard); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
 func (s *Session) prepareVMForPowerOn(
 	vmCtx context.VirtualMachineContextA2,
 	resVM *res.VirtualMachine,
@@ -697,6 +1290,19 @@ func (s *Session) prepareVMForPowerOn(
 		return err
 	}
 
+	// The VM is not powered on until the disks of all of its image volumes
+	// have been copied and added to the VM.
+	if vmCtx.VM.Annotations[constants.ImageVolumeCopyTaskAnnotation] != "" {
+		return errImageVolumeCopyInProgress
+	}
+	if conditions.Has(vmCtx.VM, vmopv1.VirtualMachineConditionImageVolumesCopied) {
+		conditions.MarkTrue(vmCtx.VM, vmopv1.VirtualMachineConditionImageVolumesCopied)
+	}
+
+	if isResizeNeeded(vmCtx.VM) {
+		markResized(vmCtx.VM, updateArgs.VMClass)
+	}
+
 	err = s.customize(vmCtx, resVM, cfg, updateArgs)
 	if err != nil {
 		return err
@@ -718,6 +1324,12 @@ func (s *Session) poweredOnVMReconfigure(
 	configSpec := &vimTypes.VirtualMachineConfigSpec{}
 	UpdateConfigSpecChangeBlockTracking(config, configSpec, nil, vmCtx.VM.Spec)
 
+	// Extend the boot disk live when its capacity has been increased.
+	virtualDisks := object.VirtualDeviceList(config.Hardware.Device).SelectByType((*vimTypes.VirtualDisk)(nil))
+	if err := updateConfigSpecBootDiskCapacity(vmCtx, configSpec, virtualDisks); err != nil {
+		return err
+	}
+
 	defaultConfigSpec := &vimTypes.VirtualMachineConfigSpec{}
 	if !apiEquality.Semantic.DeepEqual(configSpec, defaultConfigSpec) {
 		vmCtx.Logger.Info("PoweredOn Reconfigure", "configSpec", configSpec)
@@ -767,11 +1379,6 @@ func (s *Session) UpdateVirtualMachine(
 
 	resVM := res.NewVMFromObject(vcVM)
 
-	moVM, err := resVM.GetProperties(vmCtx, []string{"config", "runtime"})
-	if err != nil {
-		return err
-	}
-
 	defer func() {
 		updateErr := vmlifecycle.UpdateStatus(vmCtx, s.K8sClient, vcVM, nil)
 		if updateErr != nil {
@@ -782,6 +1389,28 @@ func (s *Session) UpdateVirtualMachine(
 		}
 	}()
 
+	// Migrate and relocate the VM before getting its properties since both
+	// change the VM's devices. While the zone migration is pending, the VM's
+	// power state is still updated but the VM is not otherwise reconfigured.
+	// An error of the migration is returned once the power state is updated.
+	migrated, migrateErr := s.migrateZone(vmCtx, resVM, getUpdateArgsFn)
+	defer func() {
+		if err == nil {
+			err = migrateErr
+		}
+	}()
+
+	if migrated {
+		if err := s.relocateStorage(vmCtx, resVM); err != nil {
+			return err
+		}
+	}
+
+	moVM, err := resVM.GetProperties(vmCtx, []string{"config", "runtime"})
+	if err != nil {
+		return err
+	}
+
 	// Translate the VM's current power state into the VM Op power state value.
 	var existingPowerState vmopv1.VirtualMachinePowerState
 	switch moVM.Runtime.PowerState {
@@ -793,6 +1422,12 @@ func (s *Session) UpdateVirtualMachine(
 		existingPowerState = vmopv1.VirtualMachinePowerStateSuspended
 	}
 
+	if isResizeNeeded(vmCtx.VM) && vmCtx.VM.Spec.PowerState != vmopv1.VirtualMachinePowerStateOn {
+		conditions.MarkFalse(vmCtx.VM, vmopv1.VirtualMachineConditionResized,
+			vmopv1.VirtualMachineResizePendingReason,
+			"VM will be resized to class %s when it is next powered on", vmCtx.VM.Spec.ClassName)
+	}
+
 	switch vmCtx.VM.Spec.PowerState {
 	case vmopv1.VirtualMachinePowerStateOff:
 		var powerOff bool
@@ -835,6 +1470,12 @@ func (s *Session) UpdateVirtualMachine(
 		switch existingPowerState {
 		case vmopv1.VirtualMachinePowerStateOn:
 
+			if migrated {
+				if retried, err := s.retryFailedCustomization(vmCtx, resVM, getUpdateArgsFn); err != nil || retried {
+					return err
+				}
+			}
+
 			// Check to see if a possible restart is required.
 			// Please note a VM may only be restarted if it is powered on.
 			if vmCtx.VM.Spec.NextRestartTime != "" {
@@ -868,6 +1509,21 @@ func (s *Session) UpdateVirtualMachine(
 				}
 			}
 
+			if !migrated {
+				// The VM is reconfigured once it has been migrated.
+				return nil
+			}
+
+			if isResizeNeeded(vmCtx.VM) {
+				if err := s.poweredOnVMResize(vmCtx, resVM, config, getUpdateArgsFn); err != nil {
+					return err
+				}
+			}
+
+			if err := s.poweredOnVMNetworkReconfigure(vmCtx, resVM, config, getUpdateArgsFn); err != nil {
+				return err
+			}
+
 			// Do not pass classConfigSpec to poweredOnVMReconfigure when VM is
 			// already powered on since we do not have to get VM class at this
 			// point.
@@ -893,6 +1549,10 @@ func (s *Session) UpdateVirtualMachine(
 		}
 
 		if err := s.prepareVMForPowerOn(vmCtx, resVM, config, updateArgs); err != nil {
+			if errors.Is(err, errImageVolumeCopyInProgress) {
+				// The VM is powered on once the copy has completed.
+				return nil
+			}
 			return err
 		}
 
@@ -234,6 +234,122 @@ var _ = Describe("Update ConfigSpec", func() {
 		})
 	})
 
+	Context("CanHotResize", func() {
+		var canHotResize bool
+
+		BeforeEach(func() {
+			config.Version = "vmx-19"
+			config.Hardware.NumCPU = 2
+			config.Hardware.MemoryMB = 4096
+		})
+
+		JustBeforeEach(func() {
+			canHotResize = session.CanHotResize(config, configSpec)
+		})
+
+		Context("only reservations and limits change", func() {
+			BeforeEach(func() {
+				configSpec.CpuAllocation = &vimTypes.ResourceAllocationInfo{Limit: pointer.Int64(1000)}
+			})
+
+			It("returns true", func() {
+				Expect(canHotResize).To(BeTrue())
+			})
+		})
+
+		Context("CPUs are added", func() {
+			BeforeEach(func() {
+				configSpec.NumCPUs = 4
+			})
+
+			It("returns false when CPU hot-add is disabled", func() {
+				Expect(canHotResize).To(BeFalse())
+			})
+
+			Context("CPU hot-add is enabled", func() {
+				BeforeEach(func() {
+					config.CpuHotAddEnabled = pointer.Bool(true)
+				})
+
+				It("returns true", func() {
+					Expect(canHotResize).To(BeTrue())
+				})
+
+				Context("hardware version does not support hot-add", func() {
+					BeforeEach(func() {
+						config.Version = "vmx-04"
+					})
+
+					It("returns false", func() {
+						Expect(canHotResize).To(BeFalse())
+					})
+				})
+			})
+		})
+
+		Context("CPUs are removed", func() {
+			BeforeEach(func() {
+				configSpec.NumCPUs = 1
+				config.CpuHotAddEnabled = pointer.Bool(true)
+			})
+
+			It("returns false when CPU hot-remove is disabled", func() {
+				Expect(canHotResize).To(BeFalse())
+			})
+
+			Context("CPU hot-remove is enabled", func() {
+				BeforeEach(func() {
+					config.CpuHotRemoveEnabled = pointer.Bool(true)
+				})
+
+				It("returns true", func() {
+					Expect(canHotResize).To(BeTrue())
+				})
+			})
+		})
+
+		Context("memory is added", func() {
+			BeforeEach(func() {
+				configSpec.MemoryMB = 8192
+			})
+
+			It("returns false when memory hot-add is disabled", func() {
+				Expect(canHotResize).To(BeFalse())
+			})
+
+			Context("memory hot-add is enabled", func() {
+				BeforeEach(func() {
+					config.MemoryHotAddEnabled = pointer.Bool(true)
+				})
+
+				It("returns true", func() {
+					Expect(canHotResize).To(BeTrue())
+				})
+
+				Context("memory exceeds the hot-plug limit", func() {
+					BeforeEach(func() {
+						config.HotPlugMemoryLimit = 6144
+					})
+
+					It("returns false", func() {
+						Expect(canHotResize).To(BeFalse())
+					})
+				})
+			})
+		})
+
+		Context("memory is removed", func() {
+			BeforeEach(func() {
+				configSpec.MemoryMB = 2048
+				config.MemoryHotAddEnabled = pointer.Bool(true)
+			})
+
+			It("returns false", func() {
+				Expect(canHotResize).To(BeFalse())
+			})
+		})
+	})
+
 	Context("ExtraConfig", func() {
 		var vmClassSpec *vmopv1.VirtualMachineClassSpec
 		var classConfigSpec *vimTypes.VirtualMachineConfigSpec
@@ -1201,4 +1317,189 @@ var _ = Describe("Update ConfigSpec", func() {
 			})
 		})
 	})
+
+	Context("Volume Attach Options Changes", func() {
+		const diskUUID = "6000C29a-3b5a-7a3c-a4c8-0a0dbd24ab71"
+
+		var (
+			vm             *vmopv1.VirtualMachine
+			claim          *vmopv1.PersistentVolumeClaimVolumeSource
+			disk           *vimTypes.VirtualDisk
+			currentDevices object.VirtualDeviceList
+			deviceChanges  []vimTypes.BaseVirtualDeviceConfigSpec
+			err            error
+		)
+
+		BeforeEach(func() {
+			claim = &vmopv1.PersistentVolumeClaimVolumeSource{
+				PersistentVolumeClaimVolumeSource: corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: "my-pvc",
+				},
+			}
+			vm = &vmopv1.VirtualMachine{
+				Spec: vmopv1.VirtualMachineSpec{
+					Volumes: []vmopv1.VirtualMachineVolume{
+						{
+							Name: "my-vol",
+							VirtualMachineVolumeSource: vmopv1.VirtualMachineVolumeSource{
+								PersistentVolumeClaim: claim,
+							},
+						},
+					},
+				},
+				Status: vmopv1.VirtualMachineStatus{
+					Volumes: []vmopv1.VirtualMachineVolumeStatus{
+						{
+							Name:     "my-vol",
+							Attached: true,
+							DiskUUID: diskUUID,
+						},
+					},
+				},
+			}
+
+			scsiController := &vimTypes.VirtualLsiLogicController{
+				VirtualSCSIController: vimTypes.VirtualSCSIController{
+					VirtualController: vimTypes.VirtualController{
+						VirtualDevice: vimTypes.VirtualDevice{Key: 1000},
+						BusNumber:     0,
+					},
+					ScsiCtlrUnitNumber: 7,
+				},
+			}
+			disk = &vimTypes.VirtualDisk{
+				VirtualDevice: vimTypes.VirtualDevice{
+					Key:           2001,
+					ControllerKey: 1000,
+					UnitNumber:    pointer.Int32(1),
+					Backing: &vimTypes.VirtualDiskFlatVer2BackingInfo{
+						Uuid:     diskUUID,
+						DiskMode: string(vimTypes.VirtualDiskModePersistent),
+						Sharing:  string(vimTypes.VirtualDiskSharingSharingNone),
+					},
+				},
+			}
+			currentDevices = object.VirtualDeviceList{scsiController, disk}
+		})
+
+		JustBeforeEach(func() {
+			deviceChanges, err = session.UpdateVolumeAttachOptionsDeviceChanges(vm, &currentDevices)
+		})
+
+		Context("No attach options", func() {
+			It("returns empty list", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(deviceChanges).To(BeEmpty())
+			})
+		})
+
+		Context("Volume is not attached", func() {
+			BeforeEach(func() {
+				claim.DiskMode = vmopv1.VirtualMachineVolumeDiskModeIndependentPersistent
+				vm.Status.Volumes[0].Attached = false
+			})
+
+			It("returns empty list", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(deviceChanges).To(BeEmpty())
+			})
+		})
+
+		Context("Disk and sharing modes", func() {
+			BeforeEach(func() {
+				claim.DiskMode = vmopv1.VirtualMachineVolumeDiskModeIndependentPersistent
+				claim.SharingMode = vmopv1.VirtualMachineVolumeSharingModeMultiWriter
+			})
+
+			It("returns edit device change", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(deviceChanges).To(HaveLen(1))
+
+				configSpec := deviceChanges[0].GetVirtualDeviceConfigSpec()
+				Expect(configSpec.Operation).To(Equal(vimTypes.VirtualDeviceConfigSpecOperationEdit))
+				backing := configSpec.Device.GetVirtualDevice().Backing.(*vimTypes.VirtualDiskFlatVer2BackingInfo)
+				Expect(backing.DiskMode).To(Equal(string(vimTypes.VirtualDiskModeIndependent_persistent)))
+				Expect(backing.Sharing).To(Equal(string(vimTypes.VirtualDiskSharingSharingMultiWriter)))
+			})
+
+			Context("Disk already has the modes", func() {
+				BeforeEach(func() {
+					backing := disk.Backing.(*vimTypes.VirtualDiskFlatVer2BackingInfo)
+					backing.DiskMode = string(vimTypes.VirtualDiskModeIndependent_persistent)
+					backing.Sharing = string(vimTypes.VirtualDiskSharingSharingMultiWriter)
+				})
+
+				It("returns empty list", func() {
+					Expect(err).ToNot(HaveOccurred())
+					Expect(deviceChanges).To(BeEmpty())
+				})
+			})
+		})
+
+		Context("Controller does not exist", func() {
+			BeforeEach(func() {
+				claim.ControllerType = vmopv1.VirtualMachineVolumeControllerTypeParaVirtualSCSI
+				claim.ControllerBusNumber = pointer.Int32(1)
+				claim.UnitNumber = pointer.Int32(2)
+			})
+
+			It("returns add controller and edit disk device changes", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(deviceChanges).To(HaveLen(2))
+
+				configSpec := deviceChanges[0].GetVirtualDeviceConfigSpec()
+				Expect(configSpec.Operation).To(Equal(vimTypes.VirtualDeviceConfigSpecOperationAdd))
+				controller, ok := configSpec.Device.(*vimTypes.ParaVirtualSCSIController)
+				Expect(ok).To(BeTrue())
+				Expect(controller.BusNumber).To(BeEquivalentTo(1))
+
+				configSpec = deviceChanges[1].GetVirtualDeviceConfigSpec()
+				Expect(configSpec.Operation).To(Equal(vimTypes.VirtualDeviceConfigSpecOperationEdit))
+				Expect(configSpec.Device.GetVirtualDevice().ControllerKey).To(Equal(controller.Key))
+				Expect(configSpec.Device.GetVirtualDevice().UnitNumber).To(Equal(pointer.Int32(2)))
+			})
+		})
+
+		Context("Controller exists", func() {
+			BeforeEach(func() {
+				claim.ControllerType = vmopv1.VirtualMachineVolumeControllerTypeLsiLogic
+				claim.ControllerBusNumber = pointer.Int32(0)
+			})
+
+			It("returns empty list when the disk is on the controller", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(deviceChanges).To(BeEmpty())
+			})
+
+			Context("Unit is in use", func() {
+				BeforeEach(func() {
+					claim.UnitNumber = pointer.Int32(0)
+					currentDevices = append(currentDevices, &vimTypes.VirtualDisk{
+						VirtualDevice: vimTypes.VirtualDevice{
+							Key:           2000,
+							ControllerKey: 1000,
+							UnitNumber:    pointer.Int32(0),
+						},
+					})
+				})
+
+				It("returns error", func() {
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("unit 0 of the lsilogic-1000 controller is in use"))
+				})
+			})
+		})
+
+		Context("Bus has a controller of another type", func() {
+			BeforeEach(func() {
+				claim.ControllerType = vmopv1.VirtualMachineVolumeControllerTypeParaVirtualSCSI
+				claim.ControllerBusNumber = pointer.Int32(0)
+			})
+
+			It("returns error", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("SCSI bus 0 already has a lsilogic controller"))
+			})
+		})
+	})
 })
@@ -0,0 +1,67 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package storage
+
+import (
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/pbm"
+	pbmTypes "github.com/vmware/govmomi/pbm/types"
+	vimTypes "github.com/vmware/govmomi/vim25/types"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	vcclient "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere2/client"
+)
+
+// GetCompatibleDatastores returns the subset of the given datastores that are
+// compatible with the storage profile.
+func GetCompatibleDatastores(
+	vmCtx context.VirtualMachineContextA2,
+	vcClient *vcclient.Client,
+	storageProfileID string,
+	datastores []vimTypes.ManagedObjectReference) ([]vimTypes.ManagedObjectReference, error) {
+
+	if len(datastores) == 0 {
+		return nil, nil
+	}
+
+	c, err := pbm.NewClient(vmCtx, vcClient.VimClient())
+	if err != nil {
+		return nil, err
+	}
+
+	hubs := make([]pbmTypes.PbmPlacementHub, 0, len(datastores))
+	for _, ds := range datastores {
+		hubs = append(hubs, pbmTypes.PbmPlacementHub{
+			HubType: ds.Type,
+			HubId:   ds.Value,
+		})
+	}
+
+	requirements := []pbmTypes.BasePbmPlacementRequirement{
+		&pbmTypes.PbmPlacementCapabilityProfileRequirement{
+			ProfileId: pbmTypes.PbmProfileId{UniqueId: storageProfileID},
+		},
+	}
+
+	result, err := c.CheckRequirements(vmCtx, hubs, nil, requirements)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to check placement requirements for storage profile ID: %s", storageProfileID)
+	}
+
+	compatibleHubs := map[string]struct{}{}
+	for _, hub := range result.CompatibleDatastores() {
+		compatibleHubs[hub.HubId] = struct{}{}
+	}
+
+	// Only return datastores from the given list in case the placement
+	// solver returns hubs that were not asked about.
+	var compatible []vimTypes.ManagedObjectReference
+	for _, ds := range datastores {
+		if _, ok := compatibleHubs[ds.Value]; ok {
+			compatible = append(compatible, ds)
+		}
+	}
+
+	return compatible, nil
+}
@@ -66,12 +66,18 @@ func getVMStorageClassNames(vm *vmopv1.VirtualMachine) []string {
 		var storageClass string
 
 		claim := vol.PersistentVolumeClaim
-		if claim != nil {
+		switch {
+		case claim != nil:
 			if isClaim := claim.InstanceVolumeClaim; isClaim != nil {
 				storageClass = isClaim.StorageClass
 			} else { //nolint
 				// TODO: Fetch claim.ClaimName PVC to get the StorageClass.
 			}
+		case vol.Ephemeral != nil:
+			storageClass = vol.Ephemeral.StorageClass
+		case vol.Image != nil:
+			// Defaults to the VM's StorageClass that is already included.
+			storageClass = vol.Image.StorageClass
 		}
 
 		if storageClass != "" {
@@ -42,3 +42,44 @@ func ClusterMinCPUFreq(ctx goctx.Context, cluster *object.ClusterComputeResource
 
 	return minFreq, nil
 }
+
+// ClusterHostAddresses returns the IP addresses of the VMkernel network adapters of
+// all the hosts in the cluster.
+func ClusterHostAddresses(ctx goctx.Context, cluster *object.ClusterComputeResource) ([]string, error) {
+	var cr mo.ComputeResource
+	if err := cluster.Properties(ctx, cluster.Reference(), []string{"host"}, &cr); err != nil {
+		return nil, err
+	}
+
+	if len(cr.Host) == 0 {
+		return nil, nil
+	}
+
+	var hosts []mo.HostSystem
+	pc := property.DefaultCollector(cluster.Client())
+	if err := pc.Retrieve(ctx, cr.Host, []string{"config.network.vnic"}, &hosts); err != nil {
+		return nil, err
+	}
+
+	var addrs []string
+	for _, h := range hosts {
+		if h.Config == nil || h.Config.Network == nil {
+			continue
+		}
+		for _, vnic := range h.Config.Network.Vnic {
+			if vnic.Spec.Ip == nil {
+				continue
+			}
+			if ip := vnic.Spec.Ip.IpAddress; ip != "" {
+				addrs = append(addrs, ip)
+			}
+			if ipv6 := vnic.Spec.Ip.IpV6Config; ipv6 != nil {
+				for _, addr := range ipv6.IpV6Address {
+					addrs = append(addrs, addr.IpAddress)
+				}
+			}
+		}
+	}
+
+	return addrs, nil
+}
@@ -13,6 +13,7 @@ import (
 
 func clusterTests() {
 	Describe("ClusterMinCPUFreq", minFreq)
+	Describe("ClusterHostAddresses", hostAddresses)
 }
 
 func minFreq() {
@@ -45,3 +46,32 @@ func minFreq() {
 		})
 	})
 }
+
+func hostAddresses() {
+	var (
+		ctx        *builder.TestContextForVCSim
+		testConfig builder.VCSimTestConfig
+	)
+
+	BeforeEach(func() {
+		testConfig = builder.VCSimTestConfig{WithV1A2: true}
+	})
+
+	JustBeforeEach(func() {
+		ctx = suite.NewTestContextForVCSim(testConfig)
+	})
+
+	AfterEach(func() {
+		ctx.AfterEach()
+		ctx = nil
+	})
+
+	Describe("ClusterHostAddresses", func() {
+		It("returns the addresses of the hosts in cluster", func() {
+			addrs, err := vcenter.ClusterHostAddresses(ctx, ctx.GetSingleClusterCompute())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(addrs).ToNot(BeEmpty())
+			Expect(addrs).To(ContainElement("127.0.0.1"))
+		})
+	})
+}
@@ -4,11 +4,14 @@
 package vcenter
 
 import (
+	goctx "context"
 	"fmt"
 
 	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/view"
 	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -43,6 +46,43 @@ func GetVirtualMachine(
 	return findVMByInventory(vmCtx, k8sClient, vimClient, finder)
 }
 
+// GetManagedVirtualMachines returns the properties of the VMs in the folder, and
+// its child folders, that are managed by VM Service.
+func GetManagedVirtualMachines(
+	ctx goctx.Context,
+	vimClient *vim25.Client,
+	folder *object.Folder,
+	properties []string) ([]mo.VirtualMachine, error) {
+
+	m := view.NewManager(vimClient)
+	v, err := m.CreateContainerView(ctx, folder.Reference(), []string{"VirtualMachine"}, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create container view: %w", err)
+	}
+	defer func() {
+		_ = v.Destroy(ctx)
+	}()
+
+	var moVMs []mo.VirtualMachine
+	if err := v.Retrieve(ctx, []string{"VirtualMachine"}, append(properties, "config.managedBy"), &moVMs); err != nil {
+		return nil, fmt.Errorf("failed to retrieve VM properties: %w", err)
+	}
+
+	managedVMs := make([]mo.VirtualMachine, 0, len(moVMs))
+	for _, moVM := range moVMs {
+		if moVM.Config == nil || moVM.Config.ManagedBy == nil {
+			continue
+		}
+		if moVM.Config.ManagedBy.ExtensionKey != vmopv1.ManagedByExtensionKey ||
+			moVM.Config.ManagedBy.Type != vmopv1.ManagedByExtensionType {
+			continue
+		}
+		managedVMs = append(managedVMs, moVM)
+	}
+
+	return managedVMs, nil
+}
+
 func findVMByMoID(
 	vmCtx context.VirtualMachineContextA2,
 	finder *find.Finder,
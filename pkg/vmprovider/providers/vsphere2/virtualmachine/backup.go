@@ -24,13 +24,15 @@ type VMDiskData struct {
 	PVCName string
 	// AccessMode is the access modes of the PVC backed by the virtual disk.
 	AccessModes []corev1.PersistentVolumeAccessMode
+	// VolumeName is the name of the ephemeral or image volume backed by the virtual disk.
+	VolumeName string
 }
 
 // BackupVirtualMachine backs up the required data of a VM into its ExtraConfig.
 // Currently, the following data is backed up:
 // - Kubernetes VirtualMachine object in YAML format (without its .status field).
 // - VM bootstrap data in JSON (if provided).
-// - VM disk data in JSON (if created and attached by PVCs).
+// - VM disk data in JSON (if created and attached by PVCs, or created for ephemeral and image volumes).
 func BackupVirtualMachine(ctx context.BackupVirtualMachineContextA2) error {
 	var moVM mo.VirtualMachine
 	if err := ctx.VcVM.Properties(ctx.VMCtx, ctx.VcVM.Reference(),
@@ -197,8 +199,17 @@ func getDesiredBootstrapDataForBackup(
 func getDesiredDiskDataForBackup(
 	ctx context.BackupVirtualMachineContextA2,
 	ecMap map[string]string) (string, error) {
-	// Return an empty string to skip backup if no disk uuid to PVC is specified.
-	if len(ctx.DiskUUIDToPVC) == 0 {
+	// The disks created for the ephemeral and image volumes are recorded in the ExtraConfig
+	// by their controller key and unit number.
+	volumeDiskToName := map[string]string{}
+	for _, volume := range FilterVolumeDiskVolumes(ctx.VMCtx.VM) {
+		if val, ok := ecMap[VolumeDiskExtraConfigKey(volume.Name)]; ok {
+			volumeDiskToName[val] = volume.Name
+		}
+	}
+
+	// Return an empty string to skip backup if no disk uuid to PVC or volume disk is specified.
+	if len(ctx.DiskUUIDToPVC) == 0 && len(volumeDiskToName) == 0 {
 		return "", nil
 	}
 
@@ -217,6 +228,11 @@ func getDesiredDiskDataForBackup(
 						PVCName:     pvc.Name,
 						AccessModes: pvc.Spec.AccessModes,
 					})
+				} else if volumeName, ok := volumeDiskToName[VolumeDiskExtraConfigValue(disk)]; ok {
+					diskData = append(diskData, VMDiskData{
+						FileName:   b.FileName,
+						VolumeName: volumeName,
+					})
 				}
 			}
 		}
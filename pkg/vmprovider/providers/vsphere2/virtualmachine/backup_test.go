@@ -200,6 +200,58 @@ func backupTests() {
 				verifyBackupDataInExtraConfig(ctx, vcVM, vmopv1.VMBackupDiskDataExtraConfigKey, string(diskDataJSON))
 			})
 		})
+
+		When("VM has disks that are created for ephemeral volumes", func() {
+			const volumeName = "scratch"
+
+			BeforeEach(func() {
+				vmCtx.VM.Spec.Volumes = []vmopv1.VirtualMachineVolume{
+					{
+						Name: volumeName,
+						VirtualMachineVolumeSource: vmopv1.VirtualMachineVolumeSource{
+							Ephemeral: &vmopv1.EphemeralVolumeSource{
+								StorageClass: "dummy-storage-class",
+							},
+						},
+					},
+				}
+
+				devices, err := vcVM.Device(ctx)
+				Expect(err).NotTo(HaveOccurred())
+				disks := devices.SelectByType((*types.VirtualDisk)(nil))
+				Expect(disks).ToNot(BeEmpty())
+
+				_, err = vcVM.Reconfigure(ctx, types.VirtualMachineConfigSpec{
+					ExtraConfig: []types.BaseOptionValue{
+						&types.OptionValue{
+							Key:   virtualmachine.VolumeDiskExtraConfigKey(volumeName),
+							Value: virtualmachine.VolumeDiskExtraConfigValue(disks[0].(*types.VirtualDisk)),
+						},
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("Should backup VM disk data as JSON in ExtraConfig", func() {
+				backupVMCtx := context.BackupVirtualMachineContextA2{
+					VMCtx:         vmCtx,
+					VcVM:          vcVM,
+					BootstrapData: nil,
+					DiskUUIDToPVC: nil,
+				}
+				Expect(virtualmachine.BackupVirtualMachine(backupVMCtx)).To(Succeed())
+
+				diskData := []virtualmachine.VMDiskData{
+					{
+						FileName:   vcSimDiskFileName,
+						VolumeName: volumeName,
+					},
+				}
+				diskDataJSON, err := json.Marshal(diskData)
+				Expect(err).NotTo(HaveOccurred())
+				verifyBackupDataInExtraConfig(ctx, vcVM, vmopv1.VMBackupDiskDataExtraConfigKey, string(diskDataJSON))
+			})
+		})
 	})
 
 	Context("VM cloud-init instance ID data", func() {
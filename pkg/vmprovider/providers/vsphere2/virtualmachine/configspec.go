@@ -24,7 +24,8 @@ func CreateConfigSpec(
 	vmClassConfigSpec *types.VirtualMachineConfigSpec,
 	vmClassSpec *vmopv1.VirtualMachineClassSpec,
 	vmImageStatus *vmopv1.VirtualMachineImageStatus,
-	minFreq uint64) *types.VirtualMachineConfigSpec {
+	minFreq uint64,
+	volumeDiskArgs *VolumeDiskArgs) *types.VirtualMachineConfigSpec {
 
 	configSpec := types.VirtualMachineConfigSpec{}
 
@@ -107,6 +108,14 @@ func CreateConfigSpec(
 		}
 	}
 
+	// Add the disks of the ephemeral and image volumes. These are created as part of the VM,
+	// unlike the PVC volumes that are later attached by CNS.
+	if volumeDiskChanges := CreateVolumeDiskDeviceChanges(vmCtx.VM, volumeDiskArgs); len(volumeDiskChanges) > 0 {
+		deviceChanges := make([]types.BaseVirtualDeviceConfigSpec, 0, len(configSpec.DeviceChange)+len(volumeDiskChanges))
+		deviceChanges = append(deviceChanges, configSpec.DeviceChange...)
+		configSpec.DeviceChange = append(deviceChanges, volumeDiskChanges...)
+	}
+
 	return &configSpec
 }
 
@@ -9,6 +9,7 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	vimtypes "github.com/vmware/govmomi/vim25/types"
+	"k8s.io/apimachinery/pkg/api/resource"
 
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
 	"github.com/vmware-tanzu/vm-operator/pkg/context"
@@ -53,7 +54,8 @@ var _ = Describe("CreateConfigSpec", func() {
 			nil,
 			vmClassSpec,
 			vmImageStatus,
-			minCPUFreq)
+			minCPUFreq,
+			nil)
 
 		Expect(configSpec).ToNot(BeNil())
 		Expect(err).To(BeNil())
@@ -93,7 +95,8 @@ var _ = Describe("CreateConfigSpec", func() {
 				classConfigSpec,
 				vmClassSpec,
 				vmImageStatus,
-				minCPUFreq)
+				minCPUFreq,
+				nil)
 			Expect(configSpec).ToNot(BeNil())
 		})
 
@@ -143,6 +146,107 @@ var _ = Describe("CreateConfigSpec", func() {
 			})
 		})
 	})
+
+	Context("VM has ephemeral and image volumes", func() {
+		var volumeDiskArgs *virtualmachine.VolumeDiskArgs
+
+		BeforeEach(func() {
+			vm.Spec.StorageClass = "vm-storage-class"
+			vm.Spec.Volumes = append(vm.Spec.Volumes,
+				vmopv1.VirtualMachineVolume{
+					Name: "scratch",
+					VirtualMachineVolumeSource: vmopv1.VirtualMachineVolumeSource{
+						Ephemeral: &vmopv1.EphemeralVolumeSource{
+							StorageClass: "scratch-storage-class",
+							Size:         resource.MustParse("10Gi"),
+						},
+					},
+				},
+				vmopv1.VirtualMachineVolume{
+					Name: "data",
+					VirtualMachineVolumeSource: vmopv1.VirtualMachineVolumeSource{
+						Image: &vmopv1.ImageVolumeSource{
+							Name: "data-image",
+						},
+					},
+				},
+			)
+
+			volumeDiskArgs = &virtualmachine.VolumeDiskArgs{
+				StorageClassesToIDs: map[string]string{
+					"vm-storage-class":      "vm-profile-id",
+					"scratch-storage-class": "scratch-profile-id",
+				},
+				ImageDiskPaths: map[string]string{
+					"data": "[datastore1] contentlib-lib/item/disk-0.vmdk",
+				},
+			}
+		})
+
+		JustBeforeEach(func() {
+			configSpec = virtualmachine.CreateConfigSpec(
+				vmCtx,
+				nil,
+				vmClassSpec,
+				vmImageStatus,
+				minCPUFreq,
+				volumeDiskArgs)
+			Expect(configSpec).ToNot(BeNil())
+		})
+
+		It("Adds a disk for each volume", func() {
+			Expect(configSpec.DeviceChange).To(HaveLen(2))
+
+			dSpec := configSpec.DeviceChange[0].GetVirtualDeviceConfigSpec()
+			Expect(dSpec.Operation).To(Equal(vimtypes.VirtualDeviceConfigSpecOperationAdd))
+			Expect(dSpec.FileOperation).To(Equal(vimtypes.VirtualDeviceConfigSpecFileOperationCreate))
+			Expect(dSpec.Profile).To(HaveLen(1))
+			Expect(dSpec.Profile[0].(*vimtypes.VirtualMachineDefinedProfileSpec).ProfileId).To(Equal("scratch-profile-id"))
+			disk, ok := dSpec.Device.(*vimtypes.VirtualDisk)
+			Expect(ok).To(BeTrue())
+			Expect(virtualmachine.IsVolumeDiskDevice(disk)).To(BeTrue())
+			Expect(disk.Key).To(Equal(virtualmachine.VolumeDiskDeviceKey(0)))
+			Expect(disk.CapacityInBytes).To(BeEquivalentTo(10 * 1024 * 1024 * 1024))
+			backing := disk.Backing.(*vimtypes.VirtualDiskFlatVer2BackingInfo)
+			Expect(backing.DiskMode).To(Equal(string(vimtypes.VirtualDiskModePersistent)))
+			Expect(backing.Parent).To(BeNil())
+
+			dSpec = configSpec.DeviceChange[1].GetVirtualDeviceConfigSpec()
+			Expect(dSpec.Operation).To(Equal(vimtypes.VirtualDeviceConfigSpecOperationAdd))
+			Expect(dSpec.FileOperation).To(BeEmpty())
+			Expect(dSpec.Profile).To(HaveLen(1))
+			Expect(dSpec.Profile[0].(*vimtypes.VirtualMachineDefinedProfileSpec).ProfileId).To(Equal("vm-profile-id"))
+			disk, ok = dSpec.Device.(*vimtypes.VirtualDisk)
+			Expect(ok).To(BeTrue())
+			Expect(disk.Key).To(Equal(virtualmachine.VolumeDiskDeviceKey(1)))
+			backing = disk.Backing.(*vimtypes.VirtualDiskFlatVer2BackingInfo)
+			Expect(backing.DiskMode).To(Equal(string(vimtypes.VirtualDiskModeIndependent_nonpersistent)))
+			Expect(backing.Parent).To(BeNil())
+			Expect(backing.FileName).To(Equal("[datastore1] contentlib-lib/item/disk-0.vmdk"))
+		})
+
+		When("the image disk path is not known", func() {
+			BeforeEach(func() {
+				volumeDiskArgs.ImageDiskPaths = nil
+			})
+
+			It("Skips the image volume", func() {
+				Expect(configSpec.DeviceChange).To(HaveLen(1))
+				disk := configSpec.DeviceChange[0].GetVirtualDeviceConfigSpec().Device.(*vimtypes.VirtualDisk)
+				Expect(disk.CapacityInBytes).To(BeEquivalentTo(10 * 1024 * 1024 * 1024))
+			})
+		})
+
+		When("the volume disk args are nil", func() {
+			BeforeEach(func() {
+				volumeDiskArgs = nil
+			})
+
+			It("Does not add any disks", func() {
+				Expect(configSpec.DeviceChange).To(BeEmpty())
+			})
+		})
+	})
 })
 
 var _ = Describe("CreateConfigSpecForPlacement", func() {
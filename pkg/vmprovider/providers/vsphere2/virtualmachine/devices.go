@@ -15,6 +15,7 @@ const (
 	// A negative device range is traditionally used.
 	pciDevicesStartDeviceKey      = int32(-200)
 	instanceStorageStartDeviceKey = int32(-300)
+	volumeDiskStartDeviceKey      = int32(-400)
 )
 
 func CreatePCIPassThroughDevice(deviceKey int32, backingInfo vimTypes.BaseVirtualDeviceBackingInfo) vimTypes.BaseVirtualDevice {
@@ -0,0 +1,549 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package virtualmachine
+
+import (
+	"archive/tar"
+	goctx "context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vmware/govmomi/nfc"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/ovf"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+)
+
+const (
+	// ovaFileMode is the mode of the files in an OVA.
+	ovaFileMode = 0644
+
+	// tarBlockSize is the size of the blocks of a tar archive.
+	tarBlockSize = 512
+
+	// leaseProgressInterval is how often the progress of the export is
+	// reported to the lease, which also keeps the lease from timing out.
+	leaseProgressInterval = 2 * time.Second
+)
+
+// ExportTarget is where the files of an exported VM are written.
+type ExportTarget interface {
+	// WriteFile writes the file with the given name and size in bytes. The
+	// size is -1 when it is not known in advance.
+	WriteFile(ctx goctx.Context, name string, size int64, r io.Reader) error
+}
+
+// ExportArgs are the arguments used to export a VM.
+type ExportArgs struct {
+	// Name is the base name of the exported files.
+	Name string
+	// Format is the format the VM is exported to.
+	Format vmopv1.VirtualMachineExportFormat
+	// Target is where the exported files are written.
+	Target ExportTarget
+	// Progress, if not nil, is called with the estimated percentage of the
+	// export that is complete.
+	Progress func(percent int32)
+	// Written are the files that an earlier attempt of the export wrote to
+	// the target. The disks of an OVF among them are not written again.
+	Written []vmopv1.VirtualMachineExportFile
+	// FileWritten, if not nil, is called with each file of an OVF once it
+	// has been written to the target.
+	FileWritten func(file vmopv1.VirtualMachineExportFile)
+}
+
+// ExportVirtualMachine exports the VM with the HttpNfcLease export flow and
+// streams the OVF descriptor, the manifest and the disks, or an OVA containing
+// them, to the target. It returns the exported files and the manifest.
+//
+// Nothing is written to local storage. For an OVF, each disk is streamed from
+// the lease to the target, and the descriptor and manifest are written once
+// the sizes and checksums of the disks are known. An OVA, however, must start
+// with the descriptor and each file in it must be preceded by its size, so the
+// disks are first read from the lease to determine their sizes and checksums,
+// and then read again while the OVA is streamed to the target.
+func ExportVirtualMachine(
+	vmCtx context.VirtualMachineContextA2,
+	vimClient *vim25.Client,
+	vcVM *object.VirtualMachine,
+	args ExportArgs) (_ []vmopv1.VirtualMachineExportFile, _ string, reterr error) {
+
+	lease, err := vcVM.Export(vmCtx)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to export VM: %w", err)
+	}
+
+	info, err := lease.Wait(vmCtx, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to wait for export lease: %w", err)
+	}
+
+	defer func() {
+		if reterr != nil {
+			fault := &types.LocalizedMethodFault{LocalizedMessage: reterr.Error()}
+			if err := lease.Abort(vmCtx, fault); err != nil {
+				vmCtx.Logger.Error(err, "Failed to abort export lease")
+			}
+		}
+	}()
+
+	progress := &exportProgress{fn: args.Progress}
+	stopProgress := reportLeaseProgress(vmCtx, lease, progress)
+
+	e := &exporter{
+		vmCtx:     vmCtx,
+		vimClient: vimClient,
+		vcVM:      vcVM,
+		args:      args,
+		items:     info.Items,
+		progress:  progress,
+	}
+
+	var files []vmopv1.VirtualMachineExportFile
+	var manifest string
+	if args.Format == vmopv1.VirtualMachineExportFormatOVA {
+		files, manifest, err = e.exportOVA()
+	} else {
+		files, manifest, err = e.exportOVF()
+	}
+	stopProgress()
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := lease.Complete(vmCtx); err != nil {
+		return nil, "", fmt.Errorf("failed to complete export lease: %w", err)
+	}
+
+	progress.set(100)
+	return files, manifest, nil
+}
+
+// reportLeaseProgress periodically reports the progress of the export to the
+// lease until the returned function is called.
+func reportLeaseProgress(
+	vmCtx context.VirtualMachineContextA2,
+	lease *nfc.Lease,
+	progress *exportProgress) func() {
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(leaseProgressInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := lease.Progress(vmCtx, progress.percent()); err != nil {
+					vmCtx.Logger.Error(err, "Failed to report export lease progress")
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+type exporter struct {
+	vmCtx     context.VirtualMachineContextA2
+	vimClient *vim25.Client
+	vcVM      *object.VirtualMachine
+	args      ExportArgs
+	items     []nfc.FileItem
+	progress  *exportProgress
+}
+
+// exportOVF streams each disk to the target, and then writes the descriptor
+// and the manifest. A disk that an earlier attempt of the export wrote is not
+// written again.
+func (e *exporter) exportOVF() ([]vmopv1.VirtualMachineExportFile, string, error) {
+	var total int64
+	for _, item := range e.items {
+		total += item.Size
+	}
+	e.progress.setTotal(total)
+
+	written := make(map[string]vmopv1.VirtualMachineExportFile, len(e.args.Written))
+	for _, file := range e.args.Written {
+		written[file.Name] = file
+	}
+
+	diskFiles := make([]vmopv1.VirtualMachineExportFile, 0, len(e.items))
+	for _, item := range e.items {
+		name := filepath.Base(item.Path)
+		if file, ok := written[name]; ok {
+			e.vmCtx.Logger.V(4).Info("Skipping disk written by an earlier attempt", "name", name)
+			e.progress.add(item.Size)
+			diskFiles = append(diskFiles, file)
+			continue
+		}
+
+		e.vmCtx.Logger.V(4).Info("Streaming exported disk to target", "name", name, "url", item.URL.String())
+
+		file, err := e.readDisk(item, func(size int64, r io.Reader) error {
+			return e.args.Target.WriteFile(e.vmCtx, name, size, r)
+		})
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to write disk %s to target: %w", name, err)
+		}
+		diskFiles = append(diskFiles, file)
+		e.fileWritten(file)
+	}
+
+	ovfFile, descriptor, err := e.createDescriptor(diskFiles)
+	if err != nil {
+		return nil, "", err
+	}
+
+	manifest := ExportManifest(append([]vmopv1.VirtualMachineExportFile{ovfFile}, diskFiles...))
+	mfFile := newExportFile(e.args.Name+".mf", manifest)
+
+	for _, f := range []struct {
+		file    vmopv1.VirtualMachineExportFile
+		content string
+	}{{ovfFile, descriptor}, {mfFile, manifest}} {
+		if err := e.args.Target.WriteFile(e.vmCtx, f.file.Name, f.file.Size, strings.NewReader(f.content)); err != nil {
+			return nil, "", fmt.Errorf("failed to write %s to target: %w", f.file.Name, err)
+		}
+		e.fileWritten(f.file)
+	}
+
+	return append([]vmopv1.VirtualMachineExportFile{ovfFile, mfFile}, diskFiles...), manifest, nil
+}
+
+// exportOVA reads the disks to determine their sizes and checksums, and then
+// streams the OVA to the target, reading the disks again.
+func (e *exporter) exportOVA() ([]vmopv1.VirtualMachineExportFile, string, error) {
+	var total int64
+	for _, item := range e.items {
+		total += item.Size
+	}
+	// The disks are read twice.
+	e.progress.setTotal(2 * total)
+
+	diskFiles := make([]vmopv1.VirtualMachineExportFile, 0, len(e.items))
+	diskItems := make(map[string]nfc.FileItem, len(e.items))
+	for _, item := range e.items {
+		name := filepath.Base(item.Path)
+		e.vmCtx.Logger.V(4).Info("Reading exported disk", "name", name, "url", item.URL.String())
+
+		file, err := e.readDisk(item, func(_ int64, r io.Reader) error {
+			_, err := io.Copy(io.Discard, r)
+			return err
+		})
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read disk %s: %w", name, err)
+		}
+		diskFiles = append(diskFiles, file)
+		diskItems[name] = item
+	}
+
+	ovfFile, descriptor, err := e.createDescriptor(diskFiles)
+	if err != nil {
+		return nil, "", err
+	}
+
+	manifest := ExportManifest(append([]vmopv1.VirtualMachineExportFile{ovfFile}, diskFiles...))
+	mfFile := newExportFile(e.args.Name+".mf", manifest)
+
+	// The descriptor must be the first file in an OVA, optionally followed by
+	// the manifest.
+	files := append([]vmopv1.VirtualMachineExportFile{ovfFile, mfFile}, diskFiles...)
+	ovaSize := OVASize(files)
+	e.progress.setTotal(e.progress.current() + ovaSize)
+
+	open := func(file vmopv1.VirtualMachineExportFile) (io.ReadCloser, error) {
+		switch file.Name {
+		case ovfFile.Name:
+			return io.NopCloser(strings.NewReader(descriptor)), nil
+		case mfFile.Name:
+			return io.NopCloser(strings.NewReader(manifest)), nil
+		}
+		return e.openDisk(diskItems[file.Name], file)
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(WriteOVA(pw, files, open))
+	}()
+
+	ovaName := e.args.Name + ".ova"
+	e.vmCtx.Logger.V(4).Info("Streaming OVA to target", "name", ovaName, "size", ovaSize)
+
+	err = e.args.Target.WriteFile(e.vmCtx, ovaName, ovaSize, &countingReader{Reader: pr, fn: e.progress.add})
+	// Stop the OVA from being written if the target returned early.
+	_ = pr.CloseWithError(io.ErrClosedPipe)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to write %s to target: %w", ovaName, err)
+	}
+
+	return files, manifest, nil
+}
+
+func (e *exporter) fileWritten(file vmopv1.VirtualMachineExportFile) {
+	if e.args.FileWritten != nil {
+		e.args.FileWritten(file)
+	}
+}
+
+// readDisk downloads the disk from the lease, calls fn with its content, and
+// returns the disk's size and checksum.
+func (e *exporter) readDisk(
+	item nfc.FileItem,
+	fn func(size int64, r io.Reader) error) (vmopv1.VirtualMachineExportFile, error) {
+
+	rc, size, err := e.vimClient.Download(e.vmCtx, item.URL, &soap.DefaultDownload)
+	if err != nil {
+		return vmopv1.VirtualMachineExportFile{}, err
+	}
+	defer rc.Close()
+
+	r := newChecksumReader(&countingReader{Reader: rc, fn: e.progress.add})
+	if err := fn(size, r); err != nil {
+		return vmopv1.VirtualMachineExportFile{}, err
+	}
+
+	return vmopv1.VirtualMachineExportFile{
+		Name:     filepath.Base(item.Path),
+		Size:     r.n,
+		Checksum: r.checksum(),
+	}, nil
+}
+
+// openDisk downloads the disk from the lease again, failing the read if its
+// content differs from when the disk was first read.
+func (e *exporter) openDisk(item nfc.FileItem, file vmopv1.VirtualMachineExportFile) (io.ReadCloser, error) {
+	rc, _, err := e.vimClient.Download(e.vmCtx, item.URL, &soap.DefaultDownload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download disk %s: %w", file.Name, err)
+	}
+
+	return &verifyingReadCloser{
+		ReadCloser: rc,
+		r:          newChecksumReader(rc),
+		file:       file,
+	}, nil
+}
+
+// createDescriptor creates the OVF descriptor for the VM and its disks.
+func (e *exporter) createDescriptor(diskFiles []vmopv1.VirtualMachineExportFile) (vmopv1.VirtualMachineExportFile, string, error) {
+	ovfFiles := make([]types.OvfFile, 0, len(e.items))
+	for i, item := range e.items {
+		ovfFile := item.File()
+		ovfFile.Size = diskFiles[i].Size
+		ovfFiles = append(ovfFiles, ovfFile)
+	}
+
+	cdp := types.OvfCreateDescriptorParams{
+		Name:     e.args.Name,
+		OvfFiles: ovfFiles,
+	}
+	res, err := ovf.NewManager(e.vimClient).CreateDescriptor(e.vmCtx, e.vcVM, cdp)
+	if err != nil {
+		return vmopv1.VirtualMachineExportFile{}, "", fmt.Errorf("failed to create OVF descriptor: %w", err)
+	}
+	if res.Error != nil {
+		return vmopv1.VirtualMachineExportFile{}, "", fmt.Errorf("failed to create OVF descriptor: %s", res.Error[0].LocalizedMessage)
+	}
+
+	return newExportFile(e.args.Name+".ovf", res.OvfDescriptor), res.OvfDescriptor, nil
+}
+
+// ExportManifest returns the OVF manifest of the files.
+func ExportManifest(files []vmopv1.VirtualMachineExportFile) string {
+	var sb strings.Builder
+	for _, file := range files {
+		fmt.Fprintf(&sb, "SHA256(%s)= %s\n", file.Name, file.Checksum)
+	}
+	return sb.String()
+}
+
+// OVASize returns the size of the OVA containing the files.
+func OVASize(files []vmopv1.VirtualMachineExportFile) int64 {
+	var size int64
+	for _, file := range files {
+		// Each file is preceded by a header block and padded to a full block.
+		size += tarBlockSize + (file.Size+tarBlockSize-1)/tarBlockSize*tarBlockSize
+	}
+	// The archive ends with two zero blocks.
+	return size + 2*tarBlockSize
+}
+
+// WriteOVA writes the files, in order, to an OVA. The content of each file is
+// read from the reader returned by open.
+func WriteOVA(
+	w io.Writer,
+	files []vmopv1.VirtualMachineExportFile,
+	open func(file vmopv1.VirtualMachineExportFile) (io.ReadCloser, error)) error {
+
+	tw := tar.NewWriter(w)
+	for _, file := range files {
+		// The GNU format encodes the size of disks larger than 8GiB in the
+		// header block itself, so every file has a single header block.
+		hdr := &tar.Header{
+			Name:   file.Name,
+			Mode:   ovaFileMode,
+			Size:   file.Size,
+			Format: tar.FormatGNU,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+
+		rc, err := open(file)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(tw, rc)
+		_ = rc.Close()
+		if err != nil {
+			return fmt.Errorf("failed to write %s to OVA: %w", file.Name, err)
+		}
+	}
+
+	return tw.Close()
+}
+
+func newExportFile(name, content string) vmopv1.VirtualMachineExportFile {
+	h := sha256.Sum256([]byte(content))
+	return vmopv1.VirtualMachineExportFile{
+		Name:     name,
+		Size:     int64(len(content)),
+		Checksum: hex.EncodeToString(h[:]),
+	}
+}
+
+// exportProgress estimates the percentage of an export that is complete from
+// the bytes read from the lease.
+type exportProgress struct {
+	sync.Mutex
+	fn func(percent int32)
+
+	total, done int64
+	last        int32
+}
+
+func (p *exportProgress) setTotal(total int64) {
+	p.Lock()
+	defer p.Unlock()
+	p.total = total
+	p.update()
+}
+
+func (p *exportProgress) add(n int64) {
+	p.Lock()
+	defer p.Unlock()
+	p.done += n
+	p.update()
+}
+
+func (p *exportProgress) current() int64 {
+	p.Lock()
+	defer p.Unlock()
+	return p.done
+}
+
+func (p *exportProgress) percent() int32 {
+	p.Lock()
+	defer p.Unlock()
+	return p.last
+}
+
+func (p *exportProgress) set(percent int32) {
+	p.Lock()
+	defer p.Unlock()
+	p.report(percent)
+}
+
+func (p *exportProgress) update() {
+	if p.total <= 0 {
+		return
+	}
+	// The size of the lease items is an estimate, so the bytes read may
+	// exceed it. Only the final step reports the export as complete.
+	p.report(int32(min(p.done*100/p.total, 99)))
+}
+
+func (p *exportProgress) report(percent int32) {
+	if percent != p.last {
+		p.last = percent
+		if p.fn != nil {
+			p.fn(percent)
+		}
+	}
+}
+
+// countingReader calls fn with the number of bytes of each read.
+type countingReader struct {
+	io.Reader
+	fn func(n int64)
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if n > 0 {
+		r.fn(int64(n))
+	}
+	return n, err
+}
+
+// checksumReader computes the size and SHA256 checksum of what is read.
+type checksumReader struct {
+	io.Reader
+	h hash.Hash
+	n int64
+}
+
+func newChecksumReader(r io.Reader) *checksumReader {
+	h := sha256.New()
+	return &checksumReader{Reader: io.TeeReader(r, h), h: h}
+}
+
+func (r *checksumReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+func (r *checksumReader) checksum() string {
+	return hex.EncodeToString(r.h.Sum(nil))
+}
+
+// verifyingReadCloser returns an error at the end of the file if its size or
+// checksum differ from the expected file.
+type verifyingReadCloser struct {
+	io.ReadCloser
+	r    *checksumReader
+	file vmopv1.VirtualMachineExportFile
+}
+
+func (r *verifyingReadCloser) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if err == io.EOF && (r.r.n != r.file.Size || r.r.checksum() != r.file.Checksum) {
+		return n, fmt.Errorf("disk %s changed while it was exported", r.file.Name)
+	}
+	return n, err
+}
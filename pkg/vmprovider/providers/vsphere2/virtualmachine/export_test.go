@@ -0,0 +1,93 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package virtualmachine_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere2/virtualmachine"
+)
+
+func exportTests() {
+	files := []vmopv1.VirtualMachineExportFile{
+		{Name: "my-vm.ovf", Size: 5, Checksum: "aaa"},
+		{Name: "my-vm.mf", Size: 3, Checksum: "bbb"},
+		{Name: "my-vm-disk-0.vmdk", Size: 4, Checksum: "ccc"},
+	}
+
+	Context("ExportManifest", func() {
+		It("returns a SHA256 line for each file", func() {
+			Expect(virtualmachine.ExportManifest(files)).To(Equal(
+				"SHA256(my-vm.ovf)= aaa\n" +
+					"SHA256(my-vm.mf)= bbb\n" +
+					"SHA256(my-vm-disk-0.vmdk)= ccc\n"))
+		})
+	})
+
+	Context("WriteOVA", func() {
+		contents := map[string]string{
+			"my-vm.ovf":         "<ovf>",
+			"my-vm.mf":          "mf\n",
+			"my-vm-disk-0.vmdk": "disk",
+		}
+
+		open := func(file vmopv1.VirtualMachineExportFile) (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader(contents[file.Name])), nil
+		}
+
+		It("writes the files in order to a tar archive of the expected size", func() {
+			var buf bytes.Buffer
+			Expect(virtualmachine.WriteOVA(&buf, files, open)).To(Succeed())
+			Expect(int64(buf.Len())).To(Equal(virtualmachine.OVASize(files)))
+
+			var names, fileContents []string
+			tr := tar.NewReader(&buf)
+			for {
+				hdr, err := tr.Next()
+				if err == io.EOF {
+					break
+				}
+				Expect(err).ToNot(HaveOccurred())
+				b, err := io.ReadAll(tr)
+				Expect(err).ToNot(HaveOccurred())
+				names = append(names, hdr.Name)
+				fileContents = append(fileContents, string(b))
+			}
+
+			Expect(names).To(Equal([]string{"my-vm.ovf", "my-vm.mf", "my-vm-disk-0.vmdk"}))
+			Expect(fileContents).To(Equal([]string{"<ovf>", "mf\n", "disk"}))
+		})
+
+		It("fails when a file is shorter than its size", func() {
+			contents["my-vm-disk-0.vmdk"] = "dis"
+			defer func() { contents["my-vm-disk-0.vmdk"] = "disk" }()
+
+			Expect(virtualmachine.WriteOVA(io.Discard, files, open)).ToNot(Succeed())
+		})
+	})
+
+	Context("OVASize", func() {
+		It("accounts for the headers, padding and end of the archive", func() {
+			Expect(virtualmachine.OVASize(files)).To(BeEquivalentTo(3*512 + 3*512 + 2*512))
+		})
+
+		It("does not add header blocks for files larger than 8GiB", func() {
+			large := []vmopv1.VirtualMachineExportFile{{Name: "my-vm-disk-0.vmdk", Size: 10 * 1024 * 1024 * 1024}}
+			Expect(virtualmachine.OVASize(large)).To(BeEquivalentTo(512 + 10*1024*1024*1024 + 2*512))
+
+			hdr := &tar.Header{Name: "my-vm-disk-0.vmdk", Mode: 0644, Size: large[0].Size, Format: tar.FormatGNU}
+			var buf bytes.Buffer
+			tw := tar.NewWriter(&buf)
+			Expect(tw.WriteHeader(hdr)).To(Succeed())
+			Expect(buf.Len()).To(Equal(512))
+		})
+	})
+}
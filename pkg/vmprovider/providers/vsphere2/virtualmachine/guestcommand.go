@@ -0,0 +1,227 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package virtualmachine
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/vmware/govmomi/guest"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+)
+
+const (
+	// guestCommandPollInterval is how often the guest is polled for whether
+	// the command has exited.
+	guestCommandPollInterval = time.Second
+
+	windowsCmdPath = `C:\Windows\System32\cmd.exe`
+)
+
+// GuestCommandFile is a file that is uploaded into the guest before the
+// command is run.
+type GuestCommandFile struct {
+	// Path is the absolute path in the guest the file is written to.
+	Path string
+	// Data is the content of the file.
+	Data []byte
+}
+
+// GuestCommandArgs describes a command run in the guest.
+type GuestCommandArgs struct {
+	// Auth is the guest account the command is run as.
+	Auth types.BaseGuestAuthentication
+	// Command is the absolute path in the guest of the program to run.
+	Command string
+	// Args is the list of arguments passed to the command.
+	Args []string
+	// Env is the list of environment variables, in the NAME=value form, set
+	// for the command.
+	Env []string
+	// WorkingDir is the directory in the guest the command is run in.
+	WorkingDir string
+	// Files are uploaded into the guest before the command is run.
+	Files []GuestCommandFile
+	// Timeout is how long the command is allowed to run before it is
+	// terminated.
+	Timeout time.Duration
+	// MaxOutputBytes is the maximum size of the tail of the standard output
+	// and standard error of the command that is returned.
+	MaxOutputBytes int64
+}
+
+// GuestCommandResult is the result of a command run in the guest.
+type GuestCommandResult struct {
+	ExitCode        int32
+	Stdout          string
+	StdoutTruncated bool
+	Stderr          string
+	StderrTruncated bool
+	// TimedOut is true when the command was terminated because it did not
+	// exit before the timeout.
+	TimedOut bool
+}
+
+// RunGuestCommand uploads the files into the guest and runs the command with
+// the guest operations of VMware Tools. The standard output and standard
+// error of the command are redirected to temporary files in the guest that
+// are downloaded once the command exits.
+func RunGuestCommand(
+	vmCtx context.VirtualMachineContextA2,
+	vm *object.VirtualMachine,
+	args GuestCommandArgs) (GuestCommandResult, error) {
+
+	var result GuestCommandResult
+
+	var o mo.VirtualMachine
+	if err := vm.Properties(vmCtx, vm.Reference(), []string{"guest.guestFamily"}, &o); err != nil {
+		return result, err
+	}
+	var guestFamily string
+	if o.Guest != nil {
+		guestFamily = o.Guest.GuestFamily
+	}
+
+	opsMgr := guest.NewOperationsManager(vm.Client(), vm.Reference())
+	fileMgr, err := opsMgr.FileManager(vmCtx)
+	if err != nil {
+		return result, err
+	}
+	procMgr, err := opsMgr.ProcessManager(vmCtx)
+	if err != nil {
+		return result, err
+	}
+
+	for _, file := range args.Files {
+		if err := writeGuestFile(vmCtx, vm, fileMgr, args.Auth, file.Path, file.Data); err != nil {
+			return result, err
+		}
+	}
+
+	stdoutPath, err := fileMgr.CreateTemporaryFile(vmCtx, args.Auth, "vmop-", ".stdout", "")
+	if err != nil {
+		return result, fmt.Errorf("failed to create guest file for stdout: %w", err)
+	}
+	defer deleteGuestFile(vmCtx, fileMgr, args.Auth, stdoutPath)
+
+	stderrPath, err := fileMgr.CreateTemporaryFile(vmCtx, args.Auth, "vmop-", ".stderr", "")
+	if err != nil {
+		return result, fmt.Errorf("failed to create guest file for stderr: %w", err)
+	}
+	defer deleteGuestFile(vmCtx, fileMgr, args.Auth, stderrPath)
+
+	spec := GuestCommandProgramSpec(guestFamily, args, stdoutPath, stderrPath)
+	pid, err := procMgr.StartProgram(vmCtx, args.Auth, spec)
+	if err != nil {
+		return result, fmt.Errorf("failed to start guest command: %w", err)
+	}
+	vmCtx.Logger.Info("Started guest command", "pid", pid)
+
+	timeout := time.NewTimer(args.Timeout)
+	defer timeout.Stop()
+	poll := time.NewTicker(guestCommandPollInterval)
+	defer poll.Stop()
+
+	for exited := false; !exited; {
+		select {
+		case <-vmCtx.Done():
+			return result, vmCtx.Err()
+		case <-timeout.C:
+			vmCtx.Logger.Info("Terminating guest command that timed out", "pid", pid)
+			if err := procMgr.TerminateProcess(vmCtx, args.Auth, pid); err != nil {
+				return result, fmt.Errorf("failed to terminate guest command: %w", err)
+			}
+			result.TimedOut = true
+			exited = true
+		case <-poll.C:
+			procs, err := procMgr.ListProcesses(vmCtx, args.Auth, []int64{pid})
+			if err != nil {
+				return result, err
+			}
+			if len(procs) == 0 {
+				return result, fmt.Errorf("guest command process %d not found", pid)
+			}
+			if procs[0].EndTime != nil {
+				result.ExitCode = procs[0].ExitCode
+				exited = true
+			}
+		}
+	}
+
+	result.Stdout, result.StdoutTruncated, err = readGuestFileTail(
+		vmCtx, vm, fileMgr, args.Auth, stdoutPath, args.MaxOutputBytes)
+	if err != nil {
+		return result, err
+	}
+
+	result.Stderr, result.StderrTruncated, err = readGuestFileTail(
+		vmCtx, vm, fileMgr, args.Auth, stderrPath, args.MaxOutputBytes)
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// GuestCommandProgramSpec returns the spec of the program that runs the
+// command with its standard output and standard error redirected to the given
+// guest files. The command is run with cmd.exe in Windows guests, and with a
+// shell in other guests, for the redirection.
+func GuestCommandProgramSpec(
+	guestFamily string,
+	args GuestCommandArgs,
+	stdoutPath, stderrPath string) *types.GuestProgramSpec {
+
+	spec := &types.GuestProgramSpec{
+		EnvVariables:     args.Env,
+		WorkingDirectory: args.WorkingDir,
+	}
+
+	if guestFamily == string(types.VirtualMachineGuestOsFamilyWindowsGuest) {
+		words := make([]string, 0, len(args.Args)+1)
+		for _, arg := range append([]string{args.Command}, args.Args...) {
+			words = append(words, quoteWindowsArg(arg))
+		}
+		spec.ProgramPath = windowsCmdPath
+		spec.Arguments = fmt.Sprintf(`/c "%s 1>%s 2>%s"`,
+			strings.Join(words, " "), quoteWindowsArg(stdoutPath), quoteWindowsArg(stderrPath))
+		return spec
+	}
+
+	words := make([]string, 0, len(args.Args))
+	for _, arg := range args.Args {
+		words = append(words, quotePosixArg(arg))
+	}
+	words = append(words, "1>"+quotePosixArg(stdoutPath), "2>"+quotePosixArg(stderrPath))
+	spec.ProgramPath = args.Command
+	spec.Arguments = strings.Join(words, " ")
+	return spec
+}
+
+func quotePosixArg(arg string) string {
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+func quoteWindowsArg(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, " \t\"") {
+		return arg
+	}
+	return `"` + strings.ReplaceAll(arg, `"`, `\"`) + `"`
+}
+
+func deleteGuestFile(
+	vmCtx context.VirtualMachineContextA2,
+	fileMgr *guest.FileManager,
+	auth types.BaseGuestAuthentication,
+	guestFilePath string) {
+
+	if err := fileMgr.DeleteFile(vmCtx, auth, guestFilePath); err != nil {
+		vmCtx.Logger.Error(err, "Failed to delete guest file", "path", guestFilePath)
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package virtualmachine_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware/govmomi/vim25/types"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere2/virtualmachine"
+)
+
+var _ = Describe("GuestCommandProgramSpec", func() {
+	var (
+		args virtualmachine.GuestCommandArgs
+	)
+
+	BeforeEach(func() {
+		args = virtualmachine.GuestCommandArgs{
+			Command:    "/bin/echo",
+			Args:       []string{"hello world", "it's"},
+			Env:        []string{"FOO=bar"},
+			WorkingDir: "/tmp",
+		}
+	})
+
+	It("redirects the output of the command in a Linux guest", func() {
+		spec := virtualmachine.GuestCommandProgramSpec(
+			string(types.VirtualMachineGuestOsFamilyLinuxGuest), args, "/tmp/out", "/tmp/err")
+
+		Expect(spec.ProgramPath).To(Equal("/bin/echo"))
+		Expect(spec.Arguments).To(Equal(`'hello world' 'it'\''s' 1>'/tmp/out' 2>'/tmp/err'`))
+		Expect(spec.EnvVariables).To(Equal([]string{"FOO=bar"}))
+		Expect(spec.WorkingDirectory).To(Equal("/tmp"))
+	})
+
+	It("runs the command with cmd.exe in a Windows guest", func() {
+		args.Command = `C:\Windows\System32\whoami.exe`
+		args.Args = []string{"/all", `C:\Program Files`}
+
+		spec := virtualmachine.GuestCommandProgramSpec(
+			string(types.VirtualMachineGuestOsFamilyWindowsGuest), args, `C:\Temp\out`, `C:\Temp\err`)
+
+		Expect(spec.ProgramPath).To(Equal(`C:\Windows\System32\cmd.exe`))
+		Expect(spec.Arguments).To(Equal(`/c "C:\Windows\System32\whoami.exe /all "C:\Program Files" 1>C:\Temp\out 2>C:\Temp\err"`))
+	})
+})
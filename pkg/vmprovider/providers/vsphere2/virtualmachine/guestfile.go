@@ -0,0 +1,110 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package virtualmachine
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/vmware/govmomi/guest"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// ReadGuestFileTail returns up to the last maxBytes of the file at the given
+// path in the VM's guest. The file is transferred out of the guest with the
+// guest operations of VMware Tools so VMware Tools must be running in the
+// guest.
+func ReadGuestFileTail(
+	ctx context.Context,
+	vm *object.VirtualMachine,
+	auth types.BaseGuestAuthentication,
+	guestFilePath string,
+	maxBytes int64) (string, error) {
+
+	opsMgr := guest.NewOperationsManager(vm.Client(), vm.Reference())
+	fileMgr, err := opsMgr.FileManager(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	data, _, err := readGuestFileTail(ctx, vm, fileMgr, auth, guestFilePath, maxBytes)
+	return data, err
+}
+
+// readGuestFileTail returns up to the last maxBytes of the guest file, and
+// whether the file was larger than maxBytes.
+func readGuestFileTail(
+	ctx context.Context,
+	vm *object.VirtualMachine,
+	fileMgr *guest.FileManager,
+	auth types.BaseGuestAuthentication,
+	guestFilePath string,
+	maxBytes int64) (string, bool, error) {
+
+	info, err := fileMgr.InitiateFileTransferFromGuest(ctx, auth, guestFilePath)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to initiate transfer of guest file %s: %w", guestFilePath, err)
+	}
+
+	u, err := fileMgr.TransferURL(ctx, info.Url)
+	if err != nil {
+		return "", false, err
+	}
+
+	rc, _, err := vm.Client().Download(ctx, u, &soap.DefaultDownload)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to download guest file %s: %w", guestFilePath, err)
+	}
+	defer rc.Close()
+
+	truncated := false
+	if skip := info.Size - maxBytes; skip > 0 {
+		if _, err := io.CopyN(io.Discard, rc, skip); err != nil {
+			return "", false, err
+		}
+		truncated = true
+	}
+
+	data, err := io.ReadAll(io.LimitReader(rc, maxBytes))
+	if err != nil {
+		return "", false, err
+	}
+
+	return string(data), truncated, nil
+}
+
+// writeGuestFile writes the data to the file at the given path in the guest,
+// overwriting an existing file.
+func writeGuestFile(
+	ctx context.Context,
+	vm *object.VirtualMachine,
+	fileMgr *guest.FileManager,
+	auth types.BaseGuestAuthentication,
+	guestFilePath string,
+	data []byte) error {
+
+	size := int64(len(data))
+	transferURL, err := fileMgr.InitiateFileTransferToGuest(
+		ctx, auth, guestFilePath, &types.GuestFileAttributes{}, size, true)
+	if err != nil {
+		return fmt.Errorf("failed to initiate transfer of guest file %s: %w", guestFilePath, err)
+	}
+
+	u, err := fileMgr.TransferURL(ctx, transferURL)
+	if err != nil {
+		return err
+	}
+
+	p := soap.DefaultUpload
+	p.ContentLength = size
+	if err := vm.Client().Upload(ctx, bytes.NewReader(data), u, &p); err != nil {
+		return fmt.Errorf("failed to upload guest file %s: %w", guestFilePath, err)
+	}
+
+	return nil
+}
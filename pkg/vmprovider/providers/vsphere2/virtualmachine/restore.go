@@ -0,0 +1,72 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package virtualmachine
+
+import (
+	"encoding/json"
+	"fmt"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	"github.com/vmware-tanzu/vm-operator/pkg/util"
+)
+
+// VMBackupData is the data of a VM that was backed up into its ExtraConfig
+// by BackupVirtualMachine.
+type VMBackupData struct {
+	// VM is the Kubernetes VirtualMachine object.
+	VM *vmopv1.VirtualMachine
+	// BootstrapData is the VM bootstrap data.
+	BootstrapData map[string]string
+	// DiskData is the data of the disks attached by PVCs or created for ephemeral and image volumes.
+	DiskData []VMDiskData
+	// CloudInitInstanceID is the VM's Cloud-Init instance ID.
+	CloudInitInstanceID string
+}
+
+// GetBackupData returns the backup data from the ExtraConfig of a VM. The
+// returned VM is nil if the ExtraConfig does not contain the VM's kube data.
+func GetBackupData(ecMap map[string]string) (VMBackupData, error) {
+	var backup VMBackupData
+
+	ecKubeData, ok := ecMap[vmopv1.VMBackupKubeDataExtraConfigKey]
+	if !ok {
+		return backup, nil
+	}
+
+	vmObj, err := constructVMObj(ecKubeData)
+	if err != nil {
+		return backup, fmt.Errorf("failed to decode VM kube data: %w", err)
+	}
+	backup.VM = &vmObj
+
+	if ecInstanceID, ok := ecMap[vmopv1.VMBackupCloudInitInstanceIDExtraConfigKey]; ok {
+		instanceID, err := util.TryToDecodeBase64Gzip([]byte(ecInstanceID))
+		if err != nil {
+			return backup, fmt.Errorf("failed to decode cloud-init instance ID: %w", err)
+		}
+		backup.CloudInitInstanceID = instanceID
+	}
+
+	if ecBootstrapData, ok := ecMap[vmopv1.VMBackupBootstrapDataExtraConfigKey]; ok {
+		bootstrapDataJSON, err := util.TryToDecodeBase64Gzip([]byte(ecBootstrapData))
+		if err != nil {
+			return backup, fmt.Errorf("failed to decode VM bootstrap data: %w", err)
+		}
+		if err := json.Unmarshal([]byte(bootstrapDataJSON), &backup.BootstrapData); err != nil {
+			return backup, fmt.Errorf("failed to unmarshal VM bootstrap data: %w", err)
+		}
+	}
+
+	if ecDiskData, ok := ecMap[vmopv1.VMBackupDiskDataExtraConfigKey]; ok {
+		diskDataJSON, err := util.TryToDecodeBase64Gzip([]byte(ecDiskData))
+		if err != nil {
+			return backup, fmt.Errorf("failed to decode VM disk data: %w", err)
+		}
+		if err := json.Unmarshal([]byte(diskDataJSON), &backup.DiskData); err != nil {
+			return backup, fmt.Errorf("failed to unmarshal VM disk data: %w", err)
+		}
+	}
+
+	return backup, nil
+}
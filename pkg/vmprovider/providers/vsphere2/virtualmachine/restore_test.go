@@ -0,0 +1,111 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package virtualmachine_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/util"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere2/virtualmachine"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func restoreTests() {
+	const (
+		vcSimDiskUUID     = "be8d2471-f32e-5c7e-a89b-22cb8e533890"
+		vcSimDiskFileName = "[LocalDS_0] DC0_C0_RP0_VM0/disk1.vmdk"
+	)
+
+	var (
+		ctx   *builder.TestContextForVCSim
+		vcVM  *object.VirtualMachine
+		vmCtx context.VirtualMachineContextA2
+	)
+
+	getExtraConfig := func() map[string]string {
+		var moVM mo.VirtualMachine
+		Expect(vcVM.Properties(ctx, vcVM.Reference(), []string{"config.extraConfig"}, &moVM)).To(Succeed())
+		return util.ExtraConfigToMap(moVM.Config.ExtraConfig)
+	}
+
+	BeforeEach(func() {
+		ctx = suite.NewTestContextForVCSim(builder.VCSimTestConfig{})
+
+		var err error
+		vcVM, err = ctx.Finder.VirtualMachine(ctx, "DC0_C0_RP0_VM0")
+		Expect(err).NotTo(HaveOccurred())
+
+		vmCtx = context.VirtualMachineContextA2{
+			Context: ctx,
+			Logger:  suite.GetLogger().WithValues("vmName", vcVM.Name()),
+			VM: &vmopv1.VirtualMachine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-vm",
+					Namespace: "my-namespace",
+					UID:       "my-vm-uid",
+				},
+				Spec: vmopv1.VirtualMachineSpec{
+					ImageName: "my-image",
+					ClassName: "my-class",
+				},
+			},
+		}
+	})
+
+	AfterEach(func() {
+		ctx.AfterEach()
+		ctx = nil
+	})
+
+	When("the VM has no backup data", func() {
+		It("Should return no VM", func() {
+			data, err := virtualmachine.GetBackupData(getExtraConfig())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data.VM).To(BeNil())
+		})
+	})
+
+	When("the VM has backup data", func() {
+		BeforeEach(func() {
+			dummyPVC := builder.DummyPersistentVolumeClaim()
+			backupVMCtx := context.BackupVirtualMachineContextA2{
+				VMCtx:         vmCtx,
+				VcVM:          vcVM,
+				BootstrapData: map[string]string{"foo": "bar"},
+				DiskUUIDToPVC: map[string]corev1.PersistentVolumeClaim{vcSimDiskUUID: *dummyPVC},
+			}
+			Expect(virtualmachine.BackupVirtualMachine(backupVMCtx)).To(Succeed())
+		})
+
+		It("Should return the backed up data", func() {
+			data, err := virtualmachine.GetBackupData(getExtraConfig())
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(data.VM).NotTo(BeNil())
+			Expect(data.VM.Name).To(Equal("my-vm"))
+			Expect(data.VM.Spec.ImageName).To(Equal("my-image"))
+			Expect(data.BootstrapData).To(HaveKeyWithValue("foo", "bar"))
+			Expect(data.CloudInitInstanceID).To(Equal("my-vm-uid"))
+			Expect(data.DiskData).To(HaveLen(1))
+			Expect(data.DiskData[0].FileName).To(Equal(vcSimDiskFileName))
+			Expect(data.DiskData[0].PVCName).To(Equal(builder.DummyPersistentVolumeClaim().Name))
+		})
+	})
+
+	When("the VM backup data is invalid", func() {
+		It("Should return an error", func() {
+			_, err := virtualmachine.GetBackupData(map[string]string{
+				vmopv1.VMBackupKubeDataExtraConfigKey: "not-valid-data",
+			})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package virtualmachine
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/serialconsole"
+)
+
+// GetSerialConsole returns the service URI of the network-backed serial port
+// of the VM, adding the serial port to the VM when it does not have one. The
+// serial port connects to the serial console proxy at proxyURI as a virtual
+// serial port concentrator, so the ESXi host does not listen for connections
+// to it. A serial port cannot be added while the VM is powered on, in which
+// case serialconsole.ErrVMPoweredOn is returned.
+func GetSerialConsole(
+	vmCtx context.VirtualMachineContextA2,
+	vm *object.VirtualMachine,
+	proxyURI string) (string, error) {
+
+	var o mo.VirtualMachine
+	props := []string{"config.hardware.device", "config.instanceUuid", "runtime.powerState"}
+	if err := vm.Properties(vmCtx, vm.Reference(), props, &o); err != nil {
+		return "", err
+	}
+	if o.Config == nil {
+		return "", errors.New("VM config is not available")
+	}
+
+	serviceURI := serialconsole.ServiceURI(o.Config.InstanceUuid)
+	devices := object.VirtualDeviceList(o.Config.Hardware.Device)
+
+	serialPort, backing := findSerialConsolePort(devices)
+	if serialPort != nil && backing.ServiceURI == serviceURI && backing.ProxyURI == proxyURI {
+		return serviceURI, nil
+	}
+
+	if o.Runtime.PowerState == types.VirtualMachinePowerStatePoweredOn {
+		return "", serialconsole.ErrVMPoweredOn
+	}
+
+	if serialPort != nil {
+		// The serial port was copied from another VM, for example by a clone,
+		// or the proxy moved. Connect the serial port as this VM's.
+		vmCtx.Logger.Info("Updating network-backed serial port",
+			"serviceURI", serviceURI, "oldServiceURI", backing.ServiceURI)
+		backing.ServiceURI = serviceURI
+		backing.ProxyURI = proxyURI
+		if err := vm.EditDevice(vmCtx, serialPort); err != nil {
+			return "", fmt.Errorf("failed to update serial port: %w", err)
+		}
+		return serviceURI, nil
+	}
+
+	serialPort, err := devices.CreateSerialPort()
+	if err != nil {
+		return "", err
+	}
+	serialPort.Backing = &types.VirtualSerialPortURIBackingInfo{
+		VirtualDeviceURIBackingInfo: types.VirtualDeviceURIBackingInfo{
+			ServiceURI: serviceURI,
+			Direction:  string(types.VirtualDeviceURIBackingOptionDirectionServer),
+			ProxyURI:   proxyURI,
+		},
+	}
+	serialPort.Connectable = &types.VirtualDeviceConnectInfo{
+		StartConnected: true,
+		Connected:      true,
+	}
+
+	vmCtx.Logger.Info("Adding network-backed serial port", "serviceURI", serviceURI)
+	if err := vm.AddDevice(vmCtx, serialPort); err != nil {
+		return "", fmt.Errorf("failed to add serial port: %w", err)
+	}
+
+	return serviceURI, nil
+}
+
+// findSerialConsolePort returns the network-backed serial port that connects
+// to the serial console proxy.
+func findSerialConsolePort(
+	devices object.VirtualDeviceList) (*types.VirtualSerialPort, *types.VirtualSerialPortURIBackingInfo) {
+
+	for _, dev := range devices.SelectByType((*types.VirtualSerialPort)(nil)) {
+		backing, ok := dev.GetVirtualDevice().Backing.(*types.VirtualSerialPortURIBackingInfo)
+		if ok && serialconsole.IsServiceURI(backing.ServiceURI) {
+			return dev.(*types.VirtualSerialPort), backing
+		}
+	}
+	return nil, nil
+}
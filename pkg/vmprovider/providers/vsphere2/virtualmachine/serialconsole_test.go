@@ -0,0 +1,130 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package virtualmachine_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/serialconsole"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere2/virtualmachine"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func serialConsoleTests() {
+
+	var (
+		ctx   *builder.TestContextForVCSim
+		vcVM  *object.VirtualMachine
+		vmCtx context.VirtualMachineContextA2
+	)
+
+	const proxyURI = "telnet://192.168.0.10:8023"
+
+	BeforeEach(func() {
+		ctx = suite.NewTestContextForVCSim(builder.VCSimTestConfig{WithV1A2: true})
+
+		var err error
+		vcVM, err = ctx.Finder.VirtualMachine(ctx, "DC0_C0_RP0_VM0")
+		Expect(err).ToNot(HaveOccurred())
+
+		vmCtx = context.VirtualMachineContextA2{
+			Context: ctx,
+			Logger:  suite.GetLogger().WithValues("vmName", vcVM.Name()),
+			VM:      &vmopv1.VirtualMachine{},
+		}
+	})
+
+	AfterEach(func() {
+		ctx.AfterEach()
+		ctx = nil
+	})
+
+	powerOff := func() {
+		task, err := vcVM.PowerOff(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(task.Wait(ctx)).To(Succeed())
+	}
+
+	serialPortBacking := func() *types.VirtualSerialPortURIBackingInfo {
+		devices, err := vcVM.Device(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		serialPorts := devices.SelectByType((*types.VirtualSerialPort)(nil))
+		Expect(serialPorts).To(HaveLen(1))
+		backing, ok := serialPorts[0].GetVirtualDevice().Backing.(*types.VirtualSerialPortURIBackingInfo)
+		Expect(ok).To(BeTrue())
+		return backing
+	}
+
+	It("adds a serial port that connects to the proxy to the VM", func() {
+		powerOff()
+
+		var o mo.VirtualMachine
+		Expect(vcVM.Properties(ctx, vcVM.Reference(), []string{"config.instanceUuid"}, &o)).To(Succeed())
+
+		serviceURI, err := virtualmachine.GetSerialConsole(vmCtx, vcVM, proxyURI)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(serviceURI).To(Equal(serialconsole.ServiceURI(o.Config.InstanceUuid)))
+
+		backing := serialPortBacking()
+		Expect(backing.ServiceURI).To(Equal(serviceURI))
+		Expect(backing.ProxyURI).To(Equal(proxyURI))
+		Expect(backing.Direction).To(Equal(string(types.VirtualDeviceURIBackingOptionDirectionServer)))
+
+		By("returns the same serial port when called again", func() {
+			serviceURI2, err := virtualmachine.GetSerialConsole(vmCtx, vcVM, proxyURI)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(serviceURI2).To(Equal(serviceURI))
+			serialPortBacking()
+		})
+
+		By("returns the serial port when the VM is powered on", func() {
+			task, err := vcVM.PowerOn(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(task.Wait(ctx)).To(Succeed())
+
+			serviceURI2, err := virtualmachine.GetSerialConsole(vmCtx, vcVM, proxyURI)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(serviceURI2).To(Equal(serviceURI))
+		})
+	})
+
+	It("does not add a serial port to a powered on VM", func() {
+		_, err := virtualmachine.GetSerialConsole(vmCtx, vcVM, proxyURI)
+		Expect(err).To(MatchError(serialconsole.ErrVMPoweredOn))
+
+		devices, err := vcVM.Device(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(devices.SelectByType((*types.VirtualSerialPort)(nil))).To(BeEmpty())
+	})
+
+	It("connects a serial port copied from another VM as the VM's", func() {
+		powerOff()
+
+		otherServiceURI := serialconsole.ServiceURI("42307e5f-4d4b-4b3a-9f67-6fa4f5a1c3a2")
+		devices, err := vcVM.Device(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		serialPort, err := devices.CreateSerialPort()
+		Expect(err).ToNot(HaveOccurred())
+		serialPort.Backing = &types.VirtualSerialPortURIBackingInfo{
+			VirtualDeviceURIBackingInfo: types.VirtualDeviceURIBackingInfo{
+				ServiceURI: otherServiceURI,
+				Direction:  string(types.VirtualDeviceURIBackingOptionDirectionServer),
+				ProxyURI:   proxyURI,
+			},
+		}
+		Expect(vcVM.AddDevice(ctx, serialPort)).To(Succeed())
+
+		serviceURI, err := virtualmachine.GetSerialConsole(vmCtx, vcVM, proxyURI)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(serviceURI).ToNot(Equal(otherServiceURI))
+		Expect(serialPortBacking().ServiceURI).To(Equal(serviceURI))
+	})
+}
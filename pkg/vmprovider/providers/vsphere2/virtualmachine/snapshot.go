@@ -0,0 +1,270 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package virtualmachine
+
+import (
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+)
+
+// snapshotNode is a VM snapshot tree node along with its parent node, if any.
+type snapshotNode struct {
+	tree   *types.VirtualMachineSnapshotTree
+	parent *types.VirtualMachineSnapshotTree
+}
+
+// findSnapshotNode returns the node in the VM's snapshot tree for the
+// snapshot. The snapshot's UniqueID is preferred, falling back to the
+// snapshot's name when the UniqueID is not yet known.
+func findSnapshotNode(
+	vmSnapshot *vmopv1.VirtualMachineSnapshot,
+	snapshotInfo *types.VirtualMachineSnapshotInfo) *snapshotNode {
+
+	if snapshotInfo == nil {
+		return nil
+	}
+
+	var find func(parent *types.VirtualMachineSnapshotTree, tree []types.VirtualMachineSnapshotTree) *snapshotNode
+	find = func(parent *types.VirtualMachineSnapshotTree, tree []types.VirtualMachineSnapshotTree) *snapshotNode {
+		for i := range tree {
+			node := &tree[i]
+
+			if id := vmSnapshot.Status.UniqueID; id != "" {
+				if node.Snapshot.Value == id {
+					return &snapshotNode{tree: node, parent: parent}
+				}
+			} else if node.Name == vmSnapshot.Name {
+				return &snapshotNode{tree: node, parent: parent}
+			}
+
+			if n := find(node, node.ChildSnapshotList); n != nil {
+				return n
+			}
+		}
+		return nil
+	}
+
+	return find(nil, snapshotInfo.RootSnapshotList)
+}
+
+func getSnapshotProperties(
+	vmCtx context.VirtualMachineContextA2,
+	vcVM *object.VirtualMachine) (*mo.VirtualMachine, error) {
+
+	var o mo.VirtualMachine
+	if err := vcVM.Properties(vmCtx, vcVM.Reference(), []string{"snapshot", "layoutEx"}, &o); err != nil {
+		return nil, errors.Wrap(err, "failed to get VM snapshot properties")
+	}
+
+	return &o, nil
+}
+
+// CreateSnapshot takes a snapshot of the VM if one does not already exist for
+// the VirtualMachineSnapshot, and then updates the VirtualMachineSnapshot's
+// status from the VM's snapshot tree.
+func CreateSnapshot(
+	vmCtx context.VirtualMachineContextA2,
+	vcVM *object.VirtualMachine,
+	vmSnapshot *vmopv1.VirtualMachineSnapshot) error {
+
+	o, err := getSnapshotProperties(vmCtx, vcVM)
+	if err != nil {
+		return err
+	}
+
+	if findSnapshotNode(vmSnapshot, o.Snapshot) == nil {
+		if vmSnapshot.Status.UniqueID != "" {
+			return errors.Errorf("snapshot %q with ID %s no longer exists", vmSnapshot.Name, vmSnapshot.Status.UniqueID)
+		}
+
+		vmCtx.Logger.Info("Creating VM snapshot", "snapshotName", vmSnapshot.Name,
+			"memory", vmSnapshot.Spec.Memory, "quiesce", vmSnapshot.Spec.Quiesce)
+
+		t, err := vcVM.CreateSnapshot(
+			vmCtx,
+			vmSnapshot.Name,
+			vmSnapshot.Spec.Description,
+			vmSnapshot.Spec.Memory,
+			vmSnapshot.Spec.Quiesce && !vmSnapshot.Spec.Memory)
+		if err != nil {
+			return err
+		}
+
+		taskInfo, err := t.WaitForResult(vmCtx)
+		if err != nil {
+			if taskInfo != nil {
+				vmCtx.Logger.V(5).Error(err, "create snapshot task failed", "taskInfo", taskInfo)
+			}
+			return errors.Wrapf(err, "create snapshot task failed")
+		}
+
+		if moRef, ok := taskInfo.Result.(types.ManagedObjectReference); ok {
+			vmSnapshot.Status.UniqueID = moRef.Value
+		}
+
+		if o, err = getSnapshotProperties(vmCtx, vcVM); err != nil {
+			return err
+		}
+	}
+
+	return updateSnapshotStatus(vmSnapshot, o)
+}
+
+// UpdateSnapshotStatus updates the VirtualMachineSnapshot's status from the
+// VM's snapshot tree.
+func UpdateSnapshotStatus(
+	vmCtx context.VirtualMachineContextA2,
+	vcVM *object.VirtualMachine,
+	vmSnapshot *vmopv1.VirtualMachineSnapshot) error {
+
+	o, err := getSnapshotProperties(vmCtx, vcVM)
+	if err != nil {
+		return err
+	}
+
+	return updateSnapshotStatus(vmSnapshot, o)
+}
+
+func updateSnapshotStatus(
+	vmSnapshot *vmopv1.VirtualMachineSnapshot,
+	o *mo.VirtualMachine) error {
+
+	node := findSnapshotNode(vmSnapshot, o.Snapshot)
+	if node == nil {
+		return errors.Errorf("snapshot %q not found", vmSnapshot.Name)
+	}
+
+	status := &vmSnapshot.Status
+	status.UniqueID = node.tree.Snapshot.Value
+	status.CreationTime = &metav1.Time{Time: node.tree.CreateTime}
+	status.PowerState = convertSnapshotPowerState(node.tree.State)
+	// A snapshot of a powered on VM is only recorded as powered on when the
+	// VM's memory is included in the snapshot.
+	status.Memory = node.tree.State == types.VirtualMachinePowerStatePoweredOn
+	status.Quiesced = node.tree.Quiesced
+
+	status.Parent = ""
+	var parentRef *types.ManagedObjectReference
+	if node.parent != nil {
+		status.Parent = node.parent.Name
+		parentRef = &node.parent.Snapshot
+	}
+
+	status.Children = nil
+	for _, child := range node.tree.ChildSnapshotList {
+		status.Children = append(status.Children, child.Name)
+	}
+
+	isCurrent := o.Snapshot.CurrentSnapshot != nil && o.Snapshot.CurrentSnapshot.Value == node.tree.Snapshot.Value
+	status.Current = isCurrent
+
+	if o.LayoutEx != nil {
+		size := object.SnapshotSize(node.tree.Snapshot, parentRef, o.LayoutEx, isCurrent)
+		status.Size = resource.NewQuantity(int64(size), resource.BinarySI)
+	}
+
+	return nil
+}
+
+// RevertToSnapshot reverts the VM to the snapshot. The VM is only powered on
+// as part of the revert if the VM's desired power state is powered on.
+func RevertToSnapshot(
+	vmCtx context.VirtualMachineContextA2,
+	vcVM *object.VirtualMachine,
+	vmSnapshot *vmopv1.VirtualMachineSnapshot) error {
+
+	o, err := getSnapshotProperties(vmCtx, vcVM)
+	if err != nil {
+		return err
+	}
+
+	node := findSnapshotNode(vmSnapshot, o.Snapshot)
+	if node == nil {
+		return errors.Errorf("snapshot %q not found", vmSnapshot.Name)
+	}
+
+	vmCtx.Logger.Info("Reverting VM to snapshot", "snapshotName", vmSnapshot.Name, "snapshotID", node.tree.Snapshot.Value)
+
+	req := types.RevertToSnapshot_Task{
+		This:            node.tree.Snapshot,
+		SuppressPowerOn: types.NewBool(vmCtx.VM.Spec.PowerState != vmopv1.VirtualMachinePowerStateOn),
+	}
+
+	res, err := methods.RevertToSnapshot_Task(vmCtx, vcVM.Client(), &req)
+	if err != nil {
+		return err
+	}
+
+	t := object.NewTask(vcVM.Client(), res.Returnval)
+	if taskInfo, err := t.WaitForResult(vmCtx); err != nil {
+		if taskInfo != nil {
+			vmCtx.Logger.V(5).Error(err, "revert snapshot task failed", "taskInfo", taskInfo)
+		}
+		return errors.Wrapf(err, "revert snapshot task failed")
+	}
+
+	return nil
+}
+
+// DeleteSnapshot removes the snapshot from the VM. The snapshot's children
+// are not removed and the snapshot's data is consolidated into them.
+func DeleteSnapshot(
+	vmCtx context.VirtualMachineContextA2,
+	vcVM *object.VirtualMachine,
+	vmSnapshot *vmopv1.VirtualMachineSnapshot) error {
+
+	o, err := getSnapshotProperties(vmCtx, vcVM)
+	if err != nil {
+		return err
+	}
+
+	node := findSnapshotNode(vmSnapshot, o.Snapshot)
+	if node == nil {
+		// Snapshot does not exist.
+		return nil
+	}
+
+	vmCtx.Logger.Info("Deleting VM snapshot", "snapshotName", vmSnapshot.Name, "snapshotID", node.tree.Snapshot.Value)
+
+	req := types.RemoveSnapshot_Task{
+		This:           node.tree.Snapshot,
+		RemoveChildren: false,
+		Consolidate:    types.NewBool(true),
+	}
+
+	res, err := methods.RemoveSnapshot_Task(vmCtx, vcVM.Client(), &req)
+	if err != nil {
+		return err
+	}
+
+	t := object.NewTask(vcVM.Client(), res.Returnval)
+	if taskInfo, err := t.WaitForResult(vmCtx); err != nil {
+		if taskInfo != nil {
+			vmCtx.Logger.V(5).Error(err, "remove snapshot task failed", "taskInfo", taskInfo)
+		}
+		return errors.Wrapf(err, "remove snapshot task failed")
+	}
+
+	return nil
+}
+
+func convertSnapshotPowerState(powerState types.VirtualMachinePowerState) vmopv1.VirtualMachinePowerState {
+	switch powerState {
+	case types.VirtualMachinePowerStatePoweredOff:
+		return vmopv1.VirtualMachinePowerStateOff
+	case types.VirtualMachinePowerStatePoweredOn:
+		return vmopv1.VirtualMachinePowerStateOn
+	case types.VirtualMachinePowerStateSuspended:
+		return vmopv1.VirtualMachinePowerStateSuspended
+	}
+	return ""
+}
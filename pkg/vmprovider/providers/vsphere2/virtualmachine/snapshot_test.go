@@ -0,0 +1,118 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package virtualmachine_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere2/virtualmachine"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func snapshotTests() {
+
+	var (
+		ctx   *builder.TestContextForVCSim
+		vcVM  *object.VirtualMachine
+		vmCtx context.VirtualMachineContextA2
+	)
+
+	newVMSnapshot := func(name string) *vmopv1.VirtualMachineSnapshot {
+		return &vmopv1.VirtualMachineSnapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: vmCtx.VM.Namespace,
+			},
+			Spec: vmopv1.VirtualMachineSnapshotSpec{
+				VMName: vmCtx.VM.Name,
+			},
+		}
+	}
+
+	getCurrentSnapshot := func() string {
+		var o mo.VirtualMachine
+		Expect(vcVM.Properties(ctx, vcVM.Reference(), []string{"snapshot"}, &o)).To(Succeed())
+		if o.Snapshot == nil || o.Snapshot.CurrentSnapshot == nil {
+			return ""
+		}
+		return o.Snapshot.CurrentSnapshot.Value
+	}
+
+	BeforeEach(func() {
+		ctx = suite.NewTestContextForVCSim(builder.VCSimTestConfig{WithV1A2: true})
+
+		var err error
+		vcVM, err = ctx.Finder.VirtualMachine(ctx, "DC0_C0_RP0_VM0")
+		Expect(err).ToNot(HaveOccurred())
+
+		vmCtx = context.VirtualMachineContextA2{
+			Context: ctx,
+			Logger:  suite.GetLogger().WithValues("vmName", vcVM.Name()),
+			VM:      builder.DummyVirtualMachineA2(),
+		}
+	})
+
+	AfterEach(func() {
+		ctx.AfterEach()
+		ctx = nil
+	})
+
+	It("Creates, reverts to, and deletes snapshots", func() {
+		snap1 := newVMSnapshot("snap-1")
+		snap2 := newVMSnapshot("snap-2")
+
+		By("creating the first snapshot", func() {
+			Expect(virtualmachine.CreateSnapshot(vmCtx, vcVM, snap1)).To(Succeed())
+			Expect(snap1.Status.UniqueID).ToNot(BeEmpty())
+			Expect(snap1.Status.CreationTime).ToNot(BeNil())
+			Expect(snap1.Status.Parent).To(BeEmpty())
+			Expect(snap1.Status.Current).To(BeTrue())
+		})
+
+		By("creating the snapshot again is a no-op", func() {
+			id := snap1.Status.UniqueID
+			Expect(virtualmachine.CreateSnapshot(vmCtx, vcVM, snap1)).To(Succeed())
+			Expect(snap1.Status.UniqueID).To(Equal(id))
+		})
+
+		By("creating a child snapshot", func() {
+			Expect(virtualmachine.CreateSnapshot(vmCtx, vcVM, snap2)).To(Succeed())
+			Expect(snap2.Status.UniqueID).ToNot(Equal(snap1.Status.UniqueID))
+			Expect(snap2.Status.Parent).To(Equal(snap1.Name))
+			Expect(snap2.Status.Current).To(BeTrue())
+
+			Expect(virtualmachine.UpdateSnapshotStatus(vmCtx, vcVM, snap1)).To(Succeed())
+			Expect(snap1.Status.Children).To(ConsistOf(snap2.Name))
+			Expect(snap1.Status.Current).To(BeFalse())
+		})
+
+		By("reverting to the first snapshot", func() {
+			Expect(virtualmachine.RevertToSnapshot(vmCtx, vcVM, snap1)).To(Succeed())
+			Expect(getCurrentSnapshot()).To(Equal(snap1.Status.UniqueID))
+		})
+
+		By("deleting the snapshots", func() {
+			Expect(virtualmachine.DeleteSnapshot(vmCtx, vcVM, snap2)).To(Succeed())
+			Expect(virtualmachine.UpdateSnapshotStatus(vmCtx, vcVM, snap2)).ToNot(Succeed())
+
+			Expect(virtualmachine.DeleteSnapshot(vmCtx, vcVM, snap1)).To(Succeed())
+			Expect(getCurrentSnapshot()).To(BeEmpty())
+
+			// Deleting a snapshot that no longer exists is not an error.
+			Expect(virtualmachine.DeleteSnapshot(vmCtx, vcVM, snap1)).To(Succeed())
+		})
+	})
+
+	It("Returns an error when a created snapshot no longer exists", func() {
+		snap := newVMSnapshot("snap-1")
+		snap.Status.UniqueID = "snapshot-does-not-exist"
+		Expect(virtualmachine.CreateSnapshot(vmCtx, vcVM, snap)).ToNot(Succeed())
+	})
+}
@@ -14,9 +14,13 @@ import (
 func vcSimTests() {
 	Describe("ClusterComputeResource", ccrTests)
 	Describe("Delete", deleteTests)
+	Describe("Export", exportTests)
 	Describe("Publish", publishTests)
 	Describe("Backup", backupTests)
+	Describe("Restore", restoreTests)
 	Describe("GuestInfo", guestInfoTests)
+	Describe("Snapshot", snapshotTests)
+	Describe("SerialConsole", serialConsoleTests)
 }
 
 var suite = builder.NewTestSuite()
@@ -0,0 +1,147 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package virtualmachine
+
+import (
+	"fmt"
+
+	"github.com/vmware/govmomi/vim25/types"
+	"k8s.io/utils/pointer"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere2/constants"
+)
+
+// VolumeDiskArgs contains the arguments needed to create the disks of the VM's
+// ephemeral and image volumes.
+type VolumeDiskArgs struct {
+	// StorageClassesToIDs maps the name of a StorageClass to its storage policy ID.
+	StorageClassesToIDs map[string]string
+	// ImageDiskPaths maps the name of an image volume to the datastore path of the
+	// image disk that is cloned.
+	ImageDiskPaths map[string]string
+}
+
+// FilterVolumeDiskVolumes returns the VM's volumes whose disks are created as part
+// of the VM's configuration: the ephemeral and image volumes.
+func FilterVolumeDiskVolumes(vm *vmopv1.VirtualMachine) []vmopv1.VirtualMachineVolume {
+	var volumes []vmopv1.VirtualMachineVolume
+
+	for _, vol := range vm.Spec.Volumes {
+		if vol.Ephemeral != nil || vol.Image != nil {
+			volumes = append(volumes, vol)
+		}
+	}
+
+	return volumes
+}
+
+// VolumeDiskDeviceKey returns the device key of the disk created for the volume at
+// idx in the volumes returned by FilterVolumeDiskVolumes.
+func VolumeDiskDeviceKey(idx int) int32 {
+	return volumeDiskStartDeviceKey - int32(idx)
+}
+
+// VolumeDiskExtraConfigKey returns the ExtraConfig key that records the disk created
+// for the named volume.
+func VolumeDiskExtraConfigKey(volumeName string) string {
+	return constants.VolumeDiskExtraConfigKeyPrefix + volumeName
+}
+
+// VolumeDiskExtraConfigValue returns the ExtraConfig value that identifies the disk
+// by its controller key and unit number.
+func VolumeDiskExtraConfigValue(disk *types.VirtualDisk) string {
+	var unitNumber int32
+	if disk.UnitNumber != nil {
+		unitNumber = *disk.UnitNumber
+	}
+	return fmt.Sprintf("%d:%d", disk.ControllerKey, unitNumber)
+}
+
+// CreateVolumeDiskDeviceChanges returns the DeviceChanges that create the disks of the
+// VM's ephemeral and image volumes. Ephemeral volumes are thin provisioned disks that
+// live and die with the VM. Image volumes are independent non-persistent disks so writes
+// are discarded when the VM is powered off. The backing of an image volume's disk is the
+// image disk, which must be copied to the VM and the backing updated to the copy before
+// the disk is added to the VM. The datastore of each disk, compatible with the storage
+// policy of the volume's storage class, is also only selected when the disk is added. An
+// image volume is skipped if the path of its image disk is not known.
+func CreateVolumeDiskDeviceChanges(
+	vm *vmopv1.VirtualMachine,
+	args *VolumeDiskArgs) []types.BaseVirtualDeviceConfigSpec {
+
+	if args == nil {
+		return nil
+	}
+
+	var deviceChanges []types.BaseVirtualDeviceConfigSpec
+
+	for idx, volume := range FilterVolumeDiskVolumes(vm) {
+		var disk *types.VirtualDisk
+		var storageClass string
+
+		switch {
+		case volume.Ephemeral != nil:
+			storageClass = volume.Ephemeral.StorageClass
+			disk = &types.VirtualDisk{
+				CapacityInBytes: volume.Ephemeral.Size.Value(),
+				VirtualDevice: types.VirtualDevice{
+					Backing: &types.VirtualDiskFlatVer2BackingInfo{
+						ThinProvisioned: pointer.Bool(true),
+						DiskMode:        string(types.VirtualDiskModePersistent),
+					},
+				},
+			}
+
+		case volume.Image != nil:
+			imageDiskPath := args.ImageDiskPaths[volume.Name]
+			if imageDiskPath == "" {
+				continue
+			}
+
+			storageClass = volume.Image.StorageClass
+			if storageClass == "" {
+				storageClass = vm.Spec.StorageClass
+			}
+			disk = &types.VirtualDisk{
+				VirtualDevice: types.VirtualDevice{
+					Backing: &types.VirtualDiskFlatVer2BackingInfo{
+						VirtualDeviceFileBackingInfo: types.VirtualDeviceFileBackingInfo{
+							FileName: imageDiskPath,
+						},
+						DiskMode: string(types.VirtualDiskModeIndependent_nonpersistent),
+					},
+				},
+			}
+		}
+
+		disk.Key = VolumeDiskDeviceKey(idx)
+
+		deviceChange := &types.VirtualDeviceConfigSpec{
+			Operation: types.VirtualDeviceConfigSpecOperationAdd,
+			Device:    disk,
+		}
+		if volume.Ephemeral != nil {
+			deviceChange.FileOperation = types.VirtualDeviceConfigSpecFileOperationCreate
+		}
+		if profileID := args.StorageClassesToIDs[storageClass]; profileID != "" {
+			deviceChange.Profile = []types.BaseVirtualMachineProfileSpec{
+				&types.VirtualMachineDefinedProfileSpec{
+					ProfileId: profileID,
+				},
+			}
+		}
+
+		deviceChanges = append(deviceChanges, deviceChange)
+	}
+
+	return deviceChanges
+}
+
+// IsVolumeDiskDevice returns true if the device is a disk created by
+// CreateVolumeDiskDeviceChanges that has not yet been added to the VM.
+func IsVolumeDiskDevice(dev types.BaseVirtualDevice) bool {
+	disk, ok := dev.(*types.VirtualDisk)
+	return ok && disk.Key <= volumeDiskStartDeviceKey
+}
@@ -4,6 +4,8 @@
 package vmlifecycle
 
 import (
+	"crypto/sha256"
+	"encoding/json"
 	"fmt"
 
 	"github.com/vmware/govmomi/object"
@@ -14,6 +16,7 @@ import (
 
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
 	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/util"
 	"github.com/vmware-tanzu/vm-operator/pkg/util/cloudinit"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere2/config"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere2/constants"
@@ -37,6 +40,16 @@ type BootstrapData struct {
 	Sysprep     *sysprep.SecretData
 }
 
+// GetBootstrapDataHash returns a hash of the bootstrap data that changes when
+// the data in any of the VM's bootstrap Secrets changes.
+func GetBootstrapDataHash(bootstrapData BootstrapData) (string, error) {
+	data, err := json.Marshal(bootstrapData)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(data)), nil
+}
+
 type TemplateRenderFunc func(string, string) string
 
 type BootstrapArgs struct {
@@ -117,6 +130,62 @@ func DoBootstrap(
 	return nil
 }
 
+// DoBootstrapNetworkUpdate pushes the network config of a powered on VM to the
+// guest after its network interfaces were hot-plugged. This is only supported
+// when the bootstrap provider is CloudInit with the GuestInfo transport since
+// the other providers require the VM to be powered off to customize it.
+func DoBootstrapNetworkUpdate(
+	vmCtx context.VirtualMachineContextA2,
+	vcVM *object.VirtualMachine,
+	config *vimTypes.VirtualMachineConfigInfo,
+	k8sClient ctrl.Client,
+	networkResults network.NetworkInterfaceResults,
+	bootstrapData BootstrapData) error {
+
+	bootstrap := vmCtx.VM.Spec.Bootstrap
+	if bootstrap == nil || bootstrap.CloudInit == nil {
+		return nil
+	}
+
+	if vmCtx.VM.Annotations[constants.CloudInitTypeAnnotation] == constants.CloudInitTypeValueCloudInitPrep {
+		vmCtx.Logger.Info("Skipping guest network update because CloudInitPrep requires customization")
+		return nil
+	}
+
+	bootstrapArgs, err := getBootstrapArgs(vmCtx, k8sClient, true, networkResults, bootstrapData)
+	if err != nil {
+		return err
+	}
+
+	// Pass an empty config so the ExtraConfig has the metadata even though the
+	// keys already exist on the VM.
+	cloudInitConfigSpec, _, err := BootStrapCloudInit(vmCtx, &vimTypes.VirtualMachineConfigInfo{}, bootstrap.CloudInit, bootstrapArgs)
+	if err != nil {
+		return fmt.Errorf("failed to create bootstrap data: %w", err)
+	}
+
+	// Only update the metadata since that is where the network config is.
+	// The userdata is not changed and the vApp config was already removed
+	// when the VM was powered on.
+	curExtraConfig := util.ExtraConfigToMap(config.ExtraConfig)
+	configSpec := &vimTypes.VirtualMachineConfigSpec{}
+	for _, opt := range cloudInitConfigSpec.ExtraConfig {
+		optValue := opt.GetOptionValue()
+		switch optValue.Key {
+		case constants.CloudInitGuestInfoMetadata, constants.CloudInitGuestInfoMetadataEncoding:
+			if curExtraConfig[optValue.Key] != optValue.Value {
+				configSpec.ExtraConfig = append(configSpec.ExtraConfig, optValue)
+			}
+		}
+	}
+
+	if err := doReconfigure(vmCtx, vcVM, configSpec); err != nil {
+		return fmt.Errorf("boostrap network update reconfigure failed: %w", err)
+	}
+
+	return nil
+}
+
 func getBootstrapArgs(
 	vmCtx context.VirtualMachineContextA2,
 	k8sClient ctrl.Client,
@@ -48,7 +48,15 @@ func BootStrapCloudInit(
 		sshPublicKeys = strings.Join(cloudInitSpec.SSHAuthorizedKeys, "\n")
 	}
 
-	metadata, err := GetCloudInitMetadata(string(vmCtx.VM.UID), bsArgs.Hostname, netPlan, sshPublicKeys)
+	// Use the annotation as the instance ID when set, ex. when the VM was
+	// restored from a backup, so that the guest is not customized again
+	// because of a different instance ID.
+	instanceID := string(vmCtx.VM.UID)
+	if value, ok := vmCtx.VM.Annotations[vmopv1.InstanceIDAnnotation]; ok && value != "" {
+		instanceID = value
+	}
+
+	metadata, err := GetCloudInitMetadata(instanceID, bsArgs.Hostname, netPlan, sshPublicKeys)
 	if err != nil {
 		return nil, nil, err
 	}
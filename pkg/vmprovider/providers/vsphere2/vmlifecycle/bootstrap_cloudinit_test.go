@@ -251,6 +251,23 @@ var _ = Describe("CloudInit Bootstrap", func() {
 					Expect(custSpec).To(BeNil())
 				})
 
+				Context("With instance ID annotation", func() {
+					BeforeEach(func() {
+						vmCtx.VM.Annotations[vmopv1.InstanceIDAnnotation] = "my-restored-instance-id"
+					})
+
+					It("Uses the annotation as the instance ID", func() {
+						Expect(err).ToNot(HaveOccurred())
+						Expect(configSpec).ToNot(BeNil())
+
+						extraConfig := util.ExtraConfigToMap(configSpec.ExtraConfig)
+						data, err := util.TryToDecodeBase64Gzip([]byte(extraConfig[constants.CloudInitGuestInfoMetadata]))
+						Expect(err).ToNot(HaveOccurred())
+						Expect(data).To(ContainSubstring("instance-id: my-restored-instance-id"))
+						Expect(data).ToNot(ContainSubstring("my-vm-uuid"))
+					})
+				})
+
 				Context("Via CAPBK userdata in 'value' key", func() {
 					const otherUserData = cloudInitUserdata + "CAPBK"
 
@@ -47,5 +47,26 @@ var _ = Describe("Customization utils", func() {
 	})
 })
 
+var _ = Describe("GetBootstrapDataHash", func() {
+	It("changes when the bootstrap data changes", func() {
+		data := vmlifecycle.BootstrapData{
+			Data: map[string]string{"foo": "bar"},
+		}
+
+		hash1, err := vmlifecycle.GetBootstrapDataHash(data)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(hash1).ToNot(BeEmpty())
+
+		hash2, err := vmlifecycle.GetBootstrapDataHash(data)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(hash2).To(Equal(hash1))
+
+		data.Data["foo"] = "baz"
+		hash3, err := vmlifecycle.GetBootstrapDataHash(data)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(hash3).ToNot(Equal(hash1))
+	})
+})
+
 // TODO: We should at least a few basic DoBootstrap() tests so we test the overall
 // Reconfigure/Customize flow but the old code didn't.
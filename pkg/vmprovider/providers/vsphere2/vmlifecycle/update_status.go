@@ -7,10 +7,15 @@ import (
 	goctx "context"
 	"fmt"
 	"net"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8serrors "k8s.io/apimachinery/pkg/util/errors"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
@@ -25,10 +30,34 @@ import (
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere2/virtualmachine"
 )
 
+const (
+	// linuxGuestCustomizationLogPath is the path of the guest customization log
+	// in Linux guests.
+	linuxGuestCustomizationLogPath = "/var/log/vmware-imc/toolsDeployPkg.log"
+	// windowsGuestCustomizationLogPath is the path of the guest customization
+	// log in Windows guests.
+	windowsGuestCustomizationLogPath = `C:\Windows\TEMP\vmware-imc\guestcust.log`
+	// guestCustomizationLogTailBytes is the maximum size of the tail of the
+	// guest customization log that is copied into the VM's status.
+	guestCustomizationLogTailBytes = 4 * 1024
+	// guestCustomizationLogTailRetryInterval is the minimum interval at which
+	// fetching the guest customization log is retried after it failed.
+	guestCustomizationLogTailRetryInterval = 5 * time.Minute
+	// guestStatusRefreshInterval is the minimum interval at which the VM's
+	// resource usage in status.guest is refreshed.
+	guestStatusRefreshInterval = 1 * time.Minute
+)
+
 var (
 	// The minimum properties needed to be retrieved in order to populate the Status. Callers may
 	// provide a MO with more. This often saves us a second round trip in the common steady state.
 	vmStatusPropertiesSelector = []string{"config.changeTrackingEnabled", "guest", "summary"}
+
+	// guestCustomizationLogTailFailures records when fetching the guest
+	// customization log of a VM last failed, keyed by the VM's UID, so the
+	// guest is not logged in to on every reconcile.
+	guestCustomizationLogTailFailures   = map[string]time.Time{}
+	guestCustomizationLogTailFailuresMu sync.Mutex
 )
 
 func UpdateStatus(
@@ -52,14 +81,19 @@ func UpdateStatus(
 	}
 	if vm.Status.Class == nil {
 		// In v1a2 we know this will always be the namespace scoped class since v1a2 doesn't have
-		// the bindings. Our handling of this field will be more complicated once we really
-		// support class changes and resizing/reconfiguring the VM the fly in response.
+		// the bindings. After a class change, this field is only updated once the VM has been
+		// resized to the new class.
 		vm.Status.Class = &common.LocalObjectRef{
 			Kind:       "VirtualMachineClass",
 			APIVersion: vmopv1.SchemeGroupVersion.String(),
 			Name:       vm.Spec.ClassName,
 		}
 	}
+	if vm.Status.StorageClass == "" {
+		// After a storage class change, this field is only updated once the VM's
+		// storage has been relocated to the new storage class.
+		vm.Status.StorageClass = vm.Spec.StorageClass
+	}
 
 	if vmMO == nil {
 		// In the common case, our caller will have already gotten the MO properties in order to determine
@@ -87,7 +121,18 @@ func UpdateStatus(
 	if vmCtx.VM.Spec.Network != nil {
 		networkInterfaces = vmCtx.VM.Spec.Network.Interfaces
 	}
+	var pendingGuestConfig []vmopv1.VirtualMachineNetworkInterfacePendingStatus
+	if vm.Status.Network != nil {
+		pendingGuestConfig = vm.Status.Network.PendingGuestConfig
+	}
 	vm.Status.Network = getGuestNetworkStatus(networkInterfaces, vmMO.Guest)
+	if pending := getPendingGuestConfigStatus(networkInterfaces, pendingGuestConfig, vm.Status.Network); len(pending) > 0 {
+		if vm.Status.Network == nil {
+			vm.Status.Network = &vmopv1.VirtualMachineNetworkStatus{}
+		}
+		vm.Status.Network.PendingGuestConfig = pending
+	}
+	vm.Status.Guest = getGuestStatus(vm.Status.Guest, vmMO.Guest, summary, time.Now())
 
 	vm.Status.Host, err = getRuntimeHostHostname(vmCtx, vcVM, summary.Runtime.Host)
 	if err != nil {
@@ -96,6 +141,12 @@ func UpdateStatus(
 
 	MarkVMToolsRunningStatusCondition(vmCtx.VM, vmMO.Guest)
 	MarkCustomizationInfoCondition(vmCtx.VM, vmMO.Guest)
+	UpdateGuestCustomizationStatus(vmCtx.VM, vmMO.Guest)
+
+	if err := updateGuestCustomizationLogTail(vmCtx, k8sClient, vcVM, vmMO.Guest); err != nil {
+		// The log tail is best-effort so do not fail the status update.
+		vmCtx.Logger.V(4).Info("Failed to fetch the guest customization log", "error", err.Error())
+	}
 
 	if config := vmMO.Config; config != nil {
 		vm.Status.ChangeBlockTracking = config.ChangeTrackingEnabled
@@ -122,7 +173,9 @@ func UpdateStatus(
 			}
 		}
 
-		if zoneName != "" {
+		// After a zone change, status.zone is only updated once the VM has been
+		// migrated to the new zone.
+		if zoneName != "" && vm.Status.Zone == "" {
 			vm.Status.Zone = zoneName
 		}
 	}
@@ -141,6 +194,49 @@ func getRuntimeHostHostname(
 	return "", nil
 }
 
+// getGuestStatus returns the resource usage of the VM and its guest. The usage
+// changes continuously, so the current status is kept until it is older than
+// guestStatusRefreshInterval rather than updating the VM's status, and having
+// the VM reconciled again, every time the usage changes.
+func getGuestStatus(
+	current *vmopv1.VirtualMachineGuestStatus,
+	guestInfo *types.GuestInfo,
+	summary types.VirtualMachineSummary,
+	now time.Time) *vmopv1.VirtualMachineGuestStatus {
+
+	if current != nil && now.Sub(current.LastUpdateTime.Time) < guestStatusRefreshInterval {
+		return current
+	}
+
+	status := &vmopv1.VirtualMachineGuestStatus{
+		LastUpdateTime: metav1.NewTime(now),
+		CPUUsageMHz:    int64(summary.QuickStats.OverallCpuUsage),
+	}
+
+	if guestInfo != nil {
+		for _, disk := range guestInfo.Disk {
+			status.Filesystems = append(status.Filesystems, vmopv1.VirtualMachineGuestFilesystemStatus{
+				Path:      disk.DiskPath,
+				Type:      disk.FilesystemType,
+				Capacity:  resource.NewQuantity(disk.Capacity, resource.BinarySI),
+				FreeSpace: resource.NewQuantity(disk.FreeSpace, resource.BinarySI),
+			})
+		}
+	}
+
+	// HostMemoryUsage is the consumed memory in MB.
+	if memMB := summary.QuickStats.HostMemoryUsage; memMB > 0 {
+		status.ConsumedMemory = resource.NewQuantity(int64(memMB)*1024*1024, resource.BinarySI)
+	}
+
+	if storage := summary.Storage; storage != nil {
+		status.CommittedStorage = resource.NewQuantity(storage.Committed, resource.BinarySI)
+		status.UncommittedStorage = resource.NewQuantity(storage.Uncommitted, resource.BinarySI)
+	}
+
+	return status
+}
+
 func getGuestNetworkStatus(
 	networkInterfaces []vmopv1.VirtualMachineNetworkInterfaceSpec,
 	guestInfo *types.GuestInfo,
@@ -183,6 +279,51 @@ func getGuestNetworkStatus(
 	return status
 }
 
+// getPendingGuestConfigStatus returns the hot-added interfaces that are still
+// in the spec and whose MAC address is not yet reported by the guest with a
+// non link-local IP address.
+func getPendingGuestConfigStatus(
+	networkInterfaces []vmopv1.VirtualMachineNetworkInterfaceSpec,
+	pendingGuestConfig []vmopv1.VirtualMachineNetworkInterfacePendingStatus,
+	status *vmopv1.VirtualMachineNetworkStatus) []vmopv1.VirtualMachineNetworkInterfacePendingStatus {
+
+	var out []vmopv1.VirtualMachineNetworkInterfacePendingStatus
+
+	for _, pending := range pendingGuestConfig {
+		inSpec := false
+		for _, interfaceSpec := range networkInterfaces {
+			if interfaceSpec.Name == pending.Name {
+				inSpec = true
+				break
+			}
+		}
+		if !inSpec {
+			continue
+		}
+
+		configured := false
+		if status != nil && pending.MACAddr != "" {
+			for _, interfaceStatus := range status.Interfaces {
+				if !strings.EqualFold(interfaceStatus.IP.MACAddr, pending.MACAddr) {
+					continue
+				}
+				for _, addr := range interfaceStatus.IP.Addresses {
+					if ip := net.ParseIP(addr.Address); ip != nil && !ip.IsLinkLocalUnicast() {
+						configured = true
+						break
+					}
+				}
+			}
+		}
+
+		if !configured {
+			out = append(out, pending)
+		}
+	}
+
+	return out
+}
+
 func guestNicInfoToInterfaceStatus(idx int, guestNicInfo *types.GuestNicInfo) vmopv1.VirtualMachineNetworkInterfaceStatus {
 	status := vmopv1.VirtualMachineNetworkInterfaceStatus{}
 
@@ -392,3 +533,158 @@ func MarkCustomizationInfoCondition(vm *vmopv1.VirtualMachine, guestInfo *types.
 		conditions.MarkFalse(vm, vmopv1.GuestCustomizationCondition, "Unknown", errorMsg)
 	}
 }
+
+// UpdateGuestCustomizationStatus updates the VM's guest customization status from
+// the CustomizationInfo in its GuestInfo. The tail of the customization log is kept
+// for as long as the status describes the same customization attempt, and the hash
+// of the bootstrap data is always kept.
+func UpdateGuestCustomizationStatus(vm *vmopv1.VirtualMachine, guestInfo *types.GuestInfo) {
+	// The hash of the bootstrap data is recorded when the VM is customized,
+	// before the guest reports any customization info.
+	var dataHash string
+	if old := vm.Status.GuestCustomization; old != nil {
+		dataHash = old.DataHash
+	}
+
+	if guestInfo == nil || guestInfo.CustomizationInfo == nil {
+		vm.Status.GuestCustomization = nil
+		if dataHash != "" {
+			vm.Status.GuestCustomization = &vmopv1.VirtualMachineGuestCustomizationStatus{
+				DataHash: dataHash,
+			}
+		}
+		return
+	}
+
+	custInfo := guestInfo.CustomizationInfo
+	status := &vmopv1.VirtualMachineGuestCustomizationStatus{
+		Status:       custInfo.CustomizationStatus,
+		ErrorMessage: custInfo.ErrorMsg,
+		DataHash:     dataHash,
+	}
+	if custInfo.StartTime != nil {
+		startTime := metav1.NewTime(*custInfo.StartTime)
+		status.StartTime = &startTime
+	}
+	if custInfo.EndTime != nil {
+		endTime := metav1.NewTime(*custInfo.EndTime)
+		status.EndTime = &endTime
+	}
+
+	switch custInfo.CustomizationStatus {
+	case string(types.GuestInfoCustomizationStatusTOOLSDEPLOYPKG_IDLE), "":
+		// Customization has not been attempted so there is no log.
+	default:
+		switch guestInfo.GuestFamily {
+		case string(types.VirtualMachineGuestOsFamilyLinuxGuest):
+			status.LogLocation = linuxGuestCustomizationLogPath
+		case string(types.VirtualMachineGuestOsFamilyWindowsGuest):
+			status.LogLocation = windowsGuestCustomizationLogPath
+		}
+	}
+
+	if old := vm.Status.GuestCustomization; old != nil &&
+		old.Status == status.Status &&
+		old.StartTime.Equal(status.StartTime) &&
+		old.EndTime.Equal(status.EndTime) {
+
+		status.LogTail = old.LogTail
+	}
+
+	vm.Status.GuestCustomization = status
+}
+
+// updateGuestCustomizationLogTail fetches the tail of the guest customization log
+// into the VM's status after customization failed. The log is only fetched when the
+// VM specifies the guest credentials to use and VMware Tools is running.
+func updateGuestCustomizationLogTail(
+	vmCtx context.VirtualMachineContextA2,
+	k8sClient ctrlclient.Client,
+	vcVM *object.VirtualMachine,
+	guestInfo *types.GuestInfo) error {
+
+	status := vmCtx.VM.Status.GuestCustomization
+	if status == nil || status.LogTail != "" || status.LogLocation == "" ||
+		status.Status != string(types.GuestInfoCustomizationStatusTOOLSDEPLOYPKG_FAILED) {
+		return nil
+	}
+
+	bootstrap := vmCtx.VM.Spec.Bootstrap
+	if bootstrap == nil || bootstrap.Customization == nil || bootstrap.Customization.GuestCredentials == "" {
+		return nil
+	}
+
+	if guestInfo.ToolsRunningStatus != string(types.VirtualMachineToolsRunningStatusGuestToolsRunning) {
+		return nil
+	}
+
+	vmUID := string(vmCtx.VM.UID)
+	if isGuestCustomizationLogTailBackingOff(vmUID) {
+		return nil
+	}
+
+	auth, err := getGuestAuthentication(vmCtx, k8sClient, bootstrap.Customization.GuestCredentials)
+	if err != nil {
+		setGuestCustomizationLogTailFailed(vmUID, true)
+		return err
+	}
+
+	logTail, err := virtualmachine.ReadGuestFileTail(
+		vmCtx, vcVM, auth, status.LogLocation, guestCustomizationLogTailBytes)
+	if err != nil {
+		setGuestCustomizationLogTailFailed(vmUID, true)
+		return err
+	}
+
+	setGuestCustomizationLogTailFailed(vmUID, false)
+	status.LogTail = logTail
+	return nil
+}
+
+// isGuestCustomizationLogTailBackingOff returns true if fetching the guest
+// customization log of the VM failed less than
+// guestCustomizationLogTailRetryInterval ago.
+func isGuestCustomizationLogTailBackingOff(vmUID string) bool {
+	guestCustomizationLogTailFailuresMu.Lock()
+	defer guestCustomizationLogTailFailuresMu.Unlock()
+
+	failedAt, ok := guestCustomizationLogTailFailures[vmUID]
+	return ok && time.Since(failedAt) < guestCustomizationLogTailRetryInterval
+}
+
+// setGuestCustomizationLogTailFailed records whether fetching the guest
+// customization log of the VM failed. Failures older than the retry interval
+// are forgotten so deleted VMs are not tracked.
+func setGuestCustomizationLogTailFailed(vmUID string, failed bool) {
+	guestCustomizationLogTailFailuresMu.Lock()
+	defer guestCustomizationLogTailFailuresMu.Unlock()
+
+	for uid, failedAt := range guestCustomizationLogTailFailures {
+		if time.Since(failedAt) >= guestCustomizationLogTailRetryInterval {
+			delete(guestCustomizationLogTailFailures, uid)
+		}
+	}
+
+	if failed {
+		guestCustomizationLogTailFailures[vmUID] = time.Now()
+	} else {
+		delete(guestCustomizationLogTailFailures, vmUID)
+	}
+}
+
+func getGuestAuthentication(
+	vmCtx context.VirtualMachineContextA2,
+	k8sClient ctrlclient.Client,
+	secretName string) (types.BaseGuestAuthentication, error) {
+
+	secret := &corev1.Secret{}
+	key := ctrlclient.ObjectKey{Namespace: vmCtx.VM.Namespace, Name: secretName}
+	if err := k8sClient.Get(vmCtx, key, secret); err != nil {
+		return nil, fmt.Errorf("failed to get guest credentials Secret %s: %w", secretName, err)
+	}
+
+	return &types.NamePasswordAuthentication{
+		Username: string(secret.Data[vmopv1.VirtualMachineGuestCredentialsUsernameKey]),
+		Password: string(secret.Data[vmopv1.VirtualMachineGuestCredentialsPasswordKey]),
+	}, nil
+}
@@ -4,6 +4,8 @@
 package vmlifecycle_test
 
 import (
+	"time"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
@@ -90,6 +92,53 @@ var _ = Describe("UpdateStatus", func() {
 			})
 		})
 
+		Context("PendingGuestConfig", func() {
+			BeforeEach(func() {
+				vmMO.Guest = &types.GuestInfo{
+					Net: []types.GuestNicInfo{
+						{
+							DeviceConfigId: 4000,
+							MacAddress:     "00:50:56:00:00:01",
+							IpConfig: &types.NetIpConfigInfo{
+								IpAddress: []types.NetIpConfigInfoIpAddress{
+									{IpAddress: "192.168.1.10"},
+								},
+							},
+						},
+						{
+							DeviceConfigId: 4001,
+							MacAddress:     "00:50:56:00:00:02",
+							IpConfig: &types.NetIpConfigInfo{
+								IpAddress: []types.NetIpConfigInfoIpAddress{
+									{IpAddress: "fe80::250:56ff:fe00:2"},
+								},
+							},
+						},
+					},
+				}
+
+				vmCtx.VM.Spec.Network.Interfaces = []vmopv1.VirtualMachineNetworkInterfaceSpec{
+					{Name: "eth0"},
+					{Name: "eth1"},
+					{Name: "eth2"},
+				}
+				vmCtx.VM.Status.Network = &vmopv1.VirtualMachineNetworkStatus{
+					PendingGuestConfig: []vmopv1.VirtualMachineNetworkInterfacePendingStatus{
+						{Name: "eth1", MACAddr: "00:50:56:00:00:01"},
+						{Name: "eth2", MACAddr: "00:50:56:00:00:02"},
+						{Name: "eth3", MACAddr: "00:50:56:00:00:03"},
+					},
+				}
+			})
+
+			It("Keeps the interfaces in the spec that are not configured in the guest", func() {
+				network := vmCtx.VM.Status.Network
+
+				Expect(network.PendingGuestConfig).To(HaveLen(1))
+				Expect(network.PendingGuestConfig[0].Name).To(Equal("eth2"))
+			})
+		})
+
 		Context("IPRoutes", func() {
 			BeforeEach(func() {
 				vmMO.Guest = &types.GuestInfo{
@@ -154,6 +203,116 @@ var _ = Describe("UpdateStatus", func() {
 			Expect(status.HardwareVersion).To(Equal(int32(19)))
 		})
 	})
+
+	Context("Guest", func() {
+		BeforeEach(func() {
+			vmMO.Guest = &types.GuestInfo{
+				Disk: []types.GuestDiskInfo{
+					{
+						DiskPath:       "/",
+						Capacity:       10 * 1024 * 1024 * 1024,
+						FreeSpace:      4 * 1024 * 1024 * 1024,
+						FilesystemType: "ext4",
+					},
+					{
+						DiskPath:       "/boot",
+						Capacity:       512 * 1024 * 1024,
+						FreeSpace:      256 * 1024 * 1024,
+						FilesystemType: "xfs",
+					},
+				},
+			}
+			vmMO.Summary = types.VirtualMachineSummary{
+				QuickStats: types.VirtualMachineQuickStats{
+					OverallCpuUsage: 1200,
+					HostMemoryUsage: 2048,
+				},
+				Storage: &types.VirtualMachineStorageSummary{
+					Committed:   20 * 1024 * 1024 * 1024,
+					Uncommitted: 30 * 1024 * 1024 * 1024,
+				},
+			}
+		})
+
+		It("sets the guest status", func() {
+			guest := vmCtx.VM.Status.Guest
+			Expect(guest).ToNot(BeNil())
+
+			Expect(guest.Filesystems).To(HaveLen(2))
+			Expect(guest.Filesystems[0].Path).To(Equal("/"))
+			Expect(guest.Filesystems[0].Type).To(Equal("ext4"))
+			Expect(guest.Filesystems[0].Capacity.String()).To(Equal("10Gi"))
+			Expect(guest.Filesystems[0].FreeSpace.String()).To(Equal("4Gi"))
+			Expect(guest.Filesystems[1].Path).To(Equal("/boot"))
+			Expect(guest.Filesystems[1].Capacity.String()).To(Equal("512Mi"))
+
+			Expect(guest.CPUUsageMHz).To(BeEquivalentTo(1200))
+			Expect(guest.ConsumedMemory).ToNot(BeNil())
+			Expect(guest.ConsumedMemory.String()).To(Equal("2Gi"))
+			Expect(guest.CommittedStorage).ToNot(BeNil())
+			Expect(guest.CommittedStorage.String()).To(Equal("20Gi"))
+			Expect(guest.UncommittedStorage).ToNot(BeNil())
+			Expect(guest.UncommittedStorage.String()).To(Equal("30Gi"))
+		})
+
+		When("there is no guest info or storage summary", func() {
+			BeforeEach(func() {
+				vmMO.Guest = nil
+				vmMO.Summary.Storage = nil
+				vmMO.Summary.QuickStats = types.VirtualMachineQuickStats{}
+			})
+
+			It("only sets the available values", func() {
+				guest := vmCtx.VM.Status.Guest
+				Expect(guest).ToNot(BeNil())
+				Expect(guest.Filesystems).To(BeEmpty())
+				Expect(guest.ConsumedMemory).To(BeNil())
+				Expect(guest.CPUUsageMHz).To(BeZero())
+				Expect(guest.CommittedStorage).To(BeNil())
+				Expect(guest.UncommittedStorage).To(BeNil())
+			})
+		})
+
+		When("the guest status was recently updated", func() {
+			var lastUpdateTime metav1.Time
+
+			BeforeEach(func() {
+				lastUpdateTime = metav1.NewTime(time.Now().Add(-10 * time.Second))
+				vmCtx.VM.Status.Guest = &vmopv1.VirtualMachineGuestStatus{
+					LastUpdateTime: lastUpdateTime,
+					CPUUsageMHz:    100,
+				}
+			})
+
+			It("keeps the guest status", func() {
+				guest := vmCtx.VM.Status.Guest
+				Expect(guest).ToNot(BeNil())
+				Expect(guest.LastUpdateTime).To(Equal(lastUpdateTime))
+				Expect(guest.CPUUsageMHz).To(BeEquivalentTo(100))
+				Expect(guest.Filesystems).To(BeEmpty())
+			})
+		})
+
+		When("the guest status is stale", func() {
+			var lastUpdateTime metav1.Time
+
+			BeforeEach(func() {
+				lastUpdateTime = metav1.NewTime(time.Now().Add(-10 * time.Minute))
+				vmCtx.VM.Status.Guest = &vmopv1.VirtualMachineGuestStatus{
+					LastUpdateTime: lastUpdateTime,
+					CPUUsageMHz:    100,
+				}
+			})
+
+			It("refreshes the guest status", func() {
+				guest := vmCtx.VM.Status.Guest
+				Expect(guest).ToNot(BeNil())
+				Expect(guest.LastUpdateTime.After(lastUpdateTime.Time)).To(BeTrue())
+				Expect(guest.CPUUsageMHz).To(BeEquivalentTo(1200))
+				Expect(guest.Filesystems).To(HaveLen(2))
+			})
+		})
+	})
 })
 
 var _ = Describe("VirtualMachineTools Status to VM Status Condition", func() {
@@ -350,3 +509,125 @@ var _ = Describe("VSphere Customization Status to VM Status Condition", func() {
 		})
 	})
 })
+
+var _ = Describe("VSphere Customization Status to VM Guest Customization Status", func() {
+	Context("UpdateGuestCustomizationStatus", func() {
+		var (
+			vm        *vmopv1.VirtualMachine
+			guestInfo *types.GuestInfo
+			endTime   time.Time
+		)
+
+		BeforeEach(func() {
+			vm = &vmopv1.VirtualMachine{}
+			endTime = time.Now().Truncate(time.Second)
+			guestInfo = &types.GuestInfo{
+				GuestFamily: string(types.VirtualMachineGuestOsFamilyLinuxGuest),
+				CustomizationInfo: &types.GuestInfoCustomizationInfo{
+					CustomizationStatus: string(types.GuestInfoCustomizationStatusTOOLSDEPLOYPKG_FAILED),
+					EndTime:             &endTime,
+					ErrorMsg:            "some error message",
+				},
+			}
+		})
+
+		JustBeforeEach(func() {
+			vmlifecycle.UpdateGuestCustomizationStatus(vm, guestInfo)
+		})
+
+		Context("customizationInfo unset", func() {
+			BeforeEach(func() {
+				guestInfo.CustomizationInfo = nil
+			})
+			It("clears the status", func() {
+				Expect(vm.Status.GuestCustomization).To(BeNil())
+			})
+
+			Context("bootstrap data hash was recorded", func() {
+				BeforeEach(func() {
+					vm.Status.GuestCustomization = &vmopv1.VirtualMachineGuestCustomizationStatus{
+						Status:   string(types.GuestInfoCustomizationStatusTOOLSDEPLOYPKG_FAILED),
+						DataHash: "hash",
+					}
+				})
+				It("keeps only the hash", func() {
+					Expect(vm.Status.GuestCustomization).To(Equal(&vmopv1.VirtualMachineGuestCustomizationStatus{
+						DataHash: "hash",
+					}))
+				})
+			})
+		})
+
+		Context("customizationInfo idle", func() {
+			BeforeEach(func() {
+				guestInfo.CustomizationInfo.CustomizationStatus = string(types.GuestInfoCustomizationStatusTOOLSDEPLOYPKG_IDLE)
+			})
+			It("does not set the log location", func() {
+				Expect(vm.Status.GuestCustomization).ToNot(BeNil())
+				Expect(vm.Status.GuestCustomization.LogLocation).To(BeEmpty())
+			})
+		})
+
+		Context("customizationInfo failed in a Linux guest", func() {
+			It("sets the error and log location", func() {
+				status := vm.Status.GuestCustomization
+				Expect(status).ToNot(BeNil())
+				Expect(status.Status).To(Equal(string(types.GuestInfoCustomizationStatusTOOLSDEPLOYPKG_FAILED)))
+				Expect(status.ErrorMessage).To(Equal("some error message"))
+				Expect(status.LogLocation).To(Equal("/var/log/vmware-imc/toolsDeployPkg.log"))
+				Expect(status.EndTime).ToNot(BeNil())
+				Expect(status.EndTime.Time).To(BeTemporally("==", endTime))
+			})
+		})
+
+		Context("customizationInfo failed in a Windows guest", func() {
+			BeforeEach(func() {
+				guestInfo.GuestFamily = string(types.VirtualMachineGuestOsFamilyWindowsGuest)
+			})
+			It("sets the log location", func() {
+				Expect(vm.Status.GuestCustomization).ToNot(BeNil())
+				Expect(vm.Status.GuestCustomization.LogLocation).To(Equal(`C:\Windows\TEMP\vmware-imc\guestcust.log`))
+			})
+		})
+
+		Context("log tail was fetched for the same failure", func() {
+			BeforeEach(func() {
+				t := metav1.NewTime(endTime)
+				vm.Status.GuestCustomization = &vmopv1.VirtualMachineGuestCustomizationStatus{
+					Status:  guestInfo.CustomizationInfo.CustomizationStatus,
+					EndTime: &t,
+					LogTail: "log tail",
+				}
+			})
+			It("keeps the log tail", func() {
+				Expect(vm.Status.GuestCustomization.LogTail).To(Equal("log tail"))
+			})
+		})
+
+		Context("log tail was fetched for a previous failure", func() {
+			BeforeEach(func() {
+				t := metav1.NewTime(endTime.Add(-time.Hour))
+				vm.Status.GuestCustomization = &vmopv1.VirtualMachineGuestCustomizationStatus{
+					Status:  guestInfo.CustomizationInfo.CustomizationStatus,
+					EndTime: &t,
+					LogTail: "log tail",
+				}
+			})
+			It("clears the log tail", func() {
+				Expect(vm.Status.GuestCustomization.LogTail).To(BeEmpty())
+			})
+		})
+
+		Context("bootstrap data hash was recorded", func() {
+			BeforeEach(func() {
+				vm.Status.GuestCustomization = &vmopv1.VirtualMachineGuestCustomizationStatus{
+					DataHash: "hash",
+				}
+			})
+			It("keeps the hash", func() {
+				Expect(vm.Status.GuestCustomization.Status).To(Equal(string(types.GuestInfoCustomizationStatusTOOLSDEPLOYPKG_FAILED)))
+				Expect(vm.Status.GuestCustomization.DataHash).To(Equal("hash"))
+			})
+		})
+	})
+})
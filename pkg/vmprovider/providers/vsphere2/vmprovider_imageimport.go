@@ -0,0 +1,174 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package vsphere
+
+import (
+	goctx "context"
+	"strings"
+
+	"github.com/vmware/govmomi/vapi/library"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
+)
+
+const (
+	// updateFileStatusReady is the status of an update session file that was
+	// transferred and validated.
+	updateFileStatusReady = "READY"
+	// updateFileStatusError is the status of an update session file that
+	// failed to transfer or validate, ex. its checksum did not match.
+	updateFileStatusError = "ERROR"
+)
+
+// ImportContentLibraryItem creates a content library item in the content
+// library and starts pulling the file at the URL into the item. The IDs of
+// the item and of the update session are returned even when the import fails
+// to start so the caller can clean up the item.
+func (vs *vSphereVMProvider) ImportContentLibraryItem(
+	ctx goctx.Context,
+	contentLibrary string,
+	item vmprovider.ContentLibraryItemImportA2) (string, string, error) {
+
+	log.Info("Import Content Library Item", "UUID", contentLibrary, "item name", item.Name, "url", item.URL)
+
+	client, err := vs.getVcClient(ctx)
+	if err != nil {
+		return "", "", err
+	}
+
+	libItem := library.Item{
+		Name:        item.Name,
+		Description: &item.Description,
+		Type:        library.ItemTypeOVF,
+		LibraryID:   contentLibrary,
+	}
+
+	var checksum *library.Checksum
+	if item.Checksum != "" {
+		checksum = &library.Checksum{
+			Algorithm: item.ChecksumAlgorithm,
+			Checksum:  strings.ToLower(item.Checksum),
+		}
+	}
+
+	return client.ContentLibClient().ImportLibraryItemFromURL(ctx, libItem, item.URL, checksum)
+}
+
+// GetContentLibraryItemImportStatus returns the status of the update session
+// that pulls the file into the content library item.
+func (vs *vSphereVMProvider) GetContentLibraryItemImportStatus(
+	ctx goctx.Context,
+	itemID, sessionID, fileName string) (vmprovider.ContentLibraryItemImportStatusA2, error) {
+
+	var status vmprovider.ContentLibraryItemImportStatusA2
+
+	client, err := vs.getVcClient(ctx)
+	if err != nil {
+		return status, err
+	}
+	clClient := client.ContentLibClient()
+
+	session, err := clClient.GetLibraryItemUpdateSession(ctx, sessionID)
+	if err != nil {
+		return status, err
+	}
+
+	if session != nil {
+		switch session.State {
+		case vmprovider.ContentLibraryItemImportStateError:
+			status.State = session.State
+			if session.ErrorMessage != nil {
+				status.Message = session.ErrorMessage.DefaultMessage
+			}
+			return status, nil
+		case vmprovider.ContentLibraryItemImportStateActive:
+			if err := clClient.KeepAliveLibraryItemUpdateSession(ctx, sessionID); err != nil {
+				return status, err
+			}
+
+			file, err := clClient.GetLibraryItemUpdateSessionFile(ctx, sessionID, fileName)
+			if err != nil {
+				return status, err
+			}
+
+			switch file.Status {
+			case updateFileStatusError:
+				status.State = vmprovider.ContentLibraryItemImportStateError
+				if file.ErrorMessage != nil {
+					status.Message = file.ErrorMessage.DefaultMessage
+				}
+			case updateFileStatusReady:
+				status.State = vmprovider.ContentLibraryItemImportStateTransferred
+				status.Progress = 100
+				status.File = file
+			default:
+				status.State = vmprovider.ContentLibraryItemImportStateActive
+				if file.Size > 0 {
+					status.Progress = int32(file.BytesTransferred * 100 / file.Size)
+				}
+			}
+			return status, nil
+		}
+	}
+
+	// The session is done or, once it was completed, expired. The session is
+	// complete when the item has files.
+	files, err := clClient.ListLibraryItemFiles(ctx, itemID)
+	if err != nil {
+		return status, err
+	}
+
+	switch {
+	case len(files) > 0:
+		status.State = vmprovider.ContentLibraryItemImportStateDone
+		status.Progress = 100
+	case session == nil:
+		status.State = vmprovider.ContentLibraryItemImportStateError
+		status.Message = "update session " + sessionID + " does not exist"
+	default:
+		// The completed session is adding the transferred file to the item.
+		status.State = vmprovider.ContentLibraryItemImportStateActive
+		status.Progress = 100
+	}
+
+	return status, nil
+}
+
+// CompleteContentLibraryItemImport completes the update session once its file
+// is transferred, adding the file to the content library item.
+func (vs *vSphereVMProvider) CompleteContentLibraryItemImport(ctx goctx.Context, sessionID string) error {
+	log.Info("Complete Content Library Item Import", "sessionID", sessionID)
+
+	client, err := vs.getVcClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	return client.ContentLibClient().CompleteLibraryItemUpdateSession(ctx, sessionID)
+}
+
+// FailContentLibraryItemImport fails the update session, discarding the file
+// transferred into it.
+func (vs *vSphereVMProvider) FailContentLibraryItemImport(ctx goctx.Context, sessionID string) error {
+	log.Info("Fail Content Library Item Import", "sessionID", sessionID)
+
+	client, err := vs.getVcClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	return client.ContentLibClient().FailLibraryItemUpdateSession(ctx, sessionID)
+}
+
+// DeleteContentLibraryItem deletes the content library item.
+func (vs *vSphereVMProvider) DeleteContentLibraryItem(ctx goctx.Context, itemID string) error {
+	log.Info("Delete Content Library Item", "itemID", itemID)
+
+	client, err := vs.getVcClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	return client.ContentLibClient().DeleteLibraryItem(ctx, itemID)
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package vsphere_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vmware/govmomi/vapi/library"
+	"github.com/vmware/govmomi/vapi/rest"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
+	vsphere "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere2"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+	"github.com/vmware-tanzu/vm-operator/test/testutil"
+)
+
+const (
+	ovaName = "ttylinux.ova"
+	ovfName = "ttylinux-pc_i486-16.1.ovf"
+
+	updateSessionFilePath = rest.Path + "/com/vmware/content/library/item/updatesession/file"
+)
+
+// newOVA returns an OVA of the test image.
+func newOVA() []byte {
+	ovf, err := os.ReadFile(path.Join(testutil.GetRootDirOrDie(), "images", ovfName))
+	Expect(err).ToNot(HaveOccurred())
+
+	var buf bytes.Buffer
+	w := tar.NewWriter(&buf)
+	Expect(w.WriteHeader(&tar.Header{Name: ovfName, Mode: 0600, Size: int64(len(ovf))})).To(Succeed())
+	_, err = w.Write(ovf)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(w.Close()).To(Succeed())
+
+	return buf.Bytes()
+}
+
+// handleUpdateSessionFileGet serves the update session file get requests that
+// vcsim does not implement: the file is looked up by name in the session's
+// files, and reported with the checksum the content library would validate
+// the transferred file against.
+func handleUpdateSessionFileGet(checksum string) func(http.ResponseWriter, *http.Request, http.Handler) {
+	return func(w http.ResponseWriter, r *http.Request, next http.Handler) {
+		if r.URL.Query().Get("~action") != "get" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var spec struct {
+			Name string `json:"file_name"`
+		}
+		Expect(json.NewDecoder(r.Body).Decode(&spec)).To(Succeed())
+
+		list := r.Clone(r.Context())
+		list.Method = http.MethodGet
+		list.URL.Path = updateSessionFilePath
+		list.URL.RawQuery = "update_session_id=" + strings.TrimPrefix(path.Base(r.URL.Path), "id:")
+		list.Body = http.NoBody
+		rec := httptest.NewRecorder()
+		next.ServeHTTP(rec, list)
+		Expect(rec.Code).To(Equal(http.StatusOK))
+
+		var files struct {
+			Value []library.UpdateFile `json:"value"`
+		}
+		Expect(json.NewDecoder(rec.Body).Decode(&files)).To(Succeed())
+
+		for _, f := range files.Value {
+			if f.Name == spec.Name {
+				f.Checksum = &library.Checksum{Algorithm: "SHA256", Checksum: checksum}
+				w.Header().Set("Content-Type", "application/json")
+				Expect(json.NewEncoder(w).Encode(struct {
+					Value library.UpdateFile `json:"value"`
+				}{f})).To(Succeed())
+				return
+			}
+		}
+		http.NotFound(w, r)
+	}
+}
+
+func imageImportTests() {
+
+	var (
+		testConfig builder.VCSimTestConfig
+		ctx        *builder.TestContextForVCSim
+		vmProvider vmprovider.VirtualMachineProviderInterfaceA2
+
+		ovaServer *httptest.Server
+		checksum  string
+	)
+
+	BeforeEach(func() {
+		testConfig = builder.VCSimTestConfig{WithV1A2: true, WithContentLibrary: true}
+
+		ova := newOVA()
+		checksum = fmt.Sprintf("%x", sha256.Sum256(ova))
+		ovaServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write(ova)
+		}))
+	})
+
+	JustBeforeEach(func() {
+		ctx = suite.NewTestContextForVCSim(testConfig)
+		vmProvider = vsphere.NewVSphereVMProviderFromClient(ctx.Client, ctx.Recorder)
+		ctx.HandleFunc(updateSessionFilePath+"/", handleUpdateSessionFileGet(checksum))
+	})
+
+	AfterEach(func() {
+		ovaServer.Close()
+		ctx.AfterEach()
+		ctx = nil
+		vmProvider = nil
+	})
+
+	Context("Import an OVA", func() {
+		var itemID, sessionID string
+
+		JustBeforeEach(func() {
+			var err error
+			itemID, sessionID, err = vmProvider.ImportContentLibraryItem(ctx, ctx.ContentLibraryID,
+				vmprovider.ContentLibraryItemImportA2{
+					Name:              "imported-image",
+					URL:               ovaServer.URL + "/" + ovaName,
+					ChecksumAlgorithm: "SHA256",
+					Checksum:          checksum,
+				})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(itemID).ToNot(BeEmpty())
+			Expect(sessionID).ToNot(BeEmpty())
+		})
+
+		getStatus := func() vmprovider.ContentLibraryItemImportStatusA2 {
+			status, err := vmProvider.GetContentLibraryItemImportStatus(ctx, itemID, sessionID, ovaName)
+			Expect(err).ToNot(HaveOccurred())
+			return status
+		}
+
+		It("reports the checksum of the transferred OVA and adds its OVF to the item once completed", func() {
+			var status vmprovider.ContentLibraryItemImportStatusA2
+			Eventually(func() string {
+				status = getStatus()
+				return status.State
+			}).Should(Equal(vmprovider.ContentLibraryItemImportStateTransferred))
+
+			Expect(status.File).ToNot(BeNil())
+			Expect(status.File.Name).To(Equal(ovaName))
+			Expect(status.File.Checksum).ToNot(BeNil())
+			Expect(status.File.Checksum.Checksum).To(Equal(checksum))
+
+			Expect(vmProvider.CompleteContentLibraryItemImport(ctx, sessionID)).To(Succeed())
+			Expect(getStatus().State).To(Equal(vmprovider.ContentLibraryItemImportStateDone))
+
+			// The content library unpacks the OVA so the item has no OVA file.
+			files, err := library.NewManager(ctx.RestClient).ListLibraryItemFiles(ctx, itemID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(files).To(HaveLen(1))
+			Expect(files[0].Name).To(Equal(ovfName))
+		})
+
+		It("reports an error once failed", func() {
+			Eventually(func() string {
+				return getStatus().State
+			}).Should(Equal(vmprovider.ContentLibraryItemImportStateTransferred))
+
+			Expect(vmProvider.FailContentLibraryItemImport(ctx, sessionID)).To(Succeed())
+			Expect(getStatus().State).To(Equal(vmprovider.ContentLibraryItemImportStateError))
+		})
+	})
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/vmware-tanzu/vm-operator/pkg/lib"
 	"github.com/vmware-tanzu/vm-operator/pkg/topology"
 	"github.com/vmware-tanzu/vm-operator/pkg/util"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
 	vcclient "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere2/client"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere2/constants"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere2/contentlibrary"
@@ -51,6 +52,7 @@ type VMCreateArgs struct {
 	ImageStatus    *vmopv1.VirtualMachineImageStatus
 
 	StorageClassesToIDs   map[string]string
+	VolumeDiskArgs        *virtualmachine.VolumeDiskArgs
 	HasInstanceStorage    bool
 	ChildResourcePoolName string
 	ChildFolderName       string
@@ -252,6 +254,77 @@ func (vs *vSphereVMProvider) GetVirtualMachineWebMKSTicket(
 	return ticket, nil
 }
 
+func (vs *vSphereVMProvider) GetVirtualMachineSerialConsole(
+	ctx goctx.Context,
+	vm *vmopv1.VirtualMachine,
+	pubKey, ticket string) (vmprovider.VirtualMachineSerialConsoleA2, error) {
+
+	vmCtx := context.VirtualMachineContextA2{
+		Context: goctx.WithValue(ctx, types.ID{}, vs.getOpID(vm, "serialconsole")),
+		Logger:  log.WithValues("vmName", vm.NamespacedName()),
+		VM:      vm,
+	}
+
+	proxyURI := lib.GetSerialConsoleProxyURI()
+	if proxyURI == "" {
+		return vmprovider.VirtualMachineSerialConsoleA2{}, errors.New("serial console proxy is not configured")
+	}
+
+	client, err := vs.getVcClient(vmCtx)
+	if err != nil {
+		return vmprovider.VirtualMachineSerialConsoleA2{}, err
+	}
+
+	vcVM, err := vs.getVM(vmCtx, client, true)
+	if err != nil {
+		return vmprovider.VirtualMachineSerialConsoleA2{}, err
+	}
+
+	serviceURI, err := virtualmachine.GetSerialConsole(vmCtx, vcVM, proxyURI)
+	if err != nil {
+		return vmprovider.VirtualMachineSerialConsoleA2{}, err
+	}
+
+	serialConsole := vmprovider.VirtualMachineSerialConsoleA2{
+		ServiceURI: serviceURI,
+	}
+	if ticket != "" {
+		if serialConsole.Response, err = virtualmachine.EncryptWebMKS(pubKey, ticket); err != nil {
+			return vmprovider.VirtualMachineSerialConsoleA2{}, err
+		}
+	}
+
+	return serialConsole, nil
+}
+
+func (vs *vSphereVMProvider) GetVirtualMachineClusterHostAddresses(
+	ctx goctx.Context,
+	vm *vmopv1.VirtualMachine) ([]string, error) {
+
+	vmCtx := context.VirtualMachineContextA2{
+		Context: goctx.WithValue(ctx, types.ID{}, vs.getOpID(vm, "clusterHostAddresses")),
+		Logger:  log.WithValues("vmName", vm.NamespacedName()),
+		VM:      vm,
+	}
+
+	client, err := vs.getVcClient(vmCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	vcVM, err := vs.getVM(vmCtx, client, true)
+	if err != nil {
+		return nil, err
+	}
+
+	cluster, err := virtualmachine.GetVMClusterComputeResource(vmCtx, vcVM)
+	if err != nil {
+		return nil, err
+	}
+
+	return vcenter.ClusterHostAddresses(vmCtx, cluster)
+}
+
 func (vs *vSphereVMProvider) GetVirtualMachineHardwareVersion(
 	ctx goctx.Context,
 	vm *vmopv1.VirtualMachine) (int32, error) {
@@ -305,6 +378,10 @@ func (vs *vSphereVMProvider) createVirtualMachine(
 		return nil, nil, err
 	}
 
+	// The disks of the ephemeral and image volumes were only needed for placement. They are
+	// added by the pre power on reconfigure, which also records each disk in the ExtraConfig.
+	util.RemoveDevicesFromConfigSpec(createArgs.ConfigSpec, virtualmachine.IsVolumeDiskDevice)
+
 	err = vs.vmCreateIsReady(vmCtx, vcClient, createArgs)
 	if err != nil {
 		return nil, nil, err
@@ -344,6 +421,7 @@ func (vs *vSphereVMProvider) createVirtualMachine(
 		Kind:       createArgs.VMClass.Kind,
 		Name:       createArgs.VMClass.Name,
 	}
+	vmCtx.VM.Status.StorageClass = vmCtx.VM.Spec.StorageClass
 	conditions.MarkTrue(vmCtx.VM, vmopv1.VirtualMachineConditionCreated)
 
 	return object.NewVirtualMachine(vcClient.VimClient(), *moRef), createArgs, nil
@@ -387,7 +465,7 @@ func (vs *vSphereVMProvider) updateVirtualMachine(
 		getUpdateArgsFn := func() (*vmUpdateArgs, error) {
 			// TODO: Use createArgs if we already got them
 			_ = createArgs
-			return vs.vmUpdateGetArgs(vmCtx)
+			return vs.vmUpdateGetArgs(vmCtx, vcClient)
 		}
 
 		err = ses.UpdateVirtualMachine(vmCtx, vcVM, getUpdateArgsFn)
@@ -828,7 +906,15 @@ func (vs *vSphereVMProvider) vmCreateGetStoragePrereqs(
 		return err
 	}
 
+	volumeDiskArgs, err := vs.getVolumeDiskArgs(vmCtx, vcClient, storageClassesToIDs)
+	if err != nil {
+		reason, msg := errToConditionReasonAndMessage(err)
+		conditions.MarkFalse(vmCtx.VM, vmopv1.VirtualMachineConditionStorageReady, reason, msg)
+		return err
+	}
+
 	createArgs.StorageClassesToIDs = storageClassesToIDs
+	createArgs.VolumeDiskArgs = volumeDiskArgs
 	createArgs.StorageProvisioning = provisioningType
 	createArgs.StorageProfileID = vmStorageProfileID
 	conditions.MarkTrue(vmCtx.VM, vmopv1.VirtualMachineConditionStorageReady)
@@ -836,6 +922,94 @@ func (vs *vSphereVMProvider) vmCreateGetStoragePrereqs(
 	return nil
 }
 
+// getVolumeDiskArgs returns the arguments needed to create the disks of the VM's ephemeral
+// and image volumes, or nil if the VM does not have any such volumes.
+func (vs *vSphereVMProvider) getVolumeDiskArgs(
+	vmCtx context.VirtualMachineContextA2,
+	vcClient *vcclient.Client,
+	storageClassesToIDs map[string]string) (*virtualmachine.VolumeDiskArgs, error) {
+
+	volumes := virtualmachine.FilterVolumeDiskVolumes(vmCtx.VM)
+	if len(volumes) == 0 {
+		return nil, nil
+	}
+
+	if storageClassesToIDs == nil {
+		var err error
+		storageClassesToIDs, err = storage.GetVMStoragePoliciesIDs(vmCtx, vs.k8sClient)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	imageDiskPaths := map[string]string{}
+	for _, volume := range volumes {
+		if volume.Image == nil {
+			continue
+		}
+
+		diskPath, err := vs.getImageVolumeDiskPath(vmCtx, vcClient, volume.Image)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the image disk of volume %s: %w", volume.Name, err)
+		}
+		imageDiskPaths[volume.Name] = diskPath
+	}
+
+	return &virtualmachine.VolumeDiskArgs{
+		StorageClassesToIDs: storageClassesToIDs,
+		ImageDiskPaths:      imageDiskPaths,
+	}, nil
+}
+
+// getImageVolumeDiskPath returns the datastore path of the image disk that an image volume
+// is cloned from. The path is resolved from the library item's storage since the file name
+// on the datastore differs from the library item file name.
+func (vs *vSphereVMProvider) getImageVolumeDiskPath(
+	vmCtx context.VirtualMachineContextA2,
+	vcClient *vcclient.Client,
+	source *vmopv1.ImageVolumeSource) (string, error) {
+
+	providerItemID, err := GetImageVolumeProviderItemID(vmCtx, vs.k8sClient, source.Name)
+	if err != nil {
+		return "", err
+	}
+
+	disks, err := vcClient.ContentLibClient().GetLibraryItemDiskStorage(vmCtx, providerItemID)
+	if err != nil {
+		return "", err
+	}
+
+	if int(source.DiskIndex) >= len(disks) {
+		return "", fmt.Errorf("image %s has %d disks but disk index %d was requested",
+			source.Name, len(disks), source.DiskIndex)
+	}
+	disk := disks[source.DiskIndex]
+
+	datastore := object.NewDatastore(vcClient.VimClient(), types.ManagedObjectReference{
+		Type:  "Datastore",
+		Value: disk.StorageBacking.DatastoreID,
+	})
+
+	var moDS mo.Datastore
+	if err := datastore.Properties(vmCtx, datastore.Reference(), []string{"name", "summary.url"}, &moDS); err != nil {
+		return "", err
+	}
+
+	datastoreURL := strings.TrimSuffix(moDS.Summary.Url, "/") + "/"
+	for _, uri := range disk.StorageURIs {
+		if strings.HasPrefix(uri, datastoreURL) {
+			diskPath := object.DatastorePath{
+				Datastore: moDS.Name,
+				Path:      strings.TrimPrefix(uri, datastoreURL),
+			}
+			return diskPath.String(), nil
+		}
+	}
+
+	return "", fmt.Errorf("disk %s of image %s is not stored on datastore %s",
+		disk.Name, source.Name, moDS.Name)
+}
+
 func (vs *vSphereVMProvider) vmCreateDoNetworking(
 	vmCtx context.VirtualMachineContextA2,
 	vcClient *vcclient.Client,
@@ -896,7 +1070,8 @@ func (vs *vSphereVMProvider) vmCreateGenConfigSpec(
 		vmClassConfigSpec,
 		&createArgs.VMClass.Spec,
 		createArgs.ImageStatus,
-		minCPUFreq)
+		minCPUFreq,
+		createArgs.VolumeDiskArgs)
 
 	// TODO: This should be in CreateConfigSpec()
 	if createArgs.ConfigSpec.Version == "" {
@@ -1101,7 +1276,8 @@ func (vs *vSphereVMProvider) vmCreateValidateArgs(
 }
 
 func (vs *vSphereVMProvider) vmUpdateGetArgs(
-	vmCtx context.VirtualMachineContextA2) (*vmUpdateArgs, error) {
+	vmCtx context.VirtualMachineContextA2,
+	vcClient *vcclient.Client) (*vmUpdateArgs, error) {
 
 	vmClass, err := GetVirtualMachineClass(vmCtx, vs.k8sClient)
 	if err != nil {
@@ -1146,6 +1322,7 @@ func (vs *vSphereVMProvider) vmUpdateGetArgs(
 	}
 
 	var vmImageStatus *vmopv1.VirtualMachineImageStatus
+	var volumeDiskArgs *virtualmachine.VolumeDiskArgs
 	// Only get VM image when this is the VM first boot.
 	if isVMFirstBoot(vmCtx) {
 		var err error
@@ -1154,6 +1331,12 @@ func (vs *vSphereVMProvider) vmUpdateGetArgs(
 			return nil, err
 		}
 
+		// The disks of the ephemeral and image volumes are added before the VM is first powered on.
+		volumeDiskArgs, err = vs.getVolumeDiskArgs(vmCtx, vcClient, nil)
+		if err != nil {
+			return nil, err
+		}
+
 		// The only use of this is for the global JSON_EXTRA_CONFIG to set the image name.
 		// The global extra config should only be set during first boot.
 		// TODO: We can just finally kill this with the demise of old gce2e?
@@ -1187,7 +1370,8 @@ func (vs *vSphereVMProvider) vmUpdateGetArgs(
 		vmClassConfigSpec,
 		&updateArgs.VMClass.Spec,
 		vmImageStatus,
-		updateArgs.MinCPUFreq)
+		updateArgs.MinCPUFreq,
+		volumeDiskArgs)
 
 	return updateArgs, nil
 }
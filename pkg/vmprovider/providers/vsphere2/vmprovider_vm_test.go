@@ -9,20 +9,37 @@ import (
 	"encoding/json"
 	"fmt"
 	"math/rand"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	. "github.com/onsi/gomega/gstruct"
 
 	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/pbm"
+	pbmMethods "github.com/vmware/govmomi/pbm/methods"
+	pbmSimulator "github.com/vmware/govmomi/pbm/simulator"
+	pbmTypes "github.com/vmware/govmomi/pbm/types"
+	"github.com/vmware/govmomi/simulator"
 	"github.com/vmware/govmomi/vapi/cluster"
+	"github.com/vmware/govmomi/vapi/library"
+	"github.com/vmware/govmomi/vapi/rest"
+	vapisimulator "github.com/vmware/govmomi/vapi/simulator"
 	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/soap"
 	"github.com/vmware/govmomi/vim25/types"
 
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
@@ -30,14 +47,49 @@ import (
 	conditions "github.com/vmware-tanzu/vm-operator/pkg/conditions2"
 	"github.com/vmware-tanzu/vm-operator/pkg/context"
 	"github.com/vmware-tanzu/vm-operator/pkg/topology"
+	"github.com/vmware-tanzu/vm-operator/pkg/util"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
 	vsphere "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere2"
+	vsphereconfig "github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere2/config"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere2/constants"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere2/contentlibrary"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere2/instancestorage"
 	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere2/virtualmachine"
 	"github.com/vmware-tanzu/vm-operator/test/builder"
 )
 
+func init() {
+	// vC Sim does not implement the library item storage service, so list the
+	// files in the item's directory on the datastore like vCenter would.
+	simulator.RegisterEndpoint(func(s *simulator.Service, r *simulator.Registry) {
+		s.HandleFunc(rest.Path+"/com/vmware/content/library/item/storage", func(w http.ResponseWriter, req *http.Request) {
+			itemID := req.URL.Query().Get("library_item_id")
+
+			var storage []contentlibrary.LibraryItemFileStorage
+			for _, e := range r.All("Datastore") {
+				ds := e.(*simulator.Datastore)
+				files, _ := filepath.Glob(filepath.Join(ds.Summary.Url, "contentlib-*", itemID, "*"))
+				for _, file := range files {
+					// The flat extent of a disk is not a file of the item.
+					if strings.HasSuffix(file, "-flat.vmdk") {
+						continue
+					}
+					storage = append(storage, contentlibrary.LibraryItemFileStorage{
+						Name: filepath.Base(file),
+						StorageBacking: library.StorageBackings{
+							DatastoreID: ds.Self.Value,
+							Type:        "DATASTORE",
+						},
+						StorageURIs: []string{file},
+					})
+				}
+			}
+
+			vapisimulator.OK(w, storage)
+		})
+	})
+}
+
 func vmTests() {
 
 	const (
@@ -1334,6 +1386,267 @@ func vmTests() {
 				Expect(state).To(Equal(types.VirtualMachinePowerStatePoweredOff))
 			})
 
+			Context("Guest customization failed", func() {
+				JustBeforeEach(func() {
+					vm.Spec.Bootstrap = &vmopv1.VirtualMachineBootstrapSpec{
+						LinuxPrep: &vmopv1.VirtualMachineBootstrapLinuxPrepSpec{},
+						Customization: &vmopv1.VirtualMachineBootstrapCustomizationSpec{
+							RetryOnSecretChange: true,
+						},
+					}
+				})
+
+				It("Powers VM off to retry customization once the bootstrap data changes", func() {
+					vcVM, err := createOrUpdateAndGetVcVM(ctx, vm)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(vm.Status.PowerState).To(Equal(vmopv1.VirtualMachinePowerStateOn))
+					Expect(vm.Status.GuestCustomization).ToNot(BeNil())
+					Expect(vm.Status.GuestCustomization.DataHash).ToNot(BeEmpty())
+
+					markFailed := func() {
+						conditions.MarkFalse(vm, vmopv1.GuestCustomizationCondition,
+							vmopv1.GuestCustomizationFailedReason, "customization failed")
+					}
+
+					By("Not retrying when the bootstrap data is unchanged", func() {
+						markFailed()
+						Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+						Expect(vm.Status.PowerState).To(Equal(vmopv1.VirtualMachinePowerStateOn))
+					})
+
+					By("Retrying when the bootstrap data changed", func() {
+						markFailed()
+						vm.Status.GuestCustomization.DataHash = "stale"
+						Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+						Expect(vm.Status.PowerState).To(Equal(vmopv1.VirtualMachinePowerStateOff))
+
+						state, err := vcVM.PowerState(ctx)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(state).To(Equal(types.VirtualMachinePowerStatePoweredOff))
+					})
+
+					By("Customizing the VM again when it is powered back on", func() {
+						Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+						Expect(vm.Status.PowerState).To(Equal(vmopv1.VirtualMachinePowerStateOn))
+						Expect(vm.Status.GuestCustomization).ToNot(BeNil())
+						Expect(vm.Status.GuestCustomization.DataHash).ToNot(Equal("stale"))
+					})
+				})
+			})
+
+			Context("VM Class is changed", func() {
+				var newVMClass *vmopv1.VirtualMachineClass
+
+				JustBeforeEach(func() {
+					newVMClass = builder.DummyVirtualMachineClassA2()
+					newVMClass.Namespace = nsInfo.Namespace
+					newVMClass.Spec.Hardware.Cpus = 4
+					newVMClass.Spec.Hardware.Memory = resource.MustParse("8Gi")
+					Expect(ctx.Client.Create(ctx, newVMClass)).To(Succeed())
+				})
+
+				It("Resizes the VM when it is next powered on", func() {
+					vcVM, err := createOrUpdateAndGetVcVM(ctx, vm)
+					Expect(err).ToNot(HaveOccurred())
+
+					vm.Spec.ClassName = newVMClass.Name
+					Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+
+					var o mo.VirtualMachine
+					By("VM requires a restart", func() {
+						c := conditions.Get(vm, vmopv1.VirtualMachineConditionResized)
+						Expect(c).ToNot(BeNil())
+						Expect(c.Status).To(Equal(metav1.ConditionFalse))
+						Expect(c.Reason).To(Equal(vmopv1.VirtualMachineResizeRestartRequiredReason))
+						Expect(vm.Status.Class.Name).To(Equal(vmClass.Name))
+
+						Expect(vcVM.Properties(ctx, vcVM.Reference(), []string{"config.hardware"}, &o)).To(Succeed())
+						Expect(o.Config.Hardware.NumCPU).To(BeEquivalentTo(2))
+					})
+
+					By("VM resize is pending while powered off", func() {
+						vm.Spec.PowerState = vmopv1.VirtualMachinePowerStateOff
+						Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+
+						c := conditions.Get(vm, vmopv1.VirtualMachineConditionResized)
+						Expect(c).ToNot(BeNil())
+						Expect(c.Reason).To(Equal(vmopv1.VirtualMachineResizePendingReason))
+					})
+
+					By("VM is resized when powered on", func() {
+						vm.Spec.PowerState = vmopv1.VirtualMachinePowerStateOn
+						Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+
+						Expect(conditions.IsTrue(vm, vmopv1.VirtualMachineConditionResized)).To(BeTrue())
+						Expect(vm.Status.Class.Name).To(Equal(newVMClass.Name))
+
+						Expect(vcVM.Properties(ctx, vcVM.Reference(), []string{"config.hardware"}, &o)).To(Succeed())
+						Expect(o.Config.Hardware.NumCPU).To(BeEquivalentTo(4))
+						Expect(o.Config.Hardware.MemoryMB).To(BeEquivalentTo(8 * 1024))
+					})
+				})
+
+				It("Hot-adds CPU and memory when enabled on the VM", func() {
+					vcVM, err := createOrUpdateAndGetVcVM(ctx, vm)
+					Expect(err).ToNot(HaveOccurred())
+
+					task, err := vcVM.Reconfigure(ctx, types.VirtualMachineConfigSpec{
+						CpuHotAddEnabled:    pointer.Bool(true),
+						MemoryHotAddEnabled: pointer.Bool(true),
+					})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(task.Wait(ctx)).To(Succeed())
+
+					vm.Spec.ClassName = newVMClass.Name
+					Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+
+					Expect(conditions.IsTrue(vm, vmopv1.VirtualMachineConditionResized)).To(BeTrue())
+					Expect(vm.Status.Class.Name).To(Equal(newVMClass.Name))
+					Expect(vm.Status.PowerState).To(Equal(vmopv1.VirtualMachinePowerStateOn))
+
+					var o mo.VirtualMachine
+					Expect(vcVM.Properties(ctx, vcVM.Reference(), []string{"config.hardware"}, &o)).To(Succeed())
+					Expect(o.Config.Hardware.NumCPU).To(BeEquivalentTo(4))
+					Expect(o.Config.Hardware.MemoryMB).To(BeEquivalentTo(8 * 1024))
+				})
+			})
+
+			Context("StorageClass is changed", func() {
+				var newStorageClass *storagev1.StorageClass
+
+				JustBeforeEach(func() {
+					newStorageClass = &storagev1.StorageClass{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "new-storage-class",
+						},
+						Parameters: map[string]string{
+							"storagePolicyID": ctx.StorageProfileID,
+						},
+					}
+					Expect(ctx.Client.Create(ctx, newStorageClass)).To(Succeed())
+				})
+
+				It("Relocates the VM storage while the VM is powered on", func() {
+					vcVM, err := createOrUpdateAndGetVcVM(ctx, vm)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(vm.Status.StorageClass).To(Equal(ctx.StorageClassName))
+					Expect(conditions.Get(vm, vmopv1.VirtualMachineConditionStorageRelocated)).To(BeNil())
+
+					vm.Spec.StorageClass = newStorageClass.Name
+					Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+
+					Expect(conditions.IsTrue(vm, vmopv1.VirtualMachineConditionStorageRelocated)).To(BeTrue())
+					Expect(vm.Status.StorageClass).To(Equal(newStorageClass.Name))
+					Expect(vm.Status.PowerState).To(Equal(vmopv1.VirtualMachinePowerStateOn))
+
+					Expect(vm.Annotations).ToNot(HaveKey(constants.StorageRelocateTaskAnnotation))
+
+					var o mo.VirtualMachine
+					Expect(vcVM.Properties(ctx, vcVM.Reference(), []string{"datastore"}, &o)).To(Succeed())
+					Expect(o.Datastore).To(HaveLen(1))
+				})
+
+				It("Retries the relocation once it has backed off after the relocation task failed", func() {
+					vcVM, err := createOrUpdateAndGetVcVM(ctx, vm)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(vm.Status.PowerState).To(Equal(vmopv1.VirtualMachinePowerStateOn))
+
+					// Powering on the powered on VM fails.
+					failedTask, err := vcVM.PowerOn(ctx)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(failedTask.Wait(ctx)).ToNot(Succeed())
+
+					vm.Spec.StorageClass = newStorageClass.Name
+					vm.Annotations[constants.StorageRelocateTaskAnnotation] = failedTask.Reference().Value
+					conditions.MarkFalse(vm, vmopv1.VirtualMachineConditionStorageRelocated,
+						vmopv1.VirtualMachineStorageRelocationInProgressReason, "")
+					Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+
+					Expect(conditions.GetReason(vm, vmopv1.VirtualMachineConditionStorageRelocated)).
+						To(Equal(vmopv1.VirtualMachineStorageRelocationFailedReason))
+					Expect(vm.Status.StorageClass).To(Equal(ctx.StorageClassName))
+					Expect(vm.Annotations).ToNot(HaveKey(constants.StorageRelocateTaskAnnotation))
+
+					By("Backs off the relocation", func() {
+						Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+						Expect(conditions.GetReason(vm, vmopv1.VirtualMachineConditionStorageRelocated)).
+							To(Equal(vmopv1.VirtualMachineStorageRelocationFailedReason))
+						Expect(vm.Status.StorageClass).To(Equal(ctx.StorageClassName))
+						Expect(vm.Annotations).ToNot(HaveKey(constants.StorageRelocateTaskAnnotation))
+					})
+
+					for i := range vm.Status.Conditions {
+						if vm.Status.Conditions[i].Type == vmopv1.VirtualMachineConditionStorageRelocated {
+							vm.Status.Conditions[i].LastTransitionTime = metav1.NewTime(time.Now().Add(-time.Hour))
+						}
+					}
+					Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+					Expect(conditions.IsTrue(vm, vmopv1.VirtualMachineConditionStorageRelocated)).To(BeTrue())
+					Expect(vm.Status.StorageClass).To(Equal(newStorageClass.Name))
+				})
+
+				It("Records the storage class the relocation task was started for", func() {
+					vcVM, err := createOrUpdateAndGetVcVM(ctx, vm)
+					Expect(err).ToNot(HaveOccurred())
+
+					// A task that succeeds stands in for the relocation to the new
+					// storage class, during which the spec changed to another one.
+					otherStorageClass := newStorageClass.DeepCopy()
+					otherStorageClass.ObjectMeta = metav1.ObjectMeta{Name: "other-storage-class"}
+					Expect(ctx.Client.Create(ctx, otherStorageClass)).To(Succeed())
+
+					task, err := vcVM.Reconfigure(ctx, types.VirtualMachineConfigSpec{})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(task.Wait(ctx)).To(Succeed())
+
+					vm.Spec.StorageClass = otherStorageClass.Name
+					vm.Annotations[constants.StorageRelocateTaskAnnotation] = task.Reference().Value
+					vm.Annotations[constants.StorageRelocateStorageClassAnnotation] = newStorageClass.Name
+					Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+
+					Expect(vm.Status.StorageClass).To(Equal(newStorageClass.Name))
+					Expect(vm.Annotations).ToNot(HaveKey(constants.StorageRelocateTaskAnnotation))
+					Expect(vm.Annotations).ToNot(HaveKey(constants.StorageRelocateStorageClassAnnotation))
+
+					Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+					Expect(conditions.IsTrue(vm, vmopv1.VirtualMachineConditionStorageRelocated)).To(BeTrue())
+					Expect(vm.Status.StorageClass).To(Equal(otherStorageClass.Name))
+				})
+
+				It("Ignores the task of another operation and tasks that no longer exist", func() {
+					vcVM, err := createOrUpdateAndGetVcVM(ctx, vm)
+					Expect(err).ToNot(HaveOccurred())
+
+					failedTask, err := vcVM.PowerOn(ctx)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(failedTask.Wait(ctx)).ToNot(Succeed())
+
+					vm.Spec.StorageClass = newStorageClass.Name
+					vm.Annotations[constants.ZoneMigrateTaskAnnotation] = failedTask.Reference().Value
+					vm.Annotations[constants.StorageRelocateTaskAnnotation] = "task-does-not-exist"
+					Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+
+					Expect(conditions.IsTrue(vm, vmopv1.VirtualMachineConditionStorageRelocated)).To(BeTrue())
+					Expect(vm.Status.StorageClass).To(Equal(newStorageClass.Name))
+					Expect(vm.Annotations).ToNot(HaveKey(constants.StorageRelocateTaskAnnotation))
+					Expect(vm.Annotations).To(HaveKeyWithValue(constants.ZoneMigrateTaskAnnotation, failedTask.Reference().Value))
+				})
+
+				It("Returns an error when the StorageClass does not have a storage policy", func() {
+					_, err := createOrUpdateAndGetVcVM(ctx, vm)
+					Expect(err).ToNot(HaveOccurred())
+
+					newStorageClass.Parameters = nil
+					Expect(ctx.Client.Update(ctx, newStorageClass)).To(Succeed())
+
+					vm.Spec.StorageClass = newStorageClass.Name
+					err = vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("does not have 'storagePolicyID' parameter"))
+					Expect(vm.Status.StorageClass).To(Equal(ctx.StorageClassName))
+				})
+			})
+
 			It("returns error when StorageClass is required but none specified", func() {
 				vm.Spec.StorageClass = ""
 				err := vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)
@@ -1487,6 +1800,105 @@ func vmTests() {
 						_, dvpg := getDVPG(ctx, dvpgName)
 						Expect(backing2.Port.PortgroupKey).To(Equal(dvpg.Reference().Value))
 					})
+
+					It("Hot-plugs NICs when the VM is powered on", func() {
+						vm.Spec.Bootstrap = &vmopv1.VirtualMachineBootstrapSpec{
+							CloudInit: &vmopv1.VirtualMachineBootstrapCloudInitSpec{},
+						}
+
+						vcVM, err := createOrUpdateAndGetVcVM(ctx, vm)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(vm.Status.PowerState).To(Equal(vmopv1.VirtualMachinePowerStateOn))
+
+						getEthCards := func() object.VirtualDeviceList {
+							var o mo.VirtualMachine
+							Expect(vcVM.Properties(ctx, vcVM.Reference(), []string{"config.hardware"}, &o)).To(Succeed())
+							return object.VirtualDeviceList(o.Config.Hardware.Device).SelectByType(&types.VirtualEthernetCard{})
+						}
+
+						By("Hot-removes a NIC", func() {
+							vm.Spec.Network.Interfaces = vm.Spec.Network.Interfaces[:1]
+							Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+
+							l := getEthCards()
+							Expect(l).To(HaveLen(1))
+							backing, ok := l[0].GetVirtualDevice().Backing.(*types.VirtualEthernetCardNetworkBackingInfo)
+							Expect(ok).Should(BeTrue())
+							Expect(backing.DeviceName).To(Equal("VM Network"))
+						})
+
+						By("Hot-adds a NIC", func() {
+							vm.Spec.Network.Interfaces = append(vm.Spec.Network.Interfaces, vmopv1.VirtualMachineNetworkInterfaceSpec{
+								Name:    "eth2",
+								Network: common.PartialObjectRef{Name: dvpgName},
+							})
+							Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+
+							l := getEthCards()
+							Expect(l).To(HaveLen(2))
+							_, ok := l[1].GetVirtualDevice().Backing.(*types.VirtualEthernetCardDistributedVirtualPortBackingInfo)
+							Expect(ok).Should(BeTrue())
+
+							Expect(vm.Status.Network).ToNot(BeNil())
+							Expect(vm.Status.Network.PendingGuestConfig).To(HaveLen(1))
+							Expect(vm.Status.Network.PendingGuestConfig[0].Name).To(Equal("eth2"))
+							macAddr := l[1].(types.BaseVirtualEthernetCard).GetVirtualEthernetCard().MacAddress
+							Expect(vm.Status.Network.PendingGuestConfig[0].MACAddr).To(Equal(macAddr))
+
+							var o mo.VirtualMachine
+							Expect(vcVM.Properties(ctx, vcVM.Reference(), []string{"config.extraConfig"}, &o)).To(Succeed())
+							ecMap := util.ExtraConfigToMap(o.Config.ExtraConfig)
+							metadata, err := util.TryToDecodeBase64Gzip([]byte(ecMap[constants.CloudInitGuestInfoMetadata]))
+							Expect(err).ToNot(HaveOccurred())
+							Expect(metadata).To(ContainSubstring("eth2"))
+							Expect(metadata).To(ContainSubstring(macAddr))
+						})
+
+						By("Does not change the NICs when the spec is unchanged", func() {
+							Expect(vm.Annotations).To(HaveKey(vmopv1.NetworkSpecHashAnnotation))
+							Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+							Expect(getEthCards()).To(HaveLen(2))
+							Expect(vm.Status.Network.PendingGuestConfig).To(HaveLen(1))
+						})
+
+						By("Updates the guest network when retried after the NIC was added", func() {
+							networkCM := &corev1.ConfigMap{}
+							networkCMKey := client.ObjectKey{Name: vsphereconfig.NetworkConfigMapName, Namespace: ctx.PodNamespace}
+							Expect(ctx.Client.Get(ctx, networkCMKey, networkCM)).To(Succeed())
+							nameservers := networkCM.Data[vsphereconfig.NameserversKey]
+
+							// Without nameservers for the static interface, the
+							// guest network update fails after the NIC is added.
+							networkCM.Data[vsphereconfig.NameserversKey] = ""
+							Expect(ctx.Client.Update(ctx, networkCM)).To(Succeed())
+
+							vm.Spec.Network.Interfaces = append(vm.Spec.Network.Interfaces, vmopv1.VirtualMachineNetworkInterfaceSpec{
+								Name:      "eth3",
+								Network:   common.PartialObjectRef{Name: dvpgName},
+								Addresses: []string{"192.168.1.10/24"},
+								Gateway4:  "192.168.1.1",
+							})
+							hash := vm.Annotations[vmopv1.NetworkSpecHashAnnotation]
+							Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).ToNot(Succeed())
+							Expect(getEthCards()).To(HaveLen(3))
+							Expect(vm.Annotations).To(HaveKeyWithValue(vmopv1.NetworkSpecHashAnnotation, hash))
+
+							networkCM.Data[vsphereconfig.NameserversKey] = nameservers
+							Expect(ctx.Client.Update(ctx, networkCM)).To(Succeed())
+
+							Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+							Expect(getEthCards()).To(HaveLen(3))
+							Expect(vm.Annotations[vmopv1.NetworkSpecHashAnnotation]).ToNot(Equal(hash))
+
+							var o mo.VirtualMachine
+							Expect(vcVM.Properties(ctx, vcVM.Reference(), []string{"config.extraConfig"}, &o)).To(Succeed())
+							ecMap := util.ExtraConfigToMap(o.Config.ExtraConfig)
+							metadata, err := util.TryToDecodeBase64Gzip([]byte(ecMap[constants.CloudInitGuestInfoMetadata]))
+							Expect(err).ToNot(HaveOccurred())
+							Expect(metadata).To(ContainSubstring("eth3"))
+							Expect(metadata).To(ContainSubstring("192.168.1.10"))
+						})
+					})
 				})
 			})
 
@@ -1568,6 +1980,410 @@ func vmTests() {
 						disk, _ := getVMHomeDisk(ctx, vcVM, o)
 						Expect(disk.CapacityInBytes).To(BeEquivalentTo(newSize.Value()))
 					})
+
+					It("Extends the root disk when the VM is powered on", func() {
+						vm.Spec.PowerState = vmopv1.VirtualMachinePowerStateOn
+						vcVM, err := createOrUpdateAndGetVcVM(ctx, vm)
+						Expect(err).ToNot(HaveOccurred())
+
+						var o mo.VirtualMachine
+						Expect(vcVM.Properties(ctx, vcVM.Reference(), nil, &o)).To(Succeed())
+						disk, _ := getVMHomeDisk(ctx, vcVM, o)
+
+						newSize := *resource.NewQuantity(disk.CapacityInBytes, resource.BinarySI)
+						newSize.Add(resource.MustParse("1Gi"))
+						if vm.Spec.Advanced == nil {
+							vm.Spec.Advanced = &vmopv1.VirtualMachineAdvancedSpec{}
+						}
+						vm.Spec.Advanced.BootDiskCapacity = &newSize
+						Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+
+						Expect(vcVM.Properties(ctx, vcVM.Reference(), nil, &o)).To(Succeed())
+						Expect(o.Runtime.PowerState).To(Equal(types.VirtualMachinePowerStatePoweredOn))
+						disk, _ = getVMHomeDisk(ctx, vcVM, o)
+						Expect(disk.CapacityInBytes).To(BeEquivalentTo(newSize.Value()))
+
+						By("Hints the new capacity to the guest", func() {
+							ecMap := util.ExtraConfigToMap(o.Config.ExtraConfig)
+							Expect(ecMap).To(HaveKeyWithValue(constants.BootDiskCapacityExtraConfigKey, strconv.FormatInt(newSize.Value(), 10)))
+						})
+
+						Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+						Expect(conditions.IsTrue(vm, vmopv1.VirtualMachineConditionBootDiskResized)).To(BeTrue())
+					})
+
+					It("Ignores a root disk capacity less than the disk's capacity", func() {
+						vm.Spec.PowerState = vmopv1.VirtualMachinePowerStateOn
+						vcVM, err := createOrUpdateAndGetVcVM(ctx, vm)
+						Expect(err).ToNot(HaveOccurred())
+
+						var o mo.VirtualMachine
+						Expect(vcVM.Properties(ctx, vcVM.Reference(), nil, &o)).To(Succeed())
+						disk, _ := getVMHomeDisk(ctx, vcVM, o)
+
+						newSize := *resource.NewQuantity(disk.CapacityInBytes-1024*1024, resource.BinarySI)
+						if vm.Spec.Advanced == nil {
+							vm.Spec.Advanced = &vmopv1.VirtualMachineAdvancedSpec{}
+						}
+						vm.Spec.Advanced.BootDiskCapacity = &newSize
+						Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+
+						Expect(conditions.IsFalse(vm, vmopv1.VirtualMachineConditionBootDiskResized)).To(BeTrue())
+						Expect(conditions.GetReason(vm, vmopv1.VirtualMachineConditionBootDiskResized)).
+							To(Equal(vmopv1.VirtualMachineBootDiskShrinkNotSupportedReason))
+
+						Expect(vcVM.Properties(ctx, vcVM.Reference(), nil, &o)).To(Succeed())
+						capacity := disk.CapacityInBytes
+						disk, _ = getVMHomeDisk(ctx, vcVM, o)
+						Expect(disk.CapacityInBytes).To(Equal(capacity))
+						Expect(util.ExtraConfigToMap(o.Config.ExtraConfig)).ToNot(HaveKey(constants.BootDiskCapacityExtraConfigKey))
+					})
+				})
+			})
+
+			Context("Volume attach options", func() {
+				It("Are not applied to a volume hot-attached to a powered on VM", func() {
+					vm.Spec.PowerState = vmopv1.VirtualMachinePowerStateOn
+					vcVM, err := createOrUpdateAndGetVcVM(ctx, vm)
+					Expect(err).ToNot(HaveOccurred())
+
+					var o mo.VirtualMachine
+					Expect(vcVM.Properties(ctx, vcVM.Reference(), nil, &o)).To(Succeed())
+					_, backing := getVMHomeDisk(ctx, vcVM, o)
+					Expect(backing.Uuid).ToNot(BeEmpty())
+					diskMode := backing.DiskMode
+
+					// Stand in for the disk CNS attaches while the VM is powered on.
+					vm.Spec.Volumes = append(vm.Spec.Volumes, vmopv1.VirtualMachineVolume{
+						Name: "hot-vol",
+						VirtualMachineVolumeSource: vmopv1.VirtualMachineVolumeSource{
+							PersistentVolumeClaim: &vmopv1.PersistentVolumeClaimVolumeSource{
+								PersistentVolumeClaimVolumeSource: corev1.PersistentVolumeClaimVolumeSource{
+									ClaimName: "pvc-claim-hot",
+								},
+								DiskMode:    vmopv1.VirtualMachineVolumeDiskModeIndependentPersistent,
+								SharingMode: vmopv1.VirtualMachineVolumeSharingModeMultiWriter,
+							},
+						},
+					})
+					vm.Status.Volumes = append(vm.Status.Volumes, vmopv1.VirtualMachineVolumeStatus{
+						Name:     "hot-vol",
+						Attached: true,
+						DiskUUID: backing.Uuid,
+					})
+					Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+
+					Expect(vcVM.Properties(ctx, vcVM.Reference(), nil, &o)).To(Succeed())
+					Expect(o.Runtime.PowerState).To(Equal(types.VirtualMachinePowerStatePoweredOn))
+					_, backing = getVMHomeDisk(ctx, vcVM, o)
+					Expect(backing.DiskMode).To(Equal(diskMode))
+					Expect(backing.Sharing).ToNot(Equal(string(types.VirtualDiskSharingSharingMultiWriter)))
+				})
+			})
+
+			Context("Ephemeral volumes", func() {
+				const volumeName = "scratch"
+				scratchSize := resource.MustParse("2Gi")
+
+				JustBeforeEach(func() {
+					vm.Spec.Volumes = append(vm.Spec.Volumes, vmopv1.VirtualMachineVolume{
+						Name: volumeName,
+						VirtualMachineVolumeSource: vmopv1.VirtualMachineVolumeSource{
+							Ephemeral: &vmopv1.EphemeralVolumeSource{
+								StorageClass: ctx.StorageClassName,
+								Size:         scratchSize,
+							},
+						},
+					})
+				})
+
+				It("Adds the disk once before the VM is powered on", func() {
+					vm.Spec.PowerState = vmopv1.VirtualMachinePowerStateOn
+					vcVM, err := createOrUpdateAndGetVcVM(ctx, vm)
+					Expect(err).ToNot(HaveOccurred())
+
+					var o mo.VirtualMachine
+					Expect(vcVM.Properties(ctx, vcVM.Reference(), nil, &o)).To(Succeed())
+
+					ecMap := util.ExtraConfigToMap(o.Config.ExtraConfig)
+					Expect(ecMap).To(HaveKey(virtualmachine.VolumeDiskExtraConfigKey(volumeName)))
+
+					findScratchDisks := func(o mo.VirtualMachine) []*types.VirtualDisk {
+						var disks []*types.VirtualDisk
+						for _, dev := range object.VirtualDeviceList(o.Config.Hardware.Device).SelectByType((*types.VirtualDisk)(nil)) {
+							disk := dev.(*types.VirtualDisk)
+							if virtualmachine.VolumeDiskExtraConfigValue(disk) == ecMap[virtualmachine.VolumeDiskExtraConfigKey(volumeName)] {
+								disks = append(disks, disk)
+							}
+						}
+						return disks
+					}
+
+					disks := findScratchDisks(o)
+					Expect(disks).To(HaveLen(1))
+					Expect(disks[0].CapacityInBytes).To(BeEquivalentTo(scratchSize.Value()))
+					numDevices := len(o.Config.Hardware.Device)
+
+					By("Not adding the disk again after a power cycle", func() {
+						vm.Spec.PowerState = vmopv1.VirtualMachinePowerStateOff
+						Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+						vm.Spec.PowerState = vmopv1.VirtualMachinePowerStateOn
+						Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+
+						Expect(vcVM.Properties(ctx, vcVM.Reference(), nil, &o)).To(Succeed())
+						Expect(o.Config.Hardware.Device).To(HaveLen(numDevices))
+						Expect(findScratchDisks(o)).To(HaveLen(1))
+					})
+				})
+
+				It("Assigns the disk a unit not requested by the attach options of a volume", func() {
+					vm.Spec.PowerState = vmopv1.VirtualMachinePowerStateOff
+					vcVM, err := createOrUpdateAndGetVcVM(ctx, vm)
+					Expect(err).ToNot(HaveOccurred())
+
+					scsiController, err := object.VirtualDeviceList(nil).CreateSCSIController("pvscsi")
+					Expect(err).ToNot(HaveOccurred())
+					Expect(vcVM.AddDevice(ctx, scsiController)).To(Succeed())
+
+					var o mo.VirtualMachine
+					Expect(vcVM.Properties(ctx, vcVM.Reference(), nil, &o)).To(Succeed())
+					homeDisk, backing := getVMHomeDisk(ctx, vcVM, o)
+					controllers := object.VirtualDeviceList(o.Config.Hardware.Device).SelectByType((*types.ParaVirtualSCSIController)(nil))
+					Expect(controllers).To(HaveLen(1))
+					controller := controllers[0].(*types.ParaVirtualSCSIController)
+
+					// Stand in for a disk CNS attached, whose attach options move it
+					// to the first free unit of the controller the scratch disk is
+					// also added to.
+					vm.Spec.Volumes = append(vm.Spec.Volumes, vmopv1.VirtualMachineVolume{
+						Name: "pvc-vol",
+						VirtualMachineVolumeSource: vmopv1.VirtualMachineVolumeSource{
+							PersistentVolumeClaim: &vmopv1.PersistentVolumeClaimVolumeSource{
+								PersistentVolumeClaimVolumeSource: corev1.PersistentVolumeClaimVolumeSource{
+									ClaimName: "pvc-claim",
+								},
+								ControllerType:      vmopv1.VirtualMachineVolumeControllerTypeParaVirtualSCSI,
+								ControllerBusNumber: pointer.Int32(controller.BusNumber),
+								UnitNumber:          pointer.Int32(0),
+							},
+						},
+					})
+					vm.Status.Volumes = append(vm.Status.Volumes, vmopv1.VirtualMachineVolumeStatus{
+						Name:     "pvc-vol",
+						Attached: true,
+						DiskUUID: backing.Uuid,
+					})
+					vm.Spec.PowerState = vmopv1.VirtualMachinePowerStateOn
+					Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+
+					Expect(vcVM.Properties(ctx, vcVM.Reference(), nil, &o)).To(Succeed())
+					ecMap := util.ExtraConfigToMap(o.Config.ExtraConfig)
+					Expect(ecMap).To(HaveKey(virtualmachine.VolumeDiskExtraConfigKey(volumeName)))
+
+					units := map[int32]bool{}
+					for _, dev := range object.VirtualDeviceList(o.Config.Hardware.Device).SelectByType((*types.VirtualDisk)(nil)) {
+						disk := dev.GetVirtualDevice()
+						if disk.ControllerKey != controller.Key {
+							continue
+						}
+						Expect(disk.UnitNumber).ToNot(BeNil())
+						Expect(units).ToNot(HaveKey(*disk.UnitNumber))
+						units[*disk.UnitNumber] = true
+						if disk.Key == homeDisk.Key {
+							Expect(*disk.UnitNumber).To(BeEquivalentTo(0))
+						}
+					}
+					Expect(units).To(HaveLen(2))
+				})
+
+				When("the volume's storage class is only compatible with another datastore", func() {
+					const (
+						datastoreName = "scratch-ds"
+						policyID      = "scratch-policy"
+					)
+
+					var datastoreDir string
+
+					BeforeEach(func() {
+						var err error
+						datastoreDir, err = os.MkdirTemp("", "scratch-ds-")
+						Expect(err).ToNot(HaveOccurred())
+					})
+
+					AfterEach(func() {
+						Expect(os.RemoveAll(datastoreDir)).To(Succeed())
+					})
+
+					JustBeforeEach(func() {
+						storageClass := &storagev1.StorageClass{
+							ObjectMeta: metav1.ObjectMeta{
+								Name: "scratch-storage-class",
+							},
+							Parameters: map[string]string{
+								"storagePolicyID": policyID,
+							},
+						}
+						Expect(ctx.Client.Create(ctx, storageClass)).To(Succeed())
+						vm.Spec.Volumes[len(vm.Spec.Volumes)-1].Ephemeral.StorageClass = storageClass.Name
+					})
+
+					It("Adds the disk on that datastore", func() {
+						vm.Spec.PowerState = vmopv1.VirtualMachinePowerStateOff
+						vcVM, err := createOrUpdateAndGetVcVM(ctx, vm)
+						Expect(err).ToNot(HaveOccurred())
+
+						var o mo.VirtualMachine
+						Expect(vcVM.Properties(ctx, vcVM.Reference(), []string{"runtime.host"}, &o)).To(Succeed())
+						dss, err := object.NewHostSystem(vcVM.Client(), *o.Runtime.Host).ConfigManager().DatastoreSystem(ctx)
+						Expect(err).ToNot(HaveOccurred())
+						ds, err := dss.CreateLocalDatastore(ctx, datastoreName, datastoreDir)
+						Expect(err).ToNot(HaveOccurred())
+
+						solver := simulator.NewRegistry()
+						solver.Namespace = pbm.Namespace
+						solver.Path = pbm.Path
+						solver.Put(&datastorePlacementSolver{
+							PlacementSolver: pbmSimulator.PlacementSolver{
+								ManagedObjectReference: types.ManagedObjectReference{Type: "PbmPlacementSolver", Value: "placementSolver"},
+							},
+							profileID: policyID,
+							datastore: ds.Reference(),
+						})
+						ctx.RegisterSDK(solver)
+
+						vm.Spec.PowerState = vmopv1.VirtualMachinePowerStateOn
+						Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+
+						Expect(vcVM.Properties(ctx, vcVM.Reference(), nil, &o)).To(Succeed())
+						ecMap := util.ExtraConfigToMap(o.Config.ExtraConfig)
+						Expect(ecMap).To(HaveKey(virtualmachine.VolumeDiskExtraConfigKey(volumeName)))
+
+						var scratchDisk *types.VirtualDisk
+						for _, dev := range object.VirtualDeviceList(o.Config.Hardware.Device).SelectByType((*types.VirtualDisk)(nil)) {
+							disk := dev.(*types.VirtualDisk)
+							if virtualmachine.VolumeDiskExtraConfigValue(disk) == ecMap[virtualmachine.VolumeDiskExtraConfigKey(volumeName)] {
+								scratchDisk = disk
+							}
+						}
+						Expect(scratchDisk).ToNot(BeNil())
+						backing := scratchDisk.Backing.(*types.VirtualDiskFlatVer2BackingInfo)
+						Expect(backing.FileName).To(HavePrefix(fmt.Sprintf("[%s] ", datastoreName)))
+
+						By("Keeping the VM's home on its datastore", func() {
+							_, backing := getVMHomeDisk(ctx, vcVM, o)
+							Expect(backing.FileName).ToNot(HavePrefix(fmt.Sprintf("[%s] ", datastoreName)))
+						})
+					})
+				})
+			})
+
+			Context("Image volumes", func() {
+				const volumeName = "data"
+
+				JustBeforeEach(func() {
+					// Add a disk to the image's library item, which only has the
+					// OVF descriptor.
+					clusterVMImage := &vmopv1.ClusterVirtualMachineImage{}
+					Expect(ctx.Client.Get(ctx, client.ObjectKey{Name: vm.Spec.ImageName}, clusterVMImage)).To(Succeed())
+					datastore, err := ctx.Finder.DefaultDatastore(ctx)
+					Expect(err).ToNot(HaveOccurred())
+					imageDiskPath := datastore.Path(path.Join("contentlib-"+ctx.ContentLibraryID,
+						clusterVMImage.Status.ProviderItemID, "disk-0.vmdk"))
+					task, err := object.NewVirtualDiskManager(ctx.VCClient.Client).CreateVirtualDisk(
+						ctx, imageDiskPath, ctx.Datacenter, &types.FileBackedVirtualDiskSpec{
+							VirtualDiskSpec: types.VirtualDiskSpec{
+								DiskType:    string(types.VirtualDiskTypeThin),
+								AdapterType: string(types.VirtualDiskAdapterTypeLsiLogic),
+							},
+							CapacityKb: 1024,
+						})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(task.Wait(ctx)).To(Succeed())
+
+					vm.Spec.Volumes = append(vm.Spec.Volumes, vmopv1.VirtualMachineVolume{
+						Name: volumeName,
+						VirtualMachineVolumeSource: vmopv1.VirtualMachineVolumeSource{
+							Image: &vmopv1.ImageVolumeSource{
+								Name: vm.Spec.ImageName,
+							},
+						},
+					})
+				})
+
+				findImageVolumeDisk := func(o mo.VirtualMachine) *types.VirtualDisk {
+					ecMap := util.ExtraConfigToMap(o.Config.ExtraConfig)
+					ecValue, ok := ecMap[virtualmachine.VolumeDiskExtraConfigKey(volumeName)]
+					Expect(ok).To(BeTrue())
+					for _, dev := range object.VirtualDeviceList(o.Config.Hardware.Device).SelectByType((*types.VirtualDisk)(nil)) {
+						disk := dev.(*types.VirtualDisk)
+						if virtualmachine.VolumeDiskExtraConfigValue(disk) == ecValue {
+							return disk
+						}
+					}
+					return nil
+				}
+
+				It("Adds a copy of the image disk before the VM is powered on", func() {
+					vm.Spec.PowerState = vmopv1.VirtualMachinePowerStateOn
+					vcVM, err := createOrUpdateAndGetVcVM(ctx, vm)
+					Expect(err).ToNot(HaveOccurred())
+
+					var o mo.VirtualMachine
+					Expect(vcVM.Properties(ctx, vcVM.Reference(), nil, &o)).To(Succeed())
+					Expect(o.Runtime.PowerState).To(Equal(types.VirtualMachinePowerStatePoweredOn))
+
+					disk := findImageVolumeDisk(o)
+					Expect(disk).ToNot(BeNil())
+					backing := disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo)
+					Expect(backing.FileName).To(HaveSuffix(fmt.Sprintf("%s-%s.vmdk", vm.Name, volumeName)))
+
+					Expect(conditions.IsTrue(vm, vmopv1.VirtualMachineConditionImageVolumesCopied)).To(BeTrue())
+					Expect(vm.Annotations).ToNot(HaveKey(constants.ImageVolumeCopyTaskAnnotation))
+				})
+
+				It("Adds the disk copied by the recorded copy task", func() {
+					vm.Spec.PowerState = vmopv1.VirtualMachinePowerStateOff
+					vcVM, err := createOrUpdateAndGetVcVM(ctx, vm)
+					Expect(err).ToNot(HaveOccurred())
+
+					var o mo.VirtualMachine
+					Expect(vcVM.Properties(ctx, vcVM.Reference(), []string{"config.files"}, &o)).To(Succeed())
+					var vmPath object.DatastorePath
+					Expect(vmPath.FromString(o.Config.Files.VmPathName)).To(BeTrue())
+
+					// Stand in for a copy started by a previous reconcile, which
+					// has since completed.
+					diskPath := object.DatastorePath{
+						Datastore: vmPath.Datastore,
+						Path:      path.Join(path.Dir(vmPath.Path), "recorded-copy.vmdk"),
+					}
+					copyTask, err := object.NewVirtualDiskManager(vcVM.Client()).CreateVirtualDisk(
+						ctx, diskPath.String(), ctx.Datacenter, &types.FileBackedVirtualDiskSpec{
+							VirtualDiskSpec: types.VirtualDiskSpec{
+								DiskType:    string(types.VirtualDiskTypeThin),
+								AdapterType: string(types.VirtualDiskAdapterTypeLsiLogic),
+							},
+							CapacityKb: 1024,
+						})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(copyTask.Wait(ctx)).To(Succeed())
+
+					vm.Annotations[constants.ImageVolumeCopyTaskAnnotation] = copyTask.Reference().Value
+					vm.Annotations[constants.ImageVolumeCopyVolumeAnnotation] = volumeName
+					vm.Annotations[constants.ImageVolumeCopyDiskAnnotation] = diskPath.String()
+
+					vm.Spec.PowerState = vmopv1.VirtualMachinePowerStateOn
+					Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+
+					Expect(vcVM.Properties(ctx, vcVM.Reference(), nil, &o)).To(Succeed())
+					Expect(o.Runtime.PowerState).To(Equal(types.VirtualMachinePowerStatePoweredOn))
+					disk := findImageVolumeDisk(o)
+					Expect(disk).ToNot(BeNil())
+					Expect(disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo).FileName).To(Equal(diskPath.String()))
+
+					Expect(vm.Annotations).ToNot(HaveKey(constants.ImageVolumeCopyTaskAnnotation))
+					Expect(vm.Annotations).ToNot(HaveKey(constants.ImageVolumeCopyVolumeAnnotation))
+					Expect(vm.Annotations).ToNot(HaveKey(constants.ImageVolumeCopyDiskAnnotation))
 				})
 			})
 
@@ -1651,6 +2467,146 @@ func vmTests() {
 					Expect(vm.Labels).To(HaveKeyWithValue(topology.KubernetesTopologyZoneLabelKey, zoneName))
 					Expect(vm.Status.Zone).To(Equal(zoneName))
 				})
+
+				It("Migrates VM to another zone when the zone label is changed", func() {
+					vcVM, err := createOrUpdateAndGetVcVM(ctx, vm)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(vm.Status.Zone).To(Equal(zoneName))
+
+					var newZoneName string
+					for _, z := range ctx.ZoneNames {
+						if z != zoneName {
+							newZoneName = z
+							break
+						}
+					}
+					Expect(newZoneName).ToNot(BeEmpty())
+
+					vm.Labels[topology.KubernetesTopologyZoneLabelKey] = newZoneName
+					Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+
+					Expect(conditions.IsTrue(vm, vmopv1.VirtualMachineConditionZoneMigrated)).To(BeTrue())
+					Expect(vm.Status.Zone).To(Equal(newZoneName))
+					Expect(vm.Status.PowerState).To(Equal(vmopv1.VirtualMachinePowerStateOn))
+
+					By("VM is in the new zone's ResourcePool", func() {
+						rp, err := vcVM.ResourcePool(ctx)
+						Expect(err).ToNot(HaveOccurred())
+						nsRP := ctx.GetResourcePoolForNamespace(nsInfo.Namespace, newZoneName, "")
+						Expect(nsRP).ToNot(BeNil())
+						Expect(rp.Reference().Value).To(Equal(nsRP.Reference().Value))
+					})
+				})
+
+				It("Does not migrate VM when a PVC's volume is not accessible in the new zone", func() {
+					vcVM, err := createOrUpdateAndGetVcVM(ctx, vm)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(vm.Status.Zone).To(Equal(zoneName))
+
+					var o mo.VirtualMachine
+					Expect(vcVM.Properties(ctx, vcVM.Reference(), []string{"config.hardware.device"}, &o)).To(Succeed())
+					disks := object.VirtualDeviceList(o.Config.Hardware.Device).SelectByType(&types.VirtualDisk{})
+					Expect(disks).ToNot(BeEmpty())
+					diskUUID := disks[0].GetVirtualDevice().Backing.(*types.VirtualDiskFlatVer2BackingInfo).Uuid
+
+					pv := &corev1.PersistentVolume{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "pv-volume-1",
+						},
+						Spec: corev1.PersistentVolumeSpec{
+							NodeAffinity: &corev1.VolumeNodeAffinity{
+								Required: &corev1.NodeSelector{
+									NodeSelectorTerms: []corev1.NodeSelectorTerm{
+										{
+											MatchExpressions: []corev1.NodeSelectorRequirement{
+												{
+													Key:      topology.KubernetesTopologyZoneLabelKey,
+													Operator: corev1.NodeSelectorOpIn,
+													Values:   []string{zoneName},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					}
+					Expect(ctx.Client.Create(ctx, pv)).To(Succeed())
+					pvc := &corev1.PersistentVolumeClaim{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "pvc-volume-1",
+							Namespace: vm.Namespace,
+						},
+						Spec: corev1.PersistentVolumeClaimSpec{
+							VolumeName: pv.Name,
+						},
+					}
+					Expect(ctx.Client.Create(ctx, pvc)).To(Succeed())
+
+					vm.Spec.Volumes = []vmopv1.VirtualMachineVolume{
+						{
+							Name: "cns-volume-1",
+							VirtualMachineVolumeSource: vmopv1.VirtualMachineVolumeSource{
+								PersistentVolumeClaim: &vmopv1.PersistentVolumeClaimVolumeSource{
+									PersistentVolumeClaimVolumeSource: corev1.PersistentVolumeClaimVolumeSource{
+										ClaimName: pvc.Name,
+									},
+								},
+							},
+						},
+					}
+					vm.Status.Volumes = []vmopv1.VirtualMachineVolumeStatus{
+						{
+							Name:     "cns-volume-1",
+							Attached: true,
+							DiskUUID: diskUUID,
+						},
+					}
+
+					var newZoneName string
+					for _, z := range ctx.ZoneNames {
+						if z != zoneName {
+							newZoneName = z
+							break
+						}
+					}
+					Expect(newZoneName).ToNot(BeEmpty())
+
+					vm.Labels[topology.KubernetesTopologyZoneLabelKey] = newZoneName
+					err = vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("not accessible in zone " + newZoneName))
+
+					Expect(conditions.IsFalse(vm, vmopv1.VirtualMachineConditionZoneMigrated)).To(BeTrue())
+					Expect(conditions.GetReason(vm, vmopv1.VirtualMachineConditionZoneMigrated)).
+						To(Equal(vmopv1.VirtualMachineZoneMigrationFailedReason))
+					Expect(vm.Status.Zone).To(Equal(zoneName))
+
+					By("Backs off the migration and still updates the power state", func() {
+						vm.Spec.PowerState = vmopv1.VirtualMachinePowerStateOff
+						Expect(vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)).To(Succeed())
+						Expect(vm.Status.PowerState).To(Equal(vmopv1.VirtualMachinePowerStateOff))
+						Expect(conditions.GetReason(vm, vmopv1.VirtualMachineConditionZoneMigrated)).
+							To(Equal(vmopv1.VirtualMachineZoneMigrationFailedReason))
+						Expect(vm.Status.Zone).To(Equal(zoneName))
+					})
+
+					By("Retries the migration once it has backed off", func() {
+						for i := range vm.Status.Conditions {
+							if vm.Status.Conditions[i].Type == vmopv1.VirtualMachineConditionZoneMigrated {
+								vm.Status.Conditions[i].LastTransitionTime = metav1.NewTime(time.Now().Add(-time.Hour))
+							}
+						}
+
+						err = vmProvider.CreateOrUpdateVirtualMachine(ctx, vm)
+						Expect(err).To(HaveOccurred())
+						Expect(err.Error()).To(ContainSubstring("not accessible in zone " + newZoneName))
+						c := conditions.Get(vm, vmopv1.VirtualMachineConditionZoneMigrated)
+						Expect(c).ToNot(BeNil())
+						Expect(c.Reason).To(Equal(vmopv1.VirtualMachineZoneMigrationFailedReason))
+						Expect(time.Since(c.LastTransitionTime.Time)).To(BeNumerically("<", time.Minute))
+					})
+				})
 			})
 		})
 
@@ -1856,3 +2812,34 @@ func getDVPG(
 
 	return network, dvpg
 }
+
+// datastorePlacementSolver is a PBM placement solver for which only the given
+// datastore is compatible with the storage profile. All datastores are
+// compatible with other profiles.
+type datastorePlacementSolver struct {
+	pbmSimulator.PlacementSolver
+
+	profileID string
+	datastore types.ManagedObjectReference
+}
+
+func (m *datastorePlacementSolver) PbmCheckRequirements(req *pbmTypes.PbmCheckRequirements) soap.HasFault {
+	matchesProfile := false
+	for _, r := range req.PlacementSubjectRequirement {
+		if p, ok := r.(*pbmTypes.PbmPlacementCapabilityProfileRequirement); ok && p.ProfileId.UniqueId == m.profileID {
+			matchesProfile = true
+		}
+	}
+
+	body := &pbmMethods.PbmCheckRequirementsBody{
+		Res: &pbmTypes.PbmCheckRequirementsResponse{},
+	}
+	for _, hub := range req.HubsToSearch {
+		if matchesProfile && hub.HubId != m.datastore.Value {
+			continue
+		}
+		body.Res.Returnval = append(body.Res.Returnval, pbmTypes.PbmPlacementCompatibilityResult{Hub: hub})
+	}
+
+	return body
+}
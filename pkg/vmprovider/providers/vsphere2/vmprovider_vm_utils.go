@@ -103,6 +103,47 @@ func GetVirtualMachineImageSpecAndStatus(
 	return obj, spec, status, nil
 }
 
+// GetImageVolumeProviderItemID returns the ID of the content library item that provides the
+// named VirtualMachineImage or ClusterVirtualMachineImage of an image volume.
+func GetImageVolumeProviderItemID(
+	vmCtx context.VirtualMachineContextA2,
+	k8sClient ctrlclient.Client,
+	imageName string) (string, error) {
+
+	var spec *vmopv1.VirtualMachineImageSpec
+	var status *vmopv1.VirtualMachineImageStatus
+
+	key := ctrlclient.ObjectKey{Name: imageName, Namespace: vmCtx.VM.Namespace}
+	vmImage := &vmopv1.VirtualMachineImage{}
+	if err := k8sClient.Get(vmCtx, key, vmImage); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return "", err
+		}
+
+		key.Namespace = ""
+		clusterVMImage := &vmopv1.ClusterVirtualMachineImage{}
+		if err := k8sClient.Get(vmCtx, key, clusterVMImage); err != nil {
+			return "", fmt.Errorf("failed to get image %s: %w", imageName, err)
+		}
+
+		spec, status = &clusterVMImage.Spec, &clusterVMImage.Status
+	} else {
+		spec, status = &vmImage.Spec, &vmImage.Status
+	}
+
+	switch spec.ProviderRef.Kind {
+	case "ClusterContentLibraryItem", "ContentLibraryItem":
+	default:
+		return "", fmt.Errorf("unsupported image provider kind: %s", spec.ProviderRef.Kind)
+	}
+
+	if status.ProviderItemID == "" {
+		return "", fmt.Errorf("image %s does not have a provider item ID", imageName)
+	}
+
+	return status.ProviderItemID, nil
+}
+
 func getSecretData(
 	vmCtx context.VirtualMachineContextA2,
 	k8sClient ctrlclient.Client,
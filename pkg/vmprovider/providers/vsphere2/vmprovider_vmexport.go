@@ -0,0 +1,56 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package vsphere
+
+import (
+	goctx "context"
+
+	"github.com/vmware/govmomi/vim25/types"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere2/virtualmachine"
+)
+
+// ExportVirtualMachine exports the VM to an OVF or OVA, and writes the
+// exported files to the target. The VM must be powered off.
+func (vs *vSphereVMProvider) ExportVirtualMachine(
+	ctx goctx.Context,
+	vm *vmopv1.VirtualMachine,
+	export vmprovider.VirtualMachineExportA2) (vmprovider.VirtualMachineExportResultA2, error) {
+
+	vmCtx := context.VirtualMachineContextA2{
+		Context: goctx.WithValue(ctx, types.ID{}, vs.getOpID(vm, "exportVM")),
+		Logger:  log.WithValues("vmName", vm.NamespacedName(), "exportName", export.Name),
+		VM:      vm,
+	}
+
+	client, err := vs.getVcClient(vmCtx)
+	if err != nil {
+		return vmprovider.VirtualMachineExportResultA2{}, err
+	}
+
+	vcVM, err := vs.getVM(vmCtx, client, true)
+	if err != nil {
+		return vmprovider.VirtualMachineExportResultA2{}, err
+	}
+
+	files, manifest, err := virtualmachine.ExportVirtualMachine(vmCtx, client.VimClient(), vcVM, virtualmachine.ExportArgs{
+		Name:        export.Name,
+		Format:      export.Format,
+		Target:      export.Target,
+		Progress:    export.Progress,
+		Written:     export.Written,
+		FileWritten: export.FileWritten,
+	})
+	if err != nil {
+		return vmprovider.VirtualMachineExportResultA2{}, err
+	}
+
+	return vmprovider.VirtualMachineExportResultA2{
+		Files:    files,
+		Manifest: manifest,
+	}, nil
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package vsphere
+
+import (
+	goctx "context"
+
+	"github.com/vmware/govmomi/vim25/types"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere2/virtualmachine"
+)
+
+// RunVirtualMachineGuestCommand uploads the files into the VM's guest and runs
+// the command with the guest operations of VMware Tools. The VM must be powered
+// on and have VMware Tools running.
+func (vs *vSphereVMProvider) RunVirtualMachineGuestCommand(
+	ctx goctx.Context,
+	vm *vmopv1.VirtualMachine,
+	cmd vmprovider.VirtualMachineGuestCommandA2) (vmprovider.VirtualMachineGuestCommandResultA2, error) {
+
+	vmCtx := context.VirtualMachineContextA2{
+		Context: goctx.WithValue(ctx, types.ID{}, vs.getOpID(vm, "guestCommand")),
+		Logger:  log.WithValues("vmName", vm.NamespacedName(), "command", cmd.Command),
+		VM:      vm,
+	}
+
+	client, err := vs.getVcClient(vmCtx)
+	if err != nil {
+		return vmprovider.VirtualMachineGuestCommandResultA2{}, err
+	}
+
+	vcVM, err := vs.getVM(vmCtx, client, true)
+	if err != nil {
+		return vmprovider.VirtualMachineGuestCommandResultA2{}, err
+	}
+
+	files := make([]virtualmachine.GuestCommandFile, 0, len(cmd.Files))
+	for _, file := range cmd.Files {
+		files = append(files, virtualmachine.GuestCommandFile{
+			Path: file.Path,
+			Data: file.Data,
+		})
+	}
+
+	result, err := virtualmachine.RunGuestCommand(vmCtx, vcVM, virtualmachine.GuestCommandArgs{
+		Auth: &types.NamePasswordAuthentication{
+			Username: cmd.Username,
+			Password: cmd.Password,
+		},
+		Command:        cmd.Command,
+		Args:           cmd.Args,
+		Env:            cmd.Env,
+		WorkingDir:     cmd.WorkingDir,
+		Files:          files,
+		Timeout:        cmd.Timeout,
+		MaxOutputBytes: cmd.MaxOutputBytes,
+	})
+	if err != nil {
+		return vmprovider.VirtualMachineGuestCommandResultA2{}, err
+	}
+
+	return vmprovider.VirtualMachineGuestCommandResultA2{
+		ExitCode:        result.ExitCode,
+		Stdout:          result.Stdout,
+		StdoutTruncated: result.StdoutTruncated,
+		Stderr:          result.Stderr,
+		StderrTruncated: result.StderrTruncated,
+		TimedOut:        result.TimedOut,
+	}, nil
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package vsphere
+
+import (
+	goctx "context"
+	"fmt"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/topology"
+	"github.com/vmware-tanzu/vm-operator/pkg/util"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere2/vcenter"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere2/virtualmachine"
+)
+
+// ListVirtualMachineBackups returns the backup data of the VMs managed by VM
+// Service in the namespace's folder.
+func (vs *vSphereVMProvider) ListVirtualMachineBackups(
+	ctx goctx.Context,
+	namespace string) ([]vmprovider.VirtualMachineBackupA2, error) {
+
+	logger := log.WithValues("namespace", namespace)
+
+	client, err := vs.getVcClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	folderMoID, err := topology.GetNamespaceFolderMoID(ctx, vs.k8sClient, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	folder, err := vcenter.GetFolderByMoID(ctx, client.Finder(), folderMoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get namespace Folder: %w", err)
+	}
+
+	moVMs, err := vcenter.GetManagedVirtualMachines(ctx, client.VimClient(), folder,
+		[]string{"name", "config.extraConfig"})
+	if err != nil {
+		return nil, err
+	}
+
+	backups := make([]vmprovider.VirtualMachineBackupA2, 0, len(moVMs))
+	for _, moVM := range moVMs {
+		backup := vmprovider.VirtualMachineBackupA2{
+			Name:     moVM.Name,
+			UniqueID: moVM.Reference().Value,
+		}
+
+		data, err := virtualmachine.GetBackupData(util.ExtraConfigToMap(moVM.Config.ExtraConfig))
+		if err != nil {
+			logger.Error(err, "Failed to get VM backup data", "vmName", moVM.Name)
+			backup.Err = err
+		} else {
+			backup.VM = data.VM
+			backup.BootstrapData = data.BootstrapData
+			backup.CloudInitInstanceID = data.CloudInitInstanceID
+			if len(data.DiskData) > 0 {
+				backup.DiskFileNames = make(map[string]string, len(data.DiskData))
+				for _, disk := range data.DiskData {
+					backup.DiskFileNames[disk.PVCName] = disk.FileName
+				}
+			}
+		}
+
+		backups = append(backups, backup)
+	}
+
+	return backups, nil
+}
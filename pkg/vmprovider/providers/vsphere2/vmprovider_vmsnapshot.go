@@ -0,0 +1,89 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package vsphere
+
+import (
+	goctx "context"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/vmprovider/providers/vsphere2/virtualmachine"
+)
+
+// CreateOrUpdateVirtualMachineSnapshot takes the snapshot of the VM if it does
+// not already exist, and updates the snapshot's status from the VM's snapshot
+// tree.
+func (vs *vSphereVMProvider) CreateOrUpdateVirtualMachineSnapshot(
+	ctx goctx.Context,
+	vm *vmopv1.VirtualMachine,
+	vmSnapshot *vmopv1.VirtualMachineSnapshot) error {
+
+	vmCtx, vcVM, err := vs.getVMForSnapshot(ctx, vm, vmSnapshot, "createOrUpdateSnapshot", true)
+	if err != nil {
+		return err
+	}
+
+	return virtualmachine.CreateSnapshot(vmCtx, vcVM, vmSnapshot)
+}
+
+// RevertVirtualMachineSnapshot reverts the VM to the snapshot.
+func (vs *vSphereVMProvider) RevertVirtualMachineSnapshot(
+	ctx goctx.Context,
+	vm *vmopv1.VirtualMachine,
+	vmSnapshot *vmopv1.VirtualMachineSnapshot) error {
+
+	vmCtx, vcVM, err := vs.getVMForSnapshot(ctx, vm, vmSnapshot, "revertSnapshot", true)
+	if err != nil {
+		return err
+	}
+
+	return virtualmachine.RevertToSnapshot(vmCtx, vcVM, vmSnapshot)
+}
+
+// DeleteVirtualMachineSnapshot removes the snapshot from the VM. It is not an
+// error if either the VM or the snapshot does not exist.
+func (vs *vSphereVMProvider) DeleteVirtualMachineSnapshot(
+	ctx goctx.Context,
+	vm *vmopv1.VirtualMachine,
+	vmSnapshot *vmopv1.VirtualMachineSnapshot) error {
+
+	vmCtx, vcVM, err := vs.getVMForSnapshot(ctx, vm, vmSnapshot, "deleteSnapshot", false)
+	if err != nil {
+		return err
+	} else if vcVM == nil {
+		// VM does not exist.
+		return nil
+	}
+
+	return virtualmachine.DeleteSnapshot(vmCtx, vcVM, vmSnapshot)
+}
+
+func (vs *vSphereVMProvider) getVMForSnapshot(
+	ctx goctx.Context,
+	vm *vmopv1.VirtualMachine,
+	vmSnapshot *vmopv1.VirtualMachineSnapshot,
+	op string,
+	notFoundReturnErr bool) (context.VirtualMachineContextA2, *object.VirtualMachine, error) {
+
+	vmCtx := context.VirtualMachineContextA2{
+		Context: goctx.WithValue(ctx, types.ID{}, vs.getOpID(vm, op)),
+		Logger:  log.WithValues("vmName", vm.NamespacedName(), "vmSnapshotName", vmSnapshot.NamespacedName()),
+		VM:      vm,
+	}
+
+	client, err := vs.getVcClient(vmCtx)
+	if err != nil {
+		return vmCtx, nil, err
+	}
+
+	vcVM, err := vs.getVM(vmCtx, client, notFoundReturnErr)
+	if err != nil {
+		return vmCtx, nil, err
+	}
+
+	return vmCtx, vcVM, nil
+}
@@ -5,51 +5,136 @@ package webconsolevalidation
 
 import (
 	"context"
+	"fmt"
+	"net"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/go-logr/logr"
+	"golang.org/x/time/rate"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
+	clientgorecord "k8s.io/client-go/tools/record"
+	"k8s.io/utils/lru"
 	ctrlruntime "sigs.k8s.io/controller-runtime/pkg/client"
 	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
 
-	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
-	"github.com/vmware-tanzu/vm-operator/controllers/virtualmachinewebconsolerequest/v1alpha1"
+	vmopv1a1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	"github.com/vmware-tanzu/vm-operator/controllers/virtualmachinewebconsolerequest/v1alpha2"
+	"github.com/vmware-tanzu/vm-operator/pkg/record"
 )
 
-// K8sClient is used to get the webconsolerequest resource from UUID and namespace.
-var K8sClient ctrlruntime.Client
+const (
+	// DefaultRateLimitQPS is the default number of validation requests per
+	// second from a client that look up a web console request.
+	DefaultRateLimitQPS = 10
 
-// InitServer initializes a K8sClient used by the web-console validation server.
-func InitServer() error {
+	// DefaultRateLimitBurst is the default number of validation requests from
+	// a client that may look up a web console request at once above
+	// DefaultRateLimitQPS.
+	DefaultRateLimitBurst = 20
+
+	// DefaultRateLimitMaxClients is the default number of clients whose rate
+	// of validation requests is tracked.
+	DefaultRateLimitMaxClients = 10000
+
+	// WebConsoleOpenedReason is the reason of the Event recorded on a
+	// VirtualMachineWebConsoleRequest or WebConsoleRequest when its web
+	// console is opened.
+	WebConsoleOpenedReason = "WebConsoleOpened"
+
+	eventSourceComponent = "web-console-validator"
+)
+
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachinewebconsolerequests,verbs=get;list;patch
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=webconsolerequests,verbs=get;list;patch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// Server validates the requests to open the web console of a VM. A request is
+// allowed only once for an existing VirtualMachineWebConsoleRequest, or
+// v1alpha1 WebConsoleRequest, that has not expired.
+type Server struct {
+	// KubeClient is used to get the webconsolerequest resource from UUID and
+	// namespace, and to mark it as used.
+	KubeClient ctrlruntime.Client
+
+	// Recorder records an Event on the webconsolerequest resource when its
+	// web console is opened.
+	Recorder record.Recorder
+
+	// Limiter limits the rate of the lookups of webconsolerequest resources
+	// per client.
+	Limiter *ClientLimiter
+
+	// TrustedProxies are the networks of the proxies that forward the
+	// requests of clients. The X-Forwarded-For header of a request is only
+	// honoured when the request is from a trusted proxy.
+	TrustedProxies []*net.IPNet
+
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// NewServer returns a Server that uses the in-cluster config to access the
+// API server, and limits the lookups of each client to the given rate and
+// burst. The clients of the requests from the trusted proxies are identified
+// by the X-Forwarded-For header.
+func NewServer(qps float64, burst int, trustedProxies []*net.IPNet) (*Server, error) {
 	restConfig, err := rest.InClusterConfig()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	scheme := runtime.NewScheme()
+	if err = vmopv1a1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
 	if err = vmopv1.AddToScheme(scheme); err != nil {
-		return err
+		return nil, err
 	}
 
 	ctrlruntimeClient, err := ctrlruntime.New(restConfig, ctrlruntime.Options{Scheme: scheme})
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
 	}
 
-	K8sClient = ctrlruntimeClient
-	return nil
+	broadcaster := clientgorecord.NewBroadcaster()
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: clientset.CoreV1().Events("")})
+	eventRecorder := broadcaster.NewRecorder(scheme, corev1.EventSource{Component: eventSourceComponent})
+
+	return &Server{
+		KubeClient:     ctrlruntimeClient,
+		Recorder:       record.New(eventRecorder),
+		Limiter:        NewClientLimiter(rate.Limit(qps), burst, DefaultRateLimitMaxClients),
+		TrustedProxies: trustedProxies,
+		Now:            time.Now,
+	}, nil
 }
 
-// RunServer runs the web-console validation server at the given addr and path.
-func RunServer(addr, path string) error {
+// Run runs the web-console validation server at the given addr and path.
+func (s *Server) Run(addr, path string) error {
 	mux := http.NewServeMux()
-	mux.HandleFunc(path, HandleWebConsoleValidation)
+	mux.HandleFunc(path, s.HandleWebConsoleValidation)
 
 	return http.ListenAndServe(addr, mux)
 }
 
 // HandleWebConsoleValidation handles the web-console validation server requests.
-func HandleWebConsoleValidation(w http.ResponseWriter, r *http.Request) {
+func (s *Server) HandleWebConsoleValidation(w http.ResponseWriter, r *http.Request) {
 	uuid := r.URL.Query().Get("uuid")
 	if uuid == "" {
 		http.Error(w, "'uuid' param is empty", http.StatusBadRequest)
@@ -64,32 +149,271 @@ func HandleWebConsoleValidation(w http.ResponseWriter, r *http.Request) {
 
 	logger := ctrllog.Log.WithName(r.URL.Path).WithValues("uuid", uuid).WithValues("namespace", namespace)
 
-	found, err := isResourceFound(r.Context(), uuid, namespace)
+	if s.Limiter != nil && !s.Limiter.Allow(s.clientAddr(r)) {
+		logger.Info("Rate limit of webconsolerequest lookups exceeded. Returning 429.", "client", s.clientAddr(r))
+		http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+		return
+	}
+
+	wcr, err := s.getResource(r.Context(), uuid, namespace)
 	if err != nil {
 		logger.Error(err, "Error occurred in finding a webconsolerequest resource with the given params.")
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	if found {
-		logger.Info("Found a webconsolerequest resource with the given params. Returning 200.")
-		w.WriteHeader(http.StatusOK)
-	} else {
+	if wcr == nil {
 		logger.Info("Didn't find a webconsolerequest resource with the given params. Returning 403.")
 		w.WriteHeader(http.StatusForbidden)
+		return
 	}
+
+	logger = logger.WithValues("name", wcr.GetName(), "kind", wcr.kind)
+	now := s.now()
+
+	if expiryTime := wcr.expiryTime; !now.Before(expiryTime.Time) {
+		logger.Info("Found an expired webconsolerequest resource with the given params. Returning 403.",
+			"expiryTime", expiryTime)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	if usedTime := wcr.GetAnnotations()[v1alpha2.UsedAnnotationKey]; usedTime != "" {
+		logger.Info("Found an already used webconsolerequest resource with the given params. Returning 403.",
+			"usedTime", usedTime)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	if err := s.markUsed(r.Context(), wcr, now); err != nil {
+		if apierrors.IsConflict(err) || apierrors.IsNotFound(err) {
+			logger.Info("The webconsolerequest resource was used or deleted concurrently. Returning 403.")
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		logger.Error(err, "Error occurred in marking the webconsolerequest resource as used.")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.audit(logger, r, wcr, now)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+	return time.Now()
+}
+
+// webConsoleRequest is a VirtualMachineWebConsoleRequest or a v1alpha1
+// WebConsoleRequest. Both kinds are validated the same way.
+type webConsoleRequest struct {
+	ctrlruntime.Object
+
+	kind       string
+	vmName     string
+	expiryTime metav1.Time
 }
 
-func isResourceFound(goCtx context.Context, uuid, namespace string) (bool, error) {
+// getResource returns the VirtualMachineWebConsoleRequest or v1alpha1
+// WebConsoleRequest with the given UUID label in the namespace, or nil when
+// there is none. The CRD of a kind may not be installed, for example when the
+// v1alpha2 API is disabled, in which case the kind has no resources.
+func (s *Server) getResource(
+	goCtx context.Context,
+	uuid, namespace string) (*webConsoleRequest, error) {
+
+	// Both kinds are labeled with the same key.
 	labelSelector := ctrlruntime.MatchingLabels{
-		v1alpha1.UUIDLabelKey: uuid,
+		v1alpha2.UUIDLabelKey: uuid,
 	}
 
 	// TODO: Use an Informer to avoid hitting the API server for every request.
-	wcrObjectList := &vmopv1.WebConsoleRequestList{}
-	if err := K8sClient.List(goCtx, wcrObjectList, ctrlruntime.InNamespace(namespace), labelSelector); err != nil {
-		return false, err
+	wcrObjectList := &vmopv1.VirtualMachineWebConsoleRequestList{}
+	if err := s.KubeClient.List(goCtx, wcrObjectList, ctrlruntime.InNamespace(namespace), labelSelector); err != nil {
+		if !meta.IsNoMatchError(err) {
+			return nil, err
+		}
+	}
+	if len(wcrObjectList.Items) > 0 {
+		wcr := &wcrObjectList.Items[0]
+		return &webConsoleRequest{
+			Object:     wcr,
+			kind:       "VirtualMachineWebConsoleRequest",
+			vmName:     wcr.Spec.Name,
+			expiryTime: wcr.Status.ExpiryTime,
+		}, nil
+	}
+
+	v1a1WCRObjectList := &vmopv1a1.WebConsoleRequestList{}
+	if err := s.KubeClient.List(goCtx, v1a1WCRObjectList, ctrlruntime.InNamespace(namespace), labelSelector); err != nil {
+		if !meta.IsNoMatchError(err) {
+			return nil, err
+		}
+	}
+	if len(v1a1WCRObjectList.Items) > 0 {
+		wcr := &v1a1WCRObjectList.Items[0]
+		return &webConsoleRequest{
+			Object:     wcr,
+			kind:       "WebConsoleRequest",
+			vmName:     wcr.Spec.VirtualMachineName,
+			expiryTime: wcr.Status.ExpiryTime,
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// markUsed sets the annotation that marks the webconsolerequest resource as
+// used. The patch fails with a conflict when the resource was changed since it
+// was read, so that the resource is used at most once even when it is
+// validated concurrently.
+func (s *Server) markUsed(goCtx context.Context, wcr *webConsoleRequest, now time.Time) error {
+	obj := wcr.Object
+	patch := ctrlruntime.MergeFromWithOptions(obj.DeepCopyObject().(ctrlruntime.Object), ctrlruntime.MergeFromWithOptimisticLock{})
+
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[v1alpha2.UsedAnnotationKey] = now.UTC().Format(time.RFC3339)
+	obj.SetAnnotations(annotations)
+
+	return s.KubeClient.Patch(goCtx, obj, patch)
+}
+
+// audit records who opened the web console of which VM and when, both as an
+// Event on the webconsolerequest resource and as a log line.
+func (s *Server) audit(
+	logger logr.Logger,
+	r *http.Request,
+	wcr *webConsoleRequest,
+	now time.Time) {
+
+	client := s.clientAddr(r)
+
+	logger.Info("Web console opened. Returning 200.",
+		"virtualMachine", wcr.vmName,
+		"client", client,
+		"userAgent", r.UserAgent(),
+		"time", now.UTC().Format(time.RFC3339))
+
+	if s.Recorder != nil {
+		s.Recorder.Eventf(wcr.Object, WebConsoleOpenedReason,
+			"Web console of VirtualMachine %s opened by %s at %s",
+			wcr.vmName, client, now.UTC().Format(time.RFC3339))
+	}
+}
+
+// clientAddr returns the address of the client that opens the web console.
+// The X-Forwarded-For header can be set by anyone, so it is only honoured for
+// a request from a trusted proxy. Each proxy appends the address it received
+// the request from to the header, so the client is the rightmost address that
+// is not a trusted proxy. The addresses before it cannot be trusted.
+func (s *Server) clientAddr(r *http.Request) string {
+	addr := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	if !s.isTrustedProxy(addr) {
+		return addr
+	}
+
+	var forwardedFor []string
+	for _, v := range r.Header.Values("X-Forwarded-For") {
+		forwardedFor = append(forwardedFor, strings.Split(v, ",")...)
+	}
+	for i := len(forwardedFor) - 1; i >= 0; i-- {
+		forwarded := strings.TrimSpace(forwardedFor[i])
+		if forwarded == "" {
+			break
+		}
+		addr = forwarded
+		if !s.isTrustedProxy(addr) {
+			break
+		}
+	}
+	return addr
+}
+
+func (s *Server) isTrustedProxy(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, ipNet := range s.TrustedProxies {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTrustedProxies parses a comma-separated list of the IP addresses and
+// CIDRs of trusted proxies.
+func ParseTrustedProxies(s string) ([]*net.IPNet, error) {
+	var trustedProxies []*net.IPNet
+	for _, v := range strings.Split(s, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if !strings.Contains(v, "/") {
+			ip := net.ParseIP(v)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy address %q", v)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			trustedProxies = append(trustedProxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy CIDR %q: %w", v, err)
+		}
+		trustedProxies = append(trustedProxies, ipNet)
+	}
+	return trustedProxies, nil
+}
+
+// ClientLimiter limits the rate of the requests of each client, so that a
+// client exceeding its rate does not prevent the requests of other clients.
+// The limiters of the least recently seen clients are evicted once more than
+// the maximum number of clients are tracked.
+type ClientLimiter struct {
+	limit rate.Limit
+	burst int
+
+	mu       sync.Mutex
+	limiters *lru.Cache
+}
+
+// NewClientLimiter returns a ClientLimiter that allows each client the given
+// rate and burst, and tracks at most maxClients clients.
+func NewClientLimiter(limit rate.Limit, burst, maxClients int) *ClientLimiter {
+	return &ClientLimiter{
+		limit:    limit,
+		burst:    burst,
+		limiters: lru.New(maxClients),
+	}
+}
+
+// Allow reports whether a request from the client with the given address may
+// happen now.
+func (l *ClientLimiter) Allow(client string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if limiter, ok := l.limiters.Get(client); ok {
+		return limiter.(*rate.Limiter).Allow()
 	}
 
-	return len(wcrObjectList.Items) > 0, nil
+	limiter := rate.NewLimiter(l.limit, l.burst)
+	l.limiters.Add(client, limiter)
+	return limiter.Allow()
 }
@@ -4,35 +4,61 @@
 package webconsolevalidation_test
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	"golang.org/x/time/rate"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
-	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
-	"github.com/vmware-tanzu/vm-operator/controllers/virtualmachinewebconsolerequest/v1alpha1"
+	vmopv1a1 "github.com/vmware-tanzu/vm-operator/api/v1alpha1"
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	webconsolerequestv1a1 "github.com/vmware-tanzu/vm-operator/controllers/virtualmachinewebconsolerequest/v1alpha1"
+	"github.com/vmware-tanzu/vm-operator/controllers/virtualmachinewebconsolerequest/v1alpha2"
 	"github.com/vmware-tanzu/vm-operator/pkg/webconsolevalidation"
 	"github.com/vmware-tanzu/vm-operator/test/builder"
 )
 
+// proxyAddr is the address of the trusted proxy that forwards the requests of
+// fakeValidationRequestFrom.
+const proxyAddr = "172.16.0.1:50000"
+
 func serverUnitTests() {
 
 	Describe("web-console validation server unit tests", func() {
 
 		var (
 			initObjects []client.Object
+			server      *webconsolevalidation.Server
+			events      chan string
+			now         time.Time
 		)
 
+		BeforeEach(func() {
+			now = time.Now()
+		})
+
 		JustBeforeEach(func() {
-			webconsolevalidation.K8sClient = builder.NewFakeClient(initObjects...)
+			trustedProxies, err := webconsolevalidation.ParseTrustedProxies("172.16.0.1")
+			Expect(err).ToNot(HaveOccurred())
+
+			server = &webconsolevalidation.Server{
+				KubeClient:     builder.NewFakeClient(initObjects...),
+				Limiter:        webconsolevalidation.NewClientLimiter(rate.Inf, 0, 10),
+				TrustedProxies: trustedProxies,
+				Now:            func() time.Time { return now },
+			}
+			server.Recorder, events = builder.NewFakeRecorder()
 		})
 
 		AfterEach(func() {
 			initObjects = nil
-			webconsolevalidation.K8sClient = nil
+			server = nil
 		})
 
 		Context("requests with missing params", func() {
@@ -40,13 +66,13 @@ func serverUnitTests() {
 			It("should return http.StatusBadRequest (400)", func() {
 				var responseCode int
 
-				responseCode = fakeValidationRequest("/")
+				responseCode = fakeValidationRequest(server, "/")
 				Expect(responseCode).To(Equal(http.StatusBadRequest))
 
-				responseCode = fakeValidationRequest("/?uuid=123")
+				responseCode = fakeValidationRequest(server, "/?uuid=123")
 				Expect(responseCode).To(Equal(http.StatusBadRequest))
 
-				responseCode = fakeValidationRequest("/?namespace=dummy")
+				responseCode = fakeValidationRequest(server, "/?namespace=dummy")
 				Expect(responseCode).To(Equal(http.StatusBadRequest))
 			})
 
@@ -54,12 +80,19 @@ func serverUnitTests() {
 
 		Context("requests with a uuid param set", func() {
 
+			var (
+				wcr *vmopv1.VirtualMachineWebConsoleRequest
+			)
+
 			BeforeEach(func() {
-				wcr := &vmopv1.WebConsoleRequest{}
+				wcr = &vmopv1.VirtualMachineWebConsoleRequest{}
+				wcr.Name = "dummy-wcr"
 				wcr.Namespace = "dummy-namespace"
 				wcr.Labels = map[string]string{
-					v1alpha1.UUIDLabelKey: "dummy-uuid-1234",
+					v1alpha2.UUIDLabelKey: "dummy-uuid-1234",
 				}
+				wcr.Spec.Name = "dummy-vm"
+				wcr.Status.ExpiryTime = metav1.NewTime(now.Add(time.Minute))
 				initObjects = append(initObjects, wcr)
 			})
 
@@ -67,17 +100,116 @@ func serverUnitTests() {
 
 				It("should return http.StatusOK (200)", func() {
 					url := "/?uuid=dummy-uuid-1234&namespace=dummy-namespace"
-					responseCode := fakeValidationRequest(url)
+					responseCode := fakeValidationRequest(server, url)
 					Expect(responseCode).To(Equal(http.StatusOK))
 				})
 
+				It("should mark the WebConsoleRequest as used", func() {
+					url := "/?uuid=dummy-uuid-1234&namespace=dummy-namespace"
+					Expect(fakeValidationRequest(server, url)).To(Equal(http.StatusOK))
+
+					obj := &vmopv1.VirtualMachineWebConsoleRequest{}
+					Expect(server.KubeClient.Get(context.Background(), client.ObjectKeyFromObject(wcr), obj)).To(Succeed())
+					Expect(obj.Annotations).To(HaveKeyWithValue(v1alpha2.UsedAnnotationKey, now.UTC().Format(time.RFC3339)))
+				})
+
+				It("should record an audit Event with the address appended by the proxy", func() {
+					url := "/?uuid=dummy-uuid-1234&namespace=dummy-namespace"
+					Expect(fakeValidationRequestFrom(server, url, "10.0.0.1, 192.168.0.1")).To(Equal(http.StatusOK))
+
+					Expect(events).To(Receive(And(
+						ContainSubstring(webconsolevalidation.WebConsoleOpenedReason),
+						ContainSubstring("dummy-vm"),
+						ContainSubstring("192.168.0.1"),
+						Not(ContainSubstring("10.0.0.1")),
+					)))
+				})
+
+				It("should record an audit Event with the remote address of a request not from a trusted proxy", func() {
+					url := "/?uuid=dummy-uuid-1234&namespace=dummy-namespace"
+					Expect(fakeValidationRequestVia(server, url, "192.168.0.2:50000", "10.0.0.1")).To(Equal(http.StatusOK))
+
+					Expect(events).To(Receive(And(
+						ContainSubstring("192.168.0.2"),
+						Not(ContainSubstring("10.0.0.1")),
+					)))
+				})
+
+				It("should return http.StatusForbidden (403) when used again", func() {
+					url := "/?uuid=dummy-uuid-1234&namespace=dummy-namespace"
+					Expect(fakeValidationRequest(server, url)).To(Equal(http.StatusOK))
+					Expect(fakeValidationRequest(server, url)).To(Equal(http.StatusForbidden))
+				})
+
+			})
+
+			When("the WebConsoleRequest resource has expired", func() {
+
+				BeforeEach(func() {
+					wcr.Status.ExpiryTime = metav1.NewTime(now.Add(-time.Second))
+				})
+
+				It("should return http.StatusForbidden (403)", func() {
+					url := "/?uuid=dummy-uuid-1234&namespace=dummy-namespace"
+					responseCode := fakeValidationRequest(server, url)
+					Expect(responseCode).To(Equal(http.StatusForbidden))
+					Expect(events).ToNot(Receive())
+				})
+
+			})
+
+			When("the WebConsoleRequest resource has no expiry time", func() {
+
+				BeforeEach(func() {
+					wcr.Status.ExpiryTime = metav1.Time{}
+				})
+
+				It("should return http.StatusForbidden (403)", func() {
+					url := "/?uuid=dummy-uuid-1234&namespace=dummy-namespace"
+					responseCode := fakeValidationRequest(server, url)
+					Expect(responseCode).To(Equal(http.StatusForbidden))
+				})
+
+			})
+
+			When("the lookups exceed the rate limit", func() {
+
+				JustBeforeEach(func() {
+					server.Limiter = webconsolevalidation.NewClientLimiter(rate.Every(time.Hour), 1, 10)
+				})
+
+				It("should return http.StatusTooManyRequests (429)", func() {
+					url := "/?uuid=non-existent-uuid&namespace=dummy-namespace"
+					Expect(fakeValidationRequestFrom(server, url, "10.0.0.1")).To(Equal(http.StatusForbidden))
+					Expect(fakeValidationRequestFrom(server, url, "10.0.0.1")).To(Equal(http.StatusTooManyRequests))
+				})
+
+				It("should not limit the requests of other clients", func() {
+					url := "/?uuid=non-existent-uuid&namespace=dummy-namespace"
+					Expect(fakeValidationRequestFrom(server, url, "10.0.0.1")).To(Equal(http.StatusForbidden))
+					Expect(fakeValidationRequestFrom(server, url, "10.0.0.1")).To(Equal(http.StatusTooManyRequests))
+					Expect(fakeValidationRequestFrom(server, url, "10.0.0.2")).To(Equal(http.StatusForbidden))
+				})
+
+				It("should limit a client regardless of the addresses it forwards", func() {
+					url := "/?uuid=non-existent-uuid&namespace=dummy-namespace"
+					Expect(fakeValidationRequestFrom(server, url, "1.1.1.1, 10.0.0.1")).To(Equal(http.StatusForbidden))
+					Expect(fakeValidationRequestFrom(server, url, "2.2.2.2, 10.0.0.1")).To(Equal(http.StatusTooManyRequests))
+				})
+
+				It("should limit a client not behind a trusted proxy by its remote address", func() {
+					url := "/?uuid=non-existent-uuid&namespace=dummy-namespace"
+					Expect(fakeValidationRequestVia(server, url, "192.168.0.2:50000", "10.0.0.1")).To(Equal(http.StatusForbidden))
+					Expect(fakeValidationRequestVia(server, url, "192.168.0.2:50001", "10.0.0.2")).To(Equal(http.StatusTooManyRequests))
+				})
+
 			})
 
 			When("Namespace doesn't match any WebConsoleRequest resource", func() {
 
 				It("should return http.StatusForbidden (403)", func() {
 					url := "/?uuid=dummy-uuid-1234&namespace=non-existent-namespace"
-					responseCode := fakeValidationRequest(url)
+					responseCode := fakeValidationRequest(server, url)
 					Expect(responseCode).To(Equal(http.StatusForbidden))
 				})
 
@@ -87,21 +219,114 @@ func serverUnitTests() {
 
 				It("should return http.StatusForbidden (403)", func() {
 					url := "/?uuid=non-existent-uuid&namespace=dummy-namespace"
-					responseCode := fakeValidationRequest(url)
+					responseCode := fakeValidationRequest(server, url)
 					Expect(responseCode).To(Equal(http.StatusForbidden))
 				})
 
 			})
 		})
+
+		Context("requests with the uuid of a v1alpha1 WebConsoleRequest", func() {
+
+			var (
+				wcr *vmopv1a1.WebConsoleRequest
+			)
+
+			BeforeEach(func() {
+				wcr = &vmopv1a1.WebConsoleRequest{}
+				wcr.Name = "dummy-v1a1-wcr"
+				wcr.Namespace = "dummy-namespace"
+				wcr.Labels = map[string]string{
+					webconsolerequestv1a1.UUIDLabelKey: "dummy-uuid-5678",
+				}
+				wcr.Spec.VirtualMachineName = "dummy-vm"
+				wcr.Status.ExpiryTime = metav1.NewTime(now.Add(time.Minute))
+				initObjects = append(initObjects, wcr)
+			})
+
+			When("UUID matches an existing WebConsoleRequest resource", func() {
+
+				It("should return http.StatusOK (200) only once and record an audit Event", func() {
+					url := "/?uuid=dummy-uuid-5678&namespace=dummy-namespace"
+					Expect(fakeValidationRequest(server, url)).To(Equal(http.StatusOK))
+					Expect(events).To(Receive(And(
+						ContainSubstring(webconsolevalidation.WebConsoleOpenedReason),
+						ContainSubstring("dummy-vm"),
+					)))
+
+					obj := &vmopv1a1.WebConsoleRequest{}
+					Expect(server.KubeClient.Get(context.Background(), client.ObjectKeyFromObject(wcr), obj)).To(Succeed())
+					Expect(obj.Annotations).To(HaveKeyWithValue(v1alpha2.UsedAnnotationKey, now.UTC().Format(time.RFC3339)))
+
+					Expect(fakeValidationRequest(server, url)).To(Equal(http.StatusForbidden))
+				})
+
+			})
+
+			When("the WebConsoleRequest resource has expired", func() {
+
+				BeforeEach(func() {
+					wcr.Status.ExpiryTime = metav1.NewTime(now.Add(-time.Second))
+				})
+
+				It("should return http.StatusForbidden (403)", func() {
+					url := "/?uuid=dummy-uuid-5678&namespace=dummy-namespace"
+					Expect(fakeValidationRequest(server, url)).To(Equal(http.StatusForbidden))
+					Expect(events).ToNot(Receive())
+				})
+
+			})
+		})
+	})
+
+	Describe("ParseTrustedProxies", func() {
+
+		It("should parse IP addresses and CIDRs", func() {
+			trustedProxies, err := webconsolevalidation.ParseTrustedProxies("172.16.0.1, 10.0.0.0/8,fd00::1")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(trustedProxies).To(HaveLen(3))
+			Expect(trustedProxies[0].String()).To(Equal("172.16.0.1/32"))
+			Expect(trustedProxies[1].String()).To(Equal("10.0.0.0/8"))
+			Expect(trustedProxies[2].String()).To(Equal("fd00::1/128"))
+		})
+
+		It("should return no trusted proxies for an empty list", func() {
+			trustedProxies, err := webconsolevalidation.ParseTrustedProxies("")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(trustedProxies).To(BeEmpty())
+		})
+
+		It("should return an error for an invalid address", func() {
+			_, err := webconsolevalidation.ParseTrustedProxies("not-an-ip")
+			Expect(err).To(HaveOccurred())
+			_, err = webconsolevalidation.ParseTrustedProxies("10.0.0.0/33")
+			Expect(err).To(HaveOccurred())
+		})
 	})
 }
 
 // fakeValidationRequest is a helper function to make a fake validation request.
 // It returns the response code from the server.
-func fakeValidationRequest(url string) int {
+func fakeValidationRequest(server *webconsolevalidation.Server, url string) int {
+	return fakeValidationRequestFrom(server, url, "")
+}
+
+// fakeValidationRequestFrom is like fakeValidationRequest, but the request is
+// forwarded by the trusted proxy for the given addresses.
+func fakeValidationRequestFrom(server *webconsolevalidation.Server, url, forwardedFor string) int {
+	return fakeValidationRequestVia(server, url, proxyAddr, forwardedFor)
+}
+
+// fakeValidationRequestVia is like fakeValidationRequestFrom, but the request
+// is from the given remote address.
+func fakeValidationRequestVia(server *webconsolevalidation.Server, url, remoteAddr, forwardedFor string) int {
 	responseRecorder := httptest.NewRecorder()
-	handler := http.HandlerFunc(webconsolevalidation.HandleWebConsoleValidation)
+	handler := http.HandlerFunc(server.HandleWebConsoleValidation)
 	testRequest, _ := http.NewRequest("GET", url, nil)
+	testRequest.RemoteAddr = remoteAddr
+	if forwardedFor != "" {
+		testRequest.Header.Set("X-Forwarded-For", forwardedFor)
+	}
 	handler.ServeHTTP(responseRecorder, testRequest)
 	response := responseRecorder.Result()
 	_ = response.Body.Close()
@@ -120,3 +120,14 @@ func generatePKIToolchain() (pkiToolchain, error) {
 		privateKeyPEM:   certPrivateKeyPEM.Bytes(),
 	}, nil
 }
+
+// NewServingCertificate returns a certificate and its private key in PEM
+// format that are signed by a new certificate authority, and that are valid
+// for the loopback addresses for one hour.
+func NewServingCertificate() (certPEM, keyPEM []byte, err error) {
+	pki, err := generatePKIToolchain()
+	if err != nil {
+		return nil, nil, err
+	}
+	return pki.publicKeyPEM, pki.privateKeyPEM, nil
+}
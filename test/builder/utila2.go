@@ -10,8 +10,10 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	"github.com/vmware-tanzu/vm-operator/api/v1alpha2/common"
 )
 
 func DummyVirtualMachineClass2A2(name string) *vmopv1.VirtualMachineClass {
@@ -185,7 +187,7 @@ func DummyVirtualMachineServiceA2() *vmopv1.VirtualMachineService {
 					Name:       "dummy-port",
 					Protocol:   "TCP",
 					Port:       42,
-					TargetPort: 4242,
+					TargetPort: intstr.FromInt(4242),
 				},
 			},
 			Selector: map[string]string{
@@ -306,3 +308,144 @@ func DummyVirtualMachineWebConsoleRequest(namespace, wcrName, vmName, pubKey str
 		},
 	}
 }
+
+func DummyVirtualMachineSerialConsoleRequest(namespace, name, vmName, pubKey string) *vmopv1.VirtualMachineSerialConsoleRequest {
+	return &vmopv1.VirtualMachineSerialConsoleRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: vmopv1.VirtualMachineSerialConsoleRequestSpec{
+			Name:      vmName,
+			PublicKey: pubKey,
+		},
+	}
+}
+
+func DummyVirtualMachineSnapshot(namespace, name, vmName string) *vmopv1.VirtualMachineSnapshot {
+	return &vmopv1.VirtualMachineSnapshot{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: vmopv1.VirtualMachineSnapshotSpec{
+			VMName: vmName,
+		},
+	}
+}
+
+func DummyVirtualMachineReplicaSet(namespace, name string) *vmopv1.VirtualMachineReplicaSet {
+	return &vmopv1.VirtualMachineReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: vmopv1.VirtualMachineReplicaSetSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": "dummy"},
+			},
+			Template: vmopv1.VirtualMachineTemplateSpec{
+				ObjectMeta: common.ObjectMeta{
+					Labels: map[string]string{"app": "dummy"},
+				},
+				Spec: vmopv1.VirtualMachineSpec{
+					ImageName:    "dummy-image",
+					ClassName:    "dummy-class",
+					StorageClass: "dummy-storage-class",
+					PowerState:   vmopv1.VirtualMachinePowerStateOn,
+				},
+			},
+		},
+	}
+}
+
+func DummyVirtualMachinePowerSchedule(namespace, name string) *vmopv1.VirtualMachinePowerSchedule {
+	return &vmopv1.VirtualMachinePowerSchedule{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: vmopv1.VirtualMachinePowerScheduleSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"env": "dev"},
+			},
+			PoweredOn:  "0 8 * * 1-5",
+			PoweredOff: "0 19 * * 1-5",
+		},
+	}
+}
+
+func DummyVirtualMachineExportRequest(namespace, name, vmName string) *vmopv1.VirtualMachineExportRequest {
+	return &vmopv1.VirtualMachineExportRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: vmopv1.VirtualMachineExportRequestSpec{
+			Source: vmopv1.VirtualMachineExportRequestSource{
+				Name: vmName,
+			},
+			Format: vmopv1.VirtualMachineExportFormatOVF,
+			Target: vmopv1.VirtualMachineExportRequestTarget{
+				ObjectStore: &vmopv1.VirtualMachineExportObjectStoreTarget{
+					Endpoint:              "https://s3.example.com",
+					Bucket:                "dummy-bucket",
+					CredentialsSecretName: "dummy-credentials",
+				},
+			},
+		},
+	}
+}
+
+func DummyVirtualMachineGuestCommandRequest(namespace, name, vmName string) *vmopv1.VirtualMachineGuestCommandRequest {
+	return &vmopv1.VirtualMachineGuestCommandRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: vmopv1.VirtualMachineGuestCommandRequestSpec{
+			VirtualMachineName:    vmName,
+			Command:               "/bin/echo",
+			Args:                  []string{"hello"},
+			CredentialsSecretName: "dummy-credentials",
+			TimeoutSeconds:        60,
+		},
+	}
+}
+
+func DummyVirtualMachineImageImportRequest(namespace, name, clName string) *vmopv1.VirtualMachineImageImportRequest {
+	return &vmopv1.VirtualMachineImageImportRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: vmopv1.VirtualMachineImageImportRequestSpec{
+			Source: vmopv1.VirtualMachineImageImportRequestSource{
+				URL: "https://images.example.com/dummy-image.ova",
+				Checksum: vmopv1.VirtualMachineImageImportChecksum{
+					Algorithm: vmopv1.VirtualMachineImageImportChecksumAlgorithmSHA256,
+					Value:     "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+				},
+			},
+			Target: vmopv1.VirtualMachineImageImportRequestTarget{
+				Item: vmopv1.VirtualMachineImageImportRequestTargetItem{
+					Description: "dummy image",
+				},
+				Location: vmopv1.VirtualMachineImageImportRequestTargetLocation{
+					Name:       clName,
+					APIVersion: "imageregistry.vmware.com/v1alpha1",
+					Kind:       "ContentLibrary",
+				},
+			},
+		},
+	}
+}
+
+func DummyVirtualMachineRestoreRequest(namespace, name string) *vmopv1.VirtualMachineRestoreRequest {
+	return &vmopv1.VirtualMachineRestoreRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+	}
+}
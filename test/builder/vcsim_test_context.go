@@ -17,6 +17,7 @@ import (
 	"fmt"
 	"math/big"
 	"net"
+	"net/http"
 	"net/url"
 	"os"
 	"path"
@@ -754,6 +755,25 @@ func (c *TestContextForVCSim) setupAZs(config VCSimTestConfig) {
 	}
 }
 
+// RegisterSDK adds the objects of the registry to the simulator, replacing the
+// simulated objects with the same references. Tests use this to override the
+// behavior of simulated methods.
+func (c *TestContextForVCSim) RegisterSDK(r *simulator.Registry) {
+	c.model.Service.RegisterSDK(r)
+}
+
+// HandleFunc registers the handler for the pattern with the simulator, in front
+// of the simulated endpoint that otherwise serves the requests. The simulated
+// endpoint is passed to the handler so it only needs to serve the requests
+// whose simulated behavior it overrides.
+func (c *TestContextForVCSim) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request, http.Handler)) {
+	mux := c.model.Service.ServeMux
+	next, _ := mux.Handler(&http.Request{Method: http.MethodGet, URL: &url.URL{Path: pattern}})
+	mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		handler(w, r, next)
+	})
+}
+
 func (c *TestContextForVCSim) GetSingleClusterCompute() *object.ClusterComputeResource {
 	Expect(c.withFaultDomains).To(BeFalse())
 	Expect(c.singleCCR).ToNot(BeNil())
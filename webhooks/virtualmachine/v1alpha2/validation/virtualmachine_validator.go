@@ -15,10 +15,12 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/api/validation"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8svalidation "k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -48,6 +50,9 @@ const (
 	allowedRestrictedNetworkTCPProbePort = 6443
 
 	readinessProbeOnlyOneAction              = "only one action can be specified"
+	readinessProbeHTTPPathNotAbsolute        = "must be an absolute path"
+	readinessProbeHTTPStatusRangeInvalid     = "min must be less than or equal to max"
+	readinessProbeHTTPHostNotIP              = "must be an IP of the VM"
 	updatesNotAllowedWhenPowerOn             = "updates to this field is not allowed when VM power is on"
 	storageClassNotAssignedFmt               = "Storage policy is not associated with the namespace %s"
 	storageClassNotFoundFmt                  = "Storage policy is not associated with the namespace %s"
@@ -61,6 +66,19 @@ const (
 	invalidNextRestartTimeOnUpdate           = "must be formatted as RFC3339Nano"
 	invalidNextRestartTimeOnUpdateNow        = "mutation webhooks are required to restart VM"
 	modifyAnnotationNotAllowedForNonAdmin    = "modifying this annotation is not allowed for non-admin users"
+	zoneRemovalNotAllowed                    = "zone cannot be removed once assigned"
+	zoneMigrationWithInstanceStorage         = "VM with instance storage volumes cannot be migrated to another zone"
+	bootDiskCapacityDecreaseNotAllowedFmt    = "boot disk capacity cannot be decreased from %s"
+	bootDiskCapacityLessThanImageFmt         = "boot disk capacity cannot be less than the image's boot disk capacity %s"
+	volumeUnitNumberOutOfRangeFmt            = "must be between 0 and %d for a %s controller"
+	volumeUnitNumberReservedSCSI             = "unit number 7 is reserved for the SCSI controller"
+	volumeControllerTypeConflictFmt          = "conflicts with the %s controller of volume %s on the same bus"
+	volumeUnitNumberConflictFmt              = "conflicts with the unit number of volume %s"
+	volumeMultiWriterNonPersistent           = "MultiWriter sharing mode is not supported with the IndependentNonPersistent disk mode"
+	volumeAttachOptionsUpdateNotAllowed      = "attach options of an existing volume cannot be changed"
+	volumeAttachOptionsPoweredOnNotAllowed   = "attach options cannot be specified for a volume added to a powered on VM"
+	volumeMultipleSourcesNotAllowed          = "only one volume source can be specified"
+	volumeDiskUpdateNotAllowed               = "ephemeral and image volumes cannot be added, removed or changed after the VM is created"
 )
 
 // +kubebuilder:webhook:verbs=create;update,path=/default-validate-vmoperator-vmware-com-v1alpha2-virtualmachine,mutating=false,failurePolicy=fail,groups=vmoperator.vmware.com,resources=virtualmachines,versions=v1alpha2,name=default.validating.virtualmachine.v1alpha2.vmoperator.vmware.com,sideEffects=None,admissionReviewVersions=v1;v1beta1
@@ -112,8 +130,11 @@ func (v validator) ValidateCreate(ctx *context.WebhookRequestContext) admission.
 	fieldErrs = append(fieldErrs, v.validateNetwork(ctx, vm)...)
 	fieldErrs = append(fieldErrs, v.validateVolumes(ctx, vm)...)
 	fieldErrs = append(fieldErrs, v.validateInstanceStorageVolumes(ctx, vm, nil)...)
+	fieldErrs = append(fieldErrs, v.validatePorts(ctx, vm)...)
 	fieldErrs = append(fieldErrs, v.validateReadinessProbe(ctx, vm)...)
+	fieldErrs = append(fieldErrs, v.validateLivenessProbe(ctx, vm)...)
 	fieldErrs = append(fieldErrs, v.validateAdvanced(ctx, vm)...)
+	fieldErrs = append(fieldErrs, v.validateBootDiskCapacity(ctx, vm, nil)...)
 	fieldErrs = append(fieldErrs, v.validatePowerStateOnCreate(ctx, vm)...)
 	fieldErrs = append(fieldErrs, v.validateNextRestartTimeOnCreate(ctx, vm)...)
 	fieldErrs = append(fieldErrs, v.validateAnnotation(ctx, vm, nil)...)
@@ -133,8 +154,6 @@ func (v validator) ValidateDelete(*context.WebhookRequestContext) admission.Resp
 // ValidateUpdate validates if the given VirtualMachineSpec update is valid.
 // Changes to following fields are not allowed:
 //   - ImageName
-//   - ClassName
-//   - StorageClass
 //   - ResourcePolicyName
 //   - Minimum VM Hardware Version
 //
@@ -164,12 +183,19 @@ func (v validator) ValidateUpdate(ctx *context.WebhookRequestContext) admission.
 	// Validations for allowed updates. Return validation responses here for conditional updates regardless
 	// of whether the update is allowed or not.
 	fieldErrs = append(fieldErrs, v.validateAvailabilityZone(ctx, vm, oldVM)...)
+	fieldErrs = append(fieldErrs, v.validateClass(ctx, vm)...)
+	fieldErrs = append(fieldErrs, v.validateStorageClassOnUpdate(ctx, vm, oldVM)...)
 	fieldErrs = append(fieldErrs, v.validateBootstrap(ctx, vm)...)
 	fieldErrs = append(fieldErrs, v.validateNetwork(ctx, vm)...)
 	fieldErrs = append(fieldErrs, v.validateVolumes(ctx, vm)...)
+	fieldErrs = append(fieldErrs, v.validateVolumeAttachOptionsOnUpdate(ctx, vm, oldVM)...)
+	fieldErrs = append(fieldErrs, v.validateVolumeDisksOnUpdate(ctx, vm, oldVM)...)
 	fieldErrs = append(fieldErrs, v.validateInstanceStorageVolumes(ctx, vm, oldVM)...)
+	fieldErrs = append(fieldErrs, v.validatePorts(ctx, vm)...)
 	fieldErrs = append(fieldErrs, v.validateReadinessProbe(ctx, vm)...)
+	fieldErrs = append(fieldErrs, v.validateLivenessProbe(ctx, vm)...)
 	fieldErrs = append(fieldErrs, v.validateAdvanced(ctx, vm)...)
+	fieldErrs = append(fieldErrs, v.validateBootDiskCapacity(ctx, vm, oldVM)...)
 	fieldErrs = append(fieldErrs, v.validateInstanceStorageVolumes(ctx, vm, oldVM)...)
 	fieldErrs = append(fieldErrs, v.validateNextRestartTimeOnUpdate(ctx, vm, oldVM)...)
 	fieldErrs = append(fieldErrs, v.validateAnnotation(ctx, vm, oldVM)...)
@@ -278,6 +304,15 @@ func (v validator) validateBootstrap(
 
 	}
 
+	if vm.Spec.Bootstrap != nil && vm.Spec.Bootstrap.Customization != nil {
+		p := bootstrapPath.Child("customization")
+
+		if linuxPrep == nil && sysPrep == nil {
+			allErrs = append(allErrs, field.Forbidden(p,
+				"customization may only be used with either LinuxPrep or Sysprep bootstrap providers"))
+		}
+	}
+
 	return allErrs
 }
 
@@ -338,25 +373,50 @@ func (v validator) validateClass(ctx *context.WebhookRequestContext, vm *vmopv1.
 	return allErrs
 }
 
-func (v validator) validateStorageClass(ctx *context.WebhookRequestContext, vm *vmopv1.VirtualMachine) field.ErrorList {
-	var allErrs field.ErrorList
+// validateStorageClassOnUpdate validates the new storage class when it is
+// changed. The VM's storage is relocated to the new storage class by the
+// provider.
+func (v validator) validateStorageClassOnUpdate(
+	ctx *context.WebhookRequestContext,
+	vm, oldVM *vmopv1.VirtualMachine) field.ErrorList {
+
+	if vm.Spec.StorageClass == oldVM.Spec.StorageClass {
+		return nil
+	}
 
 	if vm.Spec.StorageClass == "" {
-		return allErrs
+		return field.ErrorList{field.Required(field.NewPath("spec", "storageClass"), "")}
 	}
 
-	scPath := field.NewPath("spec", "storageClass")
-	scName := vm.Spec.StorageClass
+	return v.validateStorageClass(ctx, vm)
+}
+
+func (v validator) validateStorageClass(ctx *context.WebhookRequestContext, vm *vmopv1.VirtualMachine) field.ErrorList {
+	if vm.Spec.StorageClass == "" {
+		return nil
+	}
+
+	return v.validateNamespaceStorageClass(ctx, vm.Namespace, vm.Spec.StorageClass, field.NewPath("spec", "storageClass"))
+}
+
+// validateNamespaceStorageClass validates the StorageClass exists and is associated
+// with the namespace.
+func (v validator) validateNamespaceStorageClass(
+	ctx *context.WebhookRequestContext,
+	namespace, scName string,
+	scPath *field.Path) field.ErrorList {
+
+	var allErrs field.ErrorList
 
 	// TODO: This validation shouldn't be done in the webhook.
 
 	sc := &storagev1.StorageClass{}
 	if err := v.client.Get(ctx, client.ObjectKey{Name: scName}, sc); err != nil {
-		return append(allErrs, field.Invalid(scPath, scName, fmt.Sprintf(storageClassNotFoundFmt, vm.Namespace)))
+		return append(allErrs, field.Invalid(scPath, scName, fmt.Sprintf(storageClassNotFoundFmt, namespace)))
 	}
 
 	resourceQuotas := &corev1.ResourceQuotaList{}
-	if err := v.client.List(ctx, resourceQuotas, client.InNamespace(vm.Namespace)); err != nil {
+	if err := v.client.List(ctx, resourceQuotas, client.InNamespace(namespace)); err != nil {
 		return append(allErrs, field.Invalid(scPath, scName, err.Error()))
 	}
 
@@ -369,7 +429,7 @@ func (v validator) validateStorageClass(ctx *context.WebhookRequestContext, vm *
 		}
 	}
 
-	return append(allErrs, field.Invalid(scPath, scName, fmt.Sprintf(storageClassNotAssignedFmt, vm.Namespace)))
+	return append(allErrs, field.Invalid(scPath, scName, fmt.Sprintf(storageClassNotAssignedFmt, namespace)))
 }
 
 func (v validator) validateNetwork(ctx *context.WebhookRequestContext, vm *vmopv1.VirtualMachine) field.ErrorList {
@@ -581,6 +641,11 @@ func (v validator) validateVolumes(ctx *context.WebhookRequestContext, vm *vmopv
 	volumesPath := field.NewPath("spec", "volumes")
 	volumeNames := map[string]bool{}
 
+	// Volumes attached to each controller bus, and to each unit on the bus,
+	// to detect conflicting attach options.
+	busVolumes := map[string]vmopv1.VirtualMachineVolume{}
+	unitVolumes := map[string]string{}
+
 	for i, vol := range vm.Spec.Volumes {
 		volPath := volumesPath.Index(i)
 
@@ -601,10 +666,25 @@ func (v validator) validateVolumes(ctx *context.WebhookRequestContext, vm *vmopv
 			}
 		}
 
-		if vol.PersistentVolumeClaim == nil {
+		numSources := 0
+		for _, set := range []bool{vol.PersistentVolumeClaim != nil, vol.Ephemeral != nil, vol.Image != nil} {
+			if set {
+				numSources++
+			}
+		}
+
+		switch {
+		case numSources == 0:
 			allErrs = append(allErrs, field.Required(volPath.Child("persistentVolumeClaim"), ""))
-		} else {
+		case numSources > 1:
+			allErrs = append(allErrs, field.Forbidden(volPath, volumeMultipleSourcesNotAllowed))
+		case vol.PersistentVolumeClaim != nil:
 			allErrs = append(allErrs, v.validateVolumeWithPVC(ctx, vol, volPath)...)
+			allErrs = append(allErrs, validateVolumeAttachConflicts(vol, volPath, busVolumes, unitVolumes)...)
+		case vol.Ephemeral != nil:
+			allErrs = append(allErrs, v.validateEphemeralVolume(ctx, vm, vol, volPath)...)
+		case vol.Image != nil:
+			allErrs = append(allErrs, v.validateImageVolume(ctx, vm, vol, volPath)...)
 		}
 	}
 
@@ -628,9 +708,235 @@ func (v validator) validateVolumeWithPVC(
 		allErrs = append(allErrs, field.NotSupported(pvcPath.Child("readOnly"), true, []string{"false"}))
 	}
 
+	allErrs = append(allErrs, validateVolumeAttachOptions(vol.PersistentVolumeClaim, pvcPath)...)
+
+	return allErrs
+}
+
+func (v validator) validateEphemeralVolume(
+	ctx *context.WebhookRequestContext,
+	vm *vmopv1.VirtualMachine,
+	vol vmopv1.VirtualMachineVolume,
+	volPath *field.Path) field.ErrorList {
+
+	var allErrs field.ErrorList
+	ephemeralPath := volPath.Child("ephemeral")
+
+	if scName := vol.Ephemeral.StorageClass; scName == "" {
+		allErrs = append(allErrs, field.Required(ephemeralPath.Child("storageClass"), ""))
+	} else {
+		allErrs = append(allErrs, v.validateNamespaceStorageClass(ctx, vm.Namespace, scName, ephemeralPath.Child("storageClass"))...)
+	}
+
+	sizePath := ephemeralPath.Child("size")
+	if size := vol.Ephemeral.Size; size.IsZero() {
+		allErrs = append(allErrs, field.Required(sizePath, ""))
+	} else if size.Value()%(1024*1024) != 0 {
+		allErrs = append(allErrs, field.Invalid(sizePath, size.String(), vSphereVolumeSizeNotMBMultiple))
+	}
+
+	return allErrs
+}
+
+func (v validator) validateImageVolume(
+	ctx *context.WebhookRequestContext,
+	vm *vmopv1.VirtualMachine,
+	vol vmopv1.VirtualMachineVolume,
+	volPath *field.Path) field.ErrorList {
+
+	var allErrs field.ErrorList
+	imagePath := volPath.Child("image")
+
+	if vol.Image.Name == "" {
+		allErrs = append(allErrs, field.Required(imagePath.Child("name"), ""))
+	}
+	if vol.Image.DiskIndex < 0 {
+		allErrs = append(allErrs, field.Invalid(imagePath.Child("diskIndex"), vol.Image.DiskIndex, "must be greater than or equal to 0"))
+	}
+	if scName := vol.Image.StorageClass; scName != "" {
+		allErrs = append(allErrs, v.validateNamespaceStorageClass(ctx, vm.Namespace, scName, imagePath.Child("storageClass"))...)
+	}
+
+	return allErrs
+}
+
+// validateVolumeDisksOnUpdate validates the ephemeral and image volumes are not
+// changed since their disks are only created before the VM is first powered on.
+func (v validator) validateVolumeDisksOnUpdate(
+	ctx *context.WebhookRequestContext,
+	vm, oldVM *vmopv1.VirtualMachine) field.ErrorList {
+
+	volumeDisks := func(vm *vmopv1.VirtualMachine) map[string]vmopv1.VirtualMachineVolumeSource {
+		volumes := map[string]vmopv1.VirtualMachineVolumeSource{}
+		for _, vol := range vm.Spec.Volumes {
+			if vol.Ephemeral != nil || vol.Image != nil {
+				volumes[vol.Name] = vol.VirtualMachineVolumeSource
+			}
+		}
+		return volumes
+	}
+
+	if equality.Semantic.DeepEqual(volumeDisks(vm), volumeDisks(oldVM)) {
+		return nil
+	}
+
+	return field.ErrorList{field.Forbidden(field.NewPath("spec", "volumes"), volumeDiskUpdateNotAllowed)}
+}
+
+// volumeControllerMaxUnitNumber is the maximum unit number of a disk on each
+// type of controller.
+var volumeControllerMaxUnitNumber = map[vmopv1.VirtualMachineVolumeControllerType]int32{
+	vmopv1.VirtualMachineVolumeControllerTypeParaVirtualSCSI: 15,
+	vmopv1.VirtualMachineVolumeControllerTypeLsiLogic:        15,
+	vmopv1.VirtualMachineVolumeControllerTypeLsiLogicSAS:     15,
+	vmopv1.VirtualMachineVolumeControllerTypeBusLogic:        15,
+	vmopv1.VirtualMachineVolumeControllerTypeNVME:            14,
+	vmopv1.VirtualMachineVolumeControllerTypeSATA:            29,
+}
+
+// scsiControllerUnitNumber is the unit number reserved for the SCSI
+// controller itself.
+const scsiControllerUnitNumber = 7
+
+// volumeControllerBus returns the bus of the volume's controller type. The
+// SCSI controller types share the same buses.
+func volumeControllerBus(controllerType vmopv1.VirtualMachineVolumeControllerType) string {
+	switch controllerType {
+	case vmopv1.VirtualMachineVolumeControllerTypeNVME, vmopv1.VirtualMachineVolumeControllerTypeSATA:
+		return string(controllerType)
+	default:
+		return "SCSI"
+	}
+}
+
+func validateVolumeAttachOptions(
+	claim *vmopv1.PersistentVolumeClaimVolumeSource,
+	pvcPath *field.Path) field.ErrorList {
+
+	var allErrs field.ErrorList
+
+	if claim.ControllerType == "" && claim.ControllerBusNumber != nil {
+		allErrs = append(allErrs, field.Required(pvcPath.Child("controllerType"), ""))
+	}
+
+	if unitNumber := claim.UnitNumber; unitNumber != nil {
+		unitNumberPath := pvcPath.Child("unitNumber")
+
+		if claim.ControllerType == "" {
+			allErrs = append(allErrs, field.Required(pvcPath.Child("controllerType"), ""))
+		}
+		if claim.ControllerBusNumber == nil {
+			allErrs = append(allErrs, field.Required(pvcPath.Child("controllerBusNumber"), ""))
+		}
+
+		if maxUnitNumber, ok := volumeControllerMaxUnitNumber[claim.ControllerType]; ok {
+			if *unitNumber < 0 || *unitNumber > maxUnitNumber {
+				allErrs = append(allErrs, field.Invalid(unitNumberPath, *unitNumber,
+					fmt.Sprintf(volumeUnitNumberOutOfRangeFmt, maxUnitNumber, claim.ControllerType)))
+			} else if *unitNumber == scsiControllerUnitNumber && volumeControllerBus(claim.ControllerType) == "SCSI" {
+				allErrs = append(allErrs, field.Invalid(unitNumberPath, *unitNumber, volumeUnitNumberReservedSCSI))
+			}
+		}
+	}
+
+	if claim.SharingMode == vmopv1.VirtualMachineVolumeSharingModeMultiWriter &&
+		claim.DiskMode == vmopv1.VirtualMachineVolumeDiskModeIndependentNonPersistent {
+		allErrs = append(allErrs, field.Invalid(pvcPath.Child("sharingMode"), claim.SharingMode, volumeMultiWriterNonPersistent))
+	}
+
+	return allErrs
+}
+
+// validateVolumeAttachConflicts validates the volume is not attached to the
+// same controller bus as another volume with a different type of controller,
+// or to the same unit as another volume.
+func validateVolumeAttachConflicts(
+	vol vmopv1.VirtualMachineVolume,
+	volPath *field.Path,
+	busVolumes map[string]vmopv1.VirtualMachineVolume,
+	unitVolumes map[string]string) field.ErrorList {
+
+	claim := vol.PersistentVolumeClaim
+	if claim.ControllerType == "" || claim.ControllerBusNumber == nil {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	pvcPath := volPath.Child("persistentVolumeClaim")
+
+	busKey := fmt.Sprintf("%s:%d", volumeControllerBus(claim.ControllerType), *claim.ControllerBusNumber)
+	if busVol, ok := busVolumes[busKey]; !ok {
+		busVolumes[busKey] = vol
+	} else if busVol.PersistentVolumeClaim.ControllerType != claim.ControllerType {
+		allErrs = append(allErrs, field.Invalid(pvcPath.Child("controllerType"), claim.ControllerType,
+			fmt.Sprintf(volumeControllerTypeConflictFmt, busVol.PersistentVolumeClaim.ControllerType, busVol.Name)))
+	}
+
+	if claim.UnitNumber != nil {
+		unitKey := fmt.Sprintf("%s:%d", busKey, *claim.UnitNumber)
+		if unitVolName, ok := unitVolumes[unitKey]; !ok {
+			unitVolumes[unitKey] = vol.Name
+		} else {
+			allErrs = append(allErrs, field.Invalid(pvcPath.Child("unitNumber"), *claim.UnitNumber,
+				fmt.Sprintf(volumeUnitNumberConflictFmt, unitVolName)))
+		}
+	}
+
 	return allErrs
 }
 
+// validateVolumeAttachOptionsOnUpdate validates the attach options of the
+// existing volumes are not changed, since clearing an option does not revert
+// the volume's disk, and that attach options are not specified for volumes
+// added to a powered on VM, since the options are applied to the disk before
+// the VM is powered on.
+func (v validator) validateVolumeAttachOptionsOnUpdate(
+	ctx *context.WebhookRequestContext,
+	vm, oldVM *vmopv1.VirtualMachine) field.ErrorList {
+
+	var allErrs field.ErrorList
+	volumesPath := field.NewPath("spec", "volumes")
+
+	oldClaims := map[string]*vmopv1.PersistentVolumeClaimVolumeSource{}
+	for _, vol := range oldVM.Spec.Volumes {
+		if vol.PersistentVolumeClaim != nil {
+			oldClaims[vol.Name] = vol.PersistentVolumeClaim
+		}
+	}
+
+	for i, vol := range vm.Spec.Volumes {
+		claim, oldClaim := vol.PersistentVolumeClaim, oldClaims[vol.Name]
+		if claim == nil {
+			continue
+		}
+
+		if oldClaim == nil || claim.ClaimName != oldClaim.ClaimName {
+			if oldVM.Spec.PowerState == vmopv1.VirtualMachinePowerStateOn && hasVolumeAttachOptions(claim) {
+				allErrs = append(allErrs, field.Forbidden(volumesPath.Index(i).Child("persistentVolumeClaim"),
+					volumeAttachOptionsPoweredOnNotAllowed))
+			}
+			continue
+		}
+
+		if claim.ControllerType != oldClaim.ControllerType ||
+			!equality.Semantic.DeepEqual(claim.ControllerBusNumber, oldClaim.ControllerBusNumber) ||
+			!equality.Semantic.DeepEqual(claim.UnitNumber, oldClaim.UnitNumber) ||
+			claim.DiskMode != oldClaim.DiskMode ||
+			claim.SharingMode != oldClaim.SharingMode {
+
+			allErrs = append(allErrs, field.Forbidden(volumesPath.Index(i).Child("persistentVolumeClaim"),
+				volumeAttachOptionsUpdateNotAllowed))
+		}
+	}
+
+	return allErrs
+}
+
+func hasVolumeAttachOptions(claim *vmopv1.PersistentVolumeClaimVolumeSource) bool {
+	return claim.ControllerType != "" || claim.ControllerBusNumber != nil || claim.UnitNumber != nil ||
+		claim.DiskMode != "" || claim.SharingMode != ""
+}
+
 // validateInstanceStorageVolumes validates if instance storage volumes are added/modified.
 func (v validator) validateInstanceStorageVolumes(
 	ctx *context.WebhookRequestContext, vm, oldVM *vmopv1.VirtualMachine) field.ErrorList {
@@ -653,39 +959,145 @@ func (v validator) validateInstanceStorageVolumes(
 	return allErrs
 }
 
-func (v validator) validateReadinessProbe(ctx *context.WebhookRequestContext, vm *vmopv1.VirtualMachine) field.ErrorList {
+// validatePorts validates the named ports of the VM. Like the ports of a
+// container, each port must have a unique IANA_SVC_NAME, so that a
+// VirtualMachineService can refer to it by name.
+func (v validator) validatePorts(_ *context.WebhookRequestContext, vm *vmopv1.VirtualMachine) field.ErrorList {
 	var allErrs field.ErrorList
+	portsPath := field.NewPath("spec", "ports")
+
+	allNames := map[string]struct{}{}
+	for i, port := range vm.Spec.Ports {
+		portPath := portsPath.Index(i)
 
+		if port.Name == "" {
+			allErrs = append(allErrs, field.Required(portPath.Child("name"), ""))
+		} else {
+			for _, msg := range k8svalidation.IsValidPortName(port.Name) {
+				allErrs = append(allErrs, field.Invalid(portPath.Child("name"), port.Name, msg))
+			}
+			if _, ok := allNames[port.Name]; ok {
+				allErrs = append(allErrs, field.Duplicate(portPath.Child("name"), port.Name))
+			}
+			allNames[port.Name] = struct{}{}
+		}
+
+		for _, msg := range k8svalidation.IsValidPortNum(int(port.Port)) {
+			allErrs = append(allErrs, field.Invalid(portPath.Child("port"), port.Port, msg))
+		}
+
+		switch corev1.Protocol(port.Protocol) {
+		case "", corev1.ProtocolTCP, corev1.ProtocolUDP, corev1.ProtocolSCTP:
+		default:
+			allErrs = append(allErrs, field.NotSupported(portPath.Child("protocol"), port.Protocol,
+				[]string{string(corev1.ProtocolTCP), string(corev1.ProtocolUDP), string(corev1.ProtocolSCTP)}))
+		}
+	}
+
+	return allErrs
+}
+
+func (v validator) validateReadinessProbe(ctx *context.WebhookRequestContext, vm *vmopv1.VirtualMachine) field.ErrorList {
 	probe := vm.Spec.ReadinessProbe
 	if probe == nil {
-		return allErrs
+		return nil
+	}
+
+	return v.validateProbeActions(ctx, probe, field.NewPath("spec", "readinessProbe"))
+}
+
+func (v validator) validateLivenessProbe(ctx *context.WebhookRequestContext, vm *vmopv1.VirtualMachine) field.ErrorList {
+	probe := vm.Spec.LivenessProbe
+	if probe == nil {
+		return nil
 	}
 
-	readinessProbePath := field.NewPath("spec", "readinessProbe")
+	return v.validateProbeActions(ctx, &probe.VirtualMachineReadinessProbeSpec, field.NewPath("spec", "livenessProbe"))
+}
+
+// validateProbeActions validates the actions of a readiness or liveness probe.
+func (v validator) validateProbeActions(
+	ctx *context.WebhookRequestContext,
+	probe *vmopv1.VirtualMachineReadinessProbeSpec,
+	probePath *field.Path) field.ErrorList {
+
+	var allErrs field.ErrorList
 
-	if probe.TCPSocket != nil && probe.GuestHeartbeat != nil {
-		allErrs = append(allErrs, field.Forbidden(readinessProbePath, readinessProbeOnlyOneAction))
+	numActions := 0
+	if probe.TCPSocket != nil {
+		numActions++
+	}
+	if probe.HTTPGet != nil {
+		numActions++
+	}
+	if probe.GuestHeartbeat != nil {
+		numActions++
+	}
+	if numActions > 1 {
+		allErrs = append(allErrs, field.Forbidden(probePath, readinessProbeOnlyOneAction))
 	}
 
 	if probe.TCPSocket != nil {
-		tcpSocketPath := readinessProbePath.Child("tcpSocket")
+		tcpSocketPath := probePath.Child("tcpSocket")
+		allErrs = append(allErrs, v.validateProbePort(ctx, tcpSocketPath, probe.TCPSocket.Port.IntValue())...)
+	}
 
-		// Validate port if environment is a restricted network environment between SV CP VMs and Workload VMs e.g. VMC.
-		if probe.TCPSocket.Port.IntValue() != allowedRestrictedNetworkTCPProbePort {
-			isRestrictedEnv, err := v.isNetworkRestrictedForReadinessProbe(ctx)
-			if err != nil {
-				allErrs = append(allErrs, field.Forbidden(tcpSocketPath, err.Error()))
-			} else if isRestrictedEnv {
-				allErrs = append(allErrs,
-					field.NotSupported(tcpSocketPath.Child("port"), probe.TCPSocket.Port.IntValue(),
-						[]string{strconv.Itoa(allowedRestrictedNetworkTCPProbePort)}))
-			}
+	if probe.HTTPGet != nil {
+		httpGetPath := probePath.Child("httpGet")
+		allErrs = append(allErrs, v.validateProbePort(ctx, httpGetPath, probe.HTTPGet.Port.IntValue())...)
+		allErrs = append(allErrs, v.validateHTTPGetAction(probe.HTTPGet, httpGetPath)...)
+	}
+
+	return allErrs
+}
+
+// validateProbePort validates the port of a probe action that
+// connects to the VM over the network.
+func (v validator) validateProbePort(ctx *context.WebhookRequestContext, actionPath *field.Path, port int) field.ErrorList {
+	var allErrs field.ErrorList
+
+	// Validate port if environment is a restricted network environment between SV CP VMs and Workload VMs e.g. VMC.
+	if port != allowedRestrictedNetworkTCPProbePort {
+		isRestrictedEnv, err := v.isNetworkRestrictedForReadinessProbe(ctx)
+		if err != nil {
+			allErrs = append(allErrs, field.Forbidden(actionPath, err.Error()))
+		} else if isRestrictedEnv {
+			allErrs = append(allErrs,
+				field.NotSupported(actionPath.Child("port"), port,
+					[]string{strconv.Itoa(allowedRestrictedNetworkTCPProbePort)}))
 		}
 	}
 
 	return allErrs
 }
 
+func (v validator) validateHTTPGetAction(action *vmopv1.HTTPGetAction, httpGetPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if action.Path != "" && !strings.HasPrefix(action.Path, "/") {
+		allErrs = append(allErrs, field.Invalid(httpGetPath.Child("path"), action.Path, readinessProbeHTTPPathNotAbsolute))
+	}
+
+	// The request is sent from the operator, so a host name, which may resolve
+	// to any host, is not allowed. The prober checks the IP is one of the VM's.
+	if action.Host != "" && net.ParseIP(action.Host) == nil {
+		allErrs = append(allErrs, field.Invalid(httpGetPath.Child("host"), action.Host, readinessProbeHTTPHostNotIP))
+	}
+
+	for i, h := range action.HTTPHeaders {
+		for _, msg := range k8svalidation.IsHTTPHeaderName(h.Name) {
+			allErrs = append(allErrs, field.Invalid(httpGetPath.Child("httpHeaders").Index(i).Child("name"), h.Name, msg))
+		}
+	}
+
+	if r := action.ExpectedStatus; r != nil && r.Min > r.Max {
+		allErrs = append(allErrs, field.Invalid(httpGetPath.Child("expectedStatus"),
+			fmt.Sprintf("%d-%d", r.Min, r.Max), readinessProbeHTTPStatusRangeInvalid))
+	}
+
+	return allErrs
+}
+
 var megaByte = resource.MustParse("1Mi")
 
 func (v validator) validateAdvanced(ctx *context.WebhookRequestContext, vm *vmopv1.VirtualMachine) field.ErrorList {
@@ -708,6 +1120,92 @@ func (v validator) validateAdvanced(ctx *context.WebhookRequestContext, vm *vmop
 	return allErrs
 }
 
+// validateBootDiskCapacity rejects a boot disk capacity less than the capacity
+// of the image's boot disk, and on update, decreasing the boot disk capacity.
+// An increase is applied by extending the VM's boot disk in place.
+func (v validator) validateBootDiskCapacity(
+	ctx *context.WebhookRequestContext,
+	vm, oldVM *vmopv1.VirtualMachine) field.ErrorList {
+
+	bootDiskCapacity := func(vm *vmopv1.VirtualMachine) *resource.Quantity {
+		if vm.Spec.Advanced == nil {
+			return nil
+		}
+		if capacity := vm.Spec.Advanced.BootDiskCapacity; capacity != nil && !capacity.IsZero() {
+			return capacity
+		}
+		return nil
+	}
+
+	capacity := bootDiskCapacity(vm)
+	if capacity == nil {
+		return nil
+	}
+
+	capacityPath := field.NewPath("spec", "advanced", "bootDiskCapacity")
+
+	if oldVM != nil {
+		if oldCapacity := bootDiskCapacity(oldVM); oldCapacity != nil {
+			switch capacity.Cmp(*oldCapacity) {
+			case 0:
+				return nil
+			case -1:
+				return field.ErrorList{field.Invalid(capacityPath, capacity.String(),
+					fmt.Sprintf(bootDiskCapacityDecreaseNotAllowedFmt, oldCapacity.String()))}
+			}
+		}
+	}
+
+	imageCapacity, err := v.getImageBootDiskCapacity(ctx, vm)
+	if err != nil {
+		return field.ErrorList{field.InternalError(capacityPath, err)}
+	}
+
+	if imageCapacity != nil && capacity.Cmp(*imageCapacity) < 0 {
+		return field.ErrorList{field.Invalid(capacityPath, capacity.String(),
+			fmt.Sprintf(bootDiskCapacityLessThanImageFmt, imageCapacity.String()))}
+	}
+
+	return nil
+}
+
+// getImageBootDiskCapacity returns the capacity of the boot disk of the VM's
+// namespace or cluster scoped image. Nil is returned if the image does not exist
+// or its boot disk is not known yet.
+func (v validator) getImageBootDiskCapacity(
+	ctx *context.WebhookRequestContext,
+	vm *vmopv1.VirtualMachine) (*resource.Quantity, error) {
+
+	if vm.Spec.ImageName == "" {
+		return nil, nil
+	}
+
+	var status *vmopv1.VirtualMachineImageStatus
+
+	vmImage := &vmopv1.VirtualMachineImage{}
+	err := v.client.Get(ctx, client.ObjectKey{Namespace: vm.Namespace, Name: vm.Spec.ImageName}, vmImage)
+	switch {
+	case err == nil:
+		status = &vmImage.Status
+	case apierrors.IsNotFound(err):
+		clusterVMImage := &vmopv1.ClusterVirtualMachineImage{}
+		if err := v.client.Get(ctx, client.ObjectKey{Name: vm.Spec.ImageName}, clusterVMImage); err != nil {
+			return nil, client.IgnoreNotFound(err)
+		}
+		status = &clusterVMImage.Status
+	default:
+		return nil, err
+	}
+
+	for i := range status.Disks {
+		if status.Disks[i].Boot {
+			return status.Disks[i].Capacity, nil
+		}
+	}
+
+	return nil, nil
+}
+
 func (v validator) validateNextRestartTimeOnCreate(
 	ctx *context.WebhookRequestContext,
 	vm *vmopv1.VirtualMachine) field.ErrorList {
@@ -841,7 +1339,7 @@ func (v validator) validateUpdatesWhenPoweredOn(ctx *context.WebhookRequestConte
 		allErrs = append(allErrs, field.Forbidden(specPath.Child("bootstrap"), updatesNotAllowedWhenPowerOn))
 	}
 	if !equality.Semantic.DeepEqual(vm.Spec.Network, oldVM.Spec.Network) {
-		allErrs = append(allErrs, field.Forbidden(specPath.Child("network"), updatesNotAllowedWhenPowerOn))
+		allErrs = append(allErrs, v.validateNetworkUpdatesWhenPoweredOn(vm.Spec.Network, oldVM.Spec.Network)...)
 	}
 
 	// TODO: More checks.
@@ -849,13 +1347,59 @@ func (v validator) validateUpdatesWhenPoweredOn(ctx *context.WebhookRequestConte
 	return allErrs
 }
 
+// validateNetworkUpdatesWhenPoweredOn allows network interfaces to be added to
+// and removed from a powered on VM, but does not allow an existing interface
+// to be modified.
+func (v validator) validateNetworkUpdatesWhenPoweredOn(networkSpec, oldNetworkSpec *vmopv1.VirtualMachineNetworkSpec) field.ErrorList {
+	var allErrs field.ErrorList
+	networkPath := field.NewPath("spec", "network")
+
+	if networkSpec == nil || oldNetworkSpec == nil {
+		return append(allErrs, field.Forbidden(networkPath, updatesNotAllowedWhenPowerOn))
+	}
+
+	if networkSpec.HostName != oldNetworkSpec.HostName {
+		allErrs = append(allErrs, field.Forbidden(networkPath.Child("hostName"), updatesNotAllowedWhenPowerOn))
+	}
+	if networkSpec.Disabled != oldNetworkSpec.Disabled {
+		allErrs = append(allErrs, field.Forbidden(networkPath.Child("disabled"), updatesNotAllowedWhenPowerOn))
+	}
+
+	interfacesPath := networkPath.Child("interfaces")
+
+	// The default interface is used when the list is empty, so the list cannot
+	// change from or to empty while the VM is powered on.
+	if len(networkSpec.Interfaces) == 0 || len(oldNetworkSpec.Interfaces) == 0 {
+		if len(networkSpec.Interfaces) != len(oldNetworkSpec.Interfaces) {
+			allErrs = append(allErrs, field.Forbidden(interfacesPath, updatesNotAllowedWhenPowerOn))
+		}
+		return allErrs
+	}
+
+	oldInterfaces := make(map[string]vmopv1.VirtualMachineNetworkInterfaceSpec, len(oldNetworkSpec.Interfaces))
+	for _, interfaceSpec := range oldNetworkSpec.Interfaces {
+		oldInterfaces[interfaceSpec.Name] = interfaceSpec
+	}
+
+	for i, interfaceSpec := range networkSpec.Interfaces {
+		oldInterfaceSpec, ok := oldInterfaces[interfaceSpec.Name]
+		if !ok {
+			// The interface is hot-added.
+			continue
+		}
+		if !equality.Semantic.DeepEqual(interfaceSpec, oldInterfaceSpec) {
+			allErrs = append(allErrs, field.Forbidden(interfacesPath.Index(i), updatesNotAllowedWhenPowerOn))
+		}
+	}
+
+	return allErrs
+}
+
 func (v validator) validateImmutableFields(_ *context.WebhookRequestContext, vm, oldVM *vmopv1.VirtualMachine) field.ErrorList {
 	var allErrs field.ErrorList
 	specPath := field.NewPath("spec")
 
 	allErrs = append(allErrs, validation.ValidateImmutableField(vm.Spec.ImageName, oldVM.Spec.ImageName, specPath.Child("imageName"))...)
-	allErrs = append(allErrs, validation.ValidateImmutableField(vm.Spec.ClassName, oldVM.Spec.ClassName, specPath.Child("className"))...)
-	allErrs = append(allErrs, validation.ValidateImmutableField(vm.Spec.StorageClass, oldVM.Spec.StorageClass, specPath.Child("storageClass"))...)
 	allErrs = append(allErrs, validation.ValidateImmutableField(vm.Spec.MinHardwareVersion, oldVM.Spec.MinHardwareVersion, specPath.Child("minHardwareVersion"))...)
 	// TODO: More checks.
 
@@ -886,10 +1430,19 @@ func (v validator) validateAvailabilityZone(ctx *context.WebhookRequestContext,
 	zoneLabelPath := field.NewPath("metadata", "labels").Key(topology.KubernetesTopologyZoneLabelKey)
 
 	if oldVM != nil {
-		// Once the zone has been set then make sure the field is immutable.
+		// Once the zone has been set it may only be changed to migrate the VM
+		// to another zone.
 		if oldVal := oldVM.Labels[topology.KubernetesTopologyZoneLabelKey]; oldVal != "" {
 			newVal := vm.Labels[topology.KubernetesTopologyZoneLabelKey]
-			return append(allErrs, validation.ValidateImmutableField(newVal, oldVal, zoneLabelPath)...)
+			if newVal == oldVal {
+				return allErrs
+			}
+			if newVal == "" {
+				return append(allErrs, field.Forbidden(zoneLabelPath, zoneRemovalNotAllowed))
+			}
+			if instancestorage.IsPresent(vm) {
+				return append(allErrs, field.Forbidden(zoneLabelPath, zoneMigrationWithInstanceStorage))
+			}
 		}
 	}
 
@@ -944,5 +1497,9 @@ func (v validator) validateAnnotation(ctx *context.WebhookRequestContext, vm, ol
 		allErrs = append(allErrs, field.Forbidden(annotationPath.Child(vmopv1.FirstBootDoneAnnotation), modifyAnnotationNotAllowedForNonAdmin))
 	}
 
+	if vm.Annotations[vmopv1.NetworkSpecHashAnnotation] != oldVM.Annotations[vmopv1.NetworkSpecHashAnnotation] {
+		allErrs = append(allErrs, field.Forbidden(annotationPath.Child(vmopv1.NetworkSpecHashAnnotation), modifyAnnotationNotAllowedForNonAdmin))
+	}
+
 	return allErrs
 }
@@ -14,6 +14,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -85,30 +86,51 @@ func unitTestsValidateCreate() {
 	)
 
 	type createArgs struct {
-		isServiceUser                     bool
-		invalidImageName                  bool
-		invalidClassName                  bool
-		invalidVolumeName                 bool
-		dupVolumeName                     bool
-		invalidVolumeSource               bool
-		invalidPVCName                    bool
-		invalidPVCReadOnly                bool
-		invalidStorageClass               bool
-		notFoundStorageClass              bool
-		validStorageClass                 bool
-		withInstanceStorageVolumes        bool
-		invalidReadinessProbe             bool
-		isRestrictedNetworkEnv            bool
-		isRestrictedNetworkValidProbePort bool
-		isNonRestrictedNetworkEnv         bool
-		isNoAvailabilityZones             bool
-		isWCPFaultDomainsFSSEnabled       bool
-		isInvalidAvailabilityZone         bool
-		isEmptyAvailabilityZone           bool
-		powerState                        vmopv1.VirtualMachinePowerState
-		nextRestartTime                   string
-		adminOnlyAnnotations              bool
-		isPrivilegedUser                  bool
+		isServiceUser                       bool
+		invalidImageName                    bool
+		invalidClassName                    bool
+		invalidVolumeName                   bool
+		dupVolumeName                       bool
+		invalidVolumeSource                 bool
+		invalidPVCName                      bool
+		invalidPVCReadOnly                  bool
+		validAttachOptions                  bool
+		missingControllerType               bool
+		invalidUnitNumber                   bool
+		reservedSCSIUnitNumber              bool
+		multiWriterNonPersistent            bool
+		conflictingControllerType           bool
+		conflictingUnitNumber               bool
+		multipleVolumeSources               bool
+		validEphemeralVolume                bool
+		invalidEphemeralVolumeSize          bool
+		missingImageVolumeName              bool
+		invalidStorageClass                 bool
+		notFoundStorageClass                bool
+		validStorageClass                   bool
+		withInstanceStorageVolumes          bool
+		validPorts                          bool
+		invalidPortName                     bool
+		dupPortName                         bool
+		invalidPortNum                      bool
+		invalidPortProtocol                 bool
+		invalidReadinessProbe               bool
+		isRestrictedNetworkEnv              bool
+		isRestrictedNetworkValidProbePort   bool
+		isNonRestrictedNetworkEnv           bool
+		isHTTPGetReadinessProbe             bool
+		invalidHTTPGetReadinessProbe        bool
+		invalidHTTPGetReadinessProbeActions bool
+		invalidLivenessProbe                bool
+		isLivenessProbe                     bool
+		isNoAvailabilityZones               bool
+		isWCPFaultDomainsFSSEnabled         bool
+		isInvalidAvailabilityZone           bool
+		isEmptyAvailabilityZone             bool
+		powerState                          vmopv1.VirtualMachinePowerState
+		nextRestartTime                     string
+		adminOnlyAnnotations                bool
+		isPrivilegedUser                    bool
 	}
 
 	validateCreate := func(args createArgs, expectedAllowed bool, expectedReason string, expectedErr error) {
@@ -139,6 +161,81 @@ func unitTestsValidateCreate() {
 		if args.invalidPVCReadOnly {
 			ctx.vm.Spec.Volumes[0].PersistentVolumeClaim.ReadOnly = true
 		}
+		if args.validAttachOptions || args.conflictingControllerType || args.conflictingUnitNumber {
+			claim := ctx.vm.Spec.Volumes[0].PersistentVolumeClaim
+			claim.ControllerType = vmopv1.VirtualMachineVolumeControllerTypeParaVirtualSCSI
+			claim.ControllerBusNumber = pointer.Int32(1)
+			claim.UnitNumber = pointer.Int32(0)
+			claim.DiskMode = vmopv1.VirtualMachineVolumeDiskModeIndependentPersistent
+			claim.SharingMode = vmopv1.VirtualMachineVolumeSharingModeMultiWriter
+
+			vol := *ctx.vm.Spec.Volumes[0].DeepCopy()
+			vol.Name += "-2"
+			vol.PersistentVolumeClaim.ClaimName += "-2"
+			vol.PersistentVolumeClaim.UnitNumber = pointer.Int32(1)
+			if args.conflictingControllerType {
+				vol.PersistentVolumeClaim.ControllerType = vmopv1.VirtualMachineVolumeControllerTypeLsiLogic
+			}
+			if args.conflictingUnitNumber {
+				vol.PersistentVolumeClaim.UnitNumber = pointer.Int32(0)
+			}
+			ctx.vm.Spec.Volumes = append(ctx.vm.Spec.Volumes, vol)
+		}
+		if args.missingControllerType {
+			ctx.vm.Spec.Volumes[0].PersistentVolumeClaim.ControllerBusNumber = pointer.Int32(0)
+		}
+		if args.invalidUnitNumber {
+			claim := ctx.vm.Spec.Volumes[0].PersistentVolumeClaim
+			claim.ControllerType = vmopv1.VirtualMachineVolumeControllerTypeNVME
+			claim.ControllerBusNumber = pointer.Int32(0)
+			claim.UnitNumber = pointer.Int32(15)
+		}
+		if args.reservedSCSIUnitNumber {
+			claim := ctx.vm.Spec.Volumes[0].PersistentVolumeClaim
+			claim.ControllerType = vmopv1.VirtualMachineVolumeControllerTypeParaVirtualSCSI
+			claim.ControllerBusNumber = pointer.Int32(0)
+			claim.UnitNumber = pointer.Int32(7)
+		}
+		if args.multiWriterNonPersistent {
+			claim := ctx.vm.Spec.Volumes[0].PersistentVolumeClaim
+			claim.DiskMode = vmopv1.VirtualMachineVolumeDiskModeIndependentNonPersistent
+			claim.SharingMode = vmopv1.VirtualMachineVolumeSharingModeMultiWriter
+		}
+
+		if args.multipleVolumeSources {
+			ctx.vm.Spec.Volumes[0].Ephemeral = &vmopv1.EphemeralVolumeSource{
+				StorageClass: builder.DummyStorageClassName,
+				Size:         resource.MustParse("1Gi"),
+			}
+		}
+		if args.validEphemeralVolume || args.invalidEphemeralVolumeSize {
+			storageClass := builder.DummyStorageClass()
+			Expect(ctx.Client.Create(ctx, storageClass)).To(Succeed())
+			rlName := storageClass.Name + ".storageclass.storage.k8s.io/persistentvolumeclaims"
+			Expect(ctx.Client.Create(ctx, builder.DummyResourceQuota(ctx.vm.Namespace, rlName))).To(Succeed())
+
+			size := resource.MustParse("1Gi")
+			if args.invalidEphemeralVolumeSize {
+				size = resource.MustParse("1500Ki")
+			}
+			ctx.vm.Spec.Volumes = append(ctx.vm.Spec.Volumes, vmopv1.VirtualMachineVolume{
+				Name: "scratch",
+				VirtualMachineVolumeSource: vmopv1.VirtualMachineVolumeSource{
+					Ephemeral: &vmopv1.EphemeralVolumeSource{
+						StorageClass: storageClass.Name,
+						Size:         size,
+					},
+				},
+			})
+		}
+		if args.missingImageVolumeName {
+			ctx.vm.Spec.Volumes = append(ctx.vm.Spec.Volumes, vmopv1.VirtualMachineVolume{
+				Name: "data",
+				VirtualMachineVolumeSource: vmopv1.VirtualMachineVolumeSource{
+					Image: &vmopv1.ImageVolumeSource{},
+				},
+			})
+		}
 
 		if args.invalidStorageClass {
 			// StorageClass specifies but not assigned to ResourceQuota.
@@ -166,12 +263,45 @@ func unitTestsValidateCreate() {
 			ctx.vm.Spec.Volumes = append(ctx.vm.Spec.Volumes, instanceStorageVolumes...)
 		}
 
+		if args.validPorts || args.invalidPortName || args.dupPortName || args.invalidPortNum || args.invalidPortProtocol {
+			ctx.vm.Spec.Ports = []vmopv1.VirtualMachinePort{
+				{Name: "http", Port: 8080},
+				{Name: "dns", Port: 53, Protocol: string(corev1.ProtocolUDP)},
+			}
+		}
+		if args.invalidPortName {
+			ctx.vm.Spec.Ports[0].Name = "Not_Valid"
+		}
+		if args.dupPortName {
+			ctx.vm.Spec.Ports[1].Name = ctx.vm.Spec.Ports[0].Name
+		}
+		if args.invalidPortNum {
+			ctx.vm.Spec.Ports[0].Port = 0
+		}
+		if args.invalidPortProtocol {
+			ctx.vm.Spec.Ports[1].Protocol = "ICMP"
+		}
+
 		if args.invalidReadinessProbe {
 			ctx.vm.Spec.ReadinessProbe = &vmopv1.VirtualMachineReadinessProbeSpec{
 				TCPSocket:      &vmopv1.TCPSocketAction{},
 				GuestHeartbeat: &vmopv1.GuestHeartbeatAction{},
 			}
 		}
+		if args.invalidLivenessProbe {
+			ctx.vm.Spec.LivenessProbe = &vmopv1.VirtualMachineLivenessProbeSpec{
+				VirtualMachineReadinessProbeSpec: vmopv1.VirtualMachineReadinessProbeSpec{
+					TCPSocket:      &vmopv1.TCPSocketAction{},
+					GuestHeartbeat: &vmopv1.GuestHeartbeatAction{},
+				},
+			}
+		}
+		if args.invalidHTTPGetReadinessProbeActions {
+			ctx.vm.Spec.ReadinessProbe = &vmopv1.VirtualMachineReadinessProbeSpec{
+				HTTPGet:   &vmopv1.HTTPGetAction{Port: intstr.FromInt(6443)},
+				TCPSocket: &vmopv1.TCPSocketAction{Port: intstr.FromInt(6443)},
+			}
+		}
 		if args.isRestrictedNetworkEnv || args.isNonRestrictedNetworkEnv {
 			cm := &corev1.ConfigMap{
 				ObjectMeta: metav1.ObjectMeta{
@@ -192,6 +322,30 @@ func unitTestsValidateCreate() {
 			ctx.vm.Spec.ReadinessProbe = &vmopv1.VirtualMachineReadinessProbeSpec{
 				TCPSocket: &vmopv1.TCPSocketAction{Port: intstr.FromInt(portValue)},
 			}
+			if args.isHTTPGetReadinessProbe {
+				ctx.vm.Spec.ReadinessProbe = &vmopv1.VirtualMachineReadinessProbeSpec{
+					HTTPGet: &vmopv1.HTTPGetAction{Port: intstr.FromInt(portValue)},
+				}
+			}
+			if args.isLivenessProbe {
+				ctx.vm.Spec.LivenessProbe = &vmopv1.VirtualMachineLivenessProbeSpec{
+					VirtualMachineReadinessProbeSpec: *ctx.vm.Spec.ReadinessProbe,
+				}
+				ctx.vm.Spec.ReadinessProbe = nil
+			}
+		}
+		if args.invalidHTTPGetReadinessProbe {
+			ctx.vm.Spec.ReadinessProbe = &vmopv1.VirtualMachineReadinessProbeSpec{
+				HTTPGet: &vmopv1.HTTPGetAction{
+					Path: "healthz",
+					Port: intstr.FromInt(6443),
+					Host: "metadata.internal",
+					HTTPHeaders: []vmopv1.HTTPHeader{
+						{Name: "Bad Header", Value: "value"},
+					},
+					ExpectedStatus: &vmopv1.HTTPStatusCodeRange{Min: 300, Max: 200},
+				},
+			}
 		}
 
 		if args.isWCPFaultDomainsFSSEnabled {
@@ -216,6 +370,7 @@ func unitTestsValidateCreate() {
 		if args.adminOnlyAnnotations {
 			ctx.vm.Annotations[vmopv1.InstanceIDAnnotation] = updateSuffix
 			ctx.vm.Annotations[vmopv1.FirstBootDoneAnnotation] = updateSuffix
+			ctx.vm.Annotations[vmopv1.NetworkSpecHashAnnotation] = updateSuffix
 		}
 
 		if args.isPrivilegedUser {
@@ -264,6 +419,7 @@ func unitTestsValidateCreate() {
 
 	specPath := field.NewPath("spec")
 	volPath := specPath.Child("volumes")
+	portsPath := specPath.Child("ports")
 	nextRestartTimePath := specPath.Child("nextRestartTime")
 	now := time.Now().UTC()
 	annotationPath := field.NewPath("metadata", "annotations")
@@ -276,8 +432,22 @@ func unitTestsValidateCreate() {
 		Entry("should deny invalid image name", createArgs{invalidImageName: true}, false,
 			field.Required(specPath.Child("imageName"), "").Error(), nil),
 
+		Entry("should allow valid ports", createArgs{validPorts: true}, true, nil, nil),
+		Entry("should deny invalid port name", createArgs{invalidPortName: true}, false,
+			field.Invalid(portsPath.Index(0).Child("name"), "Not_Valid", validation.IsValidPortName("Not_Valid")[0]).Error(), nil),
+		Entry("should deny duplicated port names", createArgs{dupPortName: true}, false,
+			field.Duplicate(portsPath.Index(1).Child("name"), "http").Error(), nil),
+		Entry("should deny invalid port number", createArgs{invalidPortNum: true}, false,
+			field.Invalid(portsPath.Index(0).Child("port"), 0, validation.IsValidPortNum(0)[0]).Error(), nil),
+		Entry("should deny unsupported port protocol", createArgs{invalidPortProtocol: true}, false,
+			field.NotSupported(portsPath.Index(1).Child("protocol"), "ICMP", []string{"TCP", "UDP", "SCTP"}).Error(), nil),
+
 		Entry("should fail when Readiness probe has multiple actions", createArgs{invalidReadinessProbe: true}, false,
 			field.Forbidden(specPath.Child("readinessProbe"), "only one action can be specified").Error(), nil),
+		Entry("should fail when Liveness probe has multiple actions", createArgs{invalidLivenessProbe: true}, false,
+			field.Forbidden(specPath.Child("livenessProbe"), "only one action can be specified").Error(), nil),
+		Entry("should fail when Readiness probe has HTTP GET and another action", createArgs{invalidHTTPGetReadinessProbeActions: true}, false,
+			field.Forbidden(specPath.Child("readinessProbe"), "only one action can be specified").Error(), nil),
 
 		Entry("should deny invalid volume name", createArgs{invalidVolumeName: true}, false,
 			field.Invalid(volPath.Index(0).Child("name"), "underscore_not_valid", validation.IsDNS1123Subdomain("underscore_not_valid")[0]).Error(), nil),
@@ -289,6 +459,28 @@ func unitTestsValidateCreate() {
 			field.Required(volPath.Index(0).Child("persistentVolumeClaim", "claimName"), "").Error(), nil),
 		Entry("should deny invalid PVC read only", createArgs{invalidPVCReadOnly: true}, false,
 			field.NotSupported(volPath.Index(0).Child("persistentVolumeClaim", "readOnly"), true, []string{"false"}).Error(), nil),
+		Entry("should allow valid volume attach options", createArgs{validAttachOptions: true}, true, nil, nil),
+		Entry("should deny controller bus number without controller type", createArgs{missingControllerType: true}, false,
+			field.Required(volPath.Index(0).Child("persistentVolumeClaim", "controllerType"), "").Error(), nil),
+		Entry("should deny unit number out of range for the controller type", createArgs{invalidUnitNumber: true}, false,
+			field.Invalid(volPath.Index(0).Child("persistentVolumeClaim", "unitNumber"), int32(15), "must be between 0 and 14 for a NVME controller").Error(), nil),
+		Entry("should deny the unit number reserved for the SCSI controller", createArgs{reservedSCSIUnitNumber: true}, false,
+			field.Invalid(volPath.Index(0).Child("persistentVolumeClaim", "unitNumber"), int32(7), "unit number 7 is reserved for the SCSI controller").Error(), nil),
+		Entry("should deny MultiWriter sharing mode with IndependentNonPersistent disk mode", createArgs{multiWriterNonPersistent: true}, false,
+			field.Invalid(volPath.Index(0).Child("persistentVolumeClaim", "sharingMode"), vmopv1.VirtualMachineVolumeSharingModeMultiWriter,
+				"MultiWriter sharing mode is not supported with the IndependentNonPersistent disk mode").Error(), nil),
+		Entry("should deny volumes with different controller types on the same bus", createArgs{conflictingControllerType: true}, false,
+			field.Invalid(volPath.Index(1).Child("persistentVolumeClaim", "controllerType"), vmopv1.VirtualMachineVolumeControllerTypeLsiLogic,
+				"conflicts with the ParaVirtualSCSI controller of volume "+builder.DummyVolumeName+" on the same bus").Error(), nil),
+		Entry("should deny volumes with the same unit number on the same controller", createArgs{conflictingUnitNumber: true}, false,
+			field.Invalid(volPath.Index(1).Child("persistentVolumeClaim", "unitNumber"), int32(0), "conflicts with the unit number of volume "+builder.DummyVolumeName).Error(), nil),
+		Entry("should deny a volume with multiple volume sources", createArgs{multipleVolumeSources: true}, false,
+			field.Forbidden(volPath.Index(0), "only one volume source can be specified").Error(), nil),
+		Entry("should allow valid ephemeral volume", createArgs{validEphemeralVolume: true}, true, nil, nil),
+		Entry("should deny ephemeral volume size that is not a multiple of MB", createArgs{invalidEphemeralVolumeSize: true}, false,
+			field.Invalid(volPath.Index(1).Child("ephemeral", "size"), "1500Ki", "value must be a multiple of MB").Error(), nil),
+		Entry("should deny image volume without an image name", createArgs{missingImageVolumeName: true}, false,
+			field.Required(volPath.Index(1).Child("image", "name"), "").Error(), nil),
 		Entry("should deny a StorageClass that does not exist", createArgs{notFoundStorageClass: true}, false,
 			field.Invalid(specPath.Child("storageClass"), builder.DummyStorageClassName, fmt.Sprintf("Storage policy is not associated with the namespace %s", "")).Error(), nil),
 		Entry("should deny a StorageClass that is not associated with the namespace", createArgs{invalidStorageClass: true}, false,
@@ -302,6 +494,20 @@ func unitTestsValidateCreate() {
 			field.NotSupported(specPath.Child("readinessProbe", "tcpSocket", "port"), 443, []string{"6443"}).Error(), nil),
 		Entry("should allow when restricted network and TCP port in readiness probe is 6443", createArgs{isRestrictedNetworkEnv: true, isRestrictedNetworkValidProbePort: true}, true, nil, nil),
 		Entry("should allow when not restricted network and TCP port in readiness probe is not 6443", createArgs{isNonRestrictedNetworkEnv: true, isRestrictedNetworkValidProbePort: false}, true, nil, nil),
+		Entry("should deny when restricted network and HTTP GET port in readiness probe is not 6443", createArgs{isRestrictedNetworkEnv: true, isHTTPGetReadinessProbe: true, isRestrictedNetworkValidProbePort: false}, false,
+			field.NotSupported(specPath.Child("readinessProbe", "httpGet", "port"), 443, []string{"6443"}).Error(), nil),
+		Entry("should allow when restricted network and HTTP GET port in readiness probe is 6443", createArgs{isRestrictedNetworkEnv: true, isHTTPGetReadinessProbe: true, isRestrictedNetworkValidProbePort: true}, true, nil, nil),
+		Entry("should allow when not restricted network and HTTP GET port in readiness probe is not 6443", createArgs{isNonRestrictedNetworkEnv: true, isHTTPGetReadinessProbe: true, isRestrictedNetworkValidProbePort: false}, true, nil, nil),
+		Entry("should deny when restricted network and TCP port in liveness probe is not 6443", createArgs{isRestrictedNetworkEnv: true, isLivenessProbe: true, isRestrictedNetworkValidProbePort: false}, false,
+			field.NotSupported(specPath.Child("livenessProbe", "tcpSocket", "port"), 443, []string{"6443"}).Error(), nil),
+		Entry("should allow when restricted network and TCP port in liveness probe is 6443", createArgs{isRestrictedNetworkEnv: true, isLivenessProbe: true, isRestrictedNetworkValidProbePort: true}, true, nil, nil),
+		Entry("should deny invalid HTTP GET readiness probe", createArgs{invalidHTTPGetReadinessProbe: true}, false,
+			strings.Join([]string{
+				field.Invalid(specPath.Child("readinessProbe", "httpGet", "path"), "healthz", "must be an absolute path").Error(),
+				field.Invalid(specPath.Child("readinessProbe", "httpGet", "host"), "metadata.internal", "must be an IP of the VM").Error(),
+				field.Invalid(specPath.Child("readinessProbe", "httpGet", "httpHeaders").Index(0).Child("name"), "Bad Header", validation.IsHTTPHeaderName("Bad Header")[0]).Error(),
+				field.Invalid(specPath.Child("readinessProbe", "httpGet", "expectedStatus"), "300-200", "min must be less than or equal to max").Error(),
+			}, ", "), nil),
 
 		Entry("should allow when VM specifies no availability zone, there are availability zones, and WCP FaultDomains FSS is disabled", createArgs{isEmptyAvailabilityZone: true}, true, nil, nil),
 		Entry("should allow when VM specifies no availability zone, there are no availability zones, and WCP FaultDomains FSS is disabled", createArgs{isEmptyAvailabilityZone: true, isNoAvailabilityZones: true}, true, nil, nil),
@@ -333,6 +539,7 @@ func unitTestsValidateCreate() {
 			strings.Join([]string{
 				field.Forbidden(annotationPath.Child(vmopv1.InstanceIDAnnotation), "modifying this annotation is not allowed for non-admin users").Error(),
 				field.Forbidden(annotationPath.Child(vmopv1.FirstBootDoneAnnotation), "modifying this annotation is not allowed for non-admin users").Error(),
+				field.Forbidden(annotationPath.Child(vmopv1.NetworkSpecHashAnnotation), "modifying this annotation is not allowed for non-admin users").Error(),
 			}, ", "), nil),
 		Entry("should allow creating VM with admin-only annotations set by service user", createArgs{isServiceUser: true, adminOnlyAnnotations: true}, true, nil, nil),
 
@@ -507,6 +714,35 @@ func unitTestsValidateCreate() {
 					expectAllowed: true,
 				},
 			),
+			Entry("allow LinuxPrep with customization",
+				testParams{
+					setup: func(ctx *unitValidatingWebhookContext) {
+						ctx.vm.Spec.Bootstrap = &vmopv1.VirtualMachineBootstrapSpec{
+							LinuxPrep: &vmopv1.VirtualMachineBootstrapLinuxPrepSpec{},
+							Customization: &vmopv1.VirtualMachineBootstrapCustomizationSpec{
+								RetryOnSecretChange: true,
+								GuestCredentials:    "guest-creds",
+							},
+						}
+					},
+					expectAllowed: true,
+				},
+			),
+			Entry("disallow CloudInit with customization",
+				testParams{
+					setup: func(ctx *unitValidatingWebhookContext) {
+						ctx.vm.Spec.Bootstrap = &vmopv1.VirtualMachineBootstrapSpec{
+							CloudInit: &vmopv1.VirtualMachineBootstrapCloudInitSpec{},
+							Customization: &vmopv1.VirtualMachineBootstrapCustomizationSpec{
+								RetryOnSecretChange: true,
+							},
+						}
+					},
+					validate: doValidateWithMsg(
+						`spec.bootstrap.customization: Forbidden: customization may only be used with either LinuxPrep or Sysprep bootstrap providers`,
+					),
+				},
+			),
 			Entry("disallow CloudInit mixing inline CloudConfig and RawCloudConfig",
 				testParams{
 					setup: func(ctx *unitValidatingWebhookContext) {
@@ -1061,11 +1297,17 @@ func unitTestsValidateUpdate() {
 	type updateArgs struct {
 		isServiceUser               bool
 		changeClassName             bool
+		clearClassName              bool
 		changeImageName             bool
 		changeStorageClass          bool
+		changeStorageClassNotFound  bool
+		clearStorageClass           bool
 		changeResourcePolicy        bool
 		assignZoneName              bool
 		changeZoneName              bool
+		changeToInvalidZoneName     bool
+		removeZoneName              bool
+		isWCPFaultDomainsFSSEnabled bool
 		isSysprepFeatureEnabled     bool
 		isSysprepTransportUsed      bool
 		withInstanceStorageVolumes  bool
@@ -1079,6 +1321,16 @@ func unitTestsValidateUpdate() {
 		updateAdminOnlyAnnotations  bool
 		removeAdminOnlyAnnotations  bool
 		isPrivilegedUser            bool
+		addNetworkInterface         bool
+		removeNetworkInterface      bool
+		changeNetworkInterface      bool
+		changeNetworkHostName       bool
+		increaseBootDiskCapacity    bool
+		decreaseBootDiskCapacity    bool
+		changeVolumeAttachOptions   bool
+		addVolumeWithAttachOptions  bool
+		addEphemeralVolume          bool
+		changeEphemeralVolume       bool
 	}
 
 	validateUpdate := func(args updateArgs, expectedAllowed bool, expectedReason string, expectedErr error) {
@@ -1098,8 +1350,25 @@ func unitTestsValidateUpdate() {
 		if args.changeClassName {
 			ctx.vm.Spec.ClassName += updateSuffix
 		}
+		if args.clearClassName {
+			ctx.vm.Spec.ClassName = ""
+		}
 		if args.changeStorageClass {
-			ctx.vm.Spec.StorageClass += updateSuffix
+			storageClass := builder.DummyStorageClass()
+			storageClass.Name += updateSuffix
+			Expect(ctx.Client.Create(ctx, storageClass)).To(Succeed())
+			ctx.vm.Spec.StorageClass = storageClass.Name
+
+			rlName := storageClass.Name + ".storageclass.storage.k8s.io/persistentvolumeclaims"
+			resourceQuota := builder.DummyResourceQuota(ctx.vm.Namespace, rlName)
+			Expect(ctx.Client.Create(ctx, resourceQuota)).To(Succeed())
+		}
+		if args.changeStorageClassNotFound {
+			ctx.vm.Spec.StorageClass = builder.DummyStorageClassName + updateSuffix
+		}
+		if args.clearStorageClass {
+			ctx.oldVM.Spec.StorageClass = builder.DummyStorageClassName
+			ctx.vm.Spec.StorageClass = ""
 		}
 		if ctx.vm.Spec.Reserved == nil {
 			ctx.vm.Spec.Reserved = &vmopv1.VirtualMachineReservedSpec{}
@@ -1112,8 +1381,23 @@ func unitTestsValidateUpdate() {
 			ctx.vm.Labels[topology.KubernetesTopologyZoneLabelKey] = builder.DummyAvailabilityZoneName
 		}
 		if args.changeZoneName {
+			zone := builder.DummyAvailabilityZone()
+			zone.Name += updateSuffix
+			Expect(ctx.Client.Create(ctx, zone)).To(Succeed())
+
+			ctx.oldVM.Labels[topology.KubernetesTopologyZoneLabelKey] = builder.DummyAvailabilityZoneName
+			ctx.vm.Labels[topology.KubernetesTopologyZoneLabelKey] = zone.Name
+		}
+		if args.changeToInvalidZoneName {
+			ctx.oldVM.Labels[topology.KubernetesTopologyZoneLabelKey] = builder.DummyAvailabilityZoneName
+			ctx.vm.Labels[topology.KubernetesTopologyZoneLabelKey] = "invalid"
+		}
+		if args.removeZoneName {
 			ctx.oldVM.Labels[topology.KubernetesTopologyZoneLabelKey] = builder.DummyAvailabilityZoneName
-			ctx.vm.Labels[topology.KubernetesTopologyZoneLabelKey] = builder.DummyAvailabilityZoneName + updateSuffix
+			delete(ctx.vm.Labels, topology.KubernetesTopologyZoneLabelKey)
+		}
+		if args.isWCPFaultDomainsFSSEnabled {
+			Expect(os.Setenv(lib.WcpFaultDomainsFSS, "true")).To(Succeed())
 		}
 
 		if args.withInstanceStorageVolumes {
@@ -1176,6 +1460,58 @@ func unitTestsValidateUpdate() {
 			ctx.IsPrivilegedAccount = pkgbuilder.IsPrivilegedAccount(ctx.WebhookContext, ctx.UserInfo)
 		}
 
+		if args.addNetworkInterface {
+			ctx.vm.Spec.Network.Interfaces = append(ctx.vm.Spec.Network.Interfaces,
+				vmopv1.VirtualMachineNetworkInterfaceSpec{Name: "eth1"})
+		}
+		if args.removeNetworkInterface {
+			ctx.oldVM.Spec.Network.Interfaces = append(ctx.oldVM.Spec.Network.Interfaces,
+				vmopv1.VirtualMachineNetworkInterfaceSpec{Name: "eth1"})
+		}
+		if args.changeNetworkInterface {
+			ctx.vm.Spec.Network.Interfaces[0].Network.Name = "my-network"
+		}
+		if args.changeNetworkHostName {
+			ctx.vm.Spec.Network.HostName = "my-new-hostname"
+		}
+
+		if args.changeVolumeAttachOptions {
+			ctx.vm.Spec.Volumes[0].PersistentVolumeClaim.DiskMode = vmopv1.VirtualMachineVolumeDiskModeIndependentPersistent
+		}
+		if args.addVolumeWithAttachOptions {
+			vol := *ctx.vm.Spec.Volumes[0].DeepCopy()
+			vol.Name += "-2"
+			vol.PersistentVolumeClaim.ClaimName += "-2"
+			vol.PersistentVolumeClaim.DiskMode = vmopv1.VirtualMachineVolumeDiskModeIndependentPersistent
+			ctx.vm.Spec.Volumes = append(ctx.vm.Spec.Volumes, vol)
+		}
+
+		if args.addEphemeralVolume || args.changeEphemeralVolume {
+			ephemeralVolume := vmopv1.VirtualMachineVolume{
+				Name: "scratch",
+				VirtualMachineVolumeSource: vmopv1.VirtualMachineVolumeSource{
+					Ephemeral: &vmopv1.EphemeralVolumeSource{
+						StorageClass: builder.DummyStorageClassName,
+						Size:         resource.MustParse("1Gi"),
+					},
+				},
+			}
+			if args.changeEphemeralVolume {
+				ctx.oldVM.Spec.Volumes = append(ctx.oldVM.Spec.Volumes, *ephemeralVolume.DeepCopy())
+				ephemeralVolume.Ephemeral.Size = resource.MustParse("2Gi")
+			}
+			ctx.vm.Spec.Volumes = append(ctx.vm.Spec.Volumes, ephemeralVolume)
+		}
+
+		if args.increaseBootDiskCapacity || args.decreaseBootDiskCapacity {
+			oldCapacity, newCapacity := resource.MustParse("10Gi"), resource.MustParse("20Gi")
+			if args.decreaseBootDiskCapacity {
+				oldCapacity, newCapacity = newCapacity, oldCapacity
+			}
+			ctx.oldVM.Spec.Advanced = &vmopv1.VirtualMachineAdvancedSpec{BootDiskCapacity: &oldCapacity}
+			ctx.vm.Spec.Advanced = &vmopv1.VirtualMachineAdvancedSpec{BootDiskCapacity: &newCapacity}
+		}
+
 		ctx.oldVM.Spec.NextRestartTime = args.lastRestartTime
 		ctx.vm.Spec.NextRestartTime = args.nextRestartTime
 
@@ -1217,12 +1553,24 @@ func unitTestsValidateUpdate() {
 		Entry("should allow", updateArgs{}, true, nil, nil),
 
 		Entry("should deny image name change", updateArgs{changeImageName: true}, false, msg, nil),
-		Entry("should deny class name change", updateArgs{changeClassName: true}, false, msg, nil),
-		Entry("should deny storageClass change", updateArgs{changeStorageClass: true}, false, msg, nil),
+		Entry("should allow class name change", updateArgs{changeClassName: true}, true, nil, nil),
+		Entry("should deny clearing class name", updateArgs{clearClassName: true}, false,
+			field.Required(field.NewPath("spec", "className"), "").Error(), nil),
+		Entry("should allow storageClass change", updateArgs{changeStorageClass: true}, true, nil, nil),
+		Entry("should deny storageClass change to a StorageClass that does not exist", updateArgs{changeStorageClassNotFound: true}, false,
+			field.Invalid(field.NewPath("spec", "storageClass"), builder.DummyStorageClassName+updateSuffix, fmt.Sprintf("Storage policy is not associated with the namespace %s", "dummy-vm-namespace-for-webhook-validation")).Error(), nil),
+		Entry("should deny clearing storageClass", updateArgs{clearStorageClass: true}, false,
+			field.Required(field.NewPath("spec", "storageClass"), "").Error(), nil),
 		Entry("should deny resourcePolicy change", updateArgs{changeResourcePolicy: true}, false, msg, nil),
 
 		Entry("should allow initial zone assignment", updateArgs{assignZoneName: true}, true, nil, nil),
 		Entry("should allow zone name change when WCP FaultDomains FSS is disabled", updateArgs{changeZoneName: true}, true, nil, nil),
+		Entry("should allow zone name change when WCP FaultDomains FSS is enabled", updateArgs{changeZoneName: true, isWCPFaultDomainsFSSEnabled: true}, true, nil, nil),
+		Entry("should deny zone name change to invalid zone when WCP FaultDomains FSS is enabled", updateArgs{changeToInvalidZoneName: true, isWCPFaultDomainsFSSEnabled: true}, false, nil, nil),
+		Entry("should deny zone name removal when WCP FaultDomains FSS is enabled", updateArgs{removeZoneName: true, isWCPFaultDomainsFSSEnabled: true}, false,
+			field.Forbidden(field.NewPath("metadata", "labels").Key(topology.KubernetesTopologyZoneLabelKey), "zone cannot be removed once assigned").Error(), nil),
+		Entry("should deny zone name change with instance storage volumes when WCP FaultDomains FSS is enabled", updateArgs{changeZoneName: true, withInstanceStorageVolumes: true, isServiceUser: true, isWCPFaultDomainsFSSEnabled: true}, false,
+			field.Forbidden(field.NewPath("metadata", "labels").Key(topology.KubernetesTopologyZoneLabelKey), "VM with instance storage volumes cannot be migrated to another zone").Error(), nil),
 
 		Entry("should deny instance storage volume name change, when user is SSO user", updateArgs{changeInstanceStorageVolume: true}, false,
 			field.Forbidden(volumesPath, "adding or modifying instance storage volume claim(s) is not allowed").Error(), nil),
@@ -1243,6 +1591,29 @@ func unitTestsValidateUpdate() {
 		Entry("should disallow updating powered off VM to suspended", updateArgs{oldPowerState: vmopv1.VirtualMachinePowerStateOff, newPowerState: vmopv1.VirtualMachinePowerStateSuspended}, false,
 			field.Invalid(powerStatePath, vmopv1.VirtualMachinePowerStateSuspended, "cannot suspend a VM that is powered off").Error(), nil),
 
+		Entry("should allow adding a network interface to a powered on VM", updateArgs{addNetworkInterface: true}, true, nil, nil),
+		Entry("should allow removing a network interface from a powered on VM", updateArgs{removeNetworkInterface: true}, true, nil, nil),
+		Entry("should disallow changing a network interface of a powered on VM", updateArgs{changeNetworkInterface: true}, false,
+			field.Forbidden(field.NewPath("spec", "network", "interfaces").Index(0), "updates to this field is not allowed when VM power is on").Error(), nil),
+		Entry("should disallow changing the host name of a powered on VM", updateArgs{changeNetworkHostName: true}, false,
+			field.Forbidden(field.NewPath("spec", "network", "hostName"), "updates to this field is not allowed when VM power is on").Error(), nil),
+
+		Entry("should deny changing the attach options of an existing volume", updateArgs{changeVolumeAttachOptions: true}, false,
+			field.Forbidden(volumesPath.Index(0).Child("persistentVolumeClaim"), "attach options of an existing volume cannot be changed").Error(), nil),
+		Entry("should allow adding a volume with attach options to a powered off VM",
+			updateArgs{addVolumeWithAttachOptions: true, oldPowerState: vmopv1.VirtualMachinePowerStateOff, newPowerState: vmopv1.VirtualMachinePowerStateOff}, true, nil, nil),
+		Entry("should deny adding a volume with attach options to a powered on VM",
+			updateArgs{addVolumeWithAttachOptions: true, oldPowerState: vmopv1.VirtualMachinePowerStateOn, newPowerState: vmopv1.VirtualMachinePowerStateOn}, false,
+			field.Forbidden(volumesPath.Index(1).Child("persistentVolumeClaim"), "attach options cannot be specified for a volume added to a powered on VM").Error(), nil),
+		Entry("should deny adding an ephemeral volume", updateArgs{addEphemeralVolume: true}, false,
+			field.Forbidden(volumesPath, "ephemeral and image volumes cannot be added, removed or changed after the VM is created").Error(), nil),
+		Entry("should deny changing an ephemeral volume", updateArgs{changeEphemeralVolume: true}, false,
+			field.Forbidden(volumesPath, "ephemeral and image volumes cannot be added, removed or changed after the VM is created").Error(), nil),
+
+		Entry("should allow increasing the boot disk capacity", updateArgs{increaseBootDiskCapacity: true}, true, nil, nil),
+		Entry("should deny decreasing the boot disk capacity", updateArgs{decreaseBootDiskCapacity: true}, false,
+			field.Invalid(field.NewPath("spec", "advanced", "bootDiskCapacity"), "10Gi", "boot disk capacity cannot be decreased from 20Gi").Error(), nil),
+
 		Entry("should allow updating VM with non-empty, valid nextRestartTime value", updateArgs{
 			nextRestartTime: time.Now().UTC().Format(time.RFC3339Nano)}, true, nil, nil),
 		Entry("should allow updating VM with empty nextRestartTime value if existing value is also empty",
@@ -1281,6 +1652,46 @@ func unitTestsValidateUpdate() {
 		Entry("should allow removing admin-only annotations by privileged users", updateArgs{isPrivilegedUser: true, removeAdminOnlyAnnotations: true}, true, nil, nil),
 	)
 
+	When("the image's boot disk capacity is known", func() {
+		BeforeEach(func() {
+			vmImage := builder.DummyVirtualMachineImageA2(ctx.vm.Spec.ImageName)
+			vmImage.Namespace = ctx.vm.Namespace
+			dataDiskCapacity := resource.MustParse("30Gi")
+			imageCapacity := resource.MustParse("15Gi")
+			vmImage.Status.Disks = []vmopv1.VirtualMachineImageDiskInfo{
+				{Capacity: &dataDiskCapacity},
+				{Capacity: &imageCapacity, Boot: true},
+			}
+			Expect(ctx.Client.Create(ctx, vmImage)).To(Succeed())
+			Expect(ctx.Client.Status().Update(ctx, vmImage)).To(Succeed())
+		})
+
+		doValidate := func(capacity string) admission.Response {
+			bootDiskCapacity := resource.MustParse(capacity)
+			ctx.vm.Spec.Advanced = &vmopv1.VirtualMachineAdvancedSpec{BootDiskCapacity: &bootDiskCapacity}
+
+			var err error
+			ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured(ctx.vm)
+			Expect(err).ToNot(HaveOccurred())
+			ctx.WebhookRequestContext.OldObj, err = builder.ToUnstructured(ctx.oldVM)
+			Expect(err).ToNot(HaveOccurred())
+
+			return ctx.ValidateUpdate(&ctx.WebhookRequestContext)
+		}
+
+		It("should disallow a boot disk capacity less than the image's when none was set before", func() {
+			response := doValidate("10Gi")
+			Expect(response.Allowed).To(BeFalse())
+			Expect(string(response.Result.Reason)).To(Equal(field.Invalid(field.NewPath("spec", "advanced", "bootDiskCapacity"),
+				"10Gi", "boot disk capacity cannot be less than the image's boot disk capacity 15Gi").Error()))
+		})
+
+		It("should allow a boot disk capacity greater than the image's", func() {
+			response := doValidate("20Gi")
+			Expect(response.Allowed).To(BeTrue())
+		})
+	})
+
 	When("the update is performed while object deletion", func() {
 		It("should allow the request", func() {
 			t := metav1.Now()
@@ -0,0 +1,171 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package validation
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmgr "sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	"github.com/vmware-tanzu/vm-operator/pkg/builder"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/lib"
+	"github.com/vmware-tanzu/vm-operator/webhooks/common"
+)
+
+const (
+	webHookName = "default"
+
+	oneTargetRequired  = "exactly one of persistentVolumeClaim or objectStore must be set"
+	invalidPath        = "must be a relative path that does not contain '..'"
+	invalidEndpoint    = "must be an http or https URL"
+	endpointNotAllowed = "is not an object store that VMs may be exported to"
+)
+
+// +kubebuilder:webhook:verbs=create;update,path=/default-validate-vmoperator-vmware-com-v1alpha2-virtualmachineexportrequest,mutating=false,failurePolicy=fail,groups=vmoperator.vmware.com,resources=virtualmachineexportrequests,versions=v1alpha2,name=default.validating.virtualmachineexportrequest.v1alpha2.vmoperator.vmware.com,sideEffects=None,admissionReviewVersions=v1;v1beta1
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachineexportrequests,verbs=get;list
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachineexportrequests/status,verbs=get
+
+// AddToManager adds the webhook to the provided manager.
+func AddToManager(ctx *context.ControllerManagerContext, mgr ctrlmgr.Manager) error {
+	hook, err := builder.NewValidatingWebhook(ctx, mgr, webHookName, NewValidator(mgr.GetClient()))
+	if err != nil {
+		return errors.Wrapf(err, "failed to create virtualmachineexportrequest validation webhook")
+	}
+	mgr.GetWebhookServer().Register(hook.Path, hook)
+	return nil
+}
+
+// NewValidator returns the package's Validator.
+func NewValidator(_ client.Client) builder.Validator {
+	return validator{
+		converter: runtime.DefaultUnstructuredConverter,
+	}
+}
+
+type validator struct {
+	converter runtime.UnstructuredConverter
+}
+
+func (v validator) For() schema.GroupVersionKind {
+	return vmopv1.SchemeGroupVersion.WithKind(reflect.TypeOf(vmopv1.VirtualMachineExportRequest{}).Name())
+}
+
+func (v validator) ValidateCreate(ctx *context.WebhookRequestContext) admission.Response {
+	exportReq, err := v.exportRequestFromUnstructured(ctx.Obj)
+	if err != nil {
+		return webhook.Errored(http.StatusBadRequest, err)
+	}
+
+	var fieldErrs field.ErrorList
+	fieldErrs = append(fieldErrs, v.validateSource(exportReq)...)
+	fieldErrs = append(fieldErrs, v.validateTarget(exportReq)...)
+
+	validationErrs := make([]string, 0, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		validationErrs = append(validationErrs, fieldErr.Error())
+	}
+
+	return common.BuildValidationResponse(ctx, nil, validationErrs, nil)
+}
+
+func (v validator) ValidateDelete(*context.WebhookRequestContext) admission.Response {
+	return admission.Allowed("")
+}
+
+// ValidateUpdate validates the spec is not changed since a request is only
+// processed once.
+func (v validator) ValidateUpdate(ctx *context.WebhookRequestContext) admission.Response {
+	exportReq, err := v.exportRequestFromUnstructured(ctx.Obj)
+	if err != nil {
+		return webhook.Errored(http.StatusBadRequest, err)
+	}
+
+	oldExportReq, err := v.exportRequestFromUnstructured(ctx.OldObj)
+	if err != nil {
+		return webhook.Errored(http.StatusBadRequest, err)
+	}
+
+	var fieldErrs field.ErrorList
+	fieldErrs = append(fieldErrs, validation.ValidateImmutableField(exportReq.Spec, oldExportReq.Spec, field.NewPath("spec"))...)
+
+	validationErrs := make([]string, 0, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		validationErrs = append(validationErrs, fieldErr.Error())
+	}
+	return common.BuildValidationResponse(ctx, nil, validationErrs, nil)
+}
+
+func (v validator) validateSource(exportReq *vmopv1.VirtualMachineExportRequest) field.ErrorList {
+	var allErrs field.ErrorList
+	sourcePath := field.NewPath("spec", "source")
+
+	if exportReq.Spec.Source.Name == "" {
+		allErrs = append(allErrs, field.Required(sourcePath.Child("name"), ""))
+	}
+
+	return allErrs
+}
+
+func (v validator) validateTarget(exportReq *vmopv1.VirtualMachineExportRequest) field.ErrorList {
+	var allErrs field.ErrorList
+	targetPath := field.NewPath("spec", "target")
+	target := exportReq.Spec.Target
+
+	if (target.PersistentVolumeClaim == nil) == (target.ObjectStore == nil) {
+		allErrs = append(allErrs, field.Invalid(targetPath, target, oneTargetRequired))
+		return allErrs
+	}
+
+	if pvc := target.PersistentVolumeClaim; pvc != nil {
+		pvcPath := targetPath.Child("persistentVolumeClaim")
+		if pvc.ClaimName == "" {
+			allErrs = append(allErrs, field.Required(pvcPath.Child("claimName"), ""))
+		}
+		if pvc.Path != "" && (path.IsAbs(pvc.Path) || strings.Contains(pvc.Path, "..")) {
+			allErrs = append(allErrs, field.Invalid(pvcPath.Child("path"), pvc.Path, invalidPath))
+		}
+	}
+
+	if store := target.ObjectStore; store != nil {
+		storePath := targetPath.Child("objectStore")
+		if store.Endpoint == "" {
+			allErrs = append(allErrs, field.Required(storePath.Child("endpoint"), ""))
+		} else if u, err := url.Parse(store.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			allErrs = append(allErrs, field.Invalid(storePath.Child("endpoint"), store.Endpoint, invalidEndpoint))
+		} else if !lib.IsVMExportObjectStoreEndpointAllowed(store.Endpoint) {
+			allErrs = append(allErrs, field.Forbidden(storePath.Child("endpoint"), endpointNotAllowed))
+		}
+		if store.Bucket == "" {
+			allErrs = append(allErrs, field.Required(storePath.Child("bucket"), ""))
+		}
+		if store.CredentialsSecretName == "" {
+			allErrs = append(allErrs, field.Required(storePath.Child("credentialsSecretName"), ""))
+		}
+	}
+
+	return allErrs
+}
+
+// exportRequestFromUnstructured returns the VirtualMachineExportRequest from the unstructured object.
+func (v validator) exportRequestFromUnstructured(obj runtime.Unstructured) (*vmopv1.VirtualMachineExportRequest, error) {
+	exportReq := &vmopv1.VirtualMachineExportRequest{}
+	if err := v.converter.FromUnstructured(obj.UnstructuredContent(), exportReq); err != nil {
+		return nil, err
+	}
+	return exportReq, nil
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package validation_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	"github.com/vmware-tanzu/vm-operator/pkg/lib"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func intgTests() {
+	var oldGetEndpoints func() []string
+
+	BeforeEach(func() {
+		oldGetEndpoints = lib.GetVMExportObjectStoreEndpoints
+		lib.GetVMExportObjectStoreEndpoints = func() []string {
+			return []string{"https://s3.example.com"}
+		}
+	})
+	AfterEach(func() {
+		lib.GetVMExportObjectStoreEndpoints = oldGetEndpoints
+	})
+
+	Describe("Invoking Create", intgTestsValidateCreate)
+	Describe("Invoking Update", intgTestsValidateUpdate)
+	Describe("Invoking Delete", intgTestsValidateDelete)
+}
+
+type intgValidatingWebhookContext struct {
+	builder.IntegrationTestContext
+	exportReq *vmopv1.VirtualMachineExportRequest
+}
+
+func newIntgValidatingWebhookContext() *intgValidatingWebhookContext {
+	ctx := &intgValidatingWebhookContext{
+		IntegrationTestContext: *suite.NewIntegrationTestContext(),
+	}
+
+	ctx.exportReq = builder.DummyVirtualMachineExportRequest(ctx.Namespace, "some-name", "my-vm")
+	return ctx
+}
+
+func intgTestsValidateCreate() {
+	var (
+		err error
+		ctx *intgValidatingWebhookContext
+	)
+	BeforeEach(func() {
+		ctx = newIntgValidatingWebhookContext()
+	})
+	AfterEach(func() {
+		err = nil
+		ctx = nil
+	})
+
+	When("create is performed", func() {
+		BeforeEach(func() {
+			err = ctx.Client.Create(ctx, ctx.exportReq)
+		})
+		It("should allow the request", func() {
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	When("create is performed without a target", func() {
+		BeforeEach(func() {
+			ctx.exportReq.Spec.Target.ObjectStore = nil
+			err = ctx.Client.Create(ctx, ctx.exportReq)
+		})
+		It("should deny the request", func() {
+			Expect(err).To(HaveOccurred())
+		})
+	})
+}
+
+func intgTestsValidateUpdate() {
+	var (
+		err error
+		ctx *intgValidatingWebhookContext
+	)
+
+	BeforeEach(func() {
+		ctx = newIntgValidatingWebhookContext()
+		err = ctx.Client.Create(ctx, ctx.exportReq)
+		Expect(err).ToNot(HaveOccurred())
+	})
+	JustBeforeEach(func() {
+		err = ctx.Client.Update(suite, ctx.exportReq)
+	})
+	AfterEach(func() {
+		err = nil
+		ctx = nil
+	})
+
+	When("update is performed with a changed source", func() {
+		BeforeEach(func() {
+			ctx.exportReq.Spec.Source.Name = "other-vm"
+		})
+		It("should deny the request", func() {
+			Expect(err).To(HaveOccurred())
+		})
+	})
+}
+
+func intgTestsValidateDelete() {
+	var (
+		err error
+		ctx *intgValidatingWebhookContext
+	)
+
+	BeforeEach(func() {
+		ctx = newIntgValidatingWebhookContext()
+		err = ctx.Client.Create(ctx, ctx.exportReq)
+		Expect(err).ToNot(HaveOccurred())
+	})
+	JustBeforeEach(func() {
+		err = ctx.Client.Delete(suite, ctx.exportReq)
+	})
+	AfterEach(func() {
+		err = nil
+		ctx = nil
+	})
+
+	When("delete is performed", func() {
+		It("should allow the request", func() {
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+}
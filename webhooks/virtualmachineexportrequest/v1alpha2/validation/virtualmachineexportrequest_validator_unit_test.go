@@ -0,0 +1,234 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package validation_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	"github.com/vmware-tanzu/vm-operator/pkg/lib"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func unitTests() {
+	Describe("Invoking ValidateCreate", unitTestsValidateCreate)
+	Describe("Invoking ValidateUpdate", unitTestsValidateUpdate)
+	Describe("Invoking ValidateDelete", unitTestsValidateDelete)
+}
+
+type unitValidatingWebhookContext struct {
+	builder.UnitTestContextForValidatingWebhook
+	exportReq    *vmopv1.VirtualMachineExportRequest
+	oldExportReq *vmopv1.VirtualMachineExportRequest
+}
+
+func newUnitTestContextForValidatingWebhook(isUpdate bool) *unitValidatingWebhookContext {
+	exportReq := builder.DummyVirtualMachineExportRequest("some-namespace", "some-name", "my-vm")
+	obj, err := builder.ToUnstructured(exportReq)
+	Expect(err).ToNot(HaveOccurred())
+
+	var oldExportReq *vmopv1.VirtualMachineExportRequest
+	var oldObj *unstructured.Unstructured
+
+	if isUpdate {
+		oldExportReq = exportReq.DeepCopy()
+		oldObj, err = builder.ToUnstructured(oldExportReq)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	return &unitValidatingWebhookContext{
+		UnitTestContextForValidatingWebhook: *suite.NewUnitTestContextForValidatingWebhook(obj, oldObj),
+		exportReq:                           exportReq,
+		oldExportReq:                        oldExportReq,
+	}
+}
+
+func unitTestsValidateCreate() {
+	var (
+		ctx *unitValidatingWebhookContext
+	)
+
+	type createArgs struct {
+		noSourceName       bool
+		noTarget           bool
+		bothTargets        bool
+		pvcTarget          bool
+		noClaimName        bool
+		absolutePath       bool
+		parentPath         bool
+		noEndpoint         bool
+		invalidEndpoint    bool
+		disallowedEndpoint bool
+		noBucket           bool
+		noCredentialsName  bool
+	}
+
+	validateCreate := func(args createArgs, expectedAllowed bool, expectedReason string, expectedErr error) {
+		var err error
+
+		pvcTarget := &vmopv1.VirtualMachineExportPersistentVolumeClaimTarget{ClaimName: "my-pvc"}
+
+		if args.noSourceName {
+			ctx.exportReq.Spec.Source.Name = ""
+		}
+		if args.noTarget {
+			ctx.exportReq.Spec.Target.ObjectStore = nil
+		}
+		if args.bothTargets {
+			ctx.exportReq.Spec.Target.PersistentVolumeClaim = pvcTarget
+		}
+		if args.pvcTarget {
+			ctx.exportReq.Spec.Target.ObjectStore = nil
+			ctx.exportReq.Spec.Target.PersistentVolumeClaim = pvcTarget
+		}
+		if args.noClaimName {
+			pvcTarget.ClaimName = ""
+		}
+		if args.absolutePath {
+			pvcTarget.Path = "/etc"
+		}
+		if args.parentPath {
+			pvcTarget.Path = "exports/../../etc"
+		}
+		if args.noEndpoint {
+			ctx.exportReq.Spec.Target.ObjectStore.Endpoint = ""
+		}
+		if args.invalidEndpoint {
+			ctx.exportReq.Spec.Target.ObjectStore.Endpoint = "ftp://s3.example.com"
+		}
+		if args.disallowedEndpoint {
+			ctx.exportReq.Spec.Target.ObjectStore.Endpoint = "http://169.254.169.254"
+		}
+		if args.noBucket {
+			ctx.exportReq.Spec.Target.ObjectStore.Bucket = ""
+		}
+		if args.noCredentialsName {
+			ctx.exportReq.Spec.Target.ObjectStore.CredentialsSecretName = ""
+		}
+
+		ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured(ctx.exportReq)
+		Expect(err).ToNot(HaveOccurred())
+
+		response := ctx.ValidateCreate(&ctx.WebhookRequestContext)
+		Expect(response.Allowed).To(Equal(expectedAllowed))
+		if expectedReason != "" {
+			Expect(string(response.Result.Reason)).To(ContainSubstring(expectedReason))
+		}
+		if expectedErr != nil {
+			Expect(response.Result.Message).To(Equal(expectedErr.Error()))
+		}
+	}
+
+	var oldGetEndpoints func() []string
+
+	BeforeEach(func() {
+		ctx = newUnitTestContextForValidatingWebhook(false)
+		oldGetEndpoints = lib.GetVMExportObjectStoreEndpoints
+		lib.GetVMExportObjectStoreEndpoints = func() []string {
+			return []string{"https://s3.example.com"}
+		}
+	})
+	AfterEach(func() {
+		ctx = nil
+		lib.GetVMExportObjectStoreEndpoints = oldGetEndpoints
+	})
+
+	DescribeTable("create table", validateCreate,
+		Entry("should allow valid object store target", createArgs{}, true, nil, nil),
+		Entry("should allow valid PVC target", createArgs{pvcTarget: true}, true, nil, nil),
+		Entry("should deny no source name", createArgs{noSourceName: true}, false, "spec.source.name: Required value", nil),
+		Entry("should deny no target", createArgs{noTarget: true}, false, "exactly one of persistentVolumeClaim or objectStore must be set", nil),
+		Entry("should deny both targets", createArgs{bothTargets: true}, false, "exactly one of persistentVolumeClaim or objectStore must be set", nil),
+		Entry("should deny no claim name", createArgs{pvcTarget: true, noClaimName: true}, false, "spec.target.persistentVolumeClaim.claimName: Required value", nil),
+		Entry("should deny absolute path", createArgs{pvcTarget: true, absolutePath: true}, false, `spec.target.persistentVolumeClaim.path: Invalid value: "/etc"`, nil),
+		Entry("should deny parent path", createArgs{pvcTarget: true, parentPath: true}, false, `spec.target.persistentVolumeClaim.path: Invalid value: "exports/../../etc"`, nil),
+		Entry("should deny no endpoint", createArgs{noEndpoint: true}, false, "spec.target.objectStore.endpoint: Required value", nil),
+		Entry("should deny invalid endpoint", createArgs{invalidEndpoint: true}, false, `spec.target.objectStore.endpoint: Invalid value: "ftp://s3.example.com"`, nil),
+		Entry("should deny endpoint that is not allowed", createArgs{disallowedEndpoint: true}, false, "spec.target.objectStore.endpoint: Forbidden", nil),
+		Entry("should deny no bucket", createArgs{noBucket: true}, false, "spec.target.objectStore.bucket: Required value", nil),
+		Entry("should deny no credentials secret name", createArgs{noCredentialsName: true}, false, "spec.target.objectStore.credentialsSecretName: Required value", nil),
+	)
+}
+
+func unitTestsValidateUpdate() {
+	var (
+		ctx *unitValidatingWebhookContext
+	)
+
+	type updateArgs struct {
+		updateSource bool
+		updateFormat bool
+		updateLabel  bool
+	}
+
+	validateUpdate := func(args updateArgs, expectedAllowed bool, expectedReason string, expectedErr error) {
+		var err error
+
+		if args.updateSource {
+			ctx.exportReq.Spec.Source.Name = "other-vm"
+		}
+		if args.updateFormat {
+			ctx.exportReq.Spec.Format = vmopv1.VirtualMachineExportFormatOVA
+		}
+		if args.updateLabel {
+			ctx.exportReq.Labels = map[string]string{"foo": "bar"}
+		}
+
+		ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured(ctx.exportReq)
+		Expect(err).ToNot(HaveOccurred())
+
+		response := ctx.ValidateUpdate(&ctx.WebhookRequestContext)
+		Expect(response.Allowed).To(Equal(expectedAllowed))
+		if expectedReason != "" {
+			Expect(string(response.Result.Reason)).To(ContainSubstring(expectedReason))
+		}
+		if expectedErr != nil {
+			Expect(response.Result.Message).To(Equal(expectedErr.Error()))
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = newUnitTestContextForValidatingWebhook(true)
+	})
+	AfterEach(func() {
+		ctx = nil
+	})
+
+	DescribeTable("update table", validateUpdate,
+		Entry("should allow", updateArgs{}, true, nil, nil),
+		Entry("should allow label change", updateArgs{updateLabel: true}, true, nil, nil),
+		Entry("should deny source change", updateArgs{updateSource: true}, false, "spec: Invalid value", nil),
+		Entry("should deny format change", updateArgs{updateFormat: true}, false, "spec: Invalid value", nil),
+	)
+}
+
+func unitTestsValidateDelete() {
+	var (
+		ctx      *unitValidatingWebhookContext
+		response admission.Response
+	)
+
+	BeforeEach(func() {
+		ctx = newUnitTestContextForValidatingWebhook(false)
+	})
+	AfterEach(func() {
+		ctx = nil
+	})
+
+	When("the delete is performed", func() {
+		JustBeforeEach(func() {
+			response = ctx.ValidateDelete(&ctx.WebhookRequestContext)
+		})
+
+		It("should allow the request", func() {
+			Expect(response.Allowed).To(BeTrue())
+			Expect(response.Result).ToNot(BeNil())
+		})
+	})
+}
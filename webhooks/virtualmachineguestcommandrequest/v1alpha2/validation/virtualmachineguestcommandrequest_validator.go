@@ -0,0 +1,175 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package validation
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmgr "sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	"github.com/vmware-tanzu/vm-operator/pkg/builder"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/webhooks/common"
+)
+
+const (
+	webHookName = "default"
+
+	oneFileSourceRequired = "exactly one of configMap or secret must be set"
+	invalidGuestPath      = "must be an absolute path"
+)
+
+// windowsAbsPathRegex matches an absolute path in a Windows guest.
+var windowsAbsPathRegex = regexp.MustCompile(`^[a-zA-Z]:[\\/]`)
+
+// +kubebuilder:webhook:verbs=create;update,path=/default-validate-vmoperator-vmware-com-v1alpha2-virtualmachineguestcommandrequest,mutating=false,failurePolicy=fail,groups=vmoperator.vmware.com,resources=virtualmachineguestcommandrequests,versions=v1alpha2,name=default.validating.virtualmachineguestcommandrequest.v1alpha2.vmoperator.vmware.com,sideEffects=None,admissionReviewVersions=v1;v1beta1
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachineguestcommandrequests,verbs=get;list
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachineguestcommandrequests/status,verbs=get
+
+// AddToManager adds the webhook to the provided manager.
+func AddToManager(ctx *context.ControllerManagerContext, mgr ctrlmgr.Manager) error {
+	hook, err := builder.NewValidatingWebhook(ctx, mgr, webHookName, NewValidator(mgr.GetClient()))
+	if err != nil {
+		return errors.Wrapf(err, "failed to create virtualmachineguestcommandrequest validation webhook")
+	}
+	mgr.GetWebhookServer().Register(hook.Path, hook)
+	return nil
+}
+
+// NewValidator returns the package's Validator.
+func NewValidator(_ client.Client) builder.Validator {
+	return validator{
+		converter: runtime.DefaultUnstructuredConverter,
+	}
+}
+
+type validator struct {
+	converter runtime.UnstructuredConverter
+}
+
+func (v validator) For() schema.GroupVersionKind {
+	return vmopv1.SchemeGroupVersion.WithKind(reflect.TypeOf(vmopv1.VirtualMachineGuestCommandRequest{}).Name())
+}
+
+func (v validator) ValidateCreate(ctx *context.WebhookRequestContext) admission.Response {
+	cmdReq, err := v.commandRequestFromUnstructured(ctx.Obj)
+	if err != nil {
+		return webhook.Errored(http.StatusBadRequest, err)
+	}
+
+	var fieldErrs field.ErrorList
+	fieldErrs = append(fieldErrs, v.validateSpec(cmdReq)...)
+	fieldErrs = append(fieldErrs, v.validateFiles(cmdReq)...)
+
+	validationErrs := make([]string, 0, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		validationErrs = append(validationErrs, fieldErr.Error())
+	}
+
+	return common.BuildValidationResponse(ctx, nil, validationErrs, nil)
+}
+
+func (v validator) ValidateDelete(*context.WebhookRequestContext) admission.Response {
+	return admission.Allowed("")
+}
+
+// ValidateUpdate validates the spec is not changed since a request is only
+// processed once.
+func (v validator) ValidateUpdate(ctx *context.WebhookRequestContext) admission.Response {
+	cmdReq, err := v.commandRequestFromUnstructured(ctx.Obj)
+	if err != nil {
+		return webhook.Errored(http.StatusBadRequest, err)
+	}
+
+	oldCmdReq, err := v.commandRequestFromUnstructured(ctx.OldObj)
+	if err != nil {
+		return webhook.Errored(http.StatusBadRequest, err)
+	}
+
+	var fieldErrs field.ErrorList
+	fieldErrs = append(fieldErrs, validation.ValidateImmutableField(cmdReq.Spec, oldCmdReq.Spec, field.NewPath("spec"))...)
+
+	validationErrs := make([]string, 0, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		validationErrs = append(validationErrs, fieldErr.Error())
+	}
+	return common.BuildValidationResponse(ctx, nil, validationErrs, nil)
+}
+
+func (v validator) validateSpec(cmdReq *vmopv1.VirtualMachineGuestCommandRequest) field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if cmdReq.Spec.VirtualMachineName == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("virtualMachineName"), ""))
+	}
+	if cmdReq.Spec.Command == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("command"), ""))
+	}
+	if cmdReq.Spec.CredentialsSecretName == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("credentialsSecretName"), ""))
+	}
+
+	return allErrs
+}
+
+func (v validator) validateFiles(cmdReq *vmopv1.VirtualMachineGuestCommandRequest) field.ErrorList {
+	var allErrs field.ErrorList
+	filesPath := field.NewPath("spec", "files")
+
+	for i, file := range cmdReq.Spec.Files {
+		filePath := filesPath.Index(i)
+
+		if file.Path == "" {
+			allErrs = append(allErrs, field.Required(filePath.Child("path"), ""))
+		} else if !isAbsGuestPath(file.Path) {
+			allErrs = append(allErrs, field.Invalid(filePath.Child("path"), file.Path, invalidGuestPath))
+		}
+
+		if (file.ConfigMap == nil) == (file.Secret == nil) {
+			allErrs = append(allErrs, field.Invalid(filePath, file.Path, oneFileSourceRequired))
+			continue
+		}
+
+		selector, selectorPath := file.ConfigMap, filePath.Child("configMap")
+		if file.Secret != nil {
+			selector, selectorPath = file.Secret, filePath.Child("secret")
+		}
+		if selector.Name == "" {
+			allErrs = append(allErrs, field.Required(selectorPath.Child("name"), ""))
+		}
+		if selector.Key == "" {
+			allErrs = append(allErrs, field.Required(selectorPath.Child("key"), ""))
+		}
+	}
+
+	return allErrs
+}
+
+// isAbsGuestPath returns true when the path is absolute in either a Linux or
+// a Windows guest.
+func isAbsGuestPath(p string) bool {
+	return strings.HasPrefix(p, "/") || windowsAbsPathRegex.MatchString(p)
+}
+
+// commandRequestFromUnstructured returns the VirtualMachineGuestCommandRequest from the unstructured object.
+func (v validator) commandRequestFromUnstructured(obj runtime.Unstructured) (*vmopv1.VirtualMachineGuestCommandRequest, error) {
+	cmdReq := &vmopv1.VirtualMachineGuestCommandRequest{}
+	if err := v.converter.FromUnstructured(obj.UnstructuredContent(), cmdReq); err != nil {
+		return nil, err
+	}
+	return cmdReq, nil
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package validation_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func intgTests() {
+	Describe("Invoking Create", intgTestsValidateCreate)
+	Describe("Invoking Update", intgTestsValidateUpdate)
+	Describe("Invoking Delete", intgTestsValidateDelete)
+}
+
+type intgValidatingWebhookContext struct {
+	builder.IntegrationTestContext
+	cmdReq *vmopv1.VirtualMachineGuestCommandRequest
+}
+
+func newIntgValidatingWebhookContext() *intgValidatingWebhookContext {
+	ctx := &intgValidatingWebhookContext{
+		IntegrationTestContext: *suite.NewIntegrationTestContext(),
+	}
+
+	ctx.cmdReq = builder.DummyVirtualMachineGuestCommandRequest(ctx.Namespace, "some-name", "my-vm")
+	return ctx
+}
+
+func intgTestsValidateCreate() {
+	var (
+		err error
+		ctx *intgValidatingWebhookContext
+	)
+	BeforeEach(func() {
+		ctx = newIntgValidatingWebhookContext()
+	})
+	AfterEach(func() {
+		err = nil
+		ctx = nil
+	})
+
+	When("create is performed", func() {
+		BeforeEach(func() {
+			err = ctx.Client.Create(ctx, ctx.cmdReq)
+		})
+		It("should allow the request", func() {
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	When("create is performed without a command", func() {
+		BeforeEach(func() {
+			ctx.cmdReq.Spec.Command = ""
+			err = ctx.Client.Create(ctx, ctx.cmdReq)
+		})
+		It("should deny the request", func() {
+			Expect(err).To(HaveOccurred())
+		})
+	})
+}
+
+func intgTestsValidateUpdate() {
+	var (
+		err error
+		ctx *intgValidatingWebhookContext
+	)
+
+	BeforeEach(func() {
+		ctx = newIntgValidatingWebhookContext()
+		err = ctx.Client.Create(ctx, ctx.cmdReq)
+		Expect(err).ToNot(HaveOccurred())
+	})
+	JustBeforeEach(func() {
+		err = ctx.Client.Update(suite, ctx.cmdReq)
+	})
+	AfterEach(func() {
+		err = nil
+		ctx = nil
+	})
+
+	When("update is performed with a changed VM name", func() {
+		BeforeEach(func() {
+			ctx.cmdReq.Spec.VirtualMachineName = "other-vm"
+		})
+		It("should deny the request", func() {
+			Expect(err).To(HaveOccurred())
+		})
+	})
+}
+
+func intgTestsValidateDelete() {
+	var (
+		err error
+		ctx *intgValidatingWebhookContext
+	)
+
+	BeforeEach(func() {
+		ctx = newIntgValidatingWebhookContext()
+		err = ctx.Client.Create(ctx, ctx.cmdReq)
+		Expect(err).ToNot(HaveOccurred())
+	})
+	JustBeforeEach(func() {
+		err = ctx.Client.Delete(suite, ctx.cmdReq)
+	})
+	AfterEach(func() {
+		err = nil
+		ctx = nil
+	})
+
+	When("delete is performed", func() {
+		It("should allow the request", func() {
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+}
@@ -0,0 +1,220 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package validation_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func unitTests() {
+	Describe("Invoking ValidateCreate", unitTestsValidateCreate)
+	Describe("Invoking ValidateUpdate", unitTestsValidateUpdate)
+	Describe("Invoking ValidateDelete", unitTestsValidateDelete)
+}
+
+type unitValidatingWebhookContext struct {
+	builder.UnitTestContextForValidatingWebhook
+	cmdReq    *vmopv1.VirtualMachineGuestCommandRequest
+	oldCmdReq *vmopv1.VirtualMachineGuestCommandRequest
+}
+
+func newUnitTestContextForValidatingWebhook(isUpdate bool) *unitValidatingWebhookContext {
+	cmdReq := builder.DummyVirtualMachineGuestCommandRequest("some-namespace", "some-name", "my-vm")
+	obj, err := builder.ToUnstructured(cmdReq)
+	Expect(err).ToNot(HaveOccurred())
+
+	var oldCmdReq *vmopv1.VirtualMachineGuestCommandRequest
+	var oldObj *unstructured.Unstructured
+
+	if isUpdate {
+		oldCmdReq = cmdReq.DeepCopy()
+		oldObj, err = builder.ToUnstructured(oldCmdReq)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	return &unitValidatingWebhookContext{
+		UnitTestContextForValidatingWebhook: *suite.NewUnitTestContextForValidatingWebhook(obj, oldObj),
+		cmdReq:                              cmdReq,
+		oldCmdReq:                           oldCmdReq,
+	}
+}
+
+func unitTestsValidateCreate() {
+	var (
+		ctx *unitValidatingWebhookContext
+	)
+
+	type createArgs struct {
+		noVMName          bool
+		noCommand         bool
+		noCredentialsName bool
+		configMapFile     bool
+		secretFile        bool
+		windowsPath       bool
+		relativePath      bool
+		noFileSource      bool
+		bothFileSources   bool
+		noFileKey         bool
+	}
+
+	validateCreate := func(args createArgs, expectedAllowed bool, expectedReason string, expectedErr error) {
+		var err error
+
+		file := vmopv1.VirtualMachineGuestCommandFile{
+			Path:      "/tmp/script.sh",
+			ConfigMap: &vmopv1.VirtualMachineGuestCommandFileKeySelector{Name: "my-cm", Key: "script.sh"},
+		}
+
+		if args.noVMName {
+			ctx.cmdReq.Spec.VirtualMachineName = ""
+		}
+		if args.noCommand {
+			ctx.cmdReq.Spec.Command = ""
+		}
+		if args.noCredentialsName {
+			ctx.cmdReq.Spec.CredentialsSecretName = ""
+		}
+		if args.secretFile {
+			file.ConfigMap = nil
+			file.Secret = &vmopv1.VirtualMachineGuestCommandFileKeySelector{Name: "my-secret", Key: "key.pem"}
+		}
+		if args.windowsPath {
+			file.Path = `C:\Temp\script.ps1`
+		}
+		if args.relativePath {
+			file.Path = "tmp/script.sh"
+		}
+		if args.noFileSource {
+			file.ConfigMap = nil
+		}
+		if args.bothFileSources {
+			file.Secret = &vmopv1.VirtualMachineGuestCommandFileKeySelector{Name: "my-secret", Key: "key.pem"}
+		}
+		if args.noFileKey {
+			file.ConfigMap.Key = ""
+		}
+		if args.configMapFile || args.secretFile || args.windowsPath || args.relativePath ||
+			args.noFileSource || args.bothFileSources || args.noFileKey {
+			ctx.cmdReq.Spec.Files = []vmopv1.VirtualMachineGuestCommandFile{file}
+		}
+
+		ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured(ctx.cmdReq)
+		Expect(err).ToNot(HaveOccurred())
+
+		response := ctx.ValidateCreate(&ctx.WebhookRequestContext)
+		Expect(response.Allowed).To(Equal(expectedAllowed))
+		if expectedReason != "" {
+			Expect(string(response.Result.Reason)).To(ContainSubstring(expectedReason))
+		}
+		if expectedErr != nil {
+			Expect(response.Result.Message).To(Equal(expectedErr.Error()))
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = newUnitTestContextForValidatingWebhook(false)
+	})
+	AfterEach(func() {
+		ctx = nil
+	})
+
+	DescribeTable("create table", validateCreate,
+		Entry("should allow valid request", createArgs{}, true, nil, nil),
+		Entry("should allow file from ConfigMap", createArgs{configMapFile: true}, true, nil, nil),
+		Entry("should allow file from Secret", createArgs{secretFile: true}, true, nil, nil),
+		Entry("should allow Windows file path", createArgs{windowsPath: true}, true, nil, nil),
+		Entry("should deny no VM name", createArgs{noVMName: true}, false, "spec.virtualMachineName: Required value", nil),
+		Entry("should deny no command", createArgs{noCommand: true}, false, "spec.command: Required value", nil),
+		Entry("should deny no credentials secret name", createArgs{noCredentialsName: true}, false, "spec.credentialsSecretName: Required value", nil),
+		Entry("should deny relative file path", createArgs{relativePath: true}, false, `spec.files[0].path: Invalid value: "tmp/script.sh"`, nil),
+		Entry("should deny no file source", createArgs{noFileSource: true}, false, "exactly one of configMap or secret must be set", nil),
+		Entry("should deny both file sources", createArgs{bothFileSources: true}, false, "exactly one of configMap or secret must be set", nil),
+		Entry("should deny no file key", createArgs{noFileKey: true}, false, "spec.files[0].configMap.key: Required value", nil),
+	)
+}
+
+func unitTestsValidateUpdate() {
+	var (
+		ctx *unitValidatingWebhookContext
+	)
+
+	type updateArgs struct {
+		updateCommand bool
+		updateArgs    bool
+		updateLabel   bool
+	}
+
+	validateUpdate := func(args updateArgs, expectedAllowed bool, expectedReason string, expectedErr error) {
+		var err error
+
+		if args.updateCommand {
+			ctx.cmdReq.Spec.Command = "/bin/true"
+		}
+		if args.updateArgs {
+			ctx.cmdReq.Spec.Args = []string{"world"}
+		}
+		if args.updateLabel {
+			ctx.cmdReq.Labels = map[string]string{"foo": "bar"}
+		}
+
+		ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured(ctx.cmdReq)
+		Expect(err).ToNot(HaveOccurred())
+
+		response := ctx.ValidateUpdate(&ctx.WebhookRequestContext)
+		Expect(response.Allowed).To(Equal(expectedAllowed))
+		if expectedReason != "" {
+			Expect(string(response.Result.Reason)).To(ContainSubstring(expectedReason))
+		}
+		if expectedErr != nil {
+			Expect(response.Result.Message).To(Equal(expectedErr.Error()))
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = newUnitTestContextForValidatingWebhook(true)
+	})
+	AfterEach(func() {
+		ctx = nil
+	})
+
+	DescribeTable("update table", validateUpdate,
+		Entry("should allow", updateArgs{}, true, nil, nil),
+		Entry("should allow label change", updateArgs{updateLabel: true}, true, nil, nil),
+		Entry("should deny command change", updateArgs{updateCommand: true}, false, "spec: Invalid value", nil),
+		Entry("should deny args change", updateArgs{updateArgs: true}, false, "spec: Invalid value", nil),
+	)
+}
+
+func unitTestsValidateDelete() {
+	var (
+		ctx      *unitValidatingWebhookContext
+		response admission.Response
+	)
+
+	BeforeEach(func() {
+		ctx = newUnitTestContextForValidatingWebhook(false)
+	})
+	AfterEach(func() {
+		ctx = nil
+	})
+
+	When("the delete is performed", func() {
+		JustBeforeEach(func() {
+			response = ctx.ValidateDelete(&ctx.WebhookRequestContext)
+		})
+
+		It("should allow the request", func() {
+			Expect(response.Allowed).To(BeTrue())
+			Expect(response.Result).ToNot(BeNil())
+		})
+	})
+}
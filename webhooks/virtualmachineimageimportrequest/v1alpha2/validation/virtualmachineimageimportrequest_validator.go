@@ -0,0 +1,192 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package validation
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmgr "sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	imgregv1a1 "github.com/vmware-tanzu/image-registry-operator-api/api/v1alpha1"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	"github.com/vmware-tanzu/vm-operator/pkg/builder"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/webhooks/common"
+)
+
+const (
+	webHookName = "default"
+
+	invalidURL      = "must be an http or https URL"
+	invalidFileType = "must be the URL of an .ova or .ovf file"
+	invalidChecksum = "must be a %d character hex string for %s"
+)
+
+// checksumLengths are the lengths, in hex characters, of the supported
+// checksum algorithms.
+var checksumLengths = map[vmopv1.VirtualMachineImageImportChecksumAlgorithm]int{
+	vmopv1.VirtualMachineImageImportChecksumAlgorithmSHA256: 64,
+	vmopv1.VirtualMachineImageImportChecksumAlgorithmSHA512: 128,
+	vmopv1.VirtualMachineImageImportChecksumAlgorithmSHA1:   40,
+	vmopv1.VirtualMachineImageImportChecksumAlgorithmMD5:    32,
+}
+
+// +kubebuilder:webhook:verbs=create;update,path=/default-validate-vmoperator-vmware-com-v1alpha2-virtualmachineimageimportrequest,mutating=false,failurePolicy=fail,groups=vmoperator.vmware.com,resources=virtualmachineimageimportrequests,versions=v1alpha2,name=default.validating.virtualmachineimageimportrequest.v1alpha2.vmoperator.vmware.com,sideEffects=None,admissionReviewVersions=v1;v1beta1
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachineimageimportrequests,verbs=get;list
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachineimageimportrequests/status,verbs=get
+
+// AddToManager adds the webhook to the provided manager.
+func AddToManager(ctx *context.ControllerManagerContext, mgr ctrlmgr.Manager) error {
+	hook, err := builder.NewValidatingWebhook(ctx, mgr, webHookName, NewValidator(mgr.GetClient()))
+	if err != nil {
+		return errors.Wrapf(err, "failed to create virtualmachineimageimportrequest validation webhook")
+	}
+	mgr.GetWebhookServer().Register(hook.Path, hook)
+	return nil
+}
+
+// NewValidator returns the package's Validator.
+func NewValidator(_ client.Client) builder.Validator {
+	return validator{
+		converter: runtime.DefaultUnstructuredConverter,
+	}
+}
+
+type validator struct {
+	converter runtime.UnstructuredConverter
+}
+
+func (v validator) For() schema.GroupVersionKind {
+	return vmopv1.SchemeGroupVersion.WithKind(reflect.TypeOf(vmopv1.VirtualMachineImageImportRequest{}).Name())
+}
+
+func (v validator) ValidateCreate(ctx *context.WebhookRequestContext) admission.Response {
+	importReq, err := v.importRequestFromUnstructured(ctx.Obj)
+	if err != nil {
+		return webhook.Errored(http.StatusBadRequest, err)
+	}
+
+	var fieldErrs field.ErrorList
+	fieldErrs = append(fieldErrs, v.validateSource(importReq)...)
+	fieldErrs = append(fieldErrs, v.validateTargetLocation(importReq)...)
+
+	validationErrs := make([]string, 0, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		validationErrs = append(validationErrs, fieldErr.Error())
+	}
+
+	return common.BuildValidationResponse(ctx, nil, validationErrs, nil)
+}
+
+func (v validator) ValidateDelete(*context.WebhookRequestContext) admission.Response {
+	return admission.Allowed("")
+}
+
+// ValidateUpdate validates the spec is not changed since a request is only
+// processed once.
+func (v validator) ValidateUpdate(ctx *context.WebhookRequestContext) admission.Response {
+	importReq, err := v.importRequestFromUnstructured(ctx.Obj)
+	if err != nil {
+		return webhook.Errored(http.StatusBadRequest, err)
+	}
+
+	oldImportReq, err := v.importRequestFromUnstructured(ctx.OldObj)
+	if err != nil {
+		return webhook.Errored(http.StatusBadRequest, err)
+	}
+
+	var fieldErrs field.ErrorList
+	fieldErrs = append(fieldErrs, validation.ValidateImmutableField(importReq.Spec, oldImportReq.Spec, field.NewPath("spec"))...)
+
+	validationErrs := make([]string, 0, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		validationErrs = append(validationErrs, fieldErr.Error())
+	}
+	return common.BuildValidationResponse(ctx, nil, validationErrs, nil)
+}
+
+func (v validator) validateSource(importReq *vmopv1.VirtualMachineImageImportRequest) field.ErrorList {
+	var allErrs field.ErrorList
+	sourcePath := field.NewPath("spec", "source")
+	source := importReq.Spec.Source
+
+	if source.URL == "" {
+		allErrs = append(allErrs, field.Required(sourcePath.Child("url"), ""))
+	} else if u, err := url.Parse(source.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		allErrs = append(allErrs, field.Invalid(sourcePath.Child("url"), source.URL, invalidURL))
+	} else if ext := strings.ToLower(path.Ext(u.Path)); ext != ".ova" && ext != ".ovf" {
+		allErrs = append(allErrs, field.Invalid(sourcePath.Child("url"), source.URL, invalidFileType))
+	}
+
+	checksumPath := sourcePath.Child("checksum")
+	algorithm := source.Checksum.Algorithm
+	if algorithm == "" {
+		algorithm = vmopv1.VirtualMachineImageImportChecksumAlgorithmSHA256
+	}
+	length, ok := checksumLengths[algorithm]
+	if !ok {
+		allErrs = append(allErrs, field.NotSupported(checksumPath.Child("algorithm"), algorithm, []string{
+			string(vmopv1.VirtualMachineImageImportChecksumAlgorithmSHA256),
+			string(vmopv1.VirtualMachineImageImportChecksumAlgorithmSHA512),
+			string(vmopv1.VirtualMachineImageImportChecksumAlgorithmSHA1),
+			string(vmopv1.VirtualMachineImageImportChecksumAlgorithmMD5),
+		}))
+		return allErrs
+	}
+
+	if source.Checksum.Value == "" {
+		allErrs = append(allErrs, field.Required(checksumPath.Child("value"), ""))
+	} else if _, err := hex.DecodeString(source.Checksum.Value); err != nil || len(source.Checksum.Value) != length {
+		allErrs = append(allErrs, field.Invalid(checksumPath.Child("value"), source.Checksum.Value,
+			fmt.Sprintf(invalidChecksum, length, algorithm)))
+	}
+
+	return allErrs
+}
+
+func (v validator) validateTargetLocation(importReq *vmopv1.VirtualMachineImageImportRequest) field.ErrorList {
+	var allErrs field.ErrorList
+	locationPath := field.NewPath("spec", "target", "location")
+	location := importReq.Spec.Target.Location
+
+	if location.Name == "" {
+		allErrs = append(allErrs, field.Required(locationPath.Child("name"), ""))
+	}
+
+	if location.APIVersion != "" && location.APIVersion != imgregv1a1.GroupVersion.String() {
+		allErrs = append(allErrs, field.NotSupported(locationPath.Child("apiVersion"),
+			location.APIVersion, []string{imgregv1a1.GroupVersion.String(), ""}))
+	}
+
+	if location.Kind != "" && location.Kind != reflect.TypeOf(imgregv1a1.ContentLibrary{}).Name() {
+		allErrs = append(allErrs, field.NotSupported(locationPath.Child("kind"),
+			location.Kind, []string{reflect.TypeOf(imgregv1a1.ContentLibrary{}).Name(), ""}))
+	}
+
+	return allErrs
+}
+
+// importRequestFromUnstructured returns the VirtualMachineImageImportRequest from the unstructured object.
+func (v validator) importRequestFromUnstructured(obj runtime.Unstructured) (*vmopv1.VirtualMachineImageImportRequest, error) {
+	importReq := &vmopv1.VirtualMachineImageImportRequest{}
+	if err := v.converter.FromUnstructured(obj.UnstructuredContent(), importReq); err != nil {
+		return nil, err
+	}
+	return importReq, nil
+}
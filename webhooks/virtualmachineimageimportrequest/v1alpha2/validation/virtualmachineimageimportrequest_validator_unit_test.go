@@ -0,0 +1,232 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package validation_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func unitTests() {
+	Describe("Invoking ValidateCreate", unitTestsValidateCreate)
+	Describe("Invoking ValidateUpdate", unitTestsValidateUpdate)
+	Describe("Invoking ValidateDelete", unitTestsValidateDelete)
+}
+
+type unitValidatingWebhookContext struct {
+	builder.UnitTestContextForValidatingWebhook
+	importReq    *vmopv1.VirtualMachineImageImportRequest
+	oldImportReq *vmopv1.VirtualMachineImageImportRequest
+}
+
+func newUnitTestContextForValidatingWebhook(isUpdate bool) *unitValidatingWebhookContext {
+	importReq := builder.DummyVirtualMachineImageImportRequest("some-namespace", "some-name", "my-cl")
+	obj, err := builder.ToUnstructured(importReq)
+	Expect(err).ToNot(HaveOccurred())
+
+	var oldImportReq *vmopv1.VirtualMachineImageImportRequest
+	var oldObj *unstructured.Unstructured
+
+	if isUpdate {
+		oldImportReq = importReq.DeepCopy()
+		oldObj, err = builder.ToUnstructured(oldImportReq)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	return &unitValidatingWebhookContext{
+		UnitTestContextForValidatingWebhook: *suite.NewUnitTestContextForValidatingWebhook(obj, oldObj),
+		importReq:                           importReq,
+		oldImportReq:                        oldImportReq,
+	}
+}
+
+func unitTestsValidateCreate() {
+	var (
+		ctx *unitValidatingWebhookContext
+	)
+
+	type createArgs struct {
+		noURL            bool
+		invalidURL       bool
+		invalidFileType  bool
+		ovfURL           bool
+		noAlgorithm      bool
+		md5Algorithm     bool
+		invalidAlgorithm bool
+		noChecksum       bool
+		shortChecksum    bool
+		nonHexChecksum   bool
+		noLocationName   bool
+		invalidKind      bool
+		invalidVersion   bool
+	}
+
+	validateCreate := func(args createArgs, expectedAllowed bool, expectedReason string, expectedErr error) {
+		var err error
+
+		source := &ctx.importReq.Spec.Source
+		location := &ctx.importReq.Spec.Target.Location
+
+		if args.noURL {
+			source.URL = ""
+		}
+		if args.invalidURL {
+			source.URL = "ftp://images.example.com/my-image.ova"
+		}
+		if args.invalidFileType {
+			source.URL = "https://images.example.com/my-image.iso"
+		}
+		if args.ovfURL {
+			source.URL = "https://images.example.com/my-image/my-image.OVF"
+		}
+		if args.noAlgorithm {
+			source.Checksum.Algorithm = ""
+		}
+		if args.md5Algorithm {
+			source.Checksum.Algorithm = vmopv1.VirtualMachineImageImportChecksumAlgorithmMD5
+			source.Checksum.Value = "d41d8cd98f00b204e9800998ecf8427e"
+		}
+		if args.invalidAlgorithm {
+			source.Checksum.Algorithm = "CRC32"
+		}
+		if args.noChecksum {
+			source.Checksum.Value = ""
+		}
+		if args.shortChecksum {
+			source.Checksum.Value = "abcd"
+		}
+		if args.nonHexChecksum {
+			source.Checksum.Value = "z3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+		}
+		if args.noLocationName {
+			location.Name = ""
+		}
+		if args.invalidKind {
+			location.Kind = "ClusterContentLibrary"
+		}
+		if args.invalidVersion {
+			location.APIVersion = "imageregistry.vmware.com/v1"
+		}
+
+		ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured(ctx.importReq)
+		Expect(err).ToNot(HaveOccurred())
+
+		response := ctx.ValidateCreate(&ctx.WebhookRequestContext)
+		Expect(response.Allowed).To(Equal(expectedAllowed))
+		if expectedReason != "" {
+			Expect(string(response.Result.Reason)).To(ContainSubstring(expectedReason))
+		}
+		if expectedErr != nil {
+			Expect(response.Result.Message).To(Equal(expectedErr.Error()))
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = newUnitTestContextForValidatingWebhook(false)
+	})
+	AfterEach(func() {
+		ctx = nil
+	})
+
+	DescribeTable("create table", validateCreate,
+		Entry("should allow valid", createArgs{}, true, nil, nil),
+		Entry("should allow OVF URL", createArgs{ovfURL: true}, true, nil, nil),
+		Entry("should allow no algorithm", createArgs{noAlgorithm: true}, true, nil, nil),
+		Entry("should allow MD5 checksum", createArgs{md5Algorithm: true}, true, nil, nil),
+		Entry("should deny no URL", createArgs{noURL: true}, false, "spec.source.url: Required value", nil),
+		Entry("should deny invalid URL", createArgs{invalidURL: true}, false, "must be an http or https URL", nil),
+		Entry("should deny invalid file type", createArgs{invalidFileType: true}, false, "must be the URL of an .ova or .ovf file", nil),
+		Entry("should deny invalid algorithm", createArgs{invalidAlgorithm: true}, false, `spec.source.checksum.algorithm: Unsupported value: "CRC32"`, nil),
+		Entry("should deny no checksum", createArgs{noChecksum: true}, false, "spec.source.checksum.value: Required value", nil),
+		Entry("should deny short checksum", createArgs{shortChecksum: true}, false, "must be a 64 character hex string for SHA256", nil),
+		Entry("should deny non-hex checksum", createArgs{nonHexChecksum: true}, false, "must be a 64 character hex string for SHA256", nil),
+		Entry("should deny no location name", createArgs{noLocationName: true}, false, "spec.target.location.name: Required value", nil),
+		Entry("should deny invalid kind", createArgs{invalidKind: true}, false, `spec.target.location.kind: Unsupported value: "ClusterContentLibrary"`, nil),
+		Entry("should deny invalid API version", createArgs{invalidVersion: true}, false, `spec.target.location.apiVersion: Unsupported value: "imageregistry.vmware.com/v1"`, nil),
+	)
+}
+
+func unitTestsValidateUpdate() {
+	var (
+		ctx *unitValidatingWebhookContext
+	)
+
+	type updateArgs struct {
+		updateURL      bool
+		updateLocation bool
+		updateLabel    bool
+	}
+
+	validateUpdate := func(args updateArgs, expectedAllowed bool, expectedReason string, expectedErr error) {
+		var err error
+
+		if args.updateURL {
+			ctx.importReq.Spec.Source.URL = "https://images.example.com/other-image.ova"
+		}
+		if args.updateLocation {
+			ctx.importReq.Spec.Target.Location.Name = "other-cl"
+		}
+		if args.updateLabel {
+			ctx.importReq.Labels = map[string]string{"foo": "bar"}
+		}
+
+		ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured(ctx.importReq)
+		Expect(err).ToNot(HaveOccurred())
+
+		response := ctx.ValidateUpdate(&ctx.WebhookRequestContext)
+		Expect(response.Allowed).To(Equal(expectedAllowed))
+		if expectedReason != "" {
+			Expect(string(response.Result.Reason)).To(ContainSubstring(expectedReason))
+		}
+		if expectedErr != nil {
+			Expect(response.Result.Message).To(Equal(expectedErr.Error()))
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = newUnitTestContextForValidatingWebhook(true)
+	})
+	AfterEach(func() {
+		ctx = nil
+	})
+
+	DescribeTable("update table", validateUpdate,
+		Entry("should allow", updateArgs{}, true, nil, nil),
+		Entry("should allow label change", updateArgs{updateLabel: true}, true, nil, nil),
+		Entry("should deny URL change", updateArgs{updateURL: true}, false, "spec: Invalid value", nil),
+		Entry("should deny location change", updateArgs{updateLocation: true}, false, "spec: Invalid value", nil),
+	)
+}
+
+func unitTestsValidateDelete() {
+	var (
+		ctx      *unitValidatingWebhookContext
+		response admission.Response
+	)
+
+	BeforeEach(func() {
+		ctx = newUnitTestContextForValidatingWebhook(false)
+	})
+	AfterEach(func() {
+		ctx = nil
+	})
+
+	When("the delete is performed", func() {
+		JustBeforeEach(func() {
+			response = ctx.ValidateDelete(&ctx.WebhookRequestContext)
+		})
+
+		It("should allow the request", func() {
+			Expect(response.Allowed).To(BeTrue())
+			Expect(response.Result).ToNot(BeNil())
+		})
+	})
+}
@@ -0,0 +1,19 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package virtualmachineimageimportrequest
+
+import (
+	ctrlmgr "sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/lib"
+	"github.com/vmware-tanzu/vm-operator/webhooks/virtualmachineimageimportrequest/v1alpha2"
+)
+
+func AddToManager(ctx *context.ControllerManagerContext, mgr ctrlmgr.Manager) error {
+	if lib.IsVMServiceV1Alpha2FSSEnabled() && lib.IsWCPVMImageRegistryEnabled() {
+		return v1alpha2.AddToManager(ctx, mgr)
+	}
+	return nil
+}
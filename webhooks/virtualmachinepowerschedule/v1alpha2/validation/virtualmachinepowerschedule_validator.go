@@ -0,0 +1,182 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package validation
+
+import (
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/robfig/cron/v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmgr "sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	"github.com/vmware-tanzu/vm-operator/pkg/builder"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/webhooks/common"
+)
+
+const (
+	webHookName = "default"
+
+	emptySelector   = "empty selector is invalid for VirtualMachinePowerSchedule"
+	noSchedules     = "at least one of poweredOn, poweredOff, suspended or restart must be specified"
+	invalidTimeZone = "must be a time zone name from the IANA time zone database"
+)
+
+// +kubebuilder:webhook:verbs=create;update,path=/default-validate-vmoperator-vmware-com-v1alpha2-virtualmachinepowerschedule,mutating=false,failurePolicy=fail,groups=vmoperator.vmware.com,resources=virtualmachinepowerschedules,versions=v1alpha2,name=default.validating.virtualmachinepowerschedule.v1alpha2.vmoperator.vmware.com,sideEffects=None,admissionReviewVersions=v1;v1beta1
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachinepowerschedules,verbs=get;list
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachinepowerschedules/status,verbs=get
+
+// AddToManager adds the webhook to the provided manager.
+func AddToManager(ctx *context.ControllerManagerContext, mgr ctrlmgr.Manager) error {
+	hook, err := builder.NewValidatingWebhook(ctx, mgr, webHookName, NewValidator(mgr.GetClient()))
+	if err != nil {
+		return errors.Wrapf(err, "failed to create virtualmachinepowerschedule validation webhook")
+	}
+	mgr.GetWebhookServer().Register(hook.Path, hook)
+	return nil
+}
+
+// NewValidator returns the package's Validator.
+func NewValidator(_ client.Client) builder.Validator {
+	return validator{
+		converter: runtime.DefaultUnstructuredConverter,
+	}
+}
+
+type validator struct {
+	converter runtime.UnstructuredConverter
+}
+
+func (v validator) For() schema.GroupVersionKind {
+	return vmopv1.SchemeGroupVersion.WithKind(reflect.TypeOf(vmopv1.VirtualMachinePowerSchedule{}).Name())
+}
+
+func (v validator) ValidateCreate(ctx *context.WebhookRequestContext) admission.Response {
+	ps, err := v.powerScheduleFromUnstructured(ctx.Obj)
+	if err != nil {
+		return webhook.Errored(http.StatusBadRequest, err)
+	}
+
+	var fieldErrs field.ErrorList
+	fieldErrs = append(fieldErrs, v.validateSpec(ps)...)
+
+	validationErrs := make([]string, 0, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		validationErrs = append(validationErrs, fieldErr.Error())
+	}
+
+	return common.BuildValidationResponse(ctx, nil, validationErrs, nil)
+}
+
+func (v validator) ValidateDelete(*context.WebhookRequestContext) admission.Response {
+	return admission.Allowed("")
+}
+
+func (v validator) ValidateUpdate(ctx *context.WebhookRequestContext) admission.Response {
+	ps, err := v.powerScheduleFromUnstructured(ctx.Obj)
+	if err != nil {
+		return webhook.Errored(http.StatusBadRequest, err)
+	}
+
+	var fieldErrs field.ErrorList
+	fieldErrs = append(fieldErrs, v.validateSpec(ps)...)
+
+	validationErrs := make([]string, 0, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		validationErrs = append(validationErrs, fieldErr.Error())
+	}
+	return common.BuildValidationResponse(ctx, nil, validationErrs, nil)
+}
+
+func (v validator) validateSpec(ps *vmopv1.VirtualMachinePowerSchedule) field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	allErrs = append(allErrs, v.validateSelector(ps, specPath)...)
+	allErrs = append(allErrs, v.validateSchedules(ps, specPath)...)
+
+	if tz := ps.Spec.TimeZone; tz != "" {
+		if _, err := time.LoadLocation(tz); err != nil {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("timeZone"), tz, invalidTimeZone))
+		}
+	}
+
+	if d := ps.Spec.StartingDeadlineSeconds; d != nil && *d < 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("startingDeadlineSeconds"), *d, "must be greater than or equal to 0"))
+	}
+
+	return allErrs
+}
+
+// validateSelector validates the selector is not empty. Otherwise, the power
+// operations would be applied to every VM in the namespace.
+func (v validator) validateSelector(ps *vmopv1.VirtualMachinePowerSchedule, specPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	selectorPath := specPath.Child("selector")
+
+	if ps.Spec.Selector == nil {
+		return append(allErrs, field.Required(selectorPath, ""))
+	}
+
+	if len(ps.Spec.Selector.MatchLabels)+len(ps.Spec.Selector.MatchExpressions) == 0 {
+		return append(allErrs, field.Invalid(selectorPath, ps.Spec.Selector, emptySelector))
+	}
+
+	if _, err := metav1.LabelSelectorAsSelector(ps.Spec.Selector); err != nil {
+		allErrs = append(allErrs, field.Invalid(selectorPath, ps.Spec.Selector, err.Error()))
+	}
+
+	return allErrs
+}
+
+func (v validator) validateSchedules(ps *vmopv1.VirtualMachinePowerSchedule, specPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	schedules := []struct {
+		name string
+		spec string
+	}{
+		{"poweredOn", ps.Spec.PoweredOn},
+		{"poweredOff", ps.Spec.PoweredOff},
+		{"suspended", ps.Spec.Suspended},
+		{"restart", ps.Spec.Restart},
+	}
+
+	count := 0
+	for _, s := range schedules {
+		if s.spec == "" {
+			continue
+		}
+		count++
+
+		if _, err := cron.ParseStandard(s.spec); err != nil {
+			allErrs = append(allErrs, field.Invalid(specPath.Child(s.name), s.spec, err.Error()))
+		}
+	}
+
+	if count == 0 {
+		allErrs = append(allErrs, field.Required(specPath, noSchedules))
+	}
+
+	return allErrs
+}
+
+// powerScheduleFromUnstructured returns the VirtualMachinePowerSchedule from the unstructured object.
+func (v validator) powerScheduleFromUnstructured(obj runtime.Unstructured) (*vmopv1.VirtualMachinePowerSchedule, error) {
+	ps := &vmopv1.VirtualMachinePowerSchedule{}
+	if err := v.converter.FromUnstructured(obj.UnstructuredContent(), ps); err != nil {
+		return nil, err
+	}
+	return ps, nil
+}
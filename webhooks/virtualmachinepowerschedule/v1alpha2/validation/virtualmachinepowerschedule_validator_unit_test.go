@@ -0,0 +1,234 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package validation_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func unitTests() {
+	Describe("Invoking ValidateCreate", unitTestsValidateCreate)
+	Describe("Invoking ValidateUpdate", unitTestsValidateUpdate)
+	Describe("Invoking ValidateDelete", unitTestsValidateDelete)
+}
+
+type unitValidatingWebhookContext struct {
+	builder.UnitTestContextForValidatingWebhook
+	ps    *vmopv1.VirtualMachinePowerSchedule
+	oldPS *vmopv1.VirtualMachinePowerSchedule
+}
+
+func newUnitTestContextForValidatingWebhook(isUpdate bool) *unitValidatingWebhookContext {
+	ps := builder.DummyVirtualMachinePowerSchedule("some-namespace", "some-name")
+	obj, err := builder.ToUnstructured(ps)
+	Expect(err).ToNot(HaveOccurred())
+
+	var oldPS *vmopv1.VirtualMachinePowerSchedule
+	var oldObj *unstructured.Unstructured
+
+	if isUpdate {
+		oldPS = ps.DeepCopy()
+		oldObj, err = builder.ToUnstructured(oldPS)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	return &unitValidatingWebhookContext{
+		UnitTestContextForValidatingWebhook: *suite.NewUnitTestContextForValidatingWebhook(obj, oldObj),
+		ps:                                  ps,
+		oldPS:                               oldPS,
+	}
+}
+
+func unitTestsValidateCreate() {
+	var (
+		ctx *unitValidatingWebhookContext
+	)
+
+	type createArgs struct {
+		nilSelector        bool
+		emptySelector      bool
+		invalidSelector    bool
+		noSchedules        bool
+		invalidSchedule    bool
+		predefinedSchedule bool
+		validTimeZone      bool
+		invalidTimeZone    bool
+		startingDeadline   *int64
+	}
+
+	validateCreate := func(args createArgs, expectedAllowed bool, expectedReason string, expectedErr error) {
+		var err error
+
+		if args.nilSelector {
+			ctx.ps.Spec.Selector = nil
+		}
+		if args.emptySelector {
+			ctx.ps.Spec.Selector = &metav1.LabelSelector{}
+		}
+		if args.invalidSelector {
+			ctx.ps.Spec.Selector.MatchExpressions = []metav1.LabelSelectorRequirement{
+				{Key: "env", Operator: "bogus"},
+			}
+		}
+		if args.noSchedules {
+			ctx.ps.Spec.PoweredOn = ""
+			ctx.ps.Spec.PoweredOff = ""
+		}
+		if args.invalidSchedule {
+			ctx.ps.Spec.Suspended = "0 25 * * *"
+		}
+		if args.predefinedSchedule {
+			ctx.ps.Spec.Restart = "@weekly"
+		}
+		if args.validTimeZone {
+			ctx.ps.Spec.TimeZone = "Europe/Paris"
+		}
+		if args.invalidTimeZone {
+			ctx.ps.Spec.TimeZone = "Mars/Olympus_Mons"
+		}
+		ctx.ps.Spec.StartingDeadlineSeconds = args.startingDeadline
+
+		ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured(ctx.ps)
+		Expect(err).ToNot(HaveOccurred())
+
+		response := ctx.ValidateCreate(&ctx.WebhookRequestContext)
+		Expect(response.Allowed).To(Equal(expectedAllowed))
+		if expectedReason != "" {
+			Expect(string(response.Result.Reason)).To(ContainSubstring(expectedReason))
+		}
+		if expectedErr != nil {
+			Expect(response.Result.Message).To(Equal(expectedErr.Error()))
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = newUnitTestContextForValidatingWebhook(false)
+	})
+	AfterEach(func() {
+		ctx = nil
+	})
+
+	DescribeTable("create table", validateCreate,
+		Entry("should allow valid", createArgs{}, true, nil, nil),
+		Entry("should allow predefined schedule", createArgs{predefinedSchedule: true}, true, nil, nil),
+		Entry("should allow valid time zone", createArgs{validTimeZone: true}, true, nil, nil),
+		Entry("should deny nil selector", createArgs{nilSelector: true}, false, "spec.selector: Required value", nil),
+		Entry("should deny empty selector", createArgs{emptySelector: true}, false, "empty selector is invalid", nil),
+		Entry("should deny invalid selector", createArgs{invalidSelector: true}, false, "spec.selector: Invalid value", nil),
+		Entry("should deny no schedules", createArgs{noSchedules: true}, false,
+			"spec: Required value: at least one of poweredOn, poweredOff, suspended or restart must be specified", nil),
+		Entry("should deny invalid schedule", createArgs{invalidSchedule: true}, false, `spec.suspended: Invalid value: "0 25 * * *"`, nil),
+		Entry("should deny invalid time zone", createArgs{invalidTimeZone: true}, false,
+			`spec.timeZone: Invalid value: "Mars/Olympus_Mons": must be a time zone name from the IANA time zone database`, nil),
+		Entry("should allow starting deadline", createArgs{startingDeadline: pointer.Int64(60)}, true, nil, nil),
+		Entry("should deny negative starting deadline", createArgs{startingDeadline: pointer.Int64(-1)}, false,
+			"spec.startingDeadlineSeconds: Invalid value: -1: must be greater than or equal to 0", nil),
+	)
+}
+
+func unitTestsValidateUpdate() {
+	var (
+		ctx      *unitValidatingWebhookContext
+		response admission.Response
+	)
+
+	type updateArgs struct {
+		updateSchedule  bool
+		updateSelector  bool
+		suspend         bool
+		invalidSchedule bool
+	}
+
+	validateUpdate := func(args updateArgs, expectedAllowed bool, expectedReason string, expectedErr error) {
+		var err error
+
+		if args.updateSchedule {
+			ctx.ps.Spec.PoweredOff = "30 18 * * 1-5"
+		}
+		if args.updateSelector {
+			ctx.ps.Spec.Selector.MatchLabels["tier"] = "web"
+		}
+		if args.suspend {
+			ctx.ps.Spec.Suspend = true
+		}
+		if args.invalidSchedule {
+			ctx.ps.Spec.PoweredOn = "every morning"
+		}
+
+		ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured(ctx.ps)
+		Expect(err).ToNot(HaveOccurred())
+
+		response := ctx.ValidateUpdate(&ctx.WebhookRequestContext)
+		Expect(response.Allowed).To(Equal(expectedAllowed))
+		if expectedReason != "" {
+			Expect(string(response.Result.Reason)).To(ContainSubstring(expectedReason))
+		}
+		if expectedErr != nil {
+			Expect(response.Result.Message).To(Equal(expectedErr.Error()))
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = newUnitTestContextForValidatingWebhook(true)
+	})
+	AfterEach(func() {
+		ctx = nil
+	})
+
+	DescribeTable("update table", validateUpdate,
+		Entry("should allow", updateArgs{}, true, nil, nil),
+		Entry("should allow schedule change", updateArgs{updateSchedule: true}, true, nil, nil),
+		Entry("should allow selector change", updateArgs{updateSelector: true}, true, nil, nil),
+		Entry("should allow suspend", updateArgs{suspend: true}, true, nil, nil),
+		Entry("should deny invalid schedule", updateArgs{invalidSchedule: true}, false, `spec.poweredOn: Invalid value: "every morning"`, nil),
+	)
+
+	When("the update is performed while object deletion", func() {
+		JustBeforeEach(func() {
+			t := metav1.Now()
+			ctx.WebhookRequestContext.Obj.SetDeletionTimestamp(&t)
+			response = ctx.ValidateUpdate(&ctx.WebhookRequestContext)
+		})
+
+		It("should allow the request", func() {
+			Expect(response.Allowed).To(BeTrue())
+			Expect(response.Result).ToNot(BeNil())
+		})
+	})
+}
+
+func unitTestsValidateDelete() {
+	var (
+		ctx      *unitValidatingWebhookContext
+		response admission.Response
+	)
+
+	BeforeEach(func() {
+		ctx = newUnitTestContextForValidatingWebhook(false)
+	})
+	AfterEach(func() {
+		ctx = nil
+	})
+
+	When("the delete is performed", func() {
+		JustBeforeEach(func() {
+			response = ctx.ValidateDelete(&ctx.WebhookRequestContext)
+		})
+
+		It("should allow the request", func() {
+			Expect(response.Allowed).To(BeTrue())
+			Expect(response.Result).ToNot(BeNil())
+		})
+	})
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package validation
+
+import (
+	"net/http"
+	"reflect"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/validation"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmgr "sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	"github.com/vmware-tanzu/vm-operator/pkg/builder"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/webhooks/common"
+)
+
+const (
+	webHookName = "default"
+
+	selectorDoesNotMatchTemplate = "selector does not match template labels"
+	emptySelector                = "empty selector is invalid for VirtualMachineReplicaSet"
+)
+
+// +kubebuilder:webhook:verbs=create;update,path=/default-validate-vmoperator-vmware-com-v1alpha2-virtualmachinereplicaset,mutating=false,failurePolicy=fail,groups=vmoperator.vmware.com,resources=virtualmachinereplicasets,versions=v1alpha2,name=default.validating.virtualmachinereplicaset.v1alpha2.vmoperator.vmware.com,sideEffects=None,admissionReviewVersions=v1;v1beta1
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachinereplicasets,verbs=get;list
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachinereplicasets/status,verbs=get
+
+// AddToManager adds the webhook to the provided manager.
+func AddToManager(ctx *context.ControllerManagerContext, mgr ctrlmgr.Manager) error {
+	hook, err := builder.NewValidatingWebhook(ctx, mgr, webHookName, NewValidator(mgr.GetClient()))
+	if err != nil {
+		return errors.Wrapf(err, "failed to create virtualmachinereplicaset validation webhook")
+	}
+	mgr.GetWebhookServer().Register(hook.Path, hook)
+	return nil
+}
+
+// NewValidator returns the package's Validator.
+func NewValidator(_ client.Client) builder.Validator {
+	return validator{
+		converter: runtime.DefaultUnstructuredConverter,
+	}
+}
+
+type validator struct {
+	converter runtime.UnstructuredConverter
+}
+
+func (v validator) For() schema.GroupVersionKind {
+	return vmopv1.SchemeGroupVersion.WithKind(reflect.TypeOf(vmopv1.VirtualMachineReplicaSet{}).Name())
+}
+
+func (v validator) ValidateCreate(ctx *context.WebhookRequestContext) admission.Response {
+	rs, err := v.replicaSetFromUnstructured(ctx.Obj)
+	if err != nil {
+		return webhook.Errored(http.StatusBadRequest, err)
+	}
+
+	var fieldErrs field.ErrorList
+	fieldErrs = append(fieldErrs, v.validateSpec(rs)...)
+
+	validationErrs := make([]string, 0, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		validationErrs = append(validationErrs, fieldErr.Error())
+	}
+
+	return common.BuildValidationResponse(ctx, nil, validationErrs, nil)
+}
+
+func (v validator) ValidateDelete(*context.WebhookRequestContext) admission.Response {
+	return admission.Allowed("")
+}
+
+func (v validator) ValidateUpdate(ctx *context.WebhookRequestContext) admission.Response {
+	rs, err := v.replicaSetFromUnstructured(ctx.Obj)
+	if err != nil {
+		return webhook.Errored(http.StatusBadRequest, err)
+	}
+
+	oldRS, err := v.replicaSetFromUnstructured(ctx.OldObj)
+	if err != nil {
+		return webhook.Errored(http.StatusBadRequest, err)
+	}
+
+	var fieldErrs field.ErrorList
+	fieldErrs = append(fieldErrs, v.validateSpec(rs)...)
+	fieldErrs = append(fieldErrs, v.validateImmutableFields(rs, oldRS)...)
+
+	validationErrs := make([]string, 0, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		validationErrs = append(validationErrs, fieldErr.Error())
+	}
+	return common.BuildValidationResponse(ctx, nil, validationErrs, nil)
+}
+
+func (v validator) validateSpec(rs *vmopv1.VirtualMachineReplicaSet) field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	allErrs = append(allErrs, v.validateSelector(rs, specPath)...)
+
+	if rs.Spec.ClusterModuleGroup != "" && rs.Spec.ResourcePolicyName == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("resourcePolicyName"),
+			"resourcePolicyName is required when clusterModuleGroup is specified"))
+	}
+
+	return allErrs
+}
+
+// validateSelector validates the selector selects the VMs created from the
+// template. Otherwise, the replica set would create VMs indefinitely.
+func (v validator) validateSelector(rs *vmopv1.VirtualMachineReplicaSet, specPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	selectorPath := specPath.Child("selector")
+
+	if rs.Spec.Selector == nil {
+		return append(allErrs, field.Required(selectorPath, ""))
+	}
+
+	if len(rs.Spec.Selector.MatchLabels)+len(rs.Spec.Selector.MatchExpressions) == 0 {
+		return append(allErrs, field.Invalid(selectorPath, rs.Spec.Selector, emptySelector))
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(rs.Spec.Selector)
+	if err != nil {
+		return append(allErrs, field.Invalid(selectorPath, rs.Spec.Selector, err.Error()))
+	}
+
+	if !selector.Matches(labels.Set(rs.Spec.Template.Labels)) {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("template", "metadata", "labels"),
+			rs.Spec.Template.Labels, selectorDoesNotMatchTemplate))
+	}
+
+	return allErrs
+}
+
+// validateImmutableFields validates the fields that determine which VMs are
+// replicas. Changing these would orphan the existing replicas.
+func (v validator) validateImmutableFields(rs, oldRS *vmopv1.VirtualMachineReplicaSet) field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	allErrs = append(allErrs, validation.ValidateImmutableField(rs.Spec.Selector, oldRS.Spec.Selector, specPath.Child("selector"))...)
+
+	return allErrs
+}
+
+// replicaSetFromUnstructured returns the VirtualMachineReplicaSet from the unstructured object.
+func (v validator) replicaSetFromUnstructured(obj runtime.Unstructured) (*vmopv1.VirtualMachineReplicaSet, error) {
+	rs := &vmopv1.VirtualMachineReplicaSet{}
+	if err := v.converter.FromUnstructured(obj.UnstructuredContent(), rs); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
@@ -0,0 +1,222 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package validation_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func unitTests() {
+	Describe("Invoking ValidateCreate", unitTestsValidateCreate)
+	Describe("Invoking ValidateUpdate", unitTestsValidateUpdate)
+	Describe("Invoking ValidateDelete", unitTestsValidateDelete)
+}
+
+type unitValidatingWebhookContext struct {
+	builder.UnitTestContextForValidatingWebhook
+	rs    *vmopv1.VirtualMachineReplicaSet
+	oldRS *vmopv1.VirtualMachineReplicaSet
+}
+
+func newUnitTestContextForValidatingWebhook(isUpdate bool) *unitValidatingWebhookContext {
+	rs := builder.DummyVirtualMachineReplicaSet("some-namespace", "some-name")
+	obj, err := builder.ToUnstructured(rs)
+	Expect(err).ToNot(HaveOccurred())
+
+	var oldRS *vmopv1.VirtualMachineReplicaSet
+	var oldObj *unstructured.Unstructured
+
+	if isUpdate {
+		oldRS = rs.DeepCopy()
+		oldObj, err = builder.ToUnstructured(oldRS)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	return &unitValidatingWebhookContext{
+		UnitTestContextForValidatingWebhook: *suite.NewUnitTestContextForValidatingWebhook(obj, oldObj),
+		rs:                                  rs,
+		oldRS:                               oldRS,
+	}
+}
+
+func unitTestsValidateCreate() {
+	var (
+		ctx *unitValidatingWebhookContext
+	)
+
+	type createArgs struct {
+		nilSelector                 bool
+		emptySelector               bool
+		invalidSelector             bool
+		mismatchedTemplateLabels    bool
+		clusterModuleWithoutPolicy  bool
+		clusterModuleWithPolicy     bool
+		selectorWithMatchExpression bool
+	}
+
+	validateCreate := func(args createArgs, expectedAllowed bool, expectedReason string, expectedErr error) {
+		var err error
+
+		if args.nilSelector {
+			ctx.rs.Spec.Selector = nil
+		}
+		if args.emptySelector {
+			ctx.rs.Spec.Selector = &metav1.LabelSelector{}
+		}
+		if args.invalidSelector {
+			ctx.rs.Spec.Selector.MatchExpressions = []metav1.LabelSelectorRequirement{
+				{Key: "app", Operator: "bogus"},
+			}
+		}
+		if args.selectorWithMatchExpression {
+			ctx.rs.Spec.Selector.MatchExpressions = []metav1.LabelSelectorRequirement{
+				{Key: "app", Operator: metav1.LabelSelectorOpExists},
+			}
+		}
+		if args.mismatchedTemplateLabels {
+			ctx.rs.Spec.Template.Labels = map[string]string{"app": "other"}
+		}
+		if args.clusterModuleWithoutPolicy {
+			ctx.rs.Spec.ClusterModuleGroup = "some-group"
+		}
+		if args.clusterModuleWithPolicy {
+			ctx.rs.Spec.ResourcePolicyName = "some-policy"
+			ctx.rs.Spec.ClusterModuleGroup = "some-group"
+		}
+
+		ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured(ctx.rs)
+		Expect(err).ToNot(HaveOccurred())
+
+		response := ctx.ValidateCreate(&ctx.WebhookRequestContext)
+		Expect(response.Allowed).To(Equal(expectedAllowed))
+		if expectedReason != "" {
+			Expect(string(response.Result.Reason)).To(ContainSubstring(expectedReason))
+		}
+		if expectedErr != nil {
+			Expect(response.Result.Message).To(Equal(expectedErr.Error()))
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = newUnitTestContextForValidatingWebhook(false)
+	})
+	AfterEach(func() {
+		ctx = nil
+	})
+
+	DescribeTable("create table", validateCreate,
+		Entry("should allow valid", createArgs{}, true, nil, nil),
+		Entry("should allow selector with match expression", createArgs{selectorWithMatchExpression: true}, true, nil, nil),
+		Entry("should allow cluster module group with resource policy", createArgs{clusterModuleWithPolicy: true}, true, nil, nil),
+		Entry("should deny nil selector", createArgs{nilSelector: true}, false, "spec.selector: Required value", nil),
+		Entry("should deny empty selector", createArgs{emptySelector: true}, false, "empty selector is invalid", nil),
+		Entry("should deny invalid selector", createArgs{invalidSelector: true}, false, "spec.selector: Invalid value", nil),
+		Entry("should deny selector not matching template labels", createArgs{mismatchedTemplateLabels: true}, false,
+			"spec.template.metadata.labels: Invalid value: map[string]string{\"app\":\"other\"}: selector does not match template labels", nil),
+		Entry("should deny cluster module group without resource policy", createArgs{clusterModuleWithoutPolicy: true}, false,
+			"spec.resourcePolicyName: Required value", nil),
+	)
+}
+
+func unitTestsValidateUpdate() {
+	var (
+		ctx      *unitValidatingWebhookContext
+		response admission.Response
+	)
+
+	type updateArgs struct {
+		updateReplicas bool
+		updateTemplate bool
+		updateSelector bool
+	}
+
+	validateUpdate := func(args updateArgs, expectedAllowed bool, expectedReason string, expectedErr error) {
+		var err error
+
+		if args.updateReplicas {
+			ctx.rs.Spec.Replicas = pointer.Int32(5)
+		}
+		if args.updateTemplate {
+			ctx.rs.Spec.Template.Spec.ClassName = "new-class"
+		}
+		if args.updateSelector {
+			ctx.rs.Spec.Selector.MatchLabels["tier"] = "web"
+			ctx.rs.Spec.Template.Labels["tier"] = "web"
+		}
+
+		ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured(ctx.rs)
+		Expect(err).ToNot(HaveOccurred())
+
+		response := ctx.ValidateUpdate(&ctx.WebhookRequestContext)
+		Expect(response.Allowed).To(Equal(expectedAllowed))
+		if expectedReason != "" {
+			Expect(string(response.Result.Reason)).To(ContainSubstring(expectedReason))
+		}
+		if expectedErr != nil {
+			Expect(response.Result.Message).To(Equal(expectedErr.Error()))
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = newUnitTestContextForValidatingWebhook(true)
+	})
+	AfterEach(func() {
+		ctx = nil
+	})
+
+	DescribeTable("update table", validateUpdate,
+		Entry("should allow", updateArgs{}, true, nil, nil),
+		Entry("should allow replicas change", updateArgs{updateReplicas: true}, true, nil, nil),
+		Entry("should allow template change", updateArgs{updateTemplate: true}, true, nil, nil),
+		Entry("should deny selector change", updateArgs{updateSelector: true}, false, "spec.selector: Invalid value", nil),
+	)
+
+	When("the update is performed while object deletion", func() {
+		JustBeforeEach(func() {
+			t := metav1.Now()
+			ctx.WebhookRequestContext.Obj.SetDeletionTimestamp(&t)
+			response = ctx.ValidateUpdate(&ctx.WebhookRequestContext)
+		})
+
+		It("should allow the request", func() {
+			Expect(response.Allowed).To(BeTrue())
+			Expect(response.Result).ToNot(BeNil())
+		})
+	})
+}
+
+func unitTestsValidateDelete() {
+	var (
+		ctx      *unitValidatingWebhookContext
+		response admission.Response
+	)
+
+	BeforeEach(func() {
+		ctx = newUnitTestContextForValidatingWebhook(false)
+	})
+	AfterEach(func() {
+		ctx = nil
+	})
+
+	When("the delete is performed", func() {
+		JustBeforeEach(func() {
+			response = ctx.ValidateDelete(&ctx.WebhookRequestContext)
+		})
+
+		It("should allow the request", func() {
+			Expect(response.Allowed).To(BeTrue())
+			Expect(response.Result).ToNot(BeNil())
+		})
+	})
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package validation
+
+import (
+	"net/http"
+	"reflect"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmgr "sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	"github.com/vmware-tanzu/vm-operator/pkg/builder"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/webhooks/common"
+)
+
+const (
+	webHookName = "default"
+)
+
+// +kubebuilder:webhook:verbs=create;update,path=/default-validate-vmoperator-vmware-com-v1alpha2-virtualmachinerestorerequest,mutating=false,failurePolicy=fail,groups=vmoperator.vmware.com,resources=virtualmachinerestorerequests,versions=v1alpha2,name=default.validating.virtualmachinerestorerequest.v1alpha2.vmoperator.vmware.com,sideEffects=None,admissionReviewVersions=v1;v1beta1
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachinerestorerequests,verbs=get;list
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachinerestorerequests/status,verbs=get
+
+// AddToManager adds the webhook to the provided manager.
+func AddToManager(ctx *context.ControllerManagerContext, mgr ctrlmgr.Manager) error {
+	hook, err := builder.NewValidatingWebhook(ctx, mgr, webHookName, NewValidator(mgr.GetClient()))
+	if err != nil {
+		return errors.Wrapf(err, "failed to create virtualmachinerestorerequest validation webhook")
+	}
+	mgr.GetWebhookServer().Register(hook.Path, hook)
+	return nil
+}
+
+// NewValidator returns the package's Validator.
+func NewValidator(_ client.Client) builder.Validator {
+	return validator{
+		converter: runtime.DefaultUnstructuredConverter,
+	}
+}
+
+type validator struct {
+	converter runtime.UnstructuredConverter
+}
+
+func (v validator) For() schema.GroupVersionKind {
+	return vmopv1.SchemeGroupVersion.WithKind(reflect.TypeOf(vmopv1.VirtualMachineRestoreRequest{}).Name())
+}
+
+func (v validator) ValidateCreate(ctx *context.WebhookRequestContext) admission.Response {
+	restoreReq, err := v.restoreRequestFromUnstructured(ctx.Obj)
+	if err != nil {
+		return webhook.Errored(http.StatusBadRequest, err)
+	}
+
+	var fieldErrs field.ErrorList
+	fieldErrs = append(fieldErrs, v.validateSpec(restoreReq)...)
+
+	validationErrs := make([]string, 0, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		validationErrs = append(validationErrs, fieldErr.Error())
+	}
+
+	return common.BuildValidationResponse(ctx, nil, validationErrs, nil)
+}
+
+func (v validator) ValidateDelete(*context.WebhookRequestContext) admission.Response {
+	return admission.Allowed("")
+}
+
+// ValidateUpdate validates the spec is not changed since a request is only
+// processed once.
+func (v validator) ValidateUpdate(ctx *context.WebhookRequestContext) admission.Response {
+	restoreReq, err := v.restoreRequestFromUnstructured(ctx.Obj)
+	if err != nil {
+		return webhook.Errored(http.StatusBadRequest, err)
+	}
+
+	oldRestoreReq, err := v.restoreRequestFromUnstructured(ctx.OldObj)
+	if err != nil {
+		return webhook.Errored(http.StatusBadRequest, err)
+	}
+
+	var fieldErrs field.ErrorList
+	fieldErrs = append(fieldErrs, validation.ValidateImmutableField(restoreReq.Spec, oldRestoreReq.Spec, field.NewPath("spec"))...)
+
+	validationErrs := make([]string, 0, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		validationErrs = append(validationErrs, fieldErr.Error())
+	}
+	return common.BuildValidationResponse(ctx, nil, validationErrs, nil)
+}
+
+func (v validator) validateSpec(restoreReq *vmopv1.VirtualMachineRestoreRequest) field.ErrorList {
+	var allErrs field.ErrorList
+	namesPath := field.NewPath("spec", "virtualMachineNames")
+
+	names := sets.NewString()
+	for i, name := range restoreReq.Spec.VirtualMachineNames {
+		for _, msg := range validation.NameIsDNSSubdomain(name, false) {
+			allErrs = append(allErrs, field.Invalid(namesPath.Index(i), name, msg))
+		}
+		if names.Has(name) {
+			allErrs = append(allErrs, field.Duplicate(namesPath.Index(i), name))
+		}
+		names.Insert(name)
+	}
+
+	return allErrs
+}
+
+// restoreRequestFromUnstructured returns the VirtualMachineRestoreRequest from the unstructured object.
+func (v validator) restoreRequestFromUnstructured(obj runtime.Unstructured) (*vmopv1.VirtualMachineRestoreRequest, error) {
+	restoreReq := &vmopv1.VirtualMachineRestoreRequest{}
+	if err := v.converter.FromUnstructured(obj.UnstructuredContent(), restoreReq); err != nil {
+		return nil, err
+	}
+	return restoreReq, nil
+}
@@ -0,0 +1,174 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package validation_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func unitTests() {
+	Describe("Invoking ValidateCreate", unitTestsValidateCreate)
+	Describe("Invoking ValidateUpdate", unitTestsValidateUpdate)
+	Describe("Invoking ValidateDelete", unitTestsValidateDelete)
+}
+
+type unitValidatingWebhookContext struct {
+	builder.UnitTestContextForValidatingWebhook
+	restoreReq    *vmopv1.VirtualMachineRestoreRequest
+	oldRestoreReq *vmopv1.VirtualMachineRestoreRequest
+}
+
+func newUnitTestContextForValidatingWebhook(isUpdate bool) *unitValidatingWebhookContext {
+	restoreReq := builder.DummyVirtualMachineRestoreRequest("some-namespace", "some-name")
+	restoreReq.Spec.VirtualMachineNames = []string{"my-vm"}
+	obj, err := builder.ToUnstructured(restoreReq)
+	Expect(err).ToNot(HaveOccurred())
+
+	var oldRestoreReq *vmopv1.VirtualMachineRestoreRequest
+	var oldObj *unstructured.Unstructured
+
+	if isUpdate {
+		oldRestoreReq = restoreReq.DeepCopy()
+		oldObj, err = builder.ToUnstructured(oldRestoreReq)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	return &unitValidatingWebhookContext{
+		UnitTestContextForValidatingWebhook: *suite.NewUnitTestContextForValidatingWebhook(obj, oldObj),
+		restoreReq:                          restoreReq,
+		oldRestoreReq:                       oldRestoreReq,
+	}
+}
+
+func unitTestsValidateCreate() {
+	var (
+		ctx *unitValidatingWebhookContext
+	)
+
+	type createArgs struct {
+		noNames       bool
+		invalidName   bool
+		duplicateName bool
+	}
+
+	validateCreate := func(args createArgs, expectedAllowed bool, expectedReason string, expectedErr error) {
+		var err error
+
+		if args.noNames {
+			ctx.restoreReq.Spec.VirtualMachineNames = nil
+		}
+		if args.invalidName {
+			ctx.restoreReq.Spec.VirtualMachineNames = append(ctx.restoreReq.Spec.VirtualMachineNames, "My_VM")
+		}
+		if args.duplicateName {
+			ctx.restoreReq.Spec.VirtualMachineNames = append(ctx.restoreReq.Spec.VirtualMachineNames, "my-vm")
+		}
+
+		ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured(ctx.restoreReq)
+		Expect(err).ToNot(HaveOccurred())
+
+		response := ctx.ValidateCreate(&ctx.WebhookRequestContext)
+		Expect(response.Allowed).To(Equal(expectedAllowed))
+		if expectedReason != "" {
+			Expect(string(response.Result.Reason)).To(ContainSubstring(expectedReason))
+		}
+		if expectedErr != nil {
+			Expect(response.Result.Message).To(Equal(expectedErr.Error()))
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = newUnitTestContextForValidatingWebhook(false)
+	})
+	AfterEach(func() {
+		ctx = nil
+	})
+
+	DescribeTable("create table", validateCreate,
+		Entry("should allow valid", createArgs{}, true, nil, nil),
+		Entry("should allow no names", createArgs{noNames: true}, true, nil, nil),
+		Entry("should deny invalid name", createArgs{invalidName: true}, false, `spec.virtualMachineNames[1]: Invalid value: "My_VM"`, nil),
+		Entry("should deny duplicate name", createArgs{duplicateName: true}, false, `spec.virtualMachineNames[1]: Duplicate value: "my-vm"`, nil),
+	)
+}
+
+func unitTestsValidateUpdate() {
+	var (
+		ctx *unitValidatingWebhookContext
+	)
+
+	type updateArgs struct {
+		updateNames bool
+		updateLabel bool
+	}
+
+	validateUpdate := func(args updateArgs, expectedAllowed bool, expectedReason string, expectedErr error) {
+		var err error
+
+		if args.updateNames {
+			ctx.restoreReq.Spec.VirtualMachineNames = []string{"other-vm"}
+		}
+		if args.updateLabel {
+			ctx.restoreReq.Labels = map[string]string{"foo": "bar"}
+		}
+
+		ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured(ctx.restoreReq)
+		Expect(err).ToNot(HaveOccurred())
+
+		response := ctx.ValidateUpdate(&ctx.WebhookRequestContext)
+		Expect(response.Allowed).To(Equal(expectedAllowed))
+		if expectedReason != "" {
+			Expect(string(response.Result.Reason)).To(ContainSubstring(expectedReason))
+		}
+		if expectedErr != nil {
+			Expect(response.Result.Message).To(Equal(expectedErr.Error()))
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = newUnitTestContextForValidatingWebhook(true)
+	})
+	AfterEach(func() {
+		ctx = nil
+	})
+
+	DescribeTable("update table", validateUpdate,
+		Entry("should allow", updateArgs{}, true, nil, nil),
+		Entry("should allow label change", updateArgs{updateLabel: true}, true, nil, nil),
+		Entry("should deny names change", updateArgs{updateNames: true}, false, "spec: Invalid value", nil),
+	)
+}
+
+func unitTestsValidateDelete() {
+	var (
+		ctx      *unitValidatingWebhookContext
+		response admission.Response
+	)
+
+	BeforeEach(func() {
+		ctx = newUnitTestContextForValidatingWebhook(false)
+	})
+	AfterEach(func() {
+		ctx = nil
+	})
+
+	When("the delete is performed", func() {
+		JustBeforeEach(func() {
+			response = ctx.ValidateDelete(&ctx.WebhookRequestContext)
+		})
+
+		It("should allow the request", func() {
+			Expect(response.Allowed).To(BeTrue())
+			Expect(response.Result).ToNot(BeNil())
+		})
+	})
+}
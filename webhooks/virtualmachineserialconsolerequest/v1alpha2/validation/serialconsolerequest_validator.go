@@ -0,0 +1,193 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package validation
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"reflect"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmgr "sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	"github.com/vmware-tanzu/vm-operator/pkg/builder"
+	"github.com/vmware-tanzu/vm-operator/pkg/context"
+	"github.com/vmware-tanzu/vm-operator/pkg/serialconsole"
+	"github.com/vmware-tanzu/vm-operator/webhooks/common"
+)
+
+const (
+	webHookName = "default"
+
+	settingTicketMetadataNotAllowed = "the ticket of a request is only issued by the controller"
+)
+
+// +kubebuilder:webhook:verbs=create;update,path=/default-validate-vmoperator-vmware-com-v1alpha2-virtualmachineserialconsolerequest,mutating=false,failurePolicy=fail,groups=vmoperator.vmware.com,resources=virtualmachineserialconsolerequests,versions=v1alpha2,name=default.validating.virtualmachineserialconsolerequest.v1alpha2.vmoperator.vmware.com,sideEffects=None,admissionReviewVersions=v1;v1beta1
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachineserialconsolerequests,verbs=get;list
+// +kubebuilder:rbac:groups=vmoperator.vmware.com,resources=virtualmachineserialconsolerequests/status,verbs=get
+
+// AddToManager adds the webhook to the provided manager.
+func AddToManager(ctx *context.ControllerManagerContext, mgr ctrlmgr.Manager) error {
+	hook, err := builder.NewValidatingWebhook(ctx, mgr, webHookName, NewValidator(mgr.GetClient()))
+	if err != nil {
+		return errors.Wrapf(err, "failed to create virtualmachineserialconsolerequest validation webhook")
+	}
+	mgr.GetWebhookServer().Register(hook.Path, hook)
+	return nil
+}
+
+// NewValidator returns the package's Validator.
+func NewValidator(_ client.Client) builder.Validator {
+	return validator{
+		converter: runtime.DefaultUnstructuredConverter,
+	}
+}
+
+type validator struct {
+	converter runtime.UnstructuredConverter
+}
+
+func (v validator) For() schema.GroupVersionKind {
+	return vmopv1.SchemeGroupVersion.WithKind(reflect.TypeOf(vmopv1.VirtualMachineSerialConsoleRequest{}).Name())
+}
+
+func (v validator) ValidateCreate(ctx *context.WebhookRequestContext) admission.Response {
+	scr, err := v.serialConsoleRequestFromUnstructured(ctx.Obj)
+	if err != nil {
+		return webhook.Errored(http.StatusBadRequest, err)
+	}
+
+	var fieldErrs field.ErrorList
+	fieldErrs = append(fieldErrs, v.validateSpec(scr)...)
+	fieldErrs = append(fieldErrs, v.validateTicketMetadata(ctx, scr, nil)...)
+
+	validationErrs := make([]string, 0, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		validationErrs = append(validationErrs, fieldErr.Error())
+	}
+
+	return common.BuildValidationResponse(ctx, nil, validationErrs, nil)
+}
+
+func (v validator) ValidateDelete(*context.WebhookRequestContext) admission.Response {
+	return admission.Allowed("")
+}
+
+// ValidateUpdate validates the spec and the ticket metadata the proxy
+// validates connections with are not changed.
+func (v validator) ValidateUpdate(ctx *context.WebhookRequestContext) admission.Response {
+	scr, err := v.serialConsoleRequestFromUnstructured(ctx.Obj)
+	if err != nil {
+		return webhook.Errored(http.StatusBadRequest, err)
+	}
+
+	oldScr, err := v.serialConsoleRequestFromUnstructured(ctx.OldObj)
+	if err != nil {
+		return webhook.Errored(http.StatusBadRequest, err)
+	}
+
+	var fieldErrs field.ErrorList
+	fieldErrs = append(fieldErrs, validation.ValidateImmutableField(scr.Spec, oldScr.Spec, field.NewPath("spec"))...)
+	fieldErrs = append(fieldErrs, v.validateTicketMetadata(ctx, scr, oldScr)...)
+
+	validationErrs := make([]string, 0, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		validationErrs = append(validationErrs, fieldErr.Error())
+	}
+	return common.BuildValidationResponse(ctx, nil, validationErrs, nil)
+}
+
+func (v validator) validateSpec(scr *vmopv1.VirtualMachineSerialConsoleRequest) field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if scr.Spec.Name == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("name"), ""))
+	}
+	allErrs = append(allErrs, v.validatePublicKey(specPath.Child("publicKey"), scr.Spec.PublicKey)...)
+
+	return allErrs
+}
+
+func (v validator) validatePublicKey(path *field.Path, publicKey string) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if publicKey == "" {
+		allErrs = append(allErrs, field.Required(path, ""))
+		return allErrs
+	}
+
+	block, _ := pem.Decode([]byte(publicKey))
+	if block == nil || block.Type != "PUBLIC KEY" {
+		allErrs = append(allErrs, field.Invalid(path, "", "invalid public key format"))
+		return allErrs
+	}
+	if _, err := x509.ParsePKCS1PublicKey(block.Bytes); err != nil {
+		allErrs = append(allErrs, field.Invalid(path, "", "invalid public key"))
+	}
+
+	return allErrs
+}
+
+// validateTicketMetadata validates that only privileged accounts, like the
+// controller that issues the ticket of a request, set the UUID label and the
+// ticket annotations that the proxy validates connections with.
+func (v validator) validateTicketMetadata(
+	ctx *context.WebhookRequestContext,
+	scr, oldScr *vmopv1.VirtualMachineSerialConsoleRequest) field.ErrorList {
+
+	var allErrs field.ErrorList
+
+	if ctx.IsPrivilegedAccount {
+		return allErrs
+	}
+
+	var oldLabels, oldAnnotations map[string]string
+	if oldScr != nil {
+		oldLabels, oldAnnotations = oldScr.Labels, oldScr.Annotations
+	}
+
+	labelsPath := field.NewPath("metadata", "labels")
+	if !metadataValueEqual(scr.Labels, oldLabels, serialconsole.UUIDLabelKey) {
+		allErrs = append(allErrs, field.Forbidden(labelsPath.Key(serialconsole.UUIDLabelKey), settingTicketMetadataNotAllowed))
+	}
+
+	annotationsPath := field.NewPath("metadata", "annotations")
+	ticketAnnotationKeys := []string{
+		serialconsole.TicketHashAnnotationKey,
+		serialconsole.UsedAnnotationKey,
+		serialconsole.LogTicketHashAnnotationKey,
+	}
+	for _, key := range ticketAnnotationKeys {
+		if !metadataValueEqual(scr.Annotations, oldAnnotations, key) {
+			allErrs = append(allErrs, field.Forbidden(annotationsPath.Key(key), settingTicketMetadataNotAllowed))
+		}
+	}
+
+	return allErrs
+}
+
+func metadataValueEqual(m, oldM map[string]string, key string) bool {
+	val, ok := m[key]
+	oldVal, oldOk := oldM[key]
+	return ok == oldOk && val == oldVal
+}
+
+// serialConsoleRequestFromUnstructured returns the VirtualMachineSerialConsoleRequest from the unstructured object.
+func (v validator) serialConsoleRequestFromUnstructured(obj runtime.Unstructured) (*vmopv1.VirtualMachineSerialConsoleRequest, error) {
+	scr := &vmopv1.VirtualMachineSerialConsoleRequest{}
+	if err := v.converter.FromUnstructured(obj.UnstructuredContent(), scr); err != nil {
+		return nil, err
+	}
+	return scr, nil
+}
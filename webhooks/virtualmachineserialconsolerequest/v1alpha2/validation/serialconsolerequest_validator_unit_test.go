@@ -0,0 +1,259 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package validation_test
+
+import (
+	"encoding/pem"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	"github.com/vmware-tanzu/vm-operator/pkg/serialconsole"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func unitTests() {
+	Describe("Invoking ValidateCreate", unitTestsValidateCreate)
+	Describe("Invoking ValidateUpdate", unitTestsValidateUpdate)
+	Describe("Invoking ValidateDelete", unitTestsValidateDelete)
+}
+
+type unitValidatingWebhookContext struct {
+	builder.UnitTestContextForValidatingWebhook
+	scr    *vmopv1.VirtualMachineSerialConsoleRequest
+	oldScr *vmopv1.VirtualMachineSerialConsoleRequest
+}
+
+func newUnitTestContextForValidatingWebhook(isUpdate bool) *unitValidatingWebhookContext {
+	_, publicKeyPem := builder.WebConsoleRequestKeyPair()
+	scr := builder.DummyVirtualMachineSerialConsoleRequest("some-namespace", "some-name", "my-vm", publicKeyPem)
+	obj, err := builder.ToUnstructured(scr)
+	Expect(err).ToNot(HaveOccurred())
+
+	var oldScr *vmopv1.VirtualMachineSerialConsoleRequest
+	var oldObj *unstructured.Unstructured
+
+	if isUpdate {
+		oldScr = scr.DeepCopy()
+		oldScr.Labels = map[string]string{serialconsole.UUIDLabelKey: "some-uuid"}
+		oldScr.Annotations = map[string]string{serialconsole.TicketHashAnnotationKey: "some-hash"}
+		oldObj, err = builder.ToUnstructured(oldScr)
+		Expect(err).ToNot(HaveOccurred())
+		scr.Labels = map[string]string{serialconsole.UUIDLabelKey: "some-uuid"}
+		scr.Annotations = map[string]string{serialconsole.TicketHashAnnotationKey: "some-hash"}
+	}
+
+	return &unitValidatingWebhookContext{
+		UnitTestContextForValidatingWebhook: *suite.NewUnitTestContextForValidatingWebhook(obj, oldObj),
+		scr:                                 scr,
+		oldScr:                              oldScr,
+	}
+}
+
+func unitTestsValidateCreate() {
+	var (
+		ctx *unitValidatingWebhookContext
+	)
+
+	type createArgs struct {
+		noVMName         bool
+		noPublicKey      bool
+		invalidKeyFormat bool
+		invalidKey       bool
+		withLog          bool
+		withUUIDLabel    bool
+		withTicketHash   bool
+		withUsed         bool
+		withLogTicket    bool
+		isPrivileged     bool
+	}
+
+	validateCreate := func(args createArgs, expectedAllowed bool, expectedReason string, expectedErr error) {
+		var err error
+
+		if args.noVMName {
+			ctx.scr.Spec.Name = ""
+		}
+		if args.noPublicKey {
+			ctx.scr.Spec.PublicKey = ""
+		}
+		if args.invalidKeyFormat {
+			ctx.scr.Spec.PublicKey = "not-a-pem"
+		}
+		if args.invalidKey {
+			ctx.scr.Spec.PublicKey = string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: []byte("not-a-key")}))
+		}
+		if args.withLog {
+			ctx.scr.Spec.Log = &vmopv1.VirtualMachineSerialConsoleLogSpec{MaxBytes: 4096}
+		}
+		if args.withUUIDLabel {
+			ctx.scr.Labels = map[string]string{serialconsole.UUIDLabelKey: "some-uuid"}
+		}
+		if args.withTicketHash {
+			ctx.scr.Annotations = map[string]string{serialconsole.TicketHashAnnotationKey: "some-hash"}
+		}
+		if args.withUsed {
+			ctx.scr.Annotations = map[string]string{serialconsole.UsedAnnotationKey: ""}
+		}
+		if args.withLogTicket {
+			ctx.scr.Annotations = map[string]string{serialconsole.LogTicketHashAnnotationKey: "some-hash"}
+		}
+		ctx.IsPrivilegedAccount = args.isPrivileged
+
+		ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured(ctx.scr)
+		Expect(err).ToNot(HaveOccurred())
+
+		response := ctx.ValidateCreate(&ctx.WebhookRequestContext)
+		Expect(response.Allowed).To(Equal(expectedAllowed))
+		if expectedReason != "" {
+			Expect(string(response.Result.Reason)).To(ContainSubstring(expectedReason))
+		}
+		if expectedErr != nil {
+			Expect(response.Result.Message).To(Equal(expectedErr.Error()))
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = newUnitTestContextForValidatingWebhook(false)
+	})
+	AfterEach(func() {
+		ctx = nil
+	})
+
+	DescribeTable("create table", validateCreate,
+		Entry("should allow valid request", createArgs{}, true, nil, nil),
+		Entry("should allow request with log", createArgs{withLog: true}, true, nil, nil),
+		Entry("should deny no VM name", createArgs{noVMName: true}, false, "spec.name: Required value", nil),
+		Entry("should deny no public key", createArgs{noPublicKey: true}, false, "spec.publicKey: Required value", nil),
+		Entry("should deny invalid public key format", createArgs{invalidKeyFormat: true}, false, "invalid public key format", nil),
+		Entry("should deny invalid public key", createArgs{invalidKey: true}, false, "invalid public key", nil),
+		Entry("should deny UUID label", createArgs{withUUIDLabel: true}, false,
+			"metadata.labels[vmoperator.vmware.com/serialconsolerequest-uuid]: Forbidden", nil),
+		Entry("should deny ticket hash annotation", createArgs{withTicketHash: true}, false,
+			"metadata.annotations[vmoperator.vmware.com/serialconsolerequest-ticket-hash]: Forbidden", nil),
+		Entry("should deny used annotation", createArgs{withUsed: true}, false,
+			"metadata.annotations[vmoperator.vmware.com/serialconsolerequest-used]: Forbidden", nil),
+		Entry("should deny log ticket hash annotation", createArgs{withLogTicket: true}, false,
+			"metadata.annotations[vmoperator.vmware.com/serialconsolerequest-log-ticket-hash]: Forbidden", nil),
+		Entry("should allow ticket metadata when privileged", createArgs{withUUIDLabel: true, withTicketHash: true, isPrivileged: true}, true, nil, nil),
+	)
+}
+
+func unitTestsValidateUpdate() {
+	var (
+		ctx *unitValidatingWebhookContext
+	)
+
+	type updateArgs struct {
+		updateName       bool
+		updatePublicKey  bool
+		updateLog        bool
+		updateUUIDLabel  bool
+		removeUUIDLabel  bool
+		updateOtherLabel bool
+		updateTicketHash bool
+		addUsed          bool
+		removeUsed       bool
+		isPrivileged     bool
+	}
+
+	validateUpdate := func(args updateArgs, expectedAllowed bool, expectedReason string, expectedErr error) {
+		var err error
+
+		if args.updateName {
+			ctx.scr.Spec.Name = "other-vm"
+		}
+		if args.updatePublicKey {
+			_, ctx.scr.Spec.PublicKey = builder.WebConsoleRequestKeyPair()
+		}
+		if args.updateLog {
+			ctx.scr.Spec.Log = &vmopv1.VirtualMachineSerialConsoleLogSpec{}
+		}
+		if args.updateUUIDLabel {
+			ctx.scr.Labels[serialconsole.UUIDLabelKey] = "other-uuid"
+		}
+		if args.removeUUIDLabel {
+			delete(ctx.scr.Labels, serialconsole.UUIDLabelKey)
+		}
+		if args.updateOtherLabel {
+			ctx.scr.Labels["foo"] = "bar"
+		}
+		if args.updateTicketHash {
+			ctx.scr.Annotations[serialconsole.TicketHashAnnotationKey] = "other-hash"
+		}
+		if args.addUsed {
+			ctx.scr.Annotations[serialconsole.UsedAnnotationKey] = "2023-01-01T00:00:00Z"
+		}
+		if args.removeUsed {
+			ctx.oldScr.Annotations[serialconsole.UsedAnnotationKey] = "2023-01-01T00:00:00Z"
+			ctx.WebhookRequestContext.OldObj, err = builder.ToUnstructured(ctx.oldScr)
+			Expect(err).ToNot(HaveOccurred())
+		}
+		ctx.IsPrivilegedAccount = args.isPrivileged
+
+		ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured(ctx.scr)
+		Expect(err).ToNot(HaveOccurred())
+
+		response := ctx.ValidateUpdate(&ctx.WebhookRequestContext)
+		Expect(response.Allowed).To(Equal(expectedAllowed))
+		if expectedReason != "" {
+			Expect(string(response.Result.Reason)).To(ContainSubstring(expectedReason))
+		}
+		if expectedErr != nil {
+			Expect(response.Result.Message).To(Equal(expectedErr.Error()))
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = newUnitTestContextForValidatingWebhook(true)
+	})
+	AfterEach(func() {
+		ctx = nil
+	})
+
+	DescribeTable("update table", validateUpdate,
+		Entry("should allow", updateArgs{}, true, nil, nil),
+		Entry("should allow other label change", updateArgs{updateOtherLabel: true}, true, nil, nil),
+		Entry("should deny name change", updateArgs{updateName: true}, false, "spec: Invalid value", nil),
+		Entry("should deny public key change", updateArgs{updatePublicKey: true}, false, "spec: Invalid value", nil),
+		Entry("should deny log change", updateArgs{updateLog: true}, false, "spec: Invalid value", nil),
+		Entry("should deny UUID label change", updateArgs{updateUUIDLabel: true}, false, "Forbidden", nil),
+		Entry("should deny UUID label removal", updateArgs{removeUUIDLabel: true}, false, "Forbidden", nil),
+		Entry("should deny ticket hash change", updateArgs{updateTicketHash: true}, false, "Forbidden", nil),
+		Entry("should deny marking the ticket used", updateArgs{addUsed: true}, false, "Forbidden", nil),
+		Entry("should deny reusing the ticket", updateArgs{removeUsed: true}, false, "Forbidden", nil),
+		Entry("should allow ticket metadata change when privileged",
+			updateArgs{updateUUIDLabel: true, updateTicketHash: true, addUsed: true, isPrivileged: true}, true, nil, nil),
+	)
+}
+
+func unitTestsValidateDelete() {
+	var (
+		ctx      *unitValidatingWebhookContext
+		response admission.Response
+	)
+
+	BeforeEach(func() {
+		ctx = newUnitTestContextForValidatingWebhook(false)
+	})
+	AfterEach(func() {
+		ctx = nil
+	})
+
+	When("the delete is performed", func() {
+		JustBeforeEach(func() {
+			response = ctx.ValidateDelete(&ctx.WebhookRequestContext)
+		})
+
+		It("should allow the request", func() {
+			Expect(response.Allowed).To(BeTrue())
+			Expect(response.Result).ToNot(BeNil())
+		})
+	})
+}
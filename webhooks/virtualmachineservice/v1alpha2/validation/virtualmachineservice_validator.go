@@ -15,6 +15,7 @@ import (
 	unversionedvalidation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
@@ -39,6 +40,19 @@ var (
 		string(vmopv1.VirtualMachineServiceTypeLoadBalancer),
 		string(vmopv1.VirtualMachineServiceTypeClusterIP),
 		string(vmopv1.VirtualMachineServiceTypeExternalName),
+		string(vmopv1.VirtualMachineServiceTypeHeadless),
+		string(vmopv1.VirtualMachineServiceTypeNodePort),
+	)
+
+	supportedIPFamilies = sets.NewString(
+		string(vmopv1.IPv4Protocol),
+		string(vmopv1.IPv6Protocol),
+	)
+
+	supportedIPFamilyPolicies = sets.NewString(
+		string(vmopv1.IPFamilyPolicySingleStack),
+		string(vmopv1.IPFamilyPolicyPreferDualStack),
+		string(vmopv1.IPFamilyPolicyRequireDualStack),
 	)
 
 	supportedPortProtocols = sets.NewString(
@@ -64,8 +78,9 @@ func AddToManager(ctx *context.ControllerManagerContext, mgr ctrlmgr.Manager) er
 }
 
 // NewValidator returns the package's Validator.
-func NewValidator(_ client.Client) builder.Validator {
+func NewValidator(client client.Client) builder.Validator {
 	return validator{
+		client:    client,
 		converter: runtime.DefaultUnstructuredConverter,
 	}
 }
@@ -75,6 +90,7 @@ func NewValidator(_ client.Client) builder.Validator {
 // be transformed into a valid Service.
 
 type validator struct {
+	client    client.Client
 	converter runtime.UnstructuredConverter
 }
 
@@ -147,6 +163,18 @@ func (v validator) validateSpec(ctx *context.WebhookRequestContext, vmService *v
 				"may not be set to 'None' for LoadBalancer services"))
 		}
 
+	case vmopv1.VirtualMachineServiceTypeNodePort:
+		if isHeadlessVMService(vmService) {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("clusterIP"), vmService.Spec.ClusterIP,
+				"may not be set to 'None' for NodePort services"))
+		}
+
+	case vmopv1.VirtualMachineServiceTypeHeadless:
+		if clusterIP := vmService.Spec.ClusterIP; clusterIP != "" && clusterIP != corev1.ClusterIPNone {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("clusterIP"), clusterIP,
+				"must be empty or 'None' for Headless services"))
+		}
+
 	case vmopv1.VirtualMachineServiceTypeExternalName:
 		if vmService.Spec.ClusterIP != "" {
 			allErrs = append(allErrs, field.Forbidden(specPath.Child("clusterIP"), "may not be set for ExternalName services"))
@@ -181,6 +209,8 @@ func (v validator) validateSpec(ctx *context.WebhookRequestContext, vmService *v
 		allErrs = append(allErrs, field.NotSupported(specPath.Child("type"), vmService.Spec.Type, supportedServiceType.List()))
 	}
 
+	allErrs = append(allErrs, validateIPFamilies(vmService, specPath)...)
+
 	if len(vmService.Spec.LoadBalancerSourceRanges) > 0 {
 		fldPath := specPath.Child("loadBalancerSourceRanges")
 
@@ -198,6 +228,48 @@ func (v validator) validateSpec(ctx *context.WebhookRequestContext, vmService *v
 	return allErrs
 }
 
+func validateIPFamilies(vmService *vmopv1.VirtualMachineService, specPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	ipFamiliesPath := specPath.Child("ipFamilies")
+	ipFamilyPolicyPath := specPath.Child("ipFamilyPolicy")
+
+	if vmService.Spec.Type == vmopv1.VirtualMachineServiceTypeExternalName {
+		if len(vmService.Spec.IPFamilies) > 0 {
+			allErrs = append(allErrs, field.Forbidden(ipFamiliesPath, "may not be set for ExternalName services"))
+		}
+		if vmService.Spec.IPFamilyPolicy != nil {
+			allErrs = append(allErrs, field.Forbidden(ipFamilyPolicyPath, "may not be set for ExternalName services"))
+		}
+		return allErrs
+	}
+
+	if policy := vmService.Spec.IPFamilyPolicy; policy != nil {
+		if !supportedIPFamilyPolicies.Has(string(*policy)) {
+			allErrs = append(allErrs, field.NotSupported(ipFamilyPolicyPath, *policy, supportedIPFamilyPolicies.List()))
+		} else if *policy == vmopv1.IPFamilyPolicySingleStack && len(vmService.Spec.IPFamilies) > 1 {
+			allErrs = append(allErrs, field.Invalid(ipFamiliesPath, vmService.Spec.IPFamilies,
+				"must have at most one entry when `ipFamilyPolicy` is 'SingleStack'"))
+		}
+	}
+
+	if len(vmService.Spec.IPFamilies) > 2 {
+		allErrs = append(allErrs, field.Invalid(ipFamiliesPath, vmService.Spec.IPFamilies, "may specify no more than one IP for each IP family"))
+	}
+
+	seen := sets.Set[vmopv1.IPFamily]{}
+	for i, ipFamily := range vmService.Spec.IPFamilies {
+		idxPath := ipFamiliesPath.Index(i)
+		if !supportedIPFamilies.Has(string(ipFamily)) {
+			allErrs = append(allErrs, field.NotSupported(idxPath, ipFamily, supportedIPFamilies.List()))
+		} else if seen.Has(ipFamily) {
+			allErrs = append(allErrs, field.Duplicate(idxPath, ipFamily))
+		}
+		seen.Insert(ipFamily)
+	}
+
+	return allErrs
+}
+
 func validatePorts(vmService *vmopv1.VirtualMachineService, specPath *field.Path) field.ErrorList {
 	var allErrs field.ErrorList
 	portsPath := specPath.Child("ports")
@@ -210,6 +282,10 @@ func validatePorts(vmService *vmopv1.VirtualMachineService, specPath *field.Path
 	for i := range vmService.Spec.Ports {
 		portPath := portsPath.Index(i)
 		allErrs = append(allErrs, validateServicePort(&vmService.Spec.Ports[i], len(vmService.Spec.Ports) > 1, &allPortNames, portPath)...)
+
+		if vmService.Spec.Ports[i].NodePort != 0 && vmService.Spec.Type != vmopv1.VirtualMachineServiceTypeNodePort {
+			allErrs = append(allErrs, field.Forbidden(portPath.Child("nodePort"), "may only be used when `type` is 'NodePort'"))
+		}
 	}
 
 	// Check for duplicate Ports, considering (protocol,port) pairs.
@@ -224,6 +300,21 @@ func validatePorts(vmService *vmopv1.VirtualMachineService, specPath *field.Path
 		ports[key] = true
 	}
 
+	// Check for duplicate NodePorts, considering (protocol,port) pairs.
+	nodePorts := make(map[vmopv1.VirtualMachineServicePort]bool)
+	for i, port := range vmService.Spec.Ports {
+		if port.NodePort == 0 {
+			continue
+		}
+		portPath := portsPath.Index(i)
+		key := vmopv1.VirtualMachineServicePort{Protocol: port.Protocol, NodePort: port.NodePort}
+		_, found := nodePorts[key]
+		if found {
+			allErrs = append(allErrs, field.Duplicate(portPath.Child("nodePort"), port.NodePort))
+		}
+		nodePorts[key] = true
+	}
+
 	return allErrs
 }
 
@@ -251,8 +342,21 @@ func validateServicePort(sp *vmopv1.VirtualMachineServicePort, requireName bool,
 		allErrs = append(allErrs, field.NotSupported(fldPath.Child("protocol"), sp.Protocol, supportedPortProtocols.List()))
 	}
 
-	for _, msg := range validation.IsValidPortNum(int(sp.TargetPort)) {
-		allErrs = append(allErrs, field.Invalid(fldPath.Child("targetPort"), sp.TargetPort, msg))
+	switch sp.TargetPort.Type {
+	case intstr.String:
+		for _, msg := range validation.IsValidPortName(sp.TargetPort.StrVal) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("targetPort"), sp.TargetPort.StrVal, msg))
+		}
+	default:
+		for _, msg := range validation.IsValidPortNum(sp.TargetPort.IntValue()) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("targetPort"), sp.TargetPort.IntValue(), msg))
+		}
+	}
+
+	if sp.NodePort != 0 {
+		for _, msg := range validation.IsValidPortNum(int(sp.NodePort)) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("nodePort"), sp.NodePort, msg))
+		}
 	}
 
 	return allErrs
@@ -273,9 +377,38 @@ func (v validator) validateAllowedChanges(ctx *context.WebhookRequestContext, vm
 		allErrs = append(allErrs, field.Forbidden(specPath.Child("clusterIP"), "field is immutable"))
 	}
 
+	// Like the Service's, the primary IP family cannot be changed through updates. The secondary
+	// family may be added or removed to upgrade or downgrade dual-stack.
+	if ipFamilies := vmService.Spec.IPFamilies; len(ipFamilies) > 0 {
+		primaryIPFamily, err := v.primaryIPFamily(ctx, oldVMService)
+		if err != nil {
+			allErrs = append(allErrs, field.InternalError(specPath.Child("ipFamilies"), err))
+		} else if primaryIPFamily != "" && ipFamilies[0] != primaryIPFamily {
+			allErrs = append(allErrs, field.Forbidden(specPath.Child("ipFamilies").Index(0), "primary IP family is immutable"))
+		}
+	}
+
 	return allErrs
 }
 
+// primaryIPFamily returns the primary IP family of the VirtualMachineService. When it did not
+// specify the IP families, the primary family is the one k8s defaulted for its Service. An empty
+// family is returned when neither is known yet.
+func (v validator) primaryIPFamily(ctx *context.WebhookRequestContext, vmService *vmopv1.VirtualMachineService) (vmopv1.IPFamily, error) {
+	if len(vmService.Spec.IPFamilies) > 0 {
+		return vmService.Spec.IPFamilies[0], nil
+	}
+
+	service := &corev1.Service{}
+	if err := v.client.Get(ctx, client.ObjectKey{Namespace: vmService.Namespace, Name: vmService.Name}, service); err != nil {
+		return "", client.IgnoreNotFound(err)
+	}
+	if len(service.Spec.IPFamilies) == 0 {
+		return "", nil
+	}
+	return vmopv1.IPFamily(service.Spec.IPFamilies[0]), nil
+}
+
 // vmServiceFromUnstructured returns the VirtualMachineService from the unstructured object.
 func (v validator) vmServiceFromUnstructured(obj runtime.Unstructured) (*vmopv1.VirtualMachineService, error) {
 	vmService := &vmopv1.VirtualMachineService{}
@@ -286,7 +419,8 @@ func (v validator) vmServiceFromUnstructured(obj runtime.Unstructured) (*vmopv1.
 }
 
 func isHeadlessVMService(vmService *vmopv1.VirtualMachineService) bool {
-	return vmService.Spec.ClusterIP == corev1.ClusterIPNone
+	return vmService.Spec.Type == vmopv1.VirtualMachineServiceTypeHeadless ||
+		vmService.Spec.ClusterIP == corev1.ClusterIPNone
 }
 
 func ValidateDNS1123Label(value string, fldPath *field.Path) field.ErrorList {
@@ -8,8 +8,10 @@ import (
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
@@ -63,6 +65,18 @@ func unitTestsValidateCreate() {
 		invalidClusterIP      bool
 		invalidLBSourceRanges bool
 		invalidExternalName   bool
+		headless              bool
+		headlessClusterIP     bool
+		nodePort              bool
+		nodePortNoneClusterIP bool
+		nodePortNotNodePort   bool
+		invalidNodePort       bool
+		duplicateNodePort     bool
+		dualStack             bool
+		invalidIPFamily       bool
+		duplicateIPFamily     bool
+		singleStackDualStack  bool
+		externalNameIPFamily  bool
 	}
 
 	validateCreate := func(args createArgs, expectedAllowed bool, expectedReason string, expectedErr error) {
@@ -95,6 +109,56 @@ func unitTestsValidateCreate() {
 			ctx.vmService.Spec.Type = vmopv1.VirtualMachineServiceTypeExternalName
 			ctx.vmService.Spec.ExternalName = "InValid!"
 		}
+		if args.headless {
+			ctx.vmService.Spec.Type = vmopv1.VirtualMachineServiceTypeHeadless
+			ctx.vmService.Spec.Ports = nil
+		}
+		if args.headlessClusterIP {
+			ctx.vmService.Spec.Type = vmopv1.VirtualMachineServiceTypeHeadless
+			ctx.vmService.Spec.ClusterIP = "10.0.0.1"
+		}
+		if args.nodePort {
+			ctx.vmService.Spec.Type = vmopv1.VirtualMachineServiceTypeNodePort
+			ctx.vmService.Spec.Ports[0].NodePort = 30080
+		}
+		if args.nodePortNoneClusterIP {
+			ctx.vmService.Spec.Type = vmopv1.VirtualMachineServiceTypeNodePort
+			ctx.vmService.Spec.ClusterIP = corev1.ClusterIPNone
+		}
+		if args.nodePortNotNodePort {
+			ctx.vmService.Spec.Type = vmopv1.VirtualMachineServiceTypeClusterIP
+			ctx.vmService.Spec.Ports[0].NodePort = 30080
+		}
+		if args.invalidNodePort {
+			ctx.vmService.Spec.Type = vmopv1.VirtualMachineServiceTypeNodePort
+			ctx.vmService.Spec.Ports[0].NodePort = 100000
+		}
+		if args.duplicateNodePort {
+			ctx.vmService.Spec.Type = vmopv1.VirtualMachineServiceTypeNodePort
+			ctx.vmService.Spec.Ports = []vmopv1.VirtualMachineServicePort{
+				{Name: "port1", Protocol: "TCP", Port: 80, TargetPort: intstr.FromInt(8080), NodePort: 30080},
+				{Name: "port2", Protocol: "TCP", Port: 443, TargetPort: intstr.FromInt(8443), NodePort: 30080},
+			}
+		}
+		if args.dualStack {
+			ctx.vmService.Spec.IPFamilies = []vmopv1.IPFamily{vmopv1.IPv6Protocol, vmopv1.IPv4Protocol}
+			ctx.vmService.Spec.IPFamilyPolicy = ipFamilyPolicy(vmopv1.IPFamilyPolicyRequireDualStack)
+		}
+		if args.invalidIPFamily {
+			ctx.vmService.Spec.IPFamilies = []vmopv1.IPFamily{"IPv5"}
+		}
+		if args.duplicateIPFamily {
+			ctx.vmService.Spec.IPFamilies = []vmopv1.IPFamily{vmopv1.IPv4Protocol, vmopv1.IPv4Protocol}
+		}
+		if args.singleStackDualStack {
+			ctx.vmService.Spec.IPFamilies = []vmopv1.IPFamily{vmopv1.IPv4Protocol, vmopv1.IPv6Protocol}
+			ctx.vmService.Spec.IPFamilyPolicy = ipFamilyPolicy(vmopv1.IPFamilyPolicySingleStack)
+		}
+		if args.externalNameIPFamily {
+			ctx.vmService.Spec.Type = vmopv1.VirtualMachineServiceTypeExternalName
+			ctx.vmService.Spec.ExternalName = "my.example.com"
+			ctx.vmService.Spec.IPFamilyPolicy = ipFamilyPolicy(vmopv1.IPFamilyPolicyPreferDualStack)
+		}
 
 		ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured(ctx.vmService)
 		Expect(err).ToNot(HaveOccurred())
@@ -126,6 +190,18 @@ func unitTestsValidateCreate() {
 		Entry("should deny invalid ClusterIP", createArgs{invalidClusterIP: true}, false, "spec.clusterIP: Invalid value: \"100.1000.1.1\": must be a valid IP address", nil),
 		Entry("should deny invalid LoadBalancerSourceRanges", createArgs{invalidLBSourceRanges: true}, false, "spec.loadBalancerSourceRanges: Invalid value: \"[10.1.1.1/42]", nil),
 		Entry("should deny invalid ExternalName", createArgs{invalidExternalName: true}, false, "spec.externalName: Invalid value: \"InValid!\": a lowercase RFC 1123 subdomain must consist of lower case alphanumeric characters", nil),
+		Entry("should allow Headless without ports", createArgs{headless: true}, true, nil, nil),
+		Entry("should deny Headless with ClusterIP", createArgs{headlessClusterIP: true}, false, "spec.clusterIP: Invalid value: \"10.0.0.1\": must be empty or 'None' for Headless services", nil),
+		Entry("should allow NodePort", createArgs{nodePort: true}, true, nil, nil),
+		Entry("should deny NodePort with ClusterIP None", createArgs{nodePortNoneClusterIP: true}, false, "spec.clusterIP: Invalid value: \"None\": may not be set to 'None' for NodePort services", nil),
+		Entry("should deny nodePort when type is not NodePort", createArgs{nodePortNotNodePort: true}, false, "spec.ports[0].nodePort: Forbidden: may only be used when `type` is 'NodePort'", nil),
+		Entry("should deny invalid nodePort", createArgs{invalidNodePort: true}, false, "spec.ports[0].nodePort: Invalid value: 100000:", nil),
+		Entry("should deny duplicate nodePort", createArgs{duplicateNodePort: true}, false, "spec.ports[1].nodePort: Duplicate value: 30080", nil),
+		Entry("should allow dual-stack", createArgs{dualStack: true}, true, nil, nil),
+		Entry("should deny invalid IP family", createArgs{invalidIPFamily: true}, false, "spec.ipFamilies[0]: Unsupported value: \"IPv5\"", nil),
+		Entry("should deny duplicate IP family", createArgs{duplicateIPFamily: true}, false, "spec.ipFamilies[1]: Duplicate value: \"IPv4\"", nil),
+		Entry("should deny two IP families for SingleStack", createArgs{singleStackDualStack: true}, false, "spec.ipFamilies: Invalid value: []v1alpha2.IPFamily{\"IPv4\", \"IPv6\"}: must have at most one entry when `ipFamilyPolicy` is 'SingleStack'", nil),
+		Entry("should deny IP family policy for ExternalName", createArgs{externalNameIPFamily: true}, false, "spec.ipFamilyPolicy: Forbidden: may not be set for ExternalName services", nil),
 	)
 
 	validatePortCreate := func(expectedReason string, ports []vmopv1.VirtualMachineServicePort) {
@@ -152,7 +228,7 @@ func unitTestsValidateCreate() {
 					Name:       "http",
 					Protocol:   "TCP",
 					Port:       80,
-					TargetPort: 8080,
+					TargetPort: intstr.FromInt(8080),
 				},
 			},
 		),
@@ -180,7 +256,24 @@ func unitTestsValidateCreate() {
 		Entry("should deny invalid target port", "spec.ports[0].targetPort: Invalid value: 200000:",
 			[]vmopv1.VirtualMachineServicePort{
 				{
-					TargetPort: 200000,
+					TargetPort: intstr.FromInt(200000),
+				},
+			},
+		),
+		Entry("should allow named target port", "",
+			[]vmopv1.VirtualMachineServicePort{
+				{
+					Name:       "http",
+					Protocol:   "TCP",
+					Port:       80,
+					TargetPort: intstr.FromString("http"),
+				},
+			},
+		),
+		Entry("should deny invalid named target port", "spec.ports[0].targetPort: Invalid value: \"INVALID_NAME\"",
+			[]vmopv1.VirtualMachineServicePort{
+				{
+					TargetPort: intstr.FromString("INVALID_NAME"),
 				},
 			},
 		),
@@ -190,13 +283,13 @@ func unitTestsValidateCreate() {
 					Name:       "port1",
 					Protocol:   "TCP",
 					Port:       80,
-					TargetPort: 8080,
+					TargetPort: intstr.FromInt(8080),
 				},
 				{
 					Name:       "port1",
 					Protocol:   "TCP",
 					Port:       433,
-					TargetPort: 6443,
+					TargetPort: intstr.FromInt(6443),
 				},
 			},
 		),
@@ -206,13 +299,13 @@ func unitTestsValidateCreate() {
 					Name:       "port1",
 					Protocol:   "TCP",
 					Port:       80,
-					TargetPort: 8080,
+					TargetPort: intstr.FromInt(8080),
 				},
 				{
 					Name:       "port2",
 					Protocol:   "TCP",
 					Port:       80,
-					TargetPort: 8080,
+					TargetPort: intstr.FromInt(8080),
 				},
 			},
 		),
@@ -228,6 +321,10 @@ func unitTestsValidateUpdate() {
 	type updateArgs struct {
 		updateType      bool
 		updateClusterIP bool
+		updateIPFamily  bool
+		addIPFamily     bool
+		setIPFamily     bool
+		setIPFamilies   bool
 	}
 
 	validateUpdate := func(args updateArgs, expectedAllowed bool, expectedReason string, expectedErr error) {
@@ -239,6 +336,37 @@ func unitTestsValidateUpdate() {
 		if args.updateClusterIP {
 			ctx.vmService.Spec.ClusterIP = "9.9.9.9"
 		}
+		if args.updateIPFamily || args.addIPFamily {
+			oldVMService := ctx.vmService.DeepCopy()
+			oldVMService.Spec.IPFamilies = []vmopv1.IPFamily{vmopv1.IPv4Protocol}
+			ctx.WebhookRequestContext.OldObj, err = builder.ToUnstructured(oldVMService)
+			Expect(err).ToNot(HaveOccurred())
+		}
+		if args.updateIPFamily {
+			ctx.vmService.Spec.IPFamilies = []vmopv1.IPFamily{vmopv1.IPv6Protocol}
+		}
+		if args.addIPFamily {
+			ctx.vmService.Spec.IPFamilies = []vmopv1.IPFamily{vmopv1.IPv4Protocol, vmopv1.IPv6Protocol}
+		}
+		if args.setIPFamily || args.setIPFamilies {
+			// The IP families were not specified on create so k8s defaulted them on the Service.
+			service := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ctx.vmService.Name,
+					Namespace: ctx.vmService.Namespace,
+				},
+				Spec: corev1.ServiceSpec{
+					IPFamilies: []corev1.IPFamily{corev1.IPv4Protocol},
+				},
+			}
+			Expect(ctx.Client.Create(ctx, service)).To(Succeed())
+		}
+		if args.setIPFamily {
+			ctx.vmService.Spec.IPFamilies = []vmopv1.IPFamily{vmopv1.IPv6Protocol}
+		}
+		if args.setIPFamilies {
+			ctx.vmService.Spec.IPFamilies = []vmopv1.IPFamily{vmopv1.IPv4Protocol, vmopv1.IPv6Protocol}
+		}
 
 		ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured(ctx.vmService)
 		Expect(err).ToNot(HaveOccurred())
@@ -264,6 +392,10 @@ func unitTestsValidateUpdate() {
 		Entry("should allow", updateArgs{}, true, nil, nil),
 		Entry("should deny Type change", updateArgs{updateType: true}, false, "spec.type: Forbidden: field is immutable", nil),
 		Entry("should deny ClusterIP change", updateArgs{updateClusterIP: true}, false, "spec.clusterIP: Forbidden: field is immutable", nil),
+		Entry("should deny primary IP family change", updateArgs{updateIPFamily: true}, false, "spec.ipFamilies[0]: Forbidden: primary IP family is immutable", nil),
+		Entry("should allow secondary IP family to be added", updateArgs{addIPFamily: true}, true, nil, nil),
+		Entry("should deny IP family other than the Service's primary family", updateArgs{setIPFamily: true}, false, "spec.ipFamilies[0]: Forbidden: primary IP family is immutable", nil),
+		Entry("should allow IP families starting with the Service's primary family", updateArgs{setIPFamilies: true}, true, nil, nil),
 	)
 
 	When("the update is performed while object deletion", func() {
@@ -304,3 +436,7 @@ func unitTestsValidateDelete() {
 		})
 	})
 }
+
+func ipFamilyPolicy(policy vmopv1.IPFamilyPolicy) *vmopv1.IPFamilyPolicy {
+	return &policy
+}
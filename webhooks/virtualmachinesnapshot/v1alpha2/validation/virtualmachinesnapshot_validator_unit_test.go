@@ -0,0 +1,193 @@
+// Copyright (c) 2023 VMware, Inc. All Rights Reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package validation_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	vmopv1 "github.com/vmware-tanzu/vm-operator/api/v1alpha2"
+	"github.com/vmware-tanzu/vm-operator/test/builder"
+)
+
+func unitTests() {
+	Describe("Invoking ValidateCreate", unitTestsValidateCreate)
+	Describe("Invoking ValidateUpdate", unitTestsValidateUpdate)
+	Describe("Invoking ValidateDelete", unitTestsValidateDelete)
+}
+
+type unitValidatingWebhookContext struct {
+	builder.UnitTestContextForValidatingWebhook
+	vmSnapshot    *vmopv1.VirtualMachineSnapshot
+	oldVMSnapshot *vmopv1.VirtualMachineSnapshot
+}
+
+func newUnitTestContextForValidatingWebhook(isUpdate bool) *unitValidatingWebhookContext {
+	vmSnapshot := builder.DummyVirtualMachineSnapshot("some-namespace", "some-name", "some-vm-name")
+	obj, err := builder.ToUnstructured(vmSnapshot)
+	Expect(err).ToNot(HaveOccurred())
+
+	var oldVMSnapshot *vmopv1.VirtualMachineSnapshot
+	var oldObj *unstructured.Unstructured
+
+	if isUpdate {
+		oldVMSnapshot = vmSnapshot.DeepCopy()
+		oldObj, err = builder.ToUnstructured(oldVMSnapshot)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	return &unitValidatingWebhookContext{
+		UnitTestContextForValidatingWebhook: *suite.NewUnitTestContextForValidatingWebhook(obj, oldObj),
+		vmSnapshot:                          vmSnapshot,
+		oldVMSnapshot:                       oldVMSnapshot,
+	}
+}
+
+func unitTestsValidateCreate() {
+	var (
+		ctx *unitValidatingWebhookContext
+	)
+
+	type createArgs struct {
+		emptyVMName bool
+	}
+
+	validateCreate := func(args createArgs, expectedAllowed bool, expectedReason string, expectedErr error) {
+		var err error
+
+		if args.emptyVMName {
+			ctx.vmSnapshot.Spec.VMName = ""
+		}
+
+		ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured(ctx.vmSnapshot)
+		Expect(err).ToNot(HaveOccurred())
+
+		response := ctx.ValidateCreate(&ctx.WebhookRequestContext)
+		Expect(response.Allowed).To(Equal(expectedAllowed))
+		if expectedReason != "" {
+			Expect(string(response.Result.Reason)).To(ContainSubstring(expectedReason))
+		}
+		if expectedErr != nil {
+			Expect(response.Result.Message).To(Equal(expectedErr.Error()))
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = newUnitTestContextForValidatingWebhook(false)
+	})
+	AfterEach(func() {
+		ctx = nil
+	})
+
+	DescribeTable("create table", validateCreate,
+		Entry("should allow valid", createArgs{}, true, nil, nil),
+		Entry("should deny empty vmName", createArgs{emptyVMName: true}, false, "spec.vmName: Required value", nil),
+	)
+}
+
+func unitTestsValidateUpdate() {
+	var (
+		ctx      *unitValidatingWebhookContext
+		response admission.Response
+	)
+
+	type updateArgs struct {
+		updateVMName      bool
+		updateDescription bool
+		updateMemory      bool
+		updateQuiesce     bool
+		updateLabels      bool
+	}
+
+	validateUpdate := func(args updateArgs, expectedAllowed bool, expectedReason string, expectedErr error) {
+		var err error
+
+		if args.updateVMName {
+			ctx.vmSnapshot.Spec.VMName = "new-vm-name"
+		}
+		if args.updateDescription {
+			ctx.vmSnapshot.Spec.Description = "new-description"
+		}
+		if args.updateMemory {
+			ctx.vmSnapshot.Spec.Memory = true
+		}
+		if args.updateQuiesce {
+			ctx.vmSnapshot.Spec.Quiesce = true
+		}
+		if args.updateLabels {
+			ctx.vmSnapshot.Labels = map[string]string{"foo": "bar"}
+		}
+
+		ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured(ctx.vmSnapshot)
+		Expect(err).ToNot(HaveOccurred())
+
+		response := ctx.ValidateUpdate(&ctx.WebhookRequestContext)
+		Expect(response.Allowed).To(Equal(expectedAllowed))
+		if expectedReason != "" {
+			Expect(string(response.Result.Reason)).To(Equal(expectedReason))
+		}
+		if expectedErr != nil {
+			Expect(response.Result.Message).To(Equal(expectedErr.Error()))
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = newUnitTestContextForValidatingWebhook(true)
+	})
+	AfterEach(func() {
+		ctx = nil
+	})
+
+	DescribeTable("update table", validateUpdate,
+		Entry("should allow", updateArgs{}, true, nil, nil),
+		Entry("should allow labels change", updateArgs{updateLabels: true}, true, nil, nil),
+		Entry("should deny vmName change", updateArgs{updateVMName: true}, false, "spec.vmName: Invalid value: \"new-vm-name\": field is immutable", nil),
+		Entry("should deny description change", updateArgs{updateDescription: true}, false, "spec.description: Invalid value: \"new-description\": field is immutable", nil),
+		Entry("should deny memory change", updateArgs{updateMemory: true}, false, "spec.memory: Invalid value: true: field is immutable", nil),
+		Entry("should deny quiesce change", updateArgs{updateQuiesce: true}, false, "spec.quiesce: Invalid value: true: field is immutable", nil),
+	)
+
+	When("the update is performed while object deletion", func() {
+		JustBeforeEach(func() {
+			t := metav1.Now()
+			ctx.WebhookRequestContext.Obj.SetDeletionTimestamp(&t)
+			response = ctx.ValidateUpdate(&ctx.WebhookRequestContext)
+		})
+
+		It("should allow the request", func() {
+			Expect(response.Allowed).To(BeTrue())
+			Expect(response.Result).ToNot(BeNil())
+		})
+	})
+}
+
+func unitTestsValidateDelete() {
+	var (
+		ctx      *unitValidatingWebhookContext
+		response admission.Response
+	)
+
+	BeforeEach(func() {
+		ctx = newUnitTestContextForValidatingWebhook(false)
+	})
+	AfterEach(func() {
+		ctx = nil
+	})
+
+	When("the delete is performed", func() {
+		JustBeforeEach(func() {
+			response = ctx.ValidateDelete(&ctx.WebhookRequestContext)
+		})
+
+		It("should allow the request", func() {
+			Expect(response.Allowed).To(BeTrue())
+			Expect(response.Result).ToNot(BeNil())
+		})
+	})
+}
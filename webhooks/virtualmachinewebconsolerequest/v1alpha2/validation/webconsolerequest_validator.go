@@ -96,6 +96,7 @@ func (v validator) ValidateUpdate(ctx *context.WebhookRequestContext) admission.
 	var fieldErrs field.ErrorList
 	fieldErrs = append(fieldErrs, v.validateImmutableFields(wcr, oldwcr)...)
 	fieldErrs = append(fieldErrs, v.validateUUIDLabel(wcr, oldwcr)...)
+	fieldErrs = append(fieldErrs, v.validateUsedAnnotation(wcr, oldwcr)...)
 
 	validationErrs := make([]string, 0, len(fieldErrs))
 	for _, fieldErr := range fieldErrs {
@@ -187,3 +188,20 @@ func (v validator) validateUUIDLabel(wcr, oldwcr *vmopv1.VirtualMachineWebConsol
 
 	return allErrs
 }
+
+// validateUsedAnnotation validates the annotation that marks the request as used
+// is not changed or removed once set, so that the request cannot be used again.
+func (v validator) validateUsedAnnotation(wcr, oldwcr *vmopv1.VirtualMachineWebConsoleRequest) field.ErrorList {
+	var allErrs field.ErrorList
+
+	oldUsedAnnotationVal := oldwcr.Annotations[webconsolerequest.UsedAnnotationKey]
+	if oldUsedAnnotationVal == "" {
+		return allErrs
+	}
+
+	newUsedAnnotationVal := wcr.Annotations[webconsolerequest.UsedAnnotationKey]
+	annotationsPath := field.NewPath("metadata", "annotations")
+	allErrs = append(allErrs, validation.ValidateImmutableField(newUsedAnnotationVal, oldUsedAnnotationVal, annotationsPath.Key(webconsolerequest.UsedAnnotationKey))...)
+
+	return allErrs
+}
@@ -121,6 +121,9 @@ func unitTestsValidateUpdate() {
 		updateVirtualMachineName bool
 		updatePublicKey          bool
 		updateUUIDLabel          bool
+		setUsedAnnotation        bool
+		updateUsedAnnotation     bool
+		removeUsedAnnotation     bool
 	}
 
 	validateUpdate := func(args updateArgs, expectedAllowed bool, expectedReason string, expectedErr error) {
@@ -138,6 +141,20 @@ func unitTestsValidateUpdate() {
 			ctx.wcr.Labels[v1alpha2.UUIDLabelKey] = "new-uuid"
 		}
 
+		if args.setUsedAnnotation {
+			ctx.wcr.Annotations = map[string]string{v1alpha2.UsedAnnotationKey: "some-time"}
+		}
+
+		if args.updateUsedAnnotation || args.removeUsedAnnotation {
+			ctx.oldWcr.Annotations = map[string]string{v1alpha2.UsedAnnotationKey: "some-time"}
+			ctx.WebhookRequestContext.OldObj, err = builder.ToUnstructured(ctx.oldWcr)
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		if args.updateUsedAnnotation {
+			ctx.wcr.Annotations = map[string]string{v1alpha2.UsedAnnotationKey: "other-time"}
+		}
+
 		ctx.WebhookRequestContext.Obj, err = builder.ToUnstructured((ctx.wcr))
 		Expect(err).ToNot(HaveOccurred())
 
@@ -163,6 +180,9 @@ func unitTestsValidateUpdate() {
 		Entry("should deny Virtualmachine Name change", updateArgs{updateVirtualMachineName: true}, false, "spec.Name: Invalid value: \"new-vm-name\": field is immutable", nil),
 		Entry("should deny PublicKey change", updateArgs{updatePublicKey: true}, false, "spec.publicKey: Invalid value: \"new-public-key\": field is immutable", nil),
 		Entry("should deny UUID label change", updateArgs{updateUUIDLabel: true}, false, "metadata.labels[vmoperator.vmware.com/webconsolerequest-uuid]: Invalid value: \"new-uuid\": field is immutable", nil),
+		Entry("should allow setting used annotation", updateArgs{setUsedAnnotation: true}, true, nil, nil),
+		Entry("should deny used annotation change", updateArgs{updateUsedAnnotation: true}, false, "metadata.annotations[vmoperator.vmware.com/webconsolerequest-used]: Invalid value: \"other-time\": field is immutable", nil),
+		Entry("should deny used annotation removal", updateArgs{removeUsedAnnotation: true}, false, "metadata.annotations[vmoperator.vmware.com/webconsolerequest-used]: Invalid value: \"\": field is immutable", nil),
 	)
 
 	When("the update is performed while object deletion", func() {
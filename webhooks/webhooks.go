@@ -11,9 +11,17 @@ import (
 	"github.com/vmware-tanzu/vm-operator/webhooks/persistentvolumeclaim"
 	"github.com/vmware-tanzu/vm-operator/webhooks/virtualmachine"
 	"github.com/vmware-tanzu/vm-operator/webhooks/virtualmachineclass"
+	"github.com/vmware-tanzu/vm-operator/webhooks/virtualmachineexportrequest"
+	"github.com/vmware-tanzu/vm-operator/webhooks/virtualmachineguestcommandrequest"
+	"github.com/vmware-tanzu/vm-operator/webhooks/virtualmachineimageimportrequest"
+	"github.com/vmware-tanzu/vm-operator/webhooks/virtualmachinepowerschedule"
 	"github.com/vmware-tanzu/vm-operator/webhooks/virtualmachinepublishrequest"
+	"github.com/vmware-tanzu/vm-operator/webhooks/virtualmachinereplicaset"
+	"github.com/vmware-tanzu/vm-operator/webhooks/virtualmachinerestorerequest"
+	"github.com/vmware-tanzu/vm-operator/webhooks/virtualmachineserialconsolerequest"
 	"github.com/vmware-tanzu/vm-operator/webhooks/virtualmachineservice"
 	"github.com/vmware-tanzu/vm-operator/webhooks/virtualmachinesetresourcepolicy"
+	"github.com/vmware-tanzu/vm-operator/webhooks/virtualmachinesnapshot"
 	"github.com/vmware-tanzu/vm-operator/webhooks/virtualmachinewebconsolerequest"
 )
 
@@ -28,15 +36,39 @@ func AddToManager(ctx *context.ControllerManagerContext, mgr ctrlmgr.Manager) er
 	if err := virtualmachineclass.AddToManager(ctx, mgr); err != nil {
 		return errors.Wrap(err, "failed to initialize VirtualMachineClass webhooks")
 	}
+	if err := virtualmachineexportrequest.AddToManager(ctx, mgr); err != nil {
+		return errors.Wrap(err, "failed to initialize VirtualMachineExportRequest webhooks")
+	}
+	if err := virtualmachineguestcommandrequest.AddToManager(ctx, mgr); err != nil {
+		return errors.Wrap(err, "failed to initialize VirtualMachineGuestCommandRequest webhooks")
+	}
+	if err := virtualmachineimageimportrequest.AddToManager(ctx, mgr); err != nil {
+		return errors.Wrap(err, "failed to initialize VirtualMachineImageImportRequest webhooks")
+	}
+	if err := virtualmachinepowerschedule.AddToManager(ctx, mgr); err != nil {
+		return errors.Wrap(err, "failed to initialize VirtualMachinePowerSchedule webhooks")
+	}
 	if err := virtualmachinepublishrequest.AddToManager(ctx, mgr); err != nil {
 		return errors.Wrap(err, "failed to initialize VirtualMachinePublishRequest webhooks")
 	}
+	if err := virtualmachinereplicaset.AddToManager(ctx, mgr); err != nil {
+		return errors.Wrap(err, "failed to initialize VirtualMachineReplicaSet webhooks")
+	}
+	if err := virtualmachinerestorerequest.AddToManager(ctx, mgr); err != nil {
+		return errors.Wrap(err, "failed to initialize VirtualMachineRestoreRequest webhooks")
+	}
+	if err := virtualmachineserialconsolerequest.AddToManager(ctx, mgr); err != nil {
+		return errors.Wrap(err, "failed to initialize VirtualMachineSerialConsoleRequest webhooks")
+	}
 	if err := virtualmachineservice.AddToManager(ctx, mgr); err != nil {
 		return errors.Wrap(err, "failed to initialize VirtualMachineService webhooks")
 	}
 	if err := virtualmachinesetresourcepolicy.AddToManager(ctx, mgr); err != nil {
 		return errors.Wrap(err, "failed to initialize VirtualMachineSetResourcePolicy webhooks")
 	}
+	if err := virtualmachinesnapshot.AddToManager(ctx, mgr); err != nil {
+		return errors.Wrap(err, "failed to initialize VirtualMachineSnapshot webhooks")
+	}
 	if err := virtualmachinewebconsolerequest.AddToManager(ctx, mgr); err != nil {
 		return errors.Wrap(err, "failed to initialize VirtualMachineWebConsoleRequest webhooks")
 	}